@@ -0,0 +1,122 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// kinds of distributions
+const (
+	DistFixed   = "fixed"
+	DistUniform = "uniform"
+	DistExp     = "exp"
+)
+
+// Distribution is a distribution of durations, e.g. of the think time, see ParseDistribution.
+// The zero value is no duration at all.
+type Distribution struct {
+	Kind string        // DistFixed, DistUniform or DistExp
+	Min  time.Duration // the fixed duration, the lower bound of the uniform or the mean of the exponential one
+	Max  time.Duration // upper bound of the uniform distribution
+}
+
+// ParseDistribution parses a fixed duration, e.g. "10ms", a uniform distribution, e.g. "5ms-15ms",
+// or an exponential distribution with the given mean, e.g. "exp:10ms".
+func ParseDistribution(s string) (Distribution, error) {
+	invalid := fmt.Errorf("invalid distribution %q, e.g. 10ms (fixed), 5ms-15ms (uniform) or exp:10ms (exponential with the mean)", s)
+
+	if mean := strings.TrimPrefix(s, DistExp+":"); mean != s {
+		d, err := time.ParseDuration(mean)
+		if err != nil || d <= 0 {
+			return Distribution{}, invalid
+		}
+		return Distribution{Kind: DistExp, Min: d}, nil
+	}
+	if i := strings.Index(s, "-"); i > 0 {
+		min, err := time.ParseDuration(s[:i])
+		if err != nil {
+			return Distribution{}, invalid
+		}
+		max, err := time.ParseDuration(s[i+1:])
+		if err != nil || min < 0 || max < min {
+			return Distribution{}, invalid
+		}
+		return Distribution{Kind: DistUniform, Min: min, Max: max}, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return Distribution{}, invalid
+	}
+	return Distribution{Kind: DistFixed, Min: d}, nil
+}
+
+// String returns the distribution in the format of ParseDistribution, empty for the zero value.
+func (d Distribution) String() string {
+	switch d.Kind {
+	case DistUniform:
+		return fmt.Sprintf("%v-%v", d.Min, d.Max)
+	case DistExp:
+		return fmt.Sprintf("%v:%v", DistExp, d.Min)
+	case DistFixed:
+		return d.Min.String()
+	}
+	return ""
+}
+
+// draw returns a random duration of the distribution.
+func (d Distribution) draw(r *rand.Rand) time.Duration {
+	switch d.Kind {
+	case DistUniform:
+		return d.Min + time.Duration(r.Int63n(int64(d.Max-d.Min)+1))
+	case DistExp:
+		return time.Duration(r.ExpFloat64() * float64(d.Min))
+	}
+	return d.Min
+}
+
+// ValidateArrival returns an error, when the distribution of the inter-arrival times of
+// Options.Arrival is unknown.
+func ValidateArrival(arrival string) error {
+	switch arrival {
+	case "", DistFixed, DistUniform, DistExp:
+		return nil
+	}
+	return fmt.Errorf("invalid arrival %q, fixed, uniform or exp", arrival)
+}
+
+// interArrival returns the distribution of the inter-arrival times with the mean interval:
+// fixed, uniform between 0 and twice the mean or exponential, i.e. a Poisson process.
+func interArrival(arrival string, mean time.Duration) Distribution {
+	switch arrival {
+	case DistUniform:
+		return Distribution{Kind: DistUniform, Max: 2 * mean}
+	case DistExp:
+		return Distribution{Kind: DistExp, Min: mean}
+	}
+	return Distribution{Kind: DistFixed, Min: mean}
+}
+
+// thinkRand returns the random number generator of the think time of the worker,
+// independent of the one of its random values.
+func thinkRand(seed int64, thread int) *rand.Rand {
+	return rand.New(rand.NewSource(^(seed + int64(thread))))
+}
+
+// think pauses the worker for a duration of the think time, it returns false when the context
+// was cancelled in the meantime.
+func think(ctx context.Context, d Distribution, r *rand.Rand) bool {
+	if d.Kind == "" {
+		return true
+	}
+	t := time.NewTimer(d.draw(r))
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,118 @@
+package benchmark
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDistribution(t *testing.T) {
+	testCases := []struct {
+		description string
+		input       string
+		want        Distribution
+		wantErr     bool
+	}{
+		{description: "fixed", input: "10ms", want: Distribution{Kind: DistFixed, Min: 10 * time.Millisecond}},
+		{description: "uniform", input: "5ms-15ms", want: Distribution{Kind: DistUniform, Min: 5 * time.Millisecond, Max: 15 * time.Millisecond}},
+		{description: "exponential", input: "exp:10ms", want: Distribution{Kind: DistExp, Min: 10 * time.Millisecond}},
+		{description: "invalid duration", input: "10", wantErr: true},
+		{description: "reversed bounds", input: "15ms-5ms", wantErr: true},
+		{description: "exponential without mean", input: "exp:0s", wantErr: true},
+		{description: "negative", input: "-5ms", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			// act
+			d, err := ParseDistribution(tc.input)
+
+			// assert
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, d)
+			require.Equal(t, tc.input, d.String())
+		})
+	}
+}
+
+func TestDistributionDraw(t *testing.T) {
+	// arrange
+	r := rand.New(rand.NewSource(1))
+	uniform := Distribution{Kind: DistUniform, Min: 5 * time.Millisecond, Max: 15 * time.Millisecond}
+	exp := Distribution{Kind: DistExp, Min: 10 * time.Millisecond}
+
+	// act
+	var total time.Duration
+	for i := 0; i < 10000; i++ {
+		d := uniform.draw(r)
+		require.True(t, d >= uniform.Min && d <= uniform.Max, d)
+		total += exp.draw(r)
+	}
+
+	// assert
+	require.Equal(t, 10*time.Millisecond, Distribution{Kind: DistFixed, Min: 10 * time.Millisecond}.draw(r))
+	require.InDelta(t, float64(10*time.Millisecond), float64(total/10000), float64(time.Millisecond))
+}
+
+func TestOpenLimiter(t *testing.T) {
+	// arrange
+	l := newArrivals(1000, DistFixed, 1)
+	start := time.Now()
+	l.wait()
+	l.wait()
+
+	// act, the slots missed in the meantime queue up
+	time.Sleep(20 * time.Millisecond)
+	queued := l.wait()
+
+	// assert
+	require.False(t, queued.IsZero())
+	require.True(t, queued.Sub(start) < 5*time.Millisecond, queued.Sub(start))
+	require.True(t, newArrivals(0, DistExp, 1).wait().IsZero())
+}
+
+func TestLoopThinkTime(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything)
+	tmpl, _ := parseStmt("SELECT 1")
+
+	// act
+	start := time.Now()
+	latencies, _ := loop(context.Background(), bencher, tmpl, Options{Iter: 10, Threads: 2, ThinkTime: Distribution{Kind: DistFixed, Min: 10 * time.Millisecond}})
+
+	// assert, 5 statements with think time per routine, excluded from the latencies
+	require.True(t, time.Since(start) >= 50*time.Millisecond)
+	require.Equal(t, 10, latencies.n)
+	require.True(t, latencies.max < 10*time.Millisecond, latencies.max)
+}
+
+func TestLoopArrival(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything)
+	tmpl, _ := parseStmt("SELECT 1")
+
+	// act
+	start := time.Now()
+	latencies, _ := loop(context.Background(), bencher, tmpl, Options{Iter: 50, Threads: 2, Rate: 1000, Arrival: DistExp})
+
+	// assert, 50 requests arriving at 1000 ops/s on average
+	require.Equal(t, 50, latencies.n)
+	require.True(t, time.Since(start) >= 10*time.Millisecond)
+	require.True(t, time.Since(start) < time.Second)
+}
+
+func TestValidateArrival(t *testing.T) {
+	require.NoError(t, ValidateArrival(""))
+	require.NoError(t, ValidateArrival(DistExp))
+	require.Error(t, ValidateArrival("poisson"))
+}
@@ -0,0 +1,25 @@
+package benchmark
+
+import "context"
+
+// churner executes each statement on a new connection, authenticated with the method or,
+// without an authenticator, like the pooled connections.
+type churner struct {
+	Bencher
+	auth   Authenticator
+	method string
+	fresh  FreshConnExecer
+}
+
+// Exec connects, executes the statement and disconnects.
+func (c *churner) Exec(stmt string) error {
+	return c.ExecContext(context.Background(), stmt)
+}
+
+// ExecContext connects, executes the statement and disconnects, it's cancelled with the context.
+func (c *churner) ExecContext(ctx context.Context, stmt string) error {
+	if c.auth == nil {
+		return c.fresh.ExecFreshConn(ctx, stmt)
+	}
+	return c.auth.ExecAuth(ctx, c.method, stmt)
+}
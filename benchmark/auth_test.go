@@ -0,0 +1,55 @@
+package benchmark
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type mockedAuthenticator struct {
+	mockedBencher
+}
+
+func (a *mockedAuthenticator) ExecAuth(ctx context.Context, method, stmt string) error {
+	a.Called(method, stmt)
+	return nil
+}
+
+func TestAuth(t *testing.T) {
+	// arrange
+	bencher := &mockedAuthenticator{}
+	bencher.On("ExecAuth", "scram-sha-256", "SELECT 1").Return()
+	b := Benchmark{Name: "connects", Type: TypeLoop, Stmt: "SELECT {{.Iter}}", Auth: "scram-sha-256"}
+
+	// act
+	Run(context.Background(), bencher, b, Options{Iter: 1, Threads: 1})
+
+	// assert
+	bencher.AssertNumberOfCalls(t, "ExecAuth", 1)
+	bencher.AssertNotCalled(t, "Exec", mock.Anything)
+}
+
+type mockedFreshConnExecer struct {
+	mockedBencher
+}
+
+func (f *mockedFreshConnExecer) ExecFreshConn(ctx context.Context, stmt string) error {
+	f.Called(stmt)
+	return nil
+}
+
+func TestFresh(t *testing.T) {
+	// arrange
+	bencher := &mockedFreshConnExecer{}
+	bencher.On("ExecFreshConn", mock.Anything).Return()
+	b := Benchmark{Name: "connects", Type: TypeLoop, Stmt: "SELECT {{.Iter}}", Fresh: true}
+
+	// act
+	Run(context.Background(), bencher, b, Options{Iter: 2, Threads: 1})
+
+	// assert
+	bencher.AssertCalled(t, "ExecFreshConn", "SELECT 1")
+	bencher.AssertCalled(t, "ExecFreshConn", "SELECT 2")
+	bencher.AssertNotCalled(t, "Exec", mock.Anything)
+}
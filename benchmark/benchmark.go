@@ -1,13 +1,10 @@
 package benchmark
 
 import (
+	"context"
 	"log"
-	"math/rand"
-	"os"
-	"os/signal"
-	"strings"
 	"sync"
-	"text/template"
+	"sync/atomic"
 	"time"
 )
 
@@ -16,7 +13,41 @@ type Bencher interface {
 	Setup()
 	Cleanup()
 	Benchmarks() []Benchmark
-	Exec(string)
+	Exec(string) error
+}
+
+// Authenticator is implemented by benchers, which are able to connect with several authentication
+// methods. It's required to benchmark the connection churn of the methods, see Benchmark.Auth.
+type Authenticator interface {
+	// ExecAuth connects with the authentication method, executes the statement and disconnects.
+	ExecAuth(ctx context.Context, method, stmt string) error
+}
+
+// FreshConnExecer is implemented by benchers, which are able to open a new connection per statement,
+// with the same settings as the pooled ones. It's required to benchmark the connection establishment,
+// e.g. TLS handshakes or proxies like PgBouncer, see Benchmark.Fresh.
+type FreshConnExecer interface {
+	// ExecFreshConn connects, executes the statement and disconnects.
+	ExecFreshConn(ctx context.Context, stmt string) error
+}
+
+// Querier is implemented by benchers, which are able to return the result of a statement,
+// e.g. the generated IDs of INSERT ... RETURNING. It's required to capture values.
+type Querier interface {
+	// Query executes the statement and returns the first column of the returned rows.
+	Query(string) ([]string, error)
+}
+
+// ContextQuerier is implemented by queriers, which are able to cancel a running query,
+// e.g. when it exceeds the query timeout.
+type ContextQuerier interface {
+	QueryContext(ctx context.Context, stmt string) ([]string, error)
+}
+
+// ContextExecer is implemented by benchers, which are able to cancel a running statement,
+// e.g. when the benchmarks are interrupted. Otherwise the running statements finish.
+type ContextExecer interface {
+	ExecContext(ctx context.Context, stmt string) error
 }
 
 // BenchType determines if the particular benchmark should be run several times or only once.
@@ -29,46 +60,233 @@ const (
 	TypeOnce BenchType = iota
 )
 
+// String returns the name of the benchmark type.
+func (t BenchType) String() string {
+	switch t {
+	case TypeLoop:
+		return "loop"
+	case TypeOnce:
+		return "once"
+	}
+	return "unknown"
+}
+
 // Benchmark contains the benchmark name, its db statement and its type.
 type Benchmark struct {
 	Name     string
 	Type     BenchType
-	Parallel bool
+	Parallel bool  // run concurrently with the following benchmarks, see Scheduler
+	Seed     int64 // base seed of the random values, overrides SetSeed (0 -> not overridden)
 	Stmt     string
+	Capture  string // pool for the values returned by the statement, see Querier
+	Expect   string // expected result of the statement, e.g. rows=1, a mismatch fails it, see Querier
+	Auth     string // authentication method of a new connection per statement, see Authenticator
+	Tx       bool   // execute the statements of each iteration in a transaction, see TxExecer
+	Fresh    bool   // execute each statement on a new connection, see FreshConnExecer
+	Skip     string // reason why the benchmark can't run on this database, it's skipped when set
+	Tags     []string
+	Baseline string // name of a preceding benchmark, the overhead compared to it is reported
+
+	// operations per second of the loop benchmark, overrides Options.Rate (0 -> not overridden)
+	Rate float64
+
+	// statements of a mixed workload, one is chosen per iteration by the weights, instead of Stmt
+	Mix []MixStmt
 }
 
-// Run executes the benchmark.
-func Run(bencher Bencher, b Benchmark, iter, threads int) time.Duration {
-	t := template.New(b.Name)
-	t, err := t.Parse(b.Stmt)
+// Capability tags of the built-in benchmarks, custom scripts may use their own.
+const (
+	TagRead  = "read"  // reads data
+	TagWrite = "write" // changes data
+	TagDDL   = "ddl"   // changes the schema
+	TagBulk  = "bulk"  // processes many rows per statement
+)
+
+// HasTag returns whether the benchmark has at least one of the tags.
+func (b Benchmark) HasTag(tags []string) bool {
+	for _, tag := range tags {
+		for _, t := range b.Tags {
+			if t == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Options configures the execution of a benchmark.
+type Options struct {
+	Iter     int           // number of iterations
+	Duration time.Duration // run the loop benchmarks for this duration instead of Iter iterations
+	Threads  int           // number of concurrent routines
+	Offset   int           // added to the iteration counter, e.g. when the load is split across processes
+	Rate     float64       // operations per second of the loop benchmarks, shared by all routines (0 -> unlimited)
+
+	ThreadOffset int // added to the worker index, e.g. when the load is split across processes
+	TotalThreads int // total number of workers across processes, defaults to Threads
+
+	// stop the benchmark as soon as more than this fraction of the iterations failed (0 -> disabled),
+	// only with a fixed number of iterations
+	MaxErrorRate float64
+
+	// the rate and threads of the loop benchmarks can be changed while running, see SetTuning
+	Live bool
+
+	// execute the loop benchmarks for these iterations or this duration before they're measured,
+	// the measured iterations continue the counter, see Result.Warmup
+	Warmup         int
+	WarmupDuration time.Duration
+
+	// base seed of the random values of the workers, see SetSeed (0 -> the one set by SetSeed)
+	Seed int64
+
+	// distribution of the intervals between the requests arriving at Rate (open model), the latency
+	// includes the wait for a free routine (empty -> the routines wait for the next free slot)
+	Arrival string
+	// pause of each routine after each statement, excluded from the latency (zero -> none)
+	ThinkTime Distribution
+}
+
+// Run executes the benchmark and waits for it, skipped benchmarks are not executed.
+// Parallel benchmarks are run by a Scheduler, they don't record the outages, which are shared.
+// When the context is cancelled, the benchmark stops and the result contains the statements
+// executed so far, the cancelled statements are not recorded.
+func Run(ctx context.Context, bencher Bencher, b Benchmark, opts Options) Result {
+	if b.Skip != "" {
+		return Result{}
+	}
+	ctx = withBenchmark(ctx, b.Name)
+
+	t, err := parseBenchmark(b)
 	if err != nil {
 		log.Fatalf("failed to parse template: %v", err)
 	}
+	resetValues(bencher)
 
-	start := time.Now()
+	if b.Capture != "" || b.Expect != "" {
+		querier, ok := bencher.(Querier)
+		if !ok {
+			log.Fatalf("failed to capture or verify the values of %v: database doesn't support it", b.Name)
+		}
+		c := &capturer{Bencher: bencher, querier: querier, pool: b.Capture}
+		if b.Expect != "" {
+			if c.expect, err = parseExpect(b.Expect); err != nil {
+				log.Fatalf("failed to verify %v: %v", b.Name, err)
+			}
+		}
+		bencher = c
+	}
+
+	if b.Auth != "" {
+		auth, ok := bencher.(Authenticator)
+		if !ok {
+			log.Fatalf("failed to connect with %v: database doesn't support it", b.Auth)
+		}
+		bencher = &churner{Bencher: bencher, auth: auth, method: b.Auth}
+	}
+
+	if b.Fresh && b.Auth == "" {
+		if b.Tx {
+			log.Fatalf("failed to execute %v in transactions: they can't span new connections", b.Name)
+		}
+		fresh, ok := bencher.(FreshConnExecer)
+		if !ok {
+			log.Fatalf("failed to execute %v on new connections: database doesn't support it", b.Name)
+		}
+		bencher = &churner{Bencher: bencher, fresh: fresh}
+	}
+
+	if b.Tx {
+		tx, ok := bencher.(TxExecer)
+		if !ok {
+			log.Fatalf("failed to execute %v in transactions: database doesn't support it", b.Name)
+		}
+		bencher = &transactor{Bencher: bencher, tx: tx}
+	}
+
+	// the seed of the benchmark overrides the one of the options
+	if b.Seed != 0 {
+		opts.Seed = b.Seed
+	}
+	opts.Seed = opts.baseSeed()
+	if b.Rate > 0 {
+		opts.Rate = b.Rate
+	}
+
+	var warm int
+	if b.Type == TypeLoop && !b.Parallel && (opts.Warmup > 0 || opts.WarmupDuration > 0) {
+		warm = warmup(ctx, bencher, b, opts)
+		opts.Offset += warm
+	}
+
+	var (
+		start     = time.Now()
+		paused    = pausedTotal()
+		latencies *histogram
+		errors    int
+	)
+	if !b.Parallel {
+		failures.reset(start)
+		errorKinds.reset()
+	}
 	switch b.Type {
 	case TypeOnce:
-		if b.Parallel {
-			go once(bencher, t)
-		} else {
-			once(bencher, t)
-		}
+		latencies, errors = once(ctx, bencher, t, opts)
 	case TypeLoop:
-		if b.Parallel {
-			go loop(bencher, t, iter, threads)
-		} else {
-			loop(bencher, t, iter, threads)
+		switch {
+		case opts.Live && !b.Parallel:
+			latencies, errors = liveLoop(ctx, bencher, t, opts)
+		default:
+			latencies, errors = loop(ctx, bencher, t, opts)
 		}
 	}
 
-	return time.Since(start)
+	result := newResult(time.Since(start)-(pausedTotal()-paused), latencies, errors)
+	result.Warmup = warm
+	result.Seed = opts.Seed
+	if !b.Parallel {
+		result.Outages = failures.outages()
+		result.ErrorKinds = errorKinds.take()
+	}
+	if t.mix != nil {
+		result.Mix = t.mix.results(result.Duration)
+	}
+	return result
 }
 
-// loop runs the benchmark concurrently several times.
-func loop(bencher Bencher, t *template.Template, iterations, threads int) {
-	wg := &sync.WaitGroup{}
+// loop runs the benchmark concurrently several times and returns the histogram of the latencies of
+// the statements and the number of failed statements. It stops, when the context is cancelled.
+func loop(ctx context.Context, bencher Bencher, t *statement, opts Options) (*histogram, int) {
+	iterations, threads := opts.Iter, opts.Threads
+
+	totalThreads := opts.TotalThreads
+	if totalThreads == 0 {
+		totalThreads = threads
+	}
+
+	var (
+		ops       int64 // counter of the executed operations, shared by all routines
+		errors    int64 // counter of the failed operations, shared by all routines
+		mu        sync.Mutex
+		latencies = &histogram{}
+		wg        = &sync.WaitGroup{}
+
+		// closed when the errors exceed the max. error rate of all iterations
+		stop     = make(chan struct{})
+		stopOnce sync.Once
+		maxErr   = int64(opts.MaxErrorRate * float64(iterations))
+
+		// paces the routines to the rate, nil when unlimited
+		limit *limiter
+
+		// the duration excludes the pauses
+		start  = time.Now()
+		paused = pausedTotal()
+	)
+	if opts.Rate > 0 {
+		limit = newArrivals(opts.Rate, opts.Arrival, opts.baseSeed())
+	}
 	wg.Add(threads)
-	defer wg.Wait()
 
 	// start as many routines as specified
 	for routine := 0; routine < threads; routine++ {
@@ -82,59 +300,97 @@ func loop(bencher Bencher, t *template.Template, iterations, threads int) {
 			to += remainder
 		}
 
+		from += opts.Offset
+		to += opts.Offset
+
 		// start the routine
-		go func(gofrom, togo int) {
+		go func(routine, gofrom, togo int) {
 			defer wg.Done()
-			// notify channel for SIGINT (ctrl-c)
-			sigchan := make(chan os.Signal, 1)
-			signal.Notify(sigchan, os.Interrupt)
+			defer startWorker(ctx)()
+			ctx, flushTrace := traceWorker(ctx, opts.ThreadOffset+routine)
+			defer flushTrace()
+			builder := newBuilder(t)
+			builder.seed = opts.baseSeed()
+			builder.setThread(opts.ThreadOffset + routine)
+			builder.data.Threads = totalThreads
+			thinking := thinkRand(builder.seed, opts.ThreadOffset+routine)
 
-			for i := gofrom; i <= togo; i++ {
+			own := &histogram{}
+			defer func() {
+				mu.Lock()
+				latencies.merge(own)
+				mu.Unlock()
+				builder.flush()
+			}()
+
+			for i := gofrom; opts.Duration > 0 || i <= togo; i++ {
 				select {
-				case <-sigchan:
-					// got SIGINT, stop benchmarking
+				case <-ctx.Done():
+					// interrupted, stop benchmarking
+					return
+				case <-stop:
+					// too many errors, the result is meaningless anyway
 					return
 				default:
+					if !waitResumed(ctx) {
+						return
+					}
+					if opts.Duration > 0 && time.Since(start)-(pausedTotal()-paused) >= opts.Duration {
+						return
+					}
+					var arrival time.Time
+					if limit != nil {
+						arrival = limit.wait()
+					}
 					// build and execute the statement
-					stmt := buildStmt(t, i)
-					bencher.Exec(stmt)
+					builder.data.Op = opts.Offset + int(atomic.AddInt64(&ops, 1))
+					if opts.Duration > 0 {
+						// the number of iterations is unknown, they are counted in the order of execution
+						i = builder.data.Op
+					}
+					stmt := builder.build(i)
+					took, err := execute(ctx, bencher, stmt)
+					if canceled(ctx, err) {
+						return
+					}
+					if limit != nil && limit.open() && !arrival.IsZero() {
+						// the request waited for a free routine
+						took = time.Since(arrival)
+					}
+					own.record(took)
+					builder.record(took, err)
+					if err != nil && atomic.AddInt64(&errors, 1) > maxErr && opts.MaxErrorRate > 0 && opts.Duration == 0 {
+						stopOnce.Do(func() { close(stop) })
+					}
+					if !think(ctx, opts.ThinkTime, thinking) {
+						return
+					}
 				}
 			}
-		}(from, to)
+		}(routine, from, to)
 	}
-}
 
-// once runs the benchmark a single time.
-func once(bencher Bencher, t *template.Template) {
-	stmt := buildStmt(t, 1)
-	bencher.Exec(stmt)
+	wg.Wait()
+	return latencies, int(errors)
 }
 
-// buildStmt parses the given template with variables and functions to a pure DB statement.
-func buildStmt(t *template.Template, i int) string {
-	sb := &strings.Builder{}
-
-	data := struct {
-		Iter            int
-		Seed            func(int64)
-		RandInt63       func() int64
-		RandInt63n      func(int64) int64
-		RandFloat32     func() float32
-		RandFloat64     func() float64
-		RandExpFloat64  func() float64
-		RandNormFloat64 func() float64
-	}{
-		Iter:            i,
-		Seed:            rand.Seed,
-		RandInt63:       rand.Int63,
-		RandInt63n:      rand.Int63n,
-		RandFloat32:     rand.Float32,
-		RandFloat64:     rand.Float64,
-		RandExpFloat64:  rand.ExpFloat64,
-		RandNormFloat64: rand.NormFloat64,
-	}
-	if err := t.Execute(sb, data); err != nil {
-		log.Fatalf("failed to execute template: %v", err)
-	}
-	return sb.String()
+// once runs the benchmark a single time and returns the histogram of the latency of the statement
+// and the number of failed statements. No latency is recorded, when the context was cancelled.
+func once(ctx context.Context, bencher Bencher, t *statement, opts Options) (*histogram, int) {
+	defer startWorker(ctx)()
+	builder := newBuilder(t)
+	builder.seed = opts.baseSeed()
+	builder.setThread(0)
+	builder.data.Threads = 1
+	builder.data.Op = 1
+	latencies := &histogram{}
+	took, err := execute(ctx, bencher, builder.build(1))
+	if canceled(ctx, err) {
+		return latencies, 0
+	}
+	latencies.record(took)
+	if err != nil {
+		return latencies, 1
+	}
+	return latencies, 0
 }
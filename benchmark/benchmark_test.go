@@ -1,10 +1,16 @@
 package benchmark
 
 import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
-	"text/template"
+	"time"
 
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type mockedBencher struct {
@@ -14,21 +20,12 @@ type mockedBencher struct {
 func (b *mockedBencher) Benchmarks() []Benchmark { return []Benchmark{} }
 func (b *mockedBencher) Setup()                  {}
 func (b *mockedBencher) Cleanup()                {}
-func (b *mockedBencher) Exec(s string)           { _ = b.Called(s) }
-
-func TestBuildStmt(t *testing.T) {
-	// arrange
-	tmpl := template.New("test")
-	tmpl.Parse("{{.Iter}} {{call .RandInt63}}")
-
-	// act
-	stmt := buildStmt(tmpl, 1337)
-
-	// assert
-	want := "1337 5577006791947779410"
-	if stmt != want {
-		t.Errorf("got statement %v, want %v", stmt, want)
+func (b *mockedBencher) Exec(s string) error {
+	// the error is optional, most tests don't set a return value
+	if args := b.Called(s); len(args) > 0 {
+		return args.Error(0)
 	}
+	return nil
 }
 
 func TestRun(t *testing.T) {
@@ -52,15 +49,12 @@ func TestRun(t *testing.T) {
 			bencher := &mockedBencher{}
 			bencher.On("Exec", mock.Anything)
 
-			tmpl := template.New("test")
-			tmpl.Parse("can be ignored")
-
 			iter := 13
 			threads := 5
 			bLoop := Benchmark{Name: "test", Type: tt.givenType, Stmt: "NONE"}
 
 			// act
-			Run(bencher, bLoop, iter, threads)
+			Run(context.Background(), bencher, bLoop, Options{Iter: iter, Threads: threads})
 
 			// assert
 			switch tt.givenType {
@@ -72,32 +66,188 @@ func TestRun(t *testing.T) {
 		})
 	}
 }
+func TestHasTag(t *testing.T) {
+	b := Benchmark{Tags: []string{TagRead, TagBulk}}
+
+	require.True(t, b.HasTag([]string{TagWrite, TagBulk}))
+	require.False(t, b.HasTag([]string{TagWrite, TagDDL}))
+	require.False(t, Benchmark{}.HasTag([]string{TagRead}))
+}
+
+func TestRunSkipped(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	b := Benchmark{Name: "upserts", Type: TypeLoop, Stmt: "NONE", Skip: "not supported"}
+
+	// act
+	res := Run(context.Background(), bencher, b, Options{Iter: 10, Threads: 2})
+
+	// assert
+	require.Equal(t, Result{}, res)
+	bencher.AssertNotCalled(t, "Exec", mock.Anything)
+}
+
 func TestLoop(t *testing.T) {
 	// arrange
 	bencher := &mockedBencher{}
 	bencher.On("Exec", mock.Anything)
 
-	tmpl := template.New("test")
-	tmpl.Parse("{{.Iter}} {{call .RandInt63}}")
+	tmpl, _ := parseStmt("{{.Iter}} {{call .RandInt63}}")
 
 	// act
-	loop(bencher, tmpl, 17, 5)
+	loop(context.Background(), bencher, tmpl, Options{Iter: 17, Threads: 5})
 
 	// assert
 	bencher.AssertNumberOfCalls(t, "Exec", 17)
 }
 
+func TestLoopWorkers(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything)
+
+	tmpl, _ := parseStmt("{{.Thread}}/{{.Threads}} {{.Op}}")
+
+	// act
+	loop(context.Background(), bencher, tmpl, Options{Iter: 6, Threads: 3, Offset: 10, ThreadOffset: 3, TotalThreads: 6})
+
+	// assert
+	// the order of the operations depends on the scheduling
+	threads := map[string]int{}
+	ops := map[string]bool{}
+	for _, c := range bencher.Calls {
+		fields := strings.Fields(c.Arguments.String(0))
+		threads[fields[0]]++
+		ops[fields[1]] = true
+	}
+	require.Equal(t, map[string]int{"3/6": 2, "4/6": 2, "5/6": 2}, threads)
+	require.Equal(t, map[string]bool{"11": true, "12": true, "13": true, "14": true, "15": true, "16": true}, ops)
+}
+
 func TestOnce(t *testing.T) {
 	// arrange
 	bencher := &mockedBencher{}
 	bencher.On("Exec", mock.Anything)
 
-	tmpl := template.New("test")
-	tmpl.Parse("{{.Iter}} {{call .RandInt63}}")
+	tmpl, _ := parseStmt("{{.Iter}} {{call .RandInt63}}")
 
 	// act
-	once(bencher, tmpl)
+	once(context.Background(), bencher, tmpl, Options{})
 
 	// assert
 	bencher.AssertNumberOfCalls(t, "Exec", 1)
 }
+
+func TestRunLatency(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything).Run(func(mock.Arguments) { time.Sleep(time.Millisecond) })
+
+	// act
+	res := Run(context.Background(), bencher, Benchmark{Name: "test", Type: TypeLoop, Stmt: "NONE"}, Options{Iter: 20, Threads: 4})
+
+	// assert
+	require.Equal(t, 20, res.Ops)
+	require.True(t, res.Min >= time.Millisecond)
+	require.True(t, res.Min <= res.P50 && res.P50 <= res.P95 && res.P95 <= res.P99 && res.P99 <= res.Max)
+	require.True(t, res.Mean >= res.Min && res.Mean <= res.Max)
+	require.True(t, res.Duration >= res.Max)
+}
+
+func TestRunErrors(t *testing.T) {
+	testCases := []struct {
+		description  string
+		maxErrorRate float64
+		wantOps      int
+	}{
+		{description: "all iterations", maxErrorRate: 0, wantOps: 100},
+		{description: "stopped", maxErrorRate: 0.1, wantOps: 11},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			// arrange
+			bencher := &mockedBencher{}
+			bencher.On("Exec", mock.Anything).Return(errors.New("failed"))
+			b := Benchmark{Name: "inserts", Type: TypeLoop, Stmt: "INSERT {{.Iter}}"}
+
+			// act
+			result := Run(context.Background(), bencher, b, Options{Iter: 100, Threads: 1, MaxErrorRate: tt.maxErrorRate})
+
+			// assert
+			require.Equal(t, tt.wantOps, result.Ops)
+			require.Equal(t, tt.wantOps, result.Errors)
+			require.Equal(t, 1.0, result.ErrorRate())
+		})
+	}
+}
+
+func TestRunDuration(t *testing.T) {
+	// arrange
+	var (
+		mu    sync.Mutex
+		stmts = map[string]bool{}
+	)
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		stmts[args.String(0)] = true
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+	})
+	b := Benchmark{Name: "inserts", Type: TypeLoop, Stmt: "INSERT {{.Iter}}"}
+
+	// act
+	result := Run(context.Background(), bencher, b, Options{Duration: 50 * time.Millisecond, Threads: 2})
+
+	// assert
+	require.True(t, result.Duration >= 50*time.Millisecond, result.Duration)
+	require.True(t, result.Ops > 10, result.Ops)
+	require.Len(t, stmts, result.Ops, "each iteration once")
+	require.True(t, result.Throughput() > 0)
+}
+
+func TestRunRate(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything)
+	b := Benchmark{Name: "inserts", Type: TypeLoop, Stmt: "INSERT {{.Iter}}"}
+
+	// act
+	result := Run(context.Background(), bencher, b, Options{Iter: 50, Threads: 5, Rate: 500})
+
+	// assert, the first operation starts immediately
+	require.Equal(t, 50, result.Ops)
+	require.True(t, result.Duration >= 98*time.Millisecond, result.Duration)
+	require.True(t, result.Throughput() <= 520, result.Throughput())
+}
+
+// contextBencher blocks each statement after the first ones until it's cancelled.
+type contextBencher struct {
+	mockedBencher
+	fast int64
+}
+
+func (b *contextBencher) ExecContext(ctx context.Context, stmt string) error {
+	if atomic.AddInt64(&b.fast, -1) >= 0 {
+		return nil
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestRunCanceled(t *testing.T) {
+	// arrange
+	bencher := &contextBencher{fast: 5}
+	b := Benchmark{Name: "inserts", Type: TypeLoop, Stmt: "INSERT {{.Iter}}"}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	// act
+	result := Run(ctx, bencher, b, Options{Iter: 100, Threads: 2})
+
+	// assert, the cancelled statements are neither recorded nor failed
+	require.Equal(t, 5, result.Ops)
+	require.Zero(t, result.Errors)
+	bencher.AssertNotCalled(t, "Exec", mock.Anything)
+}
@@ -0,0 +1,164 @@
+package benchmark
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"text/template/parse"
+)
+
+// ArgsExecer is implemented by benchers, which are able to execute statements with bound parameters.
+// It's required for prepared statements, see SetPrepared.
+type ArgsExecer interface {
+	// ExecArgs executes the statement, whose placeholders ? are bound to the arguments.
+	// The statement is cancelled with the context.
+	ExecArgs(ctx context.Context, stmt string, args ...interface{}) error
+}
+
+// prepared is 1, when the values of the template actions are bound as parameters.
+var prepared int32
+
+// SetPrepared sets whether the values of the template actions and the fast placeholders are bound as
+// parameters of prepared statements, instead of being interpolated into the statement text. It has
+// to be set before the benchmarks are run, the statements are parsed accordingly.
+func SetPrepared(enabled bool) {
+	v := int32(0)
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&prepared, v)
+}
+
+func isPrepared() bool {
+	return atomic.LoadInt32(&prepared) == 1
+}
+
+// The values of the template actions are enclosed in these markers, when they're bound as parameters.
+const (
+	argStart = '\x00'
+	argEnd   = '\x01'
+)
+
+// markArgs encloses the output of the actions in the list with markers, recursively. Declarations
+// have no output and the list functions render statement text, whose values are marked by themselves.
+func markArgs(list *parse.ListNode) {
+	if list == nil {
+		return
+	}
+	nodes := make([]parse.Node, 0, len(list.Nodes))
+	for _, n := range list.Nodes {
+		switch n := n.(type) {
+		case *parse.ActionNode:
+			if len(n.Pipe.Decl) == 0 && !isList(n.Pipe) {
+				nodes = append(nodes, marker(argStart), n, marker(argEnd))
+				continue
+			}
+		case *parse.IfNode:
+			markArgs(n.List)
+			markArgs(n.ElseList)
+		case *parse.RangeNode:
+			markArgs(n.List)
+			markArgs(n.ElseList)
+		case *parse.WithNode:
+			markArgs(n.List)
+			markArgs(n.ElseList)
+		}
+		nodes = append(nodes, n)
+	}
+	list.Nodes = nodes
+}
+
+// marker returns a text node of the marker.
+func marker(m byte) *parse.TextNode {
+	return &parse.TextNode{NodeType: parse.NodeText, Text: []byte{m}}
+}
+
+// isList returns whether the pipeline calls one of the listFuncs.
+func isList(pipe *parse.PipeNode) bool {
+	if len(pipe.Cmds) == 0 || len(pipe.Cmds[0].Args) == 0 {
+		return false
+	}
+	field, ok := pipe.Cmds[0].Args[0].(*parse.FieldNode)
+	return ok && len(field.Ident) == 1 && listFuncs[field.Ident[0]]
+}
+
+// bindArgs replaces the marked values of the statement with the placeholder ? and returns them as
+// arguments. Values enclosed in single quotes are bound as strings without the quotes, others as
+// integers or floats when they're numbers.
+func bindArgs(stmt string) (string, []interface{}) {
+	var (
+		sb   strings.Builder
+		args []interface{}
+	)
+	for {
+		start := strings.IndexByte(stmt, argStart)
+		if start < 0 {
+			sb.WriteString(stmt)
+			return sb.String(), args
+		}
+		end := strings.IndexByte(stmt[start:], argEnd) + start
+		value := stmt[start+1 : end]
+		before, after := stmt[:start], stmt[end+1:]
+
+		if strings.HasSuffix(before, "'") && strings.HasPrefix(after, "'") {
+			sb.WriteString(before[:len(before)-1])
+			args = append(args, strings.Replace(value, "''", "'", -1))
+			after = after[1:]
+		} else {
+			sb.WriteString(before)
+			args = append(args, number(value))
+		}
+		sb.WriteByte('?')
+		stmt = after
+	}
+}
+
+// number returns the value as int64 or float64, when it's a number, otherwise as string.
+func number(value string) interface{} {
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+	return value
+}
+
+// unmarkArgs removes the markers, so the values stay interpolated.
+func unmarkArgs(stmt string) string {
+	if !hasArgs(stmt) {
+		return stmt
+	}
+	return strings.Map(func(r rune) rune {
+		if r == argStart || r == argEnd {
+			return -1
+		}
+		return r
+	}, stmt)
+}
+
+func hasArgs(stmt string) bool {
+	return strings.IndexByte(stmt, argStart) >= 0
+}
+
+// execArgs executes the statement. Marked values are bound as parameters, when the bencher supports it,
+// otherwise they're interpolated, e.g. for captured values.
+func execArgs(ctx context.Context, bencher Bencher, stmt string) error {
+	if !hasArgs(stmt) {
+		return execContext(ctx, bencher, stmt)
+	}
+	if e, ok := bencher.(ArgsExecer); ok {
+		query, args := bindArgs(stmt)
+		return e.ExecArgs(ctx, query, args...)
+	}
+	return execContext(ctx, bencher, unmarkArgs(stmt))
+}
+
+// execContext executes the statement, it's cancelled with the context, when the bencher supports it.
+func execContext(ctx context.Context, bencher Bencher, stmt string) error {
+	if e, ok := bencher.(ContextExecer); ok {
+		return e.ExecContext(ctx, stmt)
+	}
+	return bencher.Exec(stmt)
+}
@@ -0,0 +1,82 @@
+package benchmark
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type argsBencher struct {
+	mockedBencher
+}
+
+func (b *argsBencher) ExecArgs(ctx context.Context, stmt string, args ...interface{}) error {
+	return b.Called(stmt, args).Error(0)
+}
+
+func TestBuildPrepared(t *testing.T) {
+	testCases := []struct {
+		description string
+		stmt        string
+		wantQuery   string
+		wantArgs    []interface{}
+	}{
+		{description: "template", stmt: "SELECT * FROM t WHERE id = {{.Iter}} AND v > {{.HashFloat \"v\" 1}}", wantQuery: "SELECT * FROM t WHERE id = ? AND v > ?", wantArgs: []interface{}{int64(7), 0.1220129948637163}},
+		{description: "quoted", stmt: "INSERT INTO t VALUES ({{.Iter}}, '{{.HashText \"n\" .Iter 3}}')", wantQuery: "INSERT INTO t VALUES (?, ?)", wantArgs: []interface{}{int64(7), "kwy"}},
+		{description: "declaration and branches", stmt: "{{$x := 2}}{{if eq $x 2}}DELETE FROM t WHERE id = {{$x}}{{else}}SELECT 1{{end}}", wantQuery: "DELETE FROM t WHERE id = ?", wantArgs: []interface{}{int64(2)}},
+		{description: "rows", stmt: "INSERT INTO t VALUES {{.Rows 2 \"({{.Iter}}, {{.Row}})\"}}", wantQuery: "INSERT INTO t VALUES (?, ?), (?, ?)", wantArgs: []interface{}{int64(7), int64(0), int64(7), int64(1)}},
+		{description: "fast path", stmt: "SELECT * FROM t WHERE id = {iter}", wantQuery: "SELECT * FROM t WHERE id = ?", wantArgs: []interface{}{int64(7)}},
+		{description: "constant", stmt: "SELECT 1", wantQuery: "SELECT 1"},
+	}
+
+	SetPrepared(true)
+	defer SetPrepared(false)
+	SetFastPlaceholders(true)
+	defer SetFastPlaceholders(false)
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			// arrange
+			s, err := parseStmt(tt.stmt)
+			require.NoError(t, err)
+
+			// act
+			query, args := bindArgs(newBuilder(s).build(7))
+
+			// assert
+			require.Equal(t, tt.wantQuery, query)
+			require.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func TestBindArgsUnescapesQuotes(t *testing.T) {
+	// act
+	query, args := bindArgs("SELECT '\x00O''Reilly\x01', \x00x\x01")
+
+	// assert
+	require.Equal(t, "SELECT ?, ?", query)
+	require.Equal(t, []interface{}{"O'Reilly", "x"}, args)
+}
+
+func TestExecArgs(t *testing.T) {
+	// arrange
+	stmt := "SELECT * FROM t WHERE id = \x0042\x01"
+	bencher := &argsBencher{}
+	bencher.On("ExecArgs", "SELECT * FROM t WHERE id = ?", []interface{}{int64(42)}).Return(nil)
+	interpolating := &mockedBencher{}
+	interpolating.On("Exec", "SELECT * FROM t WHERE id = 42").Return(nil)
+
+	// act
+	err := execArgs(context.Background(), bencher, stmt)
+	errInterpolated := execArgs(context.Background(), interpolating, stmt)
+
+	// assert
+	require.NoError(t, err)
+	require.NoError(t, errInterpolated)
+	bencher.AssertExpectations(t)
+	interpolating.AssertExpectations(t)
+	bencher.AssertNotCalled(t, "Exec", mock.Anything)
+}
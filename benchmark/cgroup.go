@@ -0,0 +1,123 @@
+package benchmark
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CgroupStats contains the CPU throttling and memory usage of a cgroup, e.g. of the container
+// running the database. The CPU counters are cumulative, see Sub.
+type CgroupStats struct {
+	Periods       int64         // enforcement periods of the CPU quota
+	Throttled     int64         // periods, in which the cgroup was throttled
+	ThrottledTime time.Duration // total time the cgroup was throttled
+	Memory        int64         // bytes of memory in use
+	MemoryLimit   int64         // bytes of the memory limit, 0 when unlimited
+}
+
+// Sub returns the CPU counters since the earlier stats and the current memory usage.
+func (s CgroupStats) Sub(earlier CgroupStats) CgroupStats {
+	s.Periods -= earlier.Periods
+	s.Throttled -= earlier.Throttled
+	s.ThrottledTime -= earlier.ThrottledTime
+	return s
+}
+
+// ReadCgroup reads the stats of the cgroup directory, e.g. /sys/fs/cgroup/system.slice/docker-<id>.scope.
+// Both cgroup v2 and v1 (the cpu or memory controller directory) are supported.
+func ReadCgroup(dir string) (CgroupStats, error) {
+	var (
+		stats CgroupStats
+		found bool
+	)
+
+	cpu, err := readKeyValues(filepath.Join(dir, "cpu.stat"))
+	if err == nil {
+		found = true
+		stats.Periods = cpu["nr_periods"]
+		stats.Throttled = cpu["nr_throttled"]
+		if usec, ok := cpu["throttled_usec"]; ok {
+			stats.ThrottledTime = time.Duration(usec) * time.Microsecond
+		} else {
+			// cgroup v1
+			stats.ThrottledTime = time.Duration(cpu["throttled_time"])
+		}
+	} else if !os.IsNotExist(err) {
+		return stats, err
+	}
+
+	for _, file := range []string{"memory.current", "memory.usage_in_bytes"} {
+		if v, ok, err := readValue(filepath.Join(dir, file)); err != nil {
+			return stats, err
+		} else if ok {
+			found = true
+			stats.Memory = v
+			break
+		}
+	}
+	for _, file := range []string{"memory.max", "memory.limit_in_bytes"} {
+		if v, ok, err := readValue(filepath.Join(dir, file)); err != nil {
+			return stats, err
+		} else if ok {
+			// cgroup v1 has no "max", but a huge number
+			if v < 1<<62 {
+				stats.MemoryLimit = v
+			}
+			break
+		}
+	}
+
+	if !found {
+		return stats, fmt.Errorf("no cgroup stats found in %v", dir)
+	}
+	return stats, nil
+}
+
+// readKeyValues reads a file with "<key> <value>" lines.
+func readKeyValues(path string) (map[string]int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := map[string]int64{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if v, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			values[fields[0]] = v
+		}
+	}
+	return values, scanner.Err()
+}
+
+// readValue reads a file containing a single number. Missing files and "max" (unlimited) are not ok.
+func readValue(path string) (value int64, ok bool, err error) {
+	dat, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	s := strings.TrimSpace(string(dat))
+	if s == "max" {
+		return 0, false, nil
+	}
+	value, err = strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to parse %v: %v", path, err)
+	}
+	return value, true, nil
+}
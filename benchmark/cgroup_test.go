@@ -0,0 +1,82 @@
+package benchmark
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeCgroup(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+	for name, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	return dir
+}
+
+func TestReadCgroup(t *testing.T) {
+	testCases := []struct {
+		files       map[string]string
+		want        CgroupStats
+		description string
+	}{
+		{
+			files: map[string]string{
+				"cpu.stat":       "usage_usec 100\nnr_periods 40\nnr_throttled 12\nthrottled_usec 1500\n",
+				"memory.current": "1024\n",
+				"memory.max":     "max\n",
+			},
+			want:        CgroupStats{Periods: 40, Throttled: 12, ThrottledTime: 1500 * time.Microsecond, Memory: 1024},
+			description: "v2",
+		},
+		{
+			files: map[string]string{
+				"cpu.stat":              "nr_periods 40\nnr_throttled 12\nthrottled_time 1500\n",
+				"memory.usage_in_bytes": "1024\n",
+				"memory.limit_in_bytes": "2048\n",
+			},
+			want:        CgroupStats{Periods: 40, Throttled: 12, ThrottledTime: 1500, Memory: 1024, MemoryLimit: 2048},
+			description: "v1",
+		},
+		{
+			files:       map[string]string{"memory.current": "1024\n"},
+			want:        CgroupStats{Memory: 1024},
+			description: "memory only",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			// arrange
+			dir := writeCgroup(t, tt.files)
+			defer os.RemoveAll(dir)
+
+			// act
+			got, err := ReadCgroup(dir)
+
+			// assert
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReadCgroupMissing(t *testing.T) {
+	dir := writeCgroup(t, nil)
+	defer os.RemoveAll(dir)
+
+	_, err := ReadCgroup(dir)
+	require.Error(t, err)
+}
+
+func TestCgroupStatsSub(t *testing.T) {
+	earlier := CgroupStats{Periods: 10, Throttled: 2, ThrottledTime: time.Second, Memory: 100}
+	later := CgroupStats{Periods: 40, Throttled: 12, ThrottledTime: 3 * time.Second, Memory: 50, MemoryLimit: 200}
+
+	require.Equal(t, CgroupStats{Periods: 30, Throttled: 10, ThrottledTime: 2 * time.Second, Memory: 50, MemoryLimit: 200}, later.Sub(earlier))
+}
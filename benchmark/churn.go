@@ -0,0 +1,156 @@
+package benchmark
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ChurnOptions configures a churn workload, see Churn.
+type ChurnOptions struct {
+	Insert   Benchmark             // inserts the row of the iteration counter
+	Delete   Benchmark             // deletes the row of the iteration counter
+	Rows     int                   // live rows, the row of iteration i is deleted after inserting row i + Rows
+	Rate     float64               // inserts per second, each followed by a delete (0 -> unlimited)
+	Threads  int                   // number of concurrent routines
+	Duration time.Duration         // of the churn, excluding the initial inserts of the live rows
+	Interval time.Duration         // between the samples
+	Size     func() (int64, error) // returns the bytes used by the table, nil when unknown
+}
+
+// ChurnSample contains the measurements of an interval of a churn workload.
+type ChurnSample struct {
+	Offset    time.Duration // end of the interval since the start of the churn
+	Inserts   int           // inserted and deleted rows
+	Errors    int           // failed statements
+	InsertP99 time.Duration
+	DeleteP99 time.Duration
+	Size      int64 // bytes used by the table, -1 when unknown
+}
+
+// Churn inserts the live rows and then inserts new rows and deletes the oldest ones at the same rate
+// for the duration, like a queue table or rows expiring after a TTL. The number of rows stays constant,
+// so the latencies and the table size should be stable, unless the deleted rows aren't cleaned up
+// in time. Each sample is passed to report at the end of its interval. The churn stops early,
+// when the context is cancelled.
+func Churn(ctx context.Context, bencher Bencher, opts ChurnOptions, report func(ChurnSample)) []ChurnSample {
+	insert, err := parseStmt(opts.Insert.Stmt)
+	if err != nil {
+		log.Fatalf("failed to parse template: %v", err)
+	}
+	del, err := parseStmt(opts.Delete.Stmt)
+	if err != nil {
+		log.Fatalf("failed to parse template: %v", err)
+	}
+
+	loop(ctx, bencher, insert, Options{Iter: opts.Rows, Threads: opts.Threads})
+
+	var (
+		next    = int64(opts.Rows) // last inserted row, shared by all routines
+		limit   = newLimiter(opts.Rate)
+		wg      = &sync.WaitGroup{}
+		done    = make(chan struct{})
+		samples []ChurnSample
+
+		// measurements of the current interval
+		mu               sync.Mutex
+		inserts, deletes []time.Duration
+		errors           int
+	)
+
+	wg.Add(opts.Threads)
+	for routine := 0; routine < opts.Threads; routine++ {
+		go func(routine int) {
+			defer wg.Done()
+
+			insertBuilder, delBuilder := newBuilder(insert), newBuilder(del)
+			for _, b := range []*builder{insertBuilder, delBuilder} {
+				b.setThread(routine)
+				b.data.Threads = opts.Threads
+			}
+
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				limit.wait()
+
+				i := int(atomic.AddInt64(&next, 1))
+				insertBuilder.data.Op = i
+				insertTook, insertErr := execute(ctx, bencher, insertBuilder.build(i))
+				delBuilder.data.Op = i
+				delTook, delErr := execute(ctx, bencher, delBuilder.build(i-opts.Rows))
+				if canceled(ctx, insertErr) || canceled(ctx, delErr) {
+					return
+				}
+
+				mu.Lock()
+				inserts = append(inserts, insertTook)
+				deletes = append(deletes, delTook)
+				for _, err := range []error{insertErr, delErr} {
+					if err != nil {
+						errors++
+					}
+				}
+				mu.Unlock()
+			}
+		}(routine)
+	}
+
+	sample := func(offset time.Duration) {
+		mu.Lock()
+		s := ChurnSample{
+			Offset:    offset,
+			Inserts:   len(inserts),
+			Errors:    errors,
+			InsertP99: percentile(inserts, 0.99),
+			DeleteP99: percentile(deletes, 0.99),
+			Size:      -1,
+		}
+		inserts, deletes, errors = nil, nil, 0
+		mu.Unlock()
+
+		if opts.Size != nil {
+			size, err := opts.Size()
+			if err != nil {
+				log.Printf("failed to get table size: %v\n", err)
+			} else {
+				s.Size = size
+			}
+		}
+		samples = append(samples, s)
+		report(s)
+	}
+
+	var (
+		start    = time.Now()
+		ticker   = time.NewTicker(opts.Interval)
+		deadline = time.After(opts.Duration)
+	)
+	defer ticker.Stop()
+
+	for running := true; running; {
+		select {
+		case <-ticker.C:
+			if offset := time.Since(start); offset < opts.Duration {
+				sample(offset)
+				continue
+			}
+			running = false
+		case <-deadline:
+			running = false
+		case <-ctx.Done():
+			running = false
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	// the remainder of the last interval
+	sample(time.Since(start))
+	return samples
+}
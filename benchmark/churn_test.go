@@ -0,0 +1,48 @@
+package benchmark
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChurn(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything)
+	opts := ChurnOptions{
+		Insert:   Benchmark{Name: "inserts", Stmt: "INSERT {{.Iter}}"},
+		Delete:   Benchmark{Name: "deletes", Stmt: "DELETE {{.Iter}}"},
+		Rows:     10,
+		Rate:     500,
+		Threads:  2,
+		Duration: 100 * time.Millisecond,
+		Interval: 50 * time.Millisecond,
+		Size:     func() (int64, error) { return 4096, nil },
+	}
+	var reported []ChurnSample
+
+	// act
+	samples := Churn(context.Background(), bencher, opts, func(s ChurnSample) { reported = append(reported, s) })
+
+	// assert
+	require.Equal(t, samples, reported)
+	require.Len(t, samples, 2)
+	inserts := 0
+	for _, s := range samples {
+		inserts += s.Inserts
+		require.Equal(t, int64(4096), s.Size)
+		require.Equal(t, 0, s.Errors)
+	}
+	require.InDelta(t, 50, inserts, 5)
+
+	// the live rows are inserted first, then the oldest row is deleted after each insert
+	bencher.AssertCalled(t, "Exec", "INSERT 10")
+	bencher.AssertCalled(t, "Exec", "INSERT 11")
+	bencher.AssertCalled(t, "Exec", "DELETE 1")
+	bencher.AssertNotCalled(t, "Exec", "DELETE 0")
+	bencher.AssertNumberOfCalls(t, "Exec", 10+2*inserts)
+}
@@ -0,0 +1,8 @@
+package benchmark
+
+import "time"
+
+// cpuTime returns the CPU time of the process, which isn't measured on windows yet.
+func cpuTime() time.Duration {
+	return 0
+}
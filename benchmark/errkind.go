@@ -0,0 +1,95 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+)
+
+// Kinds of the errors of the failed statements, see ErrorKind.
+const (
+	ErrKindConnection = "connection" // the connection failed or was refused
+	ErrKindTimeout    = "timeout"    // a timeout of the driver or the database
+	ErrKindConflict   = "conflict"   // a deadlock, lock timeout or serialization failure
+	ErrKindConstraint = "constraint" // a violated unique or foreign key constraint
+	ErrKindStatement  = "statement"  // a syntax error or a missing table or column
+	ErrKindResult     = "result"     // an unexpected result, see Benchmark.Expect
+	ErrKindOther      = "other"
+)
+
+// errorKindPatterns contains lowercase parts of the error messages of the drivers by kind,
+// the first matching kind wins.
+var errorKindPatterns = []struct {
+	kind     string
+	patterns []string
+}{
+	{ErrKindResult, []string{"unexpected result"}},
+	{ErrKindConflict, []string{"deadlock", "could not serialize", "serialization failure", "lock wait timeout", "restart transaction", "database is locked", "write conflict"}},
+	{ErrKindTimeout, []string{"timeout", "timed out", "deadline exceeded", "canceling statement"}},
+	{ErrKindConnection, []string{"connection refused", "connection reset", "broken pipe", "too many connections", "too many clients", "bad connection", "no such host", "eof"}},
+	{ErrKindConstraint, []string{"duplicate", "unique", "constraint", "foreign key"}},
+	{ErrKindStatement, []string{"syntax", "unrecognized token", "does not exist", "doesn't exist", "no such table", "no such column", "unknown column", "invalid object name", "undefined"}},
+}
+
+// ErrorKind returns the kind of the error of a failed statement by its message, e.g. ErrKindConflict.
+func ErrorKind(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrKindTimeout
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrKindTimeout
+	case errors.As(err, &netErr):
+		return ErrKindConnection
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, k := range errorKindPatterns {
+		for _, p := range k.patterns {
+			if strings.Contains(msg, p) {
+				return k.kind
+			}
+		}
+	}
+	return ErrKindOther
+}
+
+// errorKinds counts the failed statements of the running benchmark by their kind.
+var errorKinds = &errorCounter{}
+
+type errorCounter struct {
+	mu    sync.Mutex
+	kinds map[string]int
+}
+
+// reset starts counting.
+func (c *errorCounter) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.kinds = map[string]int{}
+}
+
+// add counts a failed statement.
+func (c *errorCounter) add(err error) {
+	kind := ErrorKind(err)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.kinds == nil {
+		return
+	}
+	c.kinds[kind]++
+}
+
+// take returns the counts and stops counting, nil without failed statements.
+func (c *errorCounter) take() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	kinds := c.kinds
+	c.kinds = nil
+	if len(kinds) == 0 {
+		return nil
+	}
+	return kinds
+}
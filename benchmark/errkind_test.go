@@ -0,0 +1,59 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorKind(t *testing.T) {
+	testCases := []struct {
+		description string
+		err         error
+		want        string
+	}{
+		{description: "deadlock", err: errors.New("pq: deadlock detected"), want: ErrKindConflict},
+		{description: "serialization", err: errors.New("ERROR: could not serialize access due to concurrent update (SQLSTATE 40001)"), want: ErrKindConflict},
+		{description: "lock wait", err: errors.New("Error 1205: Lock wait timeout exceeded; try restarting transaction"), want: ErrKindConflict},
+		{description: "sqlite busy", err: errors.New("database is locked"), want: ErrKindConflict},
+		{description: "statement timeout", err: errors.New("pq: canceling statement due to statement timeout"), want: ErrKindTimeout},
+		{description: "context deadline", err: fmt.Errorf("query: %w", context.DeadlineExceeded), want: ErrKindTimeout},
+		{description: "refused", err: errors.New("dial tcp 127.0.0.1:5432: connect: connection refused"), want: ErrKindConnection},
+		{description: "net error", err: &net.OpError{Op: "read", Err: errors.New("reset")}, want: ErrKindConnection},
+		{description: "too many", err: errors.New("Error 1040: Too many connections"), want: ErrKindConnection},
+		{description: "duplicate", err: errors.New(`pq: duplicate key value violates unique constraint "users_pkey"`), want: ErrKindConstraint},
+		{description: "syntax", err: errors.New(`pq: syntax error at or near "SELEC"`), want: ErrKindStatement},
+		{description: "missing table", err: errors.New("no such table: dbbench_simple"), want: ErrKindStatement},
+		{description: "unexpected result", err: errors.New("unexpected result: 0 rows, expected rows=1"), want: ErrKindResult},
+		{description: "other", err: errors.New("something went wrong"), want: ErrKindOther},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			require.Equal(t, tt.want, ErrorKind(tt.err))
+		})
+	}
+}
+
+func TestRunErrorKinds(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", "INSERT 1").Return(errors.New("deadlock detected"))
+	bencher.On("Exec", "INSERT 2").Return(errors.New("duplicate key"))
+	bencher.On("Exec", "INSERT 3").Return(errors.New("duplicate key"))
+	bencher.On("Exec", mock.Anything).Return(nil)
+	b := Benchmark{Name: "inserts", Type: TypeLoop, Stmt: "INSERT {{.Iter}}"}
+
+	// act
+	result := Run(context.Background(), bencher, b, Options{Iter: 5, Threads: 1})
+	ok := Run(context.Background(), bencher, Benchmark{Name: "selects", Type: TypeLoop, Stmt: "SELECT {{.Iter}}"}, Options{Iter: 5, Threads: 1})
+
+	// assert
+	require.Equal(t, map[string]int{ErrKindConflict: 1, ErrKindConstraint: 2}, result.ErrorKinds)
+	require.Nil(t, ok.ErrorKinds)
+}
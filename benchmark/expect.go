@@ -0,0 +1,78 @@
+package benchmark
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// expectation is the expected result of the statements of a benchmark, see Benchmark.Expect.
+type expectation struct {
+	raw   string
+	what  string // "rows" or "value"
+	op    string // "=", "!=", ">", ">=", "<" or "<="
+	rows  int
+	value string
+}
+
+// expectPattern matches the expectations, e.g. rows=1, rows>=10 or value=42.
+var expectPattern = regexp.MustCompile(`^(rows)(=|!=|>=|<=|>|<)(\d+)$|^(value)(=|!=)(.*)$`)
+
+// parseExpect parses the expectation of the result of a statement: the number of returned rows
+// compared with =, !=, >, >=, < or <= to a number, or the first column of the first row compared
+// with = or != to a value.
+func parseExpect(s string) (*expectation, error) {
+	m := expectPattern.FindStringSubmatch(s)
+	if m == nil {
+		return nil, fmt.Errorf("invalid expectation %q, e.g. rows=1, rows>0 or value=42", s)
+	}
+	if m[1] == "rows" {
+		n, err := strconv.Atoi(m[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid number of rows in expectation %q", s)
+		}
+		return &expectation{raw: s, what: "rows", op: m[2], rows: n}, nil
+	}
+	return &expectation{raw: s, what: "value", op: m[5], value: m[6]}, nil
+}
+
+// ValidateExpect returns an error when the expectation of Benchmark.Expect is invalid.
+func ValidateExpect(s string) error {
+	_, err := parseExpect(s)
+	return err
+}
+
+// check returns an error when the result, the first column of the returned rows, isn't the expected one.
+func (e *expectation) check(result []string) error {
+	if e.what == "value" {
+		got := "<no rows>"
+		if len(result) > 0 {
+			got = result[0]
+		}
+		if (got == e.value) != (e.op == "=") {
+			return fmt.Errorf("unexpected result: value %v, expected %v", got, e.raw)
+		}
+		return nil
+	}
+
+	n := len(result)
+	var ok bool
+	switch e.op {
+	case "=":
+		ok = n == e.rows
+	case "!=":
+		ok = n != e.rows
+	case ">":
+		ok = n > e.rows
+	case ">=":
+		ok = n >= e.rows
+	case "<":
+		ok = n < e.rows
+	case "<=":
+		ok = n <= e.rows
+	}
+	if !ok {
+		return fmt.Errorf("unexpected result: %v rows, expected %v", n, e.raw)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpectation(t *testing.T) {
+	testCases := []struct {
+		description string
+		expect      string
+		result      []string
+		ok          bool
+	}{
+		{description: "rows equal", expect: "rows=1", result: []string{"a"}, ok: true},
+		{description: "rows not equal", expect: "rows=1", result: nil, ok: false},
+		{description: "rows greater", expect: "rows>0", result: []string{"a", "b"}, ok: true},
+		{description: "rows not greater", expect: "rows>0", result: nil, ok: false},
+		{description: "rows at least", expect: "rows>=2", result: []string{"a", "b"}, ok: true},
+		{description: "rows less", expect: "rows<2", result: []string{"a", "b"}, ok: false},
+		{description: "rows at most", expect: "rows<=2", result: []string{"a", "b"}, ok: true},
+		{description: "rows different", expect: "rows!=0", result: []string{"a"}, ok: true},
+		{description: "value", expect: "value=42", result: []string{"42", "7"}, ok: true},
+		{description: "wrong value", expect: "value=42", result: []string{"7"}, ok: false},
+		{description: "value without rows", expect: "value=42", result: nil, ok: false},
+		{description: "other value", expect: "value!=0", result: []string{"7"}, ok: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			// arrange
+			e, err := parseExpect(tc.expect)
+			require.NoError(t, err)
+
+			// act
+			err = e.check(tc.result)
+
+			// assert
+			require.Equal(t, tc.ok, err == nil, err)
+		})
+	}
+}
+
+func TestParseExpectInvalid(t *testing.T) {
+	for _, s := range []string{"", "rows", "rows=", "rows=-1", "rows~1", "value>1", "count=1"} {
+		require.Error(t, ValidateExpect(s), s)
+	}
+}
+
+func TestExpect(t *testing.T) {
+	// arrange
+	bencher := &mockedQuerier{}
+	bencher.On("Query", "SELECT 1").Return([]string{"1"}, nil)
+	bencher.On("Query", "SELECT 2").Return([]string{}, nil)
+	b := Benchmark{Name: "selects", Type: TypeLoop, Stmt: "SELECT {iter}", Expect: "rows=1"}
+	SetFastPlaceholders(true)
+	defer SetFastPlaceholders(false)
+
+	// act
+	result := Run(context.Background(), bencher, b, Options{Iter: 2, Threads: 1})
+
+	// assert
+	require.Equal(t, 1, result.Errors)
+	require.Equal(t, map[string]int{ErrKindResult: 1}, result.ErrorKinds)
+	bencher.AssertNotCalled(t, "Exec", mock.Anything)
+}
+
+func TestExpectQueryError(t *testing.T) {
+	// arrange
+	delete(pools.m, "test_expect_error")
+	bencher := &mockedQuerier{}
+	bencher.On("Query", "DELETE FROM missing").Return([]string(nil), errors.New("no such table: missing"))
+	b := Benchmark{Name: "deletes", Type: TypeLoop, Stmt: "DELETE FROM missing", Expect: "rows=0", Capture: "test_expect_error"}
+
+	// act
+	result := Run(context.Background(), bencher, b, Options{Iter: 1, Threads: 1})
+
+	// assert
+	require.Equal(t, 1, result.Errors)
+	require.Equal(t, map[string]int{ErrKindStatement: 1}, result.ErrorKinds)
+	require.Empty(t, pools.m["test_expect_error"])
+}
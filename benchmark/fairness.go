@@ -0,0 +1,110 @@
+package benchmark
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// FairnessSample contains the throughput of the benchmarks, which ran concurrently during an interval.
+type FairnessSample struct {
+	Elapsed    time.Duration      // since the start of the fairness, at the end of the interval
+	Throughput map[string]float64 // operations per second of each benchmark
+	Index      float64            // Jain's fairness index of the throughputs
+}
+
+// Fairness samples how the throughput is shared by the concurrently running benchmarks,
+// e.g. parallel benchmarks interfering with each other.
+type Fairness struct {
+	interval time.Duration
+	samples  []FairnessSample
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// StartFairness samples the throughput every interval, until Stop is called.
+func StartFairness(interval time.Duration) *Fairness {
+	atomic.AddInt32(&metrics.users, 1)
+	f := &Fairness{
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go f.run()
+	return f
+}
+
+// Stop stops the sampling and returns the samples of the intervals with at least two benchmarks
+// running during the whole interval. The last partial interval isn't sampled.
+func (f *Fairness) Stop() []FairnessSample {
+	close(f.stop)
+	<-f.done
+	atomic.AddInt32(&metrics.users, -1)
+	return f.samples
+}
+
+func (f *Fairness) run() {
+	defer close(f.done)
+
+	var (
+		start  = time.Now()
+		last   = start
+		prev   = fairnessSnapshot()
+		ticker = time.NewTicker(f.interval)
+	)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.stop:
+			return
+		case now := <-ticker.C:
+			cur := fairnessSnapshot()
+			elapsed := now.Sub(last).Seconds()
+			throughput := map[string]float64{}
+			for name, m := range cur {
+				p, ok := prev[name]
+				// running at the start and the end of the interval
+				if !ok || p.Workers == 0 || m.Workers == 0 {
+					continue
+				}
+				ops := m.Ops - p.Ops
+				if ops < 0 {
+					// reset by SetMetrics
+					ops = m.Ops
+				}
+				throughput[name] = float64(ops) / elapsed
+			}
+			if len(throughput) > 1 {
+				values := make([]float64, 0, len(throughput))
+				for _, v := range throughput {
+					values = append(values, v)
+				}
+				f.samples = append(f.samples, FairnessSample{Elapsed: now.Sub(start), Throughput: throughput, Index: JainIndex(values)})
+			}
+			prev, last = cur, now
+		}
+	}
+}
+
+// fairnessSnapshot returns the current metrics by benchmark name.
+func fairnessSnapshot() map[string]BenchmarkMetrics {
+	snapshot := map[string]BenchmarkMetrics{}
+	for _, m := range Metrics() {
+		snapshot[m.Name] = m
+	}
+	return snapshot
+}
+
+// JainIndex returns Jain's fairness index of the values, from 1/n when a single value got everything
+// to 1 when all values are equal. It's 1 without values.
+func JainIndex(values []float64) float64 {
+	var sum, squares float64
+	for _, v := range values {
+		sum += v
+		squares += v * v
+	}
+	if squares == 0 {
+		return 1
+	}
+	return sum * sum / (float64(len(values)) * squares)
+}
@@ -0,0 +1,69 @@
+package benchmark
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJainIndex(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   float64
+	}{
+		{values: nil, want: 1},
+		{values: []float64{100, 100}, want: 1},
+		{values: []float64{100, 0}, want: 0.5},
+		{values: []float64{100, 0, 0, 0}, want: 0.25},
+		{values: []float64{300, 100}, want: 0.8},
+	}
+	for _, tt := range tests {
+		// act
+		got := JainIndex(tt.values)
+
+		// assert
+		require.InDelta(t, tt.want, got, 0.0001, tt.values)
+	}
+}
+
+func TestFairness(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything).Run(func(args mock.Arguments) {
+		if strings.HasPrefix(args.String(0), "SLOW") {
+			time.Sleep(4 * time.Millisecond)
+			return
+		}
+		time.Sleep(time.Millisecond)
+	})
+	fast := Benchmark{Name: "fast", Type: TypeLoop, Stmt: "FAST {{.Iter}}", Parallel: true}
+	slow := Benchmark{Name: "slow", Type: TypeLoop, Stmt: "SLOW {{.Iter}}", Parallel: true}
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+
+	// act
+	f := StartFairness(10 * time.Millisecond)
+	go func() {
+		defer wg.Done()
+		Run(context.Background(), bencher, fast, Options{Duration: 60 * time.Millisecond, Threads: 2})
+	}()
+	go func() {
+		defer wg.Done()
+		Run(context.Background(), bencher, slow, Options{Duration: 60 * time.Millisecond, Threads: 1})
+	}()
+	wg.Wait()
+	samples := f.Stop()
+
+	// assert, the fast benchmark gets most of the throughput
+	require.True(t, len(samples) >= 3, len(samples))
+	for _, s := range samples {
+		require.Len(t, s.Throughput, 2)
+		require.True(t, s.Throughput["fast"] > s.Throughput["slow"], s)
+		require.True(t, s.Index > 0.5 && s.Index < 0.95, s)
+	}
+}
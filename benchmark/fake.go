@@ -0,0 +1,42 @@
+package benchmark
+
+import (
+	"strings"
+
+	"github.com/brianvoe/gofakeit/v6"
+)
+
+// fake returns the fake data generator of the builder. Each builder has its own, so the threads
+// don't contend for the lock of a shared random source.
+func (d *tmplData) fake() *gofakeit.Faker {
+	if d.faker == nil {
+		// draws from the random number generator of the worker
+		d.faker = gofakeit.NewCustom(d.random())
+	}
+	return d.faker
+}
+
+// quote escapes the single quotes of a fake value, so it can be used in a string literal.
+func quote(s string) string {
+	return strings.Replace(s, "'", "''", -1)
+}
+
+// FakeName returns a random person name, e.g. "Markus Moen".
+func (d *tmplData) FakeName() string {
+	return quote(d.fake().Name())
+}
+
+// FakeEmail returns a random email address, e.g. "markusmoen@pagac.net".
+func (d *tmplData) FakeEmail() string {
+	return quote(d.fake().Email())
+}
+
+// FakeAddress returns a random postal address, e.g. "364 Unionsville, Apt. 123, Norfolk, Ohio 99536".
+func (d *tmplData) FakeAddress() string {
+	return quote(d.fake().Address().Address)
+}
+
+// FakeText returns a random sentence of n words.
+func (d *tmplData) FakeText(n int) string {
+	return quote(d.fake().Sentence(n))
+}
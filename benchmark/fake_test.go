@@ -0,0 +1,31 @@
+package benchmark
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFake(t *testing.T) {
+	// arrange
+	s, err := parseStmt("INSERT INTO users VALUES ('{{.FakeName}}', '{{.FakeEmail}}', '{{.FakeAddress}}', '{{.FakeText 5}}');")
+	require.NoError(t, err)
+	b := newBuilder(s)
+
+	// act
+	stmt := b.build(1)
+
+	// assert
+	values := strings.Split(strings.TrimSuffix(strings.TrimPrefix(stmt, "INSERT INTO users VALUES ('"), "');"), "', '")
+	require.Len(t, values, 4, stmt)
+	require.Contains(t, values[1], "@")
+	require.Len(t, strings.Fields(values[3]), 5)
+	for _, v := range values {
+		require.NotEmpty(t, v)
+	}
+}
+
+func TestFakeQuote(t *testing.T) {
+	require.Equal(t, "O''Reilly", quote("O'Reilly"))
+}
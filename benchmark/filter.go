@@ -0,0 +1,60 @@
+package benchmark
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Filter selects the benchmarks by their names and tags.
+type Filter struct {
+	Run  string   // space separated regular expressions, one of them has to match the whole name ("all" or empty -> all)
+	Skip string   // space separated regular expressions, the benchmarks matching one of them are skipped
+	Tags []string // the benchmarks need one of the tags (empty -> all)
+}
+
+// Select returns the benchmarks matching the filter, in their order.
+func Select(benchmarks []Benchmark, f Filter) ([]Benchmark, error) {
+	run, err := namePattern(f.Run)
+	if err != nil {
+		return nil, err
+	}
+	skip, err := namePattern(f.Skip)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []Benchmark
+	for _, b := range benchmarks {
+		if run != nil && !run.MatchString(b.Name) {
+			continue
+		}
+		if skip != nil && skip.MatchString(b.Name) {
+			continue
+		}
+		if len(f.Tags) > 0 && !b.HasTag(f.Tags) {
+			continue
+		}
+		selected = append(selected, b)
+	}
+	return selected, nil
+}
+
+// namePattern returns the regular expression matching the whole names, which match one of the
+// space separated expressions, nil when all names match. Thus plain names only match themselves,
+// e.g. "inserts" doesn't match "fdw_inserts".
+func namePattern(s string) (*regexp.Regexp, error) {
+	exprs := strings.Fields(s)
+	if len(exprs) == 0 {
+		return nil, nil
+	}
+	for _, e := range exprs {
+		if e == "all" {
+			return nil, nil
+		}
+		if _, err := regexp.Compile(e); err != nil {
+			return nil, fmt.Errorf("invalid benchmark pattern %q: %v", e, err)
+		}
+	}
+	return regexp.Compile("^(?:" + strings.Join(exprs, "|") + ")$")
+}
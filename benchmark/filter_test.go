@@ -0,0 +1,51 @@
+package benchmark
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelect(t *testing.T) {
+	benchmarks := []Benchmark{
+		{Name: "inserts", Tags: []string{TagWrite}},
+		{Name: "selects", Tags: []string{TagRead}},
+		{Name: "fdw_inserts", Tags: []string{TagWrite}},
+		{Name: "fdw_selects", Tags: []string{TagRead}},
+	}
+
+	testCases := []struct {
+		description string
+		filter      Filter
+		want        []string
+	}{
+		{description: "all", filter: Filter{Run: "all"}, want: []string{"inserts", "selects", "fdw_inserts", "fdw_selects"}},
+		{description: "empty", filter: Filter{}, want: []string{"inserts", "selects", "fdw_inserts", "fdw_selects"}},
+		{description: "names", filter: Filter{Run: "inserts selects"}, want: []string{"inserts", "selects"}},
+		{description: "regex", filter: Filter{Run: "fdw_.*"}, want: []string{"fdw_inserts", "fdw_selects"}},
+		{description: "skip", filter: Filter{Run: "all", Skip: "fdw_.*"}, want: []string{"inserts", "selects"}},
+		{description: "run and skip", filter: Filter{Run: ".*selects", Skip: "fdw_selects"}, want: []string{"selects"}},
+		{description: "tags", filter: Filter{Run: ".*inserts .*selects", Tags: []string{TagRead}}, want: []string{"selects", "fdw_selects"}},
+		{description: "none", filter: Filter{Run: "updates"}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			// act
+			selected, err := Select(benchmarks, tt.filter)
+
+			// assert
+			require.NoError(t, err)
+			var names []string
+			for _, b := range selected {
+				names = append(names, b.Name)
+			}
+			require.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func TestSelectInvalidPattern(t *testing.T) {
+	_, err := Select([]Benchmark{{Name: "inserts"}}, Filter{Skip: "inserts("})
+	require.Error(t, err)
+}
@@ -0,0 +1,87 @@
+package benchmark
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// funcs contains the template functions added by the scripts and by Go code.
+var funcs = struct {
+	sync.RWMutex
+	defined    map[string]*template.Template // defined with \func, called with .Func
+	bodies     map[string]string             // of the defined functions, see DefinedFuncs
+	registered template.FuncMap              // registered with RegisterFuncs, called by their name
+}{defined: map[string]*template.Template{}, bodies: map[string]string{}, registered: template.FuncMap{}}
+
+// DefineFunc defines a function, which can be called in the statement templates with
+// {{.Func "name" args...}}, e.g. by the \func lines of the scripts. The body is a template
+// with the same variables and functions as the statements and the arguments as .Args.
+// A function with the same name is replaced.
+func DefineFunc(name, body string) error {
+	t, err := template.New(name).Funcs(registeredFuncs()).Parse(body)
+	if err != nil {
+		return fmt.Errorf("failed to parse function %v: %v", name, err)
+	}
+	funcs.Lock()
+	defer funcs.Unlock()
+	funcs.defined[name] = t
+	funcs.bodies[name] = body
+	return nil
+}
+
+// DefinedFuncs returns the bodies of the functions defined with DefineFunc (name -> body).
+func DefinedFuncs() map[string]string {
+	funcs.RLock()
+	defer funcs.RUnlock()
+	m := make(map[string]string, len(funcs.bodies))
+	for name, body := range funcs.bodies {
+		m[name] = body
+	}
+	return m
+}
+
+// RegisterFuncs registers Go functions, which can be called by their name in the statement
+// templates, e.g. {{orderID .Iter}}. They have to be registered before the scripts are parsed,
+// e.g. in the init function of a file added to a custom build of dbbench.
+func RegisterFuncs(m template.FuncMap) {
+	funcs.Lock()
+	defer funcs.Unlock()
+	for name, fn := range m {
+		funcs.registered[name] = fn
+	}
+}
+
+// registeredFuncs returns the functions registered with RegisterFuncs.
+func registeredFuncs() template.FuncMap {
+	funcs.RLock()
+	defer funcs.RUnlock()
+	m := make(template.FuncMap, len(funcs.registered))
+	for name, fn := range funcs.registered {
+		m[name] = fn
+	}
+	return m
+}
+
+// funcData is the data of the defined functions, the data of the statement with the arguments.
+type funcData struct {
+	*tmplData
+	Args []interface{}
+}
+
+// Func returns the output of the function defined with DefineFunc, e.g. by \func.
+func (d *tmplData) Func(name string, args ...interface{}) (string, error) {
+	funcs.RLock()
+	t, ok := funcs.defined[name]
+	funcs.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("function %q is not defined", name)
+	}
+
+	sb := &strings.Builder{}
+	if err := t.Execute(sb, funcData{tmplData: d, Args: args}); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
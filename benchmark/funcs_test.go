@@ -0,0 +1,75 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefineFunc(t *testing.T) {
+	// arrange
+	require.NoError(t, DefineFunc("test_order_id", `ORD-{{printf "%05d" (index .Args 0)}}-{{.Thread}}`))
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything)
+	b := Benchmark{Name: "orders", Type: TypeLoop, Stmt: `INSERT '{{.Func "test_order_id" .Iter}}'`}
+
+	// act
+	Run(context.Background(), bencher, b, Options{Iter: 2, Threads: 1})
+
+	// assert
+	bencher.AssertCalled(t, "Exec", "INSERT 'ORD-00001-0'")
+	bencher.AssertCalled(t, "Exec", "INSERT 'ORD-00002-0'")
+}
+
+func TestDefineFuncInvalid(t *testing.T) {
+	require.Error(t, DefineFunc("test_invalid", "{{.Iter"))
+}
+
+func TestFuncUndefined(t *testing.T) {
+	// arrange
+	d := &tmplData{}
+
+	// act
+	_, err := d.Func("test_undefined")
+
+	// assert
+	require.Error(t, err)
+}
+
+func TestRegisterFuncs(t *testing.T) {
+	// arrange
+	RegisterFuncs(template.FuncMap{
+		"testSKU": func(n int) string { return fmt.Sprintf("SKU-%v", n*10) },
+	})
+	require.NoError(t, DefineFunc("test_sku", "{{testSKU (index .Args 0)}}"))
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything)
+	b := Benchmark{Name: "skus", Type: TypeOnce, Stmt: `SELECT '{{testSKU .Iter}}', '{{.Func "test_sku" 2}}'`}
+
+	// act
+	Run(context.Background(), bencher, b, Options{})
+
+	// assert
+	bencher.AssertCalled(t, "Exec", "SELECT 'SKU-10', 'SKU-20'")
+}
+
+func TestRegisterFuncsPrepared(t *testing.T) {
+	// arrange
+	RegisterFuncs(template.FuncMap{"testUpper": strings.ToUpper})
+	SetPrepared(true)
+	defer SetPrepared(false)
+	s, err := parseStmt(`SELECT {{testUpper "abc"}}`)
+	require.NoError(t, err)
+
+	// act
+	query, args := bindArgs(newBuilder(s).build(1))
+
+	// assert, the output is bound as parameter
+	require.Equal(t, "SELECT ?", query)
+	require.Equal(t, []interface{}{"ABC"}, args)
+}
@@ -0,0 +1,44 @@
+package benchmark
+
+import "hash/fnv"
+
+// Hash returns a pseudo-random value derived from the name and the key, e.g. of a column and
+// a row. It's the same for each run, so reads can predict the values written by earlier runs.
+func Hash(name string, key int64) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+
+	// splitmix64 finalizer, so consecutive keys give unrelated values
+	x := h.Sum64() ^ uint64(key)*0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// HashInt returns the deterministic int64 in [0, n) of the name and key, see Hash.
+func (d *tmplData) HashInt(name string, key int, n int64) int64 {
+	if n <= 0 {
+		return 0
+	}
+	return int64(Hash(name, int64(key)) % uint64(n))
+}
+
+// HashFloat returns the deterministic float64 in [0.0, 1.0) of the name and key, see Hash.
+func (d *tmplData) HashFloat(name string, key int) float64 {
+	return float64(Hash(name, int64(key))>>11) / (1 << 53)
+}
+
+// HashText returns the deterministic text of n lowercase letters of the name and key, see Hash.
+func (d *tmplData) HashText(name string, key, n int) string {
+	b := make([]byte, n)
+	x := Hash(name, int64(key))
+	for i := range b {
+		// 13 letters per hash value
+		if i > 0 && i%13 == 0 {
+			x = Hash(name, int64(key)^int64(i))
+		}
+		b[i] = 'a' + byte(x%26)
+		x /= 26
+	}
+	return string(b)
+}
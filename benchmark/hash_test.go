@@ -0,0 +1,32 @@
+package benchmark
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHash(t *testing.T) {
+	require.Equal(t, Hash("balance", 1), Hash("balance", 1))
+	require.NotEqual(t, Hash("balance", 1), Hash("balance", 2))
+	require.NotEqual(t, Hash("balance", 1), Hash("amount", 1))
+}
+
+func TestHashTemplate(t *testing.T) {
+	// arrange
+	s, err := parseStmt(`{{.HashInt "balance" .Iter 1000}} {{.HashFloat "score" .Iter}} '{{.HashText "name" .Iter 20}}'`)
+	require.NoError(t, err)
+	d := &tmplData{}
+
+	// act
+	first := newBuilder(s).build(7)
+	again := newBuilder(s).build(7)
+	other := newBuilder(s).build(8)
+
+	// assert
+	require.Equal(t, first, again)
+	require.NotEqual(t, first, other)
+	require.Regexp(t, `^\d{1,3} 0\.\d+ '[a-z]{20}'$`, first)
+	require.Equal(t, int64(0), d.HashInt("balance", 7, 0))
+	require.Len(t, d.HashText("name", 7, 40), 40)
+}
@@ -0,0 +1,100 @@
+package benchmark
+
+import (
+	"sync"
+	"time"
+)
+
+// HeartbeatStats contains the latencies of the heartbeat queries.
+type HeartbeatStats struct {
+	Count  int
+	Errors int
+	Min    time.Duration
+	Max    time.Duration
+	Total  time.Duration
+}
+
+// Mean returns the mean latency of the successful heartbeat queries.
+func (s HeartbeatStats) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// Heartbeat executes a query at a low rate during the benchmarks, which serves as constant
+// probe of the server responsiveness under load.
+type Heartbeat struct {
+	exec     func(string) error
+	stmt     string
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+
+	mu    sync.Mutex
+	stats HeartbeatStats
+}
+
+// StartHeartbeat executes the statement every interval with exec, which
+// should use a dedicated connection, until Stop is called.
+func StartHeartbeat(exec func(string) error, stmt string, interval time.Duration) *Heartbeat {
+	h := &Heartbeat{
+		exec:     exec,
+		stmt:     stmt,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go h.run()
+	return h
+}
+
+func (h *Heartbeat) run() {
+	defer close(h.done)
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+		}
+
+		start := time.Now()
+		err := h.exec(h.stmt)
+		took := time.Since(start)
+
+		h.mu.Lock()
+		if err != nil {
+			h.stats.Errors++
+		} else {
+			if h.stats.Count == 0 || took < h.stats.Min {
+				h.stats.Min = took
+			}
+			if took > h.stats.Max {
+				h.stats.Max = took
+			}
+			h.stats.Count++
+			h.stats.Total += took
+		}
+		h.mu.Unlock()
+	}
+}
+
+// Reset returns the stats since the last reset, e.g. per benchmark.
+func (h *Heartbeat) Reset() HeartbeatStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats := h.stats
+	h.stats = HeartbeatStats{}
+	return stats
+}
+
+// Stop stops the heartbeat and waits for the last query.
+func (h *Heartbeat) Stop() {
+	close(h.stop)
+	<-h.done
+}
@@ -0,0 +1,35 @@
+package benchmark
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeartbeat(t *testing.T) {
+	// arrange
+	var calls int64
+	exec := func(stmt string) error {
+		require.Equal(t, "SELECT 1", stmt)
+		if atomic.AddInt64(&calls, 1)%2 == 0 {
+			return errors.New("failed")
+		}
+		return nil
+	}
+
+	// act
+	h := StartHeartbeat(exec, "SELECT 1", time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	h.Stop()
+	stats := h.Reset()
+
+	// assert
+	require.Equal(t, int(atomic.LoadInt64(&calls)), stats.Count+stats.Errors)
+	require.True(t, stats.Count > 0)
+	require.True(t, stats.Errors > 0)
+	require.True(t, stats.Min <= stats.Mean() && stats.Mean() <= stats.Max)
+	require.Equal(t, HeartbeatStats{}, h.Reset())
+}
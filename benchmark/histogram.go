@@ -0,0 +1,113 @@
+package benchmark
+
+import (
+	"math/bits"
+	"time"
+)
+
+const (
+	// histSubBits splits each power of two of a histogram into 2^histSubBits linear buckets,
+	// so the percentiles deviate less than 1% from the exact latencies.
+	histSubBits = 7
+	// histMaxBits limits the latencies of a histogram to 2^histMaxBits ns (about 4.9 hours),
+	// longer ones are counted in the last bucket.
+	histMaxBits = 44
+	histSize    = (histMaxBits - histSubBits + 1) << histSubBits
+)
+
+// histogram counts the latencies of the statements in logarithmic buckets, like an HDR histogram.
+// Its size is fixed, so it doesn't grow with the executed statements, e.g. with Options.Duration.
+// Each worker records into its own histogram, which are merged when the benchmark is done.
+type histogram struct {
+	counts [histSize]int64
+	n      int
+	sum    time.Duration
+	min    time.Duration
+	max    time.Duration
+}
+
+// histIndex returns the index of the bucket of the latency.
+func histIndex(d time.Duration) int {
+	if d < 1<<histSubBits {
+		if d < 0 {
+			return 0
+		}
+		return int(d)
+	}
+	e := bits.Len64(uint64(d)) - histSubBits - 1
+	i := e<<histSubBits + int(uint64(d)>>uint(e))
+	if i >= histSize {
+		return histSize - 1
+	}
+	return i
+}
+
+// histValue returns the highest latency of the bucket with the index.
+func histValue(i int) time.Duration {
+	if i < 1<<histSubBits {
+		return time.Duration(i)
+	}
+	e := i>>histSubBits - 1
+	sub := uint64(i - e<<histSubBits)
+	return time.Duration((sub+1)<<uint(e) - 1)
+}
+
+// record adds the latency.
+func (h *histogram) record(d time.Duration) {
+	if h.n == 0 || d < h.min {
+		h.min = d
+	}
+	if d > h.max {
+		h.max = d
+	}
+	h.counts[histIndex(d)]++
+	h.n++
+	h.sum += d
+}
+
+// merge adds the latencies of the other histogram.
+func (h *histogram) merge(o *histogram) {
+	if o.n == 0 {
+		return
+	}
+	if h.n == 0 || o.min < h.min {
+		h.min = o.min
+	}
+	if o.max > h.max {
+		h.max = o.max
+	}
+	for i, n := range o.counts {
+		h.counts[i] += n
+	}
+	h.n += o.n
+	h.sum += o.sum
+}
+
+// percentile returns the latency below which the given fraction of the latencies fall, like
+// percentile of the sorted latencies, rounded up to the highest latency of its bucket.
+func (h *histogram) percentile(p float64) time.Duration {
+	if h.n == 0 {
+		return 0
+	}
+	rank := int64(float64(h.n-1) * p)
+	var seen int64
+	for i, n := range h.counts {
+		seen += n
+		if seen > rank {
+			if v := histValue(i); v < h.max {
+				return v
+			}
+			return h.max
+		}
+	}
+	return h.max
+}
+
+// count returns the number of latencies up to max, including the other ones of the bucket of max.
+func (h *histogram) count(max time.Duration) int {
+	var n int64
+	for i := 0; i <= histIndex(max); i++ {
+		n += h.counts[i]
+	}
+	return int(n)
+}
@@ -0,0 +1,69 @@
+package benchmark
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistIndex(t *testing.T) {
+	testCases := []struct {
+		description string
+		latency     time.Duration
+	}{
+		{description: "zero", latency: 0},
+		{description: "exact", latency: 127},
+		{description: "first logarithmic bucket", latency: 128},
+		{description: "microseconds", latency: 1234 * time.Microsecond},
+		{description: "seconds", latency: 3 * time.Second},
+		{description: "hours", latency: 4 * time.Hour},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			// act
+			i := histIndex(tt.latency)
+
+			// assert, the latency is in its bucket and the bucket is less than 1% wide
+			require.True(t, histValue(i) >= tt.latency)
+			if i > 0 {
+				require.True(t, histValue(i-1) < tt.latency)
+				require.True(t, float64(histValue(i)-histValue(i-1)) <= 0.01*float64(tt.latency)+1)
+			}
+		})
+	}
+	require.Equal(t, histSize-1, histIndex(1000*time.Hour))
+	require.Equal(t, 0, histIndex(-time.Second))
+}
+
+func TestHistogramPercentile(t *testing.T) {
+	// arrange
+	var (
+		latencies = make([]time.Duration, 0, 10000)
+		h         = &histogram{}
+		rnd       = rand.New(rand.NewSource(1))
+	)
+	for i := 0; i < 10000; i++ {
+		l := time.Duration(rnd.ExpFloat64() * float64(time.Millisecond))
+		latencies = append(latencies, l)
+		h.record(l)
+	}
+
+	// act
+	merged := &histogram{}
+	merged.merge(h)
+	merged.merge(&histogram{})
+
+	// assert
+	for _, p := range []float64{0, 0.5, 0.95, 0.99, 1} {
+		want := percentile(latencies, p)
+		require.InEpsilon(t, want, merged.percentile(p), 0.01, "p%v", p*100)
+		require.True(t, merged.percentile(p) >= want)
+	}
+	require.Equal(t, 10000, merged.n)
+	require.Equal(t, latencies[0], merged.min)
+	require.Equal(t, latencies[len(latencies)-1], merged.max)
+	require.Equal(t, 10000, merged.count(time.Hour))
+	require.Equal(t, time.Duration(0), (&histogram{}).percentile(0.99))
+}
@@ -0,0 +1,27 @@
+package benchmark
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// hitRatio contains the bits of the float64 fraction of the keys returned by Key,
+// which target existing rows. It's stored atomically, as it's read by all threads.
+var hitRatio = math.Float64bits(1)
+
+// SetHitRatio sets the fraction (0.0 - 1.0) of the keys returned by the Key template method,
+// which target existing rows.
+func SetHitRatio(ratio float64) {
+	atomic.StoreUint64(&hitRatio, math.Float64bits(ratio))
+}
+
+// Key returns the key of a point lookup. With the probability of the hit ratio, it's the
+// iteration counter, which targets an existing row. Otherwise, it's the negated counter,
+// which targets a missing row.
+func (d *tmplData) Key() int {
+	ratio := math.Float64frombits(atomic.LoadUint64(&hitRatio))
+	if ratio >= 1 || d.random().Float64() < ratio {
+		return d.Iter
+	}
+	return -d.Iter
+}
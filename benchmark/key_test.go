@@ -0,0 +1,40 @@
+package benchmark
+
+import "testing"
+
+func TestKey(t *testing.T) {
+	defer SetHitRatio(1)
+
+	testCases := []struct {
+		ratio       float64
+		minMisses   int
+		maxMisses   int
+		description string
+	}{
+		{ratio: 1, minMisses: 0, maxMisses: 0, description: "hits"},
+		{ratio: 0, minMisses: 1000, maxMisses: 1000, description: "misses"},
+		{ratio: 0.5, minMisses: 400, maxMisses: 600, description: "half"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			// arrange
+			SetHitRatio(tt.ratio)
+			d := &tmplData{}
+
+			// act
+			misses := 0
+			for i := 1; i <= 1000; i++ {
+				d.Iter = i
+				if d.Key() < 0 {
+					misses++
+				}
+			}
+
+			// assert
+			if misses < tt.minMisses || misses > tt.maxMisses {
+				t.Errorf("got %v misses, want between %v and %v", misses, tt.minMisses, tt.maxMisses)
+			}
+		})
+	}
+}
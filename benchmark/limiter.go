@@ -0,0 +1,81 @@
+package benchmark
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// limiter paces the operations of all routines to a fixed rate. It's a token bucket with a
+// capacity of a single token: each routine reserves the next slot and sleeps until it,
+// so slots missed by slow operations are not made up with a burst later.
+//
+// With an arrival distribution (open model), the slots are the arrivals of the requests,
+// which are independent of the routines: the intervals between them are drawn from the
+// distribution and the missed slots queue up, see newArrivals.
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time // next free slot
+
+	arrival string     // distribution of the intervals of the open model, empty for the closed one
+	rand    *rand.Rand // of the intervals
+}
+
+// newLimiter returns a limiter of the given operations per second, 0 -> unlimited.
+func newLimiter(rate float64) *limiter {
+	l := &limiter{}
+	l.setRate(rate)
+	return l
+}
+
+// newArrivals returns a limiter of requests arriving at the given operations per second,
+// 0 -> unlimited, with intervals of the distribution, see ValidateArrival.
+func newArrivals(rate float64, arrival string, seed int64) *limiter {
+	l := newLimiter(rate)
+	l.arrival = arrival
+	l.rand = rand.New(rand.NewSource(seed))
+	return l
+}
+
+// open returns whether the requests arrive independently of the routines.
+func (l *limiter) open() bool {
+	return l.arrival != ""
+}
+
+// setRate changes the operations per second, 0 -> unlimited.
+func (l *limiter) setRate(rate float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.interval = 0
+	if rate > 0 {
+		l.interval = time.Duration(float64(time.Second) / rate)
+	}
+}
+
+// wait blocks until the next slot of the calling routine. It returns the slot, the arrival of
+// the request, when it was already due and the request queued up, otherwise zero.
+func (l *limiter) wait() time.Time {
+	l.mu.Lock()
+	if l.interval == 0 {
+		l.mu.Unlock()
+		return time.Time{}
+	}
+	now := time.Now()
+	if (!l.open() && l.next.Before(now)) || l.next.IsZero() {
+		l.next = now
+	}
+	slot := l.next
+	interval := l.interval
+	if l.open() {
+		interval = interArrival(l.arrival, l.interval).draw(l.rand)
+	}
+	l.next = l.next.Add(interval)
+	l.mu.Unlock()
+
+	if slot.Before(now) {
+		return slot
+	}
+	time.Sleep(time.Until(slot))
+	return time.Time{}
+}
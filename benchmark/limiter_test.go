@@ -0,0 +1,33 @@
+package benchmark
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	// arrange
+	l := newLimiter(1000)
+	wg := &sync.WaitGroup{}
+	wg.Add(4)
+
+	// act
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				l.wait()
+			}
+		}()
+	}
+	wg.Wait()
+	took := time.Since(start)
+
+	// assert, 100 operations at 1000 ops/s
+	require.True(t, took >= 99*time.Millisecond, took)
+	require.True(t, took < 500*time.Millisecond, took)
+}
@@ -0,0 +1,96 @@
+package benchmark
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// listFuncs render statement text with several values, e.g. the members of IN lists. With prepared
+// statements, each member is marked to be bound as a parameter by the list function itself.
+var listFuncs = map[string]bool{"Rows": true, "RandList": true, "PickList": true}
+
+// writeMember writes a member of a list, separated by a comma. Values, which aren't numbers, are
+// written as string literals.
+func writeMember(sb *strings.Builder, value string) {
+	if sb.Len() > 0 {
+		sb.WriteString(", ")
+	}
+	_, isString := number(value).(string)
+	if isString {
+		sb.WriteByte('\'')
+		value = quote(value)
+	}
+	if isPrepared() {
+		sb.WriteByte(argStart)
+		sb.WriteString(value)
+		sb.WriteByte(argEnd)
+	} else {
+		sb.WriteString(value)
+	}
+	if isString {
+		sb.WriteByte('\'')
+	}
+}
+
+// randMembers returns n distinct random int64 in [0, max), at most max.
+func (d *tmplData) randMembers(n int, max int64) []int64 {
+	if int64(n) > max {
+		n = int(max)
+	}
+	if n <= 0 {
+		return nil
+	}
+	// Floyd's algorithm, each member is drawn once
+	var (
+		r       = d.random()
+		members = make([]int64, 0, n)
+		chosen  = make(map[int64]bool, n)
+	)
+	for j := max - int64(n); j < max; j++ {
+		v := r.Int63n(j + 1)
+		if chosen[v] {
+			v = j
+		}
+		chosen[v] = true
+		members = append(members, v)
+	}
+	return members
+}
+
+// RandList returns n distinct random int64 in [0, max), separated by commas, e.g. for IN lists.
+func (d *tmplData) RandList(n int, max int64) string {
+	sb := &strings.Builder{}
+	for _, v := range d.randMembers(n, max) {
+		writeMember(sb, strconv.FormatInt(v, 10))
+	}
+	return sb.String()
+}
+
+// RandArray returns n distinct random int64 in [0, max) as array literal, e.g. "{3,17,42}".
+// It's a single value, which is bound as one parameter with prepared statements.
+func (d *tmplData) RandArray(n int, max int64) string {
+	members := d.randMembers(n, max)
+	values := make([]string, len(members))
+	for i, v := range members {
+		values[i] = strconv.FormatInt(v, 10)
+	}
+	return "{" + strings.Join(values, ",") + "}"
+}
+
+// PickList returns n random values of the named pool, separated by commas, see Pick.
+// Values, which aren't numbers, are string literals.
+func (d *tmplData) PickList(n int, name string) (string, error) {
+	pools.RLock()
+	defer pools.RUnlock()
+
+	values := pools.m[name]
+	if len(values) == 0 {
+		return "", fmt.Errorf("no values captured in pool %q", name)
+	}
+	sb := &strings.Builder{}
+	for i := 0; i < n; i++ {
+		writeMember(sb, values[d.random().Intn(len(values))])
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,88 @@
+package benchmark
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandList(t *testing.T) {
+	testCases := []struct {
+		description string
+		n           int
+		max         int64
+		wantLen     int
+	}{
+		{description: "members", n: 100, max: 1000, wantLen: 100},
+		{description: "all values", n: 10, max: 10, wantLen: 10},
+		{description: "more than max", n: 10, max: 3, wantLen: 3},
+		{description: "empty", n: 0, max: 10, wantLen: 0},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			// arrange
+			d := &tmplData{}
+
+			// act
+			got := d.RandList(tt.n, tt.max)
+
+			// assert
+			if tt.wantLen == 0 {
+				require.Empty(t, got)
+				return
+			}
+			members := strings.Split(got, ", ")
+			require.Len(t, members, tt.wantLen)
+			seen := map[string]bool{}
+			for _, m := range members {
+				v, err := strconv.ParseInt(m, 10, 64)
+				require.NoError(t, err)
+				require.True(t, v >= 0 && v < tt.max, "member %v", v)
+				require.False(t, seen[m], "duplicate %v", m)
+				seen[m] = true
+			}
+		})
+	}
+}
+
+func TestRandArray(t *testing.T) {
+	d := &tmplData{}
+	require.Regexp(t, `^\{[0-4],[0-4],[0-4]\}$`, d.RandArray(3, 5))
+}
+
+func TestPickList(t *testing.T) {
+	// arrange
+	addToPool("test_pick_list", "O'Reilly")
+	d := &tmplData{}
+
+	// act
+	got, err := d.PickList(2, "test_pick_list")
+	_, errEmpty := d.PickList(2, "test_empty")
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, "'O''Reilly', 'O''Reilly'", got)
+	require.Error(t, errEmpty)
+}
+
+func TestListPrepared(t *testing.T) {
+	// arrange
+	SetPrepared(true)
+	defer SetPrepared(false)
+	addToPool("test_list_prepared", "a")
+	stmt, err := parseStmt(`SELECT * FROM t WHERE id IN ({{.RandList 3 10}}) AND name IN ({{.PickList 2 "test_list_prepared"}}) AND tags = '{{.RandArray 2 10}}'`)
+	require.NoError(t, err)
+
+	// act
+	query, args := bindArgs(newBuilder(stmt).build(1))
+
+	// assert, each member is a parameter
+	require.Equal(t, "SELECT * FROM t WHERE id IN (?, ?, ?) AND name IN (?, ?) AND tags = ?", query)
+	require.Len(t, args, 6)
+	require.IsType(t, int64(0), args[0])
+	require.Equal(t, "a", args[3])
+	require.Regexp(t, `^\{[0-9],[0-9]\}$`, args[5])
+}
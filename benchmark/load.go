@@ -0,0 +1,107 @@
+package benchmark
+
+import (
+	"context"
+	"log"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// Executed statements, the time spent executing them and their min. latency (in nanoseconds)
+// since the last TakeLoad.
+var (
+	loadOps    int64
+	busy       int64
+	minLatency int64 = math.MaxInt64
+)
+
+// execute executes the statement, records and returns its latency and logs its failure.
+// The latency includes the round trip of the simulated network, see SetNetwork, and it's bounded
+// by the query timeout, see SetQueryTimeout.
+// Statements cancelled by the context are neither recorded nor logged, see canceled.
+func execute(ctx context.Context, bencher Bencher, stmt string) (time.Duration, error) {
+	start := time.Now()
+	if d := networkDelay(); d > 0 {
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return time.Since(start), ctx.Err()
+		}
+	}
+	err := execTimeout(ctx, bencher, stmt)
+	took := int64(time.Since(start))
+	if canceled(ctx, err) {
+		return time.Duration(took), err
+	}
+	if err != nil {
+		log.Printf("%v failed: %v", unmarkArgs(stmt), err)
+		failures.add(time.Now())
+		errorKinds.add(err)
+	}
+
+	atomic.AddInt64(&executed, 1)
+	recordProgress(time.Duration(took))
+	recordMetrics(ctx, time.Duration(took), err)
+	recordTrace(ctx, start, time.Duration(took), err)
+	atomic.AddInt64(&loadOps, 1)
+	atomic.AddInt64(&busy, took)
+	for {
+		min := atomic.LoadInt64(&minLatency)
+		if took >= min || atomic.CompareAndSwapInt64(&minLatency, min, took) {
+			break
+		}
+	}
+	return time.Duration(took), err
+}
+
+// canceled returns whether the statement failed, because the context was cancelled.
+func canceled(ctx context.Context, err error) bool {
+	return err != nil && ctx.Err() != nil
+}
+
+// LoadStats contains the latencies of the statements executed by the benchmarks.
+type LoadStats struct {
+	Ops  int64         // executed statements
+	Busy time.Duration // total time spent executing the statements
+	Min  time.Duration // min. latency of a statement
+}
+
+// TakeLoad returns the stats since the last call.
+func TakeLoad() LoadStats {
+	s := LoadStats{
+		Ops:  atomic.SwapInt64(&loadOps, 0),
+		Busy: time.Duration(atomic.SwapInt64(&busy, 0)),
+		Min:  time.Duration(atomic.SwapInt64(&minLatency, math.MaxInt64)),
+	}
+	if s.Ops == 0 {
+		s.Min = 0
+	}
+	return s
+}
+
+// Mean returns the mean latency of the statements.
+func (s LoadStats) Mean() time.Duration {
+	if s.Ops == 0 {
+		return 0
+	}
+	return s.Busy / time.Duration(s.Ops)
+}
+
+// Concurrency returns the mean number of statements in flight during the given duration,
+// according to Little's law: throughput (ops / took) * mean latency (busy / ops).
+func (s LoadStats) Concurrency(took time.Duration) float64 {
+	if took <= 0 {
+		return 0
+	}
+	return float64(s.Busy) / float64(took)
+}
+
+// Queueing returns the ratio of the mean to the min. latency. A high ratio indicates, that the
+// statements spent most of their time waiting in a queue, e.g. for locks or a free worker of the server.
+func (s LoadStats) Queueing() float64 {
+	if s.Min <= 0 {
+		return 0
+	}
+	return float64(s.Mean()) / float64(s.Min)
+}
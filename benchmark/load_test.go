@@ -0,0 +1,38 @@
+package benchmark
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTakeLoad(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything).Run(func(mock.Arguments) { time.Sleep(time.Millisecond) })
+	TakeLoad()
+
+	// act
+	took := Run(context.Background(), bencher, Benchmark{Name: "test", Type: TypeLoop, Stmt: "SELECT 1;"}, Options{Iter: 20, Threads: 4}).Duration
+	stats := TakeLoad()
+
+	// assert
+	require.Equal(t, int64(20), stats.Ops)
+	require.True(t, stats.Min >= time.Millisecond)
+	require.True(t, stats.Mean() >= stats.Min)
+	require.True(t, stats.Queueing() >= 1)
+	require.InDelta(t, 4, stats.Concurrency(took), 1)
+	require.Equal(t, LoadStats{}, TakeLoad())
+}
+
+func TestLoadStats(t *testing.T) {
+	s := LoadStats{Ops: 100, Busy: 2 * time.Second, Min: 5 * time.Millisecond}
+
+	require.Equal(t, 20*time.Millisecond, s.Mean())
+	require.Equal(t, 4.0, s.Queueing())
+	require.Equal(t, 2.0, s.Concurrency(time.Second))
+	require.Equal(t, 0.0, LoadStats{}.Queueing())
+}
@@ -0,0 +1,134 @@
+package benchmark
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds of the latency histogram of the metrics.
+var LatencyBuckets = []time.Duration{
+	100 * time.Microsecond, 250 * time.Microsecond, 500 * time.Microsecond,
+	time.Millisecond, 2500 * time.Microsecond, 5 * time.Millisecond,
+	10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2500 * time.Millisecond, 5 * time.Second, 10 * time.Second,
+}
+
+// metrics counts the statements and workers of each benchmark, while enabled with SetMetrics
+// or used by a running Fairness.
+var metrics = struct {
+	sync.Mutex
+	users      int32 // collecting while > 0 (atomic)
+	served     bool  // enabled with SetMetrics
+	names      []string
+	benchmarks map[string]*BenchmarkMetrics
+}{benchmarks: map[string]*BenchmarkMetrics{}}
+
+// BenchmarkMetrics contains the statements and workers of a benchmark since SetMetrics was enabled.
+type BenchmarkMetrics struct {
+	Name    string
+	Ops     int64         // executed statements, including the failed ones
+	Errors  int64         // failed statements
+	Buckets []int64       // statements with a latency up to the bucket of LatencyBuckets, cumulative
+	Sum     time.Duration // total latency of the statements
+	Workers int           // running workers
+}
+
+// SetMetrics sets whether the metrics of the benchmarks are collected, see Metrics.
+// Enabling them resets the metrics collected before.
+func SetMetrics(enabled bool) {
+	metrics.Lock()
+	defer metrics.Unlock()
+	if enabled {
+		metrics.names, metrics.benchmarks = nil, map[string]*BenchmarkMetrics{}
+	}
+	if enabled == metrics.served {
+		return
+	}
+	metrics.served = enabled
+	if enabled {
+		atomic.AddInt32(&metrics.users, 1)
+	} else {
+		atomic.AddInt32(&metrics.users, -1)
+	}
+}
+
+// Metrics returns the metrics of each benchmark, in the order they started.
+func Metrics() []BenchmarkMetrics {
+	metrics.Lock()
+	defer metrics.Unlock()
+
+	all := make([]BenchmarkMetrics, 0, len(metrics.names))
+	for _, name := range metrics.names {
+		m := *metrics.benchmarks[name]
+		m.Buckets = make([]int64, len(LatencyBuckets))
+		var sum int64
+		for i, n := range metrics.benchmarks[name].Buckets {
+			sum += n
+			m.Buckets[i] = sum
+		}
+		all = append(all, m)
+	}
+	return all
+}
+
+// benchmarkKey is the context key of the name of the running benchmark.
+type benchmarkKey struct{}
+
+// withBenchmark returns a context labeling the statements and workers with the benchmark name.
+func withBenchmark(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, benchmarkKey{}, name)
+}
+
+// benchmarkMetrics returns the metrics of the benchmark of the context, the lock must be held.
+func benchmarkMetrics(ctx context.Context) *BenchmarkMetrics {
+	name, _ := ctx.Value(benchmarkKey{}).(string)
+	m, ok := metrics.benchmarks[name]
+	if !ok {
+		m = &BenchmarkMetrics{Name: name, Buckets: make([]int64, len(LatencyBuckets))}
+		metrics.benchmarks[name] = m
+		metrics.names = append(metrics.names, name)
+	}
+	return m
+}
+
+// recordMetrics records an executed statement of the benchmark of the context.
+func recordMetrics(ctx context.Context, took time.Duration, err error) {
+	if atomic.LoadInt32(&metrics.users) == 0 {
+		return
+	}
+	metrics.Lock()
+	defer metrics.Unlock()
+
+	m := benchmarkMetrics(ctx)
+	m.Ops++
+	if err != nil {
+		m.Errors++
+	}
+	m.Sum += took
+	for i, bound := range LatencyBuckets {
+		if took <= bound {
+			m.Buckets[i]++
+			break
+		}
+	}
+}
+
+// startWorker counts a running worker of the benchmark of the context, until the returned func is called.
+func startWorker(ctx context.Context) func() {
+	if atomic.LoadInt32(&metrics.users) == 0 {
+		return func() {}
+	}
+	metrics.Lock()
+	defer metrics.Unlock()
+
+	m := benchmarkMetrics(ctx)
+	m.Workers++
+	return func() {
+		metrics.Lock()
+		defer metrics.Unlock()
+		m.Workers--
+	}
+}
@@ -0,0 +1,60 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", "INSERT 3").Return(errors.New("failed"))
+	bencher.On("Exec", mock.Anything)
+	inserts := Benchmark{Name: "inserts", Type: TypeLoop, Stmt: "INSERT {{.Iter}}"}
+	create := Benchmark{Name: "create", Type: TypeOnce, Stmt: "CREATE TABLE t"}
+
+	// act
+	SetMetrics(true)
+	defer SetMetrics(false)
+	Run(context.Background(), bencher, create, Options{})
+	Run(context.Background(), bencher, inserts, Options{Iter: 10, Threads: 2})
+	got := Metrics()
+
+	// assert
+	require.Len(t, got, 2)
+	require.Equal(t, "create", got[0].Name)
+	require.Equal(t, int64(1), got[0].Ops)
+	require.Equal(t, "inserts", got[1].Name)
+	require.Equal(t, int64(10), got[1].Ops)
+	require.Equal(t, int64(1), got[1].Errors)
+	require.Zero(t, got[1].Workers, "finished workers")
+	require.True(t, got[1].Sum > 0)
+	require.Len(t, got[1].Buckets, len(LatencyBuckets))
+	require.Equal(t, int64(10), got[1].Buckets[len(LatencyBuckets)-1], "cumulative")
+	for i := 1; i < len(LatencyBuckets); i++ {
+		require.True(t, got[1].Buckets[i] >= got[1].Buckets[i-1])
+	}
+}
+
+func TestMetricsWorkers(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything).Run(func(mock.Arguments) { time.Sleep(time.Millisecond) })
+	b := Benchmark{Name: "inserts", Type: TypeLoop, Stmt: "INSERT {{.Iter}}"}
+	workers := make(chan int, 1)
+
+	// act
+	SetMetrics(true)
+	defer SetMetrics(false)
+	time.AfterFunc(20*time.Millisecond, func() { workers <- Metrics()[0].Workers })
+	Run(context.Background(), bencher, b, Options{Duration: 50 * time.Millisecond, Threads: 3})
+
+	// assert
+	require.Equal(t, 3, <-workers)
+	require.Zero(t, Metrics()[0].Workers)
+}
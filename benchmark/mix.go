@@ -0,0 +1,191 @@
+package benchmark
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MixStmt is a statement of a mixed workload, see Benchmark.Mix.
+type MixStmt struct {
+	Name   string
+	Weight float64 // relative to the weights of the other statements, e.g. 80, 15 and 5
+	Stmt   string
+}
+
+// MixResult contains the result of a statement of a mixed workload. The duration is the one
+// of the whole benchmark, so the throughput is the share of the statement.
+type MixResult struct {
+	Name    string
+	Share   float64 // fraction of the total weight
+	Buckets []int   // statements with a latency up to the bucket of LatencyBuckets, cumulative
+	Result
+}
+
+// Statements returns the statements of the benchmark, the ones of the mix or the statement.
+func (b Benchmark) Statements() []string {
+	if len(b.Mix) == 0 {
+		return []string{b.Stmt}
+	}
+	stmts := make([]string, len(b.Mix))
+	for i, m := range b.Mix {
+		stmts[i] = m.Stmt
+	}
+	return stmts
+}
+
+// mixture chooses one of the statements of a mixed workload per iteration, randomly by their weights,
+// and collects the latencies of each statement.
+type mixture struct {
+	stmts      []MixStmt
+	parsed     []*statement
+	cumulative []float64 // cumulative weights, for a binary search of the chosen statement
+
+	mu        sync.Mutex
+	latencies []*histogram
+	errors    []int
+	kinds     []map[string]int
+}
+
+// parseBenchmark returns the parsed statement of the benchmark, or a new mixture of its statements.
+func parseBenchmark(b Benchmark) (*statement, error) {
+	if len(b.Mix) == 0 {
+		return parseStmt(b.Stmt)
+	}
+
+	m := &mixture{
+		stmts:     b.Mix,
+		latencies: make([]*histogram, len(b.Mix)),
+		errors:    make([]int, len(b.Mix)),
+		kinds:     make([]map[string]int, len(b.Mix)),
+	}
+	total := 0.0
+	for _, s := range b.Mix {
+		if s.Weight <= 0 {
+			return nil, fmt.Errorf("weight of %v must be positive", s.Name)
+		}
+		t, err := parseStmt(s.Stmt)
+		if err != nil {
+			return nil, err
+		}
+		total += s.Weight
+		m.parsed = append(m.parsed, t)
+		m.cumulative = append(m.cumulative, total)
+	}
+	return &statement{mix: m}, nil
+}
+
+// pick returns the index of a random statement, chosen by the weights.
+func (m *mixture) pick(rnd *rand.Rand) int {
+	r := rnd.Float64() * m.cumulative[len(m.cumulative)-1]
+	i := sort.SearchFloat64s(m.cumulative, r)
+	// r is exactly a cumulative weight
+	if i < len(m.cumulative)-1 && m.cumulative[i] == r {
+		i++
+	}
+	return i
+}
+
+// add adds the latencies, errors and error kinds of a routine, indexed by the statements.
+// The statements, which the routine didn't execute, have no histogram.
+func (m *mixture) add(latencies []*histogram, errors []int, kinds []map[string]int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range latencies {
+		if latencies[i] != nil {
+			if m.latencies[i] == nil {
+				m.latencies[i] = &histogram{}
+			}
+			m.latencies[i].merge(latencies[i])
+		}
+		m.errors[i] += errors[i]
+		for kind, n := range kinds[i] {
+			if m.kinds[i] == nil {
+				m.kinds[i] = map[string]int{}
+			}
+			m.kinds[i][kind] += n
+		}
+	}
+}
+
+// results returns the result of each statement.
+func (m *mixture) results(took time.Duration) []MixResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	total := m.cumulative[len(m.cumulative)-1]
+	results := make([]MixResult, len(m.stmts))
+	for i, s := range m.stmts {
+		if m.latencies[i] == nil {
+			m.latencies[i] = &histogram{}
+		}
+		r := newResult(took, m.latencies[i], m.errors[i])
+		r.ErrorKinds = m.kinds[i]
+		results[i] = MixResult{Name: s.Name, Share: s.Weight / total, Buckets: buckets(m.latencies[i]), Result: r}
+	}
+	return results
+}
+
+// buckets returns the counts of the latencies in the LatencyBuckets, see MixResult.Buckets.
+func buckets(latencies *histogram) []int {
+	if latencies.n == 0 {
+		return nil
+	}
+	counts := make([]int, len(LatencyBuckets))
+	for i, bound := range LatencyBuckets {
+		counts[i] = latencies.count(bound)
+	}
+	return counts
+}
+
+// buildMix chooses a statement of the mixture and builds it. Each statement has its own builder,
+// which gets the template data of this builder.
+func (b *builder) buildMix(i int) string {
+	m := b.stmt.mix
+	if b.subs == nil {
+		b.subs = make([]*builder, len(m.parsed))
+		b.mixLatencies = make([]*histogram, len(m.parsed))
+		b.mixErrors = make([]int, len(m.parsed))
+		b.mixKinds = make([]map[string]int, len(m.parsed))
+	}
+
+	b.chosen = m.pick(b.data.random())
+	sub := b.subs[b.chosen]
+	if sub == nil {
+		sub = newBuilder(m.parsed[b.chosen])
+		b.subs[b.chosen] = sub
+	}
+	sub.data = b.data
+	stmt := sub.build(i)
+	b.data.faker = sub.data.faker // created on first use
+	return stmt
+}
+
+// record records the latency and the error kind of the last built statement of a mixture, see flush.
+func (b *builder) record(took time.Duration, err error) {
+	if b.stmt.mix == nil {
+		return
+	}
+	if b.mixLatencies[b.chosen] == nil {
+		b.mixLatencies[b.chosen] = &histogram{}
+	}
+	b.mixLatencies[b.chosen].record(took)
+	if err != nil {
+		b.mixErrors[b.chosen]++
+		if b.mixKinds[b.chosen] == nil {
+			b.mixKinds[b.chosen] = map[string]int{}
+		}
+		b.mixKinds[b.chosen][ErrorKind(err)]++
+	}
+}
+
+// flush adds the recorded latencies to the mixture.
+func (b *builder) flush() {
+	if b.stmt.mix == nil || b.mixLatencies == nil {
+		return
+	}
+	b.stmt.mix.add(b.mixLatencies, b.mixErrors, b.mixKinds)
+	b.mixLatencies, b.mixErrors, b.mixKinds, b.subs = nil, nil, nil, nil
+}
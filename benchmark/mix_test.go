@@ -0,0 +1,74 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMixturePick(t *testing.T) {
+	// arrange
+	stmt, err := parseBenchmark(Benchmark{Mix: []MixStmt{{Name: "a", Weight: 80, Stmt: "A"}, {Name: "b", Weight: 15, Stmt: "B"}, {Name: "c", Weight: 5, Stmt: "C"}}})
+	require.NoError(t, err)
+	counts := make([]int, 3)
+	rnd := newRand(0)
+
+	// act
+	for i := 0; i < 100000; i++ {
+		counts[stmt.mix.pick(rnd)]++
+	}
+
+	// assert
+	require.InDelta(t, 80000, counts[0], 1000)
+	require.InDelta(t, 15000, counts[1], 1000)
+	require.InDelta(t, 5000, counts[2], 1000)
+}
+
+func TestParseBenchmarkInvalidWeight(t *testing.T) {
+	// act
+	_, err := parseBenchmark(Benchmark{Mix: []MixStmt{{Name: "a", Weight: 1, Stmt: "A"}, {Name: "b", Weight: 0, Stmt: "B"}}})
+
+	// assert
+	require.EqualError(t, err, "weight of b must be positive")
+}
+
+func TestRunMix(t *testing.T) {
+	for _, live := range []bool{false, true} {
+		t.Run(fmt.Sprintf("live %v", live), func(t *testing.T) {
+			// arrange
+			bencher := &mockedBencher{}
+			bencher.On("Exec", mock.MatchedBy(func(s string) bool { return strings.HasPrefix(s, "SELECT") })).Return(nil)
+			bencher.On("Exec", "UPDATE 1").Return(errors.New("failed"))
+			b := Benchmark{Name: "mix", Type: TypeLoop, Mix: []MixStmt{
+				{Name: "reads", Weight: 3, Stmt: "SELECT {{.Iter}}"},
+				{Name: "updates", Weight: 1, Stmt: "UPDATE 1"},
+			}}
+
+			// act
+			r := Run(context.Background(), bencher, b, Options{Iter: 400, Threads: 4, Live: live})
+
+			// assert
+			bencher.AssertNumberOfCalls(t, "Exec", 400)
+			require.Len(t, r.Mix, 2)
+			reads, updates := r.Mix[0], r.Mix[1]
+			require.Equal(t, "reads", reads.Name)
+			require.Equal(t, 0.75, reads.Share)
+			require.Equal(t, 0.25, updates.Share)
+			require.Equal(t, r.Ops, reads.Ops+updates.Ops)
+			require.InDelta(t, 300, reads.Ops, 60)
+			require.Equal(t, 0, reads.Errors)
+			require.Equal(t, updates.Ops, updates.Errors)
+			require.Equal(t, r.Errors, updates.Errors)
+			require.Equal(t, map[string]int{ErrKindOther: updates.Errors}, updates.ErrorKinds)
+			require.Nil(t, reads.ErrorKinds)
+			require.Len(t, reads.Buckets, len(LatencyBuckets))
+			require.Equal(t, reads.Ops, reads.Buckets[len(reads.Buckets)-1])
+			require.Equal(t, r.Duration, reads.Duration)
+		})
+	}
+}
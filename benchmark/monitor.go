@@ -0,0 +1,216 @@
+package benchmark
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// executed counts the statements executed by all benchmarks, it's sampled by the monitor.
+var executed int64
+
+// Interval contains the client-side measurements of a monitor interval.
+type Interval struct {
+	Start   time.Duration // since the start of the monitor
+	End     time.Duration
+	Ops     int64         // executed statements
+	Latency time.Duration // mean latency of the statements
+	GCPause time.Duration // garbage collection pauses of the client
+	CPU     float64       // used fraction of the available client cores
+	Tuning  *Tuning       // new rate and threads, when the live benchmark was tuned in the interval
+}
+
+// Cause returns the probable cause of a latency spike in the interval: a garbage collection
+// pause or a high CPU usage of the client, a tuning of the benchmark, otherwise the server.
+func (i Interval) Cause() string {
+	switch {
+	case i.Tuning != nil:
+		return fmt.Sprintf("tuned to %v threads, %.0f ops/s", i.Tuning.Threads, i.Tuning.Rate)
+	case i.GCPause > 0 && i.GCPause*10 >= i.Latency:
+		return fmt.Sprintf("client GC pause %v", i.GCPause)
+	case i.CPU >= 0.9:
+		return fmt.Sprintf("client CPU %.0f%%", i.CPU*100)
+	}
+	return "server"
+}
+
+// ClientStats contains the measurements of the client during a benchmark.
+type ClientStats struct {
+	Intervals    []Interval
+	GCPauses     int
+	GCPauseTotal time.Duration
+	GCPauseMax   time.Duration
+	CPU          float64       // mean used fraction of the available client cores
+	CPUTime      time.Duration // user and system CPU time of the client
+	HeapMax      uint64        // max. bytes of the allocated heap objects, sampled every interval
+	Allocated    uint64        // bytes allocated during the benchmark
+	Goroutines   int           // max. number of goroutines, sampled every interval
+}
+
+// Bottleneck returns why the client probably limited the throughput of the benchmark, when
+// it used at least 90% of its cores on average or in at least half of the intervals.
+// Benchmarks shorter than a second are too short to tell.
+func (s *ClientStats) Bottleneck() string {
+	if len(s.Intervals) == 0 || s.Intervals[len(s.Intervals)-1].End < time.Second {
+		return ""
+	}
+	if s.CPU >= 0.9 {
+		return fmt.Sprintf("the client used %.0f%% of its cores", s.CPU*100)
+	}
+	var busy int
+	for _, i := range s.Intervals {
+		if i.CPU >= 0.9 {
+			busy++
+		}
+	}
+	if busy > 0 && busy*2 >= len(s.Intervals) {
+		return fmt.Sprintf("the client used at least 90%% of its cores in %v of %v intervals", busy, len(s.Intervals))
+	}
+	return ""
+}
+
+// SlowIntervals returns the intervals, whose mean latency is more than factor times the median.
+func (s *ClientStats) SlowIntervals(factor float64) []Interval {
+	var latencies []time.Duration
+	for _, i := range s.Intervals {
+		if i.Ops > 0 {
+			latencies = append(latencies, i.Latency)
+		}
+	}
+	if len(latencies) == 0 {
+		return nil
+	}
+	sort.Slice(latencies, func(a, b int) bool { return latencies[a] < latencies[b] })
+	median := latencies[len(latencies)/2]
+
+	var slow []Interval
+	for _, i := range s.Intervals {
+		if float64(i.Latency) > factor*float64(median) {
+			slow = append(slow, i)
+		}
+	}
+	return slow
+}
+
+// Monitor samples the executed statements, the garbage collection pauses
+// and the CPU usage of the client in intervals.
+type Monitor struct {
+	threads  int
+	interval time.Duration
+	stop     chan struct{}
+	done     chan *ClientStats
+
+	// initial samples
+	start      time.Time
+	ops        int64
+	cpu        time.Duration
+	numGC      uint32
+	totalAlloc uint64
+}
+
+// StartMonitor starts sampling the client every interval. The threads are
+// used to estimate the mean latency from the executed statements.
+func StartMonitor(interval time.Duration, threads int) *Monitor {
+	m := &Monitor{
+		threads:  threads,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan *ClientStats),
+		start:    time.Now(),
+		ops:      atomic.LoadInt64(&executed),
+		cpu:      cpuTime(),
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	m.numGC, m.totalAlloc = mem.NumGC, mem.TotalAlloc
+
+	go m.run()
+	return m
+}
+
+// Stop stops the monitor and returns the measurements.
+func (m *Monitor) Stop() *ClientStats {
+	close(m.stop)
+	return <-m.done
+}
+
+func (m *Monitor) run() {
+	var (
+		stats    = &ClientStats{}
+		mem      runtime.MemStats
+		start    = m.start
+		last     = m.start
+		lastOps  = m.ops
+		lastCPU  = m.cpu
+		lastGC   = m.numGC
+		version  = atomic.LoadInt64(&tuning.version)
+		threads  = m.threads
+		cores    = float64(runtime.GOMAXPROCS(0))
+		ticker   = time.NewTicker(m.interval)
+		stopping = false
+	)
+	defer ticker.Stop()
+
+	for !stopping {
+		select {
+		case <-ticker.C:
+		case <-m.stop:
+			stopping = true
+		}
+
+		now := time.Now()
+		elapsed := now.Sub(last)
+		if elapsed <= 0 {
+			continue
+		}
+
+		ops := atomic.LoadInt64(&executed)
+		cpu := cpuTime()
+		runtime.ReadMemStats(&mem)
+
+		i := Interval{Start: last.Sub(start), End: now.Sub(start), Ops: ops - lastOps}
+		if v := atomic.LoadInt64(&tuning.version); v != version {
+			if t, ok := CurrentTuning(); ok {
+				i.Tuning = &t
+				threads = t.Threads
+			}
+			version = v
+		}
+		if i.Ops > 0 {
+			i.Latency = elapsed * time.Duration(threads) / time.Duration(i.Ops)
+		}
+		i.CPU = float64(cpu-lastCPU) / float64(elapsed) / cores
+		if mem.HeapAlloc > stats.HeapMax {
+			stats.HeapMax = mem.HeapAlloc
+		}
+		if g := runtime.NumGoroutine(); g > stats.Goroutines {
+			stats.Goroutines = g
+		}
+
+		// The last 256 pauses are kept in a circular buffer.
+		for gc := lastGC + 1; gc <= mem.NumGC && gc+256 > mem.NumGC; gc++ {
+			pause := time.Duration(mem.PauseNs[(gc+255)%256])
+			i.GCPause += pause
+			stats.GCPauses++
+			stats.GCPauseTotal += pause
+			if pause > stats.GCPauseMax {
+				stats.GCPauseMax = pause
+			}
+		}
+
+		stats.Intervals = append(stats.Intervals, i)
+		last, lastOps, lastCPU, lastGC = now, ops, cpu, mem.NumGC
+	}
+
+	stats.CPUTime = lastCPU - m.cpu
+	if mem.TotalAlloc > m.totalAlloc {
+		stats.Allocated = mem.TotalAlloc - m.totalAlloc
+	}
+	if total := last.Sub(start); total > 0 {
+		stats.CPU = float64(lastCPU-m.cpu) / float64(total) / cores
+	}
+	m.done <- stats
+}
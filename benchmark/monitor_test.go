@@ -0,0 +1,103 @@
+package benchmark
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntervalCause(t *testing.T) {
+	testCases := []struct {
+		interval Interval
+		want     string
+	}{
+		{interval: Interval{Latency: time.Millisecond, GCPause: 500 * time.Microsecond}, want: "client GC pause 500µs"},
+		{interval: Interval{Latency: time.Second, GCPause: time.Microsecond, CPU: 0.95}, want: "client CPU 95%"},
+		{interval: Interval{Latency: time.Second, GCPause: time.Microsecond, CPU: 0.5}, want: "server"},
+		{interval: Interval{Latency: time.Second, Tuning: &Tuning{Rate: 500, Threads: 10}}, want: "tuned to 10 threads, 500 ops/s"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.want, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.interval.Cause())
+		})
+	}
+}
+
+func TestSlowIntervals(t *testing.T) {
+	// arrange
+	stats := &ClientStats{Intervals: []Interval{
+		{Ops: 10, Latency: time.Millisecond},
+		{Ops: 10, Latency: time.Millisecond},
+		{Ops: 2, Latency: 5 * time.Millisecond},
+		{Ops: 10, Latency: 1500 * time.Microsecond},
+		{Ops: 0},
+	}}
+
+	// act
+	slow := stats.SlowIntervals(2)
+
+	// assert
+	require.Equal(t, []Interval{{Ops: 2, Latency: 5 * time.Millisecond}}, slow)
+}
+
+func TestMonitor(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", "SELECT 1")
+	s, _ := parseStmt("SELECT 1")
+
+	// act
+	m := StartMonitor(time.Millisecond, 2)
+	loop(context.Background(), bencher, s, Options{Iter: 100, Threads: 2})
+	time.Sleep(5 * time.Millisecond)
+	stats := m.Stop()
+
+	// assert
+	var ops int64
+	for _, i := range stats.Intervals {
+		ops += i.Ops
+	}
+	require.Equal(t, int64(100), ops)
+	require.NotZero(t, stats.Goroutines)
+	require.NotZero(t, stats.HeapMax)
+}
+
+func TestClientStatsBottleneck(t *testing.T) {
+	testCases := []struct {
+		description string
+		stats       ClientStats
+		want        string
+	}{
+		{
+			description: "high mean CPU",
+			stats:       ClientStats{CPU: 0.95, Intervals: []Interval{{End: time.Second}}},
+			want:        "the client used 95% of its cores",
+		},
+		{
+			description: "busy intervals",
+			stats:       ClientStats{CPU: 0.6, Intervals: []Interval{{CPU: 0.95}, {CPU: 0.1}, {CPU: 0.9}, {CPU: 0.5, End: 2 * time.Second}}},
+			want:        "the client used at least 90% of its cores in 2 of 4 intervals",
+		},
+		{
+			description: "few busy intervals",
+			stats:       ClientStats{CPU: 0.4, Intervals: []Interval{{CPU: 0.95}, {CPU: 0.1}, {CPU: 0.2, End: 2 * time.Second}}},
+		},
+		{
+			description: "too short",
+			stats:       ClientStats{CPU: 0.95, Intervals: []Interval{{CPU: 0.95, End: 100 * time.Millisecond}}},
+		},
+		{
+			description: "idle",
+			stats:       ClientStats{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.stats.Bottleneck())
+		})
+	}
+}
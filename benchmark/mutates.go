@@ -0,0 +1,52 @@
+package benchmark
+
+import (
+	"strings"
+	"unicode"
+)
+
+// mutatingKeywords are the keywords of statements, which change data or the schema.
+var mutatingKeywords = map[string]bool{
+	"ALTER":         true,
+	"COPY":          true,
+	"CREATE":        true,
+	"DEL":           true, // Redis
+	"DELETE":        true,
+	"DROP":          true,
+	"EXPIRE":        true, // Redis
+	"FINDANDMODIFY": true, // MongoDB
+	"FLUSHALL":      true, // Redis
+	"FLUSHDB":       true, // Redis
+	"GRANT":         true,
+	"HSET":          true, // Redis
+	"INCR":          true, // Redis
+	"INSERT":        true,
+	"LPUSH":         true, // Redis
+	"MERGE":         true,
+	"MSET":          true, // Redis
+	"MUTATION":      true, // GraphQL
+	"RENAME":        true,
+	"REPLACE":       true,
+	"REVOKE":        true,
+	"RPUSH":         true, // Redis
+	"SADD":          true, // Redis
+	"SET":           true, // Redis
+	"TRUNCATE":      true,
+	"UPDATE":        true,
+	"UPSERT":        true,
+	"ZADD":          true, // Redis
+}
+
+// Mutates returns whether the statement contains any keyword of a statement, which changes data
+// or the schema. It's conservative, e.g. the keyword in a string literal counts, too.
+func Mutates(stmt string) bool {
+	words := strings.FieldsFunc(strings.ToUpper(stmt), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	for _, w := range words {
+		if mutatingKeywords[w] {
+			return true
+		}
+	}
+	return false
+}
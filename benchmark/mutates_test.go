@@ -0,0 +1,29 @@
+package benchmark
+
+import "testing"
+
+func TestMutates(t *testing.T) {
+	testCases := []struct {
+		stmt string
+		want bool
+	}{
+		{stmt: "SELECT * FROM dbbench.simple WHERE id = {{.Key}};", want: false},
+		{stmt: "SELECT update_time FROM deleted_rows;", want: false},
+		{stmt: "insert into t values (1);", want: true},
+		{stmt: "SELECT 1;\nUPDATE t SET a = 1;", want: true},
+		{stmt: "DROP TABLE t", want: true},
+		{stmt: "mutation { insert_accounts(objects: {}) { affected_rows } }", want: true},
+		{stmt: `{"find": "users", "filter": {"_id": 1}}`, want: false},
+		{stmt: "GET dbbench:1\nMGET dbbench:2 dbbench:3", want: false},
+		{stmt: "GET dbbench:1\nINCR dbbench:2", want: true},
+		{stmt: `{"findAndModify": "users", "query": {"_id": 1}, "remove": true}`, want: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.stmt, func(t *testing.T) {
+			if got := Mutates(tt.stmt); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Neighbor executes the loop benchmarks of a workload continuously in the background, e.g. to
+// simulate another tenant on the same database server. The iteration counter continues across
+// the starts, so inserts don't collide with the rows of the previous ones.
+type Neighbor struct {
+	bencher Bencher
+	stmts   []*statement
+	threads int
+
+	iter  int64 // last iteration, shared by all routines
+	ops   int64 // executed statements since the start
+	start time.Time
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewNeighbor returns a stopped neighbor executing the loop benchmarks one after another.
+// Once and skipped benchmarks are ignored.
+func NewNeighbor(bencher Bencher, benchmarks []Benchmark, threads int) (*Neighbor, error) {
+	n := &Neighbor{bencher: bencher, threads: threads}
+	for _, b := range benchmarks {
+		if b.Type != TypeLoop || b.Skip != "" {
+			continue
+		}
+		t, err := parseBenchmark(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template of %v: %v", b.Name, err)
+		}
+		n.stmts = append(n.stmts, t)
+	}
+	if len(n.stmts) == 0 {
+		return nil, fmt.Errorf("no loop benchmarks for the neighbor")
+	}
+	return n, nil
+}
+
+// Start starts the workload.
+func (n *Neighbor) Start() {
+	n.ops = 0
+	n.start = time.Now()
+	n.stop = make(chan struct{})
+
+	n.wg.Add(n.threads)
+	for routine := 0; routine < n.threads; routine++ {
+		go func(routine int) {
+			defer n.wg.Done()
+
+			builders := make([]*builder, len(n.stmts))
+			for i, t := range n.stmts {
+				builders[i] = newBuilder(t)
+				builders[i].setThread(routine)
+				builders[i].data.Threads = n.threads
+			}
+
+			for {
+				select {
+				case <-n.stop:
+					return
+				default:
+				}
+				i := int(atomic.AddInt64(&n.iter, 1))
+				b := builders[i%len(builders)]
+				b.data.Op = int(atomic.AddInt64(&n.ops, 1))
+				stmt := b.build(i)
+				if err := execArgs(context.Background(), n.bencher, stmt); err != nil {
+					log.Printf("%v failed: %v", unmarkArgs(stmt), err)
+				}
+			}
+		}(routine)
+	}
+}
+
+// Stop stops the workload and returns its throughput since the start in operations per second.
+func (n *Neighbor) Stop() float64 {
+	close(n.stop)
+	n.wg.Wait()
+	return float64(atomic.LoadInt64(&n.ops)) / time.Since(n.start).Seconds()
+}
@@ -0,0 +1,52 @@
+package benchmark
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNeighbor(t *testing.T) {
+	// arrange
+	var (
+		mu    sync.Mutex
+		stmts = map[string]int{}
+	)
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		stmts[args.String(0)[:6]]++
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+	})
+	n, err := NewNeighbor(bencher, []Benchmark{
+		{Name: "inserts", Type: TypeLoop, Stmt: "INSERT {{.Iter}};"},
+		{Name: "setup", Type: TypeOnce, Stmt: "CREATE TABLE ...;"},
+		{Name: "skipped", Type: TypeLoop, Stmt: "DELETE {{.Iter}};", Skip: "not supported"},
+		{Name: "selects", Type: TypeLoop, Stmt: "SELECT {{.Iter}};"},
+	}, 2)
+	require.NoError(t, err)
+
+	// act
+	n.Start()
+	time.Sleep(50 * time.Millisecond)
+	rate := n.Stop()
+	first := n.iter
+	n.Start()
+	time.Sleep(10 * time.Millisecond)
+	n.Stop()
+
+	// assert
+	require.True(t, rate > 100, "rate %v", rate)
+	require.True(t, n.iter > first)
+	require.Len(t, stmts, 2)
+	require.InDelta(t, stmts["INSERT"], stmts["SELECT"], 2)
+}
+
+func TestNeighborNoLoops(t *testing.T) {
+	_, err := NewNeighbor(&mockedBencher{}, []Benchmark{{Name: "setup", Type: TypeOnce, Stmt: "CREATE TABLE ...;"}}, 1)
+	require.Error(t, err)
+}
@@ -0,0 +1,81 @@
+package benchmark
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Network is a latency profile of the network between the client and the database, which is
+// simulated by delaying each statement by a round trip.
+type Network struct {
+	RTT    time.Duration // mean round-trip time
+	Jitter time.Duration // standard deviation of the round-trip time
+}
+
+// Networks contains the presets of common deployment topologies.
+var Networks = map[string]Network{
+	"same-az":      {RTT: 250 * time.Microsecond, Jitter: 50 * time.Microsecond},
+	"cross-az":     {RTT: 1200 * time.Microsecond, Jitter: 200 * time.Microsecond},
+	"cross-region": {RTT: 70 * time.Millisecond, Jitter: 5 * time.Millisecond},
+}
+
+// ParseNetwork returns the preset with the given name or a profile with the given round-trip
+// time, optionally followed by the jitter, e.g. "20ms" or "20ms/2ms".
+func ParseNetwork(s string) (Network, error) {
+	if n, ok := Networks[s]; ok {
+		return n, nil
+	}
+
+	var (
+		n     Network
+		err   error
+		parts = strings.SplitN(s, "/", 2)
+	)
+	if n.RTT, err = time.ParseDuration(parts[0]); err != nil {
+		return Network{}, fmt.Errorf("unknown network %q, use a round-trip time or one of %v", s, strings.Join(networkNames(), ", "))
+	}
+	if len(parts) == 2 {
+		if n.Jitter, err = time.ParseDuration(parts[1]); err != nil {
+			return Network{}, fmt.Errorf("invalid jitter of network %q: %v", s, err)
+		}
+	}
+	return n, nil
+}
+
+// networkNames returns the sorted names of the presets.
+func networkNames() []string {
+	var names []string
+	for name := range Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Round-trip time and jitter of the simulated network in nanoseconds, they are stored
+// atomically, as they are read by all threads.
+var networkRTT, networkJitter int64
+
+// SetNetwork sets the simulated network, which delays each executed statement.
+// The zero value disables the simulation.
+func SetNetwork(n Network) {
+	atomic.StoreInt64(&networkRTT, int64(n.RTT))
+	atomic.StoreInt64(&networkJitter, int64(n.Jitter))
+}
+
+// networkDelay returns the simulated round-trip time of a statement, never below zero.
+func networkDelay() time.Duration {
+	rtt, jitter := atomic.LoadInt64(&networkRTT), atomic.LoadInt64(&networkJitter)
+	if rtt == 0 && jitter == 0 {
+		return 0
+	}
+	d := time.Duration(float64(rtt) + rand.NormFloat64()*float64(jitter))
+	if d < 0 {
+		return 0
+	}
+	return d
+}
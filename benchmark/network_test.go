@@ -0,0 +1,52 @@
+package benchmark
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNetwork(t *testing.T) {
+	testCases := []struct {
+		given   string
+		want    Network
+		wantErr bool
+	}{
+		{given: "cross-region", want: Networks["cross-region"]},
+		{given: "20ms", want: Network{RTT: 20 * time.Millisecond}},
+		{given: "20ms/2ms", want: Network{RTT: 20 * time.Millisecond, Jitter: 2 * time.Millisecond}},
+		{given: "mars", wantErr: true},
+		{given: "20ms/x", wantErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.given, func(t *testing.T) {
+			got, err := ParseNetwork(tt.given)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRunNetwork(t *testing.T) {
+	// arrange
+	SetNetwork(Network{RTT: 10 * time.Millisecond})
+	defer SetNetwork(Network{})
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything)
+	b := Benchmark{Name: "selects", Type: TypeLoop, Stmt: "SELECT {{.Iter}}"}
+
+	// act
+	result := Run(context.Background(), bencher, b, Options{Iter: 5, Threads: 1})
+
+	// assert
+	require.True(t, result.Duration >= 50*time.Millisecond, result.Duration)
+	require.True(t, result.Min >= 10*time.Millisecond, result.Min)
+}
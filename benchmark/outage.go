@@ -0,0 +1,93 @@
+package benchmark
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Outage is a period of a benchmark, in which statements failed, see Result.Outages.
+type Outage struct {
+	Start  time.Duration // since the start of the benchmark
+	End    time.Duration
+	Errors int // failed statements
+}
+
+// outageResolution is the precision of the outages. Failures in consecutive
+// periods of this length belong to the same outage.
+const outageResolution = 100 * time.Millisecond
+
+// failures collects the times of the failed statements of the running benchmark. Only the number
+// of failures per period is kept, so long outages don't grow the memory usage per statement.
+var failures = &failureTimeline{}
+
+type failureTimeline struct {
+	mu      sync.Mutex
+	start   time.Time
+	periods map[int64]int // failures by the index of their period
+}
+
+// reset starts a new timeline.
+func (f *failureTimeline) reset(start time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.start = start
+	f.periods = map[int64]int{}
+}
+
+// add records a failed statement.
+func (f *failureTimeline) add(at time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.periods == nil {
+		return
+	}
+	f.periods[int64(at.Sub(f.start)/outageResolution)]++
+}
+
+// outages returns the periods with failures, consecutive ones are merged. The timeline is
+// stopped, failures of parallel benchmarks still running aren't recorded anymore.
+func (f *failureTimeline) outages() []Outage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	indexes := make([]int64, 0, len(f.periods))
+	for i := range f.periods {
+		indexes = append(indexes, i)
+	}
+	sort.Slice(indexes, func(a, b int) bool { return indexes[a] < indexes[b] })
+
+	var outages []Outage
+	for n, i := range indexes {
+		if n > 0 && indexes[n-1] == i-1 {
+			last := &outages[len(outages)-1]
+			last.End += outageResolution
+			last.Errors += f.periods[i]
+			continue
+		}
+		start := time.Duration(i) * outageResolution
+		outages = append(outages, Outage{Start: start, End: start + outageResolution, Errors: f.periods[i]})
+	}
+	f.periods = nil
+	return outages
+}
+
+// Goodput returns the successful statements per second.
+func (r Result) Goodput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Ops-r.Errors) / r.Duration.Seconds()
+}
+
+// Downtime returns the total duration of the outages, at most the duration of the benchmark.
+func (r Result) Downtime() time.Duration {
+	var total time.Duration
+	for _, o := range r.Outages {
+		total += o.End - o.Start
+	}
+	if total > r.Duration {
+		return r.Duration
+	}
+	return total
+}
@@ -0,0 +1,63 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailureTimelineOutages(t *testing.T) {
+	// arrange
+	start := time.Now()
+	f := &failureTimeline{}
+	f.reset(start)
+
+	// act
+	f.add(start.Add(10 * time.Millisecond))
+	f.add(start.Add(150 * time.Millisecond))
+	f.add(start.Add(160 * time.Millisecond))
+	f.add(start.Add(950 * time.Millisecond))
+	outages := f.outages()
+	f.add(start.Add(time.Second))
+
+	// assert
+	require.Equal(t, []Outage{
+		{Start: 0, End: 200 * time.Millisecond, Errors: 3},
+		{Start: 900 * time.Millisecond, End: time.Second, Errors: 1},
+	}, outages)
+	require.Nil(t, f.periods)
+}
+
+func TestResultGoodput(t *testing.T) {
+	// arrange
+	r := Result{
+		Duration: 2 * time.Second,
+		Ops:      100,
+		Errors:   20,
+		Outages:  []Outage{{Start: 0, End: 200 * time.Millisecond}, {Start: time.Second, End: 1100 * time.Millisecond}},
+	}
+
+	// act & assert
+	require.Equal(t, 40.0, r.Goodput())
+	require.Equal(t, 300*time.Millisecond, r.Downtime())
+	require.Equal(t, 0.0, Result{}.Goodput())
+}
+
+func TestRunOutages(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything).Return(errors.New("failed"))
+	b := Benchmark{Name: "inserts", Type: TypeLoop, Stmt: "INSERT {{.Iter}}"}
+
+	// act
+	result := Run(context.Background(), bencher, b, Options{Iter: 10, Threads: 1})
+
+	// assert
+	require.Len(t, result.Outages, 1)
+	require.Equal(t, 10, result.Outages[0].Errors)
+	require.Equal(t, 0.0, result.Goodput())
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -13,16 +15,36 @@ var (
 	ErrNoMode = errors.New("failed to parse \\benchmark line, missing mode")
 	// ErrNoName is raised when there is no token after \name.
 	ErrNoName = errors.New("missing name after \\name token")
+	// ErrNoPool is raised when there is no token after \capture.
+	ErrNoPool = errors.New("missing pool after \\capture token")
+	// ErrNoTags is raised when there is no token after \tags.
+	ErrNoTags = errors.New("missing tags after \\tags token")
+	// ErrNoWeight is raised when there is no valid weight after \weight.
+	ErrNoWeight = errors.New("missing positive weight after \\weight token")
+	// ErrNoSeed is raised when there is no valid seed after \seed.
+	ErrNoSeed = errors.New("missing non-zero seed after \\seed token")
+	// ErrNoRate is raised when there is no valid rate after \rate.
+	ErrNoRate = errors.New("missing positive rate after \\rate token")
+	// ErrNoExpect is raised when there is no valid expectation after \expect.
+	ErrNoExpect = errors.New("missing expectation after \\expect token, e.g. rows=1")
+	// ErrNoFunc is raised when there is no name and body after \func.
+	ErrNoFunc = errors.New("missing name and body after \\func token")
+	// ErrNoMix is raised when a statement of a mix benchmark isn't preceded by \weight.
+	ErrNoMix = errors.New("statements of a mix benchmark must follow a \\weight line")
 )
 
 // Helper function to determine the benchmark name.
 func getName(benchmark Benchmark, start, line int) string {
 	switch benchmark.Type {
 	case TypeLoop:
+		mode := "loop"
+		if len(benchmark.Mix) > 0 {
+			mode = "mix"
+		}
 		if benchmark.Name != "" {
-			return "(loop) " + benchmark.Name
+			return fmt.Sprintf("(%v) %v", mode, benchmark.Name)
 		}
-		return fmt.Sprintf("(loop) line %v-%v", start, line-1)
+		return fmt.Sprintf("(%v) line %v-%v", mode, start, line-1)
 	case TypeOnce:
 		if benchmark.Name != "" {
 			return "(once) " + benchmark.Name
@@ -40,11 +62,24 @@ func ParseScript(r io.Reader) ([]Benchmark, error) {
 		lineN      = 1             // current line number
 		benchmarks = []Benchmark{} // the result
 		curBench   = Benchmark{Type: TypeLoop, Parallel: false}
+		mixing     bool     // the current loop benchmark is a mix
+		mixStmt    *MixStmt // current statement of the mix
 	)
 
+	// Helper function to append the current statement to the mix
+	flushMix := func() {
+		if mixStmt != nil && mixStmt.Stmt != "" {
+			mixStmt.Stmt = strings.TrimSuffix(mixStmt.Stmt, "\n")
+			curBench.Mix = append(curBench.Mix, *mixStmt)
+		}
+		mixStmt = nil
+	}
+
 	// Helper function to append a new loop benchmark
 	flushLoop := func() {
-		if curBench.Stmt != "" {
+		flushMix()
+		mixing = false
+		if curBench.Stmt != "" || len(curBench.Mix) > 0 {
 			curBench.Stmt = strings.TrimSuffix(curBench.Stmt, "\n")
 			curBench.Name = getName(curBench, loopStart, lineN)
 			benchmarks = append(benchmarks, curBench)
@@ -86,24 +121,69 @@ func ParseScript(r io.Reader) ([]Benchmark, error) {
 				flushLoop()
 				curBench.Type = TypeLoop
 				loopStart = lineN + 1
+			case "mix":
+				flushLoop()
+				curBench.Type = TypeLoop
+				loopStart = lineN + 1
+				mixing = true
 			default:
-				return []Benchmark{}, fmt.Errorf("failed to parse mode, neither 'once' nor 'loop': %v", tokens[0])
+				return []Benchmark{}, fmt.Errorf("failed to parse mode, neither 'once', 'loop' nor 'mix': %v", tokens[0])
 			}
 			// remove the mode token from the tokens
 			tokens = tokens[1:]
 
 			// Parse remaining tokens
-			for _, t := range tokens {
+			for i, t := range tokens {
 				// reminder: can't change 'tokens' inside the range, e.g. 'cutting' with tokens[2:]
 				// so we have to iterate even the token after \name, which could be skipped otherwise.
 				switch t {
 				case "\\parallel":
 					curBench.Parallel = true
+				case "\\tx":
+					curBench.Tx = true
+				case "\\fresh":
+					curBench.Fresh = true
 				case "\\name":
-					if len(tokens) < 2 {
+					if i+1 >= len(tokens) {
 						return []Benchmark{}, ErrNoName
 					}
-					curBench.Name = tokens[1]
+					curBench.Name = tokens[i+1]
+				case "\\capture":
+					if i+1 >= len(tokens) {
+						return []Benchmark{}, ErrNoPool
+					}
+					curBench.Capture = tokens[i+1]
+				case "\\expect":
+					if i+1 >= len(tokens) {
+						return []Benchmark{}, ErrNoExpect
+					}
+					if _, err := parseExpect(tokens[i+1]); err != nil {
+						return []Benchmark{}, fmt.Errorf("line %v: %v", lineN, err)
+					}
+					curBench.Expect = tokens[i+1]
+				case "\\tags":
+					if i+1 >= len(tokens) {
+						return []Benchmark{}, ErrNoTags
+					}
+					curBench.Tags = strings.Split(tokens[i+1], ",")
+				case "\\seed":
+					if i+1 >= len(tokens) {
+						return []Benchmark{}, ErrNoSeed
+					}
+					s, err := strconv.ParseInt(tokens[i+1], 10, 64)
+					if err != nil || s == 0 {
+						return []Benchmark{}, ErrNoSeed
+					}
+					curBench.Seed = s
+				case "\\rate":
+					if i+1 >= len(tokens) {
+						return []Benchmark{}, ErrNoRate
+					}
+					r, err := strconv.ParseFloat(tokens[i+1], 64)
+					if err != nil || r <= 0 {
+						return []Benchmark{}, ErrNoRate
+					}
+					curBench.Rate = r
 				}
 			}
 
@@ -111,6 +191,45 @@ func ParseScript(r io.Reader) ([]Benchmark, error) {
 			continue
 		}
 
+		// Parse '\func' command, which defines a template function for the following statements.
+		if strings.HasPrefix(line, "\\func") {
+			tokens := strings.SplitN(line, " ", 3)
+			if len(tokens) < 3 || tokens[1] == "" || strings.TrimSpace(tokens[2]) == "" {
+				return []Benchmark{}, ErrNoFunc
+			}
+			if err := DefineFunc(tokens[1], strings.TrimSpace(tokens[2])); err != nil {
+				return []Benchmark{}, fmt.Errorf("line %v: %v", lineN, err)
+			}
+			continue
+		}
+
+		// Parse '\weight' command, which starts the next statement of a mix.
+		if strings.HasPrefix(line, "\\weight") {
+			if !mixing {
+				return []Benchmark{}, fmt.Errorf("\\weight on line %v is only allowed in mix benchmarks", lineN)
+			}
+			tokens := strings.Split(line, " ")[1:]
+			if len(tokens) == 0 {
+				return []Benchmark{}, ErrNoWeight
+			}
+			weight, err := strconv.ParseFloat(tokens[0], 64)
+			if err != nil || weight <= 0 {
+				return []Benchmark{}, ErrNoWeight
+			}
+
+			flushMix()
+			mixStmt = &MixStmt{Name: fmt.Sprintf("line %v", lineN+1), Weight: weight}
+			for i, t := range tokens {
+				if t == "\\name" {
+					if i+1 >= len(tokens) {
+						return []Benchmark{}, ErrNoName
+					}
+					mixStmt.Name = tokens[i+1]
+				}
+			}
+			continue
+		}
+
 		// Neither a '\benchmark' nor '\name' command line.
 		// Should be an SQL statement line.
 		// Append the line either as benchmark type once
@@ -125,13 +244,22 @@ func ParseScript(r io.Reader) ([]Benchmark, error) {
 			// As long as there is no mode change, keep it TypeOnce, which is the non-default mode.
 			curBench = Benchmark{Type: TypeOnce}
 		case TypeLoop:
+			if mixing {
+				// Mix, append the line to the current statement of the mix.
+				if mixStmt == nil {
+					return []Benchmark{}, ErrNoMix
+				}
+				mixStmt.Stmt += line + "\n"
+				continue
+			}
 			// Loop, but not finished yet, only append the line to the statement.
 			curBench.Stmt += line + "\n"
 		}
 	}
 
 	// reached the end of the file, append remaining loop statements to benchmark
-	if curBench.Stmt != "" {
+	flushMix()
+	if curBench.Stmt != "" || len(curBench.Mix) > 0 {
 		curBench.Stmt = strings.TrimSuffix(curBench.Stmt, "\n")
 		curBench.Name = getName(curBench, loopStart, lineN)
 		benchmarks = append(benchmarks, curBench)
@@ -139,3 +267,84 @@ func ParseScript(r io.Reader) ([]Benchmark, error) {
 
 	return benchmarks, nil
 }
+
+// ScriptName returns the name of a benchmark parsed by ParseScript without its mode, e.g. "insert"
+// of "(loop) insert", and an empty name when it's named after its lines.
+func ScriptName(name string) string {
+	for _, mode := range []string{"(loop) ", "(mix) ", "(once) "} {
+		name = strings.TrimPrefix(name, mode)
+	}
+	if strings.HasPrefix(name, "line ") {
+		return ""
+	}
+	return name
+}
+
+// FormatScript returns the script of the benchmarks and the functions, the inverse of ParseScript.
+// The statements of once benchmarks are joined to a single line.
+func FormatScript(benchmarks []Benchmark, funcs map[string]string) string {
+	sb := &strings.Builder{}
+	names := make([]string, 0, len(funcs))
+	for name := range funcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(sb, "\\func %v %v\n", name, funcs[name])
+	}
+
+	for i, b := range benchmarks {
+		if i > 0 || len(names) > 0 {
+			sb.WriteString("\n")
+		}
+		mode := b.Type.String()
+		if len(b.Mix) > 0 {
+			mode = "mix"
+		}
+		sb.WriteString("\\benchmark " + mode)
+		if b.Parallel {
+			sb.WriteString(" \\parallel")
+		}
+		if b.Tx {
+			sb.WriteString(" \\tx")
+		}
+		if b.Fresh {
+			sb.WriteString(" \\fresh")
+		}
+		if name := ScriptName(b.Name); name != "" {
+			sb.WriteString(" \\name " + name)
+		}
+		if b.Capture != "" {
+			sb.WriteString(" \\capture " + b.Capture)
+		}
+		if b.Expect != "" {
+			sb.WriteString(" \\expect " + b.Expect)
+		}
+		if len(b.Tags) > 0 {
+			sb.WriteString(" \\tags " + strings.Join(b.Tags, ","))
+		}
+		if b.Seed != 0 {
+			fmt.Fprintf(sb, " \\seed %v", b.Seed)
+		}
+		if b.Rate > 0 {
+			sb.WriteString(" \\rate " + strconv.FormatFloat(b.Rate, 'f', -1, 64))
+		}
+		sb.WriteString("\n")
+
+		switch {
+		case len(b.Mix) > 0:
+			for _, m := range b.Mix {
+				sb.WriteString("\\weight " + strconv.FormatFloat(m.Weight, 'f', -1, 64))
+				if name := ScriptName(m.Name); name != "" {
+					sb.WriteString(" \\name " + name)
+				}
+				sb.WriteString("\n" + m.Stmt + "\n")
+			}
+		case b.Type == TypeOnce:
+			sb.WriteString(strings.Replace(b.Stmt, "\n", " ", -1) + "\n")
+		default:
+			sb.WriteString(b.Stmt + "\n")
+		}
+	}
+	return sb.String()
+}
@@ -32,7 +32,7 @@ func TestParseScript(t *testing.T) {
 			in:          "\\benchmark unknown-mode",
 			expect: expect{
 				benchmarks: []Benchmark{},
-				err:        errors.New("failed to parse mode, neither 'once' nor 'loop': unknown-mode"),
+				err:        errors.New("failed to parse mode, neither 'once', 'loop' nor 'mix': unknown-mode"),
 			},
 		},
 		{
@@ -234,6 +234,18 @@ func TestParseScript(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "name after other settings",
+			in: `
+			\benchmark loop \parallel \tags write \name insert
+			INSERT INTO ...;
+			`,
+			expect: expect{
+				benchmarks: []Benchmark{
+					{Name: "(loop) insert", Type: TypeLoop, Parallel: true, Tags: []string{"write"}, Stmt: "INSERT INTO ...;"},
+				},
+			},
+		},
 		{
 			description: "loop/set 2/3 names",
 			in: `
@@ -286,6 +298,206 @@ func TestParseScript(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "capture",
+			in: `
+			\benchmark loop \name ids \capture users
+			INSERT INTO ... RETURNING id;
+			`,
+			expect: expect{
+				benchmarks: []Benchmark{
+					{Name: "(loop) ids", Type: TypeLoop, Capture: "users", Stmt: "INSERT INTO ... RETURNING id;"},
+				},
+			},
+		},
+		{
+			description: "expect",
+			in: `
+			\benchmark loop \name user \expect rows=1
+			SELECT * FROM users WHERE id = 1;
+			`,
+			expect: expect{
+				benchmarks: []Benchmark{
+					{Name: "(loop) user", Type: TypeLoop, Expect: "rows=1", Stmt: "SELECT * FROM users WHERE id = 1;"},
+				},
+			},
+		},
+		{
+			description: "transaction",
+			in: `
+			\benchmark loop \name transfer \tx
+			UPDATE accounts SET balance = balance - 1 WHERE id = 1;
+			UPDATE accounts SET balance = balance + 1 WHERE id = 2;
+			`,
+			expect: expect{
+				benchmarks: []Benchmark{
+					{Name: "(loop) transfer", Type: TypeLoop, Tx: true, Stmt: "UPDATE accounts SET balance = balance - 1 WHERE id = 1;\nUPDATE accounts SET balance = balance + 1 WHERE id = 2;"},
+				},
+			},
+		},
+		{
+			description: "fresh connections",
+			in: `
+			\benchmark loop \name connects \fresh
+			SELECT 1;
+			`,
+			expect: expect{
+				benchmarks: []Benchmark{
+					{Name: "(loop) connects", Type: TypeLoop, Fresh: true, Stmt: "SELECT 1;"},
+				},
+			},
+		},
+		{
+			description: "tags",
+			in: `
+			\benchmark loop \name reports \tags read,bulk
+			SELECT * FROM ...;
+			`,
+			expect: expect{
+				benchmarks: []Benchmark{
+					{Name: "(loop) reports", Type: TypeLoop, Tags: []string{"read", "bulk"}, Stmt: "SELECT * FROM ...;"},
+				},
+			},
+		},
+		{
+			description: "fail/missing tags",
+			in:          "\\benchmark loop \\tags",
+			expect: expect{
+				benchmarks: []Benchmark{},
+				err:        ErrNoTags,
+			},
+		},
+		{
+			description: "seed",
+			in: `
+			\benchmark loop \name users \seed 42
+			SELECT * FROM ...;
+			`,
+			expect: expect{
+				benchmarks: []Benchmark{
+					{Name: "(loop) users", Type: TypeLoop, Seed: 42, Stmt: "SELECT * FROM ...;"},
+				},
+			},
+		},
+		{
+			description: "fail/invalid seed",
+			in:          "\\benchmark loop \\seed 0",
+			expect: expect{
+				benchmarks: []Benchmark{},
+				err:        ErrNoSeed,
+			},
+		},
+		{
+			description: "rate",
+			in: `
+			\benchmark loop \name users \rate 250.5
+			SELECT * FROM ...;
+			`,
+			expect: expect{
+				benchmarks: []Benchmark{
+					{Name: "(loop) users", Type: TypeLoop, Rate: 250.5, Stmt: "SELECT * FROM ...;"},
+				},
+			},
+		},
+		{
+			description: "fail/invalid rate",
+			in:          "\\benchmark loop \\rate -1",
+			expect: expect{
+				benchmarks: []Benchmark{},
+				err:        ErrNoRate,
+			},
+		},
+		{
+			description: "func",
+			in: `
+			\func test_parser_func ORD-{{index .Args 0}}
+			\benchmark loop
+			INSERT INTO orders VALUES ('{{.Func "test_parser_func" .Iter}}');
+			`,
+			expect: expect{
+				benchmarks: []Benchmark{
+					{Name: "(loop) line 4-5", Type: TypeLoop, Stmt: "INSERT INTO orders VALUES ('{{.Func \"test_parser_func\" .Iter}}');"},
+				},
+			},
+		},
+		{
+			description: "fail/missing func body",
+			in:          "\\func order_id",
+			expect: expect{
+				benchmarks: []Benchmark{},
+				err:        ErrNoFunc,
+			},
+		},
+		{
+			description: "fail/missing pool",
+			in:          "\\benchmark loop \\capture",
+			expect: expect{
+				benchmarks: []Benchmark{},
+				err:        ErrNoPool,
+			},
+		},
+		{
+			description: "fail/missing expectation",
+			in:          "\\benchmark loop \\expect",
+			expect: expect{
+				benchmarks: []Benchmark{},
+				err:        ErrNoExpect,
+			},
+		},
+		{
+			description: "fail/invalid expectation",
+			in:          "\\benchmark loop \\expect rows~1",
+			expect: expect{
+				benchmarks: []Benchmark{},
+				err:        errors.New(`line 1: invalid expectation "rows~1", e.g. rows=1, rows>0 or value=42`),
+			},
+		},
+		{
+			description: "mix",
+			in: `
+			\benchmark mix \name ycsb
+			\weight 80 \name read
+			SELECT * FROM ...;
+			\weight 20
+			UPDATE ...;
+			INSERT INTO ...;
+			\benchmark loop
+			DELETE FROM ...;
+			`,
+			expect: expect{
+				benchmarks: []Benchmark{
+					{Name: "(mix) ycsb", Type: TypeLoop, Mix: []MixStmt{
+						{Name: "read", Weight: 80, Stmt: "SELECT * FROM ...;"},
+						{Name: "line 6", Weight: 20, Stmt: "UPDATE ...;\nINSERT INTO ...;"},
+					}},
+					{Name: "(loop) line 9-10", Type: TypeLoop, Stmt: "DELETE FROM ...;"},
+				},
+			},
+		},
+		{
+			description: "fail/mix statement without weight",
+			in:          "\\benchmark mix\nSELECT * FROM ...;",
+			expect: expect{
+				benchmarks: []Benchmark{},
+				err:        ErrNoMix,
+			},
+		},
+		{
+			description: "fail/invalid weight",
+			in:          "\\benchmark mix\n\\weight -1",
+			expect: expect{
+				benchmarks: []Benchmark{},
+				err:        ErrNoWeight,
+			},
+		},
+		{
+			description: "fail/weight outside of mix",
+			in:          "\\weight 1",
+			expect: expect{
+				benchmarks: []Benchmark{},
+				err:        errors.New("\\weight on line 1 is only allowed in mix benchmarks"),
+			},
+		},
 	}
 
 	for _, tt := range testCases {
@@ -301,3 +513,40 @@ func TestParseScript(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatScript(t *testing.T) {
+	// arrange
+	script := `\benchmark once \name setup \fresh
+CREATE TABLE t (id INT, v TEXT);
+\benchmark loop \name insert \tags write \tx \seed 42 \rate 500 \expect rows=0
+INSERT INTO t VALUES ({{.Iter}}, 'a  b');
+SELECT 1;
+\benchmark mix \parallel \name rw \capture ids
+\weight 80 \name read
+SELECT * FROM t WHERE id = {{.ZipfInt 100}};
+\weight 20.5
+UPDATE t SET v = 'x' WHERE id = {{.Iter}};
+`
+	want, err := ParseScript(strings.NewReader(script))
+	require.NoError(t, err)
+
+	// act
+	formatted := FormatScript(want, map[string]string{"tenant": "t{{.Iter}}"})
+	got, err := ParseScript(strings.NewReader(formatted))
+
+	// assert
+	require.NoError(t, err)
+	require.Contains(t, formatted, "\\func tenant t{{.Iter}}\n")
+	require.Len(t, got, 3)
+	require.Equal(t, want[:2], got[:2])
+	require.Equal(t, "(mix) rw", got[2].Name)
+	require.Equal(t, "read", got[2].Mix[0].Name)
+	require.Equal(t, want[2].Mix[1].Weight, got[2].Mix[1].Weight)
+	require.Equal(t, want[2].Mix[1].Stmt, got[2].Mix[1].Stmt)
+}
+
+func TestScriptName(t *testing.T) {
+	require.Equal(t, "insert", ScriptName("(loop) insert"))
+	require.Equal(t, "", ScriptName("(mix) line 3-5"))
+	require.Equal(t, "", ScriptName("line 4"))
+}
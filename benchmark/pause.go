@@ -0,0 +1,80 @@
+package benchmark
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// pause holds the loop benchmarks between two statements, e.g. while taking a backup
+// of the database server. The paused time is excluded from the duration of the benchmarks.
+var pause = struct {
+	sync.Mutex
+	resumed chan struct{} // closed on resume, nil when running
+	since   int64         // start of the current pause in unix nanoseconds, 0 when running (atomic)
+	total   int64         // nanoseconds of the finished pauses (atomic)
+}{}
+
+// Pause pauses the loop benchmarks, the running statements finish. It returns false,
+// when they are already paused.
+func Pause() bool {
+	pause.Lock()
+	defer pause.Unlock()
+	if pause.resumed != nil {
+		return false
+	}
+	pause.resumed = make(chan struct{})
+	atomic.StoreInt64(&pause.since, time.Now().UnixNano())
+	return true
+}
+
+// Resume resumes the paused loop benchmarks. It returns false, when they aren't paused.
+func Resume() bool {
+	pause.Lock()
+	defer pause.Unlock()
+	if pause.resumed == nil {
+		return false
+	}
+	since := atomic.SwapInt64(&pause.since, 0)
+	atomic.AddInt64(&pause.total, time.Now().UnixNano()-since)
+	close(pause.resumed)
+	pause.resumed = nil
+	return true
+}
+
+// pausedTotal returns the total paused time, including the current pause.
+func pausedTotal() time.Duration {
+	total := atomic.LoadInt64(&pause.total)
+	if since := atomic.LoadInt64(&pause.since); since > 0 {
+		total += time.Now().UnixNano() - since
+	}
+	return time.Duration(total)
+}
+
+// waitResumed blocks while the benchmarks are paused. It returns false, when it was
+// cancelled by the context.
+func waitResumed(ctx context.Context) bool {
+	if atomic.LoadInt64(&pause.since) == 0 {
+		return true
+	}
+
+	pause.Lock()
+	resumed := pause.resumed
+	pause.Unlock()
+	if resumed == nil {
+		return true
+	}
+
+	select {
+	case <-resumed:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Paused returns whether the loop benchmarks are paused.
+func Paused() bool {
+	return atomic.LoadInt64(&pause.since) != 0
+}
@@ -0,0 +1,34 @@
+package benchmark
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPause(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything)
+	b := Benchmark{Name: "inserts", Type: TypeLoop, Stmt: "INSERT {{.Iter}}"}
+
+	// act
+	require.True(t, Pause())
+	require.False(t, Pause())
+
+	done := make(chan Result)
+	go func() { done <- Run(context.Background(), bencher, b, Options{Iter: 10, Threads: 2}) }()
+	time.Sleep(50 * time.Millisecond)
+	bencher.AssertNumberOfCalls(t, "Exec", 0)
+
+	require.True(t, Resume())
+	require.False(t, Resume())
+	result := <-done
+
+	// assert
+	bencher.AssertNumberOfCalls(t, "Exec", 10)
+	require.True(t, result.Duration < 50*time.Millisecond, result.Duration)
+}
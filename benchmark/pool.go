@@ -0,0 +1,80 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// pools contains the values captured by the benchmarks, which can be used by later benchmarks.
+var pools = struct {
+	sync.RWMutex
+	m map[string][]string
+}{m: map[string][]string{}}
+
+// addToPool appends the values to the named pool.
+func addToPool(name string, values ...string) {
+	if len(values) == 0 {
+		return
+	}
+	pools.Lock()
+	pools.m[name] = append(pools.m[name], values...)
+	pools.Unlock()
+}
+
+// capturer executes the statements with the querier, adds the returned values to the pool
+// and verifies them, when there is an expectation.
+type capturer struct {
+	Bencher
+	querier Querier
+	pool    string       // empty when the values aren't captured
+	expect  *expectation // nil when the values aren't verified
+}
+
+// Exec executes the statement, captures and verifies the returned values.
+// A failed statement neither captures nor verifies anything.
+func (c *capturer) Exec(stmt string) error {
+	return c.ExecContext(context.Background(), stmt)
+}
+
+// ExecContext is like Exec, the statement is cancelled with the context, when the querier supports it.
+func (c *capturer) ExecContext(ctx context.Context, stmt string) error {
+	var (
+		values []string
+		err    error
+	)
+	if q, ok := c.querier.(ContextQuerier); ok {
+		values, err = q.QueryContext(ctx, stmt)
+	} else {
+		values, err = c.querier.Query(stmt)
+	}
+	if err != nil {
+		return err
+	}
+	if c.pool != "" {
+		addToPool(c.pool, values...)
+	}
+	if c.expect != nil {
+		return c.expect.check(values)
+	}
+	return nil
+}
+
+// cancellable returns whether the querier is cancelled with the context of ExecContext.
+func (c *capturer) cancellable() bool {
+	_, ok := c.querier.(ContextQuerier)
+	return ok
+}
+
+// Pick returns a random value of the named pool, which was captured by a previous benchmark
+// with \capture, e.g. to select rows which actually exist.
+func (d *tmplData) Pick(name string) (string, error) {
+	pools.RLock()
+	defer pools.RUnlock()
+
+	values := pools.m[name]
+	if len(values) == 0 {
+		return "", fmt.Errorf("no values captured in pool %q", name)
+	}
+	return values[d.random().Intn(len(values))], nil
+}
@@ -0,0 +1,68 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockedQuerier struct {
+	mockedBencher
+}
+
+func (q *mockedQuerier) Query(s string) ([]string, error) {
+	args := q.Called(s)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func TestCapture(t *testing.T) {
+	// arrange
+	SetFastPlaceholders(true)
+	defer SetFastPlaceholders(false)
+	delete(pools.m, "test_capture")
+	bencher := &mockedQuerier{}
+	bencher.On("Query", "INSERT 1").Return([]string{"a"}, nil)
+	bencher.On("Query", "INSERT 2").Return([]string{"b", "c"}, nil)
+	b := Benchmark{Name: "inserts", Type: TypeLoop, Stmt: "INSERT {iter}", Capture: "test_capture"}
+
+	// act
+	Run(context.Background(), bencher, b, Options{Iter: 2, Threads: 1})
+
+	// assert
+	require.ElementsMatch(t, []string{"a", "b", "c"}, pools.m["test_capture"])
+	bencher.AssertNotCalled(t, "Exec", mock.Anything)
+}
+
+func TestCaptureQueryError(t *testing.T) {
+	// arrange
+	bencher := &mockedQuerier{}
+	bencher.On("Query", "INSERT 1").Return([]string(nil), errors.New("deadlock detected"))
+	b := Benchmark{Name: "inserts", Type: TypeLoop, Stmt: "INSERT {iter}", Capture: "test_capture_error"}
+	SetFastPlaceholders(true)
+	defer SetFastPlaceholders(false)
+
+	// act
+	res := Run(context.Background(), bencher, b, Options{Iter: 1, Threads: 1})
+
+	// assert
+	require.Equal(t, 1, res.Errors)
+	require.Equal(t, map[string]int{ErrKindConflict: 1}, res.ErrorKinds)
+}
+
+func TestPick(t *testing.T) {
+	// arrange
+	addToPool("test_pick", "42")
+	d := &tmplData{}
+
+	// act
+	got, err := d.Pick("test_pick")
+	_, errEmpty := d.Pick("test_empty")
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, "42", got)
+	require.Error(t, errEmpty)
+}
@@ -0,0 +1,95 @@
+package benchmark
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// PoolSample is a sample of the connection pool of database/sql. The counters are the ones
+// of the interval since the previous sample.
+type PoolSample struct {
+	Time              time.Time
+	Open              int           // established connections, in use and idle
+	InUse             int           // connections in use
+	Idle              int           // idle connections
+	WaitCount         int64         // statements, which waited for a connection
+	WaitDuration      time.Duration // total time waited for connections
+	MaxIdleClosed     int64         // connections closed, because there were too many idle ones
+	MaxIdleTimeClosed int64         // connections closed, because they were idle for too long
+	MaxLifetimeClosed int64         // connections closed, because they reached their max. lifetime
+}
+
+// PoolStats samples the connection pool of the benchmarks in intervals, e.g. as the time waiting
+// for a connection adds to the latency of the statements, but not to the work of the server.
+type PoolStats struct {
+	stats    func() sql.DBStats
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+
+	mu      sync.Mutex
+	samples []PoolSample
+}
+
+// StartPoolStats samples the stats, e.g. of sql.DB.Stats, every interval until Stop is called.
+func StartPoolStats(stats func() sql.DBStats, interval time.Duration) *PoolStats {
+	p := &PoolStats{
+		stats:    stats,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+func (p *PoolStats) run() {
+	defer close(p.done)
+
+	var (
+		last   = p.stats()
+		ticker = time.NewTicker(p.interval)
+	)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case now := <-ticker.C:
+			s := p.stats()
+			sample := PoolSample{
+				Time:              now,
+				Open:              s.OpenConnections,
+				InUse:             s.InUse,
+				Idle:              s.Idle,
+				WaitCount:         s.WaitCount - last.WaitCount,
+				WaitDuration:      s.WaitDuration - last.WaitDuration,
+				MaxIdleClosed:     s.MaxIdleClosed - last.MaxIdleClosed,
+				MaxIdleTimeClosed: s.MaxIdleTimeClosed - last.MaxIdleTimeClosed,
+				MaxLifetimeClosed: s.MaxLifetimeClosed - last.MaxLifetimeClosed,
+			}
+			p.mu.Lock()
+			p.samples = append(p.samples, sample)
+			p.mu.Unlock()
+			last = s
+		}
+	}
+}
+
+// Reset returns the samples since the last reset.
+func (p *PoolStats) Reset() []PoolSample {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	samples := p.samples
+	p.samples = nil
+	return samples
+}
+
+// Stop stops the sampling.
+func (p *PoolStats) Stop() {
+	close(p.stop)
+	<-p.done
+}
@@ -0,0 +1,42 @@
+package benchmark
+
+import (
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolStats(t *testing.T) {
+	// arrange
+	var (
+		mu    sync.Mutex
+		calls int64
+	)
+	stats := func() sql.DBStats {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		// each statement waits 1ms for one of the connections
+		return sql.DBStats{OpenConnections: 4, InUse: 3, Idle: 1, WaitCount: calls * 10, WaitDuration: time.Duration(calls*10) * time.Millisecond}
+	}
+
+	// act
+	p := StartPoolStats(stats, 10*time.Millisecond)
+	time.Sleep(45 * time.Millisecond)
+	samples := p.Reset()
+	p.Stop()
+
+	// assert, the counters are the ones of the interval
+	require.True(t, len(samples) >= 3, len(samples))
+	for _, s := range samples {
+		require.Equal(t, 4, s.Open)
+		require.Equal(t, 3, s.InUse)
+		require.Equal(t, 1, s.Idle)
+		require.Equal(t, int64(10), s.WaitCount)
+		require.Equal(t, 10*time.Millisecond, s.WaitDuration)
+	}
+	require.Empty(t, p.Reset())
+}
@@ -0,0 +1,175 @@
+package benchmark
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrNoRows is raised when there is no positive number after \rows.
+var ErrNoRows = errors.New("missing positive number of rows after \\rows token")
+
+// PopulateStmt is a statement populating the database before the benchmarks.
+type PopulateStmt struct {
+	Stmt string
+	Rows int // insert this many rows with the single-row insert Stmt, numbered by {{.Iter}} (0 -> execute once)
+}
+
+// ParsePopulate parses a populate file: each line is a statement, which is executed once, e.g. to create
+// a table, or inserts the rows given by the preceding \rows line, e.g.:
+//
+//	CREATE TABLE users (id INT, name TEXT);
+//	\rows 100000
+//	INSERT INTO users VALUES ({{.Iter}}, '{{.FakeName}}');
+//
+// Empty lines and comments starting with -- are skipped.
+func ParsePopulate(r io.Reader) ([]PopulateStmt, error) {
+	var (
+		stmts   []PopulateStmt
+		rows    int
+		scanner = bufio.NewScanner(r)
+		lineN   = 0
+	)
+	for scanner.Scan() {
+		lineN++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "--") {
+			continue
+		}
+		if strings.HasPrefix(line, "\\rows") {
+			tokens := strings.Fields(line)
+			n, err := 0, ErrNoRows
+			if len(tokens) == 2 {
+				n, err = strconv.Atoi(tokens[1])
+			}
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("line %v: %v", lineN, ErrNoRows)
+			}
+			rows = n
+			continue
+		}
+		if rows > 0 {
+			if _, _, err := splitValues(line); err != nil {
+				return nil, fmt.Errorf("line %v: %v", lineN, err)
+			}
+		}
+		stmts = append(stmts, PopulateStmt{Stmt: line, Rows: rows})
+		rows = 0
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if rows > 0 {
+		return nil, fmt.Errorf("line %v: missing insert after \\rows", lineN)
+	}
+	return stmts, nil
+}
+
+// valuesKeyword matches the last VALUES keyword of an insert.
+var valuesKeyword = regexp.MustCompile(`(?is)^(.*\bVALUES)\s*(\(.*\))\s*;?$`)
+
+// splitValues splits the single-row insert into the part up to VALUES and the tuple of the row.
+func splitValues(insert string) (prefix, tuple string, err error) {
+	m := valuesKeyword.FindStringSubmatch(insert)
+	if m == nil {
+		return "", "", fmt.Errorf("not a single-row insert ending with VALUES (...): %v", insert)
+	}
+	return m[1], m[2], nil
+}
+
+// Populate executes the statements one after another. The rows of an insert are inserted with
+// multi-row inserts of batch rows, which are executed by the threads concurrently. It returns
+// the number of inserted rows, it stops at the first failed statement or when ctx is cancelled.
+func Populate(ctx context.Context, bencher Bencher, stmts []PopulateStmt, batch, threads int) (int, error) {
+	if batch < 1 {
+		batch = 1
+	}
+	if threads < 1 {
+		threads = 1
+	}
+
+	var total int
+	for _, s := range stmts {
+		if s.Rows == 0 {
+			if err := bencher.Exec(s.Stmt); err != nil {
+				return total, fmt.Errorf("%v failed: %v", s.Stmt, err)
+			}
+			continue
+		}
+		if err := populateRows(ctx, bencher, s, batch, threads); err != nil {
+			return total, err
+		}
+		total += s.Rows
+	}
+	return total, nil
+}
+
+// populateRows inserts the rows of the statement in batches.
+func populateRows(ctx context.Context, bencher Bencher, s PopulateStmt, batch, threads int) error {
+	prefix, tuple, err := splitValues(s.Stmt)
+	if err != nil {
+		return err
+	}
+	parsed, err := parseStmt(tuple)
+	if err != nil {
+		return fmt.Errorf("failed to parse %v: %v", tuple, err)
+	}
+
+	var (
+		next     int64 // index of the next batch
+		batches  = int64((s.Rows + batch - 1) / batch)
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+		failed   int32
+	)
+	for thread := 0; thread < threads; thread++ {
+		wg.Add(1)
+		go func(thread int) {
+			defer wg.Done()
+			b := newBuilder(parsed)
+			b.data.Threads = threads
+			b.setThread(thread)
+			sb := &strings.Builder{}
+
+			for atomic.LoadInt32(&failed) == 0 && ctx.Err() == nil {
+				i := atomic.AddInt64(&next, 1) - 1
+				if i >= batches {
+					return
+				}
+				from := int(i)*batch + 1
+				to := from + batch - 1
+				if to > s.Rows {
+					to = s.Rows
+				}
+
+				sb.Reset()
+				sb.WriteString(prefix + " ")
+				for row := from; row <= to; row++ {
+					if row > from {
+						sb.WriteString(", ")
+					}
+					b.data.Row = row - from
+					sb.WriteString(b.build(row))
+				}
+				if err := bencher.Exec(sb.String()); err != nil {
+					atomic.StoreInt32(&failed, 1)
+					errOnce.Do(func() { firstErr = fmt.Errorf("failed to insert rows %v-%v: %v", from, to, err) })
+				}
+			}
+		}(thread)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,112 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePopulate(t *testing.T) {
+	testCases := []struct {
+		description string
+		in          string
+		expect      []PopulateStmt
+		err         bool
+	}{
+		{
+			description: "once and rows",
+			in:          "-- users\nCREATE TABLE users (id INT);\n\n\\rows 10\nINSERT INTO users VALUES ({{.Iter}});\n",
+			expect: []PopulateStmt{
+				{Stmt: "CREATE TABLE users (id INT);"},
+				{Stmt: "INSERT INTO users VALUES ({{.Iter}});", Rows: 10},
+			},
+		},
+		{
+			description: "fail/missing rows",
+			in:          "\\rows\nINSERT INTO users VALUES (1);",
+			err:         true,
+		},
+		{
+			description: "fail/invalid rows",
+			in:          "\\rows -1\nINSERT INTO users VALUES (1);",
+			err:         true,
+		},
+		{
+			description: "fail/rows without insert",
+			in:          "\\rows 10\n",
+			err:         true,
+		},
+		{
+			description: "fail/rows without values",
+			in:          "\\rows 10\nDELETE FROM users;",
+			err:         true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			// act
+			got, err := ParsePopulate(strings.NewReader(tc.in))
+
+			// assert
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, got)
+		})
+	}
+}
+
+func TestPopulate(t *testing.T) {
+	// arrange
+	var (
+		mu    sync.Mutex
+		execs []string
+	)
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		execs = append(execs, args.String(0))
+		mu.Unlock()
+	})
+	stmts := []PopulateStmt{
+		{Stmt: "CREATE TABLE t (id INT);"},
+		{Stmt: "insert into t values ({{.Iter}});", Rows: 5},
+	}
+
+	// act
+	rows, err := Populate(context.Background(), bencher, stmts, 2, 2)
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, 5, rows)
+	require.Equal(t, "CREATE TABLE t (id INT);", execs[0])
+	inserts := execs[1:]
+	sort.Strings(inserts)
+	require.Equal(t, []string{
+		"insert into t values (1), (2)",
+		"insert into t values (3), (4)",
+		"insert into t values (5)",
+	}, inserts)
+}
+
+func TestPopulateError(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything).Return(errors.New("no such table"))
+
+	// act
+	rows, err := Populate(context.Background(), bencher, []PopulateStmt{{Stmt: "INSERT INTO t VALUES (1);", Rows: 100}}, 10, 4)
+
+	// assert
+	require.Error(t, err)
+	require.Equal(t, 0, rows)
+}
@@ -0,0 +1,110 @@
+package benchmark
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// progress collects the latencies of the executed statements, while a Progress is running.
+var progress = struct {
+	sync.Mutex
+	active    int32 // 1 while running (atomic)
+	latencies []time.Duration
+}{}
+
+// recordProgress records the latency of an executed statement for the running Progress.
+func recordProgress(took time.Duration) {
+	if atomic.LoadInt32(&progress.active) == 0 {
+		return
+	}
+	progress.Lock()
+	progress.latencies = append(progress.latencies, took)
+	progress.Unlock()
+}
+
+// takeProgress returns the latencies recorded since the last call.
+func takeProgress() []time.Duration {
+	progress.Lock()
+	defer progress.Unlock()
+	latencies := progress.latencies
+	progress.latencies = nil
+	return latencies
+}
+
+// ProgressSample contains the progress of the running benchmarks at the end of an interval.
+// The throughput and the latencies are the ones of the interval.
+type ProgressSample struct {
+	Elapsed    time.Duration // since the start of the progress
+	Ops        int64         // executed statements since the start
+	Throughput float64       // operations per second
+	P50        time.Duration
+	P95        time.Duration
+	P99        time.Duration
+}
+
+// Progress reports the executed statements of the benchmarks in intervals, e.g. to follow long runs.
+// Only a single progress can run at a time.
+type Progress struct {
+	interval time.Duration
+	report   func(ProgressSample)
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// StartProgress passes a sample to report every interval, until Stop is called.
+func StartProgress(interval time.Duration, report func(ProgressSample)) *Progress {
+	takeProgress()
+	atomic.StoreInt32(&progress.active, 1)
+
+	p := &Progress{
+		interval: interval,
+		report:   report,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// Stop stops the progress, the last partial interval isn't reported.
+func (p *Progress) Stop() {
+	close(p.stop)
+	<-p.done
+	atomic.StoreInt32(&progress.active, 0)
+	takeProgress()
+}
+
+func (p *Progress) run() {
+	defer close(p.done)
+
+	var (
+		start  = time.Now()
+		last   = start
+		ops    int64
+		ticker = time.NewTicker(p.interval)
+	)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case now := <-ticker.C:
+			latencies := takeProgress()
+			ops += int64(len(latencies))
+			s := ProgressSample{
+				Elapsed: now.Sub(start),
+				Ops:     ops,
+				P50:     percentile(latencies, 0.5),
+				P95:     percentile(latencies, 0.95),
+				P99:     percentile(latencies, 0.99),
+			}
+			if elapsed := now.Sub(last); elapsed > 0 {
+				s.Throughput = float64(len(latencies)) / elapsed.Seconds()
+			}
+			last = now
+			p.report(s)
+		}
+	}
+}
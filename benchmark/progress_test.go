@@ -0,0 +1,45 @@
+package benchmark
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgress(t *testing.T) {
+	// arrange
+	var (
+		mu      sync.Mutex
+		samples []ProgressSample
+	)
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything).Run(func(mock.Arguments) { time.Sleep(time.Millisecond) })
+	b := Benchmark{Name: "inserts", Type: TypeLoop, Stmt: "INSERT {{.Iter}}"}
+
+	// act
+	p := StartProgress(10*time.Millisecond, func(s ProgressSample) {
+		mu.Lock()
+		defer mu.Unlock()
+		samples = append(samples, s)
+	})
+	Run(context.Background(), bencher, b, Options{Duration: 55 * time.Millisecond, Threads: 2})
+	p.Stop()
+
+	// assert, the ops accumulate while the latencies and the throughput are the ones of the interval
+	mu.Lock()
+	defer mu.Unlock()
+	require.True(t, len(samples) >= 3, len(samples))
+	for i, s := range samples[:3] {
+		require.True(t, s.Throughput > 0, s)
+		require.True(t, s.P50 >= time.Millisecond && s.P50 <= s.P95 && s.P95 <= s.P99, s)
+		if i > 0 {
+			require.True(t, s.Ops > samples[i-1].Ops)
+			require.True(t, s.Elapsed > samples[i-1].Elapsed)
+		}
+	}
+	require.Empty(t, takeProgress(), "not recorded after the stop")
+}
@@ -0,0 +1,137 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseRamp returns the steps of a ramp, either a range doubling the load, e.g. "1-64",
+// a range increasing it by a fixed amount, e.g. "100-1000+100", or a list, e.g. "1,4,16".
+func ParseRamp(s string) ([]float64, error) {
+	invalid := fmt.Errorf("invalid ramp %q, use a range, e.g. 1-64 or 100-1000+100, or a list, e.g. 1,4,16", s)
+
+	if strings.Contains(s, ",") {
+		var steps []float64
+		for _, part := range strings.Split(s, ",") {
+			v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil || v <= 0 || (len(steps) > 0 && v <= steps[len(steps)-1]) {
+				return nil, invalid
+			}
+			steps = append(steps, v)
+		}
+		return steps, nil
+	}
+
+	var (
+		rng  = s
+		incr float64
+		err  error
+	)
+	if i := strings.Index(s, "+"); i >= 0 {
+		rng = s[:i]
+		if incr, err = strconv.ParseFloat(s[i+1:], 64); err != nil || incr <= 0 {
+			return nil, invalid
+		}
+	}
+	bounds := strings.SplitN(rng, "-", 2)
+	if len(bounds) != 2 {
+		return nil, invalid
+	}
+	from, err := strconv.ParseFloat(bounds[0], 64)
+	if err != nil || from <= 0 {
+		return nil, invalid
+	}
+	to, err := strconv.ParseFloat(bounds[1], 64)
+	if err != nil || to < from {
+		return nil, invalid
+	}
+
+	var steps []float64
+	for v := from; v <= to; {
+		steps = append(steps, v)
+		if incr > 0 {
+			v += incr
+		} else {
+			v *= 2
+		}
+	}
+	return steps, nil
+}
+
+// RampOptions configures a ramp of the load in steps.
+type RampOptions struct {
+	Steps    []float64     // threads of each step, or the request rates with Rates
+	Rates    bool          // the steps are request rates of Threads threads instead of threads
+	Threads  int           // number of concurrent routines of the request rates
+	Duration time.Duration // duration of each step
+	Offset   int           // added to the iteration counter
+}
+
+// RampStep contains the measurements of a step of the ramp.
+type RampStep struct {
+	Threads    int
+	Rate       float64 // requested operations per second (0 -> unlimited)
+	Ops        int     // executed statements
+	Errors     int     // failed statements
+	Throughput float64 // executed operations per second
+	P50        time.Duration
+	P99        time.Duration
+}
+
+// load returns the load of the step, the rate or the threads.
+func (s RampStep) load() float64 {
+	if s.Rate > 0 {
+		return s.Rate
+	}
+	return float64(s.Threads)
+}
+
+// RampResult contains the steps of the ramp and the saturation point.
+type RampResult struct {
+	Steps      []RampStep
+	Saturation int // index of the step, after which more load barely increased the throughput (-1 -> not saturated)
+}
+
+// Ramp executes the loop benchmark with an increasing load, the threads or the request rate of each
+// step, to find the load at which the throughput saturates and the latency collapses. All steps
+// continue the iteration counter. When the context is cancelled, the ramp stops and the interrupted
+// step is discarded.
+func Ramp(ctx context.Context, bencher Bencher, b Benchmark, opts RampOptions) RampResult {
+	offset := opts.Offset
+	var result RampResult
+	for _, load := range opts.Steps {
+		o := Options{Duration: opts.Duration, Threads: int(load), Offset: offset}
+		if opts.Rates {
+			o.Threads, o.Rate = opts.Threads, load
+		}
+		r := Run(ctx, bencher, b, o)
+		offset += r.Ops
+		if ctx.Err() != nil {
+			break
+		}
+		result.Steps = append(result.Steps, RampStep{Threads: o.Threads, Rate: o.Rate, Ops: r.Ops, Errors: r.Errors, Throughput: r.Throughput(), P50: r.P50, P99: r.P99})
+	}
+	result.Saturation = saturation(result.Steps)
+	return result
+}
+
+// saturation returns the index of the first step, after which the throughput increased less than
+// a quarter of the relative increase of the load, e.g. less than 25% when the threads doubled.
+// It's -1 when the throughput kept up with the load.
+func saturation(steps []RampStep) int {
+	for i := 0; i+1 < len(steps); i++ {
+		cur, next := steps[i], steps[i+1]
+		if cur.Throughput == 0 {
+			continue
+		}
+		gain := next.Throughput/cur.Throughput - 1
+		increase := next.load()/cur.load() - 1
+		if gain < 0.25*increase {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,116 @@
+package benchmark
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRamp(t *testing.T) {
+	testCases := []struct {
+		description string
+		s           string
+		want        []float64
+		wantErr     bool
+	}{
+		{description: "doubling", s: "1-64", want: []float64{1, 2, 4, 8, 16, 32, 64}},
+		{description: "doubling beyond the end", s: "3-20", want: []float64{3, 6, 12}},
+		{description: "increment", s: "100-500+200", want: []float64{100, 300, 500}},
+		{description: "list", s: "1, 4,16", want: []float64{1, 4, 16}},
+		{description: "single", s: "8-8", want: []float64{8}},
+		{description: "unordered list", s: "4,1", wantErr: true},
+		{description: "descending", s: "64-1", wantErr: true},
+		{description: "zero", s: "0-8", wantErr: true},
+		{description: "zero increment", s: "1-8+0", wantErr: true},
+		{description: "invalid", s: "many", wantErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			// act
+			steps, err := ParseRamp(tt.s)
+
+			// assert
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, steps)
+		})
+	}
+}
+
+func TestRamp(t *testing.T) {
+	// arrange
+	// the statements are serialized, more threads don't increase the throughput
+	var mu sync.Mutex
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything).Run(func(mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		time.Sleep(time.Millisecond)
+	})
+	b := Benchmark{Name: "test", Type: TypeLoop, Stmt: "SELECT {{.Iter}};"}
+
+	// act
+	result := Ramp(context.Background(), bencher, b, RampOptions{Steps: []float64{1, 2, 4}, Duration: 100 * time.Millisecond})
+
+	// assert
+	require.Len(t, result.Steps, 3)
+	require.Equal(t, 1, result.Steps[0].Threads)
+	require.Equal(t, 4, result.Steps[2].Threads)
+	require.Equal(t, 0, result.Saturation)
+	for _, s := range result.Steps {
+		require.True(t, s.Ops > 0)
+		require.True(t, s.Throughput > 0)
+	}
+	// the queueing statements wait longer
+	require.True(t, result.Steps[2].P99 > result.Steps[0].P99)
+
+	// the iteration counter continues across the steps
+	stmts := map[string]bool{}
+	for _, c := range bencher.Calls {
+		stmts[c.Arguments.String(0)] = true
+	}
+	require.Len(t, stmts, result.Steps[0].Ops+result.Steps[1].Ops+result.Steps[2].Ops)
+}
+
+func TestSaturation(t *testing.T) {
+	testCases := []struct {
+		description string
+		steps       []RampStep
+		want        int
+	}{
+		{
+			description: "scaling",
+			steps:       []RampStep{{Threads: 1, Throughput: 100}, {Threads: 2, Throughput: 190}, {Threads: 4, Throughput: 350}},
+			want:        -1,
+		},
+		{
+			description: "saturated threads",
+			steps:       []RampStep{{Threads: 1, Throughput: 100}, {Threads: 2, Throughput: 190}, {Threads: 4, Throughput: 200}, {Threads: 8, Throughput: 150}},
+			want:        1,
+		},
+		{
+			description: "saturated rate",
+			steps:       []RampStep{{Rate: 100, Throughput: 100}, {Rate: 200, Throughput: 200}, {Rate: 300, Throughput: 210}},
+			want:        1,
+		},
+		{
+			description: "single step",
+			steps:       []RampStep{{Threads: 1, Throughput: 100}},
+			want:        -1,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			require.Equal(t, tt.want, saturation(tt.steps))
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package benchmark
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// seed is the base seed of the random number generators of the workers, see SetSeed.
+var seed = time.Now().UnixNano()
+
+// SetSeed sets the base seed of the random values of the templates. Each worker has its own
+// random number generator, seeded with the base seed plus the index of the worker,
+// thus the random values of a run can be repeated with the same seed and threads.
+func SetSeed(s int64) {
+	atomic.StoreInt64(&seed, s)
+}
+
+// Seed returns the base seed set by SetSeed, or the random one.
+func Seed() int64 {
+	return atomic.LoadInt64(&seed)
+}
+
+// baseSeed returns the seed of the benchmark, or the one set by SetSeed.
+func (o Options) baseSeed() int64 {
+	if o.Seed != 0 {
+		return o.Seed
+	}
+	return Seed()
+}
+
+// newRand returns the random number generator of a worker.
+func newRand(thread int) *rand.Rand {
+	return rand.New(rand.NewSource(Seed() + int64(thread)))
+}
+
+// random returns the random number generator of the worker. It's not locked,
+// as the template data is only used by a single worker.
+func (d *tmplData) random() *rand.Rand {
+	if d.rand == nil {
+		d.rand = newRand(d.Thread)
+	}
+	return d.rand
+}
+
+// setThread sets the index of the worker executing the statements and seeds its random number generator
+// with the base seed of the builder plus the index.
+func (b *builder) setThread(thread int) {
+	b.data.Thread = thread
+	b.data.random().Seed(b.seed + int64(thread))
+}
+
+// alphanumeric are the characters of RandString.
+const alphanumeric = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// RandString returns a random string of n alphanumeric characters.
+func (d *tmplData) RandString(n int) string {
+	r := d.random()
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphanumeric[r.Intn(len(alphanumeric))]
+	}
+	return string(b)
+}
+
+// RandRange returns a random int64 in [min, max].
+func (d *tmplData) RandRange(min, max int64) int64 {
+	if max <= min {
+		return min
+	}
+	return min + d.random().Int63n(max-min+1)
+}
+
+// UUID returns a random version 4 UUID, e.g. "c0f3a4b2-6f1e-4d3a-9b8c-2e7d5a1f0c9e".
+func (d *tmplData) UUID() string {
+	var u [16]byte
+	d.random().Read(u[:])
+	u[6] = u[6]&0x0f | 0x40 // version 4
+	u[8] = u[8]&0x3f | 0x80 // variant 10
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
+
+// timeLayouts are the accepted layouts of the bounds of RandDate and RandTimestamp.
+var timeLayouts = []string{"2006-01-02", "2006-01-02 15:04:05", time.RFC3339}
+
+// parseTime parses a bound of RandDate and RandTimestamp.
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, use e.g. \"2024-01-31\" or \"2024-01-31 12:00:00\"", s)
+}
+
+// randTime returns a random time in [from, to).
+func (d *tmplData) randTime(from, to string) (time.Time, error) {
+	start, err := parseTime(from)
+	if err != nil {
+		return time.Time{}, err
+	}
+	end, err := parseTime(to)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !end.After(start) {
+		return start, nil
+	}
+	return start.Add(time.Duration(d.random().Int63n(int64(end.Sub(start))))), nil
+}
+
+// RandDate returns a random date in [from, to), e.g. "2024-01-31".
+func (d *tmplData) RandDate(from, to string) (string, error) {
+	t, err := d.randTime(from, to)
+	return t.Format("2006-01-02"), err
+}
+
+// RandTimestamp returns a random timestamp in [from, to), e.g. "2024-01-31 12:34:56".
+func (d *tmplData) RandTimestamp(from, to string) (string, error) {
+	t, err := d.randTime(from, to)
+	return t.Format("2006-01-02 15:04:05"), err
+}
+
+// RandChoice returns one of the values, chosen with equal probability.
+func (d *tmplData) RandChoice(values ...interface{}) (interface{}, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no values to choose from")
+	}
+	return values[d.random().Intn(len(values))], nil
+}
+
+// RandWeighted returns one of the values, given as pairs of value and weight, chosen by their weights.
+func (d *tmplData) RandWeighted(pairs ...interface{}) (interface{}, error) {
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("values and weights must be given as pairs")
+	}
+	var (
+		cumulative = make([]float64, len(pairs)/2)
+		total      float64
+	)
+	for i := 1; i < len(pairs); i += 2 {
+		w, err := strconv.ParseFloat(fmt.Sprint(pairs[i]), 64)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("invalid weight %v of %v", pairs[i], pairs[i-1])
+		}
+		total += w
+		cumulative[i/2] = total
+	}
+	r := d.random().Float64() * total
+	for i, c := range cumulative {
+		if r < c {
+			return pairs[2*i], nil
+		}
+	}
+	return pairs[len(pairs)-2], nil
+}
+
+// zipfExponent is the exponent of the Zipf distribution of ZipfInt, the larger the more skewed.
+const zipfExponent = 1.1
+
+// ZipfInt returns a random int64 in [0, n), which is Zipf distributed: 0 is the most frequent value,
+// followed by 1 and so on, e.g. for the skewed access of hot rows.
+func (d *tmplData) ZipfInt(n int64) int64 {
+	if n <= 1 {
+		return 0
+	}
+	return int64(rand.NewZipf(d.random(), zipfExponent, 1, uint64(n-1)).Uint64())
+}
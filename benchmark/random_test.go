@@ -0,0 +1,135 @@
+package benchmark
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetThread(t *testing.T) {
+	// arrange
+	SetSeed(42)
+	stmt, err := parseStmt("{{call .RandInt63}} {rand} {{.FakeName}}")
+	require.NoError(t, err)
+	build := func(thread int) []string {
+		b := newBuilder(stmt)
+		b.setThread(thread)
+		return []string{b.build(1), b.build(2)}
+	}
+
+	// act
+	first, again, other := build(0), build(0), build(1)
+
+	// assert
+	require.Equal(t, first, again, "same seed and thread")
+	require.NotEqual(t, first, other, "other thread")
+	require.NotEqual(t, first[0], first[1], "next iteration")
+}
+
+func TestSeedOfWorker(t *testing.T) {
+	// arrange
+	SetSeed(42)
+	seeded, err := parseStmt("{{call .Seed 7}}{{call .RandInt63}}")
+	require.NoError(t, err)
+	unseeded, err := parseStmt("{{call .RandInt63}}")
+	require.NoError(t, err)
+	a, b := newBuilder(seeded), newBuilder(unseeded)
+	a.setThread(0)
+	b.setThread(1)
+
+	// act
+	stmtA, stmtB := a.build(1), b.build(1)
+
+	// assert, the seed only affects the worker which called it
+	require.Equal(t, strconv.FormatInt(rand.New(rand.NewSource(7)).Int63(), 10), stmtA)
+	require.Equal(t, strconv.FormatInt(rand.New(rand.NewSource(43)).Int63(), 10), stmtB)
+}
+
+func TestRandFuncs(t *testing.T) {
+	testCases := []struct {
+		description string
+		stmt        string
+		want        string // regular expression
+	}{
+		{description: "string", stmt: "{{.RandString 16}}", want: `^[a-zA-Z0-9]{16}$`},
+		{description: "range", stmt: "{{.RandRange 3 4}}", want: `^[34]$`},
+		{description: "uuid", stmt: "{{.UUID}}", want: `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`},
+		{description: "date", stmt: `{{.RandDate "2024-02-01" "2024-03-01"}}`, want: `^2024-02-[0-2][0-9]$`},
+		{description: "timestamp", stmt: `{{.RandTimestamp "2024-02-01 12:00:00" "2024-02-01 13:00:00"}}`, want: `^2024-02-01 12:[0-5][0-9]:[0-5][0-9]$`},
+		{description: "choice", stmt: `{{.RandChoice "a" "b" 3}}`, want: `^(a|b|3)$`},
+		{description: "weighted", stmt: `{{.RandWeighted "a" 0 "b" 1}}`, want: `^b$`},
+		{description: "zipf", stmt: "{{.ZipfInt 100}}", want: `^[0-9]{1,2}$`},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			// arrange
+			stmt, err := parseStmt(tt.stmt)
+			require.NoError(t, err)
+			b := newBuilder(stmt)
+
+			for i := 0; i < 100; i++ {
+				// act
+				got := b.build(i)
+
+				// assert
+				require.Regexp(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRandFuncsInvalid(t *testing.T) {
+	d := &tmplData{}
+
+	_, err := d.RandDate("yesterday", "2024-01-01")
+	require.Error(t, err)
+	_, err = d.RandChoice()
+	require.Error(t, err)
+	_, err = d.RandWeighted("a", 1, "b")
+	require.Error(t, err)
+	_, err = d.RandWeighted("a", "heavy")
+	require.Error(t, err)
+}
+
+func TestZipfInt(t *testing.T) {
+	// arrange
+	d := &tmplData{}
+	counts := make([]int, 1000)
+
+	// act
+	for i := 0; i < 10000; i++ {
+		counts[d.ZipfInt(1000)]++
+	}
+
+	// assert, skewed towards the first values
+	require.True(t, counts[0] > counts[1], "counts %v", counts[:10])
+	require.True(t, counts[1] > counts[100], "counts %v", counts[:10])
+	require.True(t, counts[0] > 1000, "counts %v", counts[:10])
+}
+
+func TestRunSeed(t *testing.T) {
+	// arrange
+	SetSeed(42)
+	var stmts []string
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything).Run(func(args mock.Arguments) { stmts = append(stmts, args.String(0)) })
+	b := Benchmark{Name: "seeded", Type: TypeOnce, Stmt: "{{call .RandInt63}}", Seed: 7}
+
+	// act
+	seeded := Run(context.Background(), bencher, b, Options{})
+	b.Seed = 0
+	unseeded := Run(context.Background(), bencher, b, Options{})
+
+	// assert, the seed of the benchmark overrides the base seed and is reported
+	require.Equal(t, int64(7), seeded.Seed)
+	require.Equal(t, int64(42), unseeded.Seed)
+	require.Equal(t, []string{
+		strconv.FormatInt(rand.New(rand.NewSource(7)).Int63(), 10),
+		strconv.FormatInt(rand.New(rand.NewSource(42)).Int63(), 10),
+	}, stmts)
+}
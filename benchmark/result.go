@@ -0,0 +1,130 @@
+package benchmark
+
+import (
+	"sort"
+	"time"
+)
+
+// Result contains the duration of a benchmark and the latency distribution of its statements.
+// The latencies are zero for parallel benchmarks, which are still running when Run returns.
+type Result struct {
+	Duration time.Duration
+	Ops      int   // executed statements
+	Errors   int   // failed statements
+	Warmup   int   // iterations executed before the measurement, see Options.Warmup
+	Seed     int64 // base seed of the random values, see Options.Seed
+
+	Min  time.Duration
+	Mean time.Duration
+	P50  time.Duration
+	P95  time.Duration
+	P99  time.Duration
+	Max  time.Duration
+
+	Outages    []Outage       // periods with failed statements, see Goodput
+	ErrorKinds map[string]int // failed statements by their kind, see ErrorKind
+	Mix        []MixResult    // of each statement of a mixed workload
+}
+
+// newResult returns the result of the benchmark with the histogram of its latencies.
+func newResult(took time.Duration, latencies *histogram, errors int) Result {
+	r := Result{Duration: took, Ops: latencies.n, Errors: errors}
+	if latencies.n == 0 {
+		return r
+	}
+
+	r.P50 = latencies.percentile(0.50)
+	r.P95 = latencies.percentile(0.95)
+	r.P99 = latencies.percentile(0.99)
+	r.Min = latencies.min
+	r.Max = latencies.max
+	r.Mean = latencies.sum / time.Duration(latencies.n)
+	return r
+}
+
+// ErrorRate returns the fraction of the executed statements, which failed.
+func (r Result) ErrorRate() float64 {
+	if r.Ops == 0 {
+		return 0
+	}
+	return float64(r.Errors) / float64(r.Ops)
+}
+
+// Throughput returns the executed statements per second.
+func (r Result) Throughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Ops) / r.Duration.Seconds()
+}
+
+// MergeResults combines the results of the same benchmark executed by several processes at the
+// same time, e.g. with --procs or --agents. The percentiles can't be merged without the latencies,
+// the highest percentile of the processes is used, an upper bound of the combined one.
+func MergeResults(rs []Result) Result {
+	var (
+		r     Result
+		total time.Duration
+		mixes = map[string][]Result{}
+		hists = map[string][]int{}
+	)
+	for i, p := range rs {
+		if i == 0 {
+			r.Seed = p.Seed
+		}
+		if p.Duration > r.Duration {
+			r.Duration = p.Duration
+		}
+		r.Errors += p.Errors
+		r.Warmup += p.Warmup
+		r.Outages = append(r.Outages, p.Outages...)
+		for kind, n := range p.ErrorKinds {
+			if r.ErrorKinds == nil {
+				r.ErrorKinds = map[string]int{}
+			}
+			r.ErrorKinds[kind] += n
+		}
+		for _, m := range p.Mix {
+			if _, ok := mixes[m.Name]; !ok {
+				r.Mix = append(r.Mix, MixResult{Name: m.Name, Share: m.Share})
+			}
+			mixes[m.Name] = append(mixes[m.Name], m.Result)
+			for j, n := range m.Buckets {
+				if hists[m.Name] == nil {
+					hists[m.Name] = make([]int, len(m.Buckets))
+				}
+				hists[m.Name][j] += n
+			}
+		}
+
+		if p.Ops == 0 {
+			continue
+		}
+		if r.Ops == 0 || p.Min < r.Min {
+			r.Min = p.Min
+		}
+		r.Ops += p.Ops
+		total += p.Mean * time.Duration(p.Ops)
+		if p.P50 > r.P50 {
+			r.P50 = p.P50
+		}
+		if p.P95 > r.P95 {
+			r.P95 = p.P95
+		}
+		if p.P99 > r.P99 {
+			r.P99 = p.P99
+		}
+		if p.Max > r.Max {
+			r.Max = p.Max
+		}
+	}
+	if r.Ops > 0 {
+		r.Mean = total / time.Duration(r.Ops)
+	}
+	sort.Slice(r.Outages, func(i, j int) bool { return r.Outages[i].Start < r.Outages[j].Start })
+	for i, m := range r.Mix {
+		r.Mix[i].Result = MergeResults(mixes[m.Name])
+		r.Mix[i].Buckets = hists[m.Name]
+	}
+	return r
+}
@@ -0,0 +1,72 @@
+package benchmark
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewResult(t *testing.T) {
+	// arrange
+	latencies := &histogram{}
+	for i := 100; i >= 1; i-- {
+		latencies.record(time.Duration(i) * time.Millisecond)
+	}
+
+	// act
+	r := newResult(time.Second, latencies, 0)
+
+	// assert, the percentiles deviate less than 1%
+	require.Equal(t, time.Second, r.Duration)
+	require.Equal(t, 100, r.Ops)
+	require.Equal(t, time.Millisecond, r.Min)
+	require.Equal(t, 50500*time.Microsecond, r.Mean)
+	require.Equal(t, 100*time.Millisecond, r.Max)
+	require.InEpsilon(t, 50*time.Millisecond, r.P50, 0.01)
+	require.InEpsilon(t, 95*time.Millisecond, r.P95, 0.01)
+	require.InEpsilon(t, 99*time.Millisecond, r.P99, 0.01)
+	require.Equal(t, Result{Duration: time.Second}, newResult(time.Second, &histogram{}, 0))
+}
+
+func TestMergeResults(t *testing.T) {
+	// arrange
+	rs := []Result{
+		{
+			Duration: time.Second, Ops: 100, Errors: 2, Seed: 42,
+			Min: 2 * time.Millisecond, Mean: 10 * time.Millisecond, P50: 8 * time.Millisecond, P95: 20 * time.Millisecond, P99: 30 * time.Millisecond, Max: 40 * time.Millisecond,
+			ErrorKinds: map[string]int{"timeout": 2},
+			Outages:    []Outage{{Start: 500 * time.Millisecond, End: 600 * time.Millisecond, Errors: 2}},
+			Mix:        []MixResult{{Name: "reads", Share: 1, Buckets: []int{10, 100}, Result: Result{Ops: 100, Mean: time.Millisecond}}},
+		},
+		{
+			Duration: 2 * time.Second, Ops: 300, Errors: 1, Seed: 42,
+			Min: time.Millisecond, Mean: 30 * time.Millisecond, P50: 5 * time.Millisecond, P95: 25 * time.Millisecond, P99: 28 * time.Millisecond, Max: 50 * time.Millisecond,
+			ErrorKinds: map[string]int{"timeout": 1},
+			Outages:    []Outage{{Start: 100 * time.Millisecond, End: 200 * time.Millisecond, Errors: 1}},
+			Mix:        []MixResult{{Name: "reads", Share: 1, Buckets: []int{0, 300}, Result: Result{Ops: 300, Mean: 3 * time.Millisecond}}},
+		},
+		{Duration: time.Second}, // executed nothing
+	}
+
+	// act
+	r := MergeResults(rs)
+
+	// assert
+	require.Equal(t, 2*time.Second, r.Duration)
+	require.Equal(t, 400, r.Ops)
+	require.Equal(t, 3, r.Errors)
+	require.Equal(t, int64(42), r.Seed)
+	require.Equal(t, time.Millisecond, r.Min)
+	require.Equal(t, 25*time.Millisecond, r.Mean) // (100*10ms + 300*30ms) / 400
+	require.Equal(t, 8*time.Millisecond, r.P50)
+	require.Equal(t, 25*time.Millisecond, r.P95)
+	require.Equal(t, 30*time.Millisecond, r.P99)
+	require.Equal(t, 50*time.Millisecond, r.Max)
+	require.Equal(t, map[string]int{"timeout": 3}, r.ErrorKinds)
+	require.Equal(t, 100*time.Millisecond, r.Outages[0].Start)
+	require.Len(t, r.Mix, 1)
+	require.Equal(t, 400, r.Mix[0].Ops)
+	require.Equal(t, 2500*time.Microsecond, r.Mix[0].Mean)
+	require.Equal(t, []int{10, 400}, r.Mix[0].Buckets)
+}
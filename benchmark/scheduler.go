@@ -0,0 +1,61 @@
+package benchmark
+
+import (
+	"context"
+	"sync"
+)
+
+// Scheduler runs the parallel benchmarks in the background, concurrently with the following
+// benchmarks, and waits for them, e.g. before the tables are cleaned up.
+type Scheduler struct {
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	running  int
+	finished []Finished
+}
+
+// Finished is a parallel benchmark, which finished running.
+type Finished struct {
+	Benchmark Benchmark
+	Result    Result
+}
+
+// Start runs the benchmark in the background, until it's finished or the context is cancelled.
+func (s *Scheduler) Start(ctx context.Context, bencher Bencher, b Benchmark, opts Options) {
+	s.mu.Lock()
+	s.running++
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		r := Run(ctx, bencher, b, opts)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.running--
+		s.finished = append(s.finished, Finished{Benchmark: b, Result: r})
+	}()
+}
+
+// Running returns the number of parallel benchmarks, which are still running.
+func (s *Scheduler) Running() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
+
+// Take returns the benchmarks, which finished since the last call, in the order they finished.
+func (s *Scheduler) Take() []Finished {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	finished := s.finished
+	s.finished = nil
+	return finished
+}
+
+// Wait waits for all running benchmarks and returns the benchmarks, which finished since the last Take.
+func (s *Scheduler) Wait() []Finished {
+	s.wg.Wait()
+	return s.Take()
+}
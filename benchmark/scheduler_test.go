@@ -0,0 +1,34 @@
+package benchmark
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", "SLOW").After(20 * time.Millisecond)
+	bencher.On("Exec", "FAST")
+	s := &Scheduler{}
+
+	// act
+	s.Start(context.Background(), bencher, Benchmark{Name: "slow", Type: TypeOnce, Parallel: true, Stmt: "SLOW"}, Options{})
+	s.Start(context.Background(), bencher, Benchmark{Name: "fast", Type: TypeLoop, Parallel: true, Stmt: "FAST"}, Options{Iter: 10, Threads: 2})
+	running := s.Running()
+	finished := s.Wait()
+
+	// assert, the parallel benchmarks are awaited and measured
+	require.Equal(t, 2, running)
+	require.Zero(t, s.Running())
+	require.Len(t, finished, 2)
+	require.Equal(t, "fast", finished[0].Benchmark.Name)
+	require.Equal(t, 10, finished[0].Result.Ops)
+	require.Equal(t, "slow", finished[1].Benchmark.Name)
+	require.True(t, finished[1].Result.Duration >= 20*time.Millisecond, "duration %v", finished[1].Result.Duration)
+	require.Empty(t, s.Take())
+	bencher.AssertNumberOfCalls(t, "Exec", 11)
+}
@@ -0,0 +1,184 @@
+package benchmark
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RateResult contains the measurements of a benchmark executed with a fixed request rate.
+type RateResult struct {
+	Rate       float64       // requested operations per second
+	Throughput float64       // successfully executed operations per second
+	P99        time.Duration // 99th percentile latency, including the time waiting for a free thread
+	Ops        int           // executed operations, including the failed ones
+	Errors     int           // failed operations
+}
+
+// searchMaxErrors is the max. share of failed operations of a sustainable rate.
+const searchMaxErrors = 0.01
+
+// sustainable returns whether the throughput kept up with the rate, the p99 latency stayed below max
+// and nearly all operations succeeded. A step without any operation is never sustainable.
+func (r RateResult) sustainable(max time.Duration) bool {
+	return r.Ops > 0 && float64(r.Errors) <= searchMaxErrors*float64(r.Ops) &&
+		r.P99 <= max && r.Throughput >= 0.95*r.Rate
+}
+
+// SearchOptions configures the search of the max. sustainable throughput.
+type SearchOptions struct {
+	Threads  int           // number of concurrent routines
+	MaxP99   time.Duration // max. 99th percentile latency of a sustainable rate
+	Duration time.Duration // duration of each step
+	Start    float64       // first rate of the search in operations per second
+	Offset   int           // added to the iteration counter
+}
+
+// SearchResult contains the steps of the search and the max. sustainable throughput.
+type SearchResult struct {
+	Steps       []RateResult
+	Sustainable RateResult // highest sustainable step, zero when not even the start rate was
+}
+
+// Search searches the highest request rate, at which the p99 latency of the loop benchmark stays below
+// the max. The rate is doubled until it's not sustainable anymore, followed by a binary search between
+// the last sustainable and the first unsustainable rate. All steps continue the iteration counter.
+// When not even the start rate is sustainable, the search stops after the first step.
+// When the context is cancelled, the search stops and the interrupted step is discarded.
+func Search(ctx context.Context, bencher Bencher, b Benchmark, opts SearchOptions) SearchResult {
+	t, err := parseBenchmark(b)
+	if err != nil {
+		log.Fatalf("failed to parse template: %v", err)
+	}
+	ctx = withBenchmark(ctx, b.Name)
+
+	var (
+		result = SearchResult{}
+		seed   = Options{Seed: b.Seed}.baseSeed()
+		offset = opts.Offset
+		lo     float64 // highest sustainable rate
+		hi     float64 // lowest unsustainable rate
+	)
+
+	step := func(rate float64) bool {
+		r, n := runRate(ctx, bencher, t, opts.Threads, rate, opts.Duration, offset, seed)
+		offset += n
+		if ctx.Err() != nil {
+			return false
+		}
+		result.Steps = append(result.Steps, r)
+		if !r.sustainable(opts.MaxP99) {
+			return false
+		}
+		if r.Rate > result.Sustainable.Rate {
+			result.Sustainable = r
+		}
+		return true
+	}
+
+	for rate := opts.Start; hi == 0 && ctx.Err() == nil; rate *= 2 {
+		if step(rate) {
+			lo = rate
+		} else {
+			hi = rate
+		}
+	}
+	if lo == 0 {
+		return result
+	}
+
+	// until the rates differ less than 5%
+	for hi-lo > 0.05*hi && ctx.Err() == nil {
+		rate := (lo + hi) / 2
+		if step(rate) {
+			lo = rate
+		} else {
+			hi = rate
+		}
+	}
+	return result
+}
+
+// runRate executes the statement with the given rate for the duration and returns its measurements
+// and the number of executed operations. The latency is measured from the scheduled start of the
+// operation, so operations waiting for a free thread aren't omitted. No more operations are
+// scheduled, when the context is cancelled. The random values of the workers are seeded with the seed.
+func runRate(ctx context.Context, bencher Bencher, t *statement, threads int, rate float64, duration time.Duration, offset int, seed int64) (RateResult, int) {
+	type op struct {
+		iter      int
+		scheduled time.Time
+	}
+
+	var (
+		ops       = make(chan op, threads)
+		wg        = &sync.WaitGroup{}
+		mu        sync.Mutex
+		latencies []time.Duration
+		errors    int
+		interval  = time.Duration(float64(time.Second) / rate)
+		total     = int(rate * duration.Seconds())
+	)
+
+	wg.Add(threads)
+	for routine := 0; routine < threads; routine++ {
+		go func(routine int) {
+			defer wg.Done()
+			defer startWorker(ctx)()
+			ctx, flushTrace := traceWorker(ctx, routine)
+			defer flushTrace()
+
+			builder := newBuilder(t)
+			builder.seed = seed
+			builder.setThread(routine)
+			builder.data.Threads = threads
+
+			var (
+				own    []time.Duration
+				failed int
+			)
+			for o := range ops {
+				builder.data.Op = o.iter
+				if _, err := execute(ctx, bencher, builder.build(o.iter)); err != nil {
+					failed++
+				}
+				own = append(own, time.Since(o.scheduled))
+			}
+
+			mu.Lock()
+			latencies = append(latencies, own...)
+			errors += failed
+			mu.Unlock()
+		}(routine)
+	}
+
+	start := time.Now()
+	for i := 0; i < total && ctx.Err() == nil; i++ {
+		scheduled := start.Add(time.Duration(i) * interval)
+		if wait := time.Until(scheduled); wait > 0 {
+			time.Sleep(wait)
+		}
+		ops <- op{iter: offset + i + 1, scheduled: scheduled}
+	}
+	close(ops)
+	wg.Wait()
+	took := time.Since(start)
+
+	return RateResult{
+		Rate:       rate,
+		Throughput: float64(len(latencies)-errors) / took.Seconds(),
+		P99:        percentile(latencies, 0.99),
+		Ops:        len(latencies),
+		Errors:     errors,
+	}, total
+}
+
+// percentile returns the latency below which the given fraction of the latencies fall.
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	return latencies[int(float64(len(latencies)-1)*p)]
+}
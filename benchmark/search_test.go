@@ -0,0 +1,94 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearch(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything).Run(func(mock.Arguments) { time.Sleep(2 * time.Millisecond) })
+	b := Benchmark{Name: "test", Type: TypeLoop, Stmt: "SELECT {{.Iter}};"}
+
+	// act
+	result := Search(context.Background(), bencher, b, SearchOptions{Threads: 1, MaxP99: 20 * time.Millisecond, Duration: 100 * time.Millisecond, Start: 50})
+
+	// assert
+	// a single thread executes at most 500 operations per second
+	require.True(t, result.Sustainable.Rate >= 50 && result.Sustainable.Rate <= 500, "rate %v", result.Sustainable.Rate)
+	require.True(t, result.Sustainable.P99 <= 20*time.Millisecond)
+	require.True(t, len(result.Steps) > 2)
+
+	// the iteration counter continues across the steps
+	calls := bencher.Calls
+	require.Equal(t, "SELECT 1;", calls[0].Arguments.String(0))
+	require.Equal(t, "SELECT 2;", calls[1].Arguments.String(0))
+	require.Equal(t, "SELECT 6;", calls[5].Arguments.String(0))
+}
+
+func TestSearchUnsustainable(t *testing.T) {
+	testCases := []struct {
+		description string
+		delay       time.Duration
+		err         error
+	}{
+		{description: "start rate too slow", delay: 20 * time.Millisecond},
+		{description: "failing statements", err: errors.New("rejected")},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			// arrange
+			bencher := &mockedBencher{}
+			bencher.On("Exec", mock.Anything).Run(func(mock.Arguments) { time.Sleep(tt.delay) }).Return(tt.err)
+			b := Benchmark{Name: "test", Type: TypeLoop, Stmt: "SELECT 1;"}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			// act
+			result := Search(ctx, bencher, b, SearchOptions{Threads: 1, MaxP99: time.Millisecond, Duration: 100 * time.Millisecond, Start: 100})
+
+			// assert
+			require.NoError(t, ctx.Err())
+			require.Len(t, result.Steps, 1)
+			require.Zero(t, result.Sustainable.Rate)
+		})
+	}
+}
+
+func TestRateResultSustainable(t *testing.T) {
+	testCases := []struct {
+		description string
+		result      RateResult
+		want        bool
+	}{
+		{description: "sustainable", result: RateResult{Rate: 100, Throughput: 99, P99: time.Millisecond, Ops: 100, Errors: 1}, want: true},
+		{description: "too slow", result: RateResult{Rate: 100, Throughput: 90, P99: time.Millisecond, Ops: 90}},
+		{description: "too high latency", result: RateResult{Rate: 100, Throughput: 100, P99: time.Second, Ops: 100}},
+		{description: "too many errors", result: RateResult{Rate: 100, Throughput: 100, P99: time.Millisecond, Ops: 100, Errors: 2}},
+		{description: "no operations", result: RateResult{}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.result.sustainable(5*time.Millisecond))
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 100; i >= 1; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	require.Equal(t, 99*time.Millisecond, percentile(latencies, 0.99))
+	require.Equal(t, 50*time.Millisecond, percentile(latencies, 0.5))
+	require.Equal(t, time.Duration(0), percentile(nil, 0.99))
+}
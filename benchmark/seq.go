@@ -0,0 +1,34 @@
+package benchmark
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// sequences contains the counters of the Seq template method, shared by all benchmarks.
+// The counters are looked up without a lock, as each worker calls Seq per statement.
+var sequences = struct {
+	counters sync.Map // *int64 by name
+	start    int64    // atomically
+	step     int64    // atomically
+}{start: 1, step: 1}
+
+// SetSeq resets all sequences of the Seq template method. Their values start at start
+// and increase by step, e.g. to interleave the sequences of several processes.
+func SetSeq(start, step int64) {
+	sequences.counters.Clear()
+	atomic.StoreInt64(&sequences.start, start)
+	atomic.StoreInt64(&sequences.step, step)
+}
+
+// Seq returns the next value of the named sequence. The values are unique across
+// all threads and benchmarks, e.g. for collision-free primary keys.
+func (d *tmplData) Seq(name string) int64 {
+	counter, ok := sequences.counters.Load(name)
+	if !ok {
+		counter, _ = sequences.counters.LoadOrStore(name, new(int64))
+	}
+
+	n := atomic.AddInt64(counter.(*int64), 1)
+	return atomic.LoadInt64(&sequences.start) + (n-1)*atomic.LoadInt64(&sequences.step)
+}
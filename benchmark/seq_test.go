@@ -0,0 +1,67 @@
+package benchmark
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSeq(t *testing.T) {
+	// arrange
+	SetSeq(10, 3)
+	defer SetSeq(1, 1)
+	d := &tmplData{}
+
+	// act
+	got := []int64{d.Seq("users"), d.Seq("users"), d.Seq("orders"), d.Seq("users")}
+
+	// assert
+	want := []int64{10, 13, 10, 16}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got values %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSeqConcurrent(t *testing.T) {
+	// arrange
+	SetSeq(1, 1)
+	seen := make(chan int64, 1000)
+	wg := &sync.WaitGroup{}
+
+	// act
+	for routine := 0; routine < 10; routine++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d := &tmplData{}
+			for i := 0; i < 100; i++ {
+				seen <- d.Seq("ids")
+			}
+		}()
+	}
+	wg.Wait()
+	close(seen)
+
+	// assert
+	unique := map[int64]bool{}
+	for v := range seen {
+		if unique[v] {
+			t.Fatalf("value %v was returned twice", v)
+		}
+		unique[v] = true
+	}
+	if len(unique) != 1000 {
+		t.Errorf("got %v unique values, want 1000", len(unique))
+	}
+}
+
+func BenchmarkSeq(b *testing.B) {
+	SetSeq(1, 1)
+	b.RunParallel(func(pb *testing.PB) {
+		d := &tmplData{}
+		for pb.Next() {
+			d.Seq("ids")
+		}
+	})
+}
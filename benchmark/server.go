@@ -0,0 +1,123 @@
+package benchmark
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServerSample is a sample of the disk and CPU usage of the database server, taken by vmstat.
+type ServerSample struct {
+	Time      time.Time
+	BlocksIn  int64 // blocks per second read from the disks
+	BlocksOut int64 // blocks per second written to the disks
+	CPU       int   // percentage of the CPU time in user and system mode
+	IOWait    int   // percentage of the CPU time waiting for I/O
+}
+
+// ServerStats collects the samples of vmstat executed by a command, e.g. via ssh on the database server.
+type ServerStats struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+
+	mu      sync.Mutex
+	samples []ServerSample
+}
+
+// StartServerStats executes the command, which has to print the output of 'vmstat -n <interval>',
+// and collects its samples until Stop is called.
+func StartServerStats(command []string) (*ServerStats, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("missing server stats command")
+	}
+
+	cmd := exec.Command(command[0], command[1:]...)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %v: %v", command[0], err)
+	}
+
+	s := &ServerStats{cmd: cmd, done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		parseVmstat(out, func(sample ServerSample) {
+			s.mu.Lock()
+			s.samples = append(s.samples, sample)
+			s.mu.Unlock()
+		})
+	}()
+	return s, nil
+}
+
+// parseVmstat parses the output of vmstat and calls add for each sample.
+func parseVmstat(r io.Reader, add func(ServerSample)) {
+	var (
+		scanner = bufio.NewScanner(r)
+		cols    map[string]int
+		first   bool
+	)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+
+		// The column header, the columns differ between the versions.
+		if len(fields) > 0 && fields[0] == "r" {
+			cols = map[string]int{}
+			for i, f := range fields {
+				cols[f] = i
+			}
+			first = true
+			continue
+		}
+		if cols == nil || len(fields) != len(cols) {
+			continue
+		}
+		// The first sample contains the averages since the boot.
+		if first {
+			first = false
+			continue
+		}
+
+		value := func(col string) int64 {
+			i, ok := cols[col]
+			if !ok {
+				return 0
+			}
+			v, _ := strconv.ParseInt(fields[i], 10, 64)
+			return v
+		}
+		add(ServerSample{
+			Time:      time.Now(),
+			BlocksIn:  value("bi"),
+			BlocksOut: value("bo"),
+			CPU:       int(value("us") + value("sy")),
+			IOWait:    int(value("wa")),
+		})
+	}
+}
+
+// Reset returns the samples since the last reset.
+func (s *ServerStats) Reset() []ServerSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	samples := s.samples
+	s.samples = nil
+	return samples
+}
+
+// Stop stops the command. Waiting for the command closes its output, even when it's
+// still kept open by a child process.
+func (s *ServerStats) Stop() {
+	s.cmd.Process.Kill()
+	s.cmd.Wait()
+	<-s.done
+}
@@ -0,0 +1,57 @@
+package benchmark
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVmstat(t *testing.T) {
+	// arrange
+	out := `procs -----------memory---------- ---swap-- -----io---- -system-- ------cpu-----
+ r  b   swpd   free   buff  cache   si   so    bi    bo   in   cs us sy id wa st
+ 2  0      0 4194492  71608 1550380    0    0   272  1258  455 1227 28  5 67  0  0
+ 1  3      0 4194492  71608 1550380    0    0    10  5000   62   90 20 10 40 30  0
+ 1  0      0 4194492  71608 1550380    0    0     0     0   62   90  0  1 99  0  0
+`
+
+	// act
+	var samples []ServerSample
+	parseVmstat(strings.NewReader(out), func(s ServerSample) {
+		samples = append(samples, s)
+	})
+
+	// assert
+	require.Len(t, samples, 2)
+	require.Equal(t, int64(10), samples[0].BlocksIn)
+	require.Equal(t, int64(5000), samples[0].BlocksOut)
+	require.Equal(t, 30, samples[0].CPU)
+	require.Equal(t, 30, samples[0].IOWait)
+	require.Equal(t, 1, samples[1].CPU)
+}
+
+func TestServerStats(t *testing.T) {
+	// arrange
+	s, err := StartServerStats([]string{"sh", "-c", "echo ' r  b bi bo us sy wa'; echo ' 0 0 1 1 1 1 1'; echo ' 0 0 2 3 4 5 6'; sleep 10"})
+	require.NoError(t, err)
+
+	// act
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		s.mu.Lock()
+		n := len(s.samples)
+		s.mu.Unlock()
+		if n == 1 {
+			break
+		}
+	}
+	s.Stop()
+	samples := s.Reset()
+
+	// assert
+	require.Len(t, samples, 1)
+	require.Equal(t, int64(3), samples[0].BlocksOut)
+	require.Equal(t, 9, samples[0].CPU)
+	require.Empty(t, s.Reset())
+}
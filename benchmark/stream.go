@@ -0,0 +1,163 @@
+package benchmark
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// statementLookahead is the input, which decides whether the statements are separated by
+	// semicolons or lines, see statementSplitter.
+	statementLookahead = 64 * 1024
+	// maxStatementSize is the max. size of a streamed statement, e.g. of a large multi-row insert.
+	maxStatementSize = 64 * 1024 * 1024
+)
+
+// statementSplitter splits the input of a bufio.Scanner into statements, see split.
+type statementSplitter struct {
+	decided bool // whether the input was looked at
+	lines   bool // the statements end with the line, no semicolon was found
+}
+
+// split is a split function for a bufio.Scanner. When the first statementLookahead bytes of the input
+// contain a semicolon, the statements end with a semicolon, which is kept, and may span several
+// lines. Otherwise, each line is a statement, e.g. of a query log. Semicolons and line breaks within
+// quoted literals and identifiers, dollar quotes and comments don't end a statement.
+func (s *statementSplitter) split(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if !s.decided {
+		switch {
+		case statementEnd(data, false) >= 0:
+			s.decided = true
+		case atEOF || len(data) >= statementLookahead:
+			s.decided, s.lines = true, true
+		default:
+			// request more data
+			return 0, nil, nil
+		}
+	}
+	if i := statementEnd(data, s.lines); i >= 0 {
+		// keep the semicolon as part of the statement
+		if data[i] == ';' {
+			return i + 1, data[:i+1], nil
+		}
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	// request more data
+	return 0, nil, nil
+}
+
+// dollarQuote starts a dollar-quoted string of postgres, e.g. $$ or $body$.
+var dollarQuote = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+
+// statementEnd returns the index of the semicolon, or with lines of the line break, which ends the
+// first statement of the data, or -1 when more data is needed. Quoted literals and identifiers,
+// dollar quotes and comments are skipped.
+func statementEnd(data []byte, lines bool) int {
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; {
+		case c == ';' || (c == '\n' && lines):
+			return i
+		case c == '\'' || c == '"' || c == '`':
+			// a doubled quote closes and reopens the quotes
+			end := bytes.IndexByte(data[i+1:], c)
+			if end < 0 {
+				return -1
+			}
+			i += end + 1
+		case c == '-' && bytes.HasPrefix(data[i:], []byte("--")):
+			end := bytes.IndexByte(data[i:], '\n')
+			if end < 0 {
+				return -1
+			}
+			// the line break ends the comment, not the statement, unless with lines
+			i += end - 1
+		case c == '/' && bytes.HasPrefix(data[i:], []byte("/*")):
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				return -1
+			}
+			i += end + 3
+		case c == '$':
+			if m := dollarQuote.Find(data[i:]); m != nil {
+				end := bytes.Index(data[i+len(m):], m)
+				if end < 0 {
+					return -1
+				}
+				i += len(m) + end + len(m) - 1
+			}
+		}
+	}
+	return -1
+}
+
+// Stream executes the statements read from r concurrently with the given number of threads.
+// The statements are executed as they are, without any template substitution.
+// It returns the duration, the number of executed statements and the number of failed ones, which
+// are included in the executed ones. When the context is cancelled, no more statements are read and
+// the cancelled statements aren't counted.
+func Stream(ctx context.Context, bencher Bencher, r io.Reader, threads int) (time.Duration, int64, int64) {
+	var (
+		stmts    = make(chan string, threads)
+		executed int64
+		failed   int64
+		wg       = &sync.WaitGroup{}
+	)
+
+	start := time.Now()
+
+	wg.Add(threads)
+	for routine := 0; routine < threads; routine++ {
+		go func() {
+			defer wg.Done()
+			for stmt := range stmts {
+				err := execContext(ctx, bencher, stmt)
+				if canceled(ctx, err) {
+					continue
+				}
+				if err != nil {
+					log.Printf("%v failed: %v", stmt, err)
+					atomic.AddInt64(&failed, 1)
+				}
+				atomic.AddInt64(&executed, 1)
+			}
+		}()
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStatementSize)
+	scanner.Split((&statementSplitter{}).split)
+
+scan:
+	for scanner.Scan() {
+		stmt := strings.TrimSpace(scanner.Text())
+		if stmt == "" || stmt == ";" {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			// interrupted, stop benchmarking
+			break scan
+		case stmts <- stmt:
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Printf("failed to read statements: %v", err)
+	}
+
+	close(stmts)
+	wg.Wait()
+
+	return time.Since(start), executed, failed
+}
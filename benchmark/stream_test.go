@@ -0,0 +1,102 @@
+package benchmark
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementSplitter(t *testing.T) {
+	testCases := []struct {
+		description string
+		in          string
+		want        []string
+	}{
+		{
+			description: "lines",
+			in:          "SELECT 1\nINSERT INTO t VALUES(1)\n\nDELETE FROM t",
+			want:        []string{"SELECT 1", "INSERT INTO t VALUES(1)", "DELETE FROM t"},
+		},
+		{
+			description: "semicolons",
+			in:          "INSERT INTO t VALUES(1); INSERT INTO t VALUES(2);\nSELECT *\nFROM t\nWHERE id = 1;\n",
+			want:        []string{"INSERT INTO t VALUES(1);", "INSERT INTO t VALUES(2);", "SELECT *\nFROM t\nWHERE id = 1;"},
+		},
+		{
+			description: "quoted",
+			in:          "INSERT INTO t VALUES('a;b', 'it''s\nmultiline');\nSELECT \"a;b\" FROM t;",
+			want:        []string{"INSERT INTO t VALUES('a;b', 'it''s\nmultiline');", "SELECT \"a;b\" FROM t;"},
+		},
+		{
+			description: "quoted lines",
+			in:          "SELECT 'a\nb'\nSELECT 2",
+			want:        []string{"SELECT 'a\nb'", "SELECT 2"},
+		},
+		{
+			description: "comments and dollar quotes",
+			in:          "-- a; comment\nSELECT 1 /* b; */;\nDO $$ BEGIN PERFORM 1; END $$;",
+			want:        []string{"-- a; comment\nSELECT 1 /* b; */;", "DO $$ BEGIN PERFORM 1; END $$;"},
+		},
+		{
+			description: "long statement",
+			in:          "INSERT INTO t VALUES " + strings.Repeat("(1),", 100000) + "(1);",
+			want:        []string{"INSERT INTO t VALUES " + strings.Repeat("(1),", 100000) + "(1);"},
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			// arrange
+			scanner := bufio.NewScanner(strings.NewReader(tt.in))
+			scanner.Buffer(nil, maxStatementSize)
+			scanner.Split((&statementSplitter{}).split)
+
+			// act
+			var got []string
+			for scanner.Scan() {
+				if stmt := strings.TrimSpace(scanner.Text()); stmt != "" {
+					got = append(got, stmt)
+				}
+			}
+
+			// assert
+			require.NoError(t, scanner.Err())
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStream(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything)
+
+	in := "SELECT 1;\nSELECT 2;\nSELECT 3; SELECT 4;\n"
+
+	// act
+	_, executed, failed := Stream(context.Background(), bencher, strings.NewReader(in), 3)
+
+	// assert
+	require.Equal(t, int64(4), executed)
+	require.Zero(t, failed)
+	bencher.AssertNumberOfCalls(t, "Exec", 4)
+	bencher.AssertCalled(t, "Exec", "SELECT 4;")
+}
+
+func TestStreamFailed(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", "SELECT 1;").Return(nil)
+	bencher.On("Exec", "SELECT x;").Return(errors.New("column x doesn't exist"))
+
+	// act
+	_, executed, failed := Stream(context.Background(), bencher, strings.NewReader("SELECT 1; SELECT x; SELECT 1;"), 2)
+
+	// assert
+	require.Equal(t, int64(3), executed)
+	require.Equal(t, int64(1), failed)
+}
@@ -0,0 +1,325 @@
+package benchmark
+
+import (
+	"bytes"
+	"log"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+
+	"github.com/brianvoe/gofakeit/v6"
+)
+
+// tmplData contains the variables and functions which are available in the statement templates.
+// Each field and method has to be documented in TemplateDocs.
+type tmplData struct {
+	Iter            int
+	Row             int
+	Thread          int
+	Threads         int
+	Op              int
+	Seed            func(int64) string
+	RandInt63       func() int64
+	RandInt63n      func(int64) int64
+	RandFloat32     func() float32
+	RandFloat64     func() float64
+	RandExpFloat64  func() float64
+	RandNormFloat64 func() float64
+
+	rand  *rand.Rand      // see random
+	faker *gofakeit.Faker // see fake
+}
+
+// TemplateDoc documents a variable or function of the statement templates.
+type TemplateDoc struct {
+	Name        string
+	Example     string
+	Description string
+}
+
+// TemplateDocs documents all variables and functions which can be used in the statement templates.
+var TemplateDocs = []TemplateDoc{
+	{Name: "Iter", Example: "{{.Iter}}", Description: "the iteration counter, 1 in mode once"},
+	{Name: "Thread", Example: "{{.Thread}}", Description: "the index of the worker executing the statement, starting at 0"},
+	{Name: "Threads", Example: "{{.Threads}}", Description: "the total number of workers, e.g. to partition key ranges per worker"},
+	{Name: "Op", Example: "{{.Op}}", Description: "unique counter of the executed operations in the order of execution, starting at 1"},
+	{Name: "Key", Example: "{{.Key}}", Description: "the iteration counter, negated for the lookups which should miss (--hit-ratio)"},
+	{Name: "Row", Example: "{{.Row}}", Description: "the index of the row within .Rows, starting at 0"},
+	{Name: "Seed", Example: "{{call .Seed 42}}", Description: "seeds the random number generator of the worker (math/rand.Rand.Seed), prints nothing"},
+	{Name: "RandInt63", Example: "{{call .RandInt63}}", Description: "random non-negative int64 (math/rand.Rand.Int63)"},
+	{Name: "RandInt63n", Example: "{{call .RandInt63n 9999}}", Description: "random int64 in [0, n) (math/rand.Rand.Int63n)"},
+	{Name: "RandFloat32", Example: "{{call .RandFloat32}}", Description: "random float32 in [0.0, 1.0) (math/rand.Rand.Float32)"},
+	{Name: "RandFloat64", Example: "{{call .RandFloat64}}", Description: "random float64 in [0.0, 1.0) (math/rand.Rand.Float64)"},
+	{Name: "RandExpFloat64", Example: "{{call .RandExpFloat64}}", Description: "exponentially distributed float64 (math/rand.Rand.ExpFloat64)"},
+	{Name: "RandNormFloat64", Example: "{{call .RandNormFloat64}}", Description: "normally distributed float64 (math/rand.Rand.NormFloat64)"},
+	{Name: "RandRange", Example: "{{.RandRange 18 99}}", Description: "random int64 in [min, max]"},
+	{Name: "RandString", Example: "'{{.RandString 16}}'", Description: "random string of n alphanumeric characters"},
+	{Name: "UUID", Example: "'{{.UUID}}'", Description: "random version 4 UUID"},
+	{Name: "RandDate", Example: `'{{.RandDate "2024-01-01" "2025-01-01"}}'`, Description: "random date in [from, to), formatted as 2006-01-02"},
+	{Name: "RandTimestamp", Example: `'{{.RandTimestamp "2024-01-01" "2024-01-02 12:00:00"}}'`, Description: "random timestamp in [from, to), formatted as 2006-01-02 15:04:05"},
+	{Name: "RandChoice", Example: `'{{.RandChoice "new" "paid" "shipped"}}'`, Description: "one of the values, chosen with equal probability"},
+	{Name: "RandWeighted", Example: `'{{.RandWeighted "gold" 10 "silver" 30 "bronze" 60}}'`, Description: "one of the values, given as pairs of value and weight, chosen by their weights"},
+	{Name: "ZipfInt", Example: "{{.ZipfInt 10000}}", Description: "Zipf distributed int64 in [0, n), 0 is the most frequent, e.g. for hot rows"},
+	{Name: "RandList", Example: "IN ({{.RandList 100 10000}})", Description: "n distinct random int64 in [0, max), separated by commas, e.g. for IN lists"},
+	{Name: "RandArray", Example: "'{{.RandArray 10 1000}}'", Description: "n distinct random int64 in [0, max) as array literal {1,2,3}"},
+	{Name: "PickList", Example: `IN ({{.PickList 100 "ids"}})`, Description: "n random values of the named pool, separated by commas, strings are quoted"},
+	{Name: "Seq", Example: `{{.Seq "users"}}`, Description: "next value of the named sequence, unique across threads and benchmarks (--seq-start)"},
+	{Name: "Pick", Example: `{{.Pick "ids"}}`, Description: "random value of the named pool, captured by a previous benchmark with \\capture"},
+	{Name: "QueryValue", Example: `{{.QueryValue "SELECT max(id) FROM t"}}`, Description: "first value returned by the query, executed once per benchmark, e.g. to adapt to the current data"},
+	{Name: "FakeName", Example: "'{{.FakeName}}'", Description: "random person name, quotes are escaped for string literals"},
+	{Name: "FakeEmail", Example: "'{{.FakeEmail}}'", Description: "random email address"},
+	{Name: "FakeAddress", Example: "'{{.FakeAddress}}'", Description: "random postal address"},
+	{Name: "FakeText", Example: "'{{.FakeText 20}}'", Description: "random sentence of n words"},
+	{Name: "HashInt", Example: `{{.HashInt "balance" .Iter 1000}}`, Description: "deterministic int64 in [0, n) of the name and key, the same in each run"},
+	{Name: "HashFloat", Example: `{{.HashFloat "score" .Iter}}`, Description: "deterministic float64 in [0.0, 1.0) of the name and key"},
+	{Name: "HashText", Example: `'{{.HashText "name" .Iter 20}}'`, Description: "deterministic text of n lowercase letters of the name and key"},
+	{Name: "Func", Example: `'{{.Func "order_id" .Iter}}'`, Description: "output of the function defined with \\func in the script, with the arguments as .Args"},
+	{Name: "Rows", Example: `{{.Rows 100 "({{.Iter}}, {{.Row}}, {{call .RandInt63}})"}}`, Description: "the tuple template n times, separated by commas (multi-row inserts)"},
+}
+
+// Rows renders the tuple template n times, separated by commas, e.g. for multi-row INSERT statements.
+// The tuple has access to the same variables and functions as the statement.
+func (d *tmplData) Rows(n int, tuple string) (string, error) {
+	s, err := parseStmt(tuple)
+	if err != nil {
+		return "", err
+	}
+
+	b := &builder{stmt: s, data: *d}
+	sb := &strings.Builder{}
+
+	for row := 0; row < n; row++ {
+		if row > 0 {
+			sb.WriteString(", ")
+		}
+		b.data.Row = row
+		sb.WriteString(b.build(d.Iter))
+	}
+	return sb.String(), nil
+}
+
+// placeholder is a variable of a fast path statement.
+type placeholder int
+
+const (
+	// literal is plain statement text, no placeholder.
+	literal placeholder = iota
+	// placeholderIter is replaced by the iteration counter.
+	placeholderIter
+	// placeholderRand is replaced by a random non-negative int64.
+	placeholderRand
+)
+
+// placeholders maps the fast path placeholders to their type.
+var placeholders = map[string]placeholder{
+	"{iter}": placeholderIter,
+	"{rand}": placeholderRand,
+}
+
+// fastPlaceholders is 1, when the placeholders are substituted, see SetFastPlaceholders.
+var fastPlaceholders int32
+
+// SetFastPlaceholders sets whether the placeholders, e.g. {iter}, are substituted in the statements
+// without template actions. It's disabled by default, as existing statements may contain them as data.
+// It has to be set before the benchmarks are run, the statements are parsed accordingly.
+func SetFastPlaceholders(enabled bool) {
+	v := int32(0)
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&fastPlaceholders, v)
+}
+
+func isFastPlaceholders() bool {
+	return atomic.LoadInt32(&fastPlaceholders) == 1
+}
+
+// segment is either literal statement text or a placeholder.
+type segment struct {
+	kind placeholder
+	text string
+}
+
+// statement is a parsed statement. Statements without template actions use the fast path,
+// which substitutes the placeholders without the overhead of text/template, see SetFastPlaceholders.
+type statement struct {
+	tmpl *template.Template
+	fast []segment
+	bind bool     // the placeholders of the fast path are marked to be bound as parameters
+	mix  *mixture // chooses one of several statements, see Benchmark.Mix
+}
+
+// parseFast splits the statement into literal text and placeholders. Unknown placeholders,
+// e.g. braces of JSON values, and the ones within single-quoted strings are kept as literal text.
+func parseFast(stmt string) []segment {
+	var (
+		segments []segment
+		start    int // start of the current literal text
+		quoted   bool
+	)
+
+	for i := 0; i < len(stmt); i++ {
+		if stmt[i] == '\'' {
+			// an escaped quote '' ends and starts the string again
+			quoted = !quoted
+		}
+		if stmt[i] != '{' || quoted {
+			continue
+		}
+		for name, kind := range placeholders {
+			if !strings.HasPrefix(stmt[i:], name) {
+				continue
+			}
+			if start < i {
+				segments = append(segments, segment{kind: literal, text: stmt[start:i]})
+			}
+			segments = append(segments, segment{kind: kind})
+			i += len(name) - 1
+			start = i + 1
+			break
+		}
+	}
+	if start < len(stmt) {
+		segments = append(segments, segment{kind: literal, text: stmt[start:]})
+	}
+	return segments
+}
+
+// statements caches the parsed statements, identical statements share the same parsed statement.
+// The cache is read without a lock, as Rows parses its tuple per statement.
+var statements = struct {
+	sync.Mutex          // serializes the parsing of new statements
+	m          sync.Map // *statement by key
+}{}
+
+// parseStmt returns the parsed statement, which is only parsed on the first call.
+// With prepared statements, the values of the actions are marked to be bound as parameters.
+func parseStmt(stmt string) (*statement, error) {
+	key := stmt
+	if isPrepared() {
+		key = string(argStart) + key
+	}
+	if isFastPlaceholders() {
+		key = "\x01" + key
+	}
+	if s, ok := statements.m.Load(key); ok {
+		return s.(*statement), nil
+	}
+
+	statements.Lock()
+	defer statements.Unlock()
+	if s, ok := statements.m.Load(key); ok {
+		return s.(*statement), nil
+	}
+
+	s := &statement{}
+	if strings.Contains(stmt, "{{") {
+		t, err := template.New("stmt").Funcs(registeredFuncs()).Parse(stmt)
+		if err != nil {
+			return nil, err
+		}
+		if isPrepared() {
+			markArgs(t.Tree.Root)
+		}
+		s.tmpl = t
+	} else if isFastPlaceholders() {
+		s.fast = parseFast(stmt)
+		s.bind = isPrepared()
+	} else {
+		s.fast = []segment{{kind: literal, text: stmt}}
+	}
+
+	statements.m.Store(key, s)
+	return s, nil
+}
+
+// builder builds the statements. The buffers and the template data are
+// reused for each statement, thus a builder must not be used concurrently.
+type builder struct {
+	stmt *statement
+	buf  bytes.Buffer // template output
+	fast []byte       // fast path output
+	data tmplData
+	seed int64 // base seed of the random values, see setThread
+
+	// builders of the statements of a mixture, the last chosen one and the recorded latencies
+	subs         []*builder
+	chosen       int
+	mixLatencies []*histogram
+	mixErrors    []int
+	mixKinds     []map[string]int
+}
+
+// newBuilder returns a builder of the first worker, see setThread for the others.
+func newBuilder(stmt *statement) *builder {
+	r := newRand(0)
+	return &builder{
+		stmt: stmt,
+		seed: Seed(),
+		data: tmplData{
+			Seed:            func(s int64) string { r.Seed(s); return "" },
+			RandInt63:       r.Int63,
+			RandInt63n:      r.Int63n,
+			RandFloat32:     r.Float32,
+			RandFloat64:     r.Float64,
+			RandExpFloat64:  r.ExpFloat64,
+			RandNormFloat64: r.NormFloat64,
+			rand:            r,
+		},
+	}
+}
+
+// build executes the statement with variables and functions to a pure DB statement.
+func (b *builder) build(i int) string {
+	if b.stmt.mix != nil {
+		return b.buildMix(i)
+	}
+	if b.stmt.tmpl == nil {
+		return b.buildFast(i)
+	}
+
+	b.buf.Reset()
+	b.data.Iter = i
+
+	if err := b.stmt.tmpl.Execute(&b.buf, &b.data); err != nil {
+		log.Fatalf("failed to execute template: %v", err)
+	}
+	return b.buf.String()
+}
+
+// buildFast substitutes the placeholders of a fast path statement.
+func (b *builder) buildFast(i int) string {
+	segments := b.stmt.fast
+
+	// constant statement, nothing to substitute
+	if len(segments) == 1 && segments[0].kind == literal {
+		return segments[0].text
+	}
+
+	b.fast = b.fast[:0]
+	for _, s := range segments {
+		if s.kind != literal && b.stmt.bind {
+			b.fast = append(b.fast, argStart)
+		}
+		switch s.kind {
+		case literal:
+			b.fast = append(b.fast, s.text...)
+		case placeholderIter:
+			b.fast = strconv.AppendInt(b.fast, int64(i), 10)
+		case placeholderRand:
+			b.fast = strconv.AppendInt(b.fast, b.data.random().Int63(), 10)
+		}
+		if s.kind != literal && b.stmt.bind {
+			b.fast = append(b.fast, argEnd)
+		}
+	}
+	return string(b.fast)
+}
+
+// buildStmt parses the given template with variables and functions to a pure DB statement.
+func buildStmt(t *template.Template, i int) string {
+	return newBuilder(&statement{tmpl: t}).build(i)
+}
@@ -0,0 +1,222 @@
+package benchmark
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"text/template"
+)
+
+func TestBuildStmt(t *testing.T) {
+	// arrange
+	tmpl := template.New("test")
+	tmpl.Parse("{{.Iter}} {{call .RandInt63}}")
+
+	// act
+	stmt := buildStmt(tmpl, 1337)
+
+	// assert
+	// the seed is random, only check the format
+	var iter, random int64
+	if _, err := fmt.Sscanf(stmt, "%d %d", &iter, &random); err != nil || iter != 1337 {
+		t.Errorf("got statement %v, want 1337 followed by a random number", stmt)
+	}
+}
+
+func TestTemplateDocs(t *testing.T) {
+	documented := map[string]bool{}
+	for _, doc := range TemplateDocs {
+		documented[doc.Name] = true
+	}
+
+	// unexported fields aren't accessible in the templates
+	exported := 0
+	typ := reflect.TypeOf(tmplData{})
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		exported++
+		if !documented[f.Name] {
+			t.Errorf("template field %v is not documented", f.Name)
+		}
+	}
+
+	ptr := reflect.TypeOf(&tmplData{})
+	for i := 0; i < ptr.NumMethod(); i++ {
+		if name := ptr.Method(i).Name; !documented[name] {
+			t.Errorf("template method %v is not documented", name)
+		}
+	}
+
+	if want := exported + ptr.NumMethod(); len(TemplateDocs) != want {
+		t.Errorf("got %v docs, want %v", len(TemplateDocs), want)
+	}
+}
+
+func TestParseStmt(t *testing.T) {
+	// arrange
+	SetFastPlaceholders(true)
+	defer SetFastPlaceholders(false)
+
+	// act
+	s1, err1 := parseStmt("SELECT {{.Iter}};")
+	s2, err2 := parseStmt("SELECT {{.Iter}};")
+	_, err3 := parseStmt("SELECT {{.Iter;")
+	s4, err4 := parseStmt("SELECT {iter};")
+
+	// assert
+	if err1 != nil || err2 != nil || err4 != nil {
+		t.Fatalf("failed to parse statement: %v %v %v", err1, err2, err4)
+	}
+	if s1 != s2 {
+		t.Errorf("identical statements were parsed twice")
+	}
+	if err3 == nil {
+		t.Errorf("expected error for invalid template")
+	}
+	if s1.tmpl == nil || s4.tmpl != nil {
+		t.Errorf("expected template for %q and fast path for %q", "SELECT {{.Iter}};", "SELECT {iter};")
+	}
+}
+
+func TestParseStmtConcurrent(t *testing.T) {
+	// arrange
+	const workers = 8
+	var (
+		wg     sync.WaitGroup
+		parsed [workers]*statement
+	)
+
+	// act
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func(i int) {
+			defer wg.Done()
+			parsed[i], _ = parseStmt(`INSERT INTO t VALUES {{.Rows 2 "({{.Iter}}, {{.Row}})"}};`)
+		}(i)
+	}
+	wg.Wait()
+
+	// assert
+	for _, s := range parsed {
+		if s == nil || s != parsed[0] {
+			t.Fatalf("got different parsed statements %v and %v", s, parsed[0])
+		}
+	}
+}
+
+func TestParseFast(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want []segment
+	}{
+		{in: "SELECT 1;", want: []segment{{text: "SELECT 1;"}}},
+		{in: "{iter}", want: []segment{{kind: placeholderIter}}},
+		{
+			in: "INSERT INTO t VALUES({iter}, {rand}, '{\"json\": {iter}}');",
+			want: []segment{
+				{text: "INSERT INTO t VALUES("},
+				{kind: placeholderIter},
+				{text: ", "},
+				{kind: placeholderRand},
+				{text: ", '{\"json\": {iter}}');"},
+			},
+		},
+		{
+			in: "SELECT 'it''s {iter}', {iter};",
+			want: []segment{
+				{text: "SELECT 'it''s {iter}', "},
+				{kind: placeholderIter},
+				{text: ";"},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := parseFast(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	testCases := []struct {
+		stmt string
+		want []string
+	}{
+		{stmt: "SELECT {{.Iter}};", want: []string{"SELECT 1;", "SELECT 2;"}},
+		{stmt: "SELECT {iter};", want: []string{"SELECT 1;", "SELECT 2;"}},
+		{stmt: "SELECT {iter}, '{iter}';", want: []string{"SELECT 1, '{iter}';", "SELECT 2, '{iter}';"}},
+		{stmt: "SELECT 1;", want: []string{"SELECT 1;", "SELECT 1;"}},
+		{
+			stmt: `INSERT INTO t VALUES {{.Rows 3 "({{.Iter}}, {{.Row}})"}};`,
+			want: []string{"INSERT INTO t VALUES (1, 0), (1, 1), (1, 2);", "INSERT INTO t VALUES (2, 0), (2, 1), (2, 2);"},
+		},
+	}
+
+	SetFastPlaceholders(true)
+	defer SetFastPlaceholders(false)
+
+	for _, tt := range testCases {
+		t.Run(tt.stmt, func(t *testing.T) {
+			// arrange
+			s, err := parseStmt(tt.stmt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b := newBuilder(s)
+
+			// act
+			got := []string{b.build(1), b.build(2)}
+
+			// assert
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got statements %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilderFastPlaceholdersDisabled(t *testing.T) {
+	// arrange
+	s, err := parseStmt("SELECT {iter}, '{rand}';")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	got := newBuilder(s).build(1)
+
+	// assert
+	if want := "SELECT {iter}, '{rand}';"; got != want {
+		t.Errorf("got statement %q, want %q", got, want)
+	}
+}
+
+func BenchmarkBuilder(b *testing.B) {
+	SetFastPlaceholders(true)
+	defer SetFastPlaceholders(false)
+
+	for _, stmt := range []string{
+		"INSERT INTO t (id, balance) VALUES({{.Iter}}, {{call .RandInt63}});",
+		"INSERT INTO t (id, balance) VALUES({iter}, {rand});",
+	} {
+		b.Run(stmt, func(b *testing.B) {
+			s, err := parseStmt(stmt)
+			if err != nil {
+				b.Fatal(err)
+			}
+			builder := newBuilder(s)
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				builder.build(i)
+			}
+		})
+	}
+}
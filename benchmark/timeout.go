@@ -0,0 +1,61 @@
+package benchmark
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// queryTimeout is the max. duration of each statement in nanoseconds, see SetQueryTimeout,
+// atomically, as it's read by all threads.
+var queryTimeout int64
+
+// SetQueryTimeout bounds the execution of each statement by a context deadline, a statement
+// exceeding it fails with ErrKindTimeout. The drivers of benchers without contexts can't cancel
+// the statement, it's abandoned instead, so the worker continues. The zero value disables it.
+func SetQueryTimeout(d time.Duration) {
+	atomic.StoreInt64(&queryTimeout, int64(d))
+}
+
+// execTimeout executes the statement like execArgs, bounded by the query timeout.
+func execTimeout(ctx context.Context, bencher Bencher, stmt string) error {
+	timeout := time.Duration(atomic.LoadInt64(&queryTimeout))
+	if timeout <= 0 {
+		return execArgs(ctx, bencher, stmt)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if cancellable(bencher, stmt) {
+		return execArgs(ctx, bencher, stmt)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- execArgs(ctx, bencher, stmt)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// wrappedContextExecer is implemented by benchers wrapping the bencher of a benchmark,
+// which only cancel the statements, when the wrapped bencher does, e.g. capturer.
+type wrappedContextExecer interface {
+	ContextExecer
+	cancellable() bool
+}
+
+// cancellable returns whether the bencher executes the statement with a context, see execArgs.
+func cancellable(bencher Bencher, stmt string) bool {
+	if _, ok := bencher.(ArgsExecer); ok && hasArgs(stmt) {
+		return true
+	}
+	if w, ok := bencher.(wrappedContextExecer); ok {
+		return w.cancellable()
+	}
+	_, ok := bencher.(ContextExecer)
+	return ok
+}
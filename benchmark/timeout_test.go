@@ -0,0 +1,128 @@
+package benchmark
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockedContextExecer struct {
+	mockedBencher
+}
+
+func (m *mockedContextExecer) ExecContext(ctx context.Context, stmt string) error {
+	m.Called(stmt)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Second):
+		return nil
+	}
+}
+
+func TestQueryTimeout(t *testing.T) {
+	SetQueryTimeout(10 * time.Millisecond)
+	defer SetQueryTimeout(0)
+
+	hung := &mockedBencher{}
+	hung.On("Exec", "SELECT 1").After(time.Second).Return(nil)
+	hung.On("Exec", mock.Anything).Return(nil)
+	cancelled := &mockedContextExecer{}
+	cancelled.On("ExecContext", mock.Anything).Return()
+
+	testCases := []struct {
+		description string
+		bencher     Bencher
+		want        map[string]int
+	}{
+		{description: "abandoned without context", bencher: hung, want: map[string]int{ErrKindTimeout: 1}},
+		{description: "cancelled by the context", bencher: cancelled, want: map[string]int{ErrKindTimeout: 2}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			// arrange
+			b := Benchmark{Name: "selects", Type: TypeLoop, Stmt: "SELECT {{.Iter}}"}
+			start := time.Now()
+
+			// act
+			result := Run(context.Background(), tt.bencher, b, Options{Iter: 2, Threads: 1})
+
+			// assert
+			require.True(t, time.Since(start) < time.Second)
+			require.Equal(t, 2, result.Ops)
+			require.Equal(t, tt.want, result.ErrorKinds)
+		})
+	}
+}
+
+type mockedContextQuerier struct {
+	mockedBencher
+}
+
+func (m *mockedContextQuerier) Query(stmt string) ([]string, error) {
+	return m.QueryContext(context.Background(), stmt)
+}
+
+func (m *mockedContextQuerier) QueryContext(ctx context.Context, stmt string) ([]string, error) {
+	m.Called(stmt)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(time.Second):
+		return []string{"1"}, nil
+	}
+}
+
+type mockedContextFreshConnExecer struct {
+	mockedBencher
+}
+
+func (m *mockedContextFreshConnExecer) ExecFreshConn(ctx context.Context, stmt string) error {
+	m.Called(stmt)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Second):
+		return nil
+	}
+}
+
+func TestQueryTimeoutWrapped(t *testing.T) {
+	SetQueryTimeout(10 * time.Millisecond)
+	defer SetQueryTimeout(0)
+
+	querier := &mockedContextQuerier{}
+	querier.On("QueryContext", mock.Anything).Return()
+	fresh := &mockedContextFreshConnExecer{}
+	fresh.On("ExecFreshConn", mock.Anything).Return()
+
+	testCases := []struct {
+		description string
+		bencher     Bencher
+		benchmark   Benchmark
+	}{
+		{description: "capture", bencher: querier, benchmark: Benchmark{Name: "inserts", Type: TypeLoop, Stmt: "INSERT {{.Iter}}", Capture: "test_timeout"}},
+		{description: "expect", bencher: querier, benchmark: Benchmark{Name: "selects", Type: TypeLoop, Stmt: "SELECT {{.Iter}}", Expect: "rows=1"}},
+		{description: "fresh connection", bencher: fresh, benchmark: Benchmark{Name: "connects", Type: TypeLoop, Stmt: "SELECT {{.Iter}}", Fresh: true}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			// act
+			result := Run(context.Background(), tt.bencher, tt.benchmark, Options{Iter: 2, Threads: 1})
+
+			// assert
+			require.Equal(t, 2, result.Ops)
+			require.Equal(t, map[string]int{ErrKindTimeout: 2}, result.ErrorKinds)
+		})
+	}
+
+	// the statements were cancelled, not abandoned
+	require.True(t, cancellable(&capturer{querier: querier}, "SELECT 1"))
+	require.True(t, cancellable(&churner{fresh: fresh}, "SELECT 1"))
+	require.False(t, cancellable(&capturer{querier: &mockedQuerier{}}, "SELECT 1"))
+}
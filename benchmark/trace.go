@@ -0,0 +1,150 @@
+package benchmark
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// traceBatch is the number of records buffered by a worker before they are written.
+const traceBatch = 1024
+
+// trace sends the records of the executed statements to the running Trace.
+var trace = struct {
+	sync.RWMutex
+	active  int32 // 1 while running (atomic)
+	batches chan []traceRecord
+}{}
+
+// traceRecord is an executed statement.
+type traceRecord struct {
+	start     time.Time
+	benchmark string
+	worker    int
+	latency   time.Duration
+	err       error
+}
+
+// traceKey is the context key of the traceBuffer of a worker.
+type traceKey struct{}
+
+// warmupKey is the context key marking the statements of the warmup, which aren't traced.
+type warmupKey struct{}
+
+// traceBuffer buffers the records of a worker, so they are written in batches.
+type traceBuffer struct {
+	worker  int
+	records []traceRecord
+}
+
+// traceWorker returns the context of a worker, which buffers its trace records
+// until the returned func is called.
+func traceWorker(ctx context.Context, worker int) (context.Context, func()) {
+	if atomic.LoadInt32(&trace.active) == 0 {
+		return ctx, func() {}
+	}
+	buf := &traceBuffer{worker: worker}
+	return context.WithValue(ctx, traceKey{}, buf), func() {
+		sendTrace(buf.records)
+		buf.records = nil
+	}
+}
+
+// recordTrace records an executed statement for the running Trace.
+func recordTrace(ctx context.Context, start time.Time, took time.Duration, err error) {
+	if atomic.LoadInt32(&trace.active) == 0 || ctx.Value(warmupKey{}) != nil {
+		return
+	}
+	name, _ := ctx.Value(benchmarkKey{}).(string)
+	buf, ok := ctx.Value(traceKey{}).(*traceBuffer)
+	if !ok {
+		// e.g. the statements of once benchmarks
+		sendTrace([]traceRecord{{start: start, benchmark: name, latency: took, err: err}})
+		return
+	}
+	buf.records = append(buf.records, traceRecord{start: start, benchmark: name, worker: buf.worker, latency: took, err: err})
+	if len(buf.records) >= traceBatch {
+		sendTrace(buf.records)
+		buf.records = make([]traceRecord, 0, traceBatch)
+	}
+}
+
+// sendTrace sends the records to the running Trace, they are dropped when it was stopped.
+func sendTrace(records []traceRecord) {
+	if len(records) == 0 {
+		return
+	}
+	trace.RLock()
+	defer trace.RUnlock()
+	if atomic.LoadInt32(&trace.active) == 1 {
+		trace.batches <- records
+	}
+}
+
+// Trace writes every executed statement of the benchmarks as CSV row with its start,
+// the benchmark name, the worker, the latency and the error. The rows are buffered
+// by the workers and written in the background, so they are roughly ordered by time.
+type Trace struct {
+	w    *bufio.Writer
+	done chan struct{}
+	err  error
+}
+
+// StartTrace writes the executed statements to w, until Stop is called.
+// Only a single trace can run at a time.
+func StartTrace(w io.Writer) *Trace {
+	t := &Trace{w: bufio.NewWriterSize(w, 64*1024), done: make(chan struct{})}
+
+	trace.Lock()
+	trace.batches = make(chan []traceRecord, 64)
+	atomic.StoreInt32(&trace.active, 1)
+	trace.Unlock()
+
+	go t.run(trace.batches)
+	return t
+}
+
+// Stop writes the remaining records and returns the first write error.
+// The records of the workers still running are dropped.
+func (t *Trace) Stop() error {
+	trace.Lock()
+	atomic.StoreInt32(&trace.active, 0)
+	close(trace.batches)
+	trace.Unlock()
+
+	<-t.done
+	return t.err
+}
+
+func (t *Trace) run(batches <-chan []traceRecord) {
+	defer close(t.done)
+
+	cw := csv.NewWriter(t.w)
+	write := func(row []string) {
+		if t.err == nil {
+			t.err = cw.Write(row)
+		}
+	}
+	write([]string{"start", "benchmark", "worker", "latency_ns", "error"})
+	for batch := range batches {
+		for _, r := range batch {
+			var msg string
+			if r.err != nil {
+				msg = r.err.Error()
+			}
+			write([]string{r.start.Format(time.RFC3339Nano), r.benchmark, strconv.Itoa(r.worker), strconv.FormatInt(int64(r.latency), 10), msg})
+		}
+	}
+	cw.Flush()
+	if t.err == nil {
+		t.err = cw.Error()
+	}
+	if err := t.w.Flush(); t.err == nil {
+		t.err = err
+	}
+}
@@ -0,0 +1,71 @@
+package benchmark
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrace(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", "INSERT 25").Return(errors.New("failed"))
+	bencher.On("Exec", mock.Anything)
+	inserts := Benchmark{Name: "inserts", Type: TypeLoop, Stmt: "INSERT {{.Iter}}"}
+	create := Benchmark{Name: "create", Type: TypeOnce, Stmt: "CREATE TABLE t"}
+	buf := &bytes.Buffer{}
+
+	// act
+	trace := StartTrace(buf)
+	Run(context.Background(), bencher, create, Options{})
+	Run(context.Background(), bencher, inserts, Options{Iter: 2000, Threads: 2, Warmup: 10})
+	err := trace.Stop()
+
+	// assert, a row per statement without the warmup
+	require.NoError(t, err)
+	rows, err := csv.NewReader(buf).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, []string{"start", "benchmark", "worker", "latency_ns", "error"}, rows[0])
+	require.Len(t, rows, 1+1+2000)
+	require.Equal(t, "create", rows[1][1])
+
+	workers, errs := map[string]int{}, 0
+	for _, row := range rows[2:] {
+		require.Equal(t, "inserts", row[1])
+		_, err := time.Parse(time.RFC3339Nano, row[0])
+		require.NoError(t, err)
+		latency, err := strconv.ParseInt(row[3], 10, 64)
+		require.NoError(t, err)
+		require.True(t, latency >= 0)
+		workers[row[2]]++
+		if row[4] != "" {
+			require.Equal(t, "failed", row[4])
+			errs++
+		}
+	}
+	require.Equal(t, map[string]int{"0": 1000, "1": 1000}, workers)
+	require.Equal(t, 1, errs)
+}
+
+func TestTraceStopped(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything)
+	b := Benchmark{Name: "inserts", Type: TypeLoop, Stmt: "INSERT {{.Iter}}"}
+	buf := &bytes.Buffer{}
+	require.NoError(t, StartTrace(buf).Stop())
+	buf.Reset()
+
+	// act
+	Run(context.Background(), bencher, b, Options{Iter: 10, Threads: 2})
+
+	// assert
+	require.Empty(t, buf.String())
+}
@@ -0,0 +1,203 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Tuning contains the rate and the threads of a running live loop benchmark, see Options.Live.
+type Tuning struct {
+	Rate    float64 `json:"rate"`    // operations per second, 0 -> unlimited
+	Threads int     `json:"threads"` // concurrent routines
+}
+
+// ErrNotLive is returned when tuning while no live loop benchmark is running.
+var ErrNotLive = errors.New("no live loop benchmark is running")
+
+// tuning is the state of the running live loop benchmark.
+var tuning = struct {
+	sync.Mutex
+	cur     Tuning
+	active  bool
+	changed chan struct{} // notifies the running benchmark
+	version int64         // incremented with each change, sampled by the monitor (atomic)
+}{}
+
+// SetTuning changes the rate and the threads of the running live loop benchmark.
+func SetTuning(t Tuning) error {
+	if t.Threads < 1 {
+		return fmt.Errorf("invalid threads %v, must be at least 1", t.Threads)
+	}
+	if t.Rate < 0 {
+		return fmt.Errorf("invalid rate %v, must not be negative", t.Rate)
+	}
+
+	tuning.Lock()
+	defer tuning.Unlock()
+	if !tuning.active {
+		return ErrNotLive
+	}
+	tuning.cur = t
+	atomic.AddInt64(&tuning.version, 1)
+	select {
+	case tuning.changed <- struct{}{}:
+	default:
+		// a change is pending already, the benchmark reads the latest
+	}
+	return nil
+}
+
+// CurrentTuning returns the tuning of the running live loop benchmark and whether there is one.
+func CurrentTuning() (Tuning, bool) {
+	tuning.Lock()
+	defer tuning.Unlock()
+	return tuning.cur, tuning.active
+}
+
+// startTuning publishes the initial tuning of a live loop benchmark and returns the channel
+// notifying about changes.
+func startTuning(t Tuning) <-chan struct{} {
+	tuning.Lock()
+	defer tuning.Unlock()
+	tuning.cur, tuning.active = t, true
+	tuning.changed = make(chan struct{}, 1)
+	return tuning.changed
+}
+
+// stopTuning ends the live loop benchmark.
+func stopTuning() {
+	tuning.Lock()
+	defer tuning.Unlock()
+	tuning.active = false
+}
+
+// liveLoop runs the benchmark like loop, but the rate and the number of routines can be
+// changed while it's running with SetTuning. The routines take the iterations from a shared
+// counter, so it isn't known in advance which routine executes an iteration.
+func liveLoop(ctx context.Context, bencher Bencher, t *statement, opts Options) (*histogram, int) {
+	var (
+		next      int64 // last taken iteration, shared by all routines
+		errors    int64 // counter of the failed operations, shared by all routines
+		mu        sync.Mutex
+		latencies = &histogram{}
+		wg        = &sync.WaitGroup{}
+		limit     = newArrivals(opts.Rate, opts.Arrival, opts.baseSeed())
+		maxErr    = int64(opts.MaxErrorRate * float64(opts.Iter))
+
+		// closed when all iterations are taken, the duration is over or the errors exceed the max.
+		done     = make(chan struct{})
+		doneOnce sync.Once
+		finish   = func() { doneOnce.Do(func() { close(done) }) }
+
+		// the duration excludes the pauses
+		start  = time.Now()
+		paused = pausedTotal()
+	)
+
+	worker := func(index, threads int, quit <-chan struct{}) {
+		defer wg.Done()
+		defer startWorker(ctx)()
+		ctx, flushTrace := traceWorker(ctx, opts.ThreadOffset+index)
+		defer flushTrace()
+
+		builder := newBuilder(t)
+		builder.seed = opts.baseSeed()
+		builder.setThread(opts.ThreadOffset + index)
+		builder.data.Threads = threads
+		thinking := thinkRand(builder.seed, opts.ThreadOffset+index)
+
+		own := &histogram{}
+		defer func() {
+			mu.Lock()
+			latencies.merge(own)
+			mu.Unlock()
+			builder.flush()
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				// interrupted, stop benchmarking
+				finish()
+				return
+			case <-quit:
+				// fewer threads
+				return
+			case <-done:
+				return
+			default:
+			}
+			if !waitResumed(ctx) {
+				finish()
+				return
+			}
+			if opts.Duration > 0 && time.Since(start)-(pausedTotal()-paused) >= opts.Duration {
+				finish()
+				return
+			}
+			arrival := limit.wait()
+
+			i := int(atomic.AddInt64(&next, 1))
+			if opts.Duration == 0 && i > opts.Iter {
+				finish()
+				return
+			}
+			builder.data.Op = opts.Offset + i
+			took, err := execute(ctx, bencher, builder.build(opts.Offset+i))
+			if canceled(ctx, err) {
+				finish()
+				return
+			}
+			if limit.open() && !arrival.IsZero() {
+				// the request waited for a free routine
+				took = time.Since(arrival)
+			}
+			own.record(took)
+			builder.record(took, err)
+			if err != nil && atomic.AddInt64(&errors, 1) > maxErr && opts.MaxErrorRate > 0 && opts.Duration == 0 {
+				finish()
+			}
+			if !think(ctx, opts.ThinkTime, thinking) {
+				finish()
+				return
+			}
+		}
+	}
+
+	// quit channels of the running routines
+	var quits []chan struct{}
+	resize := func(threads int) {
+		for len(quits) < threads {
+			quit := make(chan struct{})
+			quits = append(quits, quit)
+			wg.Add(1)
+			go worker(len(quits)-1, threads, quit)
+		}
+		for len(quits) > threads {
+			close(quits[len(quits)-1])
+			quits = quits[:len(quits)-1]
+		}
+	}
+
+	changed := startTuning(Tuning{Rate: opts.Rate, Threads: opts.Threads})
+	defer stopTuning()
+	resize(opts.Threads)
+
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		case <-changed:
+			cur, _ := CurrentTuning()
+			limit.setRate(cur.Rate)
+			resize(cur.Threads)
+		}
+	}
+
+	wg.Wait()
+	return latencies, int(errors)
+}
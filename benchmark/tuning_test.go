@@ -0,0 +1,64 @@
+package benchmark
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiveLoop(t *testing.T) {
+	// arrange
+	var (
+		mu      sync.Mutex
+		threads = map[string]bool{}
+	)
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		threads[args.String(0)] = true
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+	})
+	b := Benchmark{Name: "inserts", Type: TypeLoop, Stmt: "INSERT {{.Thread}}"}
+	require.Equal(t, ErrNotLive, SetTuning(Tuning{Threads: 1}))
+
+	// act
+	done := make(chan Result)
+	go func() {
+		done <- Run(context.Background(), bencher, b, Options{Duration: 100 * time.Millisecond, Threads: 1, Live: true})
+	}()
+	time.Sleep(20 * time.Millisecond)
+	require.Error(t, SetTuning(Tuning{Threads: 0}))
+	require.NoError(t, SetTuning(Tuning{Threads: 3, Rate: 0}))
+	cur, ok := CurrentTuning()
+	result := <-done
+
+	// assert
+	require.True(t, ok)
+	require.Equal(t, Tuning{Threads: 3}, cur)
+	require.Equal(t, map[string]bool{"INSERT 0": true, "INSERT 1": true, "INSERT 2": true}, threads)
+	require.True(t, result.Ops > 0)
+	_, ok = CurrentTuning()
+	require.False(t, ok)
+}
+
+func TestLiveLoopIterations(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything)
+	b := Benchmark{Name: "inserts", Type: TypeLoop, Stmt: "INSERT {{.Iter}}"}
+
+	// act
+	result := Run(context.Background(), bencher, b, Options{Iter: 100, Threads: 4, Live: true})
+
+	// assert
+	require.Equal(t, 100, result.Ops)
+	for i := 1; i <= 100; i++ {
+		bencher.AssertCalled(t, "Exec", "INSERT "+strconv.Itoa(i))
+	}
+}
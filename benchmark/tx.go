@@ -0,0 +1,59 @@
+package benchmark
+
+import (
+	"context"
+	"strings"
+)
+
+// TxExecer is implemented by benchers, which are able to execute several statements in a
+// transaction. It's required for the transaction benchmarks, see Benchmark.Tx.
+type TxExecer interface {
+	// ExecTx begins a transaction, executes the statements in order and commits it.
+	// The transaction is rolled back, when a statement fails.
+	ExecTx(ctx context.Context, stmts []string) error
+}
+
+// transactor executes the statements of each iteration in a transaction.
+type transactor struct {
+	Bencher
+	tx TxExecer
+}
+
+// Exec executes the statements in a transaction.
+func (t *transactor) Exec(stmt string) error {
+	return t.ExecContext(context.Background(), stmt)
+}
+
+// ExecContext executes the statements in a transaction, it's rolled back when the context is cancelled.
+func (t *transactor) ExecContext(ctx context.Context, stmt string) error {
+	return t.tx.ExecTx(ctx, splitStatements(stmt))
+}
+
+// splitStatements splits the statements at the semicolons outside of quotes.
+func splitStatements(s string) []string {
+	var (
+		stmts []string
+		start int
+		quote byte
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == ';':
+			if stmt := strings.TrimSpace(s[start:i]); stmt != "" {
+				stmts = append(stmts, stmt)
+			}
+			start = i + 1
+		}
+	}
+	if stmt := strings.TrimSpace(s[start:]); stmt != "" {
+		stmts = append(stmts, stmt)
+	}
+	return stmts
+}
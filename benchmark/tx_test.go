@@ -0,0 +1,55 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockedTxExecer struct {
+	mockedBencher
+}
+
+func (m *mockedTxExecer) ExecTx(ctx context.Context, stmts []string) error {
+	args := m.Called(stmts)
+	return args.Error(0)
+}
+
+func TestTx(t *testing.T) {
+	// arrange
+	bencher := &mockedTxExecer{}
+	bencher.On("ExecTx", []string{"UPDATE a SET n = n - 1 WHERE id = 1", "UPDATE b SET n = n + 1 WHERE id = 1"}).Return(nil)
+	bencher.On("ExecTx", mock.Anything).Return(errors.New("deadlock detected"))
+	b := Benchmark{Name: "transfer", Type: TypeLoop, Tx: true, Stmt: "UPDATE a SET n = n - 1 WHERE id = {{.Iter}};\nUPDATE b SET n = n + 1 WHERE id = {{.Iter}};"}
+
+	// act
+	result := Run(context.Background(), bencher, b, Options{Iter: 2, Threads: 1})
+
+	// assert, a transaction is a single operation
+	bencher.AssertNumberOfCalls(t, "ExecTx", 2)
+	bencher.AssertNotCalled(t, "Exec", mock.Anything)
+	require.Equal(t, 2, result.Ops)
+	require.Equal(t, 1, result.Errors)
+}
+
+func TestSplitStatements(t *testing.T) {
+	testCases := []struct {
+		description string
+		in          string
+		want        []string
+	}{
+		{description: "single", in: "SELECT 1", want: []string{"SELECT 1"}},
+		{description: "several", in: "SELECT 1;\nSELECT 2;", want: []string{"SELECT 1", "SELECT 2"}},
+		{description: "quoted semicolons", in: "INSERT INTO t VALUES ('a;b'); SELECT \"c;\"", want: []string{"INSERT INTO t VALUES ('a;b')", "SELECT \"c;\""}},
+		{description: "empty statements", in: ";SELECT 1;;", want: []string{"SELECT 1"}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			require.Equal(t, tt.want, splitStatements(tt.in))
+		})
+	}
+}
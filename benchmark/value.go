@@ -0,0 +1,54 @@
+package benchmark
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// values caches the results of QueryValue, each query is executed once per benchmark.
+var values = struct {
+	sync.RWMutex
+	querier Querier // of the running benchmark, nil when the database doesn't support it
+	m       map[string]string
+}{m: map[string]string{}}
+
+// resetValues discards the values of the previous benchmark, the queries of the next one
+// are executed with the bencher.
+func resetValues(bencher Bencher) {
+	values.Lock()
+	defer values.Unlock()
+	values.querier, _ = bencher.(Querier)
+	values.m = map[string]string{}
+}
+
+// QueryValue returns the first value returned by the query, e.g. "SELECT max(id) FROM t".
+// The query is executed at its first use in a benchmark, all threads get the same value
+// until the next benchmark, e.g. to adapt the statements to the current data.
+func (d *tmplData) QueryValue(query string) (string, error) {
+	values.RLock()
+	v, ok := values.m[query]
+	values.RUnlock()
+	if ok {
+		return v, nil
+	}
+
+	values.Lock()
+	defer values.Unlock()
+	if v, ok := values.m[query]; ok {
+		// queried by another thread meanwhile
+		return v, nil
+	}
+	if values.querier == nil {
+		return "", errors.New("failed to query value: database doesn't support it")
+	}
+	result, err := values.querier.Query(query)
+	if err != nil {
+		return "", fmt.Errorf("query %q failed: %w", query, err)
+	}
+	if len(result) == 0 {
+		return "", fmt.Errorf("query %q returned no value", query)
+	}
+	values.m[query] = result[0]
+	return result[0], nil
+}
@@ -0,0 +1,44 @@
+package benchmark
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryValue(t *testing.T) {
+	// arrange
+	bencher := &mockedQuerier{}
+	bencher.On("Query", "SELECT max(id) FROM t").Return([]string{"42", "41"}, nil).Once()
+	bencher.On("Exec", "DELETE FROM t WHERE id = 42").Return(nil).Times(3)
+	b := Benchmark{Name: "deletes", Type: TypeLoop, Stmt: `DELETE FROM t WHERE id = {{.QueryValue "SELECT max(id) FROM t"}}`}
+
+	// act
+	Run(context.Background(), bencher, b, Options{Iter: 3, Threads: 2})
+
+	// assert
+	bencher.AssertExpectations(t)
+}
+
+func TestQueryValueReset(t *testing.T) {
+	// arrange
+	first, second := &mockedQuerier{}, &mockedQuerier{}
+	first.On("Query", "SELECT 1").Return([]string{"1"}, nil)
+	second.On("Query", "SELECT 1").Return([]string{}, nil)
+	d := &tmplData{}
+
+	// act
+	resetValues(first)
+	got, err := d.QueryValue("SELECT 1")
+	resetValues(second)
+	_, errEmpty := d.QueryValue("SELECT 1")
+	resetValues(&mockedBencher{})
+	_, errUnsupported := d.QueryValue("SELECT 1")
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, "1", got)
+	require.EqualError(t, errEmpty, `query "SELECT 1" returned no value`)
+	require.EqualError(t, errUnsupported, "failed to query value: database doesn't support it")
+}
@@ -0,0 +1,41 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ParseWarmup returns the iterations or the duration of a warmup, e.g. "1000" or "10s".
+// An empty string or "0" disables the warmup.
+func ParseWarmup(s string) (int, time.Duration, error) {
+	if s == "" {
+		return 0, 0, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+		return n, 0, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return 0, d, nil
+	}
+	return 0, 0, fmt.Errorf("invalid warmup %q, use a number of iterations or a duration, e.g. 1000 or 10s", s)
+}
+
+// warmup executes the loop benchmark before it's measured, e.g. to open the connections of the pool
+// and to fill the caches, and returns the number of executed iterations. The statements have their
+// own mixture, so the warmup isn't part of any result.
+func warmup(ctx context.Context, bencher Bencher, b Benchmark, opts Options) int {
+	t, err := parseBenchmark(b)
+	if err != nil {
+		return 0
+	}
+	opts.Iter, opts.Duration, opts.MaxErrorRate = opts.Warmup, opts.WarmupDuration, 0
+	if opts.Threads > opts.Iter && opts.Duration == 0 {
+		opts.Threads = opts.Iter
+	}
+	latencies, _ := loop(context.WithValue(ctx, warmupKey{}, true), bencher, t, opts)
+	TakeLoad()
+	TakeWire()
+	return latencies.n
+}
@@ -0,0 +1,62 @@
+package benchmark
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWarmup(t *testing.T) {
+	testCases := []struct {
+		description  string
+		s            string
+		wantIter     int
+		wantDuration time.Duration
+		wantErr      bool
+	}{
+		{description: "disabled", s: ""},
+		{description: "zero", s: "0"},
+		{description: "iterations", s: "500", wantIter: 500},
+		{description: "duration", s: "10s", wantDuration: 10 * time.Second},
+		{description: "negative", s: "-5", wantErr: true},
+		{description: "invalid", s: "ten", wantErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			// act
+			iter, d, err := ParseWarmup(tt.s)
+
+			// assert
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantIter, iter)
+			require.Equal(t, tt.wantDuration, d)
+		})
+	}
+}
+
+func TestRunWarmup(t *testing.T) {
+	// arrange
+	bencher := &mockedBencher{}
+	bencher.On("Exec", mock.Anything).Return(nil)
+	b := Benchmark{Name: "inserts", Type: TypeLoop, Stmt: "INSERT {{.Iter}}"}
+
+	// act
+	result := Run(context.Background(), bencher, b, Options{Iter: 10, Threads: 2, Warmup: 5})
+
+	// assert
+	require.Equal(t, 5, result.Warmup)
+	require.Equal(t, 10, result.Ops)
+	bencher.AssertNumberOfCalls(t, "Exec", 15)
+	for i := 1; i <= 15; i++ {
+		bencher.AssertCalled(t, "Exec", "INSERT "+strconv.Itoa(i))
+	}
+}
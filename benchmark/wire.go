@@ -0,0 +1,100 @@
+package benchmark
+
+import (
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// wire is 1, when the latency of the wire protocol is measured, see SetWire.
+var wire int32
+
+// Round trips of the wire protocol, the time to their first response byte and the time
+// to receive the rest of their responses (in nanoseconds) since the last TakeWire.
+var (
+	wireRoundTrips int64
+	wireFirstByte  int64
+	wireTransfer   int64
+)
+
+// SetWire sets whether the latency of the wire protocol is measured on the connections
+// wrapped by WireConn.
+func SetWire(enabled bool) {
+	v := int32(0)
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&wire, v)
+}
+
+// WireStats contains the latency of the wire protocol. A round trip starts with the last write
+// of a request, e.g. a query, and ends with the last read of its response.
+type WireStats struct {
+	RoundTrips int64
+	FirstByte  time.Duration // total time until the first byte of the responses was received, mostly the execution by the server
+	Transfer   time.Duration // total time to receive the rest of the responses, mostly the transfer of the result
+}
+
+// TakeWire returns the stats since the last call.
+func TakeWire() WireStats {
+	return WireStats{
+		RoundTrips: atomic.SwapInt64(&wireRoundTrips, 0),
+		FirstByte:  time.Duration(atomic.SwapInt64(&wireFirstByte, 0)),
+		Transfer:   time.Duration(atomic.SwapInt64(&wireTransfer, 0)),
+	}
+}
+
+// MeanFirstByte returns the mean time to the first byte of a response.
+func (s WireStats) MeanFirstByte() time.Duration {
+	if s.RoundTrips == 0 {
+		return 0
+	}
+	return s.FirstByte / time.Duration(s.RoundTrips)
+}
+
+// MeanTransfer returns the mean time to receive the rest of a response.
+func (s WireStats) MeanTransfer() time.Duration {
+	if s.RoundTrips == 0 {
+		return 0
+	}
+	return s.Transfer / time.Duration(s.RoundTrips)
+}
+
+// WireConn wraps a connection to the database, to measure the latency of its wire protocol
+// while SetWire is enabled.
+func WireConn(c net.Conn) net.Conn {
+	return &wireConn{Conn: c}
+}
+
+// wireConn measures the round trips of a connection. The drivers use a connection by
+// a single goroutine at a time, thus its state isn't locked.
+type wireConn struct {
+	net.Conn
+	sent      time.Time // last write of the request
+	received  time.Time // last read of the response
+	receiving bool      // the first byte of the response was read
+}
+
+func (c *wireConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.sent, c.receiving = time.Now(), false
+	return n, err
+}
+
+func (c *wireConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n == 0 || c.sent.IsZero() || atomic.LoadInt32(&wire) == 0 {
+		return n, err
+	}
+
+	now := time.Now()
+	if c.receiving {
+		atomic.AddInt64(&wireTransfer, int64(now.Sub(c.received)))
+	} else {
+		c.receiving = true
+		atomic.AddInt64(&wireRoundTrips, 1)
+		atomic.AddInt64(&wireFirstByte, int64(now.Sub(c.sent)))
+	}
+	c.received = now
+	return n, err
+}
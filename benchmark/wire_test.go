@@ -0,0 +1,70 @@
+package benchmark
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWireConn(t *testing.T) {
+	// arrange
+	SetWire(true)
+	defer SetWire(false)
+	TakeWire()
+
+	client, server := net.Pipe()
+	defer client.Close()
+	go func() {
+		defer server.Close()
+		// greeting before the first request, e.g. of MySQL
+		server.Write([]byte("hello"))
+		buf := make([]byte, 5)
+		server.Read(buf)
+		time.Sleep(20 * time.Millisecond)
+		server.Write([]byte("a"))
+		time.Sleep(10 * time.Millisecond)
+		server.Write([]byte("b"))
+	}()
+	c := WireConn(client)
+	buf := make([]byte, 5)
+
+	// act
+	_, err := c.Read(buf)
+	require.NoError(t, err)
+	_, err = c.Write([]byte("query"))
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = c.Read(buf[:1])
+		require.NoError(t, err)
+	}
+	stats := TakeWire()
+
+	// assert
+	require.Equal(t, int64(1), stats.RoundTrips)
+	require.True(t, stats.FirstByte >= 20*time.Millisecond, "first byte %v", stats.FirstByte)
+	require.True(t, stats.Transfer >= 10*time.Millisecond, "transfer %v", stats.Transfer)
+	require.Equal(t, stats.FirstByte, stats.MeanFirstByte())
+}
+
+func TestWireConnDisabled(t *testing.T) {
+	// arrange
+	TakeWire()
+	client, server := net.Pipe()
+	defer client.Close()
+	go func() {
+		defer server.Close()
+		buf := make([]byte, 5)
+		server.Read(buf)
+		server.Write([]byte("a"))
+	}()
+	c := WireConn(client)
+
+	// act
+	c.Write([]byte("query"))
+	c.Read(make([]byte, 1))
+
+	// assert
+	require.Zero(t, TakeWire().RoundTrips)
+}
@@ -0,0 +1,424 @@
+package main
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sj14/dbbench/benchmark"
+	"github.com/sj14/dbbench/databases"
+	"github.com/spf13/pflag"
+)
+
+// agentPlan is sent by the coordinator to an agent, see --agents. The agent builds the arguments
+// of its load generating process from it, see args.
+type agentPlan struct {
+	Token    string      `json:"token,omitempty"`
+	Database string      `json:"database"`
+	Flags    []agentFlag `json:"flags,omitempty"`  // of the run command, only the agentFlags
+	Script   string      `json:"script,omitempty"` // content of the --script file of the coordinator
+	Seed     int64       `json:"seed"`             // of the coordinator, also when it's random
+	Index    int         `json:"index"`            // of the agent, which gets its part of the iterations and threads
+	Total    int         `json:"total"`            // number of agents
+}
+
+// agentFlag is a flag of the run command as given to the coordinator.
+type agentFlag struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// agentFlags are the flags of the workload, which the coordinator sends to the agents. All other
+// flags are only used by the coordinator and rejected by the agents: they connect elsewhere, read or
+// write files, execute commands, serve or upload something or are set by the agent, see agentConn.
+var agentFlags = map[string]bool{
+	"iter": true, "duration": true, "warmup": true, "rate": true, "arrival": true, "think-time": true,
+	"threads": true, "sleep": true, "nofloor": true, "run": true, "skip": true, "tags": true,
+	"fast-placeholders": true, "max-error-rate": true, "seq-start": true, "prepared": true, "query-timeout": true,
+	"fresh-conns": true, "wire": true, "hit-ratio": true, "network": true, "read-only": true,
+	"percentiles": true, "concurrency": true, "client-stats": true, "pool-stats": true,
+	"heartbeat": true, "heartbeat-stmt": true, "long-tx": true, "scale": true, "workload": true,
+	"max-p99": true, "search-step": true, "search-start": true, "ramp": true, "ramp-rate": true, "ramp-step": true,
+	"churn-rows": true, "churn-interval": true,
+	"conns": true, "max-idle-conns": true, "conn-max-lifetime": true, "conn-max-idle-time": true, "schema": true,
+	"consistency": true, "serial-consistency": true, "replication-factor": true, "token-aware": true,
+	"cluster": true, "batch": true,
+}
+
+// agentConn is the connection of an agent to the database, given to 'dbbench agent'. The
+// coordinators can't change it, so they can't send the password to another server.
+type agentConn struct {
+	host string
+	port int
+	user string
+	pass string
+	tls  databases.TLS
+}
+
+// newAgentPlan returns the plan of the run command with the given arguments, the database followed
+// by its flags, without the token, index and total. The --script file is read and sent along.
+func newAgentPlan(args []string) (agentPlan, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return agentPlan{}, errors.New("the database must be given as argument with --agents")
+	}
+	plan := agentPlan{Database: args[0], Seed: benchmark.Seed()}
+	flags, err := (&options{db: plan.Database}).flagSet(plan.Database)
+	if err != nil {
+		return agentPlan{}, err
+	}
+	err = flags.ParseAll(args[1:], func(f *pflag.Flag, value string) error {
+		if agentFlags[f.Name] {
+			plan.Flags = append(plan.Flags, agentFlag{Name: f.Name, Value: value})
+		}
+		if f.Name == "script" {
+			dat, err := ioutil.ReadFile(value)
+			if err != nil {
+				return err
+			}
+			plan.Script = string(dat)
+		}
+		return flags.Set(f.Name, value)
+	})
+	return plan, err
+}
+
+// args returns the arguments of the load generating process of the plan, the database is
+// connected with the connection of the agent. Flags which aren't agentFlags are rejected,
+// like the plugins.
+func (p agentPlan) args(conn agentConn) ([]string, error) {
+	if p.Database == "plugin" {
+		return nil, errors.New("plugins are not supported by agents")
+	}
+	flags, err := (&options{db: p.Database}).flagSet(p.Database)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"run", p.Database}
+	for _, f := range p.Flags {
+		if flags.Lookup(f.Name) == nil {
+			return nil, fmt.Errorf("unknown flag --%v", f.Name)
+		}
+		if !agentFlags[f.Name] {
+			return nil, fmt.Errorf("flag --%v is not accepted by agents", f.Name)
+		}
+		args = append(args, "--"+f.Name+"="+f.Value)
+	}
+	args = append(args, "--noinit", "--noclean", "--seed", strconv.FormatInt(p.Seed, 10))
+	return append(args, conn.args(flags)...), nil
+}
+
+// args returns the connection flags of the run command, which the database has.
+func (c agentConn) args(flags *pflag.FlagSet) []string {
+	var args []string
+	add := func(name, value string) {
+		if flags.Lookup(name) != nil {
+			args = append(args, "--"+name+"="+value)
+		}
+	}
+	add("host", c.host)
+	add("port", strconv.Itoa(c.port))
+	if c.user != "" {
+		add("user", c.user)
+	}
+	if c.pass != "" {
+		add("pass", c.pass)
+	}
+	add("tls", c.tls.Mode)
+	for _, f := range [][2]string{{"tls-ca", c.tls.CA}, {"tls-cert", c.tls.Cert}, {"tls-key", c.tls.Key}, {"tls-server-name", c.tls.ServerName}} {
+		if f[1] != "" {
+			add(f[0], f[1])
+		}
+	}
+	return args
+}
+
+// agentCmd starts the load generating processes of coordinators on other machines, which run
+// the benchmarks with --agents, until interrupted.
+func agentCmd(args []string) int {
+	var (
+		flags  = pflag.NewFlagSet("agent", pflag.ContinueOnError)
+		listen = flags.String("listen", "localhost:7002", "address the coordinators connect to, e.g. \"10.0.0.2:7002\" to accept other machines")
+		token  = flags.String("token", os.Getenv("DBBENCH_AGENT_TOKEN"), "token the coordinators have to send with --agent-token, required (default $DBBENCH_AGENT_TOKEN)")
+		conn   agentConn
+	)
+	flags.StringVar(&conn.host, "host", "localhost", "address of the database server, the coordinators can't change it")
+	flags.IntVar(&conn.port, "port", 0, "port of the database server (0 -> db defaults)")
+	flags.StringVar(&conn.user, "user", "", "user name to connect with the database (empty -> db defaults)")
+	flags.StringVar(&conn.pass, "pass", os.Getenv("DBBENCH_AGENT_PASS"), "password to connect with the database, the coordinators don't send theirs (default $DBBENCH_AGENT_PASS)")
+	flags.StringVar(&conn.tls.Mode, "tls", "disable", "encryption of the connections to the database: "+strings.Join(databases.TLSModes, ", "))
+	flags.StringVar(&conn.tls.CA, "tls-ca", "", "PEM file of the CA certificates verifying the database server (default: system CAs)")
+	flags.StringVar(&conn.tls.Cert, "tls-cert", "", "PEM file of the client certificate")
+	flags.StringVar(&conn.tls.Key, "tls-key", "", "PEM file of the key of the client certificate")
+	flags.StringVar(&conn.tls.ServerName, "tls-server-name", "", "name verified in the server certificate (default: --host)")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: dbbench agent [flags]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return exitUsage
+	}
+	if *token == "" {
+		fmt.Fprintln(os.Stderr, "missing --token, the agent only accepts coordinators knowing it")
+		return exitUsage
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		log.Fatalf("failed to get executable: %v", err)
+	}
+	ln, err := net.Listen("tcp", *listen)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	log.Printf("waiting for coordinators on %v", ln.Addr())
+
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			log.Printf("failed to accept connection: %v", err)
+			return exitFailure
+		}
+		go serveCoordinator(c, exe, *token, conn)
+	}
+}
+
+// serveCoordinator starts the load generating process of the plan sent by the coordinator and
+// connects its stdin and stdout to the coordinator, until the coordinator closes the connection.
+// The line "interrupt" of the coordinator interrupts the running benchmark like ctrl-c.
+// The database is connected with the connection of the agent.
+func serveCoordinator(conn net.Conn, exe, token string, db agentConn) {
+	defer conn.Close()
+	addr := conn.RemoteAddr()
+
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	if err != nil {
+		log.Printf("failed to receive plan of %v: %v", addr, err)
+		return
+	}
+	var plan agentPlan
+	if err := json.Unmarshal([]byte(line), &plan); err != nil {
+		fmt.Fprintf(conn, "error: invalid plan: %v\n", err)
+		return
+	}
+	if token == "" || subtle.ConstantTimeCompare([]byte(plan.Token), []byte(token)) != 1 {
+		log.Printf("rejected coordinator %v: invalid token", addr)
+		fmt.Fprintln(conn, "error: invalid token")
+		return
+	}
+	if plan.Total < 1 || plan.Index < 0 || plan.Index >= plan.Total {
+		fmt.Fprintf(conn, "error: invalid agent %d of %d\n", plan.Index, plan.Total)
+		return
+	}
+
+	args, err := plan.args(db)
+	if err != nil {
+		log.Printf("rejected plan of %v: %v", addr, err)
+		fmt.Fprintf(conn, "error: invalid plan: %v\n", err)
+		return
+	}
+	if plan.Script != "" {
+		script, err := writeAgentScript(plan.Script)
+		if err != nil {
+			fmt.Fprintf(conn, "error: %v\n", err)
+			return
+		}
+		defer os.Remove(script)
+		args = append(args, "--script="+script)
+	}
+
+	// the coordinator compares the clocks, see clockSkew
+	fmt.Fprintf(conn, "clock %d\n", time.Now().UnixNano())
+
+	cmd := exec.Command(exe, args...)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("%v=%d/%d", procEnv, plan.Index, plan.Total))
+	cmd.Stdout = conn
+	cmd.Stderr = os.Stderr
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		fmt.Fprintf(conn, "error: %v\n", err)
+		return
+	}
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintf(conn, "error: failed to start process: %v\n", err)
+		return
+	}
+	log.Printf("started agent %d of %d for %v", plan.Index+1, plan.Total, addr)
+	start := time.Now()
+
+	go func() {
+		defer in.Close()
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				// the coordinator is done or gone, don't leave the benchmark running
+				cmd.Process.Signal(os.Interrupt)
+				return
+			}
+			if line == "interrupt\n" {
+				if err := cmd.Process.Signal(os.Interrupt); err != nil {
+					log.Printf("failed to interrupt benchmark of %v: %v", addr, err)
+				}
+				continue
+			}
+			if _, err := io.WriteString(in, line); err != nil {
+				return
+			}
+		}
+	}()
+
+	if err := cmd.Wait(); err != nil {
+		log.Printf("process of %v failed: %v", addr, err)
+		fmt.Fprintf(conn, "error: process failed: %v\n", err)
+		return
+	}
+	log.Printf("finished %v after %v", addr, time.Since(start).Round(time.Millisecond))
+}
+
+// writeAgentScript writes the script of a plan to a temporary file and returns its path.
+func writeAgentScript(script string) (string, error) {
+	f, err := ioutil.TempFile("", "dbbench-agent-*.sql")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(script); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+// halfCloser closes the sending side of the connection to an agent only,
+// so its process still reports, until it exits.
+type halfCloser struct {
+	*net.TCPConn
+}
+
+func (c halfCloser) Close() error {
+	return c.CloseWrite()
+}
+
+// maxClockSkew is the max. offset of the clock of an agent, the outages and intervals of the
+// agents are still aligned to the resolution of the outages.
+const maxClockSkew = 100 * time.Millisecond
+
+// connectAgents sends the plan of the run command with the given arguments to the agents, which
+// start a load generating process each. The iterations and threads are split between them like
+// between --procs. The agents with skewed clocks are reported.
+func connectAgents(addrs []string, token string, args []string) ([]*proc, error) {
+	plan, err := newAgentPlan(args)
+	if err != nil {
+		return nil, err
+	}
+	plan.Token, plan.Total = token, len(addrs)
+
+	procs := make([]*proc, 0, len(addrs))
+	for i, addr := range addrs {
+		plan.Index = i
+		p, err := connectAgent(addr, plan)
+		if err != nil {
+			for _, p := range procs {
+				p.in.Close()
+				p.wait()
+			}
+			return nil, fmt.Errorf("failed to connect to agent %v: %v", addr, err)
+		}
+		if p.skew > maxClockSkew {
+			log.Printf("the clock of %v is off by %v, synchronize it with NTP, its intervals may be misaligned", p.name, p.skew.Round(time.Millisecond))
+		}
+		procs = append(procs, p)
+	}
+	return procs, nil
+}
+
+// connectAgent sends the plan to the agent with the given address and returns its process.
+func connectAgent(addr string, plan agentPlan) (*proc, error) {
+	conn, err := net.DialTimeout("tcp", addr, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	p, err := newAgentProc(conn.(*net.TCPConn), plan)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	p.name = "agent " + addr
+	return p, nil
+}
+
+// newAgentProc sends the plan to the connected agent and returns its process, once the agent
+// accepted the plan and replied with its clock, which is compared with the local one.
+func newAgentProc(conn *net.TCPConn, plan agentPlan) (*proc, error) {
+	sent := time.Now()
+	if err := json.NewEncoder(conn).Encode(plan); err != nil {
+		return nil, err
+	}
+	p := &proc{in: halfCloser{conn}, out: bufio.NewReader(conn)}
+	clock, err := p.receive("clock ")
+	if err != nil {
+		return nil, err
+	}
+	p.skew, err = clockSkew(clock, sent, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	p.wait = func() error {
+		defer conn.Close()
+		// skip the output until the process exited, the agent reports its failure
+		for {
+			line, err := p.out.ReadString('\n')
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(line, "error: ") {
+				return errors.New(strings.TrimSpace(strings.TrimPrefix(line, "error: ")))
+			}
+		}
+	}
+	p.interrupt = func() {
+		if _, err := io.WriteString(conn, "interrupt\n"); err != nil {
+			log.Printf("failed to interrupt %v: %v", p.name, err)
+		}
+	}
+	return p, nil
+}
+
+// clockSkew returns the offset of the clock of an agent, given in Unix nanoseconds, which it
+// read between sent and received, like NTP. The half of the round trip is the uncertainty of the
+// offset, only the offset beyond it is returned.
+func clockSkew(clock string, sent, received time.Time) (time.Duration, error) {
+	ns, err := strconv.ParseInt(clock, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid clock %q: %v", clock, err)
+	}
+	rtt := received.Sub(sent)
+	offset := time.Unix(0, ns).Sub(sent.Round(0).Add(rtt / 2))
+	if offset < 0 {
+		offset = -offset
+	}
+	if offset <= rtt/2 {
+		return 0, nil
+	}
+	return offset - rtt/2, nil
+}
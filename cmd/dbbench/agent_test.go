@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sj14/dbbench/benchmark"
+	"github.com/sj14/dbbench/databases"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentPlanRoundTrip(t *testing.T) {
+	benchmark.SetSeed(42)
+	defer benchmark.SetSeed(0)
+
+	testCases := []struct {
+		description string
+		args        []string
+		conn        agentConn
+		want        []string
+	}{
+		{
+			description: "flags",
+			args:        []string{"sqlite", "--iter", "100", "--threads=4", "--run", "inserts selects"},
+			want:        []string{"run", "sqlite", "--iter=100", "--threads=4", "--run=inserts selects", "--noinit", "--noclean", "--seed", "42"},
+		},
+		{
+			description: "coordinator flags",
+			args:        []string{"sqlite", "--save", "r.json", "--agents", "10.0.0.2:7002", "--agent-token", "secret", "--backup", "rm -rf /", "--seed", "7"},
+			want:        []string{"run", "sqlite", "--noinit", "--noclean", "--seed", "42"},
+		},
+		{
+			description: "connection of the agent",
+			args:        []string{"postgres", "--host", "coordinator", "--port", "1", "--pass", "coordinator", "--tls", "disable", "--tls-ca", "coordinator.pem", "--set", "host=coordinator", "--conns", "10"},
+			conn:        agentConn{host: "db1", port: 5432, user: "bench", pass: "agent", tls: databases.TLS{Mode: "verify-full", CA: "ca.pem"}},
+			want: []string{"run", "postgres", "--conns=10", "--noinit", "--noclean", "--seed", "42",
+				"--host=db1", "--port=5432", "--user=bench", "--pass=agent", "--tls=verify-full", "--tls-ca=ca.pem"},
+		},
+		{
+			description: "no connection flags",
+			args:        []string{"sqlite", "--path", "/etc/passwd"},
+			conn:        agentConn{host: "db1", pass: "agent", tls: databases.TLS{Mode: "disable"}},
+			want:        []string{"run", "sqlite", "--noinit", "--noclean", "--seed", "42"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			// arrange
+			plan, err := newAgentPlan(tc.args)
+			require.NoError(t, err)
+			data, err := json.Marshal(plan)
+			require.NoError(t, err)
+			require.NotContains(t, string(data), "coordinator")
+
+			// act
+			var received agentPlan
+			require.NoError(t, json.Unmarshal(data, &received))
+			args, err := received.args(tc.conn)
+
+			// assert
+			require.NoError(t, err)
+			require.Equal(t, tc.want, args)
+		})
+	}
+}
+
+func TestAgentPlanScript(t *testing.T) {
+	// arrange
+	path := filepath.Join(t.TempDir(), "script.sql")
+	require.NoError(t, ioutil.WriteFile(path, []byte("\\benchmark loop\nSELECT 1;\n"), 0644))
+	plan, err := newAgentPlan([]string{"sqlite", "--script", path})
+	require.NoError(t, err)
+
+	// act
+	script, err := writeAgentScript(plan.Script)
+	require.NoError(t, err)
+	defer os.Remove(script)
+
+	// assert
+	require.Empty(t, plan.Flags)
+	dat, err := ioutil.ReadFile(script)
+	require.NoError(t, err)
+	require.Equal(t, "\\benchmark loop\nSELECT 1;\n", string(dat))
+}
+
+func TestAgentPlanRejected(t *testing.T) {
+	testCases := []struct {
+		description string
+		plan        agentPlan
+	}{
+		{description: "plugin", plan: agentPlan{Database: "plugin", Flags: []agentFlag{{Name: "command", Value: "sh -c id"}}}},
+		{description: "command", plan: agentPlan{Database: "postgres", Flags: []agentFlag{{Name: "backup", Value: "sh -c id"}}}},
+		{description: "file", plan: agentPlan{Database: "sqlite", Flags: []agentFlag{{Name: "save", Value: "/etc/passwd"}}}},
+		{description: "host", plan: agentPlan{Database: "postgres", Flags: []agentFlag{{Name: "host", Value: "evil"}}}},
+		{description: "tls", plan: agentPlan{Database: "postgres", Flags: []agentFlag{{Name: "tls", Value: "disable"}}}},
+		{description: "settings", plan: agentPlan{Database: "postgres", Flags: []agentFlag{{Name: "set", Value: "host=evil"}}}},
+		{description: "url", plan: agentPlan{Database: "graphql", Flags: []agentFlag{{Name: "url", Value: "http://evil"}}}},
+		{description: "script file", plan: agentPlan{Database: "postgres", Flags: []agentFlag{{Name: "script", Value: "/etc/passwd"}}}},
+		{description: "path", plan: agentPlan{Database: "sqlite", Flags: []agentFlag{{Name: "path", Value: "/etc/passwd"}}}},
+		{description: "unknown flag", plan: agentPlan{Database: "sqlite", Flags: []agentFlag{{Name: "host", Value: "db1"}}}},
+		{description: "unknown database", plan: agentPlan{Database: "db2"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			// act
+			_, err := tc.plan.args(agentConn{})
+
+			// assert
+			require.Error(t, err)
+		})
+	}
+}
+
+// serveAgent serves the coordinators like 'dbbench agent', executing exe instead of dbbench.
+func serveAgent(t *testing.T, exe, token string) string {
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveCoordinator(conn, exe, token, agentConn{})
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestConnectAgent(t *testing.T) {
+	testCases := []struct {
+		description string
+		token       string
+		wantErr     string
+	}{
+		{description: "valid token", token: "secret"},
+		{description: "invalid token", token: "guess", wantErr: "invalid token"},
+		{description: "no token", wantErr: "invalid token"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			// arrange
+			addr := serveAgent(t, "true", "secret")
+			plan := agentPlan{Token: tc.token, Database: "sqlite", Total: 1}
+
+			// act
+			p, err := connectAgent(addr, plan)
+
+			// assert
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "agent "+addr, p.name)
+			require.True(t, p.skew < maxClockSkew)
+			p.in.Close()
+			require.NoError(t, p.wait())
+		})
+	}
+}
+
+func TestClockSkew(t *testing.T) {
+	sent := time.Unix(100, 0)
+	received := sent.Add(20 * time.Millisecond)
+
+	testCases := []struct {
+		description string
+		clock       string
+		want        time.Duration
+	}{
+		{description: "synchronized", clock: "100010000000", want: 0},
+		{description: "within round trip", clock: "100015000000", want: 0},
+		{description: "ahead", clock: "100510000000", want: 490 * time.Millisecond},
+		{description: "behind", clock: "99510000000", want: 490 * time.Millisecond},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			// act
+			skew, err := clockSkew(tc.clock, sent, received)
+
+			// assert
+			require.NoError(t, err)
+			require.Equal(t, tc.want, skew)
+		})
+	}
+}
+
+// nopWriteCloser discards the benchmarks sent to a fake process.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestRunProcsMergesResults(t *testing.T) {
+	// arrange
+	fake := func(r benchmark.Result) *proc {
+		data, err := json.Marshal(r)
+		require.NoError(t, err)
+		out := "progress of the agent\ndone " + string(data) + "\n"
+		return &proc{name: "fake", in: nopWriteCloser{io.Discard}, out: bufio.NewReader(strings.NewReader(out))}
+	}
+	procs := []*proc{
+		fake(benchmark.Result{Duration: time.Second, Ops: 100, Errors: 1, Min: time.Millisecond, Mean: 2 * time.Millisecond, P99: 3 * time.Millisecond, ErrorKinds: map[string]int{"timeout": 1}}),
+		fake(benchmark.Result{Duration: 2 * time.Second, Ops: 300, Errors: 2, Min: 2 * time.Millisecond, Mean: 4 * time.Millisecond, P99: 5 * time.Millisecond, ErrorKinds: map[string]int{"timeout": 2}}),
+	}
+
+	// act
+	res := runProcs(context.Background(), procs, 0)
+
+	// assert
+	require.Equal(t, 2*time.Second, res.Duration)
+	require.Equal(t, 400, res.Ops)
+	require.Equal(t, 3, res.Errors)
+	require.Equal(t, time.Millisecond, res.Min)
+	require.Equal(t, 3500*time.Microsecond, res.Mean)
+	require.Equal(t, 5*time.Millisecond, res.P99)
+	require.Equal(t, map[string]int{"timeout": 3}, res.ErrorKinds)
+}
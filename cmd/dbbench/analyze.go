@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sj14/dbbench/querylog"
+	"github.com/spf13/pflag"
+)
+
+// analyzeCmd classifies the statements of a query log and writes a script replaying the
+// most frequent classes in the proportions they were observed.
+func analyzeCmd(args []string) int {
+	var (
+		flags  = pflag.NewFlagSet("analyze", pflag.ContinueOnError)
+		format = flags.String("format", querylog.FormatPlain, "format of the query log (plain, postgres, mysql or record)")
+		top    = flags.Int("top", 20, "number of the most frequent statement classes to include")
+		out    = flags.String("out", "", "file to write the script to (default stdout)")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: dbbench analyze [flags] <query.log>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	defer f.Close()
+
+	stmts, err := querylog.Read(f, *format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		o, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		defer o.Close()
+		w = o
+	}
+
+	if err := querylog.WriteScript(w, querylog.Classify(stmts), *top); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	return exitOK
+}
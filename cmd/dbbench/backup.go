@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/sj14/dbbench/benchmark"
+	"github.com/sj14/dbbench/results"
+)
+
+// runBackup starts the backup shell command and executes the benchmark repeatedly, at least once,
+// until the command exits. Each run continues the iteration counter at the offset.
+// The output of the command is discarded, its errors are printed. When the context is cancelled,
+// it waits for the command, which was interrupted as well.
+func runBackup(ctx context.Context, bencher benchmark.Bencher, b benchmark.Benchmark, command string, opts benchmark.Options) (*results.BackupStats, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdout, cmd.Stderr = ioutil.Discard, os.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	var (
+		stats = &results.BackupStats{}
+		start = time.Now()
+		took  time.Duration
+		ops   int
+		err   error
+	)
+	for running := true; running; {
+		result := benchmark.Run(ctx, bencher, b, opts)
+		took += result.Duration
+		ops += result.Ops
+		opts.Offset += opts.Iter
+		stats.Runs++
+		if result.P99 > stats.P99 {
+			stats.P99 = result.P99
+		}
+
+		if ctx.Err() != nil {
+			// the command got the interrupt as well
+			err = <-exited
+			stats.Took = time.Since(start)
+			break
+		}
+		select {
+		case err = <-exited:
+			stats.Took = time.Since(start)
+			running = false
+		default:
+		}
+	}
+	if ops > 0 {
+		stats.NsPerOp = took.Nanoseconds() / int64(ops)
+	}
+	if err != nil {
+		stats.Failed = err.Error()
+	}
+	return stats, nil
+}
+
+// printBackup prints the slowdown of the benchmark while the backup ran.
+func printBackup(w io.Writer, name string, nsPerOp int64, p99 time.Duration, s *results.BackupStats) {
+	slowdown := 0.0
+	if nsPerOp > 0 {
+		slowdown = float64(s.NsPerOp-nsPerOp) / float64(nsPerOp) * 100
+	}
+	fmt.Fprintf(w, "%v:\tbackup (took %v, %v runs): %.1f%% slower (%v -> %v per operation), p99 %v -> %v\n",
+		name, s.Took.Round(time.Millisecond), s.Runs, slowdown, time.Duration(nsPerOp), time.Duration(s.NsPerOp), p99, s.P99)
+	if s.Failed != "" {
+		fmt.Fprintf(w, "%v:\tbackup failed: %v\n", name, s.Failed)
+	}
+}
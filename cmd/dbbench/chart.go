@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sj14/dbbench/results"
+)
+
+// readRuns reads the comma separated result files.
+func readRuns(paths string) ([]*results.Run, error) {
+	var runs []*results.Run
+	for _, path := range strings.Split(paths, ",") {
+		run, err := results.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// chartCmd prints a SVG bar chart comparing the results of two (sets of) runs.
+func chartCmd(args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: dbbench chart <base.json>[,...] <compare.json>[,...]")
+		return exitUsage
+	}
+
+	base, err := readRuns(args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	compare, err := readRuns(args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+
+	if err := results.WriteSVG(os.Stdout, base, compare); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write chart: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
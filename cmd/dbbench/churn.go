@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// sizer is implemented by the benchers, which are able to report the size of their tables.
+type sizer interface {
+	Size() (int64, error)
+}
+
+// churnCmd inserts and deletes rows at the same rate, like a queue table or rows expiring after a TTL,
+// and reports whether the latencies and the table size stay stable.
+func churnCmd(args []string) int {
+	bencher, o, code := connect(args, false)
+	if code != exitOK {
+		return code
+	}
+
+	var insert, del *benchmark.Benchmark
+	for _, b := range bencher.Benchmarks() {
+		b := b
+		switch b.Name {
+		case "inserts":
+			insert = &b
+		case "deletes":
+			del = &b
+		}
+	}
+	if insert == nil || del == nil {
+		fmt.Fprintf(os.Stderr, "churn is not supported for %v, it needs the built-in inserts and deletes\n", o.db)
+		return exitUsage
+	}
+
+	if o.readOnly {
+		fmt.Fprintln(os.Stderr, "churn is not possible with --read-only")
+		return exitUsage
+	}
+	if !o.allowed() {
+		fmt.Fprintln(os.Stderr, o.refuse("insert and delete rows"))
+		return exitUsage
+	}
+	if o.churnRows < 1 || o.churnInterval <= 0 {
+		fmt.Fprintln(os.Stderr, "--churn-rows and --churn-interval must be positive")
+		return exitUsage
+	}
+
+	duration := o.duration
+	if duration == 0 {
+		duration = 10 * o.churnInterval
+	}
+	threads := o.threads
+	if threads < 1 {
+		threads = 1
+	}
+
+	if !o.nosetup {
+		bencher.Setup()
+	}
+	if !o.noclean {
+		defer bencher.Cleanup()
+	}
+
+	opts := benchmark.ChurnOptions{
+		Insert:   *insert,
+		Delete:   *del,
+		Rows:     o.churnRows,
+		Rate:     o.rate,
+		Threads:  threads,
+		Duration: duration,
+		Interval: o.churnInterval,
+	}
+	if s, ok := bencher.(sizer); ok {
+		opts.Size = s.Size
+	}
+
+	fmt.Printf("churn:\t%v rows for %v, %v threads\n", o.churnRows, duration, threads)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	samples := benchmark.Churn(ctx, bencher, opts, printChurnSample)
+	printChurnDrift(samples)
+	return exitOK
+}
+
+// printChurnSample prints the measurements of an interval of the churn.
+func printChurnSample(s benchmark.ChurnSample) {
+	size := "unknown"
+	if s.Size >= 0 {
+		size = fmt.Sprintf("%.1f MiB", float64(s.Size)/(1<<20))
+	}
+	fmt.Printf("churn:\t%v\t%v inserts+deletes\tp99 insert %v, delete %v\tsize %v\terrors %v\n",
+		s.Offset.Round(time.Second), s.Inserts, s.InsertP99, s.DeleteP99, size, s.Errors)
+}
+
+// printChurnDrift prints the change of the latencies and the table size between the first
+// and the last sample. A growing size indicates that the deleted rows aren't cleaned up in time.
+func printChurnDrift(samples []benchmark.ChurnSample) {
+	if len(samples) < 2 {
+		return
+	}
+	first, last := samples[0], samples[len(samples)-1]
+	fmt.Printf("churn:\tp99 insert %v -> %v (%+.1f%%), delete %v -> %v (%+.1f%%)\n",
+		first.InsertP99, last.InsertP99, drift(int64(first.InsertP99), int64(last.InsertP99)),
+		first.DeleteP99, last.DeleteP99, drift(int64(first.DeleteP99), int64(last.DeleteP99)))
+	if first.Size >= 0 && last.Size >= 0 {
+		fmt.Printf("churn:\tsize %.1f MiB -> %.1f MiB (%+.1f%%)\n",
+			float64(first.Size)/(1<<20), float64(last.Size)/(1<<20), drift(first.Size, last.Size))
+	}
+}
+
+// drift returns the change from a to b in percent.
+func drift(a, b int64) float64 {
+	if a == 0 {
+		return 0
+	}
+	return float64(b-a) / float64(a) * 100
+}
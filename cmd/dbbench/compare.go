@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sj14/dbbench/results"
+	"github.com/spf13/pflag"
+)
+
+// significanceTests are the available tests of the compare command.
+var significanceTests = map[string]results.SignificanceTest{
+	"mann-whitney": results.MannWhitney,
+	"t-test":       results.WelchTTest,
+}
+
+// compareCmd compares two sets of saved runs and only reports the changes, which are significant.
+func compareCmd(args []string) int {
+	var (
+		flags      = pflag.NewFlagSet("compare", pflag.ContinueOnError)
+		test       = flags.String("test", "mann-whitney", "significance test of the changes, mann-whitney or t-test (Welch's)")
+		alpha      = flags.Float64("alpha", 0.05, "significance level of the changes")
+		maxRegress = flags.Float64("max-regression", 10, "report benchmarks as regressions, which are significantly and more than this percentage slower than the baseline")
+		bySUT      = flags.Bool("by-sut", false, "group the given result files by their --sut-version and compare each version with the previous one")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: dbbench compare [flags] <base.json>[,...] <new.json>[,...]")
+		fmt.Fprintln(os.Stderr, "       dbbench compare --by-sut [flags] <result.json>...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if (!*bySUT && flags.NArg() != 2) || (*bySUT && flags.NArg() < 2) {
+		flags.Usage()
+		return exitUsage
+	}
+	significance, ok := significanceTests[*test]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown significance test: %v\n", *test)
+		return exitUsage
+	}
+
+	var groups [][]*results.Run
+	if *bySUT {
+		var runs []*results.Run
+		for _, path := range flags.Args() {
+			run, err := results.ReadFile(path)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return exitFailure
+			}
+			runs = append(runs, run)
+		}
+		groups = results.GroupBySUT(runs)
+	} else {
+		for _, paths := range flags.Args() {
+			runs, err := readRuns(paths)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return exitFailure
+			}
+			groups = append(groups, runs)
+		}
+	}
+
+	threshold := *maxRegress / 100
+	for i := 1; i < len(groups); i++ {
+		base, compare := groups[i-1], groups[i]
+		if base[0].SUTVersion != "" || compare[0].SUTVersion != "" {
+			fmt.Printf("%v (%v runs) -> %v (%v runs)\n", sutVersion(base), len(base), sutVersion(compare), len(compare))
+		}
+		printMismatches(os.Stdout, groupMismatches(base, compare))
+
+		for _, c := range results.Compare(base, compare, significance) {
+			switch {
+			case c.Significant(*alpha) && c.Change > threshold:
+				fmt.Printf("%v:\tregression, %v\n", c.Name, c)
+			case c.Significant(*alpha) && c.Change < -threshold:
+				fmt.Printf("%v:\timprovement, %v\n", c.Name, c)
+			default:
+				fmt.Printf("%v:\tno significant change, %v\n", c.Name, c)
+			}
+		}
+	}
+	return exitOK
+}
+
+// versioner is implemented by the benchers, which are able to report the version of the database server.
+type versioner interface {
+	ServerVersion() (string, error)
+}
+
+// groupMismatches returns the differences of the setup of the compared runs to the first baseline run,
+// each difference only once.
+func groupMismatches(base, compare []*results.Run) []results.Mismatch {
+	var (
+		mismatches []results.Mismatch
+		seen       = map[results.Mismatch]bool{}
+	)
+	for _, runs := range [][]*results.Run{base[1:], compare} {
+		for _, run := range runs {
+			for _, m := range results.Mismatches(base[0], run) {
+				if !seen[m] {
+					seen[m] = true
+					mismatches = append(mismatches, m)
+				}
+			}
+		}
+	}
+	return mismatches
+}
+
+// printMismatches warns about the differences of the setup of the compared runs.
+func printMismatches(w io.Writer, mismatches []results.Mismatch) {
+	if len(mismatches) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "WARNING: the runs are not comparable, conclusions from the changes may be wrong:")
+	for _, m := range mismatches {
+		fmt.Fprintf(w, "WARNING:   %v\n", m)
+	}
+}
+
+// sutVersion returns the SUT versions of the runs.
+func sutVersion(runs []*results.Run) string {
+	var versions []string
+	for _, run := range runs {
+		v := run.SUTVersion
+		if v == "" {
+			v = "unlabeled"
+		}
+		if !contains(versions, v) {
+			versions = append(versions, v)
+		}
+	}
+	return strings.Join(versions, ",")
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// completionCmd prints the completion script for the given shell.
+func completionCmd(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: dbbench completion bash|zsh|fish")
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "bash":
+		writeBashCompletion(os.Stdout)
+	case "zsh":
+		// zsh is able to use the bash completion
+		fmt.Fprintln(os.Stdout, "autoload -U +X bashcompinit && bashcompinit")
+		writeBashCompletion(os.Stdout)
+	case "fish":
+		writeFishCompletion(os.Stdout)
+	default:
+		fmt.Fprintf(os.Stderr, "unsupported shell: %v\n", args[0])
+		return exitUsage
+	}
+	return exitOK
+}
+
+// commandNames returns the names of all commands, and those expecting a database as their first argument.
+func commandNames() (all, withDB []string) {
+	for _, c := range commands() {
+		all = append(all, c.name)
+		if strings.Contains(c.usage, "<database>") {
+			withDB = append(withDB, c.name)
+		}
+	}
+	return all, withDB
+}
+
+// databaseFlags returns the flags of the given database.
+func databaseFlags(db string) []*pflag.Flag {
+	flags, err := (&options{}).flagSet(db)
+	if err != nil {
+		return nil
+	}
+
+	var result []*pflag.Flag
+	flags.VisitAll(func(f *pflag.Flag) {
+		result = append(result, f)
+	})
+	return result
+}
+
+func writeBashCompletion(w io.Writer) {
+	all, withDB := commandNames()
+
+	fmt.Fprintln(w, "_dbbench() {")
+	fmt.Fprintln(w, `	local cur="${COMP_WORDS[COMP_CWORD]}"`)
+	fmt.Fprintln(w, `	case "$COMP_CWORD" in`)
+	fmt.Fprintf(w, "	1) COMPREPLY=( $(compgen -W %q -- \"$cur\") ) ;;\n", strings.Join(append(all, databaseNames...), " "))
+	fmt.Fprintln(w, `	*)`)
+	fmt.Fprintln(w, `		local db="${COMP_WORDS[1]}"`)
+	fmt.Fprintf(w, "		case \"$db\" in\n")
+	fmt.Fprintf(w, "		%v)\n", strings.Join(withDB, "|"))
+	fmt.Fprintln(w, `			if [ "$COMP_CWORD" -eq 2 ]; then`)
+	fmt.Fprintf(w, "				COMPREPLY=( $(compgen -W %q -- \"$cur\") ); return\n", strings.Join(databaseNames, " "))
+	fmt.Fprintln(w, `			fi`)
+	fmt.Fprintln(w, `			db="${COMP_WORDS[2]}" ;;`)
+	fmt.Fprintln(w, `		completion)`)
+	fmt.Fprintln(w, `			COMPREPLY=( $(compgen -W "bash zsh fish" -- "$cur") ); return ;;`)
+	fmt.Fprintln(w, `		esac`)
+	fmt.Fprintln(w, `		case "$db" in`)
+	for _, db := range databaseNames {
+		var names []string
+		for _, f := range databaseFlags(db) {
+			names = append(names, "--"+f.Name)
+		}
+		fmt.Fprintf(w, "		%v) COMPREPLY=( $(compgen -W %q -- \"$cur\") ) ;;\n", db, strings.Join(names, " "))
+	}
+	fmt.Fprintln(w, `		esac ;;`)
+	fmt.Fprintln(w, `	esac`)
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, "complete -F _dbbench dbbench")
+}
+
+func writeFishCompletion(w io.Writer) {
+	_, withDB := commandNames()
+
+	fmt.Fprintln(w, "complete -c dbbench -f")
+	for _, c := range commands() {
+		fmt.Fprintf(w, "complete -c dbbench -n __fish_use_subcommand -a %v -d %q\n", c.name, c.description)
+	}
+	fmt.Fprintf(w, "complete -c dbbench -n '__fish_seen_subcommand_from completion' -a 'bash zsh fish'\n")
+
+	// databases, either directly or after a command
+	fmt.Fprintf(w, "complete -c dbbench -n __fish_use_subcommand -a %q\n", strings.Join(databaseNames, " "))
+	fmt.Fprintf(w, "complete -c dbbench -n '__fish_seen_subcommand_from %v; and not __fish_seen_subcommand_from %v' -a %q\n",
+		strings.Join(withDB, " "), strings.Join(databaseNames, " "), strings.Join(databaseNames, " "))
+
+	for _, db := range databaseNames {
+		for _, f := range databaseFlags(db) {
+			fmt.Fprintf(w, "complete -c dbbench -n '__fish_seen_subcommand_from %v' -l %v -d %q\n", db, f.Name, f.Usage)
+		}
+	}
+}
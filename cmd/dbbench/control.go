@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// controlState is the state of the running benchmark returned by the control API.
+type controlState struct {
+	Live    bool    `json:"live"` // a live loop benchmark is running, which can be tuned
+	Paused  bool    `json:"paused"`
+	Rate    float64 `json:"rate,omitempty"`
+	Threads int     `json:"threads,omitempty"`
+}
+
+// serveControl serves the HTTP API to pause, resume and tune the running benchmarks on addr:
+//
+//	GET  /state   returns the controlState
+//	POST /tuning  changes the rate and threads, e.g. {"rate": 500, "threads": 10}
+//	POST /pause   pauses the loop benchmarks
+//	POST /resume  resumes the loop benchmarks
+func serveControl(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/state", func(w http.ResponseWriter, r *http.Request) {
+		writeState(w)
+	})
+	mux.HandleFunc("/tuning", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		// omitted fields keep their values
+		t, _ := benchmark.CurrentTuning()
+		if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := benchmark.SetTuning(t); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		log.Printf("tuned to %.0f ops/s (0 -> unlimited) with %v threads", t.Rate, t.Threads)
+		writeState(w)
+	})
+	mux.HandleFunc("/pause", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if benchmark.Pause() {
+			log.Println("paused")
+		}
+		writeState(w)
+	})
+	mux.HandleFunc("/resume", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if benchmark.Resume() {
+			log.Println("resumed")
+		}
+		writeState(w)
+	})
+
+	go func() {
+		if err := http.Serve(ln, mux); err != nil {
+			log.Printf("control API stopped: %v\n", err)
+		}
+	}()
+	return nil
+}
+
+// writeState writes the state of the running benchmark as JSON.
+func writeState(w http.ResponseWriter) {
+	t, live := benchmark.CurrentTuning()
+	state := controlState{Live: live, Paused: benchmark.Paused()}
+	if live {
+		state.Rate, state.Threads = t.Rate, t.Threads
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(state); err != nil {
+		log.Printf("failed to write state: %v\n", err)
+	}
+}
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sj14/dbbench/config"
+	"github.com/sj14/dbbench/results"
+	"github.com/spf13/pflag"
+)
+
+// daemonCmd runs the suites of a schedule repeatedly in the background and serves their results
+// with an HTTP API, until interrupted or stopped as Windows service. Suites with thresholds alert
+// with the webhook of the schedule, when a run fails or exceeds them.
+func daemonCmd(args []string) int {
+	var (
+		flags   = pflag.NewFlagSet("daemon", pflag.ContinueOnError)
+		listen  = flags.String("listen", "localhost:7001", "address of the HTTP API serving the results of the suites")
+		keep    = flags.Int("keep", 100, "number of runs of each suite kept for the HTTP API")
+		saveDir = flags.String("save-dir", "", "directory to save the results of each run, e.g. for 'dbbench trend' (default: not saved)")
+		logPath = flags.String("log", "", "file to append the log and the output of the runs to, e.g. when running as service (default stderr)")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: dbbench daemon [flags] <schedule.yaml>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 || *keep < 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	schedule, err := config.LoadSchedule(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	var out io.Writer = os.Stderr
+	if *logPath != "" {
+		f, err := os.OpenFile(*logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		defer f.Close()
+		log.SetOutput(f)
+		out = f
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		log.Fatalf("failed to get executable: %v", err)
+	}
+	ln, err := net.Listen("tcp", *listen)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+
+	d := &daemon{
+		exe:      exe,
+		out:      out,
+		keep:     *keep,
+		saveDir:  *saveDir,
+		webhook:  schedule.Webhook,
+		runs:     map[string][]daemonRun{},
+		running:  map[string]bool{},
+		alerting: map[string]bool{},
+	}
+	return runService(func(ctx context.Context) int {
+		d.serve(ctx, ln, schedule.Suites)
+		return exitOK
+	})
+}
+
+// daemonRun is a finished run of a suite.
+type daemonRun struct {
+	Start    time.Time       `json:"start"`
+	Duration time.Duration   `json:"duration"`
+	ExitCode int             `json:"exit_code"` // of 'dbbench run', -1 when it didn't exit
+	Error    string          `json:"error,omitempty"`
+	Results  *results.Run    `json:"results,omitempty"` // missing when the run failed before saving them
+	Alerts   []results.Alert `json:"alerts,omitempty"`  // exceeded thresholds of the suite
+}
+
+// daemonSuite is the state of a suite returned by the HTTP API.
+type daemonSuite struct {
+	Name    string        `json:"name"`
+	Every   time.Duration `json:"every"`
+	Running bool          `json:"running"`
+	Alerts  int           `json:"alerts"`         // of the last run
+	Runs    int           `json:"runs"`           // kept runs
+	Last    *daemonRun    `json:"last,omitempty"` // without the results
+}
+
+// daemon runs the suites and keeps their last runs.
+type daemon struct {
+	exe     string
+	out     io.Writer // output of the runs
+	keep    int
+	saveDir string
+	webhook string // posted to when the alerts of a suite change
+
+	mu       sync.Mutex
+	runs     map[string][]daemonRun // of each suite, the newest first
+	running  map[string]bool
+	alerting map[string]bool // the last run of the suite had alerts
+}
+
+// serve runs each suite every interval and serves the HTTP API, until the context is cancelled.
+// The running suites are interrupted and waited for.
+func (d *daemon) serve(ctx context.Context, ln net.Listener, suites []config.Suite) {
+	srv := &http.Server{Handler: d.handler(suites)}
+	go func() {
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			log.Printf("daemon API stopped: %v\n", err)
+		}
+	}()
+	log.Printf("serving the results of %v suites on %v\n", len(suites), ln.Addr())
+
+	var wg sync.WaitGroup
+	for _, s := range suites {
+		wg.Add(1)
+		go func(s config.Suite) {
+			defer wg.Done()
+			ticker := time.NewTicker(s.Every)
+			defer ticker.Stop()
+			for {
+				d.run(ctx, s)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(s)
+	}
+	wg.Wait()
+	srv.Close()
+}
+
+// run executes the suite with 'dbbench run' and keeps its results.
+func (d *daemon) run(ctx context.Context, s config.Suite) {
+	if ctx.Err() != nil {
+		return
+	}
+	d.setRunning(s.Name, true)
+	defer d.setRunning(s.Name, false)
+
+	start := time.Now()
+	path := filepath.Join(d.saveDir, fmt.Sprintf("%v-%v.json", s.Name, start.Format("20060102-150405")))
+	if d.saveDir == "" {
+		f, err := ioutil.TempFile("", "dbbench-"+s.Name)
+		if err != nil {
+			log.Printf("suite %v: failed to create temp file: %v\n", s.Name, err)
+			return
+		}
+		f.Close()
+		path = f.Name()
+		defer os.Remove(path)
+	}
+
+	log.Printf("suite %v: started\n", s.Name)
+	cmd := exec.CommandContext(ctx, d.exe, append(append([]string{"run"}, s.Args...), "--save", path)...)
+	cmd.Stdout, cmd.Stderr = d.out, d.out
+	// interrupted runs still clean up, unless they don't exit in time or can't be interrupted (windows)
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = time.Minute
+	err := cmd.Run()
+
+	r := daemonRun{Start: start, Duration: time.Since(start)}
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		r.ExitCode = exitErr.ExitCode()
+		r.Error = err.Error()
+	case err != nil:
+		r.ExitCode = -1
+		r.Error = err.Error()
+	}
+	if run, err := results.ReadFile(path); err == nil {
+		r.Results = run
+	}
+	log.Printf("suite %v: finished with exit code %v after %v\n", s.Name, r.ExitCode, r.Duration.Round(time.Second))
+	if ctx.Err() != nil {
+		// interrupted by stopping the daemon, not by the database
+		s.Alert = nil
+	}
+
+	d.mu.Lock()
+	if s.Alert != nil {
+		r.Alerts = d.alerts(s, r)
+	}
+	runs := append([]daemonRun{r}, d.runs[s.Name]...)
+	if len(runs) > d.keep {
+		runs = runs[:d.keep]
+	}
+	d.runs[s.Name] = runs
+	changed := s.Alert != nil && d.alerting[s.Name] != (len(r.Alerts) > 0)
+	d.alerting[s.Name] = len(r.Alerts) > 0
+	d.mu.Unlock()
+
+	for _, a := range r.Alerts {
+		log.Printf("suite %v: alert %v: %v\n", s.Name, a.Name, a.Message)
+	}
+	if changed && d.webhook != "" {
+		if err := results.NotifyAlerts(d.webhook, s.Name, r.Alerts); err != nil {
+			log.Printf("suite %v: %v\n", s.Name, err)
+		}
+	}
+}
+
+// alerts returns the exceeded thresholds of the run, compared to the kept runs of the suite.
+// It must be called with the lock held.
+func (d *daemon) alerts(s config.Suite, r daemonRun) []results.Alert {
+	if r.Results == nil || r.ExitCode != exitOK {
+		msg := "failed"
+		if r.Error != "" {
+			msg = "failed: " + r.Error
+		}
+		return []results.Alert{{Name: s.Name, Message: msg}}
+	}
+
+	var previous []*results.Run
+	for _, p := range d.runs[s.Name] {
+		if len(previous) == s.Alert.Baseline {
+			break
+		}
+		if p.Results != nil && p.ExitCode == exitOK {
+			previous = append(previous, p.Results)
+		}
+	}
+	return results.CanaryAlerts(r.Results, previous, results.CanaryThresholds{
+		NsPerOp: s.Alert.NsPerOp,
+		P99:     s.Alert.P99,
+		Drift:   s.Alert.Drift,
+	})
+}
+
+func (d *daemon) setRunning(name string, running bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.running[name] = running
+}
+
+// handler returns the HTTP API:
+//
+//	GET /suites               returns the state of each suite
+//	GET /suites/{name}/runs   returns the kept runs of the suite, the newest first
+//	GET /suites/{name}/latest returns the last run of the suite
+//	GET /metrics              returns the last run of each suite as Prometheus metrics
+func (d *daemon) handler(suites []config.Suite) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /suites", func(w http.ResponseWriter, r *http.Request) {
+		d.mu.Lock()
+		states := make([]daemonSuite, 0, len(suites))
+		for _, s := range suites {
+			state := daemonSuite{Name: s.Name, Every: s.Every, Running: d.running[s.Name], Runs: len(d.runs[s.Name])}
+			if runs := d.runs[s.Name]; len(runs) > 0 {
+				state.Alerts = len(runs[0].Alerts)
+				last := runs[0]
+				last.Results = nil
+				state.Last = &last
+			}
+			states = append(states, state)
+		}
+		d.mu.Unlock()
+		writeJSON(w, states)
+	})
+	mux.HandleFunc("GET /suites/{name}/runs", func(w http.ResponseWriter, r *http.Request) {
+		runs, ok := d.suiteRuns(suites, r.PathValue("name"))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, runs)
+	})
+	mux.HandleFunc("GET /suites/{name}/latest", func(w http.ResponseWriter, r *http.Request) {
+		runs, ok := d.suiteRuns(suites, r.PathValue("name"))
+		if !ok || len(runs) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, runs[0])
+	})
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.writeMetrics(w, suites)
+	})
+	return mux
+}
+
+// writeMetrics writes the last run of each suite in the Prometheus text format, e.g. to alert
+// with Alertmanager instead of the webhook. It must be called with the lock held.
+func (d *daemon) writeMetrics(w io.Writer, suites []config.Suite) {
+	fmt.Fprintln(w, "# HELP dbbench_canary_exit_code Exit code of the last run of the suite.")
+	fmt.Fprintln(w, "# TYPE dbbench_canary_exit_code gauge")
+	for _, s := range suites {
+		if runs := d.runs[s.Name]; len(runs) > 0 {
+			fmt.Fprintf(w, "dbbench_canary_exit_code{suite=%q} %v\n", s.Name, runs[0].ExitCode)
+		}
+	}
+	fmt.Fprintln(w, "# HELP dbbench_canary_alerts Exceeded thresholds of the last run of the suite.")
+	fmt.Fprintln(w, "# TYPE dbbench_canary_alerts gauge")
+	for _, s := range suites {
+		if runs := d.runs[s.Name]; len(runs) > 0 {
+			fmt.Fprintf(w, "dbbench_canary_alerts{suite=%q} %v\n", s.Name, len(runs[0].Alerts))
+		}
+	}
+	fmt.Fprintln(w, "# HELP dbbench_canary_last_run_timestamp_seconds Start of the last run of the suite.")
+	fmt.Fprintln(w, "# TYPE dbbench_canary_last_run_timestamp_seconds gauge")
+	for _, s := range suites {
+		if runs := d.runs[s.Name]; len(runs) > 0 {
+			fmt.Fprintf(w, "dbbench_canary_last_run_timestamp_seconds{suite=%q} %v\n", s.Name, runs[0].Start.Unix())
+		}
+	}
+	fmt.Fprintln(w, "# HELP dbbench_canary_seconds_per_op Duration per operation of the benchmark in the last run of the suite.")
+	fmt.Fprintln(w, "# TYPE dbbench_canary_seconds_per_op gauge")
+	for _, s := range suites {
+		if runs := d.runs[s.Name]; len(runs) > 0 && runs[0].Results != nil {
+			for _, b := range runs[0].Results.Benchmarks {
+				if b.Skipped == "" {
+					fmt.Fprintf(w, "dbbench_canary_seconds_per_op{suite=%q,benchmark=%q} %v\n", s.Name, b.Name, time.Duration(b.NsPerOp).Seconds())
+				}
+			}
+		}
+	}
+	fmt.Fprintln(w, "# HELP dbbench_canary_p99_seconds P99 latency of the benchmark in the last run of the suite, with --percentiles.")
+	fmt.Fprintln(w, "# TYPE dbbench_canary_p99_seconds gauge")
+	for _, s := range suites {
+		if runs := d.runs[s.Name]; len(runs) > 0 && runs[0].Results != nil {
+			for _, b := range runs[0].Results.Benchmarks {
+				if b.Skipped == "" && b.Latency != nil {
+					fmt.Fprintf(w, "dbbench_canary_p99_seconds{suite=%q,benchmark=%q} %v\n", s.Name, b.Name, b.Latency.P99.Seconds())
+				}
+			}
+		}
+	}
+}
+
+// suiteRuns returns the kept runs of the suite and whether the suite exists.
+func (d *daemon) suiteRuns(suites []config.Suite, name string) ([]daemonRun, bool) {
+	for _, s := range suites {
+		if s.Name == name {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			return append([]daemonRun{}, d.runs[name]...), true
+		}
+	}
+	return nil, false
+}
+
+// writeJSON writes v as JSON response.
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("failed to write response: %v\n", err)
+	}
+}
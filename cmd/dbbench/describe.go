@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// describeCmd lists the built-in benchmarks and the available template functions.
+func describeCmd(args []string) int {
+	benchers := builtinBenchers()
+
+	var names []string
+	for name := range benchers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(args) > 0 {
+		if _, ok := benchers[args[0]]; !ok {
+			fmt.Fprintf(os.Stderr, "unknown database: %v, available: %v\n", args[0], strings.Join(names, "|"))
+			return exitUsage
+		}
+		names = args[:1]
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "Built-in benchmarks:")
+	for _, name := range names {
+		fmt.Fprintf(w, "%v:\n", name)
+
+		benchmarks := benchers[name].Benchmarks()
+		if len(benchmarks) == 0 {
+			fmt.Fprintln(w, "\tnone, use your own script")
+		}
+		for _, b := range benchmarks {
+			if b.Skip != "" {
+				fmt.Fprintf(w, "\t%v\t(%v)\tskipped, %v\n", b.Name, b.Type, b.Skip)
+				continue
+			}
+			// only the first line of statements with several lines, e.g. pipelines
+			stmt := b.Stmt
+			if len(b.Mix) > 0 {
+				stmt = describeMix(b.Mix)
+			}
+			if lines := strings.Split(stmt, "\n"); len(lines) > 1 {
+				stmt = fmt.Sprintf("%v (+%d lines)", lines[0], len(lines)-1)
+			}
+			fmt.Fprintf(w, "\t%v\t(%v)\t[%v]\t%v\n", b.Name, b.Type, strings.Join(b.Tags, ","), stmt)
+		}
+	}
+
+	fmt.Fprintln(w, "\nTemplate variables and functions:")
+	for _, doc := range benchmark.TemplateDocs {
+		fmt.Fprintf(w, "\t%v\t%v\n", doc.Example, doc.Description)
+	}
+
+	w.Flush()
+	return exitOK
+}
+
+// describeMix returns the names and weights of the statements of a mixed workload, e.g. "mix: read 80%, update 20%".
+func describeMix(mix []benchmark.MixStmt) string {
+	total := 0.0
+	for _, m := range mix {
+		total += m.Weight
+	}
+	parts := make([]string, len(mix))
+	for i, m := range mix {
+		parts[i] = fmt.Sprintf("%v %.0f%%", m.Name, m.Weight/total*100)
+	}
+	return "mix: " + strings.Join(parts, ", ")
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// printer prints the statements instead of executing them, see --dry-run. It supports all
+// capabilities of the benchmarks, so they're printed like they would be executed.
+type printer struct {
+	w io.Writer
+}
+
+func (p *printer) Setup()                            {}
+func (p *printer) Cleanup()                          {}
+func (p *printer) Benchmarks() []benchmark.Benchmark { return nil }
+
+// Exec prints the statement.
+func (p *printer) Exec(stmt string) error {
+	fmt.Fprintln(p.w, terminated(stmt))
+	return nil
+}
+
+// ExecArgs prints the statement with the parameters, which would be bound to its placeholders.
+func (p *printer) ExecArgs(ctx context.Context, stmt string, args ...interface{}) error {
+	values := make([]string, len(args))
+	for i, a := range args {
+		if s, ok := a.(string); ok {
+			values[i] = fmt.Sprintf("%q", s)
+			continue
+		}
+		values[i] = fmt.Sprint(a)
+	}
+	fmt.Fprintf(p.w, "%v -- args: %v\n", terminated(stmt), strings.Join(values, ", "))
+	return nil
+}
+
+// Query prints the statement, it returns no rows.
+func (p *printer) Query(stmt string) ([]string, error) {
+	return nil, p.Exec(stmt)
+}
+
+// ExecTx prints the statements enclosed in a transaction.
+func (p *printer) ExecTx(ctx context.Context, stmts []string) error {
+	fmt.Fprintln(p.w, "BEGIN;")
+	for _, stmt := range stmts {
+		p.Exec(stmt)
+	}
+	fmt.Fprintln(p.w, "COMMIT;")
+	return nil
+}
+
+// ExecAuth prints the statement of a new connection with the authentication method.
+func (p *printer) ExecAuth(ctx context.Context, method, stmt string) error {
+	fmt.Fprintf(p.w, "-- new connection (%v)\n", method)
+	return p.Exec(stmt)
+}
+
+// ExecFreshConn prints the statement of a new connection.
+func (p *printer) ExecFreshConn(ctx context.Context, stmt string) error {
+	fmt.Fprintln(p.w, "-- new connection")
+	return p.Exec(stmt)
+}
+
+// terminated returns the statement ending with a semicolon.
+func terminated(stmt string) string {
+	stmt = strings.TrimSpace(stmt)
+	if strings.HasSuffix(stmt, ";") {
+		return stmt
+	}
+	return stmt + ";"
+}
+
+// unconnected returns the bencher of the database without connecting to it, only usable to get
+// its built-in benchmarks, e.g. for --dry-run. Plugins have no built-in benchmarks without their process.
+func unconnected(db string) benchmark.Bencher {
+	switch db {
+	case "scylla":
+		db = "cassandra"
+	case "mariadb", "tidb":
+		db = "mysql"
+	}
+	if bencher, ok := builtinBenchers()[db]; ok {
+		return bencher
+	}
+	return &printer{}
+}
+
+// dryRun prints the statements of the first iterations of the benchmarks, with the parameters bound
+// with --prepared, instead of executing them. The loop benchmarks are run by a single thread without
+// the rates, the once benchmarks only once.
+func dryRun(benchmarks []benchmark.Benchmark, iter int) int {
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	p := &printer{w: w}
+	for i, b := range benchmarks {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "-- %v\n", b.Name)
+		if b.Skip != "" {
+			fmt.Fprintf(w, "-- skipped, %v\n", b.Skip)
+			continue
+		}
+		b.Rate = 0
+		benchmark.Run(context.Background(), p, b, benchmark.Options{Iter: iter, Threads: 1})
+	}
+	return exitOK
+}
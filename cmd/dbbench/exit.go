@@ -0,0 +1,13 @@
+package main
+
+// Exit codes of dbbench, which allow wrappers and CI to distinguish the failure reason.
+const (
+	exitOK         = 0   // all benchmarks finished
+	exitFailure    = 1   // generic failure, e.g. an invalid script
+	exitUsage      = 2   // invalid command line usage
+	exitConnection = 3   // failed to connect to the database
+	exitErrors     = 4   // error threshold of a benchmark exceeded
+	exitSLO        = 5   // service level objective violated
+	exitRegression = 6   // slower than the baseline of --compare
+	exitInterrupt  = 130 // interrupted by SIGINT (ctrl-c)
+)
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sj14/dbbench/benchmark"
+	"github.com/sj14/dbbench/results"
+)
+
+// fairnessStats returns the stats of the fairness samples, nil when no benchmarks ran concurrently.
+func fairnessStats(interval time.Duration, samples []benchmark.FairnessSample) *results.FairnessStats {
+	converted := make([]results.FairnessSample, 0, len(samples))
+	for _, s := range samples {
+		converted = append(converted, results.FairnessSample{Elapsed: s.Elapsed, Index: s.Index, Throughput: s.Throughput})
+	}
+	return results.NewFairnessStats(interval, converted)
+}
+
+// printFairness prints how the concurrently running benchmarks shared the throughput.
+func printFairness(w io.Writer, f *results.FairnessStats) {
+	if f == nil {
+		fmt.Fprintln(w, "fairness:\tno benchmarks ran concurrently")
+		return
+	}
+	fmt.Fprintf(w, "fairness:\tmean index %.2f, min %.2f over %v intervals of %v\n", f.Mean, f.Min, len(f.Samples), f.Interval)
+
+	shares := f.Shares()
+	names := make([]string, 0, len(shares))
+	for name := range shares {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return shares[names[i]] > shares[names[j]] })
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%v %.1f%%", name, shares[name]*100))
+	}
+	fmt.Fprintf(w, "fairness:\tmean share of the throughput: %v\n", strings.Join(parts, ", "))
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// floorIter is the number of round trips measuring the floor.
+const floorIter = 1000
+
+// floorStmt returns the trivial statement of the database, which is executed without any work
+// of the server, empty when there is none.
+func floorStmt(db string) string {
+	switch db {
+	case "redis":
+		return "PING"
+	case "mongodb":
+		return `{"ping": 1}`
+	case "cassandra", "scylla":
+		return "SELECT release_version FROM system.local"
+	case "graphql":
+		return "{ __typename }"
+	case "plugin":
+		return ""
+	}
+	return "SELECT 1"
+}
+
+// measureFloor returns the mean latency of the trivial statement of the database on a single thread,
+// the round trip to the server and the overhead of the driver. It's the floor of the latencies of the
+// statements, which can be normalized with it. It's 0 when the database has no trivial statement.
+func measureFloor(ctx context.Context, bencher benchmark.Bencher, db string) (time.Duration, error) {
+	stmt := floorStmt(db)
+	if stmt == "" {
+		return 0, nil
+	}
+	b := benchmark.Benchmark{Name: "floor", Type: benchmark.TypeLoop, Stmt: stmt}
+	result := benchmark.Run(ctx, bencher, b, benchmark.Options{Iter: floorIter, Threads: 1})
+	if result.Errors > 0 {
+		return 0, fmt.Errorf("%v of %v statements failed", result.Errors, floorIter)
+	}
+	return result.Mean, nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// target returns the address of the database, which must match the allowed targets.
+// It's empty for local database files.
+func (o *options) target() string {
+	switch o.db {
+	case "sqlite":
+		return ""
+	case "plugin":
+		return o.pluginOpts["host"]
+	case "graphql":
+		u, err := url.Parse(o.url)
+		if err != nil {
+			return o.url
+		}
+		return u.Hostname()
+	case "redis":
+		// the first seed node of a cluster
+		host := strings.Split(o.host, ",")[0]
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			return h
+		}
+		return host
+	}
+	return o.host
+}
+
+// allowed returns whether the run may change data on the target.
+func (o *options) allowed() bool {
+	target := o.target()
+	if o.force || target == "" {
+		return true
+	}
+	for _, pattern := range o.allowTargets {
+		if ok, _ := path.Match(pattern, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// refuse returns the error of refusing the action on the target.
+func (o *options) refuse(what string) error {
+	return fmt.Errorf("refusing to %v on %v, pass --i-know-what-i-am-doing or allow the target with --allow-target", what, o.target())
+}
+
+// guard returns an error, when the run would set up or clean the tables, or execute a destructive
+// benchmark, but the target is not allowed. This prevents accidental table drops on the wrong server.
+func (o *options) guard(benchmarks []benchmark.Benchmark) error {
+	if o.allowed() {
+		return nil
+	}
+
+	switch {
+	case o.clean, !o.nosetup, !o.noclean:
+		return o.refuse("set up or clean the benchmark tables")
+	case o.stdin && !o.readOnly:
+		return o.refuse("execute unknown statements from stdin")
+	}
+	for _, b := range benchmarks {
+		if b.Skip == "" && mutates(b) {
+			return o.refuse(fmt.Sprintf("run the destructive benchmark %v", b.Name))
+		}
+	}
+	return nil
+}
+
+// mutates reports whether any statement of the benchmark changes data.
+func mutates(b benchmark.Benchmark) bool {
+	for _, stmt := range b.Statements() {
+		if benchmark.Mutates(stmt) {
+			return true
+		}
+	}
+	return false
+}
+
+// readOnlySkip is the reason why benchmarks are skipped in read-only mode.
+const readOnlySkip = "changes data (--read-only)"
+
+// skipMutating marks the benchmarks as skipped, which change data.
+func skipMutating(benchmarks []benchmark.Benchmark) {
+	for i, b := range benchmarks {
+		if b.Skip == "" && mutates(b) {
+			benchmarks[i].Skip = readOnlySkip
+		}
+	}
+}
+
+// readOnlyBencher refuses to execute statements, which change data, e.g. when streamed from stdin.
+type readOnlyBencher struct {
+	benchmark.Bencher
+}
+
+// Exec executes the statement, unless it changes data.
+func (r readOnlyBencher) Exec(stmt string) error {
+	if benchmark.Mutates(stmt) {
+		return errors.New("refused, changes data (--read-only)")
+	}
+	return r.Bencher.Exec(stmt)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// heartbeatExec returns the exec func of the heartbeat and a func to release its connection.
+// When possible, the heartbeat gets a dedicated connection, which isn't used by the benchmarks.
+func heartbeatExec(bencher benchmark.Bencher) (exec func(string) error, release func()) {
+	pooler, ok := bencher.(interface{ DB() *sql.DB })
+
+	// A single connection (sqlite) can't be dedicated to the heartbeat.
+	if ok && pooler.DB().Stats().MaxOpenConnections != 1 {
+		db := pooler.DB()
+
+		// keep the number of connections available for the benchmarks
+		if max := db.Stats().MaxOpenConnections; max > 0 {
+			db.SetMaxOpenConns(max + 1)
+		}
+
+		conn, err := db.Conn(context.Background())
+		if err == nil {
+			exec = func(stmt string) error {
+				_, err := conn.ExecContext(context.Background(), stmt)
+				return err
+			}
+			return exec, func() { conn.Close() }
+		}
+		log.Printf("failed to open heartbeat connection, sharing the benchmark connections: %v", err)
+	}
+
+	return bencher.Exec, func() {}
+}
+
+// printHeartbeat prints the latencies of the heartbeat queries during the benchmark.
+func printHeartbeat(w io.Writer, name string, stats benchmark.HeartbeatStats) {
+	if stats.Count == 0 && stats.Errors == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%v:\theartbeat: %v queries (%v errors), min %v, mean %v, max %v\n",
+		name, stats.Count, stats.Errors, stats.Min, stats.Mean(), stats.Max)
+}
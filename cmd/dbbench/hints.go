@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/sj14/dbbench/results"
+)
+
+// printHints prints the probable bottlenecks and what to adjust.
+func printHints(w io.Writer, hints []results.Hint) {
+	if len(hints) == 0 {
+		fmt.Fprintln(w, "hints:\tno bottleneck found")
+		return
+	}
+	for _, h := range hints {
+		fmt.Fprintf(w, "%v:\thint: %v, %v\n", h.Name, h.Bottleneck, h.Message)
+		fmt.Fprintf(w, "%v:\t  try: %v\n", h.Name, h.Suggestion)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sj14/dbbench/results"
+	"github.com/spf13/pflag"
+)
+
+// historyCmd prints the benchmarks of the runs stored in the results warehouse, see --warehouse,
+// or the rows of a custom query.
+func historyCmd(args []string) int {
+	var (
+		flags      = pflag.NewFlagSet("history", pflag.ContinueOnError)
+		db         = flags.String("db", "", "only runs of this database, e.g. \"postgres\"")
+		sutVersion = flags.String("sut-version", "", "only runs of this version of the system under test")
+		since      = flags.String("since", "", "only runs started at or after this date or time, e.g. \"2020-01-31\"")
+		until      = flags.String("until", "", "only runs started before this date or time")
+		names      = flags.StringSlice("run", nil, "only these benchmarks, e.g. \"inserts,selects\"")
+		limit      = flags.Int("limit", 50, "max. number of rows, the latest ones (0 -> unlimited)")
+		query      = flags.String("query", "", "custom SQL query of the dbbench_runs and dbbench_benchmarks tables instead of the filters")
+		format     = flags.String("format", "text", "output format: text|csv")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: dbbench history [flags] <warehouse-url>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 || (*format != "text" && *format != "csv") {
+		flags.Usage()
+		return exitUsage
+	}
+
+	filter := results.HistoryFilter{Database: *db, SUTVersion: *sutVersion, Benchmarks: *names, Limit: *limit}
+	for _, t := range []struct {
+		value string
+		time  *time.Time
+	}{{*since, &filter.Since}, {*until, &filter.Until}} {
+		if t.value == "" {
+			continue
+		}
+		parsed, err := parseHistoryTime(t.value)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+		*t.time = parsed
+	}
+
+	driver, dsn, err := warehouseDSN(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	conn, err := sql.Open(driver, dsn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open connection: %v\n", err)
+		return exitConnection
+	}
+	defer conn.Close()
+	if err := conn.Ping(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to connect: %v\n", err)
+		return exitConnection
+	}
+
+	var table *results.Table
+	if *query != "" {
+		table, err = results.QueryTable(conn, *query)
+	} else {
+		table, err = results.History(conn, driver, filter)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to query history: %v\n", err)
+		return exitFailure
+	}
+
+	if *format == "csv" {
+		err = table.WriteCSV(os.Stdout)
+	} else {
+		err = table.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// parseHistoryTime parses a date or an RFC 3339 time, dates are in UTC.
+func parseHistoryTime(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, use a date like 2020-01-31 or RFC 3339", value)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sj14/dbbench/config"
+)
+
+// prompter asks the user for input on the command line.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask prints the question and returns the answer, or def when the answer is empty.
+func (p *prompter) ask(question, def string) string {
+	if def != "" {
+		fmt.Fprintf(p.out, "%v [%v]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%v: ", question)
+	}
+
+	answer, _ := p.in.ReadString('\n')
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return def
+	}
+	return answer
+}
+
+// askChoice asks until the answer is one of the choices.
+func (p *prompter) askChoice(question, def string, choices []string) string {
+	for {
+		answer := p.ask(fmt.Sprintf("%v (%v)", question, strings.Join(choices, "|")), def)
+		if contains(choices, answer) {
+			return answer
+		}
+		fmt.Fprintf(p.out, "invalid choice: %q\n", answer)
+	}
+}
+
+// askInt asks until the answer is a number.
+func (p *prompter) askInt(question string, def int) int {
+	for {
+		answer := p.ask(question, strconv.Itoa(def))
+		n, err := strconv.Atoi(answer)
+		if err == nil {
+			return n
+		}
+		fmt.Fprintf(p.out, "not a number: %q\n", answer)
+	}
+}
+
+// initCmd interactively creates a config file, which can be used with 'dbbench run --config'.
+func initCmd(args []string) int {
+	path := "dbbench.yaml"
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	p := &prompter{in: bufio.NewReader(os.Stdin), out: os.Stdout}
+	cfg := &config.Config{}
+
+	cfg.Database = p.askChoice("database", "postgres", databaseNames)
+	if cfg.Database == "sqlite" {
+		cfg.Connection.Path = p.ask("database file", "dbbench.sqlite")
+	} else {
+		cfg.Connection.Host = p.ask("host", "localhost")
+		cfg.Connection.Port = p.askInt("port (0 -> db default)", 0)
+		cfg.Connection.User = p.ask("user", "root")
+		cfg.Connection.Pass = p.ask("password", "root")
+	}
+
+	if p.askChoice("workload", "builtin", []string{"builtin", "script"}) == "script" {
+		cfg.Script = p.ask("script file", "")
+	}
+	cfg.Iter = p.askInt("iterations", 1000)
+	cfg.Threads = p.askInt("threads", 25)
+
+	if _, err := os.Stat(path); err == nil {
+		if p.askChoice(fmt.Sprintf("%v exists, overwrite?", path), "no", []string{"yes", "no"}) != "yes" {
+			return exitFailure
+		}
+	}
+
+	if err := cfg.Save(path); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	fmt.Printf("created %v, run it with: dbbench run --config %v\n", path, path)
+	return exitOK
+}
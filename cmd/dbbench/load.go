@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"time"
+
+	"github.com/sj14/dbbench/benchmark"
+	"github.com/sj14/dbbench/dataset"
+)
+
+// loadBatch is the number of rows per insert when loading datasets,
+// the max. compound select of older sqlite versions.
+const loadBatch = 500
+
+// loadDialect returns the dialect of the statements loading the datasets into the database.
+func loadDialect(db string) dataset.Dialect {
+	switch db {
+	case "mysql", "mariadb", "tidb", "clickhouse":
+		return dataset.DialectMySQL
+	}
+	return dataset.DialectANSI
+}
+
+// loadDatasets loads the CSV or Parquet files into the tables (table -> file), which are created
+// unless they exist. The column names are quoted, as the headers may contain spaces or keywords.
+func loadDatasets(w io.Writer, bencher benchmark.Bencher, db string, datasets map[string]string, threads int) error {
+	var tables []string
+	for table := range datasets {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	for _, table := range tables {
+		path := datasets[table]
+		src, err := dataset.Open(path)
+		if err != nil {
+			return err
+		}
+		rows, err := dataset.Load(bencher.Exec, table, src, dataset.Options{
+			Batch:   loadBatch,
+			Threads: threads,
+			Create:  true,
+			Quote:   true,
+			Dialect: loadDialect(db),
+		})
+		src.Close()
+		if err != nil {
+			return fmt.Errorf("failed to load %v: %v", path, err)
+		}
+		fmt.Fprintf(w, "loaded %v rows from %v into %v\n", rows, path, table)
+	}
+	return nil
+}
+
+// importSchema creates the tables of the schema dump, fills each of them with synthetic rows
+// fitting the column types and executes the remaining statements afterwards, e.g. the indexes.
+// The columns may have NULL values and skewed values, see dataset.Schema.SetDistributions.
+func importSchema(w io.Writer, bencher benchmark.Bencher, db, path string, rows int, nulls, skew map[string]string, threads int) error {
+	if path == "" {
+		return nil
+	}
+	ddl, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read schema dump: %v", err)
+	}
+	schema, err := dataset.ParseSchema(string(ddl))
+	if err != nil {
+		return fmt.Errorf("failed to parse %v: %v", path, err)
+	}
+	if err := schema.SetDistributions(nulls, skew); err != nil {
+		return err
+	}
+
+	for _, stmt := range schema.Create {
+		if err := bencher.Exec(stmt); err != nil {
+			log.Printf("%v failed: %v", stmt, err)
+		}
+	}
+	for _, t := range schema.Tables {
+		if _, err := dataset.Load(bencher.Exec, t.Name, dataset.Generate(t, rows), dataset.Options{Batch: loadBatch, Threads: threads, Dialect: loadDialect(db)}); err != nil {
+			return fmt.Errorf("failed to fill %v: %v", t.Name, err)
+		}
+	}
+	for _, stmt := range schema.Other {
+		if err := bencher.Exec(stmt); err != nil {
+			log.Printf("%v failed: %v", stmt, err)
+		}
+	}
+	fmt.Fprintf(w, "imported %v tables with %v rows each from %v\n", len(schema.Tables), rows, path)
+	return nil
+}
+
+// populate executes the populate file, which bulk-loads the rows of its templated inserts,
+// see benchmark.ParsePopulate. When interrupted, the running inserts finish.
+func populate(w io.Writer, bencher benchmark.Bencher, path string, batch, threads int) error {
+	if path == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	stmts, err := benchmark.ParsePopulate(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to parse %v: %v", path, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	start := time.Now()
+	rows, err := benchmark.Populate(ctx, bencher, stmts, batch, threads)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "populated %v rows from %v in %v\n", rows, path, time.Since(start).Round(time.Millisecond))
+	return nil
+}
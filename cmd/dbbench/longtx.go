@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/sj14/dbbench/benchmark"
+	"github.com/sj14/dbbench/results"
+)
+
+// txHolder is implemented by the benchers, which are able to hold a transaction open and
+// report the garbage it prevents from being cleaned up, e.g. dead tuples or undo logs.
+type txHolder interface {
+	HoldTx() (release func(), err error)
+	Bloat() (int64, error)
+}
+
+// checkLongTx returns an error, when the duration is invalid or not supported by the bencher.
+func checkLongTx(bencher benchmark.Bencher, d time.Duration) error {
+	if d < 0 {
+		return fmt.Errorf("invalid long transaction duration %v, must not be negative", d)
+	}
+	if _, ok := bencher.(txHolder); d > 0 && !ok {
+		return fmt.Errorf("--long-tx is not supported for this database")
+	}
+	return nil
+}
+
+// bloat returns the current bloat of the holder, errors are logged and return 0.
+func bloat(holder txHolder) int64 {
+	n, err := holder.Bloat()
+	if err != nil {
+		log.Printf("failed to get bloat: %v\n", err)
+	}
+	return n
+}
+
+// runLongTx holds a transaction open for the duration, while the benchmark is executed repeatedly,
+// at least once, or until the context is cancelled. Each run continues the iteration counter at the offset.
+func runLongTx(ctx context.Context, bencher benchmark.Bencher, b benchmark.Benchmark, d time.Duration, opts benchmark.Options) (*results.LongTxStats, error) {
+	holder := bencher.(txHolder)
+	release, err := holder.HoldTx()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var (
+		stats = &results.LongTxStats{}
+		start = time.Now()
+		took  time.Duration
+		ops   int
+		first = bloat(holder)
+	)
+	for stats.Runs == 0 || time.Since(start) < d && ctx.Err() == nil {
+		result := benchmark.Run(ctx, bencher, b, opts)
+		took += result.Duration
+		ops += result.Ops
+		opts.Offset += opts.Iter
+		stats.Runs++
+	}
+	stats.Held = time.Since(start)
+	if ops > 0 {
+		stats.NsPerOp = took.Nanoseconds() / int64(ops)
+	}
+	stats.Bloat = bloat(holder) - first
+	return stats, nil
+}
+
+// printLongTx prints the slowdown of the benchmark and the bloat growth while the transaction was open.
+func printLongTx(w io.Writer, name string, nsPerOp int64, l *results.LongTxStats) {
+	slowdown := 0.0
+	if nsPerOp > 0 {
+		slowdown = float64(l.NsPerOp-nsPerOp) / float64(nsPerOp) * 100
+	}
+	fmt.Fprintf(w, "%v:\tlong transaction (held %v, %v runs): %.1f%% slower (%v -> %v per operation), bloat %+d (without %+d)\n",
+		name, l.Held.Round(time.Millisecond), l.Runs, slowdown, time.Duration(nsPerOp), time.Duration(l.NsPerOp), l.Bloat, l.BloatAlone)
+}
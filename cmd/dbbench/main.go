@@ -1,9 +1,10 @@
 package main
 
 import (
-	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"log"
 	"os"
 	"os/signal"
@@ -15,8 +16,8 @@ import (
 	_ "github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/sj14/dbbench/benchmark"
-	"github.com/sj14/dbbench/databases"
-	"github.com/spf13/pflag"
+	"github.com/sj14/dbbench/config"
+	"github.com/sj14/dbbench/dataset"
 )
 
 var (
@@ -25,194 +26,333 @@ var (
 	date    = "unknown"
 )
 
-func main() {
-	var (
-		// Default set of flags, available for all subcommands (benchmark options).
-		defaultFlags = pflag.NewFlagSet("defaults", pflag.ExitOnError)
-		iter         = defaultFlags.Int("iter", 1000, "how many iterations should be run")
-		threads      = defaultFlags.Int("threads", 25, "max. number of green threads (iter >= threads > 0)")
-		sleep        = defaultFlags.Duration("sleep", 0, "how long to pause after each single benchmark (valid units: ns, us, ms, s, m, h)")
-		nosetup      = defaultFlags.Bool("noinit", false, "do not initialize database and tables, e.g. when only running own script")
-		clean        = defaultFlags.Bool("clean", false, "only cleanup benchmark data, e.g. after a crash")
-		noclean      = defaultFlags.Bool("noclean", false, "keep benchmark data")
-		versionFlag  = defaultFlags.Bool("version", false, "print version information")
-		runBench     = defaultFlags.String("run", "all", "only run the specified benchmarks, e.g. \"inserts deletes\"")
-		scriptname   = defaultFlags.String("script", "", "custom sql file to execute")
-
-		// Connection flags, applicable for most databases (not sqlite).
-		connFlags = pflag.NewFlagSet("conn", pflag.ExitOnError)
-		host      = connFlags.String("host", "localhost", "address of the server")
-		port      = connFlags.Int("port", 0, "port of the server (0 -> db defaults)")
-		user      = connFlags.String("user", "root", "user name to connect with the server")
-		pass      = connFlags.String("pass", "root", "password to connect with the server")
-
-		// Max. connections, applicable for most databases (not cassandra, sqlite).
-		maxconnsFlags = pflag.NewFlagSet("conns", pflag.ExitOnError)
-		maxconns      = maxconnsFlags.Int("conns", 0, "max. number of open connections")
-
-		// Flag sets for each database. DB specific flags are set in the switch statement below.
-		cassandraFlags = pflag.NewFlagSet("cassandra", pflag.ExitOnError)
-		cockroachFlags = pflag.NewFlagSet("cockroach", pflag.ExitOnError)
-		mssqlFlags     = pflag.NewFlagSet("mssql", pflag.ExitOnError)
-		mysqlFlags     = pflag.NewFlagSet("mysql", pflag.ExitOnError)
-		postgresFlags  = pflag.NewFlagSet("postgres", pflag.ExitOnError)
-		sqliteFlags    = pflag.NewFlagSet("sqlite", pflag.ExitOnError)
-	)
-
-	defaultFlags.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Available subcommands:\n\tcassandra|cockroach|mssql|mysql|postgres|sqlite\n")
-		fmt.Fprintf(os.Stderr, "\tUse 'subcommand --help' for all flags of the specified command.\n")
-		fmt.Fprintf(os.Stderr, "Generic flags for all subcommands:\n")
-		defaultFlags.PrintDefaults()
+// command is a dbbench subcommand.
+type command struct {
+	name        string
+	usage       string
+	description string
+	run         func(args []string) int
+}
+
+// commands returns all available subcommands.
+func commands() []command {
+	return []command{
+		{name: "run", usage: "run <database>|--config|--target [flags]", description: "run the benchmarks against the database", run: runCmd},
+		{name: "seed", usage: "seed <database> [flags]", description: "only initialize the database and tables, keeps the data", run: seedCmd},
+		{name: "orm", usage: "orm <database> [flags]", description: "compare the overhead of ORMs (gorm, sqlx, sqlc) with raw statements", run: ormCmd},
+		{name: "churn", usage: "churn <database> [flags]", description: "insert and delete rows at the same rate and report the table size", run: churnCmd},
+		{name: "check", usage: "check <database> [flags]", description: "check the connection to the database", run: checkCmd},
+		{name: "chart", usage: "chart <base.json>[,...] <compare.json>[,...]", description: "print a SVG bar chart comparing saved results", run: chartCmd},
+		{name: "compare", usage: "compare [flags] <base>[,...] <new>[,...]", description: "report the significant changes between saved results", run: compareCmd},
+		{name: "score", usage: "score [flags] <target.json>[,...]...", description: "score targets with the geometric mean of their normalized results", run: scoreCmd},
+		{name: "trend", usage: "trend [flags] <result.json>...", description: "detect slow drifts of saved results over the last runs", run: trendCmd},
+		{name: "verify", usage: "verify --key <public key> <result.json>...", description: "verify the signatures of results saved with --sign", run: verifyCmd},
+		{name: "decrypt", usage: "decrypt --key <key> <result.json>", description: "print results saved with --encrypt-results", run: decryptCmd},
+		{name: "keygen", usage: "keygen [flags] <name>", description: "generate the keys for --sign and --encrypt-results", run: keygenCmd},
+		{name: "history", usage: "history [flags] <warehouse-url>", description: "query the results stored with --warehouse", run: historyCmd},
+		{name: "scenario", usage: "scenario [flags] <scenario.yaml>", description: "run the variants of a scenario and compare their results", run: scenarioCmd},
+		{name: "export", usage: "export [flags] <script.sql>...", description: "write scripts and their options as portable workload definition", run: exportCmd},
+		{name: "import", usage: "import [flags] <workload.yaml>", description: "write the suites of a workload definition as scripts", run: importCmd},
+		{name: "analyze", usage: "analyze [flags] <query.log>", description: "generate a workload script from a query log", run: analyzeCmd},
+		{name: "agent", usage: "agent [flags]", description: "generate the load of a coordinator running with --agents", run: agentCmd},
+		{name: "daemon", usage: "daemon [flags] <schedule.yaml>", description: "run benchmark suites on a schedule and serve their results", run: daemonCmd},
+		{name: "record", usage: "record [flags] <database-address>", description: "record the statements of applications as a proxy", run: recordCmd},
+		{name: "describe", usage: "describe [database]", description: "list the built-in benchmarks and template functions", run: describeCmd},
+		{name: "init", usage: "init [file]", description: "interactively create a config file (default dbbench.yaml)", run: initCmd},
+		{name: "completion", usage: "completion bash|zsh|fish", description: "print the shell completion script", run: completionCmd},
+		{name: "version", usage: "version", description: "print version information", run: versionCmd},
 	}
+}
 
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run executes the subcommand given in args and returns the exit code.
+func run(args []string) int {
 	// No comamnd given. Print usage help and exit.
-	if len(os.Args) < 2 {
-		defaultFlags.Usage()
-		os.Exit(1)
+	if len(args) < 1 {
+		usage()
+		return exitUsage
 	}
 
-	var bencher benchmark.Bencher
-	switch os.Args[1] {
-	case "postgres":
-		postgresFlags.AddFlagSet(defaultFlags)
-		postgresFlags.AddFlagSet(connFlags)
-		postgresFlags.AddFlagSet(maxconnsFlags)
-		postgresFlags.Parse(os.Args[2:])
-		bencher = databases.NewPostgres(*host, *port, *user, *pass, *maxconns)
-	case "cockroach":
-		cockroachFlags.AddFlagSet(defaultFlags)
-		cockroachFlags.AddFlagSet(connFlags)
-		cockroachFlags.AddFlagSet(maxconnsFlags)
-		cockroachFlags.Parse(os.Args[2:])
-		bencher = databases.NewCockroach(*host, *port, *user, *pass, *maxconns)
-	case "cassandra", "scylla":
-		cassandraFlags.AddFlagSet(defaultFlags)
-		cassandraFlags.AddFlagSet(connFlags)
-		cassandraFlags.Parse(os.Args[2:])
-		bencher = databases.NewCassandra(*host, *port, *user, *pass)
-	case "mysql", "mariadb", "tidb":
-		mysqlFlags.AddFlagSet(defaultFlags)
-		mysqlFlags.AddFlagSet(connFlags)
-		mysqlFlags.AddFlagSet(maxconnsFlags)
-		mysqlFlags.Parse(os.Args[2:])
-		bencher = databases.NewMySQL(*host, *port, *user, *pass, *maxconns)
-	case "mssql":
-		mssqlFlags.AddFlagSet(defaultFlags)
-		mssqlFlags.AddFlagSet(connFlags)
-		mssqlFlags.AddFlagSet(maxconnsFlags)
-		mssqlFlags.Parse(os.Args[2:])
-		bencher = databases.NewMSSQL(*host, *port, *user, *pass, *maxconns)
-	case "sqlite":
-		sqliteFlags.AddFlagSet(defaultFlags)
-		path := sqliteFlags.String("path", "dbbench.sqlite", "database file (sqlite only)")
-		sqliteFlags.Parse(os.Args[2:])
-		bencher = databases.NewSQLite(*path)
-	default:
-		defaultFlags.Parse(os.Args[1:])
-
-		// Only show version information and exit.
-		if *versionFlag {
-			fmt.Printf("dbbench %v, commit %v, built at %v\n", version, commit, date)
-			os.Exit(0)
+	for _, c := range commands() {
+		if c.name == args[0] {
+			return c.run(args[1:])
 		}
+	}
+
+	switch args[0] {
+	case "--version", "-version":
+		return versionCmd(nil)
+	case "--help", "-help", "-h", "help":
+		usage()
+		return exitOK
+	}
 
-		// Command not recognized. Print usage help and exit.
-		defaultFlags.Usage()
-		os.Exit(1)
+	// Keep 'dbbench <database> [flags]' working as an alias of the run command.
+	if contains(databaseNames, args[0]) {
+		return runCmd(args)
 	}
 
-	// only clean old data when clean flag is set
-	if *clean {
-		bencher.Cleanup()
-		fmt.Println("cleaned data")
-		os.Exit(0)
+	// Run the benchmarks of a config file with 'dbbench --config <file>', too.
+	if strings.HasPrefix(args[0], "-") && configPath(args) != "" {
+		return runCmd(args)
 	}
 
-	// setup database
-	if !*nosetup {
-		bencher.Setup()
+	// Command not recognized. Print usage help and exit.
+	usage()
+	return exitUsage
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage:\n\tdbbench <command> [arguments]\n")
+	fmt.Fprintf(os.Stderr, "Available commands:\n")
+	for _, c := range commands() {
+		fmt.Fprintf(os.Stderr, "\t%-46v %v\n", c.usage, c.description)
 	}
+	fmt.Fprintf(os.Stderr, "Available databases:\n\t%v\n", strings.Join(databaseNames, "|"))
+	fmt.Fprintf(os.Stderr, "\tUse 'dbbench run <database> --help' for all flags of the specified database.\n")
+}
 
-	// only cleanup benchmark data when noclean flag is not set
-	if !*noclean {
-		defer bencher.Cleanup()
+// configPath returns the value of the --config flag in args.
+func configPath(args []string) string {
+	for i, arg := range args {
+		if arg == "--config" && i+1 < len(args) {
+			return args[i+1]
+		}
+		if strings.HasPrefix(arg, "--config=") {
+			return strings.TrimPrefix(arg, "--config=")
+		}
 	}
+	return ""
+}
 
-	// we need at least one thread
-	if *threads == 0 {
-		*threads = 1
-		fmt.Println("increased to 1 thread")
+// parseDatabase parses the database name and its flags from args.
+// When a config file is given, the database and the defaults of the flags are taken from it.
+func parseDatabase(args []string) (db string, opts *options, ok bool) {
+	var cfg *config.Config
+	if path := configPath(args); path != "" {
+		var err error
+		if cfg, err = config.Load(path); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return "", nil, false
+		}
 	}
 
-	// can't have more threads than iterations
-	if *threads > *iter {
-		*threads = *iter
+	switch {
+	case len(args) > 0 && !strings.HasPrefix(args[0], "-"):
+		db, args = args[0], args[1:]
+	case cfg != nil:
+		db = cfg.Database
+	default:
+		fmt.Fprintf(os.Stderr, "missing database, available: %v\n", strings.Join(databaseNames, "|"))
+		return "", nil, false
 	}
 
-	// Use built-in benchmarks.
-	benchmarks := bencher.Benchmarks()
+	opts = &options{db: db}
+	flags, err := opts.flagSet(db)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return "", nil, false
+	}
 
-	// If a script was specified, overwrite built-in benchmarks.
-	if *scriptname != "" {
-		dat, err := ioutil.ReadFile(*scriptname)
-		if err != nil {
-			log.Fatalf("failed to read file: %v", err)
+	if cfg != nil {
+		opts.suite, opts.suiteFuncs = cfg.Benchmarks, cfg.Functions
+		for name, value := range cfg.Flags() {
+			// skip flags which are not available for this database, e.g. host for sqlite
+			if flags.Lookup(name) == nil {
+				continue
+			}
+			if err := flags.Set(name, value); err != nil {
+				fmt.Fprintf(os.Stderr, "invalid config value for %v: %v\n", name, err)
+				return "", nil, false
+			}
 		}
-		buf := bytes.NewBuffer(dat)
-		benchmarks, err = benchmark.ParseScript(buf)
-		if err != nil {
-			log.Fatalf("failed to parse script: %v\n", err)
+	}
+
+	flags.Parse(args)
+	return db, opts, true
+}
+
+// connect parses the database flags from args and connects to the database. With --dry-run,
+// which is only possible when allowed, the database isn't connected.
+func connect(args []string, dryRun bool) (benchmark.Bencher, *options, int) {
+	db, opts, ok := parseDatabase(args)
+	if !ok {
+		return nil, nil, exitUsage
+	}
+	if err := opts.tls.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, exitUsage
+	}
+
+	if opts.dryRun != 0 && !dryRun {
+		fmt.Fprintln(os.Stderr, "--dry-run is only possible with the run command")
+		return nil, nil, exitUsage
+	}
+
+	var bencher benchmark.Bencher
+	if opts.dryRun > 0 {
+		// the statements are only printed
+		bencher = unconnected(db)
+	} else {
+		var err error
+		if bencher, err = opts.connect(db); err != nil {
+			log.Printf("failed to connect: %v\n", err)
+			return nil, nil, exitConnection
 		}
 	}
+	if err := setWorkload(bencher, db, opts.workload, opts.scale); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, exitUsage
+	}
+	return bencher, opts, exitOK
+}
 
-	// split benchmark names when "-run 'bench0 bench1 ...'" flag was used
-	toRun := strings.Split(*runBench, " ")
+// workloader is implemented by the benchers, which have built-in workloads besides their built-in benchmarks.
+type workloader interface {
+	SetWorkload(name string, scale int) error
+}
 
-	startTotal := time.Now()
+// setWorkload replaces the built-in benchmarks of the bencher with the workload, the scale is its size.
+func setWorkload(bencher benchmark.Bencher, db, workload string, scale int) error {
+	if workload == "" {
+		return nil
+	}
+	w, ok := bencher.(workloader)
+	if !ok {
+		return fmt.Errorf("the workloads are not supported by %v", db)
+	}
+	return w.SetWorkload(workload, scale)
+}
 
-	// notify channel for SIGINT (ctrl-c)
-	sigchan := make(chan os.Signal, 1)
-	signal.Notify(sigchan, os.Interrupt)
+// selector is implemented by the benchers, whose setup depends on the selected built-in benchmarks,
+// e.g. creating the users of the authentication benchmarks only when they're selected.
+type selector interface {
+	SelectBenchmarks(names []string)
+}
 
-	for i, b := range benchmarks {
-		select {
-		case <-sigchan:
-			// got SIGINT, stop benchmarking
-			printTotal(startTotal)
-			// using os.Exit(130) instead of return won't
-			// run deferred funcs (e.g. b.Cleanup())
-			return
-		default:
-			// check if we want to run this particular benchmark
-			if !contains(toRun, "all") && !contains(toRun, b.Name) {
-				continue
-			}
+// rowsPerScale is the number of rows seeded per scale factor.
+const rowsPerScale = 100000
 
-			// run the particular benchmark
-			took := benchmark.Run(bencher, b, *iter, *threads)
+// seeder is implemented by the benchers, which are able to seed the tables of their built-in benchmarks.
+type seeder interface {
+	// Seed inserts the seeded rows [from, to), existing rows of the range are replaced.
+	Seed(from, to int) error
+}
 
-			// execution in ns for mode once
-			nsPerOp := took.Nanoseconds()
+// checkScale returns an error, when the scale or its seed options are invalid or the scale
+// is not supported by the bencher.
+func checkScale(bencher benchmark.Bencher, o *options) error {
+	if o.scale < 0 {
+		return fmt.Errorf("invalid scale %v, must not be negative", o.scale)
+	}
+	if _, ok := bencher.(seeder); o.scale > 0 && !ok {
+		return fmt.Errorf("--scale is not supported for this database")
+	}
+	if o.seedChunk < 1 || o.seedThreads < 1 {
+		return fmt.Errorf("--seed-chunk and --seed-threads must be positive")
+	}
+	return nil
+}
 
-			// execution in ns/op for mode loop
-			if b.Type == benchmark.TypeLoop {
-				nsPerOp /= int64(*iter)
-			}
+// seedScale seeds the tables of the built-in benchmarks with the rows of the scale in chunks,
+// see dataset.SeedChunks. When interrupted, the running chunks finish.
+func seedScale(w io.Writer, bencher benchmark.Bencher, o *options) error {
+	if o.scale == 0 {
+		return nil
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
-			fmt.Printf("%v:\t%v\t%v\tns/op\n", b.Name, took, nsPerOp)
+	rows := o.scale * rowsPerScale
+	err := dataset.SeedChunks(ctx, bencher.(seeder).Seed, rows, dataset.ChunkOptions{
+		Chunk:      o.seedChunk,
+		Threads:    o.seedThreads,
+		Checkpoint: o.checkpoint,
+		Progress: func(p dataset.ChunkProgress) {
+			if p.Chunks > 1 {
+				log.Printf("seeded rows %v-%v, %v of %v chunks (%.0f%%), eta %v\n",
+					p.From, p.To-1, p.Done, p.Chunks, float64(p.Done)/float64(p.Chunks)*100, p.ETA().Round(time.Second))
+			}
+		},
+	})
+	switch {
+	case err == context.Canceled && o.checkpoint != "":
+		return fmt.Errorf("interrupted, run it again with --seed-checkpoint %v to resume: %w", o.checkpoint, err)
+	case err != nil:
+		return err
+	}
+	fmt.Fprintf(w, "seeded %v rows (scale %v)\n", rows, o.scale)
+	return nil
+}
 
-			// Don't sleep after the last benchmark
-			if i != len(benchmarks)-1 {
-				time.Sleep(*sleep)
+// seedCmd only initializes the database, e.g. before running own scripts with --noinit.
+func seedCmd(args []string) int {
+	bencher, o, code := connect(args, false)
+	if code != exitOK {
+		return code
+	}
+	if o.readOnly {
+		fmt.Fprintln(os.Stderr, "seeding is not possible with --read-only")
+		return exitUsage
+	}
+	if !o.allowed() {
+		fmt.Fprintln(os.Stderr, o.refuse("set up the benchmark tables"))
+		return exitUsage
+	}
+	if err := checkScale(bencher, o); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	bencher.Setup()
+	if o.workload == "" {
+		if err := seedScale(os.Stdout, bencher, o); err != nil {
+			log.Printf("failed to seed: %v\n", err)
+			if errors.Is(err, context.Canceled) {
+				return exitInterrupt
+			}
+			return exitFailure
+		}
+	}
+	if err := loadDatasets(os.Stdout, bencher, o.db, o.load, o.loadThread); err != nil {
+		log.Printf("failed to load datasets: %v\n", err)
+		return exitFailure
+	}
+	if err := importSchema(os.Stdout, bencher, o.db, o.ddl, o.ddlRows, o.ddlNulls, o.ddlSkew, o.loadThread); err != nil {
+		log.Printf("failed to import schema: %v\n", err)
+		return exitFailure
+	}
+	if !o.nopopulate {
+		if err := populate(os.Stdout, bencher, o.populate, o.popBatch, o.loadThread); err != nil {
+			log.Printf("failed to populate: %v\n", err)
+			if errors.Is(err, context.Canceled) {
+				return exitInterrupt
 			}
+			return exitFailure
 		}
 	}
-	printTotal(startTotal)
+	fmt.Println("seeded database")
+	return exitOK
+}
+
+// checkCmd checks the connection to the database.
+func checkCmd(args []string) int {
+	_, o, code := connect(args, false)
+	if code != exitOK {
+		return code
+	}
+	fmt.Println("connection ok")
+	if hasPool(o.db) {
+		fmt.Printf("connection pool: %v\n", connPool(o.pool()))
+	}
+	return exitOK
 }
 
-func printTotal(startTotal time.Time) {
-	fmt.Printf("total: %v\n", time.Since(startTotal))
+func versionCmd(args []string) int {
+	fmt.Printf("dbbench %v, commit %v, built at %v\n", version, commit, date)
+	return exitOK
 }
 
 func contains(options []string, want string) bool {
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// serveMetrics serves the metrics of the running benchmarks in the Prometheus text format on addr:
+//
+//	GET /metrics  returns the operations, errors, latencies and workers of each benchmark
+func serveMetrics(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	benchmark.SetMetrics(true)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		writeMetrics(w, benchmark.Metrics())
+	})
+	go func() {
+		if err := http.Serve(ln, mux); err != nil {
+			log.Printf("metrics endpoint stopped: %v\n", err)
+		}
+	}()
+	return nil
+}
+
+// writeMetrics writes the metrics of the benchmarks in the Prometheus text format.
+func writeMetrics(w io.Writer, metrics []benchmark.BenchmarkMetrics) {
+	fmt.Fprintln(w, "# HELP dbbench_operations_total Executed statements of the benchmark, including the failed ones.")
+	fmt.Fprintln(w, "# TYPE dbbench_operations_total counter")
+	for _, m := range metrics {
+		fmt.Fprintf(w, "dbbench_operations_total{benchmark=%q} %v\n", m.Name, m.Ops)
+	}
+	fmt.Fprintln(w, "# HELP dbbench_errors_total Failed statements of the benchmark.")
+	fmt.Fprintln(w, "# TYPE dbbench_errors_total counter")
+	for _, m := range metrics {
+		fmt.Fprintf(w, "dbbench_errors_total{benchmark=%q} %v\n", m.Name, m.Errors)
+	}
+	fmt.Fprintln(w, "# HELP dbbench_workers Running workers of the benchmark.")
+	fmt.Fprintln(w, "# TYPE dbbench_workers gauge")
+	for _, m := range metrics {
+		fmt.Fprintf(w, "dbbench_workers{benchmark=%q} %v\n", m.Name, m.Workers)
+	}
+	fmt.Fprintln(w, "# HELP dbbench_statement_duration_seconds Latency of the statements of the benchmark.")
+	fmt.Fprintln(w, "# TYPE dbbench_statement_duration_seconds histogram")
+	for _, m := range metrics {
+		for i, bound := range benchmark.LatencyBuckets {
+			fmt.Fprintf(w, "dbbench_statement_duration_seconds_bucket{benchmark=%q,le=\"%v\"} %v\n", m.Name, bound.Seconds(), m.Buckets[i])
+		}
+		fmt.Fprintf(w, "dbbench_statement_duration_seconds_bucket{benchmark=%q,le=\"+Inf\"} %v\n", m.Name, m.Ops)
+		fmt.Fprintf(w, "dbbench_statement_duration_seconds_sum{benchmark=%q} %v\n", m.Name, m.Sum.Seconds())
+		fmt.Fprintf(w, "dbbench_statement_duration_seconds_count{benchmark=%q} %v\n", m.Name, m.Ops)
+	}
+}
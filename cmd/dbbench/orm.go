@@ -0,0 +1,91 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/sj14/dbbench/orm"
+)
+
+// ormCmd compares the overhead of ORMs and other database access layers with raw statements.
+func ormCmd(args []string) int {
+	bencher, o, code := connect(args, false)
+	if code != exitOK {
+		return code
+	}
+
+	var dialect, table string
+	switch o.db {
+	case "postgres", "cockroach":
+		dialect, table = "postgres", o.schema+".orm"
+	case "mysql", "mariadb", "tidb":
+		dialect, table = "mysql", o.schema+".orm"
+	case "sqlite":
+		dialect, table = "sqlite3", "dbbench_orm"
+	}
+
+	pooler, ok := bencher.(interface{ DB() *sql.DB })
+	if !ok || dialect == "" {
+		fmt.Fprintf(os.Stderr, "orm comparison is not supported for %v\n", o.db)
+		return exitUsage
+	}
+	db := pooler.DB()
+
+	// the orm benchmarks always create and drop their table
+	if o.readOnly {
+		fmt.Fprintln(os.Stderr, "orm comparison is not possible with --read-only")
+		return exitUsage
+	}
+	if !o.allowed() {
+		fmt.Fprintln(os.Stderr, o.refuse("create the orm table"))
+		return exitUsage
+	}
+
+	if !o.nosetup {
+		bencher.Setup()
+	}
+	if !o.noclean {
+		defer bencher.Cleanup()
+	}
+
+	if err := orm.CreateTable(db, table); err != nil {
+		log.Printf("failed to create table: %v\n", err)
+		return exitFailure
+	}
+	defer func() {
+		if err := orm.DropTable(db, table); err != nil {
+			log.Printf("failed to drop table: %v\n", err)
+		}
+	}()
+
+	layers, err := orm.Layers(db, dialect, table)
+	if err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	threads := o.threads
+	if threads < 1 {
+		threads = 1
+	}
+	if threads > o.iter {
+		threads = o.iter
+	}
+
+	results, err := orm.Run(layers, orm.Options{Iter: o.iter, Threads: threads})
+	if err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "operation\tlayer\tns/op\toverhead")
+	for _, r := range results {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%+.1f%%\n", r.Operation, r.Layer, r.NsPerOp, r.Overhead*100)
+	}
+	w.Flush()
+	return exitOK
+}
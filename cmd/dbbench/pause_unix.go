@@ -0,0 +1,30 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// handlePause pauses the loop benchmarks on SIGUSR1 and resumes them on SIGUSR2,
+// e.g. while taking a backup or triggering a failover on the database server.
+func handlePause() {
+	sigchan := make(chan os.Signal, 1)
+	signal.Notify(sigchan, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		for sig := range sigchan {
+			switch {
+			case sig == syscall.SIGUSR1 && benchmark.Pause():
+				log.Printf("paused, send SIGUSR2 to pid %v to resume", os.Getpid())
+			case sig == syscall.SIGUSR2 && benchmark.Resume():
+				log.Println("resumed")
+			}
+		}
+	}()
+}
@@ -0,0 +1,4 @@
+package main
+
+// handlePause does nothing on windows, which has no signals to pause the benchmarks.
+func handlePause() {}
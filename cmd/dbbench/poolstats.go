@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sj14/dbbench/benchmark"
+	"github.com/sj14/dbbench/databases"
+	"github.com/sj14/dbbench/results"
+)
+
+// poolSamples converts the samples taken during the benchmark started at start.
+func poolSamples(start time.Time, samples []benchmark.PoolSample) []results.PoolSample {
+	var result []results.PoolSample
+	for _, s := range samples {
+		result = append(result, results.PoolSample{
+			Offset:            s.Time.Sub(start).Round(time.Millisecond),
+			Open:              s.Open,
+			InUse:             s.InUse,
+			Idle:              s.Idle,
+			WaitCount:         s.WaitCount,
+			WaitDuration:      s.WaitDuration,
+			MaxIdleClosed:     s.MaxIdleClosed,
+			MaxIdleTimeClosed: s.MaxIdleTimeClosed,
+			MaxLifetimeClosed: s.MaxLifetimeClosed,
+		})
+	}
+	return result
+}
+
+// connPool returns the configuration of the connection pool for the results.
+func connPool(p databases.Pool) *results.ConnPool {
+	return &results.ConnPool{MaxOpen: p.MaxOpen, MaxIdle: p.MaxIdle, MaxLifetime: p.MaxLifetime, MaxIdleTime: p.MaxIdleTime}
+}
+
+// printPoolStats prints the peak connections and the total waits for connections during the benchmark.
+func printPoolStats(w io.Writer, name string, samples []results.PoolSample) {
+	if len(samples) == 0 {
+		return
+	}
+	var (
+		peak   results.PoolSample
+		waits  int64
+		waited time.Duration
+		closed int64
+	)
+	for _, s := range samples {
+		if s.Open > peak.Open {
+			peak.Open = s.Open
+		}
+		if s.InUse > peak.InUse {
+			peak.InUse = s.InUse
+		}
+		waits += s.WaitCount
+		waited += s.WaitDuration
+		closed += s.MaxIdleClosed + s.MaxIdleTimeClosed + s.MaxLifetimeClosed
+	}
+	var mean time.Duration
+	if waits > 0 {
+		mean = waited / time.Duration(waits)
+	}
+	fmt.Fprintf(w, "%v:\tpool: %v samples, max %v open, %v in use, %v waits for a connection (total %v, mean %v), %v closed\n",
+		name, len(samples), peak.Open, peak.InUse, waits, waited, mean, closed)
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// procEnv is set for the load generating child processes and contains "<index>/<total>".
+const procEnv = "DBBENCH_PROC"
+
+// proc is a load generating child process, started locally or by an agent.
+type proc struct {
+	name string // for the errors
+	in   io.WriteCloser
+	out  *bufio.Reader
+	wait func() error // until the process exited
+
+	// interrupts the running benchmark, nil when the process gets the ctrl-c of the terminal
+	interrupt func()
+
+	skew time.Duration // of the clock of an agent, beyond the uncertainty of its measurement
+}
+
+// numaNodes returns the number of NUMA nodes of this machine, at least 1.
+func numaNodes() int {
+	nodes, err := filepath.Glob("/sys/devices/system/node/node[0-9]*")
+	if err != nil || len(nodes) == 0 {
+		return 1
+	}
+	return len(nodes)
+}
+
+// split returns the offset and the amount of the part of total,
+// when total is distributed to several parts.
+func split(total, parts, part int) (offset, n int) {
+	n = total / parts
+	offset = n * part
+
+	// Add the remainder to the last part.
+	if part == parts-1 {
+		n += total % parts
+	}
+	return offset, n
+}
+
+// procOptions returns the benchmark options for the child process
+// when the given options are distributed across all processes.
+func procOptions(opts benchmark.Options, index, total int) benchmark.Options {
+	offset, iter := split(opts.Iter, total, index)
+	threadOffset, threads := split(opts.Threads, total, index)
+	totalThreads := opts.Threads
+
+	// at least one thread per process
+	if opts.Threads < total {
+		threadOffset, threads, totalThreads = index, 1, total
+	}
+	if threads > iter {
+		threads = iter
+	}
+	return benchmark.Options{
+		Iter:         iter,
+		Threads:      threads,
+		Offset:       opts.Offset + offset,
+		Rate:         opts.Rate / float64(total),
+		ThreadOffset: threadOffset,
+		TotalThreads: totalThreads,
+		Arrival:      opts.Arrival,
+		ThinkTime:    opts.ThinkTime,
+	}
+}
+
+// childProc returns the index and the total amount of processes,
+// when the current process is a load generating child process.
+func childProc() (index, total int, ok bool) {
+	env := os.Getenv(procEnv)
+	if env == "" {
+		return 0, 0, false
+	}
+	if _, err := fmt.Sscanf(env, "%d/%d", &index, &total); err != nil {
+		log.Fatalf("failed to parse %v: %v", procEnv, err)
+	}
+	return index, total, true
+}
+
+// startProcs starts the load generating child processes. When numa is set,
+// each process gets bound to its own NUMA node (requires numactl).
+func startProcs(total int, numa bool) []*proc {
+	exe, err := os.Executable()
+	if err != nil {
+		log.Fatalf("failed to get executable: %v", err)
+	}
+
+	numactl := ""
+	if numa {
+		if numactl, err = exec.LookPath("numactl"); err != nil {
+			log.Println("numactl not found, processes won't be bound to NUMA nodes")
+		}
+	}
+
+	procs := make([]*proc, 0, total)
+	for i := 0; i < total; i++ {
+		args := childArgs()
+
+		var cmd *exec.Cmd
+		if numactl != "" {
+			node := strconv.Itoa(i)
+			cmd = exec.Command(numactl, append([]string{"--cpunodebind=" + node, "--membind=" + node, exe}, args...)...)
+		} else {
+			cmd = exec.Command(exe, args...)
+		}
+		cmd.Env = append(os.Environ(), fmt.Sprintf("%v=%d/%d", procEnv, i, total))
+		cmd.Stderr = os.Stderr
+
+		in, err := cmd.StdinPipe()
+		if err != nil {
+			log.Fatalf("failed to create stdin pipe: %v", err)
+		}
+		out, err := cmd.StdoutPipe()
+		if err != nil {
+			log.Fatalf("failed to create stdout pipe: %v", err)
+		}
+		if err := cmd.Start(); err != nil {
+			log.Fatalf("failed to start process: %v", err)
+		}
+		procs = append(procs, &proc{name: fmt.Sprintf("process %d", i), in: in, out: bufio.NewReader(out), wait: cmd.Wait})
+	}
+	return procs
+}
+
+// childArgs returns the arguments of the load generating child processes, the ones of this process.
+// The parent takes care of setting up and cleaning the database. The children use the seed
+// of the parent, which is reported, also when it's random.
+func childArgs() []string {
+	return append(append([]string{}, os.Args[1:]...), "--noinit", "--noclean", "--seed", strconv.FormatInt(benchmark.Seed(), 10))
+}
+
+// receive skips the other output of the process until the line starting with prefix
+// and returns the rest of the line. The agents report their errors with "error: ...".
+func (p *proc) receive(prefix string) (string, error) {
+	for {
+		line, err := p.out.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if strings.HasPrefix(line, "error: ") {
+			return "", fmt.Errorf("%v", strings.TrimPrefix(line, "error: "))
+		}
+		if strings.HasPrefix(line, prefix) {
+			return strings.TrimPrefix(line, prefix), nil
+		}
+	}
+}
+
+// awaitProcs waits until all child processes are connected to the database and ready to
+// start the benchmarks at the same time.
+func awaitProcs(procs []*proc) {
+	for _, p := range procs {
+		if _, err := p.receive("ready"); err != nil {
+			log.Fatalf("failed to start %v: %v", p.name, err)
+		}
+	}
+}
+
+// runProcs executes the benchmark with the given index on all child processes, waits
+// until all of them are finished and returns their merged result. The child processes
+// without a terminal are interrupted, when ctx is canceled.
+func runProcs(ctx context.Context, procs []*proc, index int) benchmark.Result {
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-ctx.Done():
+			for _, p := range procs {
+				if p.interrupt != nil {
+					p.interrupt()
+				}
+			}
+		case <-finished:
+		}
+	}()
+
+	wg := &sync.WaitGroup{}
+	wg.Add(len(procs))
+
+	res := make([]benchmark.Result, len(procs))
+	for i, p := range procs {
+		go func(i int, p *proc) {
+			defer wg.Done()
+			if _, err := fmt.Fprintf(p.in, "%d\n", index); err != nil {
+				log.Fatalf("failed to send benchmark to %v: %v", p.name, err)
+			}
+			data, err := p.receive("done ")
+			if err != nil {
+				log.Fatalf("failed to receive result from %v: %v", p.name, err)
+			}
+			if err := json.Unmarshal([]byte(data), &res[i]); err != nil {
+				log.Fatalf("failed to parse result of %v: %v", p.name, err)
+			}
+		}(i, p)
+	}
+	wg.Wait()
+	return benchmark.MergeResults(res)
+}
+
+// stopProcs signals the end of the benchmarks to the child processes and waits for their exit.
+func stopProcs(procs []*proc) {
+	for _, p := range procs {
+		p.in.Close()
+		if err := p.wait(); err != nil {
+			log.Printf("%v failed: %v", p.name, err)
+		}
+	}
+}
+
+// serveParent executes the benchmarks requested by the parent process and reports their results,
+// until the parent closes stdin. The ctrl-c of the parent interrupts the running benchmark.
+func serveParent(bencher benchmark.Bencher, benchmarks []benchmark.Benchmark, opts benchmark.Options) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Println("ready")
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		index, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+		if err != nil || index < 0 || index >= len(benchmarks) {
+			log.Fatalf("received invalid benchmark from parent: %q", scanner.Text())
+		}
+		data, err := json.Marshal(benchmark.Run(ctx, bencher, benchmarks[index], opts))
+		if err != nil {
+			log.Fatalf("failed to encode result: %v", err)
+		}
+		fmt.Printf("done %s\n", data)
+	}
+}
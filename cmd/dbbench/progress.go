@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// printProgress returns a func printing the progress of the benchmark. The executed operations
+// are shown relative to the iterations or the elapsed time relative to the duration, when known.
+func printProgress(w io.Writer, name string, iter int, duration time.Duration) func(benchmark.ProgressSample) {
+	return func(s benchmark.ProgressSample) {
+		elapsed, ops := s.Elapsed.Round(time.Second).String(), fmt.Sprintf("%v ops", s.Ops)
+		switch {
+		case duration > 0:
+			elapsed += "/" + duration.String()
+		case iter > 0:
+			ops = fmt.Sprintf("%v/%v ops (%.0f%%)", s.Ops, iter, float64(s.Ops)/float64(iter)*100)
+		}
+		fmt.Fprintf(w, "%v:\tprogress %v\t%v\t%.0f ops/s\tp50 %v, p95 %v, p99 %v\n",
+			name, elapsed, ops, s.Throughput, s.P50, s.P95, s.P99)
+	}
+}
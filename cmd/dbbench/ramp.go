@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/sj14/dbbench/benchmark"
+	"github.com/sj14/dbbench/results"
+)
+
+// parseRamp returns the options of the ramp of --ramp or --ramp-rate, without steps when disabled.
+func parseRamp(o *options) (benchmark.RampOptions, error) {
+	ramp := benchmark.RampOptions{Threads: o.threads, Duration: o.rampStep}
+	var err error
+	switch {
+	case o.ramp != "" && o.rampRate != "":
+		return ramp, errors.New("--ramp and --ramp-rate can't be combined")
+	case (o.ramp != "" || o.rampRate != "") && o.maxP99 > 0:
+		return ramp, errors.New("the ramp can't be combined with the throughput search of --max-p99")
+	case o.ramp != "":
+		if ramp.Steps, err = benchmark.ParseRamp(o.ramp); err != nil {
+			return ramp, err
+		}
+		for _, threads := range ramp.Steps {
+			if threads != math.Trunc(threads) {
+				return ramp, fmt.Errorf("invalid ramp %q, the threads must be whole numbers", o.ramp)
+			}
+		}
+	case o.rampRate != "":
+		ramp.Rates = true
+		if ramp.Steps, err = benchmark.ParseRamp(o.rampRate); err != nil {
+			return ramp, err
+		}
+	}
+	return ramp, nil
+}
+
+// rampPeak returns the step at the saturation point or the last step, when the ramp didn't saturate.
+func rampPeak(ramp *benchmark.RampResult) (benchmark.RampStep, bool) {
+	switch {
+	case len(ramp.Steps) == 0:
+		return benchmark.RampStep{}, false
+	case ramp.Saturation >= 0:
+		return ramp.Steps[ramp.Saturation], true
+	}
+	return ramp.Steps[len(ramp.Steps)-1], true
+}
+
+// rampSteps converts the steps of the ramp.
+func rampSteps(ramp *benchmark.RampResult) []results.RampStep {
+	var steps []results.RampStep
+	for i, s := range ramp.Steps {
+		steps = append(steps, results.RampStep{
+			Threads:    s.Threads,
+			Rate:       s.Rate,
+			Ops:        s.Ops,
+			Errors:     s.Errors,
+			Throughput: s.Throughput,
+			P50:        s.P50,
+			P99:        s.P99,
+			Saturated:  i == ramp.Saturation,
+		})
+	}
+	return steps
+}
+
+// printRamp prints the steps of the ramp with the p99 latency relative to the first step
+// and the saturation point, beyond which more load only adds latency.
+func printRamp(w io.Writer, name string, ramp *benchmark.RampResult) {
+	for i, s := range ramp.Steps {
+		load := fmt.Sprintf("%v threads", s.Threads)
+		if s.Rate > 0 {
+			load = fmt.Sprintf("%.0f ops/s requested", s.Rate)
+		}
+		var factor float64
+		if first := ramp.Steps[0].P99; first > 0 {
+			factor = float64(s.P99) / float64(first)
+		}
+		var mark string
+		if i == ramp.Saturation {
+			mark = "\t<- saturation"
+		}
+		fmt.Fprintf(w, "%v:\tramp %v\t%.0f ops/s\tp50 %v\tp99 %v (%.1fx)%v\n", name, load, s.Throughput, s.P50, s.P99, factor, mark)
+	}
+	peak, ok := rampPeak(ramp)
+	switch {
+	case !ok:
+		return
+	case ramp.Saturation < 0:
+		fmt.Fprintf(w, "%v:\tnot saturated, the throughput kept up with the load up to %.0f ops/s\n", name, peak.Throughput)
+	case peak.Rate > 0:
+		fmt.Fprintf(w, "%v:\tsaturated at %.0f ops/s requested: %.0f ops/s, p99 %v\n", name, peak.Rate, peak.Throughput, peak.P99)
+	default:
+		fmt.Fprintf(w, "%v:\tsaturated at %v threads: %.0f ops/s, p99 %v\n", name, peak.Threads, peak.Throughput, peak.P99)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+
+	"github.com/sj14/dbbench/proxy"
+	"github.com/spf13/pflag"
+)
+
+// recordCmd forwards the connections of applications to the database and records their statements,
+// until interrupted. The recorded log can be turned into a script with 'dbbench analyze --format record'.
+func recordCmd(args []string) int {
+	var (
+		flags    = pflag.NewFlagSet("record", pflag.ContinueOnError)
+		listen   = flags.String("listen", "localhost:6432", "address the applications connect to instead of the database")
+		protocol = flags.String("protocol", proxy.ProtocolPostgres, "wire protocol of the database: postgres|mysql")
+		out      = flags.String("out", "", "file to write the recorded statements to (default stdout)")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: dbbench record [flags] <database-address>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		defer f.Close()
+		w = f
+	}
+
+	recorder, err := proxy.NewRecorder(flags.Arg(0), *protocol, proxy.WriteTo(w))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	ln, err := net.Listen("tcp", *listen)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	fmt.Fprintf(os.Stderr, "recording the statements sent to %v, forwarded to %v (ctrl-c to stop)\n", ln.Addr(), flags.Arg(0))
+
+	sigchan := make(chan os.Signal, 1)
+	signal.Notify(sigchan, os.Interrupt)
+	go func() {
+		<-sigchan
+		ln.Close()
+	}()
+	recorder.Serve(ln)
+	recorder.Close()
+
+	recorded, skipped := recorder.Stats()
+	fmt.Fprintf(os.Stderr, "recorded %v statements", recorded)
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, ", skipped %v with binary parameters", skipped)
+	}
+	fmt.Fprintln(os.Stderr)
+	return exitOK
+}
@@ -0,0 +1,1304 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sj14/dbbench/benchmark"
+	"github.com/sj14/dbbench/config"
+	"github.com/sj14/dbbench/results"
+)
+
+// runCmd runs the benchmarks against the database.
+func runCmd(args []string) int {
+	targets, shared, err := parseTargets(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	if len(targets) > 0 {
+		if len(shared) > 0 && !strings.HasPrefix(shared[0], "-") {
+			fmt.Fprintln(os.Stderr, "the databases are given by --target, not as argument")
+			return exitUsage
+		}
+		return runTargets(targets, shared)
+	}
+
+	bencher, o, code := connect(shared, true)
+	if code != exitOK {
+		return code
+	}
+
+	if o.hitRatio < 0 || o.hitRatio > 1 {
+		fmt.Fprintf(os.Stderr, "invalid hit ratio %v, must be between 0.0 and 1.0\n", o.hitRatio)
+		return exitUsage
+	}
+	benchmark.SetHitRatio(o.hitRatio)
+	if o.seed != 0 {
+		benchmark.SetSeed(o.seed)
+	}
+
+	if o.network != "" {
+		network, err := benchmark.ParseNetwork(o.network)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+		benchmark.SetNetwork(network)
+	}
+
+	if o.prepared {
+		if _, ok := bencher.(benchmark.ArgsExecer); !ok {
+			fmt.Fprintf(os.Stderr, "prepared statements are not supported by %v\n", o.db)
+			return exitUsage
+		}
+		benchmark.SetPrepared(true)
+	}
+
+	if o.stmtTimeout < 0 {
+		fmt.Fprintf(os.Stderr, "invalid --query-timeout %v, must not be negative\n", o.stmtTimeout)
+		return exitUsage
+	}
+	benchmark.SetQueryTimeout(o.stmtTimeout)
+
+	if o.maxP99 > 0 && o.searchStart <= 0 {
+		fmt.Fprintf(os.Stderr, "invalid --search-start %v, must be positive\n", o.searchStart)
+		return exitUsage
+	}
+
+	if o.freshConns {
+		if _, ok := bencher.(benchmark.FreshConnExecer); !ok {
+			fmt.Fprintf(os.Stderr, "new connections per statement are not supported by %v\n", o.db)
+			return exitUsage
+		}
+	}
+
+	if o.wire {
+		switch o.db {
+		case "postgres", "cockroach", "mysql", "mariadb", "tidb":
+		default:
+			fmt.Fprintf(os.Stderr, "the wire latency is not supported by %v\n", o.db)
+			return exitUsage
+		}
+		benchmark.SetWire(true)
+	}
+
+	warmupIter, warmupDuration, err := benchmark.ParseWarmup(o.warmup)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	var thinkTime benchmark.Distribution
+	if o.thinkTime != "" {
+		if thinkTime, err = benchmark.ParseDistribution(o.thinkTime); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+	}
+	if err := benchmark.ValidateArrival(o.arrival); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	ramp, err := parseRamp(o)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	// the stats the hints are derived from
+	if o.hints {
+		o.clientStat, o.concurrent = true, true
+		if o.poolStats == 0 {
+			o.poolStats = time.Second
+		}
+	}
+
+	if err := checkScale(bencher, o); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	if o.checkpoint != "" && !o.noclean {
+		fmt.Fprintln(os.Stderr, "--seed-checkpoint needs --noclean, the cleanup drops the seeded rows, or seed with 'dbbench seed'")
+		return exitUsage
+	}
+	if err := checkLongTx(bencher, o.longTx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	slos, err := results.ParseSLOs(o.slo)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	if !validFormat(o.format) {
+		fmt.Fprintf(os.Stderr, "unknown format: %v, available: %v\n", o.format, strings.Join(results.Formats, "|"))
+		return exitUsage
+	}
+	// the progress goes to stderr with --format, to keep stdout clean for the results
+	out := io.Writer(os.Stdout)
+	if o.format != "text" {
+		out = os.Stderr
+	}
+	if o.warehouse != "" {
+		if _, _, err := warehouseDSN(o.warehouse); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+	}
+
+	if (o.sign != "" || o.encResults != "") && o.save == "" {
+		fmt.Fprintln(os.Stderr, "--sign and --encrypt-results need --save")
+		return exitUsage
+	}
+	var signKey ed25519.PrivateKey
+	if o.sign != "" {
+		if signKey, err = results.ReadSigningKey(o.sign); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+	}
+	var encryptKey []byte
+	if o.encResults != "" {
+		if encryptKey, err = results.ReadEncryptionKey(o.encResults); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+	}
+
+	var baseline *results.Run
+	if o.compare != "" {
+		if baseline, err = results.ReadFile(o.compare); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+	}
+
+	if o.readOnly {
+		if o.clean {
+			fmt.Fprintln(os.Stderr, "--clean is not possible with --read-only")
+			return exitUsage
+		}
+		o.nosetup, o.noclean = true, true
+	}
+
+	if o.dryRun < 0 {
+		fmt.Fprintf(os.Stderr, "invalid --dry-run %v, must not be negative\n", o.dryRun)
+		return exitUsage
+	}
+	if o.dryRun > 0 {
+		if o.clean || o.stdin {
+			fmt.Fprintln(os.Stderr, "--clean and --stdin are not possible with --dry-run")
+			return exitUsage
+		}
+		o.nosetup, o.noclean = true, true
+	}
+
+	if err := o.guard(nil); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	benchmark.SetFastPlaceholders(o.fastPH)
+
+	// Load generating child process, the parent takes care of everything else.
+	procIndex, procTotal, isChild := childProc()
+
+	// only clean old data when clean flag is set
+	if o.clean {
+		bencher.Cleanup()
+		fmt.Fprintln(out, "cleaned data")
+		return exitOK
+	}
+
+	// setup database
+	if !o.nosetup {
+		if s, ok := bencher.(selector); ok && o.scriptname == "" && len(o.suite) == 0 {
+			// the benchmarks are selected again after the setup, which may skip some of them
+			selected, _ := benchmark.Select(bencher.Benchmarks(), benchmark.Filter{Run: o.runBench, Skip: o.skip, Tags: o.tags})
+			names := make([]string, 0, len(selected))
+			for _, b := range selected {
+				names = append(names, b.Name)
+			}
+			s.SelectBenchmarks(names)
+		}
+		bencher.Setup()
+	}
+
+	// only cleanup benchmark data when noclean flag is not set
+	if !o.noclean {
+		defer bencher.Cleanup()
+	}
+
+	if !o.nosetup {
+		if o.workload == "" {
+			if err := seedScale(out, bencher, o); err != nil {
+				log.Printf("failed to seed: %v\n", err)
+				if errors.Is(err, context.Canceled) {
+					return exitInterrupt
+				}
+				return exitFailure
+			}
+		}
+		if err := loadDatasets(out, bencher, o.db, o.load, o.loadThread); err != nil {
+			log.Printf("failed to load datasets: %v\n", err)
+			return exitFailure
+		}
+		if err := importSchema(out, bencher, o.db, o.ddl, o.ddlRows, o.ddlNulls, o.ddlSkew, o.loadThread); err != nil {
+			log.Printf("failed to import schema: %v\n", err)
+			return exitFailure
+		}
+		if !o.nopopulate {
+			if err := populate(out, bencher, o.populate, o.popBatch, o.loadThread); err != nil {
+				log.Printf("failed to populate: %v\n", err)
+				if errors.Is(err, context.Canceled) {
+					return exitInterrupt
+				}
+				return exitFailure
+			}
+		}
+	}
+
+	// we need at least one thread
+	if o.threads == 0 {
+		o.threads = 1
+		fmt.Fprintln(out, "increased to 1 thread")
+	}
+
+	// can't have more threads than iterations
+	if o.threads > o.iter && o.duration == 0 {
+		o.threads = o.iter
+	}
+
+	if o.numa {
+		o.procs = numaNodes()
+	}
+	if len(o.agents) > 0 && !isChild {
+		if o.procs > 1 {
+			fmt.Fprintln(os.Stderr, "--agents can't be combined with --procs and --numa")
+			return exitUsage
+		}
+		if o.agentToken == "" {
+			fmt.Fprintln(os.Stderr, "missing --agent-token of the agents")
+			return exitUsage
+		}
+		if o.configFile != "" {
+			fmt.Fprintln(os.Stderr, "--agents can't be combined with --config, the agents get the flags only")
+			return exitUsage
+		}
+		// the sequences are interleaved like the ones of the processes
+		o.procs = len(o.agents)
+	}
+
+	opts := benchmark.Options{Iter: o.iter, Duration: o.duration, Threads: o.threads, Rate: o.rate, MaxErrorRate: o.maxErrRate, Warmup: warmupIter, WarmupDuration: warmupDuration, Arrival: o.arrival, ThinkTime: thinkTime}
+
+	// Interleave the sequences of the processes, the parent executes the once benchmarks.
+	switch {
+	case isChild:
+		benchmark.SetSeq(o.seqStart+int64(procIndex), int64(procTotal+1))
+	case o.procs > 1 || len(o.agents) > 0:
+		benchmark.SetSeq(o.seqStart+int64(o.procs), int64(o.procs+1))
+	default:
+		benchmark.SetSeq(o.seqStart, 1)
+	}
+
+	// Only execute the statements from stdin, instead of any benchmarks.
+	if o.stdin {
+		streamer := bencher
+		if o.readOnly {
+			streamer = readOnlyBencher{bencher}
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		took, executed, failed := benchmark.Stream(ctx, streamer, os.Stdin, o.threads)
+		if executed == 0 {
+			fmt.Fprintln(out, "no statements on stdin")
+			return exitOK
+		}
+		fmt.Fprintf(out, "(stream) stdin:\t%v\t%v\tns/op\t%v\tstatements", took, took.Nanoseconds()/executed, executed)
+		if failed > 0 {
+			fmt.Fprintf(out, "\t%v failed", failed)
+		}
+		fmt.Fprintln(out)
+		return exitOK
+	}
+
+	// Use built-in benchmarks.
+	benchmarks := bencher.Benchmarks()
+
+	// The template functions of the config file, they may be used by a script, too.
+	if err := defineFuncs(o.suiteFuncs); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	// If a script was specified, overwrite built-in benchmarks.
+	if o.scriptname != "" {
+		dat, err := ioutil.ReadFile(o.scriptname)
+		if err != nil {
+			log.Fatalf("failed to read file: %v", err)
+		}
+		buf := bytes.NewBuffer(dat)
+		benchmarks, err = benchmark.ParseScript(buf)
+		if err != nil {
+			log.Fatalf("failed to parse script: %v\n", err)
+		}
+	} else if len(o.suite) > 0 {
+		// the benchmarks of the config file, when no script overrides them
+		benchmarks = config.ToBenchmarks(o.suite)
+	}
+
+	if len(benchmarks) == 0 {
+		log.Fatal("no built-in benchmarks for this database available yet, use your own script")
+	}
+
+	total := len(benchmarks)
+	benchmarks, err = benchmark.Select(benchmarks, benchmark.Filter{Run: o.runBench, Skip: o.skip, Tags: o.tags})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	if len(benchmarks) == 0 {
+		fmt.Fprintln(os.Stderr, "no benchmarks match --run, --skip and --tags, list them with 'dbbench describe'")
+		return exitUsage
+	}
+
+	if o.readOnly {
+		skipMutating(benchmarks)
+	}
+	if o.freshConns {
+		for i := range benchmarks {
+			benchmarks[i].Fresh = true
+		}
+	}
+	if o.dryRun > 0 {
+		return dryRun(benchmarks, o.dryRun)
+	}
+
+	if o.arrival != "" && o.rate == 0 {
+		rated := false
+		for _, b := range benchmarks {
+			rated = rated || b.Rate > 0
+		}
+		if !rated {
+			fmt.Fprintln(os.Stderr, "--arrival needs the request rate of --rate")
+			return exitUsage
+		}
+	}
+
+	if err := o.guard(benchmarks); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	handlePause()
+
+	if isChild {
+		// the rates of the benchmarks are shared by the processes, like --rate
+		for i := range benchmarks {
+			benchmarks[i].Rate /= float64(procTotal)
+		}
+		serveParent(bencher, benchmarks, procOptions(opts, procIndex, procTotal))
+		return exitOK
+	}
+
+	if len(benchmarks) < total {
+		names := make([]string, 0, len(benchmarks))
+		for _, b := range benchmarks {
+			names = append(names, b.Name)
+		}
+		fmt.Fprintf(out, "selected %v of %v benchmarks: %v\n", len(benchmarks), total, strings.Join(names, ", "))
+	}
+
+	var (
+		children  []*proc
+		clockSkew time.Duration // of the agents
+	)
+	if o.procs > 1 || len(o.agents) > 0 {
+		if o.clientStat {
+			log.Println("client stats are not available with several processes")
+			o.clientStat = false
+		}
+		if o.progress {
+			log.Println("the progress is not available with several processes")
+			o.progress = false
+		}
+		if o.poolStats > 0 {
+			log.Println("the pool stats are not available with several processes")
+			o.poolStats = 0
+		}
+		if o.fairness {
+			log.Println("the fairness report is not available with several processes")
+			o.fairness = false
+		}
+		if o.traceFile != "" {
+			log.Println("the trace file is not available with several processes")
+			o.traceFile = ""
+		}
+		if o.concurrent {
+			log.Println("the concurrency report is not available with several processes")
+			o.concurrent = false
+		}
+		if o.maxP99 > 0 {
+			log.Println("the throughput search is not available with several processes")
+			o.maxP99 = 0
+		}
+		if ramp.Steps != nil {
+			log.Println("the ramp is not available with several processes")
+			ramp.Steps = nil
+		}
+		if o.neighbor != "" {
+			log.Println("the noisy neighbor is not available with several processes")
+			o.neighbor = ""
+		}
+		if o.longTx > 0 {
+			log.Println("the long transaction is not available with several processes")
+			o.longTx = 0
+		}
+		if o.backup != "" {
+			log.Println("the backup is not available with several processes")
+			o.backup = ""
+		}
+		if o.maxErrRate > 0 {
+			log.Println("the error rate is not available with several processes")
+			o.maxErrRate, opts.MaxErrorRate = 0, 0
+		}
+		if o.duration > 0 {
+			log.Println("the duration is not available with several processes")
+			o.duration, opts.Duration = 0, 0
+		}
+		if o.warmup != "" {
+			log.Println("the warmup is not available with several processes")
+			o.warmup, opts.Warmup, opts.WarmupDuration = "", 0, 0
+		}
+		if o.control != "" {
+			log.Println("the control API is not available with several processes")
+			o.control = ""
+		}
+		if o.metricsAddr != "" {
+			log.Println("the metrics are not available with several processes")
+			o.metricsAddr = ""
+		}
+		if o.wire {
+			log.Println("the wire latency is not available with several processes")
+			o.wire = false
+			benchmark.SetWire(false)
+		}
+		if len(o.agents) > 0 {
+			if children, err = connectAgents(o.agents, o.agentToken, shared); err != nil {
+				log.Println(err)
+				return exitConnection
+			}
+		} else {
+			children = startProcs(o.procs, o.numa)
+		}
+		defer stopProcs(children)
+		awaitProcs(children)
+		for _, c := range children {
+			if c.skew > clockSkew {
+				clockSkew = c.skew
+			}
+		}
+	}
+
+	if o.maxP99 > 0 && o.rate > 0 {
+		log.Println("the rate is ignored by the throughput search, which sets its own rates")
+		o.rate, opts.Rate = 0, 0
+	}
+	if ramp.Steps != nil && o.rate > 0 {
+		log.Println("the rate is ignored by the ramp, which sets its own load")
+		o.rate, opts.Rate = 0, 0
+	}
+	// the throughput search and the ramp run the loop benchmarks in steps
+	stepped := o.maxP99 > 0 || ramp.Steps != nil
+
+	if o.control != "" {
+		if err := serveControl(o.control); err != nil {
+			log.Printf("failed to serve control API: %v\n", err)
+			return exitUsage
+		}
+		opts.Live = true
+	}
+
+	if o.metricsAddr != "" {
+		if err := serveMetrics(o.metricsAddr); err != nil {
+			log.Printf("failed to serve metrics: %v\n", err)
+			return exitUsage
+		}
+	}
+
+	// the paired runs continue the iterations after the benchmark, which are unknown with a duration
+	if o.duration > 0 {
+		if o.neighbor != "" {
+			log.Println("the noisy neighbor is not available with --duration")
+			o.neighbor = ""
+		}
+		if o.longTx > 0 {
+			log.Println("the long transaction is not available with --duration")
+			o.longTx = 0
+		}
+		if o.backup != "" {
+			log.Println("the backup is not available with --duration")
+			o.backup = ""
+		}
+	}
+
+	var neighbor *benchmark.Neighbor
+	if o.neighbor != "" {
+		nb, err := o.neighborBencher()
+		if err != nil {
+			log.Printf("failed to connect the neighbor: %v\n", err)
+			return exitConnection
+		}
+		if !o.nosetup {
+			nb.Setup()
+		}
+		if !o.noclean {
+			defer nb.Cleanup()
+		}
+
+		workload, err := benchmark.Select(nb.Benchmarks(), benchmark.Filter{Run: o.neighborRun})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+		if o.readOnly {
+			skipMutating(workload)
+		}
+		if neighbor, err = benchmark.NewNeighbor(nb, workload, o.neighborThreads); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+	}
+
+	var heartbeat *benchmark.Heartbeat
+	if o.heartbeat > 0 {
+		exec, release := heartbeatExec(bencher)
+		heartbeat = benchmark.StartHeartbeat(exec, o.hbStmt, o.heartbeat)
+		defer release()
+		defer heartbeat.Stop()
+	}
+
+	var serverStats *benchmark.ServerStats
+	if o.serverStat != "" {
+		if serverStats, err = benchmark.StartServerStats(serverStatsCommand(o.serverStat)); err != nil {
+			log.Printf("failed to sample server stats: %v\n", err)
+			return exitFailure
+		}
+		defer serverStats.Stop()
+	}
+
+	var poolStats *benchmark.PoolStats
+	if o.poolStats > 0 {
+		if pooler, ok := bencher.(interface{ DB() *sql.DB }); ok {
+			poolStats = benchmark.StartPoolStats(pooler.DB().Stats, o.poolStats)
+			defer poolStats.Stop()
+		} else {
+			log.Printf("the pool stats are not available for %v\n", o.db)
+		}
+	}
+
+	if o.cgroup != "" {
+		if _, err := benchmark.ReadCgroup(o.cgroup); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+	}
+
+	// the round trip without any work of the server, excluded from the total duration
+	var floor time.Duration
+	if !o.nofloor {
+		if floor, err = measureFloor(context.Background(), bencher, o.db); err != nil {
+			log.Printf("failed to measure the floor: %v\n", err)
+		} else if floor > 0 {
+			fmt.Fprintf(out, "floor:\t%v per round trip (%v)\n", floor, floorStmt(o.db))
+		}
+	}
+	// also the random one, to repeat the statements of the run
+	fmt.Fprintf(out, "seed:\t%v\n", benchmark.Seed())
+
+	startTotal := time.Now()
+	run := &results.Run{Database: o.db, Version: version, Start: startTotal, Iter: o.iter, Duration: o.duration, Rate: o.rate, Arrival: o.arrival, ThinkTime: o.thinkTime, FreshConns: o.freshConns, QueryTimeout: o.stmtTimeout, Network: o.network, Threads: o.threads, Seed: benchmark.Seed(), Agents: len(o.agents), ClockSkew: clockSkew, Scale: o.scale, Workload: o.workload, SUTVersion: o.sutVersion, Settings: o.settings, Floor: floor}
+	if v, ok := bencher.(versioner); ok {
+		if run.ServerVersion, err = v.ServerVersion(); err != nil {
+			log.Printf("failed to get the server version: %v\n", err)
+		}
+	}
+	if hasPool(o.db) {
+		run.ConnPool = connPool(o.pool())
+		if poolStats != nil {
+			fmt.Fprintf(out, "connection pool: %v\n", run.ConnPool)
+		}
+	}
+	if hasTLS(o.db) {
+		run.TLS = o.tls.Mode
+	}
+	if baseline != nil {
+		printMismatches(out, results.Mismatches(baseline, run))
+	}
+
+	// SIGINT (ctrl-c) cancels the running statements, the results until then are still reported
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var trace *benchmark.Trace
+	if o.traceFile != "" {
+		f, err := os.Create(o.traceFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		defer f.Close()
+		trace = benchmark.StartTrace(f)
+	}
+
+	var fairness *benchmark.Fairness
+	if o.fairness {
+		fairness = benchmark.StartFairness(time.Second)
+	}
+
+	violated, aborted := false, false
+	nsPerOps := map[string]int64{} // of the finished benchmarks, for the overhead of paired benchmarks
+
+	// the parallel benchmarks run concurrently with the following ones, their results are added when they finished
+	scheduler := &benchmark.Scheduler{}
+	addParallel := func(finished []benchmark.Finished) {
+		for _, f := range finished {
+			result := parallelResult(out, f, slos.For(f.Benchmark.Name), o.percentiles)
+			if result.SLOViolated() {
+				violated = true
+				fmt.Fprintf(out, "%v:\tSLO violated, %v\n", result.Name, result.SLOMessage())
+			}
+			run.Benchmarks = append(run.Benchmarks, result)
+		}
+	}
+	for i, b := range benchmarks {
+		if aborted || ctx.Err() != nil {
+			break
+		}
+		if b.Skip != "" {
+			fmt.Fprintf(out, "%v:\tskipped, %v\n", b.Name, b.Skip)
+			run.Benchmarks = append(run.Benchmarks, results.Benchmark{Name: b.Name, Type: b.Type.String(), Skipped: b.Skip})
+			continue
+		}
+
+		// with several processes, the throughput search or the ramp, the parallel benchmarks run one after another
+		if b.Parallel && children == nil && !stepped {
+			scheduler.Start(ctx, bencher, b, opts)
+			fmt.Fprintf(out, "%v:\tstarted in parallel\n", b.Name)
+			continue
+		}
+
+		if heartbeat != nil {
+			heartbeat.Reset()
+		}
+		if serverStats != nil {
+			serverStats.Reset()
+		}
+		if poolStats != nil {
+			poolStats.Reset()
+		}
+		var cgroupStart benchmark.CgroupStats
+		if o.cgroup != "" {
+			cgroupStart, _ = benchmark.ReadCgroup(o.cgroup)
+		}
+
+		benchmark.TakeLoad()
+		benchmark.TakeWire()
+
+		// paired runs, which aren't part of the benchmark
+		paired := b.Type == benchmark.TypeLoop && !stepped && children == nil
+		var bloatStart int64
+		if o.longTx > 0 && paired {
+			bloatStart = bloat(bencher.(txHolder))
+		}
+
+		var monitor *benchmark.Monitor
+		if o.clientStat {
+			monitor = benchmark.StartMonitor(100*time.Millisecond, o.threads)
+		}
+		var (
+			progress *benchmark.Progress
+			timeline []results.TimelineSample
+		)
+		if (o.progress || o.report != "") && b.Type == benchmark.TypeLoop && children == nil {
+			printer := printProgress(out, b.Name, o.iter, o.duration)
+			if stepped {
+				// the steps of the search and the ramp have neither the iterations nor the duration
+				printer = printProgress(out, b.Name, 0, 0)
+			}
+			progress = benchmark.StartProgress(time.Second, func(s benchmark.ProgressSample) {
+				if o.progress {
+					printer(s)
+				}
+				timeline = append(timeline, results.TimelineSample{Offset: s.Elapsed, Throughput: s.Throughput, P50: s.P50, P95: s.P95, P99: s.P99})
+			})
+		}
+
+		// run the particular benchmark
+		var (
+			took    time.Duration
+			search  *benchmark.SearchResult
+			rampRes *benchmark.RampResult
+			latency benchmark.Result
+		)
+		start := time.Now()
+		switch {
+		case o.maxP99 > 0 && b.Type == benchmark.TypeLoop:
+			res := benchmark.Search(ctx, bencher, b, benchmark.SearchOptions{Threads: o.threads, MaxP99: o.maxP99, Duration: o.searchStep, Start: o.searchStart})
+			took = time.Since(start)
+			search = &res
+		case ramp.Steps != nil && b.Type == benchmark.TypeLoop:
+			res := benchmark.Ramp(ctx, bencher, b, ramp)
+			took = time.Since(start)
+			rampRes = &res
+		case children != nil && b.Type == benchmark.TypeLoop:
+			latency = runProcs(ctx, children, i)
+			took = time.Since(start)
+		default:
+			latency = benchmark.Run(ctx, bencher, b, opts)
+			took = latency.Duration
+		}
+		if progress != nil {
+			progress.Stop()
+		}
+
+		// execution in ns for mode once
+		nsPerOp := took.Nanoseconds()
+
+		// interrupted by ctrl-c, report the statements executed until then and skip the paired runs
+		interrupted := ctx.Err() != nil
+		if interrupted {
+			paired = false
+		}
+
+		switch {
+		case search != nil:
+			// execution in ns/op at the max. sustainable throughput
+			nsPerOp = 0
+			if search.Sustainable.Throughput > 0 {
+				nsPerOp = int64(float64(time.Second) / search.Sustainable.Throughput)
+			}
+			printSearch(out, b.Name, search, o.maxP99)
+		case rampRes != nil:
+			// execution in ns/op at the saturation point
+			nsPerOp = 0
+			if peak, ok := rampPeak(rampRes); ok && peak.Throughput > 0 {
+				nsPerOp = int64(float64(time.Second) / peak.Throughput)
+			}
+			printRamp(out, b.Name, rampRes)
+		case b.Type == benchmark.TypeLoop && (o.duration > 0 || interrupted || latency.Ops < o.iter):
+			// execution in ns/op of the executed statements, e.g. within the duration or until
+			// --max-error-rate stopped the benchmark
+			if latency.Ops > 0 {
+				nsPerOp /= int64(latency.Ops)
+			}
+		case b.Type == benchmark.TypeLoop:
+			// execution in ns/op for mode loop
+			nsPerOp /= int64(o.iter)
+		}
+
+		fmt.Fprintf(out, "%v:\t%v\t%v\tns/op\n", b.Name, took, nsPerOp)
+		if interrupted {
+			fmt.Fprintf(out, "%v:\tinterrupted after %v operations\n", b.Name, latency.Ops)
+		}
+		if o.duration > 0 && b.Type == benchmark.TypeLoop {
+			fmt.Fprintf(out, "%v:\t%v operations, %.0f ops/s\n", b.Name, latency.Ops, latency.Throughput())
+		}
+		if o.rate > 0 && b.Type == benchmark.TypeLoop && latency.Ops > 0 {
+			fmt.Fprintf(out, "%v:\t%.0f ops/s requested, %.0f ops/s achieved\n", b.Name, o.rate, latency.Throughput())
+		}
+		var clientStats *benchmark.ClientStats
+		if monitor != nil {
+			clientStats = monitor.Stop()
+			printClientStats(out, b.Name, clientStats)
+		}
+		if heartbeat != nil {
+			printHeartbeat(out, b.Name, heartbeat.Reset())
+		}
+		result := results.Benchmark{Name: b.Name, Type: b.Type.String(), Duration: took, NsPerOp: nsPerOp, SLO: slos.For(b.Name), Interrupted: interrupted, Seed: b.Seed}
+		if result.Seed == 0 {
+			result.Seed = benchmark.Seed() // also of the search and the child processes
+		}
+		result.Ops = latency.Ops
+		result.Timeline = timeline
+		if clientStats != nil {
+			result.Client = &results.ClientStats{
+				GCPauses: clientStats.GCPauses, GCPauseTotal: clientStats.GCPauseTotal, GCPauseMax: clientStats.GCPauseMax,
+				CPU: clientStats.CPU, CPUTime: clientStats.CPUTime, HeapMax: clientStats.HeapMax, Allocated: clientStats.Allocated,
+				Goroutines: clientStats.Goroutines, Bottleneck: clientStats.Bottleneck(),
+			}
+		}
+		if base, ok := nsPerOps[b.Baseline]; ok && base > 0 {
+			result.Baseline = b.Baseline
+			result.Overhead = float64(nsPerOp-base) / float64(base)
+			fmt.Fprintf(out, "%v:\toverhead %.1f%% compared to %v\n", b.Name, result.Overhead*100, b.Baseline)
+		}
+		nsPerOps[b.Name] = nsPerOp
+		if floor > 0 {
+			result.Floors = float64(nsPerOp) / float64(floor)
+		}
+		if latency.Errors > 0 {
+			result.Errors = latency.Errors
+			result.ErrorRate = latency.ErrorRate()
+			fmt.Fprintf(out, "%v:\t%v of %v statements failed (%.2f%%)", b.Name, latency.Errors, latency.Ops, result.ErrorRate*100)
+			if n := latency.ErrorKinds[benchmark.ErrKindTimeout]; n > 0 {
+				fmt.Fprintf(out, ", %v timed out", n)
+			}
+			fmt.Fprintln(out)
+			result.Goodput = latency.Goodput()
+			result.ErrorKinds = latency.ErrorKinds
+			for _, o := range latency.Outages {
+				result.Outages = append(result.Outages, results.Outage{Start: o.Start, End: o.End, Errors: o.Errors})
+			}
+			printOutages(out, b.Name, latency)
+		}
+		if latency.Ops > 0 {
+			result.Latency = &results.LatencyStats{Min: latency.Min, Mean: latency.Mean, P50: latency.P50, P95: latency.P95, P99: latency.P99, Max: latency.Max}
+			if o.percentiles {
+				printLatency(out, b.Name, result.Latency)
+			}
+		}
+		for _, m := range latency.Mix {
+			stats := mixStats(m)
+			result.Mix = append(result.Mix, stats)
+			printMix(out, b.Name, stats, latency.Ops)
+		}
+		if load := benchmark.TakeLoad(); o.concurrent && load.Ops > 0 {
+			result.Concurrency = load.Concurrency(took)
+			result.MeanLatency = load.Mean()
+			result.MinLatency = load.Min
+			printConcurrency(out, b.Name, load, took, o.threads)
+		}
+		if wire := benchmark.TakeWire(); o.wire && wire.RoundTrips > 0 {
+			result.Wire = &results.WireStats{RoundTrips: wire.RoundTrips, FirstByte: wire.MeanFirstByte(), Transfer: wire.MeanTransfer()}
+			printWire(out, b.Name, result.Wire)
+		}
+		if search != nil {
+			result.Throughput = search.Sustainable.Throughput
+			result.P99 = search.Sustainable.P99
+		}
+		if rampRes != nil {
+			if peak, ok := rampPeak(rampRes); ok {
+				result.Throughput, result.P99 = peak.Throughput, peak.P99
+			}
+			result.Ramp = rampSteps(rampRes)
+		}
+		if serverStats != nil {
+			result.Server = serverSamples(start, serverStats.Reset())
+			printServerStats(out, b.Name, result.Server)
+		}
+		if poolStats != nil {
+			result.Pool = poolSamples(start, poolStats.Reset())
+			printPoolStats(out, b.Name, result.Pool)
+		}
+		if o.cgroup != "" {
+			if stats, err := benchmark.ReadCgroup(o.cgroup); err != nil {
+				log.Printf("failed to read cgroup: %v\n", err)
+			} else {
+				d := stats.Sub(cgroupStart)
+				result.Cgroup = &results.CgroupStats{Periods: d.Periods, Throttled: d.Throttled, ThrottledTime: d.ThrottledTime, Memory: d.Memory, MemoryLimit: d.MemoryLimit}
+				printCgroup(out, b.Name, result.Cgroup)
+			}
+		}
+		// the iterations of the paired runs continue after the benchmark and its warmup
+		offset := latency.Warmup + o.iter
+		if o.longTx > 0 && paired {
+			alone := bloat(bencher.(txHolder)) - bloatStart
+			if stats, err := runLongTx(ctx, bencher, b, o.longTx, benchmark.Options{Iter: o.iter, Threads: o.threads, Rate: o.rate, Offset: offset}); err != nil {
+				log.Printf("failed to hold transaction: %v\n", err)
+			} else {
+				stats.BloatAlone = alone
+				offset += stats.Runs * o.iter
+				result.LongTx = stats
+				printLongTx(out, b.Name, nsPerOp, stats)
+			}
+		}
+		if o.backup != "" && paired {
+			if stats, err := runBackup(ctx, bencher, b, o.backup, benchmark.Options{Iter: o.iter, Threads: o.threads, Rate: o.rate, Offset: offset}); err != nil {
+				log.Printf("failed to start backup: %v\n", err)
+			} else {
+				offset += stats.Runs * o.iter
+				result.Backup = stats
+				printBackup(out, b.Name, nsPerOp, latency.P99, stats)
+			}
+		}
+		if neighbor != nil && paired {
+			// the neighbor alone for the same duration, then both simultaneously
+			neighbor.Start()
+			time.Sleep(took)
+			alone := neighbor.Stop()
+
+			neighbor.Start()
+			shared := benchmark.Run(ctx, bencher, b, benchmark.Options{Iter: o.iter, Threads: o.threads, Rate: o.rate, Offset: offset}).Duration
+			result.Neighbor = &results.NeighborStats{NsPerOp: shared.Nanoseconds() / int64(o.iter), Alone: alone, Shared: neighbor.Stop()}
+			printNeighbor(out, b.Name, nsPerOp, result.Neighbor)
+		}
+		if result.SLOViolated() {
+			violated = true
+			fmt.Fprintf(out, "%v:\tSLO violated, %v\n", b.Name, result.SLOMessage())
+		}
+		run.Benchmarks = append(run.Benchmarks, result)
+		addParallel(scheduler.Take())
+		if o.maxErrRate > 0 && result.ErrorRate > o.maxErrRate {
+			aborted = true
+			fmt.Fprintf(out, "%v:\terror rate exceeds %.2f%%, aborting\n", b.Name, o.maxErrRate*100)
+			continue
+		}
+
+		// Don't sleep after the last benchmark
+		if i != len(benchmarks)-1 {
+			time.Sleep(o.sleep)
+		}
+	}
+	if n := scheduler.Running(); n > 0 {
+		fmt.Fprintf(out, "waiting for %v parallel benchmarks\n", n)
+	}
+	addParallel(scheduler.Wait())
+	printTotal(out, startTotal)
+	if trace != nil {
+		if err := trace.Stop(); err != nil {
+			log.Printf("failed to write trace file: %v\n", err)
+		}
+	}
+	if fairness != nil {
+		run.Fairness = fairnessStats(time.Second, fairness.Stop())
+		printFairness(out, run.Fairness)
+	}
+	if o.hints {
+		printHints(out, results.Hints(run))
+	}
+
+	var regressions []results.Regression
+	if baseline != nil {
+		for _, d := range results.Deltas(baseline, run) {
+			printDelta(out, d)
+		}
+		regressions = results.Regressions(baseline, run, o.maxRegress/100)
+		for _, r := range regressions {
+			fmt.Fprintf(out, "%v:\tregression, %v\n", r.Name, r)
+		}
+	}
+
+	if o.github {
+		if err := results.WriteGitHubAnnotations(os.Stdout, run, regressions); err != nil {
+			log.Printf("failed to write annotations: %v\n", err)
+		}
+	}
+
+	if o.notify != "" {
+		if err := results.Notify(o.notify, run, regressions); err != nil {
+			log.Printf("failed to notify: %v\n", err)
+		}
+	}
+
+	if o.save != "" {
+		if err := run.WriteSealedFile(o.save, signKey, encryptKey); err != nil {
+			log.Printf("failed to save results: %v\n", err)
+			return exitFailure
+		}
+	}
+
+	if err := writeResults(os.Stdout, o.format, run); err != nil {
+		log.Printf("failed to write results: %v\n", err)
+		return exitFailure
+	}
+
+	if o.junit != "" {
+		if err := writeJUnit(o.junit, run); err != nil {
+			log.Printf("failed to write junit xml: %v\n", err)
+			return exitFailure
+		}
+	}
+
+	if o.report != "" {
+		if err := writeReport(o.report, run); err != nil {
+			log.Printf("failed to write report: %v\n", err)
+			return exitFailure
+		}
+	}
+
+	if o.publish != "" {
+		url, err := results.Publish(o.publish, run)
+		if err != nil {
+			log.Printf("failed to publish results: %v\n", err)
+			return exitFailure
+		}
+		fmt.Fprintf(out, "published results: %v\n", url)
+	}
+
+	if o.warehouse != "" {
+		id, err := storeWarehouse(o.warehouse, run)
+		if err != nil {
+			log.Printf("failed to store results in the warehouse: %v\n", err)
+			return exitFailure
+		}
+		fmt.Fprintf(out, "stored results in the warehouse: run %v\n", id)
+	}
+
+	if ctx.Err() != nil {
+		return exitInterrupt
+	}
+	if aborted {
+		return exitErrors
+	}
+	if violated {
+		return exitSLO
+	}
+	if len(regressions) > 0 {
+		return exitRegression
+	}
+	return exitOK
+}
+
+// validFormat returns whether format is one of results.Formats.
+func validFormat(format string) bool {
+	for _, f := range results.Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// writeResults writes the run to w in the given format, text was printed while running already.
+func writeResults(w io.Writer, format string, run *results.Run) error {
+	switch format {
+	case "json":
+		return results.WriteJSON(w, run)
+	case "csv":
+		return results.WriteCSV(w, run)
+	}
+	return nil
+}
+
+// writeJUnit writes the results as JUnit XML to the file at path.
+func writeJUnit(path string, run *results.Run) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := results.WriteJUnit(f, run); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeReport writes the HTML report of the run to the file.
+func writeReport(path string, run *results.Run) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := results.WriteHTML(f, run); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// printClientStats prints the GC pauses and the resource usage of the client, whether it limited
+// the throughput and the slow intervals with their probable cause, to distinguish client-induced
+// latency spikes from server-induced ones.
+func printClientStats(w io.Writer, name string, stats *benchmark.ClientStats) {
+	fmt.Fprintf(w, "%v:\tclient: %v GC pauses (total %v, max %v), CPU %.0f%% (%v), heap max %.1f MiB, %.1f MiB allocated, max %v goroutines\n",
+		name, stats.GCPauses, stats.GCPauseTotal, stats.GCPauseMax, stats.CPU*100, stats.CPUTime.Round(time.Millisecond),
+		float64(stats.HeapMax)/(1<<20), float64(stats.Allocated)/(1<<20), stats.Goroutines)
+	if b := stats.Bottleneck(); b != "" {
+		fmt.Fprintf(w, "%v:\tWARNING: %v, it may limit the throughput, run dbbench on more cores or --agents\n", name, b)
+	}
+	for _, i := range stats.Intervals {
+		if i.Tuning != nil {
+			fmt.Fprintf(w, "%v:\ttuned at %v: %v threads, %.0f ops/s (0 -> unlimited)\n",
+				name, i.End.Round(time.Millisecond), i.Tuning.Threads, i.Tuning.Rate)
+		}
+	}
+	for _, i := range stats.SlowIntervals(2) {
+		fmt.Fprintf(w, "%v:\tslow interval %v-%v: %v latency, cause: %v\n",
+			name, i.Start.Round(time.Millisecond), i.End.Round(time.Millisecond), i.Latency, i.Cause())
+	}
+}
+
+// printSearch prints the steps and the max. sustainable throughput of the search.
+func printSearch(w io.Writer, name string, search *benchmark.SearchResult, maxP99 time.Duration) {
+	for _, s := range search.Steps {
+		fmt.Fprintf(w, "%v:\t%.0f ops/s requested, %.0f ops/s executed, p99 %v", name, s.Rate, s.Throughput, s.P99)
+		if s.Errors > 0 {
+			fmt.Fprintf(w, ", %v of %v failed", s.Errors, s.Ops)
+		}
+		fmt.Fprintln(w)
+	}
+	if search.Sustainable.Rate == 0 {
+		fmt.Fprintf(w, "%v:\tno sustainable throughput with p99 below %v\n", name, maxP99)
+		return
+	}
+	fmt.Fprintf(w, "%v:\tmax. sustainable throughput %.0f ops/s, p99 %v below %v\n", name, search.Sustainable.Throughput, search.Sustainable.P99, maxP99)
+}
+
+// printConcurrency prints the mean number of statements in flight and whether more threads would
+// probably help: When the threads are often idle, the client is the bottleneck. When the mean latency
+// is far above the min. one, the statements queue on the server and more threads only add latency.
+func printConcurrency(w io.Writer, name string, load benchmark.LoadStats, took time.Duration, threads int) {
+	var (
+		concurrency = load.Concurrency(took)
+		queueing    = load.Queueing()
+		hint        string
+	)
+	switch {
+	case concurrency < 0.8*float64(threads):
+		hint = "threads are idle, the client is the bottleneck"
+	case queueing > 2:
+		hint = "statements are queueing, more threads add latency"
+	default:
+		hint = "more threads may increase the throughput"
+	}
+	fmt.Fprintf(w, "%v:\tconcurrency %.1f of %v threads, mean latency %v, min %v (%.1fx): %v\n",
+		name, concurrency, threads, load.Mean(), load.Min, queueing, hint)
+}
+
+// printOutages prints the goodput, the throughput of the successful statements, and the periods
+// with failed statements, e.g. to measure the availability during a failover.
+func printOutages(w io.Writer, name string, r benchmark.Result) {
+	fmt.Fprintf(w, "%v:\tgoodput %.0f of %.0f ops/s, failing during %v of %v\n",
+		name, r.Goodput(), r.Throughput(), r.Downtime().Round(time.Millisecond), r.Duration.Round(time.Millisecond))
+	for _, o := range r.Outages {
+		fmt.Fprintf(w, "%v:\terrors %v-%v: %v failed\n", name, o.Start, o.End, o.Errors)
+	}
+}
+
+// parallelResult prints and returns the result of a finished parallel benchmark. The ns/op of the loop
+// benchmarks are of the executed operations, which may be fewer than the iterations, e.g. with --duration.
+func parallelResult(w io.Writer, f benchmark.Finished, slo time.Duration, percentiles bool) results.Benchmark {
+	b, r := f.Benchmark, f.Result
+	nsPerOp := r.Duration.Nanoseconds()
+	if b.Type == benchmark.TypeLoop && r.Ops > 0 {
+		nsPerOp /= int64(r.Ops)
+	}
+	fmt.Fprintf(w, "%v:\t%v\t%v\tns/op\t(parallel)\n", b.Name, r.Duration, nsPerOp)
+
+	result := results.Benchmark{Name: b.Name, Type: b.Type.String(), Duration: r.Duration, NsPerOp: nsPerOp, Ops: r.Ops, SLO: slo, Seed: r.Seed}
+	if r.Errors > 0 {
+		result.Errors = r.Errors
+		result.ErrorRate = r.ErrorRate()
+		fmt.Fprintf(w, "%v:\t%v of %v statements failed (%.2f%%)\n", b.Name, r.Errors, r.Ops, result.ErrorRate*100)
+	}
+	if r.Ops > 0 {
+		result.Latency = &results.LatencyStats{Min: r.Min, Mean: r.Mean, P50: r.P50, P95: r.P95, P99: r.P99, Max: r.Max}
+		if percentiles {
+			printLatency(w, b.Name, result.Latency)
+		}
+	}
+	for _, m := range r.Mix {
+		stats := mixStats(m)
+		result.Mix = append(result.Mix, stats)
+		printMix(w, b.Name, stats, r.Ops)
+	}
+	return result
+}
+
+// printWire prints the mean latency of the round trips of the wire protocol, see --wire.
+func printWire(w io.Writer, name string, wire *results.WireStats) {
+	share := 0.0
+	if total := wire.FirstByte + wire.Transfer; total > 0 {
+		share = float64(wire.FirstByte) / float64(total) * 100
+	}
+	fmt.Fprintf(w, "%v:\twire %v round trips, first byte %v (%.0f%%), transfer %v\n", name, wire.RoundTrips, wire.FirstByte, share, wire.Transfer)
+}
+
+// printLatency prints the latency distribution of the statements.
+func printLatency(w io.Writer, name string, l *results.LatencyStats) {
+	fmt.Fprintf(w, "%v:\tlatency min %v, mean %v, p50 %v, p95 %v, p99 %v, max %v\n", name, l.Min, l.Mean, l.P50, l.P95, l.P99, l.Max)
+}
+
+// mixStats returns the result of a statement of a mixed workload.
+func mixStats(m benchmark.MixResult) results.MixStats {
+	stats := results.MixStats{Name: m.Name, Share: m.Share, Ops: m.Ops, Errors: m.Errors, Throughput: m.Throughput(), ErrorKinds: m.ErrorKinds}
+	if m.Ops > 0 {
+		stats.Latency = &results.LatencyStats{Min: m.Min, Mean: m.Mean, P50: m.P50, P95: m.P95, P99: m.P99, Max: m.Max}
+	}
+	for i, n := range m.Buckets {
+		stats.Histogram = append(stats.Histogram, results.HistogramBucket{Le: benchmark.LatencyBuckets[i], Count: n})
+	}
+	return stats
+}
+
+// printMix prints the operations and latencies of a statement of a mixed workload, see \benchmark mix.
+func printMix(w io.Writer, name string, m results.MixStats, ops int) {
+	share := 0.0
+	if ops > 0 {
+		share = float64(m.Ops) / float64(ops) * 100
+	}
+	line := fmt.Sprintf("%v:\t%v: %v operations (%.1f%%, weight %.1f%%), %.0f ops/s", name, m.Name, m.Ops, share, m.Share*100, m.Throughput)
+	if m.Latency != nil {
+		line += fmt.Sprintf(", p50 %v, p99 %v", m.Latency.P50, m.Latency.P99)
+	}
+	if m.Errors > 0 {
+		line += fmt.Sprintf(", %v failed", m.Errors)
+		kinds := make([]string, 0, len(m.ErrorKinds))
+		for kind, n := range m.ErrorKinds {
+			kinds = append(kinds, fmt.Sprintf("%v %v", n, kind))
+		}
+		sort.Strings(kinds)
+		if len(kinds) > 0 {
+			line += " (" + strings.Join(kinds, ", ") + ")"
+		}
+	}
+	fmt.Fprintln(w, line)
+}
+
+// printNeighbor prints the slowdown of the benchmark and the neighbor, when running simultaneously.
+func printNeighbor(w io.Writer, name string, nsPerOp int64, n *results.NeighborStats) {
+	slowdown := func(before, after float64) float64 {
+		if before == 0 {
+			return 0
+		}
+		return (after - before) / before * 100
+	}
+	fmt.Fprintf(w, "%v:\tneighbor: %.1f%% slower (%v -> %v per operation), neighbor %.1f%% slower (%.0f -> %.0f ops/s)\n",
+		name, slowdown(float64(nsPerOp), float64(n.NsPerOp)), time.Duration(nsPerOp), time.Duration(n.NsPerOp),
+		-slowdown(n.Alone, n.Shared), n.Alone, n.Shared)
+}
+
+func printTotal(w io.Writer, startTotal time.Time) {
+	fmt.Fprintf(w, "total: %v\n", time.Since(startTotal))
+}
+
+// printDelta prints the changes of a benchmark compared to the baseline.
+func printDelta(w io.Writer, d results.Delta) {
+	fmt.Fprintf(w, "%v:\tcompared to the baseline: %+.1f%% ns/op, %+.1f%% ops/s", d.Name, d.NsPerOp*100, d.Throughput*100)
+	if d.Latency {
+		fmt.Fprintf(w, ", p50 %+.1f%%, p95 %+.1f%%, p99 %+.1f%%", d.P50*100, d.P95*100, d.P99*100)
+	}
+	fmt.Fprintln(w)
+}
+
+// defineFuncs defines the template functions by their name and body, see benchmark.DefineFunc.
+func defineFuncs(funcs map[string]string) error {
+	var names []string
+	for name := range funcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := benchmark.DefineFunc(name, funcs[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
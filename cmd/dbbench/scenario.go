@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/sj14/dbbench/config"
+	"github.com/sj14/dbbench/results"
+	"github.com/spf13/pflag"
+)
+
+// scenarioCmd runs the variants of a scenario one after another and prints the combined results,
+// e.g. the overhead of an encrypted compared to an unencrypted instance.
+func scenarioCmd(args []string) int {
+	var (
+		flags   = pflag.NewFlagSet("scenario", pflag.ContinueOnError)
+		saveDir = flags.String("save-dir", "", "directory to save the results of the variants, e.g. for 'dbbench compare' (default: not saved)")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: dbbench scenario [flags] <scenario.yaml>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	scenario, err := config.LoadScenario(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	if scenario.Name == "" {
+		scenario.Name = strings.TrimSuffix(filepath.Base(flags.Arg(0)), filepath.Ext(flags.Arg(0)))
+	}
+
+	dir := *saveDir
+	if dir == "" {
+		if dir, err = ioutil.TempDir("", "dbbench"); err != nil {
+			log.Printf("failed to create temp dir: %v\n", err)
+			return exitFailure
+		}
+		defer os.RemoveAll(dir)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		log.Fatalf("failed to get executable: %v", err)
+	}
+
+	var (
+		names       []string
+		runs        []*results.Run
+		failed      bool
+		snapshotted bool
+	)
+	for i, v := range scenario.Variants {
+		fmt.Printf("variant %v:\n", v.Name)
+		path := filepath.Join(dir, fmt.Sprintf("%v-%v.json", scenario.Name, v.Name))
+
+		err := runHooks(v.Before)
+		if err == nil && scenario.Snapshot != nil {
+			// the first variant runs on the saved data, the following ones on the restored data
+			if i == 0 {
+				if err = runHooks(scenario.Snapshot.Create); err != nil {
+					err = fmt.Errorf("failed to create snapshot: %v", err)
+				}
+				snapshotted = err == nil
+			} else if err = runHooks(scenario.Snapshot.Restore); err != nil {
+				err = fmt.Errorf("failed to restore snapshot: %v", err)
+			}
+		}
+		if err == nil {
+			cmd := exec.Command(exe, append(append([]string{"run"}, v.Args...), "--save", path)...)
+			cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+			err = cmd.Run()
+		}
+		if hookErr := runHooks(v.After); hookErr != nil {
+			log.Printf("variant %v: %v\n", v.Name, hookErr)
+		}
+
+		run := &results.Run{}
+		if err == nil {
+			run, err = results.ReadFile(path)
+		}
+		if err != nil {
+			log.Printf("variant %v failed: %v\n", v.Name, err)
+			failed = true
+			run = &results.Run{}
+		}
+		names = append(names, v.Name)
+		runs = append(runs, run)
+
+		// the following variants can't start from the same data without the snapshot
+		if i == 0 && scenario.Snapshot != nil && !snapshotted {
+			break
+		}
+	}
+	if scenario.Snapshot != nil && snapshotted {
+		if err := runHooks(scenario.Snapshot.Drop); err != nil {
+			log.Printf("failed to drop snapshot: %v\n", err)
+		}
+	}
+
+	fmt.Printf("\nscenario %v:\n", scenario.Name)
+	write := results.WriteMatrix
+	if scenario.Sweep != nil {
+		fmt.Printf("throughput by %v:\n", scenario.Sweep.Setting)
+		write = results.WriteThroughputMatrix
+	}
+	if err := write(os.Stdout, names, runs); err != nil {
+		log.Printf("failed to write results: %v\n", err)
+		return exitFailure
+	}
+	if failed {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// runHooks executes the shell commands one after another, until one fails.
+func runHooks(commands []string) error {
+	for _, c := range commands {
+		cmd := exec.Command("sh", "-c", c)
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("hook %q failed: %v", c, err)
+		}
+	}
+	return nil
+}
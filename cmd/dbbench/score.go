@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sj14/dbbench/results"
+	"github.com/spf13/pflag"
+)
+
+// scoreCmd prints a composite score of each target, the weighted geometric mean of its benchmarks
+// normalized to the reference, with the ratios of the benchmarks.
+func scoreCmd(args []string) int {
+	var (
+		flags     = pflag.NewFlagSet("score", pflag.ContinueOnError)
+		reference = flags.String("reference", "", "saved results of the reference, which scores 1, e.g. \"ref1.json,ref2.json\" (default: the first target)")
+		weights   = flags.StringToString("weights", nil, "weights of the benchmarks in the score, by their name, unlisted benchmarks have the weight 1 and 0 excludes them, e.g. \"inserts=2,selects=0.5\"")
+		names     = flags.StringSlice("names", nil, "names of the targets in the table (default: the file name of their first result)")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: dbbench score [flags] <target.json>[,...]...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return exitUsage
+	}
+	if len(*names) > 0 && len(*names) != flags.NArg() {
+		fmt.Fprintf(os.Stderr, "%v names for %v targets\n", len(*names), flags.NArg())
+		return exitUsage
+	}
+
+	w := map[string]float64{}
+	for name, s := range *weights {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid weight of %v: %v\n", name, s)
+			return exitUsage
+		}
+		w[name] = v
+	}
+
+	var targets [][]*results.Run
+	for i, paths := range flags.Args() {
+		runs, err := readRuns(paths)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		targets = append(targets, runs)
+		if len(*names) <= i {
+			first := strings.Split(paths, ",")[0]
+			*names = append(*names, strings.TrimSuffix(filepath.Base(first), filepath.Ext(first)))
+		}
+	}
+	ref := targets[0]
+	if *reference != "" {
+		var err error
+		if ref, err = readRuns(*reference); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+	}
+
+	scores, scored, err := results.Scores(ref, targets, *names, w)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	if err := results.WriteScores(os.Stdout, scored, scores); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	return exitOK
+}
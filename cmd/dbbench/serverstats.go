@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sj14/dbbench/benchmark"
+	"github.com/sj14/dbbench/results"
+)
+
+// serverStatsCommand returns the command sampling vmstat every second on the given ssh destination,
+// or on this machine for "local".
+func serverStatsCommand(dest string) []string {
+	if dest == "local" {
+		return []string{"vmstat", "-n", "1"}
+	}
+	return []string{"ssh", "-o", "BatchMode=yes", dest, "vmstat -n 1"}
+}
+
+// serverSamples converts the samples taken during the benchmark started at start.
+func serverSamples(start time.Time, samples []benchmark.ServerSample) []results.ServerSample {
+	var result []results.ServerSample
+	for _, s := range samples {
+		result = append(result, results.ServerSample{
+			Offset:    s.Time.Sub(start).Round(time.Millisecond),
+			BlocksIn:  s.BlocksIn,
+			BlocksOut: s.BlocksOut,
+			CPU:       s.CPU,
+			IOWait:    s.IOWait,
+		})
+	}
+	return result
+}
+
+// printServerStats prints the peak disk and CPU usage of the database server during the benchmark.
+func printServerStats(w io.Writer, name string, samples []results.ServerSample) {
+	if len(samples) == 0 {
+		return
+	}
+	var peak results.ServerSample
+	for _, s := range samples {
+		if s.BlocksIn > peak.BlocksIn {
+			peak.BlocksIn = s.BlocksIn
+		}
+		if s.BlocksOut > peak.BlocksOut {
+			peak.BlocksOut = s.BlocksOut
+		}
+		if s.CPU > peak.CPU {
+			peak.CPU = s.CPU
+		}
+		if s.IOWait > peak.IOWait {
+			peak.IOWait = s.IOWait
+		}
+	}
+	fmt.Fprintf(w, "%v:\tserver: %v samples, max %v blocks/s in, %v blocks/s out, CPU %v%%, I/O wait %v%%\n",
+		name, len(samples), peak.BlocksIn, peak.BlocksOut, peak.CPU, peak.IOWait)
+}
+
+// printCgroup prints the CPU throttling and memory usage of the database container during the benchmark.
+func printCgroup(w io.Writer, name string, stats *results.CgroupStats) {
+	limit := "unlimited"
+	if stats.MemoryLimit > 0 {
+		limit = fmt.Sprintf("%.1f MiB", float64(stats.MemoryLimit)/(1<<20))
+	}
+	fmt.Fprintf(w, "%v:\tcgroup: throttled %v of %v periods (%v), memory %.1f MiB of %v\n",
+		name, stats.Throttled, stats.Periods, stats.ThrottledTime, float64(stats.Memory)/(1<<20), limit)
+}
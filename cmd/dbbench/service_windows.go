@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+// runService runs the daemon as Windows service, when started by the service control manager,
+// otherwise in the foreground until ctrl-c.
+func runService(run func(ctx context.Context) int) int {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		log.Printf("failed to detect the service control manager: %v\n", err)
+	}
+	if !isService {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		return run(ctx)
+	}
+
+	h := &serviceHandler{run: run}
+	if err := svc.Run("dbbench", h); err != nil {
+		log.Printf("failed to run as service: %v\n", err)
+		return exitFailure
+	}
+	return h.code
+}
+
+// serviceHandler runs the daemon until the service is stopped.
+type serviceHandler struct {
+	run  func(ctx context.Context) int
+	code int
+}
+
+func (h *serviceHandler) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan int, 1)
+	go func() { done <- h.run(ctx) }()
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case h.code = <-done:
+			return false, uint32(h.code)
+		case r := <-requests:
+			switch r.Cmd {
+			case svc.Interrogate:
+				status <- r.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				cancel()
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/sj14/dbbench/results"
+	"github.com/spf13/pflag"
+)
+
+// keygenCmd generates the keys signing and encrypting the results.
+func keygenCmd(args []string) int {
+	var (
+		flags      = pflag.NewFlagSet("keygen", pflag.ContinueOnError)
+		encryption = flags.Bool("encryption", false, "generate a key encrypting the results for --encrypt-results, instead of a key pair for --sign")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: dbbench keygen [flags] <name>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+	name := flags.Arg(0)
+
+	if *encryption {
+		if err := results.GenerateEncryptionKey(name); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		fmt.Printf("encryption key: %v (keep it secret)\n", name)
+		return exitOK
+	}
+	if err := results.GenerateSigningKeys(name); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	fmt.Printf("private key: %v.key (signs the results, keep it secret)\n", name)
+	fmt.Printf("public key:  %v.pub (verifies the results, share it)\n", name)
+	return exitOK
+}
+
+// verifyCmd verifies the signatures of the result files.
+func verifyCmd(args []string) int {
+	var (
+		flags = pflag.NewFlagSet("verify", pflag.ContinueOnError)
+		key   = flags.String("key", "", "public key of the signer, generated by 'dbbench keygen'")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: dbbench verify --key <public key> <result.json>...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *key == "" || flags.NArg() < 1 {
+		flags.Usage()
+		return exitUsage
+	}
+	pub, err := results.ReadVerifyingKey(*key)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	code := exitOK
+	for _, path := range flags.Args() {
+		if err := results.VerifyFile(path, pub); err != nil {
+			fmt.Printf("%v:\t%v\n", path, err)
+			code = exitFailure
+			continue
+		}
+		fmt.Printf("%v:\tok\n", path)
+	}
+	return code
+}
+
+// decryptCmd writes the decrypted results to stdout.
+func decryptCmd(args []string) int {
+	var (
+		flags = pflag.NewFlagSet("decrypt", pflag.ContinueOnError)
+		key   = flags.String("key", "", "key the results were encrypted with, generated by 'dbbench keygen --encryption'")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: dbbench decrypt --key <key> <result.json>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *key == "" || flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+	secret, err := results.ReadEncryptionKey(*key)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	dat, err := ioutil.ReadFile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	plain, err := results.Decrypt(dat, secret)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v: %v\n", flags.Arg(0), err)
+		return exitFailure
+	}
+	if _, err := os.Stdout.Write(plain); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	return exitOK
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/sj14/dbbench/results"
+	"github.com/spf13/pflag"
+)
+
+// target is a database the benchmarks run against, see --target.
+type target struct {
+	name string
+	args []string // database and its connection flags, e.g. [postgres --host a]
+}
+
+// parseTargets removes the --target flags from args and returns the targets, e.g. of
+// "--target pg=postgres --host a", and the remaining args shared by the targets.
+// A target without a name is named after its database.
+func parseTargets(args []string) ([]target, []string, error) {
+	var (
+		targets []target
+		rest    []string
+		names   = map[string]bool{}
+	)
+	for i := 0; i < len(args); i++ {
+		var value string
+		switch {
+		case args[i] == "--target":
+			if i+1 >= len(args) {
+				return nil, nil, errors.New("missing value of --target")
+			}
+			i++
+			value = args[i]
+		case strings.HasPrefix(args[i], "--target="):
+			value = strings.TrimPrefix(args[i], "--target=")
+		default:
+			rest = append(rest, args[i])
+			continue
+		}
+
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			return nil, nil, errors.New("empty --target, e.g. \"pg=postgres --host a\"")
+		}
+		t := target{name: fields[0], args: fields}
+		if i := strings.Index(fields[0], "="); i >= 0 {
+			t.name, t.args[0] = fields[0][:i], fields[0][i+1:]
+		}
+		if t.name == "" || t.args[0] == "" || strings.HasPrefix(t.args[0], "-") {
+			return nil, nil, fmt.Errorf("invalid --target %q, needs a database, e.g. \"pg=postgres --host a\"", value)
+		}
+		if names[t.name] {
+			return nil, nil, fmt.Errorf("duplicate target %v, name the targets, e.g. \"a=%v\"", t.name, value)
+		}
+		names[t.name] = true
+		targets = append(targets, t)
+	}
+	return targets, rest, nil
+}
+
+// runTargets runs the benchmarks against each target one after another, with the shared args,
+// and prints their results side by side. The first target is the baseline of the comparison.
+// --save and --report write a file per target, named after it.
+func runTargets(targets []target, shared []string) int {
+	flags := pflag.NewFlagSet("run", pflag.ContinueOnError)
+	flags.ParseErrorsWhitelist.UnknownFlags = true
+	flags.SetOutput(ioutil.Discard)
+	save := flags.String("save", "", "")
+	report := flags.String("report", "", "")
+	if err := flags.Parse(shared); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	dir, err := ioutil.TempDir("", "dbbench")
+	if err != nil {
+		log.Printf("failed to create temp dir: %v\n", err)
+		return exitFailure
+	}
+	defer os.RemoveAll(dir)
+
+	exe, err := os.Executable()
+	if err != nil {
+		log.Fatalf("failed to get executable: %v", err)
+	}
+
+	var (
+		names []string
+		runs  []*results.Run
+		code  = exitOK
+	)
+	for _, t := range targets {
+		fmt.Printf("target %v:\n", t.name)
+		path := filepath.Join(dir, t.name+".json")
+		if *save != "" {
+			path = targetPath(*save, t.name)
+		}
+		// the flags of the target override the shared ones
+		args := append(append(append([]string{"run", t.args[0]}, shared...), t.args[1:]...), "--save", path)
+		if *report != "" {
+			args = append(args, "--report", targetPath(*report, t.name))
+		}
+
+		os.Remove(path) // not the results of a previous run, when this one fails
+		cmd := exec.Command(exe, args...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		err := cmd.Run()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && code == exitOK {
+			// e.g. a violated SLO, the results are still saved
+			code = exitErr.ExitCode()
+		}
+
+		run, readErr := results.ReadFile(path)
+		if readErr != nil {
+			if err == nil {
+				err = readErr
+			}
+			log.Printf("target %v failed: %v\n", t.name, err)
+			if code == exitOK {
+				code = exitFailure
+			}
+			run = &results.Run{}
+		}
+		names = append(names, t.name)
+		runs = append(runs, run)
+	}
+
+	fmt.Println("\ntargets:")
+	if err := results.WriteMatrix(os.Stdout, names, runs); err != nil {
+		log.Printf("failed to write results: %v\n", err)
+		return exitFailure
+	}
+	return code
+}
+
+// targetPath returns the path of the file of the target, e.g. results-pg.json of results.json.
+func targetPath(path, name string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + name + ext
+}
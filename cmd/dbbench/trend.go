@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sj14/dbbench/results"
+	"github.com/spf13/pflag"
+)
+
+// trendCmd prints the trends of the benchmarks over the last saved runs and flags significant drifts,
+// which are too slow to be noticed by comparing two runs.
+func trendCmd(args []string) int {
+	var (
+		flags = pflag.NewFlagSet("trend", pflag.ContinueOnError)
+		last  = flags.Int("last", 10, "number of the latest runs (or SUT versions) to analyze")
+		alpha = flags.Float64("alpha", 0.05, "significance level of the drifts")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: dbbench trend [flags] <result.json>...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() < 3 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	var runs []*results.Run
+	for _, path := range flags.Args() {
+		run, err := results.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		runs = append(runs, run)
+	}
+	groups := results.GroupBySUT(runs)
+	if *last > 0 && len(groups) > *last {
+		groups = groups[len(groups)-*last:]
+	}
+	runs = nil
+	for _, g := range groups {
+		runs = append(runs, g...)
+	}
+
+	for _, t := range results.Trends(runs) {
+		if t.Significant(*alpha) {
+			fmt.Printf("%v:\tdrift, %v\n", t.Name, t)
+			continue
+		}
+		fmt.Printf("%v:\tstable, %v\n", t.Name, t)
+	}
+	return exitOK
+}
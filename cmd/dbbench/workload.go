@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/sj14/dbbench/benchmark"
+	"github.com/sj14/dbbench/config"
+	"github.com/spf13/pflag"
+)
+
+// exportCmd writes the benchmarks of scripts and their run options as portable workload definition.
+func exportCmd(args []string) int {
+	var (
+		flags        = pflag.NewFlagSet("export", pflag.ContinueOnError)
+		output       = flags.StringP("output", "o", "", "write the workload to this file, as JSON with the extension .json (default: YAML to stdout)")
+		name         = flags.String("name", "", "name of the workload")
+		description  = flags.String("description", "", "description of the workload")
+		database     = flags.String("database", "", "database the statements are written for, e.g. postgres")
+		threads      = flags.Int("threads", 0, "threads of the suites (0 -> default of 'dbbench run')")
+		iter         = flags.Int("iter", 0, "iterations of the suites (0 -> default of 'dbbench run')")
+		duration     = flags.String("duration", "", "duration of each loop benchmark instead of the iterations, e.g. 60s")
+		rate         = flags.Float64("rate", 0, "max. operations per second of the loop benchmarks (0 -> unlimited)")
+		maxErrorRate = flags.Float64("max-error-rate", 0, "max. fraction of failed statements of a benchmark (0 -> disabled)")
+		slo          = flags.StringToString("slo", nil, "max. duration per operation of the benchmarks, by their name in the script, e.g. \"insert=200us\"")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: dbbench export [flags] <script.sql>...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	w := &config.Workload{Version: config.WorkloadVersion, Name: *name, Description: *description}
+	asserted := map[string]bool{}
+	for _, path := range flags.Args() {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+		benchmarks, err := benchmark.ParseScript(f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to parse %v: %v\n", path, err)
+			return exitUsage
+		}
+
+		suite := config.NewWorkloadSuite(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), benchmarks)
+		suite.Database, suite.Threads, suite.Iter, suite.Duration = *database, *threads, *iter, *duration
+		suite.Rate, suite.MaxErrorRate = *rate, *maxErrorRate
+		for i, b := range suite.Benchmarks {
+			if d, ok := (*slo)[b.Name]; ok && b.Name != "" {
+				suite.Benchmarks[i].Assert = &config.WorkloadAssert{NsPerOp: d}
+				asserted[b.Name] = true
+			}
+		}
+		w.Suites = append(w.Suites, suite)
+	}
+	for name := range *slo {
+		if !asserted[name] {
+			fmt.Fprintf(os.Stderr, "no benchmark named %v for the SLO, name it with \\name\n", name)
+			return exitUsage
+		}
+	}
+	w.Functions = benchmark.DefinedFuncs()
+
+	if *output != "" {
+		if err := w.Save(*output); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		return exitOK
+	}
+	dat, err := w.Marshal(false)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	os.Stdout.Write(dat)
+	return exitOK
+}
+
+// importCmd writes the suites of a workload definition as scripts and prints the commands running them.
+func importCmd(args []string) int {
+	var (
+		flags = pflag.NewFlagSet("import", pflag.ContinueOnError)
+		dir   = flags.String("dir", ".", "directory of the scripts, one per suite named <suite>.sql")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: dbbench import [flags] <workload.yaml|json>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	w, err := config.LoadWorkload(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+
+	for _, s := range w.Suites {
+		path := filepath.Join(*dir, s.Name+".sql")
+		script := benchmark.FormatScript(config.ToBenchmarks(s.Benchmarks), w.Functions)
+		if err := ioutil.WriteFile(path, []byte(script), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write script: %v\n", err)
+			return exitFailure
+		}
+
+		database := s.Database
+		if database == "" {
+			database = "<database>"
+		}
+		cmd := append([]string{"dbbench", "run", database, "--script", path}, s.Args()...)
+		for i := range cmd {
+			cmd[i] = shellQuote(cmd[i])
+		}
+		fmt.Println(strings.Join(cmd, " "))
+	}
+	return exitOK
+}
+
+// unquoted matches the arguments, which don't need quotes in a shell.
+var unquoted = regexp.MustCompile(`^[\w@%+=:,./-]+$`)
+
+// shellQuote returns the argument quoted for a POSIX shell, when needed.
+func shellQuote(s string) string {
+	if unquoted.MatchString(s) {
+		return s
+	}
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
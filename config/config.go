@@ -0,0 +1,183 @@
+// Package config reads and writes dbbench configuration files.
+package config
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Config describes a complete benchmark run, which is otherwise specified with command line flags.
+type Config struct {
+	Database   string     `yaml:"database"`
+	Connection Connection `yaml:"connection,omitempty"`
+	Iter       int        `yaml:"iter,omitempty"`
+	Duration   string     `yaml:"duration,omitempty"` // instead of iter, e.g. "60s"
+	Warmup     string     `yaml:"warmup,omitempty"`   // iterations or duration, e.g. "1000" or "10s"
+	Threads    int        `yaml:"threads,omitempty"`
+	Rate       float64    `yaml:"rate,omitempty"`          // max. operations per second of the loop benchmarks
+	Arrival    string     `yaml:"arrival,omitempty"`       // distribution of the intervals between the requests, e.g. "exp"
+	ThinkTime  string     `yaml:"think_time,omitempty"`    // pause after each statement, e.g. "10ms", "5ms-15ms" or "exp:10ms"
+	Timeout    string     `yaml:"query_timeout,omitempty"` // of each statement, e.g. "5s"
+	Seed       int64      `yaml:"seed,omitempty"`          // of the random values, to repeat the statements
+	Run        string     `yaml:"run,omitempty"`
+	Skip       string     `yaml:"skip,omitempty"`
+	Tags       []string   `yaml:"tags,omitempty"`
+	Script     string     `yaml:"script,omitempty"`
+
+	// custom benchmarks instead of the built-in ones, like the ones of a script or a workload suite,
+	// their assertions are the SLOs of the run
+	Functions  map[string]string   `yaml:"functions,omitempty"` // template functions of the statements, see \func
+	Benchmarks []WorkloadBenchmark `yaml:"benchmarks,omitempty"`
+}
+
+// Connection contains the settings to connect to the database.
+type Connection struct {
+	Host   string `yaml:"host,omitempty"`
+	Port   int    `yaml:"port,omitempty"`
+	User   string `yaml:"user,omitempty"`
+	Pass   string `yaml:"pass,omitempty"`
+	Conns  int    `yaml:"conns,omitempty"`
+	Path   string `yaml:"path,omitempty"`
+	Schema string `yaml:"schema,omitempty"`
+
+	// connection pool of the databases using database/sql
+	MaxIdleConns    int    `yaml:"max_idle_conns,omitempty"`
+	ConnMaxLifetime string `yaml:"conn_max_lifetime,omitempty"` // e.g. "5m"
+	ConnMaxIdleTime string `yaml:"conn_max_idle_time,omitempty"`
+
+	// TLS of the postgres, cockroach, mysql and cassandra connections
+	TLS           string `yaml:"tls,omitempty"` // e.g. "verify-full"
+	TLSCA         string `yaml:"tls_ca,omitempty"`
+	TLSCert       string `yaml:"tls_cert,omitempty"`
+	TLSKey        string `yaml:"tls_key,omitempty"`
+	TLSServerName string `yaml:"tls_server_name,omitempty"`
+
+	// settings applied to each connection, e.g. synchronous_commit: "off"
+	Settings map[string]string `yaml:"settings,omitempty"`
+}
+
+// Load reads the config file at path.
+func Load(path string) (*Config, error) {
+	dat, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %v", err)
+	}
+
+	c := &Config{}
+	if err := yaml.UnmarshalStrict(dat, c); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %v", err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %v: %v", path, err)
+	}
+	return c, nil
+}
+
+// validate returns an error, when the benchmarks of the config can't be run.
+func (c *Config) validate() error {
+	if c.Script != "" && len(c.Benchmarks) > 0 {
+		return fmt.Errorf("either a script or benchmarks")
+	}
+	for i, b := range c.Benchmarks {
+		if err := b.validate(); err != nil {
+			return fmt.Errorf("benchmark %v: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Save writes the config to path.
+func (c *Config) Save(path string) error {
+	dat, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %v", err)
+	}
+	if err := ioutil.WriteFile(path, dat, 0600); err != nil {
+		return fmt.Errorf("failed to write config: %v", err)
+	}
+	return nil
+}
+
+// Flags returns the values of the config as command line flags (name -> value).
+// Unset values are omitted, so the flag defaults stay in place.
+func (c *Config) Flags() map[string]string {
+	flags := map[string]string{}
+
+	setString := func(name, value string) {
+		if value != "" {
+			flags[name] = value
+		}
+	}
+	setInt := func(name string, value int) {
+		if value != 0 {
+			flags[name] = strconv.Itoa(value)
+		}
+	}
+	// the key=value pairs are CSV, like the values of the map flags
+	setMap := func(name string, values map[string]string) {
+		if len(values) == 0 {
+			return
+		}
+		var pairs []string
+		for k, v := range values {
+			pairs = append(pairs, k+"="+v)
+		}
+		sort.Strings(pairs)
+		buf := &bytes.Buffer{}
+		w := csv.NewWriter(buf)
+		w.Write(pairs)
+		w.Flush()
+		flags[name] = strings.TrimSuffix(buf.String(), "\n")
+	}
+
+	setString("host", c.Connection.Host)
+	setInt("port", c.Connection.Port)
+	setString("user", c.Connection.User)
+	setString("pass", c.Connection.Pass)
+	setInt("conns", c.Connection.Conns)
+	setString("path", c.Connection.Path)
+	setString("schema", c.Connection.Schema)
+	setInt("max-idle-conns", c.Connection.MaxIdleConns)
+	setString("conn-max-lifetime", c.Connection.ConnMaxLifetime)
+	setString("conn-max-idle-time", c.Connection.ConnMaxIdleTime)
+	setString("tls", c.Connection.TLS)
+	setString("tls-ca", c.Connection.TLSCA)
+	setString("tls-cert", c.Connection.TLSCert)
+	setString("tls-key", c.Connection.TLSKey)
+	setString("tls-server-name", c.Connection.TLSServerName)
+	setMap("set", c.Connection.Settings)
+	setInt("iter", c.Iter)
+	setString("duration", c.Duration)
+	setString("warmup", c.Warmup)
+	setInt("threads", c.Threads)
+	if c.Rate != 0 {
+		flags["rate"] = strconv.FormatFloat(c.Rate, 'f', -1, 64)
+	}
+	setString("arrival", c.Arrival)
+	setString("think-time", c.ThinkTime)
+	setString("query-timeout", c.Timeout)
+	if c.Seed != 0 {
+		flags["seed"] = strconv.FormatInt(c.Seed, 10)
+	}
+	setString("run", c.Run)
+	setString("skip", c.Skip)
+	setString("tags", strings.Join(c.Tags, ","))
+	setString("script", c.Script)
+
+	slos := map[string]string{}
+	for i, b := range ToBenchmarks(c.Benchmarks) {
+		if a := c.Benchmarks[i].Assert; a != nil {
+			slos[b.Name] = a.NsPerOp
+		}
+	}
+	setMap("slo", slos)
+
+	return flags
+}
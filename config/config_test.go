@@ -0,0 +1,119 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveLoad(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "dbbench.yaml")
+	want := &Config{
+		Database:   "postgres",
+		Connection: Connection{Host: "localhost", Port: 5432, User: "postgres", Pass: "example", Schema: "dbbench_tmp", MaxIdleConns: 25, ConnMaxLifetime: "5m", TLS: "verify-full", TLSCA: "ca.pem"},
+		Iter:       1000,
+		Threads:    25,
+		Warmup:     "10s",
+		Rate:       500,
+		Seed:       42,
+		Tags:       []string{"read", "bulk"},
+		Functions:  map[string]string{"order_id": "ORD-{{.Iter}}"},
+		Benchmarks: []WorkloadBenchmark{
+			{Name: "setup", Type: "once", Statement: "CREATE TABLE orders (id TEXT);"},
+			{Name: "insert", Statement: "INSERT INTO orders VALUES ('{{.Func \"order_id\"}}');", Rate: 100, Assert: &WorkloadAssert{NsPerOp: "2ms"}},
+		},
+	}
+
+	// act
+	require.NoError(t, want.Save(path))
+	got, err := Load(path)
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
+
+func TestLoadUnknownField(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "dbbench.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte("database: sqlite\nunknown: 1\n"), 0600))
+
+	// act
+	_, err = Load(path)
+
+	// assert
+	require.Error(t, err)
+}
+
+func TestLoadInvalidBenchmarks(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, content := range []string{
+		"script: bench.sql\nbenchmarks:\n  - statement: SELECT 1;\n",
+		"benchmarks:\n  - type: forever\n    statement: SELECT 1;\n",
+		"benchmarks:\n  - statement: SELECT 1;\n    rate: -1\n",
+	} {
+		path := filepath.Join(dir, "dbbench.yaml")
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+		// act
+		_, err := Load(path)
+
+		// assert
+		require.Error(t, err, content)
+	}
+}
+
+func TestFlags(t *testing.T) {
+	c := &Config{
+		Database:   "sqlite",
+		Connection: Connection{Path: "bench.sqlite", MaxIdleConns: 10, ConnMaxIdleTime: "30s"},
+		Iter:       500,
+		Duration:   "1m",
+		Tags:       []string{"read", "ddl"},
+	}
+
+	require.Equal(t, map[string]string{"path": "bench.sqlite", "max-idle-conns": "10", "conn-max-idle-time": "30s", "iter": "500", "duration": "1m", "tags": "read,ddl"}, c.Flags())
+}
+
+func TestFlagsSuite(t *testing.T) {
+	c := &Config{
+		Connection: Connection{Settings: map[string]string{"work_mem": "64MB", "search_path": "a,b"}},
+		Warmup:     "1000",
+		Rate:       250.5,
+		Arrival:    "exp",
+		ThinkTime:  "exp:10ms",
+		Timeout:    "5s",
+		Seed:       7,
+		Benchmarks: []WorkloadBenchmark{
+			{Name: "insert", Statement: "INSERT INTO t VALUES (1);", Assert: &WorkloadAssert{NsPerOp: "2ms"}},
+			{Statement: "SELECT 1;"},
+		},
+	}
+
+	require.Equal(t, map[string]string{
+		"set":           `"search_path=a,b",work_mem=64MB`,
+		"warmup":        "1000",
+		"rate":          "250.5",
+		"arrival":       "exp",
+		"think-time":    "exp:10ms",
+		"query-timeout": "5s",
+		"seed":          "7",
+		"slo":           "(loop) insert=2ms",
+	}, c.Flags())
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"text/template"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Scenario runs the same benchmarks against several variants of the database, e.g. an encrypted
+// and an unencrypted instance, and compares their results.
+type Scenario struct {
+	Name     string    `yaml:"name"`
+	Variants []Variant `yaml:"variants,omitempty"`
+	Sweep    *Sweep    `yaml:"sweep,omitempty"` // generates the variants instead
+	Snapshot *Snapshot `yaml:"snapshot,omitempty"`
+}
+
+// Snapshot saves the dataset before the first variant and restores it before each following variant,
+// so all variants start from identical data without seeding it again, e.g. with a template database
+// or a volume snapshot. The commands are executed after the before hooks of the variant.
+type Snapshot struct {
+	Create  []string `yaml:"create"`         // shell commands saving the dataset
+	Restore []string `yaml:"restore"`        // shell commands replacing the dataset with the saved one
+	Drop    []string `yaml:"drop,omitempty"` // shell commands executed after the last variant, e.g. to remove the snapshot
+}
+
+// Variant is a single run of a scenario. The first variant is the baseline of the comparison.
+type Variant struct {
+	Name   string   `yaml:"name"`
+	Args   []string `yaml:"args"`             // arguments of 'dbbench run', e.g. [postgres, --port, "5433"]
+	Before []string `yaml:"before,omitempty"` // shell commands executed before the run, e.g. to start the instance
+	After  []string `yaml:"after,omitempty"`  // shell commands executed after the run, even when it failed
+}
+
+// Sweep generates a variant for each value of a server setting, e.g. synchronous_commit on and off.
+// The value is applied with --set to each connection or by the hooks, in which {{.Setting}} and
+// {{.Value}} are replaced.
+type Sweep struct {
+	Setting string   `yaml:"setting"`
+	Values  []string `yaml:"values"`
+	Args    []string `yaml:"args"`              // arguments of 'dbbench run' for all values
+	Session bool     `yaml:"session,omitempty"` // apply the value with --set
+	Before  []string `yaml:"before,omitempty"`
+	After   []string `yaml:"after,omitempty"`
+}
+
+// variants returns a variant named after each value.
+func (s *Sweep) variants() ([]Variant, error) {
+	if s.Setting == "" {
+		return nil, fmt.Errorf("sweep needs a setting")
+	}
+
+	expand := func(commands []string, value string) ([]string, error) {
+		var expanded []string
+		for _, c := range commands {
+			t, err := template.New("hook").Parse(c)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse hook %q: %v", c, err)
+			}
+			var buf bytes.Buffer
+			if err := t.Execute(&buf, struct{ Setting, Value string }{s.Setting, value}); err != nil {
+				return nil, fmt.Errorf("failed to execute hook %q: %v", c, err)
+			}
+			expanded = append(expanded, buf.String())
+		}
+		return expanded, nil
+	}
+
+	var variants []Variant
+	for _, value := range s.Values {
+		v := Variant{Name: value, Args: append([]string{}, s.Args...)}
+		if s.Session {
+			v.Args = append(v.Args, "--set", s.Setting+"="+value)
+		}
+		var err error
+		if v.Before, err = expand(s.Before, value); err != nil {
+			return nil, err
+		}
+		if v.After, err = expand(s.After, value); err != nil {
+			return nil, err
+		}
+		variants = append(variants, v)
+	}
+	return variants, nil
+}
+
+// LoadScenario reads the scenario file at path.
+func LoadScenario(path string) (*Scenario, error) {
+	dat, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %v", err)
+	}
+
+	s := &Scenario{}
+	if err := yaml.UnmarshalStrict(dat, s); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario: %v", err)
+	}
+	if s.Sweep != nil {
+		if len(s.Variants) > 0 {
+			return nil, fmt.Errorf("scenario %v has both variants and a sweep", path)
+		}
+		if s.Variants, err = s.Sweep.variants(); err != nil {
+			return nil, fmt.Errorf("invalid sweep of scenario %v: %v", path, err)
+		}
+	}
+	if len(s.Variants) < 2 {
+		return nil, fmt.Errorf("scenario %v needs at least 2 variants", path)
+	}
+	if s.Snapshot != nil && (len(s.Snapshot.Create) == 0 || len(s.Snapshot.Restore) == 0) {
+		return nil, fmt.Errorf("snapshot of scenario %v needs create and restore commands", path)
+	}
+
+	names := map[string]bool{}
+	for _, v := range s.Variants {
+		if v.Name == "" || names[v.Name] {
+			return nil, fmt.Errorf("variant names of scenario %v must be unique and not empty", path)
+		}
+		names[v.Name] = true
+	}
+	return s, nil
+}
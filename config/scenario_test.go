@@ -0,0 +1,124 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadScenario(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "tde.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`name: tde
+variants:
+  - name: plain
+    args: [postgres, --port, "5432"]
+  - name: encrypted
+    before: ["docker start pg-tde"]
+    args: [postgres, --port, "5433"]
+    after: ["docker stop pg-tde"]
+`), 0600))
+
+	// act
+	got, err := LoadScenario(path)
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, &Scenario{Name: "tde", Variants: []Variant{
+		{Name: "plain", Args: []string{"postgres", "--port", "5432"}},
+		{Name: "encrypted", Args: []string{"postgres", "--port", "5433"}, Before: []string{"docker start pg-tde"}, After: []string{"docker stop pg-tde"}},
+	}}, got)
+}
+
+func TestLoadScenarioInvalid(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, content := range []string{
+		"variants:\n  - name: a\n",
+		"variants:\n  - name: a\n  - name: a\n",
+		"variants:\n  - name: a\n  - args: [sqlite]\n",
+		"variants:\n  - name: a\n  - name: b\n    unknown: 1\n",
+		"variants:\n  - name: a\n  - name: b\nsweep:\n  setting: s\n  values: [c, d]\n",
+		"sweep:\n  values: [c, d]\n",
+		"sweep:\n  setting: s\n  values: [c]\n",
+		"sweep:\n  setting: s\n  values: [c, d]\n  before: [\"{{.Unknown}}\"]\n",
+		"sweep:\n  setting: s\n  values: [c, d]\nsnapshot:\n  create: [\"zfs snapshot tank/pg@bench\"]\n",
+	} {
+		path := filepath.Join(dir, "scenario.yaml")
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+		// act
+		_, err := LoadScenario(path)
+
+		// assert
+		require.Error(t, err, content)
+	}
+}
+
+func TestLoadScenarioSweep(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "sweep.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`name: sweep
+sweep:
+  setting: synchronous_commit
+  values: ["on", "off"]
+  args: [postgres, --tags, write]
+  session: true
+  before: ["echo {{.Setting}} = {{.Value}}"]
+`), 0600))
+
+	// act
+	got, err := LoadScenario(path)
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, []Variant{
+		{Name: "on", Args: []string{"postgres", "--tags", "write", "--set", "synchronous_commit=on"}, Before: []string{"echo synchronous_commit = on"}},
+		{Name: "off", Args: []string{"postgres", "--tags", "write", "--set", "synchronous_commit=off"}, Before: []string{"echo synchronous_commit = off"}},
+	}, got.Variants)
+}
+
+func TestLoadScenarioSnapshot(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "snapshot.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`sweep:
+  setting: work_mem
+  values: [4MB, 64MB]
+  args: [postgres, --noinit, --noclean]
+  session: true
+snapshot:
+  create: ["psql -c 'CREATE DATABASE snap TEMPLATE bench'"]
+  restore: ["psql -c 'DROP DATABASE bench'", "psql -c 'CREATE DATABASE bench TEMPLATE snap'"]
+  drop: ["psql -c 'DROP DATABASE snap'"]
+`), 0600))
+
+	// act
+	got, err := LoadScenario(path)
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, &Snapshot{
+		Create:  []string{"psql -c 'CREATE DATABASE snap TEMPLATE bench'"},
+		Restore: []string{"psql -c 'DROP DATABASE bench'", "psql -c 'CREATE DATABASE bench TEMPLATE snap'"},
+		Drop:    []string{"psql -c 'DROP DATABASE snap'"},
+	}, got.Snapshot)
+	require.Len(t, got.Variants, 2)
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Schedule contains the benchmark suites, which are run repeatedly by the daemon, e.g. as standing
+// performance canaries against a staging database.
+type Schedule struct {
+	Webhook string  `yaml:"webhook"` // Slack, Teams or generic webhook posted to when the alerts of a suite change
+	Suites  []Suite `yaml:"suites"`
+}
+
+// Suite is run by the daemon every interval, the first run starts immediately.
+type Suite struct {
+	Name  string        `yaml:"name"`
+	Every time.Duration `yaml:"every"` // interval between the starts of the runs, e.g. 1h
+	Args  []string      `yaml:"args"`  // arguments of 'dbbench run', e.g. [postgres, --host, staging-db]
+	Alert *Alert        `yaml:"alert"` // thresholds of the runs, optional
+}
+
+// Alert contains the thresholds of a suite, the daemon alerts when a run fails or exceeds them.
+type Alert struct {
+	NsPerOp  time.Duration `yaml:"ns_per_op"` // max. duration per operation of each benchmark
+	P99      time.Duration `yaml:"p99"`       // max. p99 latency, needs --percentiles in the arguments
+	Drift    float64       `yaml:"drift"`     // max. relative change compared to the median of the previous runs, e.g. 0.2
+	Baseline int           `yaml:"baseline"`  // previous runs compared for the drift (default 10)
+}
+
+// LoadSchedule reads the schedule file at path.
+func LoadSchedule(path string) (*Schedule, error) {
+	dat, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedule: %v", err)
+	}
+
+	s := &Schedule{}
+	if err := yaml.UnmarshalStrict(dat, s); err != nil {
+		return nil, fmt.Errorf("failed to parse schedule: %v", err)
+	}
+	if len(s.Suites) == 0 {
+		return nil, fmt.Errorf("schedule %v has no suites", path)
+	}
+
+	names := map[string]bool{}
+	for i, suite := range s.Suites {
+		if suite.Name == "" || names[suite.Name] {
+			return nil, fmt.Errorf("suite names of schedule %v must be unique and not empty", path)
+		}
+		names[suite.Name] = true
+		if suite.Every <= 0 {
+			return nil, fmt.Errorf("suite %v needs a positive interval", suite.Name)
+		}
+		if len(suite.Args) == 0 {
+			return nil, fmt.Errorf("suite %v needs the arguments of 'dbbench run'", suite.Name)
+		}
+		if a := suite.Alert; a != nil {
+			if a.NsPerOp < 0 || a.P99 < 0 || a.Drift < 0 || a.Baseline < 0 {
+				return nil, fmt.Errorf("suite %v has negative alert thresholds", suite.Name)
+			}
+			if a.Baseline == 0 {
+				s.Suites[i].Alert.Baseline = 10
+			}
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSchedule(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "canary.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`webhook: https://hooks.example.com/dbbench
+suites:
+  - name: staging
+    every: 1h
+    args: [postgres, --host, staging-db, --iter, "10000"]
+    alert:
+      p99: 20ms
+      drift: 0.25
+  - name: reads
+    every: 15m
+    args: [postgres, --host, staging-db, --tags, read]
+`), 0600))
+
+	// act
+	got, err := LoadSchedule(path)
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, &Schedule{Webhook: "https://hooks.example.com/dbbench", Suites: []Suite{
+		{Name: "staging", Every: time.Hour, Args: []string{"postgres", "--host", "staging-db", "--iter", "10000"}, Alert: &Alert{P99: 20 * time.Millisecond, Drift: 0.25, Baseline: 10}},
+		{Name: "reads", Every: 15 * time.Minute, Args: []string{"postgres", "--host", "staging-db", "--tags", "read"}},
+	}}, got)
+}
+
+func TestLoadScheduleInvalid(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, content := range []string{
+		"suites: []\n",
+		"suites:\n  - every: 1h\n    args: [sqlite]\n",
+		"suites:\n  - name: a\n    every: 1h\n    args: [sqlite]\n  - name: a\n    every: 1h\n    args: [sqlite]\n",
+		"suites:\n  - name: a\n    args: [sqlite]\n",
+		"suites:\n  - name: a\n    every: 1h\n",
+		"suites:\n  - name: a\n    every: 1h\n    args: [sqlite]\n    unknown: 1\n",
+		"suites:\n  - name: a\n    every: 1h\n    args: [sqlite]\n    alert:\n      drift: -0.1\n",
+	} {
+		path := filepath.Join(dir, "schedule.yaml")
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+		// act
+		_, err := LoadSchedule(path)
+
+		// assert
+		require.Error(t, err, content)
+	}
+}
@@ -0,0 +1,303 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sj14/dbbench/benchmark"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// WorkloadVersion is the version of the workload format, files of newer versions are rejected.
+const WorkloadVersion = 1
+
+// Workload is a portable definition of benchmark suites, e.g. to share them between teams and tools.
+// It's stored as YAML or, with the extension .json, as JSON.
+type Workload struct {
+	Version     int               `yaml:"version" json:"version"`
+	Name        string            `yaml:"name,omitempty" json:"name,omitempty"`
+	Description string            `yaml:"description,omitempty" json:"description,omitempty"`
+	Functions   map[string]string `yaml:"functions,omitempty" json:"functions,omitempty"` // template functions of the statements, see \func
+	Suites      []WorkloadSuite   `yaml:"suites" json:"suites"`
+}
+
+// WorkloadSuite contains benchmarks, which are run with the same options.
+type WorkloadSuite struct {
+	Name         string              `yaml:"name" json:"name"`
+	Database     string              `yaml:"database,omitempty" json:"database,omitempty"` // dialect of the statements, e.g. postgres (empty -> any)
+	Threads      int                 `yaml:"threads,omitempty" json:"threads,omitempty"`
+	Iter         int                 `yaml:"iter,omitempty" json:"iter,omitempty"`
+	Duration     string              `yaml:"duration,omitempty" json:"duration,omitempty"` // of each loop benchmark instead of iter, e.g. "60s"
+	Rate         float64             `yaml:"rate,omitempty" json:"rate,omitempty"`         // max. operations per second of the loop benchmarks
+	MaxErrorRate float64             `yaml:"max_error_rate,omitempty" json:"max_error_rate,omitempty"`
+	Benchmarks   []WorkloadBenchmark `yaml:"benchmarks" json:"benchmarks"`
+}
+
+// WorkloadBenchmark is a benchmark of a suite, see benchmark.Benchmark. The random values, e.g. their
+// distributions, are template functions of the statements.
+type WorkloadBenchmark struct {
+	Name      string              `yaml:"name,omitempty" json:"name,omitempty"`
+	Type      string              `yaml:"type,omitempty" json:"type,omitempty"` // loop (default) or once
+	Statement string              `yaml:"statement,omitempty" json:"statement,omitempty"`
+	Mix       []WorkloadStatement `yaml:"mix,omitempty" json:"mix,omitempty"` // instead of the statement
+	Parallel  bool                `yaml:"parallel,omitempty" json:"parallel,omitempty"`
+	Tx        bool                `yaml:"tx,omitempty" json:"tx,omitempty"`
+	Fresh     bool                `yaml:"fresh,omitempty" json:"fresh,omitempty"` // a new connection per statement
+	Capture   string              `yaml:"capture,omitempty" json:"capture,omitempty"`
+	Expect    string              `yaml:"expect,omitempty" json:"expect,omitempty"` // expected result, e.g. rows=1
+	Seed      int64               `yaml:"seed,omitempty" json:"seed,omitempty"`
+	Rate      float64             `yaml:"rate,omitempty" json:"rate,omitempty"` // max. operations per second, overrides the one of the suite
+	Tags      []string            `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Assert    *WorkloadAssert     `yaml:"assert,omitempty" json:"assert,omitempty"`
+}
+
+// WorkloadStatement is a weighted statement of a mix benchmark.
+type WorkloadStatement struct {
+	Name      string  `yaml:"name,omitempty" json:"name,omitempty"`
+	Weight    float64 `yaml:"weight" json:"weight"`
+	Statement string  `yaml:"statement" json:"statement"`
+}
+
+// WorkloadAssert contains the thresholds of a benchmark.
+type WorkloadAssert struct {
+	NsPerOp string `yaml:"ns_per_op" json:"ns_per_op"` // max. duration per operation, e.g. "200us", see --slo
+}
+
+// LoadWorkload reads the workload file at path.
+func LoadWorkload(path string) (*Workload, error) {
+	dat, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workload: %v", err)
+	}
+
+	w := &Workload{}
+	if isJSON(path) {
+		dec := json.NewDecoder(bytes.NewReader(dat))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(w)
+	} else {
+		err = yaml.UnmarshalStrict(dat, w)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse workload: %v", err)
+	}
+	if err := w.validate(); err != nil {
+		return nil, fmt.Errorf("invalid workload %v: %v", path, err)
+	}
+	return w, nil
+}
+
+// Save writes the workload to path, as JSON when the extension is .json.
+func (w *Workload) Save(path string) error {
+	dat, err := w.Marshal(isJSON(path))
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, dat, 0644); err != nil {
+		return fmt.Errorf("failed to write workload: %v", err)
+	}
+	return nil
+}
+
+// Marshal returns the workload as YAML or JSON.
+func (w *Workload) Marshal(asJSON bool) ([]byte, error) {
+	if asJSON {
+		dat, err := json.MarshalIndent(w, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal workload: %v", err)
+		}
+		return append(dat, '\n'), nil
+	}
+	dat, err := yaml.Marshal(w)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal workload: %v", err)
+	}
+	return dat, nil
+}
+
+func isJSON(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".json")
+}
+
+// validate returns an error, when the workload can't be run.
+func (w *Workload) validate() error {
+	if w.Version < 1 {
+		return fmt.Errorf("missing version")
+	}
+	if w.Version > WorkloadVersion {
+		return fmt.Errorf("version %v is newer than the supported version %v, update dbbench", w.Version, WorkloadVersion)
+	}
+	if len(w.Suites) == 0 {
+		return fmt.Errorf("no suites")
+	}
+	suites := map[string]bool{}
+	for _, s := range w.Suites {
+		if s.Name == "" || suites[s.Name] || strings.ContainsAny(s.Name, ` /\`) {
+			return fmt.Errorf("suite names must be unique and not empty, without spaces and slashes")
+		}
+		suites[s.Name] = true
+		if s.Duration != "" {
+			if _, err := time.ParseDuration(s.Duration); err != nil {
+				return fmt.Errorf("suite %v: invalid duration: %v", s.Name, err)
+			}
+		}
+		if len(s.Benchmarks) == 0 {
+			return fmt.Errorf("suite %v has no benchmarks", s.Name)
+		}
+		for i, b := range s.Benchmarks {
+			if err := b.validate(); err != nil {
+				return fmt.Errorf("suite %v, benchmark %v: %v", s.Name, i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (b WorkloadBenchmark) validate() error {
+	if strings.ContainsAny(b.Name, " \t\n") {
+		return fmt.Errorf("name %q contains spaces", b.Name)
+	}
+	switch b.Type {
+	case "", "loop":
+	case "once":
+		if len(b.Mix) > 0 {
+			return fmt.Errorf("a mix can't run once")
+		}
+	default:
+		return fmt.Errorf("unknown type %q, neither loop nor once", b.Type)
+	}
+	if (b.Statement == "") == (len(b.Mix) == 0) {
+		return fmt.Errorf("needs either a statement or a mix")
+	}
+	for _, m := range b.Mix {
+		if m.Weight <= 0 || m.Statement == "" || strings.ContainsAny(m.Name, " \t\n") {
+			return fmt.Errorf("the statements of the mix need a positive weight, a statement and a name without spaces")
+		}
+	}
+	if b.Rate < 0 {
+		return fmt.Errorf("negative rate")
+	}
+	if b.Expect != "" {
+		if strings.ContainsAny(b.Expect, " \t\n") {
+			return fmt.Errorf("expectation %q contains spaces", b.Expect)
+		}
+		if err := benchmark.ValidateExpect(b.Expect); err != nil {
+			return err
+		}
+	}
+	if b.Assert != nil {
+		if _, err := time.ParseDuration(b.Assert.NsPerOp); err != nil {
+			return fmt.Errorf("invalid ns_per_op: %v", err)
+		}
+	}
+	return nil
+}
+
+// NewWorkloadSuite returns a suite of the benchmarks, e.g. of a script, without options.
+func NewWorkloadSuite(name string, benchmarks []benchmark.Benchmark) WorkloadSuite {
+	s := WorkloadSuite{Name: name}
+	for _, b := range benchmarks {
+		wb := WorkloadBenchmark{
+			Name:      benchmark.ScriptName(b.Name),
+			Statement: b.Stmt,
+			Parallel:  b.Parallel,
+			Tx:        b.Tx,
+			Fresh:     b.Fresh,
+			Capture:   b.Capture,
+			Expect:    b.Expect,
+			Seed:      b.Seed,
+			Rate:      b.Rate,
+			Tags:      b.Tags,
+		}
+		if b.Type == benchmark.TypeOnce {
+			wb.Type = "once"
+		}
+		for _, m := range b.Mix {
+			wb.Mix = append(wb.Mix, WorkloadStatement{Name: benchmark.ScriptName(m.Name), Weight: m.Weight, Statement: m.Stmt})
+		}
+		s.Benchmarks = append(s.Benchmarks, wb)
+	}
+	return s
+}
+
+// ToBenchmarks returns the benchmarks of the definitions, named like the ones of a script.
+func ToBenchmarks(defs []WorkloadBenchmark) []benchmark.Benchmark {
+	var benchmarks []benchmark.Benchmark
+	for i, wb := range defs {
+		b := benchmark.Benchmark{
+			Name:     wb.Name,
+			Type:     benchmark.TypeLoop,
+			Stmt:     wb.Statement,
+			Parallel: wb.Parallel,
+			Tx:       wb.Tx,
+			Fresh:    wb.Fresh,
+			Capture:  wb.Capture,
+			Expect:   wb.Expect,
+			Seed:     wb.Seed,
+			Rate:     wb.Rate,
+			Tags:     wb.Tags,
+		}
+		if wb.Type == "once" {
+			b.Type = benchmark.TypeOnce
+		}
+		for j, m := range wb.Mix {
+			name := m.Name
+			if name == "" {
+				name = fmt.Sprintf("statement_%v", j+1)
+			}
+			b.Mix = append(b.Mix, benchmark.MixStmt{Name: name, Weight: m.Weight, Stmt: m.Statement})
+		}
+		b.Name = benchmarkName(b, i)
+		benchmarks = append(benchmarks, b)
+	}
+	return benchmarks
+}
+
+// benchmarkName returns the name of the benchmark with its mode, like the names of ParseScript,
+// e.g. "(loop) insert", or "(loop) benchmark_2" after its position, when it has no name.
+func benchmarkName(b benchmark.Benchmark, i int) string {
+	mode := b.Type.String()
+	if len(b.Mix) > 0 {
+		mode = "mix"
+	}
+	if b.Name == "" {
+		return fmt.Sprintf("(%v) benchmark_%v", mode, i+1)
+	}
+	return fmt.Sprintf("(%v) %v", mode, b.Name)
+}
+
+// Args returns the flags of 'dbbench run' for the options of the suite.
+func (s WorkloadSuite) Args() []string {
+	var args []string
+	if s.Threads > 0 {
+		args = append(args, "--threads", strconv.Itoa(s.Threads))
+	}
+	if s.Iter > 0 {
+		args = append(args, "--iter", strconv.Itoa(s.Iter))
+	}
+	if s.Duration != "" {
+		args = append(args, "--duration", s.Duration)
+	}
+	if s.Rate > 0 {
+		args = append(args, "--rate", strconv.FormatFloat(s.Rate, 'f', -1, 64))
+	}
+	if s.MaxErrorRate > 0 {
+		args = append(args, "--max-error-rate", strconv.FormatFloat(s.MaxErrorRate, 'f', -1, 64))
+	}
+	var slos []string
+	for i, b := range ToBenchmarks(s.Benchmarks) {
+		if a := s.Benchmarks[i].Assert; a != nil {
+			slos = append(slos, b.Name+"="+a.NsPerOp)
+		}
+	}
+	if len(slos) > 0 {
+		args = append(args, "--slo", strings.Join(slos, ","))
+	}
+	return args
+}
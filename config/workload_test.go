@@ -0,0 +1,101 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sj14/dbbench/benchmark"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkloadSaveLoad(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	want := &Workload{
+		Version:   WorkloadVersion,
+		Name:      "orders",
+		Functions: map[string]string{"order_id": "ORD-{{.Iter}}"},
+		Suites: []WorkloadSuite{{
+			Name:     "oltp",
+			Database: "postgres",
+			Threads:  8,
+			Duration: "1m",
+			Rate:     500,
+			Benchmarks: []WorkloadBenchmark{
+				{Name: "setup", Type: "once", Statement: "CREATE TABLE orders (id TEXT);"},
+				{Name: "insert", Statement: "INSERT INTO orders VALUES ('{{.Func \"order_id\"}}');", Tags: []string{"write"}, Assert: &WorkloadAssert{NsPerOp: "2ms"}},
+				{Name: "rw", Expect: "rows>0", Mix: []WorkloadStatement{{Name: "read", Weight: 80, Statement: "SELECT 1;"}, {Weight: 20, Statement: "SELECT 2;"}}},
+			},
+		}},
+	}
+
+	for _, name := range []string{"orders.yaml", "orders.json"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+
+			// act
+			require.NoError(t, want.Save(path))
+			got, err := LoadWorkload(path)
+
+			// assert
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestLoadWorkloadInvalid(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, content := range []string{
+		"suites:\n  - name: a\n    benchmarks:\n      - statement: SELECT 1;\n",
+		"version: 2\nsuites:\n  - name: a\n    benchmarks:\n      - statement: SELECT 1;\n",
+		"version: 1\nsuites:\n  - name: a\n",
+		"version: 1\nsuites:\n  - name: a b\n    benchmarks:\n      - statement: SELECT 1;\n",
+		"version: 1\nsuites:\n  - name: a\n    benchmarks:\n      - type: forever\n        statement: SELECT 1;\n",
+		"version: 1\nsuites:\n  - name: a\n    benchmarks:\n      - name: two words\n        statement: SELECT 1;\n",
+		"version: 1\nsuites:\n  - name: a\n    benchmarks:\n      - statement: SELECT 1;\n        assert:\n          ns_per_op: fast\n",
+		"version: 1\nsuites:\n  - name: a\n    benchmarks:\n      - statement: SELECT 1;\n        expect: rows~1\n",
+		"version: 1\nsuites:\n  - name: a\n    benchmarks:\n      - mix:\n          - weight: 0\n            statement: SELECT 1;\n",
+	} {
+		path := filepath.Join(dir, "workload.yaml")
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+		// act
+		_, err := LoadWorkload(path)
+
+		// assert
+		require.Error(t, err, content)
+	}
+}
+
+func TestWorkloadSuite(t *testing.T) {
+	// arrange
+	script := []benchmark.Benchmark{
+		{Name: "(once) setup", Type: benchmark.TypeOnce, Stmt: "CREATE TABLE t (id INT);"},
+		{Name: "(loop) line 3-3", Type: benchmark.TypeLoop, Stmt: "INSERT INTO t VALUES ({{.Iter}});"},
+	}
+
+	// act
+	suite := NewWorkloadSuite("script", script)
+	suite.Threads, suite.Rate = 4, 100
+	suite.Benchmarks[0].Assert = &WorkloadAssert{NsPerOp: "1ms"}
+
+	// assert
+	require.Equal(t, []WorkloadBenchmark{
+		{Name: "setup", Type: "once", Statement: "CREATE TABLE t (id INT);", Assert: &WorkloadAssert{NsPerOp: "1ms"}},
+		{Statement: "INSERT INTO t VALUES ({{.Iter}});"},
+	}, suite.Benchmarks)
+	benchmarks := ToBenchmarks(suite.Benchmarks)
+	require.Equal(t, "(once) setup", benchmarks[0].Name)
+	require.Equal(t, "(loop) benchmark_2", benchmarks[1].Name)
+	require.Equal(t, []string{"--threads", "4", "--rate", "100", "--slo", "(once) setup=1ms"}, suite.Args())
+}
@@ -0,0 +1,108 @@
+package databases
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// authMethod is an authentication method, benchmarked by connecting as a dedicated user.
+type authMethod struct {
+	name   string // suffix of the benchmark and the user name
+	method string // name of the method in the database
+}
+
+// authUsers contains the users created to benchmark the authentication methods.
+type authUsers struct {
+	password string
+	created  []string          // user names
+	skip     map[string]string // reason why a method can't be benchmarked
+}
+
+// benchmark returns the name of the benchmark of the method.
+func (m authMethod) benchmark() string {
+	return "connects_" + m.name
+}
+
+// selection contains the names of the benchmarks selected to run, see SelectBenchmarks.
+// The setup only creates the users and roles of the selected benchmarks.
+type selection map[string]bool
+
+// newSelection returns the selection of the benchmarks with the given names.
+func newSelection(names []string) selection {
+	s := selection{}
+	for _, name := range names {
+		s[name] = true
+	}
+	return s
+}
+
+// includes returns whether one of the benchmarks is selected.
+func (s selection) includes(benchmarks []benchmark.Benchmark) bool {
+	for _, b := range benchmarks {
+		if s[b.Name] {
+			return true
+		}
+	}
+	return false
+}
+
+// user returns the name of the user of the method.
+func (u *authUsers) user(schema string, m authMethod) string {
+	return fmt.Sprintf("%v_%v", schema, m.name)
+}
+
+// method returns the method with the given name of the database.
+func method(methods []authMethod, name string) authMethod {
+	for _, m := range methods {
+		if m.method == name {
+			return m
+		}
+	}
+	log.Fatalf("unknown authentication method: %v\n", name)
+	return authMethod{}
+}
+
+// benchmarks returns the benchmarks connecting with each method. Without setup, they are skipped.
+func (u *authUsers) benchmarks(methods []authMethod) []benchmark.Benchmark {
+	var benchmarks []benchmark.Benchmark
+	for _, m := range methods {
+		skip := "requires the setup of the users, not possible with --noinit"
+		if u.skip != nil {
+			skip = u.skip[m.method]
+		}
+		benchmarks = append(benchmarks, benchmark.Benchmark{Name: m.benchmark(), Type: benchmark.TypeLoop, Stmt: "SELECT 1;", Auth: m.method, Skip: skip})
+	}
+	return benchmarks
+}
+
+// randomPassword returns a new password of the users.
+func randomPassword() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatalf("failed to generate password: %v\n", err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// execConnected opens a new connection, executes the statement and closes the connection again.
+func execConnected(ctx context.Context, driver, dataSourceName, stmt string) error {
+	db, err := sql.Open(driver, dataSourceName)
+	if err != nil {
+		return err
+	}
+	return execClosing(ctx, db, stmt)
+}
+
+// execClosing executes the statement on the new connection pool and closes it, see ExecFreshConn.
+func execClosing(ctx context.Context, db *sql.DB, stmt string) error {
+	defer db.Close()
+
+	_, err := db.ExecContext(ctx, stmt)
+	return err
+}
@@ -1,87 +1,182 @@
 package databases
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/gocql/gocql"
 	"github.com/sj14/dbbench/benchmark"
+	"gopkg.in/inf.v0"
 )
 
 // Cassandra implements the bencher interface.
 type Cassandra struct {
-	session *gocql.Session
+	session     *gocql.Session
+	keyspace    string
+	replication int
+	created     bool // keyspace was created by dbbench
+}
+
+// CassandraOptions are the consistency, replication and routing options of Cassandra and ScyllaDB.
+type CassandraOptions struct {
+	Consistency       string // e.g. ONE, QUORUM or ALL
+	SerialConsistency string // of the lightweight transactions, SERIAL or LOCAL_SERIAL
+	ReplicationFactor int    // of the keyspace created by Setup
+	TokenAware        bool   // send the statements to a replica of their partition
 }
 
 // NewCassandra returns a new cassandra bencher.
-func NewCassandra(host string, port int, user, password string) *Cassandra {
+// All tables are created in the given keyspace, which is dropped when it was created by dbbench.
+func NewCassandra(host string, port int, user, password, keyspace string, tls TLS, opts CassandraOptions) (*Cassandra, error) {
 	if port == 0 {
 		port = 9042
 	}
+	if keyspace == "" {
+		keyspace = defaultSchema
+	}
 	dataSourceName := fmt.Sprintf("%v:%v", host, port) // TODO: check how to do with port, user and password
 
 	cluster := gocql.NewCluster(dataSourceName)
 	cluster.Keyspace = ""
 	cluster.Timeout = 5 * time.Minute
 	cluster.Consistency = gocql.Quorum
-	// TODO: as flags?
-	// Any         Consistency = 0x00
-	// One         Consistency = 0x01
-	// Two         Consistency = 0x02
-	// Three       Consistency = 0x03
-	// Quorum      Consistency = 0x04
-	// All         Consistency = 0x05
-	// LocalQuorum Consistency = 0x06
-	// EachQuorum  Consistency = 0x07
-	// LocalOne    Consistency = 0x0A
+	if opts.Consistency != "" {
+		var err error
+		if cluster.Consistency, err = gocql.ParseConsistencyWrapper(opts.Consistency); err != nil {
+			return nil, fmt.Errorf("invalid consistency %q, e.g. ONE, QUORUM, ALL or LOCAL_QUORUM", opts.Consistency)
+		}
+	}
+	if opts.SerialConsistency != "" {
+		if err := cluster.SerialConsistency.UnmarshalText([]byte(strings.ToUpper(opts.SerialConsistency))); err != nil {
+			return nil, fmt.Errorf("invalid serial consistency %q, SERIAL or LOCAL_SERIAL", opts.SerialConsistency)
+		}
+	}
+	if opts.TokenAware {
+		cluster.PoolConfig.HostSelectionPolicy = gocql.TokenAwareHostPolicy(gocql.RoundRobinHostPolicy())
+	}
+	if opts.ReplicationFactor < 1 {
+		opts.ReplicationFactor = 1
+	}
+	cfg, err := tls.config(host)
+	if err != nil {
+		return nil, err
+	}
+	if cfg != nil {
+		cluster.SslOpts = &gocql.SslOptions{Config: cfg, EnableHostVerification: tls.Mode == "verify-full"}
+	}
 	session, err := cluster.CreateSession()
 	if err != nil {
-		log.Fatalf("failed to create session: %v\n", err)
+		return nil, fmt.Errorf("failed to create session: %v", err)
 	}
 
-	return &Cassandra{session: session}
+	return &Cassandra{session: session, keyspace: keyspace, replication: opts.ReplicationFactor}, nil
 }
 
-// Benchmarks returns the individual benchmark functions for the cassandra db.
+// Benchmarks returns the individual benchmark functions for the cassandra db,
+// followed by the variants of the modifications as lightweight transactions (IF [NOT] EXISTS).
+// The deletes remove the inserted rows, so the conditional inserts apply again.
 // TODO: update is not like other db statements balance = balance + balance!
 func (c *Cassandra) Benchmarks() []benchmark.Benchmark {
-	return []benchmark.Benchmark{
-		{Name: "inserts", Type: benchmark.TypeLoop, Stmt: "INSERT INTO dbbench.dbbench_simple (id, balance) VALUES({{.Iter}}, {{call .RandInt63}}) IF NOT EXISTS;"},
-		{Name: "selects", Type: benchmark.TypeLoop, Stmt: "SELECT * FROM dbbench.dbbench_simple WHERE id = {{.Iter}};"},
-		{Name: "updates", Type: benchmark.TypeLoop, Stmt: "UPDATE dbbench.dbbench_simple SET balance = {{call .RandInt63}} WHERE id = {{.Iter}} IF EXISTS;"},
-		{Name: "deletes", Type: benchmark.TypeLoop, Stmt: "DELETE FROM dbbench.dbbench_simple WHERE id = {{.Iter}} IF EXISTS;"},
+	d := cassandraDialect.in(c.keyspace)
+	lwt := d
+	lwt.conditional = true
+
+	benchmarks := builtins(d)
+	for i, b := range benchmarks {
+		if b.Name == "deletes" {
+			updates := benchmark.Benchmark{Name: "updates-lwt", Type: benchmark.TypeLoop, Stmt: lwt.update("simple"), Tags: writeTags}
+			benchmarks = append(benchmarks[:i], append([]benchmark.Benchmark{updates}, benchmarks[i:]...)...)
+			break
+		}
 	}
+	return append(benchmarks,
+		benchmark.Benchmark{Name: "inserts-lwt", Type: benchmark.TypeLoop, Stmt: lwt.insert("simple"), Tags: writeTags},
+		benchmark.Benchmark{Name: "deletes-lwt", Type: benchmark.TypeLoop, Stmt: lwt.delete("simple"), Tags: writeTags},
+	)
 }
 
 // Setup initializes the database for the benchmark.
 func (c *Cassandra) Setup() {
-	// TODO: flags for class and replication factor
-	if err := c.session.Query("CREATE KEYSPACE IF NOT EXISTS dbbench WITH replication = { 'class':'SimpleStrategy', 'replication_factor' : 1 }").Exec(); err != nil {
+	var name string
+	err := c.session.Query("SELECT keyspace_name FROM system_schema.keyspaces WHERE keyspace_name = ?", c.keyspace).Scan(&name)
+	c.created = err == gocql.ErrNotFound
+
+	// TODO: flag for the class
+	if err := c.session.Query(fmt.Sprintf("CREATE KEYSPACE IF NOT EXISTS %v WITH replication = { 'class':'SimpleStrategy', 'replication_factor' : %d }", c.keyspace, c.replication)).Exec(); err != nil {
 		log.Fatalf("failed to create keyspace: %v\n", err)
 	}
-	if err := c.session.Query("CREATE TABLE IF NOT EXISTS dbbench.dbbench_simple (id INT PRIMARY KEY, balance DECIMAL);").Exec(); err != nil {
+	if err := c.session.Query(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %v.dbbench_simple (id INT PRIMARY KEY, balance DECIMAL);", c.keyspace)).Exec(); err != nil {
 		log.Fatalf("failed to create table: %v\n", err)
 	}
-	if err := c.session.Query("TRUNCATE dbbench.dbbench_simple;").Exec(); err != nil {
+	if err := c.session.Query(fmt.Sprintf("TRUNCATE %v.dbbench_simple;", c.keyspace)).Exec(); err != nil {
 		log.Fatalf("failed to truncate table: %v\n", err)
 	}
 }
 
-// Cleanup removes all remaining benchmarking data.
+// Seed inserts the seeded rows [from, to) into the table of the built-in benchmarks.
+func (c *Cassandra) Seed(from, to int) error {
+	for _, stmt := range cassandraDialect.in(c.keyspace).seedStmts("simple", from, to) {
+		if err := c.session.Query(stmt).Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cleanup removes all remaining benchmarking data. The keyspace is dropped with all its tables,
+// when it was created by dbbench, otherwise only the benchmark table is dropped.
 func (c *Cassandra) Cleanup() {
-	if err := c.session.Query("DROP TABLE dbbench.dbbench_simple").Exec(); err != nil {
+	if c.created {
+		if err := c.session.Query(fmt.Sprintf("DROP KEYSPACE %v", c.keyspace)).Exec(); err != nil {
+			log.Printf("failed to drop database: %v\n", err)
+		}
+	} else if err := c.session.Query(fmt.Sprintf("DROP TABLE %v.dbbench_simple", c.keyspace)).Exec(); err != nil {
 		log.Printf("failed to drop table: %v\n", err)
 	}
-	if err := c.session.Query("DROP KEYSPACE dbbench").Exec(); err != nil {
-		log.Printf("failed to drop database: %v\n", err)
-	}
 	c.session.Close()
 }
 
 // Exec executes the given statement on the database.
-func (c *Cassandra) Exec(stmt string) {
-	if err := c.session.Query(stmt).Exec(); err != nil {
-		log.Fatalf("%v: failed: %v\n", stmt, err)
+func (c *Cassandra) Exec(stmt string) error {
+	return c.session.Query(stmt).Exec()
+}
+
+// ExecArgs executes the statement with the arguments bound to its placeholders ?. The bound
+// statements are prepared by the driver, which routes them by their partition key with --token-aware.
+func (c *Cassandra) ExecArgs(ctx context.Context, stmt string, args ...interface{}) error {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = cqlValue{arg}
+	}
+	return c.session.Query(stmt, values...).WithContext(ctx).Exec()
+}
+
+// cqlValue is a bound value, which is converted to the type of its column when needed,
+// e.g. the integers of the templates to DECIMAL.
+type cqlValue struct {
+	value interface{}
+}
+
+func (v cqlValue) MarshalCQL(info gocql.TypeInfo) ([]byte, error) {
+	if i, ok := v.value.(int64); ok && info.Type() == gocql.TypeDecimal {
+		return gocql.Marshal(info, *inf.NewDec(i, 0))
+	}
+	return gocql.Marshal(info, v.value)
+}
+
+// Query executes the given statement and returns the first column of the returned rows.
+func (c *Cassandra) Query(stmt string) ([]string, error) {
+	iter := c.session.Query(stmt).Iter()
+	cols := iter.Columns()
+
+	var result []string
+	row := map[string]interface{}{}
+	for len(cols) > 0 && iter.MapScan(row) {
+		result = append(result, fmt.Sprint(row[cols[0].Name]))
+		row = map[string]interface{}{}
 	}
+	return result, iter.Close()
 }
@@ -0,0 +1,206 @@
+package databases
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// ClickHouse implements the bencher interface. The statements are sent to the HTTP interface,
+// the results are returned tab-separated.
+type ClickHouse struct {
+	client   *http.Client
+	url      string // including the settings
+	user     string
+	password string
+	schema   string
+	batch    int  // rows per insert of the built-in benchmarks
+	created  bool // database was created by dbbench
+}
+
+// clickhouseBatch is the default number of rows per insert. Single-row inserts create a part
+// per row, which is not how columnar stores are used.
+const clickhouseBatch = 1000
+
+// NewClickHouse returns a new ClickHouse bencher. All tables are created in the given database,
+// which is dropped when it was created by dbbench. The built-in inserts write batch rows each.
+// The settings are applied to each query, e.g. async_insert.
+func NewClickHouse(host string, port int, user, password, schema string, maxConns, batch int, settings map[string]string) (*ClickHouse, error) {
+	if port == 0 {
+		port = 8123
+	}
+	if schema == "" {
+		schema = defaultSchema
+	}
+	transport := &http.Transport{MaxIdleConnsPerHost: maxConns, MaxConnsPerHost: maxConns}
+	if maxConns == 0 {
+		// keep the connections of all threads open
+		transport.MaxIdleConnsPerHost = 1024
+	}
+	u := &url.URL{Scheme: "http", Host: fmt.Sprintf("%v:%v", host, port), Path: "/"}
+	params := url.Values{}
+	for k, v := range settings {
+		params.Set(k, v)
+	}
+	u.RawQuery = params.Encode()
+
+	c := &ClickHouse{
+		client:   &http.Client{Transport: transport, Timeout: 5 * time.Minute},
+		url:      u.String(),
+		user:     user,
+		password: password,
+		schema:   schema,
+		batch:    batch,
+	}
+
+	if _, err := c.query("SELECT 1"); err != nil {
+		return nil, fmt.Errorf("failed to ping db: %v", err)
+	}
+	return c, nil
+}
+
+// Benchmarks returns the individual benchmark statements for ClickHouse. The rows of an insert
+// share the id of the iteration, so the selects and deletes of an id affect the whole batch.
+func (c *ClickHouse) Benchmarks() []benchmark.Benchmark {
+	d := clickhouseDialect.in(c.schema)
+	batch := c.batch
+	if batch < 1 {
+		batch = clickhouseBatch
+	}
+	table := d.table("simple")
+	rows := fmt.Sprintf(`{{.Rows %d "({{.Iter}}, {{.Row}}, %v)"}}`, batch, d.balance)
+
+	return []benchmark.Benchmark{
+		{Name: "inserts", Type: benchmark.TypeLoop, Stmt: fmt.Sprintf("INSERT INTO %v (id, row, balance) VALUES %v;", table, rows), Tags: []string{benchmark.TagWrite, benchmark.TagBulk}},
+		{Name: "selects", Type: benchmark.TypeLoop, Stmt: d.selectKey("simple"), Tags: readTags},
+		{Name: "scans", Type: benchmark.TypeLoop, Stmt: d.scan("simple", 100), Tags: bulkReadTags},
+		{Name: "aggregates", Type: benchmark.TypeLoop, Stmt: fmt.Sprintf("SELECT count(), sum(balance), avg(balance) FROM %v;", table), Tags: bulkReadTags},
+		{Name: "groups", Type: benchmark.TypeLoop, Stmt: fmt.Sprintf("SELECT id %% 100 AS bucket, count(), max(balance) FROM %v GROUP BY bucket ORDER BY bucket;", table), Tags: bulkReadTags},
+		{Name: "quantiles", Type: benchmark.TypeLoop, Stmt: fmt.Sprintf("SELECT quantiles(0.5, 0.9, 0.99)(balance) FROM %v;", table), Tags: bulkReadTags},
+		{Name: "deletes", Type: benchmark.TypeLoop, Stmt: d.delete("simple"), Tags: writeTags},
+	}
+}
+
+// Setup initializes the database for the benchmark.
+func (c *ClickHouse) Setup() {
+	names, err := c.query(fmt.Sprintf("SELECT name FROM system.databases WHERE name = '%v'", c.schema))
+	if err != nil {
+		log.Fatalf("failed to list databases: %v\n", err)
+	}
+	c.created = len(names) == 0
+
+	if _, err := c.query(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %v", c.schema)); err != nil {
+		log.Fatalf("failed to create database: %v\n", err)
+	}
+	if _, err := c.query(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %v.simple (id Int64, row UInt32, balance Int64) ENGINE = MergeTree ORDER BY (id, row)", c.schema)); err != nil {
+		log.Fatalf("failed to create table: %v\n", err)
+	}
+	if _, err := c.query(fmt.Sprintf("TRUNCATE TABLE %v.simple", c.schema)); err != nil {
+		log.Fatalf("failed to truncate table: %v\n", err)
+	}
+}
+
+// Seed inserts the seeded rows [from, to) into the table of the built-in benchmarks.
+func (c *ClickHouse) Seed(from, to int) error {
+	for _, stmt := range clickhouseDialect.in(c.schema).seedStmts("simple", from, to) {
+		if _, err := c.query(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cleanup removes all remaining benchmarking data. The database is dropped with all its tables,
+// when it was created by dbbench, otherwise only the benchmark table is dropped.
+func (c *ClickHouse) Cleanup() {
+	if c.created {
+		if _, err := c.query(fmt.Sprintf("DROP DATABASE %v", c.schema)); err != nil {
+			log.Printf("failed to drop database: %v\n", err)
+		}
+	} else if _, err := c.query(fmt.Sprintf("DROP TABLE %v.simple", c.schema)); err != nil {
+		log.Printf("failed to drop table: %v\n", err)
+	}
+	c.client.CloseIdleConnections()
+}
+
+// Exec executes the given statement on the database.
+func (c *ClickHouse) Exec(stmt string) error {
+	_, err := c.query(stmt)
+	return err
+}
+
+// Query executes the given statement and returns the first column of the returned rows.
+func (c *ClickHouse) Query(stmt string) ([]string, error) {
+	rows, err := c.query(stmt)
+	if err != nil {
+		return nil, err
+	}
+	for i, row := range rows {
+		rows[i] = strings.SplitN(row, "\t", 2)[0]
+	}
+	return rows, nil
+}
+
+// ServerVersion returns the version of the server, e.g. "24.3.2.23".
+func (c *ClickHouse) ServerVersion() (string, error) {
+	rows, err := c.query("SELECT version()")
+	if err != nil {
+		return "", err
+	}
+	if len(rows) == 0 {
+		return "", nil
+	}
+	return rows[0], nil
+}
+
+// Size returns the bytes of the active parts of the tables of the database, rows removed by
+// lightweight deletes are included until the parts are merged.
+func (c *ClickHouse) Size() (int64, error) {
+	rows, err := c.query(fmt.Sprintf("SELECT sum(bytes_on_disk) FROM system.parts WHERE database = '%v' AND active", c.schema))
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	return strconv.ParseInt(rows[0], 10, 64)
+}
+
+// query posts the statement to the HTTP interface and returns the lines of the response.
+func (c *ClickHouse) query(stmt string) ([]string, error) {
+	req, err := http.NewRequest(http.MethodPost, c.url, strings.NewReader(stmt))
+	if err != nil {
+		return nil, err
+	}
+	if c.user != "" {
+		req.Header.Set("X-ClickHouse-User", c.user)
+		req.Header.Set("X-ClickHouse-Key", c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	dat, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%v: %s", resp.Status, strings.TrimSpace(string(dat)))
+	}
+
+	body := strings.TrimSuffix(string(dat), "\n")
+	if body == "" {
+		return nil, nil
+	}
+	return strings.Split(body, "\n"), nil
+}
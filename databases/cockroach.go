@@ -1,83 +1,128 @@
 package databases
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/sj14/dbbench/benchmark"
 )
 
 // Cockroach implements the bencher interface.
 type Cockroach struct {
-	db *sql.DB
+	db       *sql.DB
+	prepared preparedStmts // see ExecArgs
+	schema   string
+	created  bool  // database was created by dbbench
+	tpcc     *tpcc // workload instead of the built-in benchmarks, see SetWorkload
+
+	dataSourceName string // of the pooled connections, see ExecFreshConn
+	addr           string
 }
 
 // NewCockroach returns a new cockroach bencher.
-func NewCockroach(host string, port int, user, password string, maxOpenConns int) *Cockroach {
+// All tables are created in the given database, which is dropped when it was created by dbbench.
+// The settings are applied to each connection.
+func NewCockroach(host string, port int, user, password, schema string, pool Pool, tls TLS, settings map[string]string) (*Cockroach, error) {
 	if port == 0 {
 		port = 26257
 	}
+	if schema == "" {
+		schema = defaultSchema
+	}
 
-	dataSourceName := fmt.Sprintf("host=%v port=%v user='%v' password='%v' sslmode=disable", host, port, user, password)
+	if err := tls.Validate(); err != nil {
+		return nil, err
+	}
+	host, addr := tls.pqHost(host, port)
+	dataSourceName := fmt.Sprintf("host=%v port=%v user='%v' password='%v'", host, port, user, password) + tls.pqParams() + runtimeParams(settings)
 
-	db, err := sql.Open("postgres", dataSourceName)
+	db, err := openPostgres(dataSourceName, addr)
 	if err != nil {
-		log.Fatalf("failed to open connection: %v\n", err)
+		return nil, fmt.Errorf("failed to open connection: %v", err)
 	}
 	if err := db.Ping(); err != nil {
-		log.Fatalf("failed to ping db: %v", err)
+		return nil, fmt.Errorf("failed to ping db: %v", err)
 	}
 
-	db.SetMaxOpenConns(maxOpenConns)
-	return &Cockroach{db: db}
+	pool.apply(db)
+	return &Cockroach{db: db, schema: schema, dataSourceName: dataSourceName, addr: addr}, nil
+}
+
+// ExecFreshConn connects like the pooled connections, executes the statement and disconnects.
+func (p *Cockroach) ExecFreshConn(ctx context.Context, stmt string) error {
+	db, err := openPostgres(p.dataSourceName, p.addr)
+	if err != nil {
+		return err
+	}
+	return execClosing(ctx, db, stmt)
 }
 
 // Benchmarks returns the individual benchmark functions for the cockroach db.
 func (p *Cockroach) Benchmarks() []benchmark.Benchmark {
-	return []benchmark.Benchmark{
-		{Name: "inserts", Type: benchmark.TypeLoop, Stmt: "INSERT INTO dbbench.simple (id, balance) VALUES( {{.Iter}}, {{call .RandInt63}});"},
-		{Name: "selects", Type: benchmark.TypeLoop, Stmt: "SELECT * FROM dbbench.simple WHERE id = {{.Iter}};"},
-		{Name: "updates", Type: benchmark.TypeLoop, Stmt: "UPDATE dbbench.simple SET balance = {{call .RandInt63}} WHERE id = {{.Iter}};"},
-		{Name: "deletes", Type: benchmark.TypeLoop, Stmt: "DELETE FROM dbbench.simple WHERE id = {{.Iter}};"},
-		// {"relation_insert0", benchmark.TypeLoop, "INSERT INTO dbbench.relational_one (oid, balance_one) VALUES( {{.Iter}}, {{call .RandInt63}});"},
-		// {"relation_insert1", benchmark.TypeLoop, "INSERT INTO dbbench.relational_two (relation, balance_two) VALUES( {{.Iter}}, {{call .RandInt63}});"},
-		// {"relation_select", benchmark.TypeLoop, "SELECT * FROM dbbench.relational_two INNER JOIN dbbench.relational_one ON relational_one.oid = relational_two.relation WHERE relation = {{.Iter}};"},
-		// {"relation_delete1", benchmark.TypeLoop, "DELETE FROM dbbench.relational_two WHERE relation = {{.Iter}};"},
-		// {"relation_delete0", benchmark.TypeLoop, "DELETE FROM dbbench.relational_one WHERE oid = {{.Iter}};"},
+	if p.tpcc != nil {
+		return p.tpcc.benchmarks(cockroachDialect.in(p.schema))
 	}
+	return builtins(cockroachDialect.in(p.schema))
 }
 
 // Setup initializes the database for the benchmark.
 func (p *Cockroach) Setup() {
-	if _, err := p.db.Exec("CREATE DATABASE IF NOT EXISTS dbbench"); err != nil {
+	p.created = !exists(p.db, "SELECT 1 FROM pg_database WHERE datname = $1", p.schema)
+
+	if _, err := p.db.Exec(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %v", p.schema)); err != nil {
 		log.Fatalf("failed to create database: %v\n", err)
 	}
-	if _, err := p.db.Exec("CREATE TABLE IF NOT EXISTS dbbench.simple (id INT PRIMARY KEY, balance DECIMAL);"); err != nil {
+	if p.tpcc != nil {
+		p.tpcc.setup(p.db, cockroachDialect.in(p.schema))
+		return
+	}
+	if _, err := p.db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %v.simple (id INT PRIMARY KEY, balance DECIMAL);", p.schema)); err != nil {
 		log.Fatalf("failed to create table: %v\n", err)
 	}
-	if _, err := p.db.Exec("CREATE TABLE IF NOT EXISTS dbbench.relational_one (oid INT PRIMARY KEY, balance_one DECIMAL);"); err != nil {
+	if _, err := p.db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %v.relational_one (oid INT PRIMARY KEY, balance_one DECIMAL);", p.schema)); err != nil {
 		log.Fatalf("failed to create table relational_one: %v\n", err)
 	}
-	if _, err := p.db.Exec("CREATE TABLE IF NOT EXISTS dbbench.relational_two (balance_two DECIMAL, relation INT PRIMARY KEY, FOREIGN KEY(relation) REFERENCES dbbench.relational_one(oid));"); err != nil {
+	if _, err := p.db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %v.relational_two (balance_two DECIMAL, relation INT PRIMARY KEY, FOREIGN KEY(relation) REFERENCES %v.relational_one(oid));", p.schema, p.schema)); err != nil {
 		log.Fatalf("failed to create table relational_two: %v\n", err)
 	}
 }
 
-// Cleanup removes all remaining benchmarking data.
-func (p *Cockroach) Cleanup() {
-	if _, err := p.db.Exec("DROP TABLE dbbench.simple"); err != nil {
-		log.Printf("failed to drop table: %v\n", err)
-	}
-	if _, err := p.db.Exec("DROP TABLE dbbench.relational_two"); err != nil {
-		log.Printf("failed to drop table: %v\n", err)
-	}
-	if _, err := p.db.Exec("DROP TABLE dbbench.relational_one"); err != nil {
-		log.Printf("failed to drop table: %v\n", err)
+// SetWorkload sets the workload replacing the built-in benchmarks, e.g. WorkloadTPCC with the scale
+// as number of warehouses. It has to be set before the setup.
+func (p *Cockroach) SetWorkload(name string, scale int) (err error) {
+	p.tpcc, err = newWorkload(name, scale)
+	return err
+}
+
+// Seed inserts the seeded rows [from, to) into the table of the built-in benchmarks.
+func (p *Cockroach) Seed(from, to int) error {
+	for _, stmt := range cockroachDialect.in(p.schema).seedStmts("simple", from, to) {
+		if _, err := p.db.Exec(stmt); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	if _, err := p.db.Exec("DROP DATABASE dbbench"); err != nil {
-		log.Printf("failed to drop database: %v\n", err)
+// Cleanup removes all remaining benchmarking data. The database is dropped with all its objects,
+// when it was created by dbbench, otherwise only the benchmark tables are dropped.
+func (p *Cockroach) Cleanup() {
+	p.prepared.close() // they would lock the dropped tables
+	if p.created {
+		if _, err := p.db.Exec(fmt.Sprintf("DROP DATABASE %v CASCADE", p.schema)); err != nil {
+			log.Printf("failed to drop database: %v\n", err)
+		}
+	} else if p.tpcc != nil {
+		dropTPCC(p.db, cockroachDialect.in(p.schema))
+	} else {
+		for _, table := range []string{"simple", "relational_two", "relational_one"} {
+			if _, err := p.db.Exec(fmt.Sprintf("DROP TABLE %v.%v", p.schema, table)); err != nil {
+				log.Printf("failed to drop table: %v\n", err)
+			}
+		}
 	}
 	if err := p.db.Close(); err != nil {
 		log.Printf("failed to close connection: %v", err)
@@ -85,9 +130,51 @@ func (p *Cockroach) Cleanup() {
 }
 
 // Exec executes the given statement on the database.
-func (p *Cockroach) Exec(stmt string) {
+func (p *Cockroach) Exec(stmt string) error {
+	if isTx(stmt) {
+		return execTx(context.Background(), p.db, stmt)
+	}
 	_, err := p.db.Exec(stmt)
-	if err != nil {
-		log.Printf("%v failed: %v", stmt, err)
+	return err
+}
+
+// ExecContext executes the given statement, it's cancelled with the context.
+func (p *Cockroach) ExecContext(ctx context.Context, stmt string) error {
+	if isTx(stmt) {
+		return execTx(ctx, p.db, stmt)
 	}
+	_, err := p.db.ExecContext(ctx, stmt)
+	return err
+}
+
+// ExecTx executes the statements in a transaction, it's rolled back when a statement fails.
+func (p *Cockroach) ExecTx(ctx context.Context, stmts []string) error {
+	return execInTx(ctx, p.db, stmts)
+}
+
+// ExecArgs executes the given statement as prepared statement with the arguments bound to its placeholders.
+func (p *Cockroach) ExecArgs(ctx context.Context, stmt string, args ...interface{}) error {
+	return p.prepared.exec(ctx, p.db, sqlx.DOLLAR, stmt, args...)
+}
+
+// Query executes the given statement and returns the first column of the returned rows.
+func (p *Cockroach) Query(stmt string) ([]string, error) {
+	return p.QueryContext(context.Background(), stmt)
+}
+
+// QueryContext is like Query, the query is cancelled with the context.
+func (p *Cockroach) QueryContext(ctx context.Context, stmt string) ([]string, error) {
+	return queryFirstColumn(ctx, p.db, stmt)
+}
+
+// DB returns the connection pool, e.g. to use it with other database access layers.
+func (p *Cockroach) DB() *sql.DB {
+	return p.db
+}
+
+// ServerVersion returns the version of the node, e.g. "v23.2.3".
+func (p *Cockroach) ServerVersion() (string, error) {
+	var v string
+	err := p.db.QueryRow("SELECT value FROM crdb_internal.node_build_info WHERE field = 'Version'").Scan(&v)
+	return v, err
 }
@@ -0,0 +1,182 @@
+package databases
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// dialect describes how the statements of the built-in benchmarks differ between the databases,
+// so the benchmarks are defined only once and translated for each database.
+type dialect struct {
+	// schema is the namespace of the tables, e.g. the schema, database or keyspace.
+	schema string
+	// prefix is prepended to the table names.
+	prefix string
+	// limit restricts the select to return at most n rows.
+	limit func(stmt string, n int) string
+	// upsert returns the clause of an insert, which updates the cols of an existing row with the same key.
+	upsert func(key string, cols []string) string
+	// balance is the template of a random value fitting the balance column.
+	balance string
+	// batch is the max. number of rows inserted by a single statement when seeding the tables.
+	batch int
+	// conditional uses lightweight transactions (IF [NOT] EXISTS) for the modifications.
+	conditional bool
+	// seedDelete deletes the existing rows before seeding them again, as there are no upserts.
+	seedDelete bool
+}
+
+// defaultSchema is the namespace of the tables, unless another one is given.
+const defaultSchema = "dbbench"
+
+var (
+	postgresDialect = dialect{
+		schema:  defaultSchema,
+		limit:   limitClause,
+		upsert:  onConflict,
+		balance: "{{call .RandInt63}}",
+		batch:   1000,
+	}
+	cockroachDialect = postgresDialect
+	mysqlDialect     = dialect{
+		schema: defaultSchema,
+		limit:  limitClause,
+		upsert: onDuplicateKey,
+		// the default DECIMAL of mysql only holds 10 digits
+		balance: "{{call .RandInt63n 9999999999}}",
+		batch:   1000,
+	}
+	sqliteDialect = dialect{
+		prefix:  "dbbench_",
+		limit:   limitClause,
+		upsert:  onConflict,
+		balance: "{{call .RandInt63}}",
+		batch:   500, // max. compound select of older versions
+	}
+	cassandraDialect = dialect{
+		schema: defaultSchema,
+		prefix: "dbbench_",
+		limit:  limitClause,
+		// inserts of existing rows already overwrite them
+		upsert:  func(string, []string) string { return "" },
+		balance: "{{call .RandInt63}}",
+		batch:   1, // no multi-row inserts
+	}
+	mssqlDialect = dialect{
+		schema: defaultSchema,
+		limit:  topClause,
+		// upserts are MERGE statements, see MSSQL.Benchmarks
+		upsert:     func(string, []string) string { return "" },
+		balance:    "{{call .RandInt63}}",
+		batch:      1000, // max. rows of a VALUES list
+		seedDelete: true,
+	}
+	clickhouseDialect = dialect{
+		schema: defaultSchema,
+		limit:  limitClause,
+		// no unique keys, inserts of existing ids add rows
+		upsert:     func(string, []string) string { return "" },
+		balance:    "{{call .RandInt63}}",
+		batch:      10000,
+		seedDelete: true,
+	}
+)
+
+func limitClause(stmt string, n int) string {
+	return fmt.Sprintf("%v LIMIT %d", stmt, n)
+}
+
+func topClause(stmt string, n int) string {
+	return strings.Replace(stmt, "SELECT ", fmt.Sprintf("SELECT TOP %d ", n), 1)
+}
+
+func onConflict(key string, cols []string) string {
+	set := make([]string, len(cols))
+	for i, col := range cols {
+		set[i] = fmt.Sprintf("%v = excluded.%v", col, col)
+	}
+	return fmt.Sprintf(" ON CONFLICT (%v) DO UPDATE SET %v", key, strings.Join(set, ", "))
+}
+
+func onDuplicateKey(key string, cols []string) string {
+	set := make([]string, len(cols))
+	for i, col := range cols {
+		set[i] = fmt.Sprintf("%v = VALUES(%v)", col, col)
+	}
+	return " ON DUPLICATE KEY UPDATE " + strings.Join(set, ", ")
+}
+
+// in returns the dialect using the given schema, or the default one when it's empty.
+func (d dialect) in(schema string) dialect {
+	if schema != "" && d.schema != "" {
+		d.schema = schema
+	}
+	return d
+}
+
+// table returns the full name of the given table.
+func (d dialect) table(name string) string {
+	if d.schema == "" {
+		return d.prefix + name
+	}
+	return d.schema + "." + d.prefix + name
+}
+
+// cond returns the lightweight transaction clause, when the dialect uses them.
+func (d dialect) cond(clause string) string {
+	if !d.conditional {
+		return ""
+	}
+	return " " + clause
+}
+
+// insert returns the statement inserting a new row with a random balance.
+func (d dialect) insert(table string) string {
+	return fmt.Sprintf("INSERT INTO %v (id, balance) VALUES({{.Iter}}, %v)%v;", d.table(table), d.balance, d.cond("IF NOT EXISTS"))
+}
+
+// upsertStmt returns the statement inserting a row or updating the balance of the existing one.
+func (d dialect) upsertStmt(table string) string {
+	return fmt.Sprintf("INSERT INTO %v (id, balance) VALUES({{.Iter}}, %v)%v;", d.table(table), d.balance, d.upsert("id", []string{"balance"}))
+}
+
+// selectKey returns the statement selecting the row of a key, see the Key template function.
+func (d dialect) selectKey(table string) string {
+	return fmt.Sprintf("SELECT * FROM %v WHERE id = {{.Key}};", d.table(table))
+}
+
+// scan returns the statement selecting n rows.
+func (d dialect) scan(table string, n int) string {
+	return d.limit(fmt.Sprintf("SELECT * FROM %v", d.table(table)), n) + ";"
+}
+
+// update returns the statement updating the balance of the current iteration's row.
+func (d dialect) update(table string) string {
+	return fmt.Sprintf("UPDATE %v SET balance = %v WHERE id = {{.Iter}}%v;", d.table(table), d.balance, d.cond("IF EXISTS"))
+}
+
+// delete returns the statement deleting the row of the current iteration.
+func (d dialect) delete(table string) string {
+	return fmt.Sprintf("DELETE FROM %v WHERE id = {{.Iter}}%v;", d.table(table), d.cond("IF EXISTS"))
+}
+
+// tags of the built-in benchmarks
+var (
+	readTags     = []string{benchmark.TagRead}
+	writeTags    = []string{benchmark.TagWrite}
+	bulkReadTags = []string{benchmark.TagRead, benchmark.TagBulk}
+)
+
+// builtins returns the built-in benchmarks translated to the dialect.
+func builtins(d dialect) []benchmark.Benchmark {
+	return []benchmark.Benchmark{
+		{Name: "inserts", Type: benchmark.TypeLoop, Stmt: d.insert("simple"), Tags: writeTags},
+		{Name: "upserts", Type: benchmark.TypeLoop, Stmt: d.upsertStmt("simple"), Tags: writeTags},
+		{Name: "selects", Type: benchmark.TypeLoop, Stmt: d.selectKey("simple"), Tags: readTags},
+		{Name: "scans", Type: benchmark.TypeLoop, Stmt: d.scan("simple", 100), Tags: bulkReadTags},
+		{Name: "updates", Type: benchmark.TypeLoop, Stmt: d.update("simple"), Tags: writeTags},
+		{Name: "deletes", Type: benchmark.TypeLoop, Stmt: d.delete("simple"), Tags: writeTags},
+	}
+}
@@ -0,0 +1,107 @@
+package databases
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// GraphQL implements the bencher interface. The statements are GraphQL documents,
+// which are posted to the endpoint of a GraphQL layer backed by a database (e.g. Hasura).
+type GraphQL struct {
+	client  *http.Client
+	url     string
+	headers map[string]string
+}
+
+// graphQLResponse contains the errors of a GraphQL response, the data is ignored.
+type graphQLResponse struct {
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// NewGraphQL returns a new GraphQL bencher. The headers are sent with each request, e.g. for authentication.
+func NewGraphQL(url string, headers map[string]string, maxConns int) (*GraphQL, error) {
+	transport := &http.Transport{MaxIdleConnsPerHost: maxConns, MaxConnsPerHost: maxConns}
+	if maxConns == 0 {
+		// keep the connections of all threads open
+		transport.MaxIdleConnsPerHost = 1024
+	}
+	g := &GraphQL{
+		client:  &http.Client{Transport: transport, Timeout: 5 * time.Minute},
+		url:     url,
+		headers: headers,
+	}
+
+	// every GraphQL schema can resolve __typename
+	if err := g.query("{ __typename }"); err != nil {
+		return nil, fmt.Errorf("failed to ping endpoint: %v", err)
+	}
+	return g, nil
+}
+
+// Benchmarks returns the individual benchmark statements for GraphQL.
+// There are no built-in benchmarks, as they depend on the schema, use your own script.
+func (g *GraphQL) Benchmarks() []benchmark.Benchmark {
+	return []benchmark.Benchmark{}
+}
+
+// Setup initializes the database for the benchmark.
+// The schema is managed by the GraphQL layer, use your own script.
+func (g *GraphQL) Setup() {
+}
+
+// Cleanup removes all remaining benchmarking data.
+func (g *GraphQL) Cleanup() {
+}
+
+// Exec posts the given GraphQL document to the endpoint.
+func (g *GraphQL) Exec(stmt string) error {
+	return g.query(stmt)
+}
+
+// query posts the document and returns the errors of the response.
+func (g *GraphQL) query(document string) error {
+	body, err := json.Marshal(map[string]string{"query": document})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, g.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range g.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dat, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%v: %s", resp.Status, dat)
+	}
+
+	var result graphQLResponse
+	if err := json.Unmarshal(dat, &result); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("%v", result.Errors[0].Message)
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package databases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/sj14/dbbench/benchmark"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDB implements the bencher interface. The statements are database commands
+// in extended JSON, e.g. {"find": "dbbench_simple", "filter": {"_id": 1}}.
+type MongoDB struct {
+	client  *mongo.Client
+	db      *mongo.Database
+	created bool // database was created by dbbench
+}
+
+// mongoCollection is the collection of the built-in benchmarks.
+const mongoCollection = "dbbench_simple"
+
+// mongoTimeout limits the duration of a single command.
+const mongoTimeout = 5 * time.Minute
+
+// NewMongoDB returns a new MongoDB bencher. The collections are created in the given database,
+// which is dropped when it was created by dbbench. No credentials are sent, when user is empty.
+func NewMongoDB(host string, port int, user, password, database string, maxConns int) (*MongoDB, error) {
+	if port == 0 {
+		port = 27017
+	}
+	if database == "" {
+		database = defaultSchema
+	}
+
+	u := &url.URL{Scheme: "mongodb", Host: fmt.Sprintf("%v:%v", host, port)}
+	if user != "" {
+		u.User = url.UserPassword(user, password)
+	}
+	opts := options.Client().ApplyURI(u.String())
+	if maxConns > 0 {
+		opts.SetMaxPoolSize(uint64(maxConns))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %v", err)
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		return nil, fmt.Errorf("failed to ping db: %v", err)
+	}
+	return &MongoDB{client: client, db: client.Database(database)}, nil
+}
+
+// Benchmarks returns the individual benchmark commands for MongoDB.
+func (m *MongoDB) Benchmarks() []benchmark.Benchmark {
+	const balance = `{"$set": {"balance": {{call .RandInt63}}}}`
+	return []benchmark.Benchmark{
+		{Name: "inserts", Type: benchmark.TypeLoop, Stmt: `{"insert": "` + mongoCollection + `", "documents": [{"_id": {{.Iter}}, "balance": {{call .RandInt63}}}]}`, Tags: writeTags},
+		{Name: "upserts", Type: benchmark.TypeLoop, Stmt: `{"update": "` + mongoCollection + `", "updates": [{"q": {"_id": {{.Iter}}}, "u": ` + balance + `, "upsert": true}]}`, Tags: writeTags},
+		{Name: "selects", Type: benchmark.TypeLoop, Stmt: `{"find": "` + mongoCollection + `", "filter": {"_id": {{.Key}}}}`, Tags: readTags},
+		{Name: "scans", Type: benchmark.TypeLoop, Stmt: `{"find": "` + mongoCollection + `", "limit": 100}`, Tags: bulkReadTags},
+		{Name: "updates", Type: benchmark.TypeLoop, Stmt: `{"update": "` + mongoCollection + `", "updates": [{"q": {"_id": {{.Iter}}}, "u": ` + balance + `}]}`, Tags: writeTags},
+		{Name: "deletes", Type: benchmark.TypeLoop, Stmt: `{"delete": "` + mongoCollection + `", "deletes": [{"q": {"_id": {{.Iter}}}, "limit": 1}]}`, Tags: writeTags},
+	}
+}
+
+// Setup initializes the database for the benchmark.
+func (m *MongoDB) Setup() {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	names, err := m.client.ListDatabaseNames(ctx, bson.D{{Key: "name", Value: m.db.Name()}})
+	if err != nil {
+		log.Fatalf("failed to list databases: %v\n", err)
+	}
+	m.created = len(names) == 0
+
+	if _, err := m.db.Collection(mongoCollection).DeleteMany(ctx, bson.D{}); err != nil {
+		log.Fatalf("failed to truncate collection: %v\n", err)
+	}
+}
+
+// Seed inserts the seeded documents [from, to) into the collection of the built-in benchmarks.
+// Existing documents of the range are deleted before, e.g. of an interrupted seed.
+func (m *MongoDB) Seed(from, to int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	coll := m.db.Collection(mongoCollection)
+	filter := bson.M{"_id": bson.M{"$gte": int64(seedOffset + from), "$lt": int64(seedOffset + to)}}
+	if _, err := coll.DeleteMany(ctx, filter); err != nil {
+		return err
+	}
+	const batch = 1000
+	for i := from; i < to; i += batch {
+		var docs []interface{}
+		for j := i; j < i+batch && j < to; j++ {
+			// the same balance as seeded into the tables of the other databases
+			id := int64(seedOffset + j)
+			docs = append(docs, bson.D{{Key: "_id", Value: id}, {Key: "balance", Value: int64(benchmark.Hash("balance", id) % 1e9)}})
+		}
+		if _, err := coll.InsertMany(ctx, docs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cleanup removes all remaining benchmarking data. The database is dropped with all its collections,
+// when it was created by dbbench, otherwise only the benchmark collection is dropped.
+func (m *MongoDB) Cleanup() {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	if m.created {
+		if err := m.db.Drop(ctx); err != nil {
+			log.Printf("failed to drop database: %v\n", err)
+		}
+	} else if err := m.db.Collection(mongoCollection).Drop(ctx); err != nil {
+		log.Printf("failed to drop collection: %v\n", err)
+	}
+	if err := m.client.Disconnect(ctx); err != nil {
+		log.Printf("failed to close connection: %v\n", err)
+	}
+}
+
+// Exec runs the given command on the database. Write errors, e.g. of duplicate keys,
+// are returned like failed commands, although the server reports the command as successful.
+func (m *MongoDB) Exec(stmt string) error {
+	reply, err := m.run(stmt)
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		WriteErrors []struct {
+			Errmsg string `bson:"errmsg"`
+		} `bson:"writeErrors"`
+	}
+	if err := bson.Unmarshal(reply, &result); err != nil {
+		return err
+	}
+	if len(result.WriteErrors) > 0 {
+		return fmt.Errorf("write error: %v", result.WriteErrors[0].Errmsg)
+	}
+	return nil
+}
+
+// Query runs the given command and returns the first field of the returned documents, e.g. the _id
+// of the first batch of a find. Commands without a cursor return the first field of their reply,
+// e.g. n of {"count": "dbbench_simple"}.
+func (m *MongoDB) Query(stmt string) ([]string, error) {
+	reply, err := m.run(stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := []bson.Raw{reply}
+	if batch, err := reply.LookupErr("cursor", "firstBatch"); err == nil {
+		values, _ := batch.Array().Values()
+		docs = docs[:0]
+		for _, v := range values {
+			docs = append(docs, v.Document())
+		}
+	}
+
+	var result []string
+	for _, doc := range docs {
+		elems, err := doc.Elements()
+		if err != nil || len(elems) == 0 {
+			continue
+		}
+		result = append(result, mongoString(elems[0].Value()))
+	}
+	return result, nil
+}
+
+// Size returns the bytes of the storage and the indexes of the collection of the built-in benchmarks.
+func (m *MongoDB) Size() (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	var stats struct {
+		StorageSize    int64 `bson:"storageSize"`
+		TotalIndexSize int64 `bson:"totalIndexSize"`
+	}
+	if err := m.db.RunCommand(ctx, bson.D{{Key: "collStats", Value: mongoCollection}}).Decode(&stats); err != nil {
+		return 0, err
+	}
+	return stats.StorageSize + stats.TotalIndexSize, nil
+}
+
+// run parses the command from extended JSON and runs it.
+func (m *MongoDB) run(stmt string) (bson.Raw, error) {
+	var cmd bson.D // keeps the order, the command name has to be first
+	if err := bson.UnmarshalExtJSON([]byte(stmt), false, &cmd); err != nil {
+		return nil, fmt.Errorf("invalid command: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+	return m.db.RunCommand(ctx, cmd).Raw()
+}
+
+// mongoString formats the value like it's written in a command, e.g. the hex of an ObjectID.
+func mongoString(v bson.RawValue) string {
+	var value interface{}
+	if err := v.Unmarshal(&value); err != nil {
+		return v.String()
+	}
+	if id, ok := value.(primitive.ObjectID); ok {
+		return id.Hex()
+	}
+	return fmt.Sprint(value)
+}
@@ -1,63 +1,169 @@
 package databases
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"net/url"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/sj14/dbbench/benchmark"
 )
 
 // MSSQL implements the bencher interface.
 type MSSQL struct {
-	db *sql.DB
+	db       *sql.DB
+	prepared preparedStmts // see ExecArgs
+	schema   string
+	created  bool   // schema was created by dbbench
+	url      string // of the pooled connections, see ExecFreshConn
 }
 
-// NewMSSQL returns a new MS SQL bencher.
-func NewMSSQL(host string, port int, user, password string, maxOpenConns int) *MSSQL {
+// NewMSSQL returns a new MS SQL bencher. All tables are created in the given schema of the default
+// database of the user, which is dropped when it was created by dbbench. With an instance name,
+// the port is resolved by the SQL Server Browser. Encrypt is "true", "false" (only the login)
+// or "disable", the driver default is used when it's empty.
+func NewMSSQL(host string, port int, instance, user, password, schema string, pool Pool, encrypt string, trustCert bool) (*MSSQL, error) {
 	if port == 0 {
 		port = 1433
 	}
+	if schema == "" {
+		schema = defaultSchema
+	}
 
 	u := &url.URL{
 		Scheme: "sqlserver",
 		User:   url.UserPassword(user, password),
 		Host:   fmt.Sprintf("%s:%d", host, port),
-		// Path:  instance, // TODO: when connecting to an instance instead of a port
 	}
+	if instance != "" {
+		u.Host = host
+		u.Path = instance
+	}
+	params := url.Values{}
+	if encrypt != "" {
+		params.Set("encrypt", encrypt)
+	}
+	if trustCert {
+		params.Set("TrustServerCertificate", "true")
+	}
+	u.RawQuery = params.Encode()
 
 	db, err := sql.Open("sqlserver", u.String())
 	if err != nil {
-		log.Fatalf("failed to open connection: %v\n", err)
+		return nil, fmt.Errorf("failed to open connection: %v", err)
 	}
 	if err := db.Ping(); err != nil {
-		log.Fatalf("failed to ping db: %v", err)
+		return nil, fmt.Errorf("failed to ping db: %v", err)
 	}
 
-	db.SetMaxOpenConns(maxOpenConns)
-	p := &MSSQL{db: db}
-	return p
+	pool.apply(db)
+	return &MSSQL{db: db, schema: schema, url: u.String()}, nil
 }
 
-// Benchmarks returns the individual benchmark functions for the mysql db.
+// ExecFreshConn connects like the pooled connections, executes the statement and disconnects.
+func (m *MSSQL) ExecFreshConn(ctx context.Context, stmt string) error {
+	return execConnected(ctx, "sqlserver", m.url, stmt)
+}
+
+// Benchmarks returns the individual benchmark functions for the mssql db.
 func (m *MSSQL) Benchmarks() []benchmark.Benchmark {
-	log.Fatal("no built-in benchmarks for MS SQL available yet, use your own script")
-	return []benchmark.Benchmark{}
+	d := mssqlDialect.in(m.schema)
+	benchmarks := builtins(d)
+	for i, b := range benchmarks {
+		if b.Name == "upserts" {
+			// no INSERT ... ON CONFLICT in T-SQL
+			benchmarks[i].Stmt = fmt.Sprintf("MERGE %v AS t USING (VALUES ({{.Iter}}, %v)) AS s (id, balance) ON t.id = s.id "+
+				"WHEN MATCHED THEN UPDATE SET balance = s.balance WHEN NOT MATCHED THEN INSERT (id, balance) VALUES (s.id, s.balance);", d.table("simple"), d.balance)
+		}
+	}
+	return benchmarks
 }
 
 // Setup initializes the database for the benchmark.
 func (m *MSSQL) Setup() {
+	m.created = !exists(m.db, "SELECT 1 FROM sys.schemas WHERE name = @p1", m.schema)
+
+	// CREATE SCHEMA has to be the only statement of its batch
+	if _, err := m.db.Exec(fmt.Sprintf("IF SCHEMA_ID('%v') IS NULL EXEC('CREATE SCHEMA %v')", m.schema, m.schema)); err != nil {
+		log.Fatalf("failed to create schema: %v\n", err)
+	}
+	if _, err := m.db.Exec(fmt.Sprintf("IF OBJECT_ID('%v.simple') IS NULL CREATE TABLE %v.simple (id INT PRIMARY KEY, balance DECIMAL(19));", m.schema, m.schema)); err != nil {
+		log.Fatalf("failed to create table: %v\n", err)
+	}
+	if _, err := m.db.Exec(fmt.Sprintf("TRUNCATE TABLE %v.simple;", m.schema)); err != nil {
+		log.Fatalf("failed to truncate table: %v\n", err)
+	}
+}
+
+// Seed inserts the seeded rows [from, to) into the table of the built-in benchmarks.
+func (m *MSSQL) Seed(from, to int) error {
+	for _, stmt := range mssqlDialect.in(m.schema).seedStmts("simple", from, to) {
+		if _, err := m.db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// Cleanup removes all remaining benchmarking data.
+// Cleanup removes all remaining benchmarking data. The benchmark table is dropped and the schema too,
+// when it was created by dbbench.
 func (m *MSSQL) Cleanup() {
+	m.prepared.close() // they would lock the dropped tables
+	if _, err := m.db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %v.simple", m.schema)); err != nil {
+		log.Printf("failed to drop table: %v\n", err)
+	}
+	if m.created {
+		if _, err := m.db.Exec(fmt.Sprintf("DROP SCHEMA %v", m.schema)); err != nil {
+			log.Printf("failed to drop schema: %v\n", err)
+		}
+	}
+	if err := m.db.Close(); err != nil {
+		log.Printf("failed to close connection: %v", err)
+	}
 }
 
 // Exec executes the given statement on the database.
-func (m *MSSQL) Exec(stmt string) {
+func (m *MSSQL) Exec(stmt string) error {
 	_, err := m.db.Exec(stmt)
-	if err != nil {
-		log.Printf("%v failed: %v", stmt, err)
-	}
+	return err
+}
+
+// ExecContext executes the given statement, it's cancelled with the context.
+func (m *MSSQL) ExecContext(ctx context.Context, stmt string) error {
+	_, err := m.db.ExecContext(ctx, stmt)
+	return err
+}
+
+// ExecTx executes the statements in a transaction, it's rolled back when a statement fails.
+func (m *MSSQL) ExecTx(ctx context.Context, stmts []string) error {
+	return execInTx(ctx, m.db, stmts)
+}
+
+// ExecArgs executes the given statement as prepared statement with the arguments bound to its placeholders.
+func (m *MSSQL) ExecArgs(ctx context.Context, stmt string, args ...interface{}) error {
+	return m.prepared.exec(ctx, m.db, sqlx.AT, stmt, args...)
+}
+
+// Query executes the given statement and returns the first column of the returned rows.
+func (m *MSSQL) Query(stmt string) ([]string, error) {
+	return m.QueryContext(context.Background(), stmt)
+}
+
+// QueryContext is like Query, the query is cancelled with the context.
+func (m *MSSQL) QueryContext(ctx context.Context, stmt string) ([]string, error) {
+	return queryFirstColumn(ctx, m.db, stmt)
+}
+
+// DB returns the connection pool, e.g. to use it with other database access layers.
+func (m *MSSQL) DB() *sql.DB {
+	return m.db
+}
+
+// ServerVersion returns the product version of the server, e.g. "16.0.4105.2".
+func (m *MSSQL) ServerVersion() (string, error) {
+	var v string
+	err := m.db.QueryRow("SELECT CAST(SERVERPROPERTY('ProductVersion') AS NVARCHAR(128))").Scan(&v)
+	return v, err
 }
@@ -1,80 +1,189 @@
 package databases
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
 	"github.com/sj14/dbbench/benchmark"
 )
 
 // Mysql implements the bencher interface.
 type Mysql struct {
-	db *sql.DB
+	db       *sql.DB
+	prepared preparedStmts // see ExecArgs
+	host     string
+	port     int
+	user     string // of the pooled connections, see ExecFreshConn
+	password string
+	schema   string
+	created  bool // database was created by dbbench
+	auth     authUsers
+	selected selection         // see SelectBenchmarks
+	settings map[string]string // system variables of each connection
+	tls      bool              // connections use the TLS config mysqlTLS
 }
 
+// mysqlTLS is the name of the registered TLS config of the driver.
+const mysqlTLS = "dbbench"
+
+// mysqlAuthMethods are the benchmarked authentication plugins.
+var mysqlAuthMethods = []authMethod{{"native", "mysql_native_password"}, {"sha2", "caching_sha2_password"}}
+
 // NewMySQL returns a new mysql bencher.
-func NewMySQL(host string, port int, user, password string, maxOpenConns int) *Mysql {
+// All tables are created in the given database, which is dropped when it was created by dbbench.
+// The settings are applied to each connection as system variables, e.g. unique_checks.
+func NewMySQL(host string, port int, user, password, schema string, pool Pool, tls TLS, settings map[string]string) (*Mysql, error) {
 	if port == 0 {
 		port = 3306
 	}
-	// username:password@protocol(address)/dbname?param=value
-	dataSourceName := fmt.Sprintf("%v:%v@tcp(%v:%v)/", user, password, host, port)
+	if schema == "" {
+		schema = defaultSchema
+	}
+	m := &Mysql{host: host, port: port, user: user, password: password, schema: schema, settings: settings}
 
-	db, err := sql.Open("mysql", dataSourceName)
+	cfg, err := tls.config(host)
 	if err != nil {
-		log.Fatalf("failed to open connection: %v\n", err)
+		return nil, err
+	}
+	if cfg != nil {
+		if err := mysql.RegisterTLSConfig(mysqlTLS, cfg); err != nil {
+			return nil, err
+		}
+		m.tls = true
+	}
+
+	db, err := sql.Open("mysql", m.dataSourceName(user, password))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connection: %v", err)
 	}
 	if err := db.Ping(); err != nil {
-		log.Fatalf("failed to ping db: %v", err)
+		return nil, fmt.Errorf("failed to ping db: %v", err)
 	}
 
-	db.SetMaxOpenConns(maxOpenConns)
-	p := &Mysql{db: db}
-	return p
+	pool.apply(db)
+	m.db = db
+	return m, nil
+}
+
+// dataSourceName returns the connection string of the user.
+func (m *Mysql) dataSourceName(user, password string) string {
+	// username:password@protocol(address)/dbname?param=value
+	dataSourceName := fmt.Sprintf("%v:%v@%v(%v:%v)/", user, password, mysqlWire, m.host, m.port) + systemVars(m.settings)
+	if !m.tls {
+		return dataSourceName
+	}
+	if len(m.settings) == 0 {
+		return dataSourceName + "?tls=" + mysqlTLS
+	}
+	return dataSourceName + "&tls=" + mysqlTLS
 }
 
 // Benchmarks returns the individual benchmark functions for the mysql db.
 func (m *Mysql) Benchmarks() []benchmark.Benchmark {
-	return []benchmark.Benchmark{
-		{Name: "inserts", Type: benchmark.TypeLoop, Stmt: "INSERT INTO dbbench.simple (id, balance) VALUES( {{.Iter}}, {{call .RandInt63n 9999999999}});"},
-		{Name: "selects", Type: benchmark.TypeLoop, Stmt: "SELECT * FROM dbbench.simple WHERE id = {{.Iter}};"},
-		{Name: "updates", Type: benchmark.TypeLoop, Stmt: "UPDATE dbbench.simple SET balance = {{call .RandInt63n 9999999999}} WHERE id = {{.Iter}};"},
-		{Name: "deletes", Type: benchmark.TypeLoop, Stmt: "DELETE FROM dbbench.simple WHERE id = {{.Iter}};"},
-		// {"relation_insert0", benchmark.TypeLoop, "INSERT INTO dbbench.relational_one (oid, balance_one) VALUES( {{.Iter}}, {{call .RandInt63n 9999999999}});"},
-		// {"relation_insert1", benchmark.TypeLoop, "INSERT INTO dbbench.relational_two (relation, balance_two) VALUES( {{.Iter}}, {{call .RandInt63n 9999999999}});"},
-		// {"relation_select", benchmark.TypeLoop, "SELECT * FROM dbbench.relational_two INNER JOIN dbbench.relational_one ON relational_one.oid = relational_two.relation WHERE relation = {{.Iter}};"},
-		// {"relation_delete1", benchmark.TypeLoop, "DELETE FROM dbbench.relational_two WHERE relation = {{.Iter}};"},
-		// {"relation_delete0", benchmark.TypeLoop, "DELETE FROM dbbench.relational_one WHERE oid = {{.Iter}};"},
-	}
+	return append(builtins(mysqlDialect.in(m.schema)), m.auth.benchmarks(mysqlAuthMethods)...)
 }
 
 // Setup initializes the database for the benchmark.
 func (m *Mysql) Setup() {
-	if _, err := m.db.Exec("CREATE DATABASE IF NOT EXISTS dbbench"); err != nil {
+	m.created = !exists(m.db, "SELECT 1 FROM information_schema.schemata WHERE schema_name = ?", m.schema)
+
+	if _, err := m.db.Exec(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %v", m.schema)); err != nil {
 		log.Fatalf("failed to create database: %v\n", err)
 	}
-	if _, err := m.db.Exec("USE dbbench"); err != nil {
-		log.Fatalf("failed to USE dbbench: %v\n", err)
+	if _, err := m.db.Exec(fmt.Sprintf("USE %v", m.schema)); err != nil {
+		log.Fatalf("failed to USE %v: %v\n", m.schema, err)
 	}
-	if _, err := m.db.Exec("CREATE TABLE IF NOT EXISTS dbbench.simple (id INT PRIMARY KEY, balance DECIMAL);"); err != nil {
+	if _, err := m.db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %v.simple (id INT PRIMARY KEY, balance DECIMAL);", m.schema)); err != nil {
 		log.Fatalf("failed to create table: %v\n", err)
 	}
-	if _, err := m.db.Exec("CREATE TABLE IF NOT EXISTS dbbench.relational_one (oid INT PRIMARY KEY, balance_one DECIMAL);"); err != nil {
+	if _, err := m.db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %v.relational_one (oid INT PRIMARY KEY, balance_one DECIMAL);", m.schema)); err != nil {
 		log.Fatalf("failed to create table relational_one: %v\n", err)
 	}
-	if _, err := m.db.Exec("CREATE TABLE IF NOT EXISTS dbbench.relational_two (balance_two DECIMAL, relation INT PRIMARY KEY, FOREIGN KEY(relation) REFERENCES relational_one(oid));"); err != nil {
+	if _, err := m.db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %v.relational_two (balance_two DECIMAL, relation INT PRIMARY KEY, FOREIGN KEY(relation) REFERENCES relational_one(oid));", m.schema)); err != nil {
 		log.Fatalf("failed to create table relational_two: %v\n", err)
 	}
-	if _, err := m.db.Exec("TRUNCATE dbbench.simple;"); err != nil {
+	if _, err := m.db.Exec(fmt.Sprintf("TRUNCATE %v.simple;", m.schema)); err != nil {
 		log.Fatalf("failed to truncate table: %v\n", err)
 	}
+	m.setupAuth()
+}
+
+// SelectBenchmarks sets the names of the benchmarks selected to run, the setup only creates the
+// users of the selected authentication benchmarks. It has to be set before the setup.
+func (m *Mysql) SelectBenchmarks(names []string) {
+	m.selected = newSelection(names)
+}
+
+// setupAuth creates a user for each selected authentication plugin. The plugins, whose user exists
+// already, which aren't available (e.g. caching_sha2_password of MariaDB) or fail to connect, are skipped.
+func (m *Mysql) setupAuth() {
+	m.auth = authUsers{password: randomPassword(), skip: map[string]string{}}
+	for _, method := range mysqlAuthMethods {
+		if !m.selected[method.benchmark()] {
+			m.auth.skip[method.method] = "not selected"
+			continue
+		}
+		user := m.auth.user(m.schema, method)
+		if exists(m.db, "SELECT 1 FROM mysql.user WHERE user = ?", user) {
+			m.auth.skip[method.method] = fmt.Sprintf("user %v exists already, it's not replaced", user)
+			continue
+		}
+		if _, err := m.db.Exec(fmt.Sprintf("CREATE USER '%v'@'%%' IDENTIFIED WITH %v BY '%v'", user, method.method, m.auth.password)); err != nil {
+			m.auth.skip[method.method] = fmt.Sprintf("failed to create user: %v", err)
+			continue
+		}
+		m.auth.created = append(m.auth.created, user)
+
+		if err := execConnected(context.Background(), "mysql", m.dataSourceName(user, m.auth.password), "SELECT 1"); err != nil {
+			m.auth.skip[method.method] = fmt.Sprintf("failed to connect: %v", err)
+		}
+	}
+}
+
+// ExecAuth connects with the authentication plugin, executes the statement and disconnects.
+func (m *Mysql) ExecAuth(ctx context.Context, name, stmt string) error {
+	user := m.auth.user(m.schema, method(mysqlAuthMethods, name))
+	return execConnected(ctx, "mysql", m.dataSourceName(user, m.auth.password), stmt)
+}
+
+// ExecFreshConn connects like the pooled connections, executes the statement and disconnects.
+func (m *Mysql) ExecFreshConn(ctx context.Context, stmt string) error {
+	return execConnected(ctx, "mysql", m.dataSourceName(m.user, m.password), stmt)
+}
+
+// Seed inserts the seeded rows [from, to) into the table of the built-in benchmarks.
+func (m *Mysql) Seed(from, to int) error {
+	for _, stmt := range mysqlDialect.in(m.schema).seedStmts("simple", from, to) {
+		if _, err := m.db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// Cleanup removes all remaining benchmarking data.
+// Cleanup removes all remaining benchmarking data. The database is dropped with all its objects,
+// when it was created by dbbench, otherwise only the benchmark tables are dropped.
 func (m *Mysql) Cleanup() {
-	if _, err := m.db.Exec("DROP DATABASE dbbench"); err != nil {
-		log.Printf("failed drop schema: %v\n", err)
+	m.prepared.close() // they would lock the dropped tables
+	if m.created {
+		if _, err := m.db.Exec(fmt.Sprintf("DROP DATABASE %v", m.schema)); err != nil {
+			log.Printf("failed drop schema: %v\n", err)
+		}
+	} else {
+		for _, table := range []string{"simple", "relational_two", "relational_one"} {
+			if _, err := m.db.Exec(fmt.Sprintf("DROP TABLE %v.%v", m.schema, table)); err != nil {
+				log.Printf("failed to drop table: %v\n", err)
+			}
+		}
+	}
+	for _, user := range m.auth.created {
+		if _, err := m.db.Exec(fmt.Sprintf("DROP USER '%v'@'%%'", user)); err != nil {
+			log.Printf("failed to drop user: %v\n", err)
+		}
 	}
 	if err := m.db.Close(); err != nil {
 		log.Printf("failed to close connection: %v", err)
@@ -82,9 +191,77 @@ func (m *Mysql) Cleanup() {
 }
 
 // Exec executes the given statement on the database.
-func (m *Mysql) Exec(stmt string) {
+func (m *Mysql) Exec(stmt string) error {
 	_, err := m.db.Exec(stmt)
+	return err
+}
+
+// ExecContext executes the given statement, it's cancelled with the context.
+func (m *Mysql) ExecContext(ctx context.Context, stmt string) error {
+	_, err := m.db.ExecContext(ctx, stmt)
+	return err
+}
+
+// ExecTx executes the statements in a transaction, it's rolled back when a statement fails.
+func (m *Mysql) ExecTx(ctx context.Context, stmts []string) error {
+	return execInTx(ctx, m.db, stmts)
+}
+
+// ExecArgs executes the given statement as prepared statement with the arguments bound to its placeholders.
+func (m *Mysql) ExecArgs(ctx context.Context, stmt string, args ...interface{}) error {
+	return m.prepared.exec(ctx, m.db, sqlx.QUESTION, stmt, args...)
+}
+
+// Query executes the given statement and returns the first column of the returned rows.
+func (m *Mysql) Query(stmt string) ([]string, error) {
+	return m.QueryContext(context.Background(), stmt)
+}
+
+// QueryContext is like Query, the query is cancelled with the context.
+func (m *Mysql) QueryContext(ctx context.Context, stmt string) ([]string, error) {
+	return queryFirstColumn(ctx, m.db, stmt)
+}
+
+// DB returns the connection pool, e.g. to use it with other database access layers.
+func (m *Mysql) DB() *sql.DB {
+	return m.db
+}
+
+// HoldTx starts a transaction, whose read view prevents the purge of all undo logs created
+// until release is called. It occupies a connection of the pool.
+func (m *Mysql) HoldTx() (release func(), err error) {
+	conn, err := m.db.Conn(context.Background())
 	if err != nil {
-		log.Printf("%v failed: %v", stmt, err)
+		return nil, fmt.Errorf("failed to get connection: %v", err)
+	}
+	if _, err := conn.ExecContext(context.Background(), "START TRANSACTION WITH CONSISTENT SNAPSHOT"); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
 	}
+	return func() {
+		conn.ExecContext(context.Background(), "ROLLBACK")
+		conn.Close()
+	}, nil
+}
+
+// ServerVersion returns the version of the server, e.g. "8.0.36".
+func (m *Mysql) ServerVersion() (string, error) {
+	var v string
+	err := m.db.QueryRow("SELECT VERSION()").Scan(&v)
+	return v, err
+}
+
+// Size returns the bytes used by the tables of the schema, including their indexes. InnoDB updates
+// the statistics asynchronously.
+func (m *Mysql) Size() (int64, error) {
+	var size int64
+	err := m.db.QueryRow("SELECT coalesce(sum(data_length + index_length), 0) FROM information_schema.tables WHERE table_schema = ?", m.schema).Scan(&size)
+	return size, err
+}
+
+// Bloat returns the history list length of InnoDB, the number of undo logs waiting to be purged.
+func (m *Mysql) Bloat() (int64, error) {
+	var length int64
+	err := m.db.QueryRow("SELECT count FROM information_schema.innodb_metrics WHERE name = 'trx_rseg_history_len'").Scan(&length)
+	return length, err
 }
@@ -0,0 +1,210 @@
+package databases
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// Plugin implements the bencher interface for databases, which are not built into dbbench, e.g. with
+// a proprietary client library. The plugin is an executable in any language, which is started once and
+// receives the requests as JSON lines on stdin and writes the responses as JSON lines to stdout, see
+// pluginRequest and pluginResponse. The requests of the threads are sent concurrently, the responses
+// are matched by their id, so the plugin may answer them in any order. It should exit when stdin is closed.
+type Plugin struct {
+	cmd        *exec.Cmd
+	benchmarks []benchmark.Benchmark
+
+	mu      sync.Mutex // guards the writes to stdin and the pending requests
+	stdin   io.WriteCloser
+	enc     *json.Encoder
+	nextID  uint64
+	pending map[uint64]chan pluginResponse
+	err     error // set when the plugin exited, all following requests fail with it
+}
+
+// pluginRequest is a request to the plugin. The methods are:
+//
+//	open:       connect with the options, it's the first request
+//	benchmarks: return the built-in benchmarks (may be empty)
+//	setup:      create the tables of the built-in benchmarks
+//	cleanup:    remove the benchmarking data
+//	exec:       execute the statement
+//	query:      execute the statement and return the first column of its rows, see benchmark.Querier
+type pluginRequest struct {
+	ID      uint64            `json:"id"`
+	Method  string            `json:"method"`
+	Stmt    string            `json:"stmt,omitempty"`
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// pluginResponse is the response to the request with the same id, the error is empty on success.
+type pluginResponse struct {
+	ID         uint64            `json:"id"`
+	Error      string            `json:"error,omitempty"`
+	Benchmarks []pluginBenchmark `json:"benchmarks,omitempty"`
+	Rows       []string          `json:"rows,omitempty"`
+}
+
+// pluginBenchmark is a built-in benchmark of the plugin.
+type pluginBenchmark struct {
+	Name string   `json:"name"`
+	Type string   `json:"type,omitempty"` // loop (default) or once
+	Stmt string   `json:"stmt"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+// NewPlugin starts the plugin command, connects it with the options and returns it as bencher.
+// The stderr of the plugin is passed through, e.g. for its logs.
+func NewPlugin(command []string, options map[string]string) (*Plugin, error) {
+	if len(command) == 0 {
+		return nil, errors.New("missing plugin command")
+	}
+
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start plugin: %v", err)
+	}
+
+	p := &Plugin{
+		cmd:     cmd,
+		stdin:   stdin,
+		enc:     json.NewEncoder(stdin),
+		pending: map[uint64]chan pluginResponse{},
+	}
+	go p.receive(stdout)
+
+	if _, err := p.call(pluginRequest{Method: "open", Options: options}); err != nil {
+		p.stdin.Close()
+		return nil, fmt.Errorf("failed to open plugin: %v", err)
+	}
+	resp, err := p.call(pluginRequest{Method: "benchmarks"})
+	if err != nil {
+		p.stdin.Close()
+		return nil, fmt.Errorf("failed to get the benchmarks of the plugin: %v", err)
+	}
+	for _, b := range resp.Benchmarks {
+		typ := benchmark.TypeLoop
+		if b.Type == "once" {
+			typ = benchmark.TypeOnce
+		}
+		p.benchmarks = append(p.benchmarks, benchmark.Benchmark{Name: b.Name, Type: typ, Stmt: b.Stmt, Tags: b.Tags})
+	}
+	return p, nil
+}
+
+// receive reads the responses and passes them to the pending requests, until the plugin exits.
+func (p *Plugin) receive(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var resp pluginResponse
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			log.Printf("invalid response of the plugin: %v\n", err)
+			continue
+		}
+		p.mu.Lock()
+		ch, ok := p.pending[resp.ID]
+		delete(p.pending, resp.ID)
+		p.mu.Unlock()
+		if ok {
+			ch <- resp
+		}
+	}
+
+	err := scanner.Err()
+	if err == nil {
+		err = p.cmd.Wait()
+	}
+	if err == nil {
+		err = errors.New("exited")
+	}
+
+	// fail the pending and all following requests
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = fmt.Errorf("plugin stopped: %v", err)
+	for id, ch := range p.pending {
+		ch <- pluginResponse{ID: id, Error: p.err.Error()}
+		delete(p.pending, id)
+	}
+}
+
+// call sends the request and waits for its response.
+func (p *Plugin) call(req pluginRequest) (pluginResponse, error) {
+	ch := make(chan pluginResponse, 1)
+
+	p.mu.Lock()
+	if p.err != nil {
+		p.mu.Unlock()
+		return pluginResponse{}, p.err
+	}
+	p.nextID++
+	req.ID = p.nextID
+	p.pending[req.ID] = ch
+	err := p.enc.Encode(req)
+	if err != nil {
+		delete(p.pending, req.ID)
+	}
+	p.mu.Unlock()
+	if err != nil {
+		return pluginResponse{}, fmt.Errorf("failed to send request to plugin: %v", err)
+	}
+
+	resp := <-ch
+	if resp.Error != "" {
+		return resp, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+// Benchmarks returns the built-in benchmarks of the plugin.
+func (p *Plugin) Benchmarks() []benchmark.Benchmark {
+	return p.benchmarks
+}
+
+// Setup initializes the database for the benchmark.
+func (p *Plugin) Setup() {
+	if _, err := p.call(pluginRequest{Method: "setup"}); err != nil {
+		log.Fatalf("failed to setup plugin: %v\n", err)
+	}
+}
+
+// Cleanup removes all remaining benchmarking data.
+func (p *Plugin) Cleanup() {
+	if _, err := p.call(pluginRequest{Method: "cleanup"}); err != nil {
+		log.Printf("failed to cleanup plugin: %v\n", err)
+	}
+}
+
+// Exec executes the given statement with the plugin.
+func (p *Plugin) Exec(stmt string) error {
+	_, err := p.call(pluginRequest{Method: "exec", Stmt: stmt})
+	return err
+}
+
+// Query executes the statement with the plugin and returns the first column of the returned rows.
+func (p *Plugin) Query(stmt string) ([]string, error) {
+	resp, err := p.call(pluginRequest{Method: "query", Stmt: stmt})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Rows, nil
+}
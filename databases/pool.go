@@ -0,0 +1,25 @@
+package databases
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Pool configures the connection pool of the benchers using database/sql.
+type Pool struct {
+	MaxOpen     int           // max. open connections (0 -> unlimited)
+	MaxIdle     int           // max. idle connections, more are closed when returned to the pool (0 -> none)
+	MaxLifetime time.Duration // connections are closed after this duration (0 -> never)
+	MaxIdleTime time.Duration // connections are closed after being idle for this duration (0 -> never)
+}
+
+// DefaultPool is the pool of database/sql, unless configured otherwise.
+var DefaultPool = Pool{MaxIdle: 2}
+
+// apply configures the connection pool of the database.
+func (p Pool) apply(db *sql.DB) {
+	db.SetMaxOpenConns(p.MaxOpen)
+	db.SetMaxIdleConns(p.MaxIdle)
+	db.SetConnMaxLifetime(p.MaxLifetime)
+	db.SetConnMaxIdleTime(p.MaxIdleTime)
+}
@@ -1,85 +1,231 @@
 package databases
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/sj14/dbbench/benchmark"
 )
 
 // Postgres implements the bencher interface.
 type Postgres struct {
-	db *sql.DB
+	db       *sql.DB
+	prepared preparedStmts // see ExecArgs
+	host     string
+	port     int
+	user     string // of the pooled connections, see ExecFreshConn
+	password string
+	schema   string
+	created  bool // schema was created by dbbench
+	auth     authUsers
+	selected selection         // see SelectBenchmarks
+	settings map[string]string // run-time parameters of each connection
+	tls      TLS
+
+	rlsCreated bool   // role of the row-level security benchmarks was created
+	rlsSkip    string // reason why the row-level security benchmarks are skipped
+
+	tpcc *tpcc // workload instead of the built-in benchmarks, see SetWorkload
 }
 
+// postgresAuthMethods are the benchmarked authentication methods, see the password_encryption setting.
+var postgresAuthMethods = []authMethod{{"md5", "md5"}, {"scram", "scram-sha-256"}}
+
 // NewPostgres returns a new postgres bencher.
-func NewPostgres(host string, port int, user, password string, maxOpenConns int) *Postgres {
+// All tables are created in the given schema, which is dropped when it was created by dbbench.
+// The settings are applied to each connection, e.g. synchronous_commit.
+func NewPostgres(host string, port int, user, password, schema string, pool Pool, tls TLS, settings map[string]string) (*Postgres, error) {
 	if port == 0 {
 		port = 5432
 	}
+	if schema == "" {
+		schema = defaultSchema
+	}
 
-	dataSourceName := fmt.Sprintf("host=%v port=%v user='%v' password='%v' sslmode=disable", host, port, user, password)
+	if err := tls.Validate(); err != nil {
+		return nil, err
+	}
+	p := &Postgres{host: host, port: port, user: user, password: password, schema: schema, settings: settings, tls: tls}
 
-	db, err := sql.Open("postgres", dataSourceName)
+	db, err := p.open(user, password)
 	if err != nil {
-		log.Fatalf("failed to open connection: %v\n", err)
+		return nil, fmt.Errorf("failed to open connection: %v", err)
 	}
 	if err := db.Ping(); err != nil {
-		log.Fatalf("failed to ping db: %v", err)
+		return nil, fmt.Errorf("failed to ping db: %v", err)
 	}
 
-	db.SetMaxOpenConns(maxOpenConns)
+	pool.apply(db)
 
-	p := &Postgres{db: db}
-	return p
+	p.db = db
+	return p, nil
 }
 
 // Benchmarks returns the individual benchmark statements for the postgres db.
 func (p *Postgres) Benchmarks() []benchmark.Benchmark {
-	return []benchmark.Benchmark{
-		{Name: "inserts", Type: benchmark.TypeLoop, Stmt: "INSERT INTO dbbench.simple (id, balance) VALUES( {{.Iter}}, {{call .RandInt63}});"},
-		{Name: "selects", Type: benchmark.TypeLoop, Stmt: "SELECT * FROM dbbench.simple WHERE id = {{.Iter}};"},
-		{Name: "updates", Type: benchmark.TypeLoop, Stmt: "UPDATE dbbench.simple SET balance = {{call .RandInt63}} WHERE id = {{.Iter}};"},
-		{Name: "deletes", Type: benchmark.TypeLoop, Stmt: "DELETE FROM dbbench.simple WHERE id = {{.Iter}};"},
-		// {"relation_insert0", benchmark.TypeLoop, "INSERT INTO dbbench.relational_one (oid, balance_one) VALUES( {{.Iter}}, {{call .RandInt63}});"},
-		// {"relation_insert1", benchmark.TypeLoop, "INSERT INTO dbbench.relational_two (relation, balance_two) VALUES( {{.Iter}}, {{call .RandInt63}});"},
-		// {"relation_select", benchmark.TypeLoop, "SELECT * FROM dbbench.relational_two INNER JOIN dbbench.relational_one ON relational_one.oid = relational_two.relation WHERE relation = {{.Iter}};"},
-		// {"relation_delete1", benchmark.TypeLoop, "DELETE FROM dbbench.relational_two WHERE relation = {{.Iter}};"},
-		// {"relation_delete0", benchmark.TypeLoop, "DELETE FROM dbbench.relational_one WHERE oid = {{.Iter}};"},
+	if p.tpcc != nil {
+		return p.tpcc.benchmarks(postgresDialect.in(p.schema))
 	}
+	return append(append(builtins(postgresDialect.in(p.schema)),
+		benchmark.Benchmark{Name: "fdw_inserts", Type: benchmark.TypeLoop, Skip: fdwSkip, Tags: writeTags},
+		benchmark.Benchmark{Name: "fdw_selects", Type: benchmark.TypeLoop, Skip: fdwSkip, Tags: readTags},
+		benchmark.Benchmark{Name: "fdw_joins", Type: benchmark.TypeLoop, Skip: fdwSkip, Tags: readTags},
+		benchmark.Benchmark{Name: "fdw_updates", Type: benchmark.TypeLoop, Skip: fdwSkip, Tags: writeTags},
+		benchmark.Benchmark{Name: "fdw_deletes", Type: benchmark.TypeLoop, Skip: fdwSkip, Tags: writeTags},
+	), append(p.rlsBenchmarks(), p.auth.benchmarks(postgresAuthMethods)...)...)
 }
 
 // Setup initializes the database for the benchmark.
 func (p *Postgres) Setup() {
-	if _, err := p.db.Exec("CREATE SCHEMA IF NOT EXISTS dbbench"); err != nil {
+	p.created = !exists(p.db, "SELECT 1 FROM pg_namespace WHERE nspname = $1", p.schema)
+
+	if _, err := p.db.Exec(fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %v", p.schema)); err != nil {
 		log.Fatalf("failed to create schema: %v\n", err)
 	}
-	if _, err := p.db.Exec("CREATE TABLE IF NOT EXISTS dbbench.simple (id INT PRIMARY KEY, balance DECIMAL);"); err != nil {
+	if p.tpcc != nil {
+		p.tpcc.setup(p.db, postgresDialect.in(p.schema))
+		return
+	}
+	if _, err := p.db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %v.simple (id INT PRIMARY KEY, balance DECIMAL);", p.schema)); err != nil {
 		log.Fatalf("failed to create table: %v\n", err)
 	}
-	if _, err := p.db.Exec("CREATE TABLE IF NOT EXISTS dbbench.relational_one (oid INT PRIMARY KEY, balance_one DECIMAL);"); err != nil {
+	if _, err := p.db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %v.relational_one (oid INT PRIMARY KEY, balance_one DECIMAL);", p.schema)); err != nil {
 		log.Fatalf("failed to create table relational_one: %v\n", err)
 	}
-	if _, err := p.db.Exec("CREATE TABLE IF NOT EXISTS dbbench.relational_two (balance_two DECIMAL, relation INT PRIMARY KEY, FOREIGN KEY(relation) REFERENCES dbbench.relational_one(oid));"); err != nil {
+	if _, err := p.db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %v.relational_two (balance_two DECIMAL, relation INT PRIMARY KEY, FOREIGN KEY(relation) REFERENCES %v.relational_one(oid));", p.schema, p.schema)); err != nil {
 		log.Fatalf("failed to create table relational_two: %v\n", err)
 	}
+	if p.selected.includes(p.rlsBenchmarks()) {
+		p.setupRLS()
+	} else {
+		p.rlsSkip = "not selected"
+	}
+	p.setupAuth()
 }
 
-// Cleanup removes all remaining benchmarking data.
-func (p *Postgres) Cleanup() {
-	if _, err := p.db.Exec("DROP TABLE dbbench.simple"); err != nil {
-		log.Printf("failed to drop table: %v\n", err)
+// SelectBenchmarks sets the names of the benchmarks selected to run, the setup only creates the
+// roles of the selected authentication and row-level security benchmarks. It has to be set before the setup.
+func (p *Postgres) SelectBenchmarks(names []string) {
+	p.selected = newSelection(names)
+}
+
+// setupAuth creates a role for each selected authentication method, whose password is stored with
+// the method. The methods, whose role exists already or which fail to connect, e.g. because of the
+// pg_hba.conf, are skipped.
+func (p *Postgres) setupAuth() {
+	p.auth = authUsers{password: randomPassword(), skip: map[string]string{}}
+	for _, m := range postgresAuthMethods {
+		if !p.selected[m.benchmark()] {
+			p.auth.skip[m.method] = "not selected"
+			continue
+		}
+		role := p.auth.user(p.schema, m)
+		if exists(p.db, "SELECT 1 FROM pg_roles WHERE rolname = $1", role) {
+			p.auth.skip[m.method] = fmt.Sprintf("role %v exists already, it's not replaced", role)
+			continue
+		}
+
+		tx, err := p.db.Begin()
+		if err != nil {
+			log.Fatalf("failed to begin transaction: %v\n", err)
+		}
+		for _, stmt := range []string{
+			fmt.Sprintf("SET LOCAL password_encryption = '%v'", m.method),
+			fmt.Sprintf("CREATE ROLE %v LOGIN PASSWORD '%v'", role, p.auth.password),
+		} {
+			if _, err = tx.Exec(stmt); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = tx.Commit()
+		} else {
+			tx.Rollback()
+		}
+		if err != nil {
+			p.auth.skip[m.method] = fmt.Sprintf("failed to create role: %v", err)
+			continue
+		}
+		p.auth.created = append(p.auth.created, role)
+
+		if err := p.execConnected(context.Background(), role, "SELECT 1"); err != nil {
+			p.auth.skip[m.method] = fmt.Sprintf("failed to connect: %v", err)
+		}
 	}
-	if _, err := p.db.Exec("DROP TABLE dbbench.relational_two"); err != nil {
-		log.Printf("failed to drop table: %v\n", err)
+}
+
+// open opens a connection pool of the user.
+func (p *Postgres) open(user, password string) (*sql.DB, error) {
+	host, addr := p.tls.pqHost(p.host, p.port)
+	dataSourceName := fmt.Sprintf("host=%v port=%v user='%v' password='%v'", host, p.port, user, password) + p.tls.pqParams() + runtimeParams(p.settings)
+	return openPostgres(dataSourceName, addr)
+}
+
+// execConnected connects as the user of the authentication benchmarks, executes the statement and disconnects.
+func (p *Postgres) execConnected(ctx context.Context, user, stmt string) error {
+	db, err := p.open(user, p.auth.password)
+	if err != nil {
+		return err
 	}
-	if _, err := p.db.Exec("DROP TABLE dbbench.relational_one"); err != nil {
-		log.Printf("failed to drop table: %v\n", err)
+	return execClosing(ctx, db, stmt)
+}
+
+// ExecAuth connects with the authentication method, executes the statement and disconnects.
+func (p *Postgres) ExecAuth(ctx context.Context, name, stmt string) error {
+	return p.execConnected(ctx, p.auth.user(p.schema, method(postgresAuthMethods, name)), stmt)
+}
+
+// ExecFreshConn connects like the pooled connections, executes the statement and disconnects.
+func (p *Postgres) ExecFreshConn(ctx context.Context, stmt string) error {
+	db, err := p.open(p.user, p.password)
+	if err != nil {
+		return err
+	}
+	return execClosing(ctx, db, stmt)
+}
+
+// SetWorkload sets the workload replacing the built-in benchmarks, e.g. WorkloadTPCC with the scale
+// as number of warehouses. It has to be set before the setup.
+func (p *Postgres) SetWorkload(name string, scale int) (err error) {
+	p.tpcc, err = newWorkload(name, scale)
+	return err
+}
+
+// Seed inserts the seeded rows [from, to) into the table of the built-in benchmarks.
+func (p *Postgres) Seed(from, to int) error {
+	for _, stmt := range postgresDialect.in(p.schema).seedStmts("simple", from, to) {
+		if _, err := p.db.Exec(stmt); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	if _, err := p.db.Exec("DROP SCHEMA dbbench"); err != nil {
-		log.Printf("failed drop schema: %v\n", err)
+// Cleanup removes all remaining benchmarking data. The schema is dropped with all its objects,
+// when it was created by dbbench, otherwise only the benchmark tables are dropped.
+func (p *Postgres) Cleanup() {
+	p.prepared.close() // they would lock the dropped tables
+	p.cleanupRLS()
+	if p.created {
+		if _, err := p.db.Exec(fmt.Sprintf("DROP SCHEMA %v CASCADE", p.schema)); err != nil {
+			log.Printf("failed drop schema: %v\n", err)
+		}
+	} else if p.tpcc != nil {
+		dropTPCC(p.db, postgresDialect.in(p.schema))
+	} else {
+		for _, table := range []string{"simple", "relational_two", "relational_one"} {
+			if _, err := p.db.Exec(fmt.Sprintf("DROP TABLE %v.%v", p.schema, table)); err != nil {
+				log.Printf("failed to drop table: %v\n", err)
+			}
+		}
+	}
+	for _, role := range p.auth.created {
+		if _, err := p.db.Exec(fmt.Sprintf("DROP ROLE %v", role)); err != nil {
+			log.Printf("failed to drop role: %v\n", err)
+		}
 	}
 	if err := p.db.Close(); err != nil {
 		log.Printf("failed to close connection: %v", err)
@@ -87,9 +233,81 @@ func (p *Postgres) Cleanup() {
 }
 
 // Exec executes the given statement on the database.
-func (p *Postgres) Exec(stmt string) {
+func (p *Postgres) Exec(stmt string) error {
+	if isTx(stmt) {
+		return execTx(context.Background(), p.db, stmt)
+	}
 	_, err := p.db.Exec(stmt)
+	return err
+}
+
+// ExecContext executes the given statement, it's cancelled with the context.
+func (p *Postgres) ExecContext(ctx context.Context, stmt string) error {
+	if isTx(stmt) {
+		return execTx(ctx, p.db, stmt)
+	}
+	_, err := p.db.ExecContext(ctx, stmt)
+	return err
+}
+
+// ExecTx executes the statements in a transaction, it's rolled back when a statement fails.
+func (p *Postgres) ExecTx(ctx context.Context, stmts []string) error {
+	return execInTx(ctx, p.db, stmts)
+}
+
+// ExecArgs executes the given statement as prepared statement with the arguments bound to its placeholders.
+func (p *Postgres) ExecArgs(ctx context.Context, stmt string, args ...interface{}) error {
+	return p.prepared.exec(ctx, p.db, sqlx.DOLLAR, stmt, args...)
+}
+
+// Query executes the given statement and returns the first column of the returned rows.
+func (p *Postgres) Query(stmt string) ([]string, error) {
+	return p.QueryContext(context.Background(), stmt)
+}
+
+// QueryContext is like Query, the query is cancelled with the context.
+func (p *Postgres) QueryContext(ctx context.Context, stmt string) ([]string, error) {
+	return queryFirstColumn(ctx, p.db, stmt)
+}
+
+// DB returns the connection pool, e.g. to use it with other database access layers.
+func (p *Postgres) DB() *sql.DB {
+	return p.db
+}
+
+// HoldTx begins a transaction, whose snapshot prevents the cleanup of all row versions created
+// until release is called. It occupies a connection of the pool.
+func (p *Postgres) HoldTx() (release func(), err error) {
+	tx, err := p.db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
 	if err != nil {
-		log.Printf("%v failed: %v", stmt, err)
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	// the snapshot is taken by the first statement
+	if _, err := tx.Exec("SELECT 1"); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to take snapshot: %v", err)
 	}
+	return func() { tx.Rollback() }, nil
+}
+
+// ServerVersion returns the version of the server, e.g. "16.2".
+func (p *Postgres) ServerVersion() (string, error) {
+	var v string
+	err := p.db.QueryRow("SHOW server_version").Scan(&v)
+	return v, err
+}
+
+// Size returns the bytes used by the tables of the schema, including their indexes and TOAST data.
+func (p *Postgres) Size() (int64, error) {
+	var size int64
+	err := p.db.QueryRow("SELECT coalesce(sum(pg_total_relation_size(relid)), 0) FROM pg_stat_user_tables WHERE schemaname = $1", p.schema).Scan(&size)
+	return size, err
+}
+
+// Bloat returns the number of dead row versions in the tables of the schema. The statistics are
+// updated asynchronously, after the transactions have finished.
+func (p *Postgres) Bloat() (int64, error) {
+	var dead int64
+	err := p.db.QueryRow("SELECT coalesce(sum(n_dead_tup), 0) FROM pg_stat_user_tables WHERE schemaname = $1", p.schema).Scan(&dead)
+	return dead, err
 }
@@ -0,0 +1,127 @@
+package databases
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// fdwSkip is the reason why the foreign table benchmarks are skipped without a second server.
+const fdwSkip = "requires a second server (--fdw-host)"
+
+// PostgresFDW implements the bencher interface. Additionally to the postgres benchmarks,
+// it queries a foreign table (postgres_fdw), which is stored on a second, remote postgres server.
+type PostgresFDW struct {
+	*Postgres
+	remote        *sql.DB
+	remoteCreated bool // remote schema was created by dbbench
+
+	host     string
+	port     int
+	user     string
+	password string
+}
+
+// NewPostgresFDW returns a new postgres bencher, which additionally benchmarks foreign tables on the remote server.
+// The remote server has to be reachable from dbbench and from the local server with the same address.
+// dbbench connects to it with the TLS configuration of the local server, except its server name.
+func NewPostgresFDW(local *Postgres, host string, port int, user, password string) (*PostgresFDW, error) {
+	if port == 0 {
+		port = 5432
+	}
+
+	tls := local.tls
+	tls.ServerName = ""
+	dataSourceName := fmt.Sprintf("host=%v port=%v user='%v' password='%v'", host, port, user, password) + tls.pqParams()
+
+	db, err := openPostgres(dataSourceName, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open remote connection: %v", err)
+	}
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping remote db: %v", err)
+	}
+
+	return &PostgresFDW{Postgres: local, remote: db, host: host, port: port, user: user, password: password}, nil
+}
+
+// Benchmarks returns the postgres benchmarks, whose skipped benchmarks of the foreign table are enabled.
+func (p *PostgresFDW) Benchmarks() []benchmark.Benchmark {
+	d := postgresDialect.in(p.schema)
+	remote := map[string]string{
+		"fdw_inserts": d.insert("remote"),
+		"fdw_selects": d.selectKey("remote"),
+		"fdw_joins":   fmt.Sprintf("SELECT * FROM %v s JOIN %v r ON r.id = s.id WHERE s.id = {{.Key}};", d.table("simple"), d.table("remote")),
+		"fdw_updates": d.update("remote"),
+		"fdw_deletes": d.delete("remote"),
+	}
+
+	benchmarks := p.Postgres.Benchmarks()
+	for i, b := range benchmarks {
+		if stmt, ok := remote[b.Name]; ok {
+			benchmarks[i].Stmt, benchmarks[i].Skip = stmt, ""
+		}
+	}
+	return benchmarks
+}
+
+// Setup initializes the local and the remote database and links the remote table as foreign table.
+func (p *PostgresFDW) Setup() {
+	p.Postgres.Setup()
+
+	p.remoteCreated = !exists(p.remote, "SELECT 1 FROM pg_namespace WHERE nspname = $1", p.schema)
+
+	if _, err := p.remote.Exec(fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %v", p.schema)); err != nil {
+		log.Fatalf("failed to create remote schema: %v\n", err)
+	}
+	if _, err := p.remote.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %v.remote (id INT PRIMARY KEY, balance DECIMAL);", p.schema)); err != nil {
+		log.Fatalf("failed to create remote table: %v\n", err)
+	}
+
+	if _, err := p.db.Exec("CREATE EXTENSION IF NOT EXISTS postgres_fdw"); err != nil {
+		log.Fatalf("failed to create extension postgres_fdw: %v\n", err)
+	}
+	if _, err := p.db.Exec(fmt.Sprintf("CREATE SERVER IF NOT EXISTS dbbench_remote FOREIGN DATA WRAPPER postgres_fdw OPTIONS (host %v, port '%v')", quoteLiteral(p.host), p.port)); err != nil {
+		log.Fatalf("failed to create foreign server: %v\n", err)
+	}
+	if _, err := p.db.Exec(fmt.Sprintf("CREATE USER MAPPING IF NOT EXISTS FOR CURRENT_USER SERVER dbbench_remote OPTIONS (user %v, password %v)", quoteLiteral(p.user), quoteLiteral(p.password))); err != nil {
+		log.Fatalf("failed to create user mapping: %v\n", err)
+	}
+	if _, err := p.db.Exec(fmt.Sprintf("CREATE FOREIGN TABLE IF NOT EXISTS %v.remote (id INT, balance DECIMAL) SERVER dbbench_remote OPTIONS (schema_name '%v', table_name 'remote');", p.schema, p.schema)); err != nil {
+		log.Fatalf("failed to create foreign table: %v\n", err)
+	}
+}
+
+// Cleanup removes the foreign table and the remote data, followed by the local data.
+func (p *PostgresFDW) Cleanup() {
+	if _, err := p.db.Exec(fmt.Sprintf("DROP FOREIGN TABLE %v.remote", p.schema)); err != nil {
+		log.Printf("failed to drop foreign table: %v\n", err)
+	}
+	if _, err := p.db.Exec("DROP USER MAPPING FOR CURRENT_USER SERVER dbbench_remote"); err != nil {
+		log.Printf("failed to drop user mapping: %v\n", err)
+	}
+	if _, err := p.db.Exec("DROP SERVER dbbench_remote"); err != nil {
+		log.Printf("failed to drop foreign server: %v\n", err)
+	}
+
+	if p.remoteCreated {
+		if _, err := p.remote.Exec(fmt.Sprintf("DROP SCHEMA %v CASCADE", p.schema)); err != nil {
+			log.Printf("failed to drop remote schema: %v\n", err)
+		}
+	} else if _, err := p.remote.Exec(fmt.Sprintf("DROP TABLE %v.remote", p.schema)); err != nil {
+		log.Printf("failed to drop remote table: %v\n", err)
+	}
+	if err := p.remote.Close(); err != nil {
+		log.Printf("failed to close remote connection: %v", err)
+	}
+
+	p.Postgres.Cleanup()
+}
+
+// quoteLiteral returns the string as SQL string literal, e.g. of a password.
+func quoteLiteral(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
@@ -0,0 +1,90 @@
+package databases
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// rlsRows is the number of rows of the tables of the row-level security benchmarks, half of them
+// belong to the tenant of the benchmarks.
+const rlsRows = 10000
+
+// rlsRole returns the role executing the row-level security benchmarks. It's neither a superuser
+// nor the owner of the tables, which would bypass the policies.
+func (p *Postgres) rlsRole() string {
+	return p.schema + "_tenant"
+}
+
+// setupRLS creates the tables of the row-level security benchmarks, an identical pair with and
+// without a policy restricting the rows to the tenant of the session. The benchmarks are skipped,
+// when the role exists already, e.g. after a failed cleanup, or can't be created.
+func (p *Postgres) setupRLS() {
+	p.rlsSkip = ""
+	role := p.rlsRole()
+	if exists(p.db, "SELECT 1 FROM pg_roles WHERE rolname = $1", role) {
+		p.rlsSkip = fmt.Sprintf("role %v exists already, it's not replaced", role)
+		return
+	}
+	if _, err := p.db.Exec(fmt.Sprintf("CREATE ROLE %v NOLOGIN", role)); err != nil {
+		p.rlsSkip = fmt.Sprintf("failed to create role: %v", err)
+		return
+	}
+	p.rlsCreated = true
+
+	for _, stmt := range []string{
+		fmt.Sprintf("GRANT USAGE ON SCHEMA %v TO %v", p.schema, role),
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %v.tenants (id INT PRIMARY KEY, tenant INT NOT NULL, balance DECIMAL)", p.schema),
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %v.tenants_plain (id INT PRIMARY KEY, tenant INT NOT NULL, balance DECIMAL)", p.schema),
+		fmt.Sprintf("TRUNCATE %v.tenants, %v.tenants_plain", p.schema, p.schema),
+		fmt.Sprintf("INSERT INTO %v.tenants SELECT g, g %% 2, 0 FROM generate_series(1, %d) g", p.schema, rlsRows),
+		fmt.Sprintf("INSERT INTO %v.tenants_plain SELECT * FROM %v.tenants", p.schema, p.schema),
+		fmt.Sprintf("ALTER TABLE %v.tenants ENABLE ROW LEVEL SECURITY", p.schema),
+		fmt.Sprintf("DROP POLICY IF EXISTS tenant_isolation ON %v.tenants", p.schema),
+		fmt.Sprintf("CREATE POLICY tenant_isolation ON %v.tenants USING (tenant = current_setting('dbbench.tenant')::INT)", p.schema),
+		fmt.Sprintf("GRANT SELECT ON %v.tenants, %v.tenants_plain TO %v", p.schema, p.schema, role),
+	} {
+		if _, err := p.db.Exec(stmt); err != nil {
+			log.Fatalf("failed to set up row-level security: %v\n", err)
+		}
+	}
+}
+
+// rlsBenchmarks returns pairs of benchmarks executing the same query as the tenant role, restricted by
+// the row-level security policy or by an explicit filter of the tenant.
+func (p *Postgres) rlsBenchmarks() []benchmark.Benchmark {
+	skip := p.rlsSkip
+	if !p.rlsCreated && skip == "" {
+		skip = "requires the setup of the role, not possible with --noinit"
+	}
+
+	// as the tenant 1 in a transaction, which resets the role and the setting
+	tx := func(query string) string {
+		return fmt.Sprintf("BEGIN; SET LOCAL ROLE %v; SET LOCAL dbbench.tenant = 1; %v; COMMIT;", p.rlsRole(), query)
+	}
+	return []benchmark.Benchmark{
+		{Name: "filter_selects", Type: benchmark.TypeLoop, Stmt: tx(fmt.Sprintf("SELECT * FROM %v.tenants_plain WHERE id = {{.Key}} AND tenant = 1", p.schema)), Skip: skip, Tags: readTags},
+		{Name: "rls_selects", Type: benchmark.TypeLoop, Stmt: tx(fmt.Sprintf("SELECT * FROM %v.tenants WHERE id = {{.Key}}", p.schema)), Skip: skip, Tags: readTags, Baseline: "filter_selects"},
+		{Name: "filter_scans", Type: benchmark.TypeLoop, Stmt: tx(fmt.Sprintf("SELECT count(*) FROM %v.tenants_plain WHERE tenant = 1", p.schema)), Skip: skip, Tags: bulkReadTags},
+		{Name: "rls_scans", Type: benchmark.TypeLoop, Stmt: tx(fmt.Sprintf("SELECT count(*) FROM %v.tenants", p.schema)), Skip: skip, Tags: bulkReadTags, Baseline: "filter_scans"},
+	}
+}
+
+// cleanupRLS drops the tables and the role of the row-level security benchmarks.
+func (p *Postgres) cleanupRLS() {
+	if !p.rlsCreated {
+		return
+	}
+	if !p.created {
+		if _, err := p.db.Exec(fmt.Sprintf("DROP TABLE %v.tenants, %v.tenants_plain", p.schema, p.schema)); err != nil {
+			log.Printf("failed to drop table: %v\n", err)
+		}
+	}
+	if _, err := p.db.Exec(fmt.Sprintf("DROP OWNED BY %v", p.rlsRole())); err != nil {
+		log.Printf("failed to drop privileges: %v\n", err)
+	}
+	if _, err := p.db.Exec(fmt.Sprintf("DROP ROLE %v", p.rlsRole())); err != nil {
+		log.Printf("failed to drop role: %v\n", err)
+	}
+}
@@ -0,0 +1,49 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// preparedStmts caches the prepared statements of a connection pool, so each statement is only
+// prepared once and then executed with the bound arguments. The placeholders ? are rebound to the
+// ones of the driver, e.g. $1 for postgres.
+type preparedStmts struct {
+	mu    sync.Mutex
+	stmts map[string]*sql.Stmt
+}
+
+// exec executes the prepared statement with the arguments, the statement is prepared on the first call.
+// The execution is cancelled with the context.
+func (p *preparedStmts) exec(ctx context.Context, db *sql.DB, bind int, query string, args ...interface{}) error {
+	p.mu.Lock()
+	stmt, ok := p.stmts[query]
+	if !ok {
+		var err error
+		if stmt, err = db.Prepare(sqlx.Rebind(bind, query)); err != nil {
+			p.mu.Unlock()
+			return err
+		}
+		if p.stmts == nil {
+			p.stmts = map[string]*sql.Stmt{}
+		}
+		p.stmts[query] = stmt
+	}
+	p.mu.Unlock()
+
+	_, err := stmt.ExecContext(ctx, args...)
+	return err
+}
+
+// close closes the prepared statements, before the connection pool is closed.
+func (p *preparedStmts) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, stmt := range p.stmts {
+		stmt.Close()
+	}
+	p.stmts = nil
+}
@@ -0,0 +1,47 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"log"
+)
+
+// queryFirstColumn executes the statement and returns the first column of the returned rows.
+func queryFirstColumn(ctx context.Context, db *sql.DB, stmt string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil || len(cols) == 0 {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(cols))
+	for i := range values {
+		values[i] = &sql.RawBytes{}
+	}
+
+	var result []string
+	for rows.Next() {
+		if err := rows.Scan(values...); err != nil {
+			return result, err
+		}
+		result = append(result, string(*values[0].(*sql.RawBytes)))
+	}
+	return result, rows.Err()
+}
+
+// exists returns whether the query returns any rows. When the query fails, the rows are assumed
+// to exist, e.g. so an existing schema is never dropped.
+func exists(db *sql.DB, query string, args ...interface{}) bool {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		log.Printf("%v failed: %v", query, err)
+		return true
+	}
+	defer rows.Close()
+	return rows.Next()
+}
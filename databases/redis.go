@@ -0,0 +1,248 @@
+package databases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// Redis implements the bencher interface. The statements are Redis commands, e.g. "GET dbbench:1",
+// the lines of a statement with several commands are sent as a single pipeline.
+type Redis struct {
+	client redis.UniversalClient
+}
+
+// redisPrefix is the prefix of the keys of the built-in benchmarks.
+const redisPrefix = "dbbench:"
+
+// redisTimeout limits the duration of a single command.
+const redisTimeout = 5 * time.Minute
+
+// NewRedis returns a new Redis bencher. The hosts are comma-separated, in cluster mode they
+// are the seed nodes of the cluster. No credentials are sent, when user and password are empty.
+func NewRedis(hosts string, port int, user, password string, cluster bool, maxConns int) (*Redis, error) {
+	if port == 0 {
+		port = 6379
+	}
+	var addrs []string
+	for _, host := range strings.Split(hosts, ",") {
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(host, strconv.Itoa(port))
+		}
+		addrs = append(addrs, host)
+	}
+
+	var client redis.UniversalClient
+	if cluster {
+		client = redis.NewClusterClient(&redis.ClusterOptions{Addrs: addrs, Username: user, Password: password, PoolSize: maxConns})
+	} else {
+		if len(addrs) > 1 {
+			return nil, errors.New("several hosts are only supported in cluster mode")
+		}
+		client = redis.NewClient(&redis.Options{Addr: addrs[0], Username: user, Password: password, PoolSize: maxConns})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to ping db: %v", err)
+	}
+	return &Redis{client: client}, nil
+}
+
+// Benchmarks returns the individual benchmark commands for Redis.
+func (r *Redis) Benchmarks() []benchmark.Benchmark {
+	// sets 10 keys with a single round trip
+	var pipeline []string
+	for i := 0; i < 10; i++ {
+		pipeline = append(pipeline, fmt.Sprintf("SET %vpipeline:{{.Iter}}:%d {{call .RandInt63}}", redisPrefix, i))
+	}
+
+	return []benchmark.Benchmark{
+		{Name: "sets", Type: benchmark.TypeLoop, Stmt: "SET " + redisPrefix + "{{.Iter}} {{call .RandInt63}}", Tags: writeTags},
+		{Name: "gets", Type: benchmark.TypeLoop, Stmt: "GET " + redisPrefix + "{{.Key}}", Tags: readTags},
+		{Name: "incrs", Type: benchmark.TypeLoop, Stmt: "INCR " + redisPrefix + "{{.Iter}}", Tags: writeTags},
+		{Name: "pipelines", Type: benchmark.TypeLoop, Stmt: strings.Join(pipeline, "\n"), Tags: []string{benchmark.TagWrite, benchmark.TagBulk}},
+		{Name: "deletes", Type: benchmark.TypeLoop, Stmt: "DEL " + redisPrefix + "{{.Iter}}", Tags: writeTags},
+	}
+}
+
+// Setup initializes the database for the benchmark, the keys of a previous run are deleted.
+func (r *Redis) Setup() {
+	if err := r.deleteKeys(); err != nil {
+		log.Fatalf("failed to delete keys: %v\n", err)
+	}
+}
+
+// Seed sets the seeded keys [from, to), existing keys are overwritten.
+func (r *Redis) Seed(from, to int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	const batch = 1000
+	for i := from; i < to; i += batch {
+		pipe := r.client.Pipeline()
+		for j := i; j < i+batch && j < to; j++ {
+			// the same balance as seeded into the tables of the other databases
+			id := int64(seedOffset + j)
+			pipe.Set(ctx, redisPrefix+strconv.FormatInt(id, 10), benchmark.Hash("balance", id)%1e9, 0)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cleanup removes all remaining benchmarking data, the keys with the prefix of the built-in benchmarks.
+func (r *Redis) Cleanup() {
+	if err := r.deleteKeys(); err != nil {
+		log.Printf("failed to delete keys: %v\n", err)
+	}
+	r.client.Close()
+}
+
+// deleteKeys deletes the keys with the prefix of the built-in benchmarks on all nodes.
+func (r *Redis) deleteKeys() error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	del := func(ctx context.Context, client *redis.Client) error {
+		iter := client.Scan(ctx, 0, redisPrefix+"*", 1000).Iterator()
+		for iter.Next(ctx) {
+			if err := client.Del(ctx, iter.Val()).Err(); err != nil {
+				return err
+			}
+		}
+		return iter.Err()
+	}
+
+	if cluster, ok := r.client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, del)
+	}
+	return del(ctx, r.client.(*redis.Client))
+}
+
+// Exec executes the commands of the statement. Missing keys are no error.
+func (r *Redis) Exec(stmt string) error {
+	return r.ExecContext(context.Background(), stmt)
+}
+
+// ExecContext executes the commands of the statement, they're cancelled with the context.
+func (r *Redis) ExecContext(ctx context.Context, stmt string) error {
+	ctx, cancel := context.WithTimeout(ctx, redisTimeout)
+	defer cancel()
+
+	cmds, err := redisCommands(stmt)
+	if err != nil {
+		return err
+	}
+	if len(cmds) == 1 {
+		return redisErr(r.client.Do(ctx, cmds[0]...).Err())
+	}
+
+	pipe := r.client.Pipeline()
+	for _, args := range cmds {
+		pipe.Do(ctx, args...)
+	}
+	results, _ := pipe.Exec(ctx)
+	for _, cmd := range results {
+		if err := redisErr(cmd.Err()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Query executes the first command of the statement and returns the returned values,
+// e.g. the keys of "SCAN 0 MATCH dbbench:*" or the value of "GET dbbench:1".
+func (r *Redis) Query(stmt string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	cmds, err := redisCommands(stmt)
+	if err != nil {
+		return nil, err
+	}
+	value, err := r.client.Do(ctx, cmds[0]...).Result()
+	if err != nil {
+		// a missing key returns no values
+		return nil, redisErr(err)
+	}
+	return redisStrings(value), nil
+}
+
+// redisErr returns the error, unless it's a missing key.
+func redisErr(err error) error {
+	if err == redis.Nil {
+		return nil
+	}
+	return err
+}
+
+// redisStrings flattens the value returned by a command, e.g. the keys and the cursor of SCAN.
+func redisStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		var result []string
+		for _, e := range v {
+			result = append(result, redisStrings(e)...)
+		}
+		return result
+	}
+	return []string{fmt.Sprint(value)}
+}
+
+// redisCommands splits the statement into the arguments of its commands, one command per line.
+// Arguments containing spaces are enclosed in double quotes, e.g. SET dbbench:name "Markus Moen".
+func redisCommands(stmt string) ([][]interface{}, error) {
+	var cmds [][]interface{}
+	for _, line := range strings.Split(stmt, "\n") {
+		var (
+			args    []interface{}
+			arg     strings.Builder
+			quoted  bool
+			pending bool // arg contains an argument, possibly an empty quoted one
+		)
+		for _, c := range strings.TrimSpace(line) {
+			switch {
+			case c == '"':
+				quoted = !quoted
+				pending = true
+			case c == ' ' && !quoted:
+				if pending {
+					args = append(args, arg.String())
+					arg.Reset()
+					pending = false
+				}
+			default:
+				arg.WriteRune(c)
+				pending = true
+			}
+		}
+		if quoted {
+			return nil, fmt.Errorf("unterminated quote: %v", line)
+		}
+		if pending {
+			args = append(args, arg.String())
+		}
+		if len(args) > 0 {
+			cmds = append(cmds, args)
+		}
+	}
+	if len(cmds) == 0 {
+		return nil, errors.New("empty command")
+	}
+	return cmds, nil
+}
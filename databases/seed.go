@@ -0,0 +1,32 @@
+package databases
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// seedOffset is the id of the first seeded row, so the seeded rows don't collide with
+// the rows of the built-in benchmarks, which use the iteration counter as id.
+const seedOffset = 1000000000
+
+// seedStmts returns the statements inserting the seeded rows [from, to) into the table. Existing rows
+// of the range are replaced, so the rows of an interrupted seed can be seeded again.
+func (d dialect) seedStmts(table string, from, to int) []string {
+	var stmts []string
+	if d.seedDelete {
+		stmts = append(stmts, fmt.Sprintf("DELETE FROM %v WHERE id >= %d AND id < %d;", d.table(table), seedOffset+from, seedOffset+to))
+	}
+	replace := d.upsert("id", []string{"balance"})
+	for i := from; i < to; i += d.batch {
+		var values []string
+		for j := i; j < i+d.batch && j < to; j++ {
+			// fits the balance column of all databases, the same in each run
+			id := seedOffset + j
+			values = append(values, fmt.Sprintf("(%d, %d)", id, benchmark.Hash("balance", int64(id))%1e9))
+		}
+		stmts = append(stmts, fmt.Sprintf("INSERT INTO %v (id, balance) VALUES %v%v;", d.table(table), strings.Join(values, ", "), replace))
+	}
+	return stmts
+}
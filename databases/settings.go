@@ -0,0 +1,40 @@
+package databases
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// runtimeParams returns the settings as run-time parameters of a postgres connection string,
+// which are set for each connection.
+func runtimeParams(settings map[string]string) string {
+	var params string
+	for _, name := range sortedKeys(settings) {
+		params += fmt.Sprintf(" %v='%v'", name, strings.Replace(settings[name], "'", `\'`, -1))
+	}
+	return params
+}
+
+// systemVars returns the settings as query of a mysql connection string, the driver sets
+// them as system variables of each connection. String values need quotes, e.g. "'value'".
+func systemVars(settings map[string]string) string {
+	vars := make([]string, 0, len(settings))
+	for _, name := range sortedKeys(settings) {
+		vars = append(vars, name+"="+url.QueryEscape(settings[name]))
+	}
+	if len(vars) == 0 {
+		return ""
+	}
+	return "?" + strings.Join(vars, "&")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,17 +1,21 @@
 package databases
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"os"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/sj14/dbbench/benchmark"
 )
 
 // SQLite implements the bencher interface.
 type SQLite struct {
-	db *sql.DB
+	db       *sql.DB
+	prepared preparedStmts // see ExecArgs
+	tpcc     *tpcc         // workload instead of the built-in benchmarks, see SetWorkload
 }
 
 var (
@@ -20,7 +24,7 @@ var (
 )
 
 // NewSQLite retruns a new SQLite bencher.
-func NewSQLite(path string) *SQLite {
+func NewSQLite(path string) (*SQLite, error) {
 	dbPath = path
 
 	if _, err := os.Stat(path); os.IsNotExist(err) {
@@ -31,31 +35,39 @@ func NewSQLite(path string) *SQLite {
 	// Automatically creates the DB file if it doesn't exist yet.
 	db, err := sql.Open("sqlite3", fmt.Sprintf("%s?cache=shared", path))
 	if err != nil {
-		log.Fatalf("failed to open connection: %v\n", err)
+		return nil, fmt.Errorf("failed to open connection: %v", err)
 	}
 
 	db.SetMaxOpenConns(1)
-	p := &SQLite{db: db}
-	return p
+	return &SQLite{db: db}, nil
+}
+
+// ExecFreshConn opens the database file, executes the statement and closes it.
+func (m *SQLite) ExecFreshConn(ctx context.Context, stmt string) error {
+	return execConnected(ctx, "sqlite3", fmt.Sprintf("%s?cache=shared", dbPath), stmt)
 }
 
 // Benchmarks returns the individual benchmark statements for sqlite.
 func (m *SQLite) Benchmarks() []benchmark.Benchmark {
-	return []benchmark.Benchmark{
-		{Name: "inserts", Type: benchmark.TypeLoop, Stmt: "INSERT INTO dbbench_simple (id, balance) VALUES( {{.Iter}}, {{call .RandInt63}});"},
-		{Name: "selects", Type: benchmark.TypeLoop, Stmt: "SELECT * FROM dbbench_simple WHERE id = {{.Iter}};"},
-		{Name: "updates", Type: benchmark.TypeLoop, Stmt: "UPDATE dbbench_simple SET balance = {{call .RandInt63}} WHERE id = {{.Iter}};"},
-		{Name: "deletes", Type: benchmark.TypeLoop, Stmt: "DELETE FROM dbbench_simple WHERE id = {{.Iter}};"},
-		// {"relation_insert0", benchmark.TypeLoop, "INSERT INTO dbbench_relational_one (oid, balance_one) VALUES( {{.Iter}}, {{call .RandInt63}});"},
-		// {"relation_insert1", benchmark.TypeLoop, "INSERT INTO dbbench_relational_two (relation, balance_two) VALUES( {{.Iter}}, {{call .RandInt63}});"},
-		// {"relation_select", benchmark.TypeLoop, "SELECT * FROM dbbench_relational_two INNER JOIN dbbench_relational_one ON dbbench_relational_one.oid = relation WHERE relation = {{.Iter}};"},
-		// {"relation_delete1", benchmark.TypeLoop, "DELETE FROM dbbench_relational_two WHERE relation = {{.Iter}};"},
-		// {"relation_delete0", benchmark.TypeLoop, "DELETE FROM dbbench_relational_one WHERE oid = {{.Iter}};"},
+	if m.tpcc != nil {
+		return m.tpcc.benchmarks(sqliteDialect)
 	}
+	return builtins(sqliteDialect)
+}
+
+// SetWorkload sets the workload replacing the built-in benchmarks, e.g. WorkloadTPCC with the scale
+// as number of warehouses. It has to be set before the setup.
+func (m *SQLite) SetWorkload(name string, scale int) (err error) {
+	m.tpcc, err = newWorkload(name, scale)
+	return err
 }
 
 // Setup initializes the database for the benchmark.
 func (m *SQLite) Setup() {
+	if m.tpcc != nil {
+		m.tpcc.setup(m.db, sqliteDialect)
+		return
+	}
 	if _, err := m.db.Exec("CREATE TABLE IF NOT EXISTS dbbench_simple (id INT PRIMARY KEY, balance DECIMAL);"); err != nil {
 		log.Fatalf("failed to create table dbbench_simple: %v\n", err)
 	}
@@ -70,16 +82,31 @@ func (m *SQLite) Setup() {
 	}
 }
 
+// Seed inserts the seeded rows [from, to) into the table of the built-in benchmarks.
+func (m *SQLite) Seed(from, to int) error {
+	for _, stmt := range sqliteDialect.seedStmts("simple", from, to) {
+		if _, err := m.db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Cleanup removes all remaining benchmarking data.
 func (m *SQLite) Cleanup() {
-	if _, err := m.db.Exec("DROP TABLE dbbench_simple"); err != nil {
-		log.Printf("failed to drop table: %v\n", err)
-	}
-	if _, err := m.db.Exec("DROP TABLE dbbench_relational_two"); err != nil {
-		log.Printf("failed to drop table: %v\n", err)
-	}
-	if _, err := m.db.Exec("DROP TABLE dbbench_relational_one"); err != nil {
-		log.Printf("failed to drop table: %v\n", err)
+	m.prepared.close() // they would lock the dropped tables
+	if m.tpcc != nil {
+		dropTPCC(m.db, sqliteDialect)
+	} else {
+		if _, err := m.db.Exec("DROP TABLE dbbench_simple"); err != nil {
+			log.Printf("failed to drop table: %v\n", err)
+		}
+		if _, err := m.db.Exec("DROP TABLE dbbench_relational_two"); err != nil {
+			log.Printf("failed to drop table: %v\n", err)
+		}
+		if _, err := m.db.Exec("DROP TABLE dbbench_relational_one"); err != nil {
+			log.Printf("failed to drop table: %v\n", err)
+		}
 	}
 	if err := m.db.Close(); err != nil {
 		log.Printf("failed to close connection: %v", err)
@@ -96,10 +123,60 @@ func (m *SQLite) Cleanup() {
 }
 
 // Exec executes the given statement on the database.
-func (m *SQLite) Exec(stmt string) {
+func (m *SQLite) Exec(stmt string) error {
+	if isTx(stmt) {
+		return execTx(context.Background(), m.db, stmt)
+	}
 	//  driver has no support for results
 	_, err := m.db.Exec(stmt)
-	if err != nil {
-		log.Printf("%v failed: %v", stmt, err)
+	return err
+}
+
+// ExecContext executes the given statement, it's cancelled with the context.
+func (m *SQLite) ExecContext(ctx context.Context, stmt string) error {
+	if isTx(stmt) {
+		return execTx(ctx, m.db, stmt)
 	}
+	_, err := m.db.ExecContext(ctx, stmt)
+	return err
+}
+
+// ExecTx executes the statements in a transaction, it's rolled back when a statement fails.
+func (m *SQLite) ExecTx(ctx context.Context, stmts []string) error {
+	return execInTx(ctx, m.db, stmts)
+}
+
+// ExecArgs executes the given statement as prepared statement with the arguments bound to its placeholders.
+func (m *SQLite) ExecArgs(ctx context.Context, stmt string, args ...interface{}) error {
+	return m.prepared.exec(ctx, m.db, sqlx.QUESTION, stmt, args...)
+}
+
+// Query executes the given statement and returns the first column of the returned rows.
+func (m *SQLite) Query(stmt string) ([]string, error) {
+	return m.QueryContext(context.Background(), stmt)
+}
+
+// QueryContext is like Query, the query is cancelled with the context.
+func (m *SQLite) QueryContext(ctx context.Context, stmt string) ([]string, error) {
+	return queryFirstColumn(ctx, m.db, stmt)
+}
+
+// ServerVersion returns the version of the linked SQLite library, e.g. "3.45.1".
+func (m *SQLite) ServerVersion() (string, error) {
+	var v string
+	err := m.db.QueryRow("SELECT sqlite_version()").Scan(&v)
+	return v, err
+}
+
+// Size returns the bytes of the database file. Pages of deleted rows are reused,
+// but the file only shrinks with VACUUM.
+func (m *SQLite) Size() (int64, error) {
+	var size int64
+	err := m.db.QueryRow("SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size()").Scan(&size)
+	return size, err
+}
+
+// DB returns the connection pool, e.g. to use it with other database access layers.
+func (m *SQLite) DB() *sql.DB {
+	return m.db
 }
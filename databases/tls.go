@@ -0,0 +1,135 @@
+package databases
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// TLSModes are the modes of TLS, named like the sslmode of libpq.
+var TLSModes = []string{"disable", "require", "verify-ca", "verify-full"}
+
+// TLS configures the encryption of the connections.
+type TLS struct {
+	Mode       string // one of TLSModes (empty -> disable)
+	CA         string // file of the CA certificates verifying the server (empty -> system CAs)
+	Cert       string // file of the client certificate
+	Key        string // file of the key of the client certificate
+	ServerName string // name verified in the server certificate (empty -> host)
+}
+
+// enabled returns whether the connections are encrypted.
+func (t TLS) enabled() bool {
+	return t.Mode != "" && t.Mode != "disable"
+}
+
+// Validate returns an error, when the mode is unknown or only one of the client certificate and key is given.
+func (t TLS) Validate() error {
+	if t.Mode != "" && !contains(TLSModes, t.Mode) {
+		return fmt.Errorf("unknown tls mode %q, available: %v", t.Mode, strings.Join(TLSModes, ", "))
+	}
+	if (t.Cert == "") != (t.Key == "") {
+		return errors.New("the tls client certificate needs a key and vice versa")
+	}
+	return nil
+}
+
+// config returns the TLS config connecting to the host, nil when disabled.
+func (t TLS) config(host string) (*tls.Config, error) {
+	if err := t.Validate(); err != nil || !t.enabled() {
+		return nil, err
+	}
+	cfg := &tls.Config{ServerName: host}
+	if t.ServerName != "" {
+		cfg.ServerName = t.ServerName
+	}
+	if t.CA != "" {
+		pem, err := ioutil.ReadFile(t.CA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %v", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %v", t.CA)
+		}
+	}
+	if t.Cert != "" {
+		cert, err := tls.LoadX509KeyPair(t.Cert, t.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	switch t.Mode {
+	case "require":
+		cfg.InsecureSkipVerify = true
+	case "verify-ca":
+		// the chain is verified, but not the name of the server
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyPeerCertificate = verifyChain(cfg.RootCAs)
+	}
+	return cfg, nil
+}
+
+// verifyChain returns a callback of tls.Config, which verifies the certificate chain of the server with the CAs.
+func verifyChain(roots *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("server sent no certificate")
+		}
+		opts := x509.VerifyOptions{Roots: roots, Intermediates: x509.NewCertPool()}
+		var leaf *x509.Certificate
+		for i, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return err
+			}
+			if i == 0 {
+				leaf = cert
+				continue
+			}
+			opts.Intermediates.AddCert(cert)
+		}
+		_, err := leaf.Verify(opts)
+		return err
+	}
+}
+
+// pqParams returns the TLS parameters of a lib/pq connection string.
+func (t TLS) pqParams() string {
+	if !t.enabled() {
+		return " sslmode=disable"
+	}
+	params := " sslmode=" + t.Mode
+	if t.CA != "" {
+		params += fmt.Sprintf(" sslrootcert='%v'", t.CA)
+	}
+	if t.Cert != "" {
+		params += fmt.Sprintf(" sslcert='%v' sslkey='%v'", t.Cert, t.Key)
+	}
+	return params
+}
+
+// pqHost returns the host of a lib/pq connection string and the address to dial instead, lib/pq verifies
+// the host as server name. The address is empty, when the host is dialed.
+func (t TLS) pqHost(host string, port int) (string, string) {
+	if !t.enabled() || t.ServerName == "" {
+		return host, ""
+	}
+	return t.ServerName, net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
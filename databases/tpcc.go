@@ -0,0 +1,248 @@
+package databases
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// WorkloadTPCC is the transactional workload modeled after TPC-C, see SetWorkload.
+const WorkloadTPCC = "tpcc"
+
+// Cardinalities of the tpcc workload. They are scaled down from TPC-C, so a warehouse loads
+// within seconds, but keep its contention on the warehouses and districts.
+const (
+	tpccDistricts  = 10    // per warehouse
+	tpccCustomers  = 300   // per district
+	tpccItems      = 10000 // in total, each warehouse stocks all of them
+	tpccOrderLines = 5     // per order
+)
+
+// tpccTables are the tables of the tpcc workload, in the order they can be dropped.
+var tpccTables = []string{"order_line", "orders", "history", "stock", "item", "customer", "district", "warehouse"}
+
+// tpcc is the transactional workload modeled after TPC-C: new orders, payments and order status
+// queries of the customers of the districts of the warehouses. It's not a compliant implementation,
+// e.g. there are no keying and think times, no deliveries and no stock level queries.
+type tpcc struct {
+	warehouses int
+}
+
+// newWorkload returns the workload with the given name, nil for the built-in benchmarks. The scale
+// is the number of warehouses of the tpcc workload.
+func newWorkload(name string, scale int) (*tpcc, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case WorkloadTPCC:
+		if scale == 0 {
+			scale = 1
+		}
+		return &tpcc{warehouses: scale}, nil
+	}
+	return nil, fmt.Errorf("unknown workload %q, use %v", name, WorkloadTPCC)
+}
+
+// setup creates and populates the tables.
+func (t *tpcc) setup(db *sql.DB, d dialect) {
+	for _, stmt := range t.schemaStmts(d) {
+		if _, err := db.Exec(stmt); err != nil {
+			log.Fatalf("failed to create tpcc tables: %v\n", err)
+		}
+	}
+	for _, stmt := range t.loadStmts(d) {
+		if _, err := db.Exec(stmt); err != nil {
+			log.Fatalf("failed to load tpcc tables: %v\n", err)
+		}
+	}
+	log.Printf("loaded tpcc tables with %v warehouses\n", t.warehouses)
+}
+
+// schemaStmts returns the statements creating the tables, existing ones are dropped.
+func (t *tpcc) schemaStmts(d dialect) []string {
+	var stmts []string
+	for _, table := range tpccTables {
+		stmts = append(stmts, fmt.Sprintf("DROP TABLE IF EXISTS %v;", d.table(table)))
+	}
+	return append(stmts,
+		fmt.Sprintf("CREATE TABLE %v (w_id INT PRIMARY KEY, w_name VARCHAR(10), w_tax DECIMAL(4,4), w_ytd DECIMAL(12,2));", d.table("warehouse")),
+		fmt.Sprintf("CREATE TABLE %v (d_w_id INT, d_id INT, d_name VARCHAR(10), d_tax DECIMAL(4,4), d_ytd DECIMAL(12,2), d_next_o_id INT, PRIMARY KEY (d_w_id, d_id));", d.table("district")),
+		fmt.Sprintf("CREATE TABLE %v (c_w_id INT, c_d_id INT, c_id INT, c_last VARCHAR(16), c_discount DECIMAL(4,4), c_balance DECIMAL(12,2), c_ytd_payment DECIMAL(12,2), c_payment_cnt INT, PRIMARY KEY (c_w_id, c_d_id, c_id));", d.table("customer")),
+		fmt.Sprintf("CREATE TABLE %v (h_c_w_id INT, h_c_d_id INT, h_c_id INT, h_w_id INT, h_d_id INT, h_date TIMESTAMP, h_amount DECIMAL(6,2));", d.table("history")),
+		fmt.Sprintf("CREATE TABLE %v (i_id INT PRIMARY KEY, i_name VARCHAR(24), i_price DECIMAL(5,2));", d.table("item")),
+		fmt.Sprintf("CREATE TABLE %v (s_w_id INT, s_i_id INT, s_quantity INT, s_ytd INT, s_order_cnt INT, PRIMARY KEY (s_w_id, s_i_id));", d.table("stock")),
+		fmt.Sprintf("CREATE TABLE %v (o_w_id INT, o_d_id INT, o_id INT, o_c_id INT, o_entry_d TIMESTAMP, o_ol_cnt INT, PRIMARY KEY (o_w_id, o_d_id, o_id));", d.table("orders")),
+		fmt.Sprintf("CREATE INDEX %v ON %v (o_w_id, o_d_id, o_c_id, o_id);", d.prefix+"orders_customer", d.table("orders")),
+		fmt.Sprintf("CREATE TABLE %v (ol_w_id INT, ol_d_id INT, ol_o_id INT, ol_number INT, ol_i_id INT, ol_quantity INT, ol_amount DECIMAL(6,2), PRIMARY KEY (ol_w_id, ol_d_id, ol_o_id, ol_number));", d.table("order_line")),
+	)
+}
+
+// loadStmts returns the statements populating the tables, the values are the same in each run.
+// Each customer has placed an order, the next order ids of the districts continue after them.
+func (t *tpcc) loadStmts(d dialect) []string {
+	var (
+		stmts []string
+		rows  []string
+	)
+	// adds a row, the rows of the same table are inserted in batches
+	insert := func(table, cols string, values string) {
+		rows = append(rows, values)
+		if len(rows) == d.batch {
+			stmts = append(stmts, fmt.Sprintf("INSERT INTO %v (%v) VALUES %v;", d.table(table), cols, strings.Join(rows, ", ")))
+			rows = nil
+		}
+	}
+	flush := func(table, cols string) {
+		if len(rows) > 0 {
+			stmts = append(stmts, fmt.Sprintf("INSERT INTO %v (%v) VALUES %v;", d.table(table), cols, strings.Join(rows, ", ")))
+			rows = nil
+		}
+	}
+	hash := func(name string, key, n int) uint64 {
+		return benchmark.Hash(name, int64(key)) % uint64(n)
+	}
+
+	const itemCols = "i_id, i_name, i_price"
+	for i := 1; i <= tpccItems; i++ {
+		insert("item", itemCols, fmt.Sprintf("(%d, 'item-%d', %d.%02d)", i, i, 1+hash("i_price", i, 100), hash("i_cents", i, 100)))
+	}
+	flush("item", itemCols)
+
+	const (
+		warehouseCols = "w_id, w_name, w_tax, w_ytd"
+		districtCols  = "d_w_id, d_id, d_name, d_tax, d_ytd, d_next_o_id"
+		customerCols  = "c_w_id, c_d_id, c_id, c_last, c_discount, c_balance, c_ytd_payment, c_payment_cnt"
+		stockCols     = "s_w_id, s_i_id, s_quantity, s_ytd, s_order_cnt"
+		ordersCols    = "o_w_id, o_d_id, o_id, o_c_id, o_entry_d, o_ol_cnt"
+		orderLineCols = "ol_w_id, ol_d_id, ol_o_id, ol_number, ol_i_id, ol_quantity, ol_amount"
+	)
+	for w := 1; w <= t.warehouses; w++ {
+		insert("warehouse", warehouseCols, fmt.Sprintf("(%d, 'wh-%d', 0.%04d, 300000.00)", w, w, hash("w_tax", w, 2000)))
+	}
+	flush("warehouse", warehouseCols)
+
+	for w := 1; w <= t.warehouses; w++ {
+		for dist := 1; dist <= tpccDistricts; dist++ {
+			insert("district", districtCols, fmt.Sprintf("(%d, %d, 'dist-%d', 0.%04d, 30000.00, %d)", w, dist, dist, hash("d_tax", w*tpccDistricts+dist, 2000), tpccCustomers+1))
+		}
+	}
+	flush("district", districtCols)
+
+	for w := 1; w <= t.warehouses; w++ {
+		for dist := 1; dist <= tpccDistricts; dist++ {
+			for c := 1; c <= tpccCustomers; c++ {
+				key := (w*tpccDistricts+dist)*tpccCustomers + c
+				insert("customer", customerCols, fmt.Sprintf("(%d, %d, %d, 'last-%d', 0.%04d, -10.00, 10.00, 1)", w, dist, c, hash("c_last", key, 1000), hash("c_discount", key, 5000)))
+			}
+		}
+	}
+	flush("customer", customerCols)
+
+	for w := 1; w <= t.warehouses; w++ {
+		for i := 1; i <= tpccItems; i++ {
+			insert("stock", stockCols, fmt.Sprintf("(%d, %d, %d, 0, 0)", w, i, 10+hash("s_quantity", w*tpccItems+i, 91)))
+		}
+	}
+	flush("stock", stockCols)
+
+	for w := 1; w <= t.warehouses; w++ {
+		for dist := 1; dist <= tpccDistricts; dist++ {
+			for o := 1; o <= tpccCustomers; o++ {
+				insert("orders", ordersCols, fmt.Sprintf("(%d, %d, %d, %d, CURRENT_TIMESTAMP, %d)", w, dist, o, o, tpccOrderLines))
+			}
+		}
+	}
+	flush("orders", ordersCols)
+
+	for w := 1; w <= t.warehouses; w++ {
+		for dist := 1; dist <= tpccDistricts; dist++ {
+			for o := 1; o <= tpccCustomers; o++ {
+				for n := 1; n <= tpccOrderLines; n++ {
+					key := ((w*tpccDistricts+dist)*tpccCustomers+o)*tpccOrderLines + n
+					insert("order_line", orderLineCols, fmt.Sprintf("(%d, %d, %d, %d, %d, %d, %d.00)", w, dist, o, n, 1+hash("ol_i_id", key, tpccItems), 1+hash("ol_quantity", key, 10), 1+hash("ol_amount", key, 9999)))
+				}
+			}
+		}
+	}
+	flush("order_line", orderLineCols)
+	return stmts
+}
+
+// benchmarks returns the transactions, each in a single round trip, and their mixture in the
+// proportions of TPC-C. The warehouse, district and customer are random, so the transactions
+// contend for the rows of the warehouses and districts.
+func (t *tpcc) benchmarks(d dialect) []benchmark.Benchmark {
+	var (
+		newOrder    = t.newOrder(d)
+		payment     = t.payment(d)
+		orderStatus = t.orderStatus(d)
+	)
+	return []benchmark.Benchmark{
+		{Name: "new_order", Type: benchmark.TypeLoop, Stmt: newOrder, Tags: writeTags},
+		{Name: "payment", Type: benchmark.TypeLoop, Stmt: payment, Tags: writeTags},
+		{Name: "order_status", Type: benchmark.TypeLoop, Stmt: orderStatus, Tags: readTags},
+		{Name: "tpcc", Type: benchmark.TypeLoop, Tags: writeTags, Mix: []benchmark.MixStmt{
+			{Name: "new_order", Weight: 45, Stmt: newOrder},
+			{Name: "payment", Weight: 43, Stmt: payment},
+			{Name: "order_status", Weight: 4, Stmt: orderStatus},
+		}},
+	}
+}
+
+// customer returns the template variables of a random customer of a random district.
+func (t *tpcc) customer() string {
+	return fmt.Sprintf("{{$w := .RandRange 1 %d}}{{$d := .RandRange 1 %d}}{{$c := .RandRange 1 %d}}", t.warehouses, tpccDistricts, tpccCustomers)
+}
+
+// newOrder returns the transaction taking the next order id of the district and inserting
+// the order with its lines, which reduce the stock of the items.
+func (t *tpcc) newOrder(d dialect) string {
+	var sb strings.Builder
+	sb.WriteString(t.customer())
+	sb.WriteString("BEGIN; ")
+	fmt.Fprintf(&sb, "UPDATE %v SET d_next_o_id = d_next_o_id + 1 WHERE d_w_id = {{$w}} AND d_id = {{$d}}; ", d.table("district"))
+	fmt.Fprintf(&sb, "INSERT INTO %v (o_w_id, o_d_id, o_id, o_c_id, o_entry_d, o_ol_cnt) SELECT d_w_id, d_id, d_next_o_id - 1, {{$c}}, CURRENT_TIMESTAMP, %d FROM %v WHERE d_w_id = {{$w}} AND d_id = {{$d}}; ",
+		d.table("orders"), tpccOrderLines, d.table("district"))
+	for n := 1; n <= tpccOrderLines; n++ {
+		fmt.Fprintf(&sb, "{{$i := .RandRange 1 %d}}{{$q := .RandRange 1 10}}", tpccItems)
+		fmt.Fprintf(&sb, "UPDATE %v SET s_quantity = CASE WHEN s_quantity >= {{$q}} + 10 THEN s_quantity - {{$q}} ELSE s_quantity - {{$q}} + 91 END, s_ytd = s_ytd + {{$q}}, s_order_cnt = s_order_cnt + 1 WHERE s_w_id = {{$w}} AND s_i_id = {{$i}}; ", d.table("stock"))
+		fmt.Fprintf(&sb, "INSERT INTO %v (ol_w_id, ol_d_id, ol_o_id, ol_number, ol_i_id, ol_quantity, ol_amount) SELECT d_w_id, d_id, d_next_o_id - 1, %d, i_id, {{$q}}, i_price * {{$q}} FROM %v, %v WHERE d_w_id = {{$w}} AND d_id = {{$d}} AND i_id = {{$i}}; ",
+			d.table("order_line"), n, d.table("district"), d.table("item"))
+	}
+	sb.WriteString("COMMIT;")
+	return sb.String()
+}
+
+// payment returns the transaction adding a payment of the customer to the year-to-date
+// balances of the warehouse, the district and the customer.
+func (t *tpcc) payment(d dialect) string {
+	return t.customer() + "{{$a := .RandRange 1 5000}}BEGIN; " +
+		fmt.Sprintf("UPDATE %v SET w_ytd = w_ytd + {{$a}} WHERE w_id = {{$w}}; ", d.table("warehouse")) +
+		fmt.Sprintf("UPDATE %v SET d_ytd = d_ytd + {{$a}} WHERE d_w_id = {{$w}} AND d_id = {{$d}}; ", d.table("district")) +
+		fmt.Sprintf("UPDATE %v SET c_balance = c_balance - {{$a}}, c_ytd_payment = c_ytd_payment + {{$a}}, c_payment_cnt = c_payment_cnt + 1 WHERE c_w_id = {{$w}} AND c_d_id = {{$d}} AND c_id = {{$c}}; ", d.table("customer")) +
+		fmt.Sprintf("INSERT INTO %v (h_c_w_id, h_c_d_id, h_c_id, h_w_id, h_d_id, h_date, h_amount) VALUES ({{$w}}, {{$d}}, {{$c}}, {{$w}}, {{$d}}, CURRENT_TIMESTAMP, {{$a}}); ", d.table("history")) +
+		"COMMIT;"
+}
+
+// orderStatus returns the read-only transaction querying the balance of the customer and
+// the lines of the last order.
+func (t *tpcc) orderStatus(d dialect) string {
+	last := fmt.Sprintf("SELECT max(o_id) FROM %v WHERE o_w_id = {{$w}} AND o_d_id = {{$d}} AND o_c_id = {{$c}}", d.table("orders"))
+	return t.customer() + "BEGIN; " +
+		fmt.Sprintf("SELECT c_balance, c_last FROM %v WHERE c_w_id = {{$w}} AND c_d_id = {{$d}} AND c_id = {{$c}}; ", d.table("customer")) +
+		fmt.Sprintf("SELECT o_id, o_entry_d, o_ol_cnt FROM %v WHERE o_w_id = {{$w}} AND o_d_id = {{$d}} AND o_id = (%v); ", d.table("orders"), last) +
+		fmt.Sprintf("SELECT ol_i_id, ol_quantity, ol_amount FROM %v WHERE ol_w_id = {{$w}} AND ol_d_id = {{$d}} AND ol_o_id = (%v); ", d.table("order_line"), last) +
+		"COMMIT;"
+}
+
+// dropTPCC drops the tables of the workload.
+func dropTPCC(db *sql.DB, d dialect) {
+	for _, table := range tpccTables {
+		if _, err := db.Exec(fmt.Sprintf("DROP TABLE %v", d.table(table))); err != nil {
+			log.Printf("failed to drop table: %v\n", err)
+		}
+	}
+}
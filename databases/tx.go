@@ -0,0 +1,43 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// execInTx executes the statements in a transaction, which is rolled back when a statement fails.
+func execInTx(ctx context.Context, db *sql.DB, stmts []string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// execTx executes the statements of a transaction (BEGIN; ...; COMMIT;) in a single round trip.
+// A failed transaction is rolled back, so it doesn't remain open on the connection returned to the pool.
+func execTx(ctx context.Context, db *sql.DB, stmt string) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err = conn.ExecContext(ctx, stmt); err != nil {
+		conn.ExecContext(context.Background(), "ROLLBACK")
+	}
+	return err
+}
+
+// isTx returns whether the statement is a whole transaction, see execTx.
+func isTx(stmt string) bool {
+	s := strings.ToUpper(strings.TrimSpace(stmt))
+	return strings.HasPrefix(s, "BEGIN") && strings.HasSuffix(strings.TrimSuffix(s, ";"), "COMMIT")
+}
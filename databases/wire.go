@@ -0,0 +1,65 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/sj14/dbbench/benchmark"
+)
+
+// wireDialer dials the connections of the Postgres driver, which are wrapped to measure
+// the latency of the wire protocol, see benchmark.SetWire.
+type wireDialer struct {
+	net.Dialer
+	addr string // dialed instead of the address of the host (empty -> host), see TLS.pqHost
+}
+
+func (d wireDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, address)
+}
+
+func (d wireDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return d.DialContext(ctx, network, address)
+}
+
+func (d wireDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if d.addr != "" {
+		address = d.addr
+	}
+	c, err := d.Dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	return benchmark.WireConn(c), nil
+}
+
+// openPostgres opens the connection pool of the Postgres driver with the wireDialer,
+// which dials the address instead of the host when given.
+func openPostgres(dataSourceName, addr string) (*sql.DB, error) {
+	connector, err := pq.NewConnector(dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	connector.Dialer(wireDialer{addr: addr})
+	return sql.OpenDB(connector), nil
+}
+
+// mysqlWire is the protocol of the MySQL data source names, whose TCP connections are wrapped
+// to measure the latency of the wire protocol, e.g. "user:password@wire(localhost:3306)/".
+const mysqlWire = "wire"
+
+func init() {
+	mysql.RegisterDial(mysqlWire, func(addr string) (net.Conn, error) {
+		c, err := net.Dial("tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		return benchmark.WireConn(c), nil
+	})
+}
@@ -0,0 +1,191 @@
+package dataset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ChunkOptions configures the seeding of rows in chunks.
+type ChunkOptions struct {
+	Chunk      int                 // rows per chunk
+	Threads    int                 // number of concurrently seeded chunks
+	Checkpoint string              // file recording the seeded chunks, so an interrupted seed resumes (empty -> none)
+	Progress   func(ChunkProgress) // called after each seeded chunk
+}
+
+// ChunkProgress is reported after each seeded chunk.
+type ChunkProgress struct {
+	From, To int           // rows of the chunk [From, To)
+	Done     int           // seeded chunks, including the ones of a previous seed
+	Chunks   int           // total chunks
+	Resumed  int           // chunks seeded by a previous seed
+	Took     time.Duration // since the seed started
+}
+
+// ETA returns the estimated time until all chunks are seeded, based on the chunks seeded so far.
+func (p ChunkProgress) ETA() time.Duration {
+	seeded := p.Done - p.Resumed
+	if seeded <= 0 {
+		return 0
+	}
+	return p.Took / time.Duration(seeded) * time.Duration(p.Chunks-p.Done)
+}
+
+// checkpoint is the content of the checkpoint file.
+type checkpoint struct {
+	Rows  int   `json:"rows"`
+	Chunk int   `json:"chunk"`
+	Done  []int `json:"done"` // indexes of the seeded chunks
+}
+
+// SeedChunks seeds the rows [0, rows) in chunks, which are passed to seed concurrently. The seeded
+// chunks are recorded in the checkpoint file, a later call with the same rows and chunk size only
+// seeds the missing ones, e.g. after an interrupted seed of billions of rows. Thus seed must replace
+// the rows of a chunk, which was partially seeded before. The checkpoint file is removed when all
+// chunks are seeded. When the context is cancelled, the running chunks finish and the seed stops.
+func SeedChunks(ctx context.Context, seed func(from, to int) error, rows int, opts ChunkOptions) error {
+	if rows <= 0 {
+		return nil
+	}
+	if opts.Chunk < 1 {
+		opts.Chunk = rows
+	}
+	if opts.Threads < 1 {
+		opts.Threads = 1
+	}
+	chunks := (rows + opts.Chunk - 1) / opts.Chunk
+
+	cp := checkpoint{Rows: rows, Chunk: opts.Chunk}
+	if opts.Checkpoint != "" {
+		prev, err := readCheckpoint(opts.Checkpoint)
+		if err != nil {
+			return err
+		}
+		if prev != nil {
+			if prev.Rows != rows || prev.Chunk != opts.Chunk {
+				return fmt.Errorf("checkpoint %v was written for %v rows in chunks of %v, remove it to seed %v rows in chunks of %v",
+					opts.Checkpoint, prev.Rows, prev.Chunk, rows, opts.Chunk)
+			}
+			cp.Done = prev.Done
+		}
+	}
+	done := make(map[int]bool, len(cp.Done))
+	for _, i := range cp.Done {
+		done[i] = true
+	}
+	resumed := len(done)
+
+	var (
+		start   = time.Now()
+		todo    = make(chan int)
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		seedErr error // of the first failed chunk
+	)
+	wg.Add(opts.Threads)
+	for t := 0; t < opts.Threads; t++ {
+		go func() {
+			defer wg.Done()
+			for i := range todo {
+				from, to := i*opts.Chunk, (i+1)*opts.Chunk
+				if to > rows {
+					to = rows
+				}
+				err := seed(from, to)
+
+				mu.Lock()
+				if err != nil {
+					if seedErr == nil {
+						seedErr = fmt.Errorf("failed to seed rows %v-%v: %v", from, to-1, err)
+					}
+					mu.Unlock()
+					continue
+				}
+				cp.Done = append(cp.Done, i)
+				if opts.Checkpoint != "" {
+					if err := writeCheckpoint(opts.Checkpoint, cp); err != nil && seedErr == nil {
+						seedErr = err
+					}
+				}
+				progress := ChunkProgress{From: from, To: to, Done: len(cp.Done), Chunks: chunks, Resumed: resumed, Took: time.Since(start)}
+				mu.Unlock()
+
+				if opts.Progress != nil {
+					opts.Progress(progress)
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < chunks; i++ {
+		if done[i] {
+			continue
+		}
+		mu.Lock()
+		failed := seedErr != nil
+		mu.Unlock()
+		if failed || ctx.Err() != nil {
+			break
+		}
+		select {
+		case todo <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(todo)
+	wg.Wait()
+
+	switch {
+	case seedErr != nil:
+		return seedErr
+	case ctx.Err() != nil:
+		return ctx.Err()
+	}
+	if opts.Checkpoint != "" {
+		if err := os.Remove(opts.Checkpoint); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// readCheckpoint returns the checkpoint of the file, nil when it doesn't exist.
+func readCheckpoint(path string) (*checkpoint, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cp checkpoint
+	if err := json.Unmarshal(b, &cp); err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint %v: %v", path, err)
+	}
+	return &cp, nil
+}
+
+// writeCheckpoint replaces the checkpoint file, the previous one stays intact when it fails.
+func writeCheckpoint(path string, cp checkpoint) error {
+	sort.Ints(cp.Done)
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	return nil
+}
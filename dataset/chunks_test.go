@@ -0,0 +1,140 @@
+package dataset
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// chunkRecorder records the seeded chunks.
+type chunkRecorder struct {
+	mu     sync.Mutex
+	chunks [][2]int
+	fail   int // from of the chunk, which fails (-1 -> none)
+}
+
+func (r *chunkRecorder) seed(from, to int) error {
+	if from == r.fail {
+		return errors.New("connection reset")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.chunks = append(r.chunks, [2]int{from, to})
+	return nil
+}
+
+func (r *chunkRecorder) sorted() [][2]int {
+	sort.Slice(r.chunks, func(i, j int) bool { return r.chunks[i][0] < r.chunks[j][0] })
+	return r.chunks
+}
+
+func TestSeedChunks(t *testing.T) {
+	// arrange
+	r := &chunkRecorder{fail: -1}
+	var progress []ChunkProgress
+
+	// act
+	err := SeedChunks(context.Background(), r.seed, 25, ChunkOptions{Chunk: 10, Threads: 1, Progress: func(p ChunkProgress) {
+		progress = append(progress, p)
+	}})
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, [][2]int{{0, 10}, {10, 20}, {20, 25}}, r.chunks)
+	require.Len(t, progress, 3)
+	require.Equal(t, 3, progress[2].Done)
+	require.Equal(t, 3, progress[2].Chunks)
+}
+
+func TestSeedChunksResume(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "seed.json")
+	opts := ChunkOptions{Chunk: 10, Threads: 3, Checkpoint: path}
+
+	// act, the chunk of the rows 20-29 fails
+	first := &chunkRecorder{fail: 20}
+	err = SeedChunks(context.Background(), first.seed, 50, opts)
+
+	// assert
+	require.Error(t, err)
+	require.FileExists(t, path)
+	cp, err := readCheckpoint(path)
+	require.NoError(t, err)
+	require.NotContains(t, cp.Done, 2)
+
+	// act, resume with the missing chunks
+	second := &chunkRecorder{fail: -1}
+	err = SeedChunks(context.Background(), second.seed, 50, opts)
+
+	// assert
+	require.NoError(t, err)
+	all := append(first.chunks, second.chunks...)
+	require.Len(t, all, 5)
+	require.Contains(t, second.chunks, [2]int{20, 30})
+	require.NotContains(t, second.chunks, [2]int{0, 10})
+	_, err = os.Stat(path)
+	require.True(t, os.IsNotExist(err), "checkpoint removed when done")
+}
+
+func TestSeedChunksCheckpointMismatch(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "seed.json")
+	require.NoError(t, writeCheckpoint(path, checkpoint{Rows: 100, Chunk: 10, Done: []int{0}}))
+	r := &chunkRecorder{fail: -1}
+
+	// act
+	err = SeedChunks(context.Background(), r.seed, 200, ChunkOptions{Chunk: 10, Checkpoint: path})
+
+	// assert
+	require.Error(t, err)
+	require.Empty(t, r.chunks)
+}
+
+func TestSeedChunksCancel(t *testing.T) {
+	// arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &chunkRecorder{fail: -1}
+	seed := func(from, to int) error {
+		cancel()
+		return r.seed(from, to)
+	}
+
+	// act
+	err := SeedChunks(ctx, seed, 100, ChunkOptions{Chunk: 10})
+
+	// assert, the running chunk finished
+	require.Equal(t, context.Canceled, err)
+	require.Equal(t, [][2]int{{0, 10}}, r.sorted())
+}
+
+func TestChunkProgressETA(t *testing.T) {
+	testCases := []struct {
+		description string
+		progress    ChunkProgress
+		want        time.Duration
+	}{
+		{description: "half", progress: ChunkProgress{Done: 5, Chunks: 10, Took: 10 * time.Second}, want: 10 * time.Second},
+		{description: "resumed", progress: ChunkProgress{Done: 6, Chunks: 10, Resumed: 4, Took: 10 * time.Second}, want: 20 * time.Second},
+		{description: "nothing seeded", progress: ChunkProgress{Done: 4, Chunks: 10, Resumed: 4}, want: 0},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.progress.ETA())
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package dataset
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// csvSource reads a CSV file with a header. Empty fields are NULL.
+type csvSource struct {
+	file *os.File
+	r    *csv.Reader
+	cols []Column
+}
+
+// openCSV opens the CSV file and infers the column types by reading it completely once.
+func openCSV(path string) (*csvSource, error) {
+	cols, err := inferCSV(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r := csv.NewReader(f)
+	r.ReuseRecord = true
+	if _, err := r.Read(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read header of %v: %v", path, err)
+	}
+	return &csvSource{file: f, r: r, cols: cols}, nil
+}
+
+// inferCSV returns the columns of the CSV file with the most specific type fitting all values.
+func inferCSV(path string) ([]Column, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.ReuseRecord = true
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header of %v: %v", path, err)
+	}
+
+	cols := make([]Column, len(header))
+	seen := make([]bool, len(header))
+	for i, name := range header {
+		cols[i] = Column{Name: strings.TrimSpace(name), Type: TypeBool}
+	}
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %v: %v", path, err)
+		}
+		for i, field := range record {
+			if field == "" {
+				continue
+			}
+			seen[i] = true
+			for cols[i].Type < TypeText && !fits(field, cols[i].Type) {
+				cols[i].Type++
+			}
+		}
+	}
+
+	// columns without any value
+	for i := range cols {
+		if !seen[i] {
+			cols[i].Type = TypeText
+		}
+	}
+	return cols, nil
+}
+
+// fits returns whether the field can be parsed as the type.
+func fits(field string, t Type) bool {
+	switch t {
+	case TypeBool:
+		_, err := strconv.ParseBool(field)
+		// no numbers, they fit the integers
+		return err == nil && field != "0" && field != "1"
+	case TypeInt:
+		_, err := strconv.ParseInt(field, 10, 64)
+		return err == nil
+	case TypeFloat:
+		f, err := strconv.ParseFloat(field, 64)
+		return err == nil && !math.IsNaN(f) && !math.IsInf(f, 0)
+	default:
+		return true
+	}
+}
+
+func (s *csvSource) Columns() []Column {
+	return s.cols
+}
+
+func (s *csvSource) Read() ([]interface{}, error) {
+	record, err := s.r.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	row := make([]interface{}, len(record))
+	for i, field := range record {
+		if field == "" {
+			continue
+		}
+		switch s.cols[i].Type {
+		case TypeBool:
+			row[i], _ = strconv.ParseBool(field)
+		case TypeInt:
+			row[i], _ = strconv.ParseInt(field, 10, 64)
+		case TypeFloat:
+			row[i], _ = strconv.ParseFloat(field, 64)
+		default:
+			row[i] = field
+		}
+	}
+	return row, nil
+}
+
+func (s *csvSource) Close() error {
+	return s.file.Close()
+}
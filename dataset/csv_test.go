@@ -0,0 +1,68 @@
+package dataset
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenCSV(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "users.csv")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`id,name,score,active,note
+1,Markus,1.5,true,
+2,O'Reilly,2,false,
+3,,,FALSE,
+`), 0600))
+
+	// act
+	src, err := Open(path)
+	require.NoError(t, err)
+	defer src.Close()
+
+	var rows [][]interface{}
+	for {
+		row, err := src.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+
+	// assert
+	require.Equal(t, []Column{{"id", TypeInt}, {"name", TypeText}, {"score", TypeFloat}, {"active", TypeBool}, {"note", TypeText}}, src.Columns())
+	require.Equal(t, [][]interface{}{
+		{int64(1), "Markus", 1.5, true, nil},
+		{int64(2), "O'Reilly", 2.0, false, nil},
+		{int64(3), nil, nil, false, nil},
+	}, rows)
+}
+
+func TestFits(t *testing.T) {
+	for _, tc := range []struct {
+		field string
+		typ   Type
+		want  bool
+	}{
+		{"true", TypeBool, true},
+		{"1", TypeBool, false},
+		{"1", TypeInt, true},
+		{"1.5", TypeInt, false},
+		{"1e3", TypeFloat, true},
+		{"NaN", TypeFloat, false},
+		{"abc", TypeText, true},
+	} {
+		if got := fits(tc.field, tc.typ); got != tc.want {
+			t.Errorf("fits(%q, %v) = %v, want %v", tc.field, tc.typ, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+// Package dataset loads user-provided CSV and Parquet files into tables, e.g. to run the
+// benchmarks against production-shaped data instead of synthetic rows.
+package dataset
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Type is the type of a column, inferred from the values of a CSV file or the Parquet schema.
+type Type int
+
+// Column types, ordered from the most to the least specific.
+const (
+	TypeBool Type = iota
+	TypeInt
+	TypeFloat
+	TypeText
+)
+
+// SQL returns the column type used when creating the table.
+func (t Type) SQL() string {
+	switch t {
+	case TypeBool:
+		return "BOOLEAN"
+	case TypeInt:
+		return "BIGINT"
+	case TypeFloat:
+		return "DOUBLE PRECISION"
+	default:
+		return "TEXT"
+	}
+}
+
+// Column is a column of a dataset.
+type Column struct {
+	Name string
+	Type Type
+}
+
+// Source reads the rows of a dataset. The values of a row are nil (NULL), bool, int64,
+// float64 or string, according to the column types.
+type Source interface {
+	Columns() []Column
+	// Read returns the next row or io.EOF after the last one.
+	Read() ([]interface{}, error)
+	Close() error
+}
+
+// Open opens the CSV (.csv) or Parquet (.parquet) file at path.
+func Open(path string) (Source, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return openCSV(path)
+	case ".parquet":
+		return openParquet(path)
+	default:
+		return nil, fmt.Errorf("unknown dataset format of %v, must be .csv or .parquet", path)
+	}
+}
+
+// Dialect is the SQL dialect of the statements loading a dataset.
+type Dialect int
+
+const (
+	// DialectANSI quotes identifiers with double quotes and escapes only quotes in string literals,
+	// e.g. for postgres, sqlite, mssql and cassandra.
+	DialectANSI Dialect = iota
+	// DialectMySQL quotes identifiers with backticks and also escapes backslashes in string literals,
+	// e.g. for mysql and clickhouse.
+	DialectMySQL
+)
+
+// Options configures the loading of a dataset.
+type Options struct {
+	Batch   int     // rows per insert statement
+	Threads int     // number of concurrent insert statements
+	Create  bool    // create the table, unless it exists
+	Quote   bool    // quote the column names, e.g. the headers of a CSV file like "first name" or "order"
+	Dialect Dialect // of the statements
+}
+
+// Load inserts the rows of the source into the table with multi-row inserts, executed
+// concurrently by exec. It returns the number of read rows and the first failed insert.
+func Load(exec func(string) error, table string, src Source, opts Options) (int, error) {
+	cols := src.Columns()
+
+	// Some databases don't support IF NOT EXISTS, a failed create only matters when the inserts fail.
+	var createErr error
+	if opts.Create {
+		createErr = exec(createStmt(table, cols, opts))
+	}
+	if opts.Batch < 1 {
+		opts.Batch = 1
+	}
+	if opts.Threads < 1 {
+		opts.Threads = 1
+	}
+
+	var (
+		stmts   = make(chan string, opts.Threads)
+		wg      = &sync.WaitGroup{}
+		mu      sync.Mutex
+		execErr error // of the first failed insert
+	)
+	wg.Add(opts.Threads)
+	for i := 0; i < opts.Threads; i++ {
+		go func() {
+			defer wg.Done()
+			for stmt := range stmts {
+				if err := exec(stmt); err != nil {
+					mu.Lock()
+					if execErr == nil {
+						execErr = fmt.Errorf("failed to insert rows: %v", err)
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	var (
+		rows  int
+		batch [][]interface{}
+		err   error
+	)
+	for {
+		var row []interface{}
+		if row, err = src.Read(); err != nil {
+			break
+		}
+		batch = append(batch, row)
+		rows++
+		if len(batch) == opts.Batch {
+			stmts <- insertStmt(table, cols, batch, opts)
+			batch = nil
+		}
+	}
+	if len(batch) > 0 && err == io.EOF {
+		stmts <- insertStmt(table, cols, batch, opts)
+	}
+	close(stmts)
+	wg.Wait()
+
+	switch {
+	case err != io.EOF:
+		return rows, err
+	case execErr != nil && createErr != nil:
+		return rows, fmt.Errorf("failed to create table: %v", createErr)
+	}
+	return rows, execErr
+}
+
+// createStmt returns the statement creating the table with the columns.
+func createStmt(table string, cols []Column, opts Options) string {
+	defs := make([]string, len(cols))
+	for i, c := range cols {
+		defs[i] = columnName(c.Name, opts) + " " + c.Type.SQL()
+	}
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %v (%v);", table, strings.Join(defs, ", "))
+}
+
+// insertStmt returns the statement inserting the rows into the table.
+func insertStmt(table string, cols []Column, rows [][]interface{}, opts Options) string {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = columnName(c.Name, opts)
+	}
+
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "INSERT INTO %v (%v) VALUES ", table, strings.Join(names, ", "))
+	for i, row := range rows {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("(")
+		for j, v := range row {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(literal(v, opts.Dialect))
+		}
+		sb.WriteString(")")
+	}
+	sb.WriteString(";")
+	return sb.String()
+}
+
+// columnName returns the name of the column in the statements, quoted when Options.Quote is set.
+func columnName(name string, opts Options) string {
+	if !opts.Quote {
+		return name
+	}
+	q := `"`
+	if opts.Dialect == DialectMySQL {
+		q = "`"
+	}
+	return q + strings.Replace(name, q, q+q, -1) + q
+}
+
+// literal returns the SQL literal of the value.
+func literal(v interface{}, dialect Dialect) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "NULL"
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		s := fmt.Sprint(v)
+		if dialect == DialectMySQL {
+			// a backslash escapes the next character, unless NO_BACKSLASH_ESCAPES is set
+			s = strings.Replace(s, `\`, `\\`, -1)
+		}
+		return "'" + strings.Replace(s, "'", "''", -1) + "'"
+	}
+}
@@ -0,0 +1,160 @@
+package dataset
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rowsSource is a source of the given rows.
+type rowsSource struct {
+	cols []Column
+	rows [][]interface{}
+}
+
+func (s *rowsSource) Columns() []Column { return s.cols }
+
+func (s *rowsSource) Read() ([]interface{}, error) {
+	if len(s.rows) == 0 {
+		return nil, io.EOF
+	}
+	row := s.rows[0]
+	s.rows = s.rows[1:]
+	return row, nil
+}
+
+func (s *rowsSource) Close() error { return nil }
+
+func TestLoad(t *testing.T) {
+	// arrange
+	src := &rowsSource{
+		cols: []Column{{"id", TypeInt}, {"name", TypeText}, {"score", TypeFloat}, {"active", TypeBool}},
+		rows: [][]interface{}{
+			{int64(1), "O'Reilly", 1.5, true},
+			{int64(2), nil, 2.0, false},
+			{int64(3), "Moen", nil, nil},
+		},
+	}
+	var (
+		mu    sync.Mutex
+		stmts []string
+	)
+	exec := func(stmt string) error {
+		mu.Lock()
+		stmts = append(stmts, stmt)
+		mu.Unlock()
+		return nil
+	}
+
+	// act
+	n, err := Load(exec, "dbbench.users", src, Options{Batch: 2, Threads: 2, Create: true})
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Equal(t, "CREATE TABLE IF NOT EXISTS dbbench.users (id BIGINT, name TEXT, score DOUBLE PRECISION, active BOOLEAN);", stmts[0])
+	inserts := stmts[1:]
+	sort.Strings(inserts)
+	require.Equal(t, []string{
+		"INSERT INTO dbbench.users (id, name, score, active) VALUES (1, 'O''Reilly', 1.5, TRUE), (2, NULL, 2, FALSE);",
+		"INSERT INTO dbbench.users (id, name, score, active) VALUES (3, 'Moen', NULL, NULL);",
+	}, inserts)
+}
+
+func TestLoadError(t *testing.T) {
+	testCases := []struct {
+		name    string
+		failing string // prefix of the failing statements
+		wantErr string
+	}{
+		{name: "existing table", failing: "CREATE", wantErr: ""},
+		{name: "failed insert", failing: "INSERT", wantErr: "failed to insert rows: boom"},
+		{name: "failed create", failing: "", wantErr: "failed to create table: boom"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			src := &rowsSource{cols: []Column{{"id", TypeInt}}, rows: [][]interface{}{{int64(1)}, {int64(2)}}}
+			exec := func(stmt string) error {
+				if strings.HasPrefix(stmt, tt.failing) {
+					return errors.New("boom")
+				}
+				return nil
+			}
+
+			// act
+			n, err := Load(exec, "t", src, Options{Batch: 1, Threads: 2, Create: true})
+
+			// assert
+			require.Equal(t, 2, n)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoadQuote(t *testing.T) {
+	testCases := []struct {
+		description string
+		opts        Options
+		wantCreate  string
+		wantInsert  string
+	}{
+		{
+			description: "unquoted",
+			opts:        Options{},
+			wantCreate:  `CREATE TABLE IF NOT EXISTS t (first name TEXT, order BIGINT);`,
+			wantInsert:  `INSERT INTO t (first name, order) VALUES ('C:\dir\', 1);`,
+		},
+		{
+			description: "ansi",
+			opts:        Options{Quote: true, Dialect: DialectANSI},
+			wantCreate:  `CREATE TABLE IF NOT EXISTS t ("first name" TEXT, "order" BIGINT);`,
+			wantInsert:  `INSERT INTO t ("first name", "order") VALUES ('C:\dir\', 1);`,
+		},
+		{
+			description: "mysql",
+			opts:        Options{Quote: true, Dialect: DialectMySQL},
+			wantCreate:  "CREATE TABLE IF NOT EXISTS t (`first name` TEXT, `order` BIGINT);",
+			wantInsert:  "INSERT INTO t (`first name`, `order`) VALUES ('C:\\\\dir\\\\', 1);",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			// arrange
+			src := &rowsSource{
+				cols: []Column{{"first name", TypeText}, {"order", TypeInt}},
+				rows: [][]interface{}{{`C:\dir\`, int64(1)}},
+			}
+			var stmts []string
+			exec := func(stmt string) error {
+				stmts = append(stmts, stmt)
+				return nil
+			}
+			tt.opts.Threads = 1
+			tt.opts.Create = true
+
+			// act
+			_, err := Load(exec, "t", src, tt.opts)
+
+			// assert
+			require.NoError(t, err)
+			require.Equal(t, []string{tt.wantCreate, tt.wantInsert}, stmts)
+		})
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	require.Equal(t, `"a""b"`, columnName(`a"b`, Options{Quote: true}))
+	require.Equal(t, "`a``b`", columnName("a`b", Options{Quote: true, Dialect: DialectMySQL}))
+}
@@ -0,0 +1,297 @@
+package dataset
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SchemaColumn is a column of a table in a schema dump.
+type SchemaColumn struct {
+	Name string
+	Type string // lower case type without its size, e.g. "character varying"
+	Size int    // max. length of the values, e.g. of varchar(20), 0 when unlimited
+	Key  bool   // part of the primary key
+
+	// distribution of the generated values, see SetDistributions
+	Nulls float64 // probability of NULL
+	Skew  float64 // exponent of the Zipf distribution of the values, 0 when uniform
+}
+
+// SchemaTable is a table in a schema dump.
+type SchemaTable struct {
+	Name    string
+	Columns []SchemaColumn // without the columns generated by the database, e.g. serial
+}
+
+// Schema is a parsed schema dump, e.g. of pg_dump --schema-only or mysqldump --no-data.
+type Schema struct {
+	Tables []SchemaTable // in the order of the dump
+	Create []string      // statements creating the schemas and tables
+	Other  []string      // remaining statements, e.g. indexes and constraints, executed after loading the data
+}
+
+var (
+	createTable = regexp.MustCompile(`(?is)^CREATE\s+(?:(?:GLOBAL|LOCAL)\s+)?(?:(?:TEMP|TEMPORARY|UNLOGGED)\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([^\s(]+)\s*\((.*)\)[^)]*;?$`)
+	createOther = regexp.MustCompile(`(?is)^CREATE\s+(SCHEMA|DATABASE|TYPE|DOMAIN|EXTENSION|SEQUENCE)\b`)
+	primaryKey  = regexp.MustCompile(`(?is)PRIMARY\s+KEY\s*\(([^)]*)\)`)
+	// statements, which only affect a single connection of the pool or need the roles of production
+	ignored = regexp.MustCompile(`(?is)^(SET\s|SELECT\s+pg_catalog\.set_config|GRANT\s|REVOKE\s|ALTER\s.*\sOWNER\s+TO\s)`)
+)
+
+// ParseSchema parses the statements of a schema dump.
+func ParseSchema(ddl string) (*Schema, error) {
+	s := &Schema{}
+	for _, stmt := range splitStatements(ddl) {
+		switch {
+		case ignored.MatchString(stmt):
+			continue
+		case createTable.MatchString(stmt):
+			m := createTable.FindStringSubmatch(stmt)
+			t, err := parseTable(m[1], m[2])
+			if err != nil {
+				return nil, err
+			}
+			s.Tables = append(s.Tables, t)
+			s.Create = append(s.Create, stmt)
+		case createOther.MatchString(stmt):
+			s.Create = append(s.Create, stmt)
+		default:
+			s.Other = append(s.Other, stmt)
+		}
+	}
+	if len(s.Tables) == 0 {
+		return nil, fmt.Errorf("no CREATE TABLE statements found")
+	}
+	return s, nil
+}
+
+// parseTable parses the column and constraint definitions of a CREATE TABLE statement.
+func parseTable(name, body string) (SchemaTable, error) {
+	t := SchemaTable{Name: name}
+	keys := map[string]bool{}
+
+	for _, def := range splitTopLevel(body, ',') {
+		words := strings.Fields(def)
+		if len(words) == 0 {
+			continue
+		}
+		switch strings.ToUpper(words[0]) {
+		case "CONSTRAINT", "PRIMARY", "UNIQUE", "FOREIGN", "CHECK", "KEY", "INDEX", "EXCLUDE", "FULLTEXT", "SPATIAL":
+			if m := primaryKey.FindStringSubmatch(def); m != nil {
+				for _, col := range strings.Split(m[1], ",") {
+					keys[unquote(strings.Fields(col)[0])] = true
+				}
+			}
+			continue
+		}
+		if len(words) < 2 {
+			return t, fmt.Errorf("invalid column definition %q of table %v", def, name)
+		}
+
+		col, generated := parseColumn(words)
+		if !generated {
+			t.Columns = append(t.Columns, col)
+		}
+	}
+
+	for i, col := range t.Columns {
+		if keys[unquote(col.Name)] {
+			t.Columns[i].Key = true
+		}
+	}
+	if len(t.Columns) == 0 {
+		return t, fmt.Errorf("table %v has no columns to fill", name)
+	}
+	return t, nil
+}
+
+// constraintWords end the type of a column definition.
+var constraintWords = map[string]bool{
+	"NOT": true, "NULL": true, "DEFAULT": true, "PRIMARY": true, "REFERENCES": true, "UNIQUE": true, "CHECK": true,
+	"GENERATED": true, "COLLATE": true, "CONSTRAINT": true, "AUTO_INCREMENT": true, "IDENTITY": true, "COMMENT": true,
+}
+
+// parseColumn parses the words of a column definition and returns, whether its values are
+// generated by the database.
+func parseColumn(words []string) (SchemaColumn, bool) {
+	col := SchemaColumn{Name: words[0]}
+
+	var typ []string
+	rest := words[1:]
+	for len(rest) > 0 && !constraintWords[strings.ToUpper(rest[0])] {
+		typ = append(typ, rest[0])
+		rest = rest[1:]
+	}
+	col.Type = strings.ToLower(strings.Join(typ, " "))
+	if i := strings.Index(col.Type, "("); i >= 0 {
+		size := strings.Split(strings.Trim(col.Type[i:], "()"), ",")[0]
+		col.Size, _ = strconv.Atoi(strings.TrimSpace(size))
+		col.Type = strings.TrimSpace(col.Type[:i])
+	}
+
+	constraints := strings.ToUpper(strings.Join(rest, " "))
+	if strings.Contains(constraints, "PRIMARY KEY") {
+		col.Key = true
+	}
+	generated := strings.HasSuffix(col.Type, "serial") ||
+		strings.Contains(constraints, "AUTO_INCREMENT") ||
+		strings.Contains(constraints, "GENERATED") ||
+		strings.Contains(constraints, "IDENTITY") ||
+		strings.Contains(constraints, "NEXTVAL(")
+	return col, generated
+}
+
+// unquote removes the quotes of an identifier.
+func unquote(ident string) string {
+	return strings.Trim(ident, "\"`[]")
+}
+
+// splitStatements splits the statements at the semicolons outside of quotes, dollar quotes
+// and comments. The comments are removed.
+func splitStatements(ddl string) []string {
+	var (
+		stmts []string
+		sb    strings.Builder
+	)
+	for i := 0; i < len(ddl); i++ {
+		c := ddl[i]
+		switch {
+		case c == '-' && strings.HasPrefix(ddl[i:], "--"):
+			end := strings.IndexByte(ddl[i:], '\n')
+			if end < 0 {
+				end = len(ddl) - i
+			}
+			i += end - 1
+			continue
+		case c == '/' && strings.HasPrefix(ddl[i:], "/*"):
+			end := strings.Index(ddl[i:], "*/")
+			if end < 0 {
+				end = len(ddl) - i - 2
+			}
+			i += end + 1
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			end := strings.IndexByte(ddl[i+1:], c)
+			if end < 0 {
+				end = len(ddl) - i - 2
+			}
+			sb.WriteString(ddl[i : i+end+2])
+			i += end + 1
+			continue
+		case c == '$':
+			// dollar quotes of postgres, e.g. $$ or $body$
+			if m := dollarQuote.FindString(ddl[i:]); m != "" {
+				end := strings.Index(ddl[i+len(m):], m)
+				if end < 0 {
+					end = len(ddl) - i - 2*len(m)
+				}
+				sb.WriteString(ddl[i : i+end+2*len(m)])
+				i += end + 2*len(m) - 1
+				continue
+			}
+		case c == ';':
+			sb.WriteByte(c)
+			if stmt := strings.TrimSpace(sb.String()); stmt != ";" {
+				stmts = append(stmts, stmt)
+			}
+			sb.Reset()
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	if stmt := strings.TrimSpace(sb.String()); stmt != "" {
+		stmts = append(stmts, stmt)
+	}
+	return stmts
+}
+
+var dollarQuote = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+
+// splitTopLevel splits s at the separator outside of parentheses and quotes.
+func splitTopLevel(s string, sep byte) []string {
+	var (
+		parts []string
+		depth int
+		quote byte
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
+
+// SetDistributions sets the probability of NULL values and the skew of the generated values of the
+// columns, given as "table.column" or "column" of all tables, e.g. {"orders.note": "0.3"}.
+// The skew is the exponent (> 1) of a Zipf distribution, the larger the more often the most
+// common values are repeated.
+func (s *Schema) SetDistributions(nulls, skew map[string]string) error {
+	for name, v := range nulls {
+		p, err := strconv.ParseFloat(v, 64)
+		if err != nil || p < 0 || p > 1 {
+			return fmt.Errorf("invalid NULL probability %q of %v, must be between 0.0 and 1.0", v, name)
+		}
+		if err := s.setColumns(name, func(c *SchemaColumn) { c.Nulls = p }); err != nil {
+			return err
+		}
+	}
+	for name, v := range skew {
+		e, err := strconv.ParseFloat(v, 64)
+		if err != nil || e <= 1 {
+			return fmt.Errorf("invalid skew %q of %v, must be greater than 1.0", v, name)
+		}
+		if err := s.setColumns(name, func(c *SchemaColumn) { c.Skew = e }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setColumns calls set for each column matching the name, "table.column" or "column" of all tables.
+// The tables match without their schema and the identifiers without quotes.
+func (s *Schema) setColumns(name string, set func(*SchemaColumn)) error {
+	table, column := "", name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		table, column = name[:i], name[i+1:]
+	}
+
+	found := false
+	for i := range s.Tables {
+		t := &s.Tables[i]
+		if table != "" && !strings.EqualFold(unquote(t.Name[strings.LastIndex(t.Name, ".")+1:]), unquote(table)) {
+			continue
+		}
+		for j := range t.Columns {
+			c := &t.Columns[j]
+			if !strings.EqualFold(unquote(c.Name), unquote(column)) {
+				continue
+			}
+			if c.Key {
+				return fmt.Errorf("column %v is part of the primary key, its values are unique", name)
+			}
+			set(c)
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("column %v not found in the schema dump", name)
+	}
+	return nil
+}
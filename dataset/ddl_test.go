@@ -0,0 +1,120 @@
+package dataset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchema(t *testing.T) {
+	// arrange
+	ddl := `-- dumped from production
+SET statement_timeout = 0;
+SELECT pg_catalog.set_config('search_path', '', false);
+CREATE SCHEMA shop;
+CREATE TABLE shop.users (
+    id bigserial PRIMARY KEY,
+    email character varying(64) NOT NULL,
+    score numeric(10,2) DEFAULT 0,
+    active boolean,
+    created timestamp without time zone
+);
+ALTER TABLE shop.users OWNER TO admin;
+CREATE TABLE shop.orders (
+    user_id bigint NOT NULL,
+    line int NOT NULL,
+    note text, -- "a comment; with a semicolon"
+    CONSTRAINT orders_pkey PRIMARY KEY (user_id, line)
+);
+CREATE FUNCTION shop.touch() RETURNS trigger AS $$ BEGIN RETURN NEW; END; $$ LANGUAGE plpgsql;
+CREATE INDEX users_email ON shop.users (email);
+`
+
+	// act
+	s, err := ParseSchema(ddl)
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, []SchemaTable{
+		{Name: "shop.users", Columns: []SchemaColumn{
+			{Name: "email", Type: "character varying", Size: 64},
+			{Name: "score", Type: "numeric", Size: 10},
+			{Name: "active", Type: "boolean"},
+			{Name: "created", Type: "timestamp without time zone"},
+		}},
+		{Name: "shop.orders", Columns: []SchemaColumn{
+			{Name: "user_id", Type: "bigint", Key: true},
+			{Name: "line", Type: "int", Key: true},
+			{Name: "note", Type: "text"},
+		}},
+	}, s.Tables)
+	require.Len(t, s.Create, 3)
+	require.Equal(t, []string{
+		"CREATE FUNCTION shop.touch() RETURNS trigger AS $$ BEGIN RETURN NEW; END; $$ LANGUAGE plpgsql;",
+		"CREATE INDEX users_email ON shop.users (email);",
+	}, s.Other)
+}
+
+func TestParseSchemaInvalid(t *testing.T) {
+	for _, ddl := range []string{
+		"CREATE INDEX i ON t (c);",
+		"CREATE TABLE t (id serial);",
+	} {
+		_, err := ParseSchema(ddl)
+		require.Error(t, err, ddl)
+	}
+}
+
+func TestSetDistributions(t *testing.T) {
+	ddl := `CREATE TABLE public.orders (id bigint PRIMARY KEY, "Note" text, status text);
+CREATE TABLE users (id bigint PRIMARY KEY, status text);`
+
+	testCases := []struct {
+		description string
+		nulls       map[string]string
+		skew        map[string]string
+		wantErr     bool
+	}{
+		{description: "table and column", nulls: map[string]string{"orders.note": "0.3"}, skew: map[string]string{"orders.status": "1.5"}},
+		{description: "column of all tables", nulls: map[string]string{"status": "0.1"}},
+		{description: "unknown column", nulls: map[string]string{"orders.missing": "0.1"}, wantErr: true},
+		{description: "primary key", skew: map[string]string{"id": "1.5"}, wantErr: true},
+		{description: "invalid probability", nulls: map[string]string{"note": "2"}, wantErr: true},
+		{description: "invalid skew", skew: map[string]string{"note": "1"}, wantErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			// arrange
+			s, err := ParseSchema(ddl)
+			require.NoError(t, err)
+
+			// act
+			err = s.SetDistributions(tt.nulls, tt.skew)
+
+			// assert
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSetDistributionsColumns(t *testing.T) {
+	// arrange
+	s, err := ParseSchema(`CREATE TABLE public.orders (id bigint PRIMARY KEY, "Note" text, status text);
+CREATE TABLE users (id bigint PRIMARY KEY, status text);`)
+	require.NoError(t, err)
+
+	// act
+	err = s.SetDistributions(map[string]string{"status": "0.1"}, map[string]string{"orders.note": "1.5"})
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, 0.1, s.Tables[0].Columns[2].Nulls)
+	require.Equal(t, 0.1, s.Tables[1].Columns[1].Nulls)
+	require.Zero(t, s.Tables[0].Columns[1].Nulls)
+	require.Equal(t, 1.5, s.Tables[0].Columns[1].Skew)
+}
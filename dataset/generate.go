@@ -0,0 +1,222 @@
+package dataset
+
+import (
+	"hash/fnv"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+)
+
+// generator is a source of synthetic rows fitting the column types of a table.
+type generator struct {
+	table SchemaTable
+	rows  int
+	row   int
+	rand  *rand.Rand
+	fake  *gofakeit.Faker
+
+	// of the skewed columns, see skewed
+	zipfs  map[string]*rand.Zipf
+	values map[string]map[uint64]interface{}
+}
+
+// epoch is the reference time of the generated dates, so they are the same in each run.
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Generate returns a source of n synthetic rows of the table. The values of the primary key
+// columns are the row numbers 1 to n, the other integers are between 1 and n, so foreign keys
+// referencing the primary keys of other tables with n rows are satisfied.
+// The rows are seeded by the table name, each run generates the same rows.
+// The columns may have NULL values and skewed values, see Schema.SetDistributions.
+func Generate(table SchemaTable, n int) Source {
+	seed := seedOf(table.Name)
+	return &generator{
+		table:  table,
+		rows:   n,
+		rand:   rand.New(rand.NewSource(seed)),
+		fake:   gofakeit.New(seed),
+		zipfs:  map[string]*rand.Zipf{},
+		values: map[string]map[uint64]interface{}{},
+	}
+}
+
+// seedOf returns the seed of the name.
+func seedOf(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+// columnType returns the type of the generated values.
+func columnType(col SchemaColumn) Type {
+	switch {
+	case isInt(col.Type):
+		return TypeInt
+	case isFloat(col.Type):
+		return TypeFloat
+	case col.Type == "bool" || col.Type == "boolean":
+		return TypeBool
+	default:
+		return TypeText
+	}
+}
+
+func isInt(typ string) bool {
+	switch typ {
+	case "int", "integer", "bigint", "smallint", "tinyint", "mediumint", "int2", "int4", "int8":
+		return true
+	}
+	return false
+}
+
+func isFloat(typ string) bool {
+	switch typ {
+	case "numeric", "decimal", "real", "float", "double", "double precision", "float4", "float8", "money":
+		return true
+	}
+	return false
+}
+
+func (g *generator) Columns() []Column {
+	cols := make([]Column, len(g.table.Columns))
+	for i, c := range g.table.Columns {
+		cols[i] = Column{Name: c.Name, Type: columnType(c)}
+	}
+	return cols
+}
+
+func (g *generator) Read() ([]interface{}, error) {
+	if g.row == g.rows {
+		return nil, io.EOF
+	}
+	g.row++
+
+	row := make([]interface{}, len(g.table.Columns))
+	for i, col := range g.table.Columns {
+		switch {
+		case col.Nulls > 0 && g.rand.Float64() < col.Nulls:
+			row[i] = nil
+		case col.Skew > 1:
+			row[i] = g.skewed(col)
+		default:
+			row[i] = g.value(col)
+		}
+	}
+	return row, nil
+}
+
+// intRange returns the number of values of an integer column, 1 to n.
+func (g *generator) intRange(col SchemaColumn) int64 {
+	n := int64(g.rows)
+	// the ranges of small integers
+	if col.Type == "tinyint" && n > 127 {
+		n = 127
+	} else if (col.Type == "smallint" || col.Type == "int2") && n > 32767 {
+		n = 32767
+	}
+	return n
+}
+
+// skewed returns a value of the column chosen by a Zipf distribution of its possible values:
+// the first is the most common one, followed by the second and so on. Integers are 1 to n,
+// other values are generated from a seed of the column and their rank, thus they repeat.
+func (g *generator) skewed(col SchemaColumn) interface{} {
+	n := int64(g.rows)
+	if columnType(col) == TypeInt {
+		n = g.intRange(col)
+	}
+	if n <= 1 {
+		return g.value(col)
+	}
+
+	z, ok := g.zipfs[col.Name]
+	if !ok {
+		z = rand.NewZipf(g.rand, col.Skew, 1, uint64(n-1))
+		g.zipfs[col.Name] = z
+	}
+	k := z.Uint64()
+	if columnType(col) == TypeInt {
+		return int64(1 + k)
+	}
+
+	values, ok := g.values[col.Name]
+	if !ok {
+		values = map[uint64]interface{}{}
+		g.values[col.Name] = values
+	}
+	v, ok := values[k]
+	if !ok {
+		seed := seedOf(g.table.Name+"."+col.Name) ^ int64(k)
+		ranked := &generator{table: g.table, rows: g.rows, row: int(k) + 1, rand: rand.New(rand.NewSource(seed)), fake: gofakeit.New(seed)}
+		v = ranked.value(col)
+		values[k] = v
+	}
+	return v
+}
+
+// value returns a random value of the column in the current row.
+func (g *generator) value(col SchemaColumn) interface{} {
+	switch columnType(col) {
+	case TypeInt:
+		if col.Key {
+			return int64(g.row)
+		}
+		return 1 + g.rand.Int63n(g.intRange(col))
+	case TypeFloat:
+		return float64(g.rand.Int63n(100000)) / 100
+	case TypeBool:
+		return g.rand.Intn(2) == 1
+	}
+
+	past := epoch.Add(-time.Duration(g.rand.Int63n(int64(5 * 365 * 24 * time.Hour))))
+
+	var v string
+	switch {
+	case strings.HasPrefix(col.Type, "timestamp") || col.Type == "datetime":
+		v = past.Format("2006-01-02 15:04:05")
+	case col.Type == "date":
+		v = past.Format("2006-01-02")
+	case strings.HasPrefix(col.Type, "time"):
+		v = past.Format("15:04:05")
+	case col.Type == "uuid":
+		v = g.fake.UUID()
+	case col.Type == "json" || col.Type == "jsonb":
+		v = `{"value": "` + g.fake.Word() + `"}`
+	case col.Key:
+		// unique, even when truncated to a short size
+		v = strconv.Itoa(g.row)
+	default:
+		v = g.text(col)
+	}
+	if col.Size > 0 && len(v) > col.Size {
+		v = v[:col.Size]
+	}
+	return v
+}
+
+// text returns a text fitting the name of the column, e.g. an email address.
+func (g *generator) text(col SchemaColumn) string {
+	name := strings.ToLower(unquote(col.Name))
+	switch {
+	case strings.Contains(name, "email"):
+		return g.fake.Email()
+	case strings.Contains(name, "name"):
+		return g.fake.Name()
+	case strings.Contains(name, "address"):
+		return g.fake.Address().Address
+	case strings.Contains(name, "phone"):
+		return g.fake.Phone()
+	case col.Size > 0 && col.Size < 20:
+		return g.fake.LetterN(uint(col.Size))
+	default:
+		return g.fake.Sentence(8)
+	}
+}
+
+func (g *generator) Close() error {
+	return nil
+}
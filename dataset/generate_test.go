@@ -0,0 +1,111 @@
+package dataset
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	// arrange
+	table := SchemaTable{Name: "users", Columns: []SchemaColumn{
+		{Name: "id", Type: "bigint", Key: true},
+		{Name: "group_id", Type: "int"},
+		{Name: "email", Type: "varchar", Size: 10},
+		{Name: "score", Type: "numeric"},
+		{Name: "active", Type: "boolean"},
+		{Name: "created", Type: "date"},
+	}}
+
+	// act
+	src := Generate(table, 5)
+	var rows [][]interface{}
+	for {
+		row, err := src.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+
+	// assert
+	require.Equal(t, []Column{{"id", TypeInt}, {"group_id", TypeInt}, {"email", TypeText}, {"score", TypeFloat}, {"active", TypeBool}, {"created", TypeText}}, src.Columns())
+	require.Len(t, rows, 5)
+	for i, row := range rows {
+		require.Equal(t, int64(i+1), row[0])
+		require.True(t, row[1].(int64) >= 1 && row[1].(int64) <= 5)
+		require.True(t, len(row[2].(string)) <= 10)
+		require.IsType(t, float64(0), row[3])
+		require.IsType(t, true, row[4])
+		require.Len(t, row[5], len("2006-01-02"))
+	}
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	// arrange
+	table := SchemaTable{Name: "users", Columns: []SchemaColumn{
+		{Name: "id", Type: "uuid"},
+		{Name: "name", Type: "text"},
+		{Name: "score", Type: "numeric"},
+		{Name: "created", Type: "timestamp"},
+	}}
+	read := func(src Source) [][]interface{} {
+		var rows [][]interface{}
+		for {
+			row, err := src.Read()
+			if err == io.EOF {
+				return rows
+			}
+			require.NoError(t, err)
+			rows = append(rows, row)
+		}
+	}
+
+	// act
+	first := read(Generate(table, 10))
+	again := read(Generate(table, 10))
+
+	// assert
+	require.Equal(t, first, again)
+}
+
+func TestGenerateDistributions(t *testing.T) {
+	// arrange
+	table := SchemaTable{Name: "orders", Columns: []SchemaColumn{
+		{Name: "note", Type: "text", Nulls: 0.5},
+		{Name: "customer_id", Type: "int", Skew: 2},
+		{Name: "status", Type: "varchar", Size: 10, Skew: 2},
+	}}
+	const n = 2000
+
+	// act
+	src := Generate(table, n)
+	var (
+		nulls     int
+		customers = map[int64]int{}
+		statuses  = map[string]int{}
+	)
+	for {
+		row, err := src.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if row[0] == nil {
+			nulls++
+		}
+		customers[row[1].(int64)]++
+		statuses[row[2].(string)]++
+	}
+
+	// assert
+	require.InDelta(t, n/2, nulls, n/10)
+	for id := range customers {
+		require.True(t, id >= 1 && id <= n, "customer %v", id)
+	}
+	require.True(t, customers[1] > n/2, "the first value is the most common: %v", customers[1])
+	require.True(t, customers[1] > customers[2])
+	require.True(t, len(statuses) < n/10, "%v distinct values", len(statuses))
+}
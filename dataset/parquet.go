@@ -0,0 +1,102 @@
+package dataset
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetSource reads a Parquet file with a flat schema, the column types are taken from it.
+type parquetSource struct {
+	file *os.File
+	r    *parquet.Reader
+	cols []Column
+	buf  []parquet.Row
+}
+
+// openParquet opens the Parquet file. Nested and repeated columns are not supported.
+func openParquet(path string) (*parquetSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	pf, err := parquet.OpenFile(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open %v: %v", path, err)
+	}
+
+	var cols []Column
+	for _, field := range pf.Schema().Fields() {
+		if !field.Leaf() || field.Repeated() {
+			f.Close()
+			return nil, fmt.Errorf("column %v of %v is nested or repeated, only flat schemas are supported", field.Name(), path)
+		}
+		cols = append(cols, Column{Name: field.Name(), Type: parquetType(field.Type().Kind())})
+	}
+	return &parquetSource{file: f, r: parquet.NewReader(pf), cols: cols, buf: make([]parquet.Row, 1)}, nil
+}
+
+// parquetType returns the column type of the physical type.
+func parquetType(kind parquet.Kind) Type {
+	switch kind {
+	case parquet.Boolean:
+		return TypeBool
+	case parquet.Int32, parquet.Int64:
+		return TypeInt
+	case parquet.Float, parquet.Double:
+		return TypeFloat
+	default:
+		return TypeText
+	}
+}
+
+func (s *parquetSource) Columns() []Column {
+	return s.cols
+}
+
+func (s *parquetSource) Read() ([]interface{}, error) {
+	n, err := s.r.ReadRows(s.buf)
+	if n == 0 {
+		if err == nil {
+			err = io.EOF
+		}
+		return nil, err
+	}
+
+	row := make([]interface{}, len(s.cols))
+	for _, v := range s.buf[0] {
+		i := v.Column()
+		if v.IsNull() || i < 0 || i >= len(row) {
+			continue
+		}
+		switch v.Kind() {
+		case parquet.Boolean:
+			row[i] = v.Boolean()
+		case parquet.Int32:
+			row[i] = int64(v.Int32())
+		case parquet.Int64:
+			row[i] = v.Int64()
+		case parquet.Float:
+			row[i] = float64(v.Float())
+		case parquet.Double:
+			row[i] = v.Double()
+		case parquet.ByteArray, parquet.FixedLenByteArray:
+			row[i] = string(v.ByteArray())
+		default:
+			row[i] = v.String()
+		}
+	}
+	return row, nil
+}
+
+func (s *parquetSource) Close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,56 @@
+package dataset
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenParquet(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	type user struct {
+		ID     int64   `parquet:"id"`
+		Name   string  `parquet:"name,optional"`
+		Score  float64 `parquet:"score"`
+		Active bool    `parquet:"active"`
+	}
+	path := filepath.Join(dir, "users.parquet")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	w := parquet.NewWriter(f)
+	require.NoError(t, w.Write(user{ID: 1, Name: "Markus", Score: 1.5, Active: true}))
+	require.NoError(t, w.Write(user{ID: 2, Score: 2}))
+	require.NoError(t, w.Close())
+	require.NoError(t, f.Close())
+
+	// act
+	src, err := Open(path)
+	require.NoError(t, err)
+	defer src.Close()
+
+	var rows [][]interface{}
+	for {
+		row, err := src.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+
+	// assert
+	require.Equal(t, []Column{{"id", TypeInt}, {"name", TypeText}, {"score", TypeFloat}, {"active", TypeBool}}, src.Columns())
+	require.Equal(t, [][]interface{}{
+		{int64(1), "Markus", 1.5, true},
+		{int64(2), nil, 2.0, false},
+	}, rows)
+}
@@ -0,0 +1,138 @@
+package orm
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+	"github.com/jmoiron/sqlx"
+)
+
+// raw executes plain statements without placeholders, like the dbbench benchmarks.
+type raw struct {
+	db    *sql.DB
+	table string
+}
+
+func (r *raw) Insert(a Account) error {
+	_, err := r.db.Exec(fmt.Sprintf("INSERT INTO %v (id, balance) VALUES (%d, %d)", r.table, a.ID, a.Balance))
+	return err
+}
+
+func (r *raw) Select(id int64) (Account, error) {
+	var a Account
+	err := r.db.QueryRow(fmt.Sprintf("SELECT id, balance FROM %v WHERE id = %d", r.table, id)).Scan(&a.ID, &a.Balance)
+	return a, err
+}
+
+func (r *raw) Update(a Account) error {
+	_, err := r.db.Exec(fmt.Sprintf("UPDATE %v SET balance = %d WHERE id = %d", r.table, a.Balance, a.ID))
+	return err
+}
+
+func (r *raw) Delete(id int64) error {
+	_, err := r.db.Exec(fmt.Sprintf("DELETE FROM %v WHERE id = %d", r.table, id))
+	return err
+}
+
+// queries resembles the code generated by sqlc: constant parameterized
+// statements executed directly on database/sql.
+type queries struct {
+	db                                                      *sql.DB
+	insertAccount, getAccount, updateAccount, deleteAccount string
+}
+
+func newQueries(db *sql.DB, dialect, table string) *queries {
+	bind := sqlx.BindType(dialect)
+	return &queries{
+		db:            db,
+		insertAccount: sqlx.Rebind(bind, fmt.Sprintf("INSERT INTO %v (id, balance) VALUES (?, ?)", table)),
+		getAccount:    sqlx.Rebind(bind, fmt.Sprintf("SELECT id, balance FROM %v WHERE id = ?", table)),
+		updateAccount: sqlx.Rebind(bind, fmt.Sprintf("UPDATE %v SET balance = ? WHERE id = ?", table)),
+		deleteAccount: sqlx.Rebind(bind, fmt.Sprintf("DELETE FROM %v WHERE id = ?", table)),
+	}
+}
+
+func (q *queries) Insert(a Account) error {
+	_, err := q.db.Exec(q.insertAccount, a.ID, a.Balance)
+	return err
+}
+
+func (q *queries) Select(id int64) (Account, error) {
+	var a Account
+	err := q.db.QueryRow(q.getAccount, id).Scan(&a.ID, &a.Balance)
+	return a, err
+}
+
+func (q *queries) Update(a Account) error {
+	_, err := q.db.Exec(q.updateAccount, a.Balance, a.ID)
+	return err
+}
+
+func (q *queries) Delete(id int64) error {
+	_, err := q.db.Exec(q.deleteAccount, id)
+	return err
+}
+
+// sqlxLayer uses named statements and struct scanning of sqlx.
+type sqlxLayer struct {
+	db    *sqlx.DB
+	table string
+}
+
+func newSqlx(db *sql.DB, dialect, table string) *sqlxLayer {
+	return &sqlxLayer{db: sqlx.NewDb(db, dialect), table: table}
+}
+
+func (s *sqlxLayer) Insert(a Account) error {
+	_, err := s.db.NamedExec(fmt.Sprintf("INSERT INTO %v (id, balance) VALUES (:id, :balance)", s.table), a)
+	return err
+}
+
+func (s *sqlxLayer) Select(id int64) (Account, error) {
+	var a Account
+	err := s.db.Get(&a, s.db.Rebind(fmt.Sprintf("SELECT id, balance FROM %v WHERE id = ?", s.table)), id)
+	return a, err
+}
+
+func (s *sqlxLayer) Update(a Account) error {
+	_, err := s.db.NamedExec(fmt.Sprintf("UPDATE %v SET balance = :balance WHERE id = :id", s.table), a)
+	return err
+}
+
+func (s *sqlxLayer) Delete(id int64) error {
+	_, err := s.db.Exec(s.db.Rebind(fmt.Sprintf("DELETE FROM %v WHERE id = ?", s.table)), id)
+	return err
+}
+
+// gormLayer uses the model based API of GORM.
+type gormLayer struct {
+	db    *gorm.DB
+	table string
+}
+
+func newGorm(db *sql.DB, dialect, table string) (*gormLayer, error) {
+	g, err := gorm.Open(dialect, db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open gorm: %v", err)
+	}
+	return &gormLayer{db: g, table: table}, nil
+}
+
+func (g *gormLayer) Insert(a Account) error {
+	return g.db.Table(g.table).Create(&a).Error
+}
+
+func (g *gormLayer) Select(id int64) (Account, error) {
+	var a Account
+	err := g.db.Table(g.table).Where("id = ?", id).First(&a).Error
+	return a, err
+}
+
+func (g *gormLayer) Update(a Account) error {
+	return g.db.Table(g.table).Where("id = ?", a.ID).Update("balance", a.Balance).Error
+}
+
+func (g *gormLayer) Delete(id int64) error {
+	return g.db.Table(g.table).Where("id = ?", id).Delete(Account{}).Error
+}
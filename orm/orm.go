@@ -0,0 +1,148 @@
+// Package orm compares the overhead of database access layers (ORMs, query builders
+// and generated code) with raw statements executed by the driver.
+package orm
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Account is the row of the benchmark table, mapped by all layers.
+type Account struct {
+	ID      int64 `db:"id" gorm:"column:id;primary_key"`
+	Balance int64 `db:"balance" gorm:"column:balance"`
+}
+
+// Layer executes the operations of the harness through a database access layer.
+type Layer interface {
+	Insert(a Account) error
+	Select(id int64) (Account, error)
+	Update(a Account) error
+	Delete(id int64) error
+}
+
+// NamedLayer is a layer and its name in the results.
+type NamedLayer struct {
+	Name  string
+	Layer Layer
+}
+
+// Layers returns all layers, the first one executes raw statements. The dialect is the name of the
+// driver ("postgres", "mysql" or "sqlite3"), table the benchmark table, which is created by CreateTable.
+func Layers(db *sql.DB, dialect, table string) ([]NamedLayer, error) {
+	gormLayer, err := newGorm(db, dialect, table)
+	if err != nil {
+		return nil, err
+	}
+	return []NamedLayer{
+		{Name: "raw", Layer: &raw{db: db, table: table}},
+		{Name: "sqlc", Layer: newQueries(db, dialect, table)},
+		{Name: "sqlx", Layer: newSqlx(db, dialect, table)},
+		{Name: "gorm", Layer: gormLayer},
+	}, nil
+}
+
+// CreateTable creates the benchmark table.
+func CreateTable(db *sql.DB, table string) error {
+	_, err := db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %v (id BIGINT PRIMARY KEY, balance BIGINT)", table))
+	return err
+}
+
+// DropTable removes the benchmark table.
+func DropTable(db *sql.DB, table string) error {
+	_, err := db.Exec(fmt.Sprintf("DROP TABLE %v", table))
+	return err
+}
+
+// Options configures the harness.
+type Options struct {
+	Iter    int // number of operations per operation type and layer
+	Threads int // number of concurrent routines
+}
+
+// Result contains the duration per operation of a layer.
+type Result struct {
+	Operation string
+	Layer     string
+	NsPerOp   int64
+	Overhead  float64 // relative to the first layer, e.g. 0.1 when 10% slower
+}
+
+// operations executes the operations in their order, each operation gets the row
+// of its iteration. The rows are inserted first and deleted last.
+var operations = []struct {
+	name string
+	exec func(l Layer, i int64) error
+}{
+	{name: "insert", exec: func(l Layer, i int64) error { return l.Insert(Account{ID: i, Balance: rand.Int63()}) }},
+	{name: "select", exec: func(l Layer, i int64) error { _, err := l.Select(i); return err }},
+	{name: "update", exec: func(l Layer, i int64) error { return l.Update(Account{ID: i, Balance: rand.Int63()}) }},
+	{name: "delete", exec: func(l Layer, i int64) error { return l.Delete(i) }},
+}
+
+// Run executes all operations through all layers and returns the results grouped by operation.
+// The overhead of the results is relative to the first layer, usually the raw statements.
+func Run(layers []NamedLayer, opts Options) ([]Result, error) {
+	results := make([]Result, len(layers)*len(operations))
+
+	// base contains the ns/op of the first layer per operation
+	base := map[string]int64{}
+
+	for li, l := range layers {
+		for oi, op := range operations {
+			took, err := loop(l.Layer, op.exec, opts)
+			if err != nil {
+				return nil, fmt.Errorf("%v %v failed: %v", l.Name, op.name, err)
+			}
+
+			nsPerOp := took.Nanoseconds() / int64(opts.Iter)
+			if _, ok := base[op.name]; !ok {
+				base[op.name] = nsPerOp
+			}
+
+			r := Result{Operation: op.name, Layer: l.Name, NsPerOp: nsPerOp}
+			if b := base[op.name]; b > 0 {
+				r.Overhead = float64(nsPerOp-b) / float64(b)
+			}
+			results[oi*len(layers)+li] = r
+		}
+	}
+	return results, nil
+}
+
+// loop executes the operation for the iterations 1 to opts.Iter concurrently
+// and returns the duration and the first error.
+func loop(l Layer, exec func(Layer, int64) error, opts Options) (time.Duration, error) {
+	var (
+		wg       = &sync.WaitGroup{}
+		errOnce  = &sync.Once{}
+		firstErr error
+	)
+
+	start := time.Now()
+	for routine := 0; routine < opts.Threads; routine++ {
+		from := ((opts.Iter / opts.Threads) * routine) + 1
+		to := (opts.Iter / opts.Threads) * (routine + 1)
+
+		// Add the remainder of iterations to the last routine.
+		if routine == opts.Threads-1 {
+			to = opts.Iter
+		}
+
+		wg.Add(1)
+		go func(from, to int) {
+			defer wg.Done()
+			for i := from; i <= to; i++ {
+				if err := exec(l, int64(i)); err != nil {
+					errOnce.Do(func() { firstErr = err })
+					return
+				}
+			}
+		}(from, to)
+	}
+	wg.Wait()
+	return time.Since(start), firstErr
+}
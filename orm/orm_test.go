@@ -0,0 +1,78 @@
+package orm
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+func openSQLite(t *testing.T) (*sql.DB, func()) {
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "orm.sqlite"))
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	require.NoError(t, CreateTable(db, "dbbench_orm"))
+
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestLayers(t *testing.T) {
+	db, cleanup := openSQLite(t)
+	defer cleanup()
+
+	layers, err := Layers(db, "sqlite3", "dbbench_orm")
+	require.NoError(t, err)
+
+	for _, l := range layers {
+		t.Run(l.Name, func(t *testing.T) {
+			// act
+			require.NoError(t, l.Layer.Insert(Account{ID: 1, Balance: 10}))
+			require.NoError(t, l.Layer.Update(Account{ID: 1, Balance: 20}))
+			got, err := l.Layer.Select(1)
+			require.NoError(t, err)
+			require.NoError(t, l.Layer.Delete(1))
+			_, errDeleted := l.Layer.Select(1)
+
+			// assert
+			require.Equal(t, Account{ID: 1, Balance: 20}, got)
+			require.Error(t, errDeleted)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	// arrange
+	db, cleanup := openSQLite(t)
+	defer cleanup()
+
+	layers, err := Layers(db, "sqlite3", "dbbench_orm")
+	require.NoError(t, err)
+
+	// act
+	results, err := Run(layers, Options{Iter: 20, Threads: 3})
+
+	// assert
+	require.NoError(t, err)
+	require.Len(t, results, len(layers)*len(operations))
+	for i, r := range results {
+		require.Equal(t, operations[i/len(layers)].name, r.Operation)
+		require.Equal(t, layers[i%len(layers)].Name, r.Layer)
+		if r.Layer == "raw" {
+			require.Equal(t, 0.0, r.Overhead)
+		}
+	}
+
+	var rows int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM dbbench_orm").Scan(&rows))
+	require.Equal(t, 0, rows)
+}
@@ -0,0 +1,80 @@
+package proxy
+
+import "encoding/binary"
+
+// capability flags of the handshake response
+const (
+	mysqlClientSSL             = 0x00000800
+	mysqlClientQueryAttributes = 0x08000000
+)
+
+// commands of the client
+const (
+	mysqlComQuery       = 0x03
+	mysqlComStmtExecute = 0x17
+)
+
+// mysqlDecoder decodes the queries of the MySQL client/server protocol. Prepared statements are
+// executed with binary parameters, they're skipped.
+type mysqlDecoder struct {
+	buf        []byte
+	handshake  bool // handshake response received
+	encrypted  bool // TLS, nothing can be decoded anymore
+	attributes bool // the queries are prefixed with their attributes
+}
+
+func (d *mysqlDecoder) feed(p []byte) ([]string, int) {
+	if d.encrypted {
+		return nil, 0
+	}
+	d.buf = append(d.buf, p...)
+
+	var (
+		stmts   []string
+		skipped int
+	)
+	for len(d.buf) >= 4 {
+		n := int(d.buf[0]) | int(d.buf[1])<<8 | int(d.buf[2])<<16
+		seq := d.buf[3]
+		if len(d.buf) < 4+n {
+			break
+		}
+		payload := d.buf[4 : 4+n]
+		d.buf = d.buf[4+n:]
+
+		if !d.handshake {
+			d.handshake = true
+			if len(payload) >= 4 {
+				flags := binary.LittleEndian.Uint32(payload)
+				d.attributes = flags&mysqlClientQueryAttributes != 0
+				// the short SSL request is followed by the TLS handshake
+				if flags&mysqlClientSSL != 0 && n == 32 {
+					d.encrypted, d.buf = true, nil
+					break
+				}
+			}
+			continue
+		}
+
+		// commands start a new sequence, others continue the authentication
+		if seq != 0 || n == 0 {
+			continue
+		}
+		switch payload[0] {
+		case mysqlComQuery:
+			query := payload[1:]
+			if d.attributes {
+				// without attributes: no parameters in a single set
+				if len(query) < 2 || query[0] != 0 || query[1] != 1 {
+					skipped++
+					continue
+				}
+				query = query[2:]
+			}
+			stmts = append(stmts, string(query))
+		case mysqlComStmtExecute:
+			skipped++
+		}
+	}
+	return stmts, skipped
+}
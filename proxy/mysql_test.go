@@ -0,0 +1,78 @@
+package proxy
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mysqlPacket returns a packet of the client/server protocol.
+func mysqlPacket(seq byte, payload ...byte) []byte {
+	return append([]byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), seq}, payload...)
+}
+
+// mysqlHandshake returns a handshake response with the capability flags.
+func mysqlHandshake(flags uint32, size int) []byte {
+	payload := make([]byte, size)
+	binary.LittleEndian.PutUint32(payload, flags)
+	return mysqlPacket(1, payload...)
+}
+
+func TestMySQLDecoder(t *testing.T) {
+	query := mysqlPacket(0, append([]byte{mysqlComQuery}, "SELECT 1"...)...)
+
+	testCases := []struct {
+		description string
+		input       [][]byte
+		wantStmts   []string
+		wantSkipped int
+	}{
+		{
+			description: "query",
+			input:       [][]byte{mysqlHandshake(0, 64), mysqlPacket(3, 1, 2, 3), query},
+			wantStmts:   []string{"SELECT 1"},
+		},
+		{
+			description: "split packets",
+			input:       [][]byte{mysqlHandshake(0, 64), query[:5], query[5:]},
+			wantStmts:   []string{"SELECT 1"},
+		},
+		{
+			description: "query attributes",
+			input:       [][]byte{mysqlHandshake(mysqlClientQueryAttributes, 64), mysqlPacket(0, append([]byte{mysqlComQuery, 0, 1}, "SELECT 1"...)...)},
+			wantStmts:   []string{"SELECT 1"},
+		},
+		{
+			description: "prepared statement",
+			input:       [][]byte{mysqlHandshake(0, 64), mysqlPacket(0, mysqlComStmtExecute, 1, 0, 0, 0)},
+			wantSkipped: 1,
+		},
+		{
+			description: "encrypted",
+			input:       [][]byte{mysqlHandshake(mysqlClientSSL, 32), query},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			// arrange
+			d := &mysqlDecoder{}
+			var (
+				stmts   []string
+				skipped int
+			)
+
+			// act
+			for _, p := range tt.input {
+				s, n := d.feed(p)
+				stmts = append(stmts, s...)
+				skipped += n
+			}
+
+			// assert
+			require.Equal(t, tt.wantStmts, stmts)
+			require.Equal(t, tt.wantSkipped, skipped)
+		})
+	}
+}
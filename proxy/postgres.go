@@ -0,0 +1,234 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// codes of the messages sent before the startup message
+const (
+	pgSSLRequest    = 80877103
+	pgGSSENCRequest = 80877104
+	pgCancelRequest = 80877102
+)
+
+// postgresDecoder decodes the simple queries and the executed prepared statements of the
+// PostgreSQL frontend protocol. The parameters of prepared statements are substituted as
+// literals, statements with parameters in binary format are skipped.
+type postgresDecoder struct {
+	buf       []byte
+	started   bool // startup message received
+	encrypted bool // TLS or GSSAPI, nothing can be decoded anymore
+	upgrade   bool // encryption was requested, the server might have accepted it
+
+	prepared map[string]string        // queries by the name of the statement
+	portals  map[string]postgresQuery // bound statements by the name of the portal
+}
+
+// postgresQuery is a statement with its parameters substituted.
+type postgresQuery struct {
+	stmt   string
+	binary bool // a parameter is in binary format, the statement can't be recorded
+}
+
+func (d *postgresDecoder) feed(p []byte) ([]string, int) {
+	if d.encrypted {
+		return nil, 0
+	}
+	d.buf = append(d.buf, p...)
+
+	var (
+		stmts   []string
+		skipped int
+	)
+	for {
+		if d.upgrade && len(d.buf) > 0 {
+			d.upgrade = false
+			// not a startup message, but the handshake of the accepted encryption
+			if d.buf[0] != 0 {
+				d.encrypted, d.buf = true, nil
+				return stmts, skipped
+			}
+		}
+
+		if !d.started {
+			if len(d.buf) < 8 {
+				return stmts, skipped
+			}
+			n := int(binary.BigEndian.Uint32(d.buf))
+			if n < 8 || len(d.buf) < n {
+				return stmts, skipped
+			}
+			switch binary.BigEndian.Uint32(d.buf[4:8]) {
+			case pgSSLRequest, pgGSSENCRequest:
+				d.upgrade = true
+			case pgCancelRequest:
+			default:
+				d.started = true
+			}
+			d.buf = d.buf[n:]
+			continue
+		}
+
+		if len(d.buf) < 5 {
+			return stmts, skipped
+		}
+		n := int(binary.BigEndian.Uint32(d.buf[1:5])) + 1
+		if n < 5 || len(d.buf) < n {
+			return stmts, skipped
+		}
+		typ, body := d.buf[0], d.buf[5:n]
+		switch typ {
+		case 'Q':
+			if stmt, _, ok := cstring(body); ok {
+				stmts = append(stmts, stmt)
+			}
+		case 'P':
+			d.parse(body)
+		case 'B':
+			d.bind(body)
+		case 'E':
+			if portal, _, ok := cstring(body); ok {
+				if q, ok := d.portals[portal]; ok {
+					if q.binary {
+						skipped++
+					} else {
+						stmts = append(stmts, q.stmt)
+					}
+				}
+			}
+		case 'C':
+			if len(body) > 1 {
+				if name, _, ok := cstring(body[1:]); ok {
+					if body[0] == 'S' {
+						delete(d.prepared, name)
+					} else {
+						delete(d.portals, name)
+					}
+				}
+			}
+		}
+		d.buf = d.buf[n:]
+	}
+}
+
+// parse decodes the Parse message, which prepares a statement.
+func (d *postgresDecoder) parse(body []byte) {
+	name, rest, ok := cstring(body)
+	if !ok {
+		return
+	}
+	query, _, ok := cstring(rest)
+	if !ok {
+		return
+	}
+	if d.prepared == nil {
+		d.prepared = map[string]string{}
+	}
+	d.prepared[name] = query
+}
+
+// bind decodes the Bind message, which binds the parameters of a prepared statement to a portal.
+func (d *postgresDecoder) bind(body []byte) {
+	portal, rest, ok := cstring(body)
+	if !ok {
+		return
+	}
+	name, rest, ok := cstring(rest)
+	if !ok {
+		return
+	}
+	query, ok := d.prepared[name]
+	if !ok {
+		return
+	}
+
+	formats, rest, ok := int16s(rest)
+	if !ok || len(rest) < 2 {
+		return
+	}
+	n := int(binary.BigEndian.Uint16(rest))
+	rest = rest[2:]
+
+	q := postgresQuery{}
+	values := make([]string, n)
+	for i := 0; i < n; i++ {
+		if len(rest) < 4 {
+			return
+		}
+		size := int32(binary.BigEndian.Uint32(rest))
+		rest = rest[4:]
+		if size < 0 {
+			values[i] = "NULL"
+			continue
+		}
+		if len(rest) < int(size) {
+			return
+		}
+		// a single format applies to all parameters, none means text
+		format := int16(0)
+		switch {
+		case len(formats) == 1:
+			format = formats[0]
+		case i < len(formats):
+			format = formats[i]
+		}
+		if format != 0 {
+			q.binary = true
+		}
+		values[i] = sqlLiteral(string(rest[:size]))
+		rest = rest[size:]
+	}
+
+	// substitute $10 before $1
+	for i := n; i >= 1; i-- {
+		query = strings.Replace(query, "$"+strconv.Itoa(i), values[i-1], -1)
+	}
+	q.stmt = query
+
+	if d.portals == nil {
+		d.portals = map[string]postgresQuery{}
+	}
+	d.portals[portal] = q
+}
+
+// number matches the parameters, which are substituted without quotes.
+var number = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// sqlLiteral returns the value of a parameter as SQL literal, quoted unless it's a number.
+func sqlLiteral(value string) string {
+	if number.MatchString(value) {
+		return value
+	}
+	return "'" + strings.Replace(value, "'", "''", -1) + "'"
+}
+
+// cstring returns the null-terminated string at the start of b and the remaining bytes.
+func cstring(b []byte) (string, []byte, bool) {
+	i := bytes.IndexByte(b, 0)
+	if i < 0 {
+		return "", nil, false
+	}
+	return string(b[:i]), b[i+1:], true
+}
+
+// int16s returns the array of int16 values, prefixed with their number, at the start of b
+// and the remaining bytes.
+func int16s(b []byte) ([]int16, []byte, bool) {
+	if len(b) < 2 {
+		return nil, nil, false
+	}
+	n := int(binary.BigEndian.Uint16(b))
+	b = b[2:]
+	if len(b) < 2*n {
+		return nil, nil, false
+	}
+	values := make([]int16, n)
+	for i := range values {
+		values[i] = int16(binary.BigEndian.Uint16(b[2*i:]))
+	}
+	return values, b[2*n:], true
+}
@@ -0,0 +1,119 @@
+package proxy
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// pgMessage returns a message of the frontend protocol.
+func pgMessage(typ byte, body ...[]byte) []byte {
+	var b []byte
+	for _, part := range body {
+		b = append(b, part...)
+	}
+	msg := []byte{typ, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(msg[1:], uint32(len(b)+4))
+	return append(msg, b...)
+}
+
+// pgStartup returns a message sent before the startup, e.g. the startup message itself.
+func pgStartup(code uint32) []byte {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint32(msg, 8)
+	binary.BigEndian.PutUint32(msg[4:], code)
+	return msg
+}
+
+func pgString(s string) []byte {
+	return append([]byte(s), 0)
+}
+
+func pgInt16(values ...int16) []byte {
+	b := make([]byte, 2*len(values))
+	for i, v := range values {
+		binary.BigEndian.PutUint16(b[2*i:], uint16(v))
+	}
+	return b
+}
+
+func pgParam(value string) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(len(value)))
+	return append(b, value...)
+}
+
+func TestPostgresDecoder(t *testing.T) {
+	startup := pgStartup(196608)
+	parse := pgMessage('P', pgString("s1"), pgString("SELECT * FROM users WHERE id = $1 AND name = $2 AND x = $10"), pgInt16(0))
+	execute := pgMessage('E', pgString(""), []byte{0, 0, 0, 0})
+
+	bind := func(formats []int16, values ...string) []byte {
+		var params []byte
+		for _, v := range values {
+			params = append(params, pgParam(v)...)
+		}
+		return pgMessage('B', pgString(""), pgString("s1"), pgInt16(int16(len(formats))), pgInt16(formats...), pgInt16(int16(len(values))), params)
+	}
+	values := []string{"42", "O'Reilly", "3", "4", "5", "6", "7", "8", "9", "10"}
+
+	testCases := []struct {
+		description string
+		input       [][]byte
+		wantStmts   []string
+		wantSkipped int
+	}{
+		{
+			description: "simple query",
+			input:       [][]byte{startup, pgMessage('Q', pgString("SELECT 1"))},
+			wantStmts:   []string{"SELECT 1"},
+		},
+		{
+			description: "split messages",
+			input:       [][]byte{startup[:3], startup[3:], pgMessage('Q', pgString("SELECT 1"))[:6], pgMessage('Q', pgString("SELECT 1"))[6:]},
+			wantStmts:   []string{"SELECT 1"},
+		},
+		{
+			description: "prepared statement",
+			input:       [][]byte{startup, parse, bind(nil, values...), execute, pgMessage('S')},
+			wantStmts:   []string{"SELECT * FROM users WHERE id = 42 AND name = 'O''Reilly' AND x = 10"},
+		},
+		{
+			description: "binary parameters",
+			input:       [][]byte{startup, parse, bind([]int16{1}, values...), execute},
+			wantSkipped: 1,
+		},
+		{
+			description: "declined encryption",
+			input:       [][]byte{pgStartup(pgSSLRequest), startup, pgMessage('Q', pgString("SELECT 1"))},
+			wantStmts:   []string{"SELECT 1"},
+		},
+		{
+			description: "encrypted",
+			input:       [][]byte{pgStartup(pgSSLRequest), {0x16, 0x03, 0x01}, pgMessage('Q', pgString("SELECT 1"))},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			// arrange
+			d := &postgresDecoder{}
+			var (
+				stmts   []string
+				skipped int
+			)
+
+			// act
+			for _, p := range tt.input {
+				s, n := d.feed(p)
+				stmts = append(stmts, s...)
+				skipped += n
+			}
+
+			// assert
+			require.Equal(t, tt.wantStmts, stmts)
+			require.Equal(t, tt.wantSkipped, skipped)
+		})
+	}
+}
@@ -0,0 +1,207 @@
+// Package proxy records the statements, which applications send to a database, by forwarding
+// their connections to the database and decoding the wire protocol on the way.
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sj14/dbbench/querylog"
+)
+
+// Protocols of the recorded connections.
+const (
+	ProtocolPostgres = "postgres"
+	ProtocolMySQL    = "mysql"
+)
+
+// decoder decodes the statements of the messages sent by a client. Statements which can't
+// be decoded, e.g. with binary parameters, are counted as skipped.
+type decoder interface {
+	feed(p []byte) (stmts []string, skipped int)
+}
+
+// newDecoder returns a decoder of the protocol.
+func newDecoder(protocol string) (decoder, error) {
+	switch protocol {
+	case ProtocolPostgres:
+		return &postgresDecoder{}, nil
+	case ProtocolMySQL:
+		return &mysqlDecoder{}, nil
+	}
+	return nil, fmt.Errorf("unknown protocol %q, use %v or %v", protocol, ProtocolPostgres, ProtocolMySQL)
+}
+
+// Recorder forwards the connections of the clients to the upstream database and records their
+// statements. The latency of a statement is the time until the first byte of the response.
+// Encrypted connections are forwarded without recording their statements.
+type Recorder struct {
+	upstream string
+	protocol string
+
+	mu     sync.Mutex // serializes the records
+	record func(querylog.Record)
+
+	conns    int64
+	recorded int64
+	skipped  int64
+
+	clientsMu sync.Mutex
+	clients   map[net.Conn]bool // open connections of the clients, see Close
+	handlers  sync.WaitGroup
+}
+
+// NewRecorder returns a recorder, which forwards the connections to the upstream address
+// and passes the statements to record.
+func NewRecorder(upstream, protocol string, record func(querylog.Record)) (*Recorder, error) {
+	if _, err := newDecoder(protocol); err != nil {
+		return nil, err
+	}
+	return &Recorder{upstream: upstream, protocol: protocol, record: record, clients: map[net.Conn]bool{}}, nil
+}
+
+// Serve accepts the connections of the clients until the listener is closed.
+func (r *Recorder) Serve(ln net.Listener) error {
+	for {
+		client, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		r.clientsMu.Lock()
+		r.clients[client] = true
+		r.clientsMu.Unlock()
+		r.handlers.Add(1)
+		go func() {
+			defer r.handlers.Done()
+			r.handle(client, int(atomic.AddInt64(&r.conns, 1)))
+			r.clientsMu.Lock()
+			delete(r.clients, client)
+			r.clientsMu.Unlock()
+		}()
+	}
+}
+
+// Close closes the open connections of the clients, after the listener was closed, and waits
+// until their statements are recorded.
+func (r *Recorder) Close() {
+	r.clientsMu.Lock()
+	for client := range r.clients {
+		client.Close()
+	}
+	r.clientsMu.Unlock()
+	r.handlers.Wait()
+}
+
+// Stats returns the number of recorded and skipped statements.
+func (r *Recorder) Stats() (recorded, skipped int64) {
+	return atomic.LoadInt64(&r.recorded), atomic.LoadInt64(&r.skipped)
+}
+
+// handle forwards the connection of a client to the upstream database, until one of them closes it.
+func (r *Recorder) handle(client net.Conn, conn int) {
+	server, err := net.Dial("tcp", r.upstream)
+	if err != nil {
+		log.Printf("failed to connect to %v: %v\n", r.upstream, err)
+		client.Close()
+		return
+	}
+	dec, _ := newDecoder(r.protocol)
+
+	var (
+		mu      sync.Mutex
+		waiting []querylog.Record // sent statements without response
+		once    sync.Once
+		wg      sync.WaitGroup
+	)
+	closeBoth := func() {
+		once.Do(func() {
+			client.Close()
+			server.Close()
+		})
+	}
+	// records the waiting statements, which got the first byte of their response
+	respond := func(now time.Time) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, rec := range waiting {
+			if !now.IsZero() {
+				rec.Latency = now.Sub(rec.Time)
+			}
+			r.write(rec)
+		}
+		waiting = waiting[:0]
+	}
+
+	wg.Add(2)
+
+	// client -> server, the statements wait before being sent, so they don't miss their response
+	go func() {
+		defer wg.Done()
+		defer closeBoth()
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := client.Read(buf)
+			if n > 0 {
+				now := time.Now()
+				stmts, skipped := dec.feed(buf[:n])
+				atomic.AddInt64(&r.skipped, int64(skipped))
+				mu.Lock()
+				for _, stmt := range stmts {
+					waiting = append(waiting, querylog.Record{Time: now, Conn: conn, Stmt: stmt})
+				}
+				mu.Unlock()
+				if _, err := server.Write(buf[:n]); err != nil {
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	// server -> client
+	go func() {
+		defer wg.Done()
+		defer closeBoth()
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := server.Read(buf)
+			if n > 0 {
+				respond(time.Now())
+				if _, err := client.Write(buf[:n]); err != nil {
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+	// statements without response, e.g. the last one before the connection was closed
+	respond(time.Time{})
+}
+
+// write records the statement.
+func (r *Recorder) write(rec querylog.Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.record(rec)
+	atomic.AddInt64(&r.recorded, 1)
+}
+
+// WriteTo returns a function recording the statements to w, in the format of querylog.FormatRecord.
+func WriteTo(w io.Writer) func(querylog.Record) {
+	return func(rec querylog.Record) {
+		if err := querylog.WriteRecord(w, rec); err != nil {
+			log.Printf("failed to write statement: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package proxy
+
+import (
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/sj14/dbbench/querylog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRecorderUnknownProtocol(t *testing.T) {
+	_, err := NewRecorder("localhost:1521", "oracle", func(querylog.Record) {})
+	require.Error(t, err)
+}
+
+func TestRecorder(t *testing.T) {
+	// arrange
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer upstream.Close()
+	go func() {
+		// answers each message with a single byte
+		conn, err := upstream.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 1024)
+		for {
+			if _, err := conn.Read(buf); err != nil {
+				return
+			}
+			conn.Write([]byte{'Z'})
+		}
+	}()
+
+	var (
+		mu      sync.Mutex
+		records []querylog.Record
+	)
+	r, err := NewRecorder(upstream.Addr().String(), ProtocolPostgres, func(rec querylog.Record) {
+		mu.Lock()
+		defer mu.Unlock()
+		records = append(records, rec)
+	})
+	require.NoError(t, err)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go r.Serve(ln)
+
+	// act
+	client, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	response := make([]byte, 1)
+	for _, msg := range [][]byte{pgStartup(196608), pgMessage('Q', pgString("SELECT 1")), pgMessage('Q', pgString("SELECT 2"))} {
+		_, err := client.Write(msg)
+		require.NoError(t, err)
+		_, err = io.ReadFull(client, response)
+		require.NoError(t, err)
+		require.Equal(t, []byte{'Z'}, response)
+	}
+	client.Close()
+
+	// assert, the statements are recorded before their response is forwarded
+	recorded, skipped := r.Stats()
+	require.Equal(t, int64(2), recorded)
+	require.Zero(t, skipped)
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, records, 2)
+	require.Equal(t, "SELECT 1", records[0].Stmt)
+	require.Equal(t, "SELECT 2", records[1].Stmt)
+	require.Equal(t, 1, records[0].Conn)
+	require.True(t, records[0].Latency > 0)
+}
@@ -0,0 +1,169 @@
+package querylog
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Class is a class of statements, which only differ in their literals.
+type Class struct {
+	Fingerprint string // normalized statement, the literals are replaced with ?
+	Example     string // first statement of the class
+	Count       int    // number of statements
+
+	// max. value of each integer literal of the example, when the statements have the same number of them
+	max []int64
+}
+
+// token is a part of a statement.
+type token struct {
+	text    string
+	literal bool // string or number
+	integer bool
+}
+
+// tokenize splits the statement into literals and the text between them.
+func tokenize(stmt string) []token {
+	var (
+		tokens []token
+		start  int
+	)
+	text := func(end int) {
+		if end > start {
+			tokens = append(tokens, token{text: stmt[start:end]})
+		}
+	}
+
+	for i := 0; i < len(stmt); i++ {
+		c := stmt[i]
+		switch {
+		case c == '\'':
+			text(i)
+			end := i + 1
+			for end < len(stmt) {
+				if stmt[end] == '\'' {
+					// escaped quote
+					if end+1 < len(stmt) && stmt[end+1] == '\'' {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			if end < len(stmt) {
+				end++
+			}
+			tokens = append(tokens, token{text: stmt[i:end], literal: true})
+			i, start = end-1, end
+		case c >= '0' && c <= '9' && (i == 0 || !isIdent(stmt[i-1])):
+			text(i)
+			end, integer := i, true
+			for end < len(stmt) && (stmt[end] >= '0' && stmt[end] <= '9' || stmt[end] == '.') {
+				if stmt[end] == '.' {
+					integer = false
+				}
+				end++
+			}
+			// part of an identifier, e.g. 1st
+			if end < len(stmt) && isIdent(stmt[end]) {
+				continue
+			}
+			tokens = append(tokens, token{text: stmt[i:end], literal: true, integer: integer})
+			i, start = end-1, end
+		}
+	}
+	text(len(stmt))
+	return tokens
+}
+
+func isIdent(c byte) bool {
+	return c == '_' || c == '$' || c == '.' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+var (
+	whitespace = regexp.MustCompile(`\s+`)
+	// lists of literals, e.g. IN (1, 2, 3) or VALUES (1, 'a'), (2, 'b')
+	list   = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	tuples = regexp.MustCompile(`\(\?\)(?:\s*,\s*\(\?\))+`)
+)
+
+// fingerprint returns the normalized statement of the tokens.
+func fingerprint(tokens []token) string {
+	sb := &strings.Builder{}
+	for _, t := range tokens {
+		if t.literal {
+			sb.WriteString("?")
+		} else {
+			sb.WriteString(strings.ToLower(t.text))
+		}
+	}
+	fp := whitespace.ReplaceAllString(strings.TrimSpace(sb.String()), " ")
+	fp = list.ReplaceAllString(fp, "(?)")
+	return tuples.ReplaceAllString(fp, "(?)")
+}
+
+// Classify groups the statements into classes, ordered by their number of statements.
+func Classify(stmts []string) []*Class {
+	var (
+		classes []*Class
+		index   = map[string]*Class{}
+	)
+	for _, stmt := range stmts {
+		tokens := tokenize(stmt)
+		fp := fingerprint(tokens)
+
+		c, ok := index[fp]
+		if !ok {
+			c = &Class{Fingerprint: fp, Example: whitespace.ReplaceAllString(stmt, " ")}
+			index[fp] = c
+			classes = append(classes, c)
+		}
+		c.Count++
+		c.observe(tokens)
+	}
+
+	sort.SliceStable(classes, func(i, j int) bool { return classes[i].Count > classes[j].Count })
+	return classes
+}
+
+// observe updates the max. values of the integer literals.
+func (c *Class) observe(tokens []token) {
+	var ints []int64
+	for _, t := range tokens {
+		if t.integer {
+			v, _ := strconv.ParseInt(t.text, 10, 64)
+			ints = append(ints, v)
+		}
+	}
+	if c.max == nil {
+		c.max = ints
+		return
+	}
+	if len(ints) != len(c.max) {
+		return
+	}
+	for i, v := range ints {
+		if v > c.max[i] {
+			c.max[i] = v
+		}
+	}
+}
+
+// Template returns the example as statement template, the integer literals are replaced
+// with random values up to the max. observed value.
+func (c *Class) Template() string {
+	sb := &strings.Builder{}
+	n := 0
+	for _, t := range tokenize(c.Example) {
+		if !t.integer {
+			sb.WriteString(t.text)
+			continue
+		}
+		sb.WriteString("{{call .RandInt63n " + strconv.FormatInt(c.max[n]+1, 10) + "}}")
+		n++
+	}
+	return sb.String()
+}
@@ -0,0 +1,34 @@
+package querylog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	// arrange
+	stmts := []string{
+		"SELECT * FROM users WHERE id = 7",
+		"SELECT * FROM users  WHERE id = 42",
+		"select * from users where id = 3",
+		"SELECT * FROM users WHERE id IN (1, 2, 3)",
+		"SELECT * FROM users WHERE id IN (4)",
+		"INSERT INTO logs (msg, level) VALUES ('it''s', 1.5), ('b', 2)",
+		"SELECT * FROM t1 WHERE name = 'x'",
+	}
+
+	// act
+	classes := Classify(stmts)
+
+	// assert
+	require.Len(t, classes, 4)
+	require.Equal(t, "select * from users where id = ?", classes[0].Fingerprint)
+	require.Equal(t, 3, classes[0].Count)
+	require.Equal(t, "SELECT * FROM users WHERE id = {{call .RandInt63n 43}}", classes[0].Template())
+	require.Equal(t, "select * from users where id in (?)", classes[1].Fingerprint)
+	require.Equal(t, 2, classes[1].Count)
+	require.Equal(t, "insert into logs (msg, level) values (?)", classes[2].Fingerprint)
+	require.Equal(t, "INSERT INTO logs (msg, level) VALUES ('it''s', 1.5), ('b', {{call .RandInt63n 3}})", classes[2].Template())
+	require.Equal(t, "select * from t1 where name = ?", classes[3].Fingerprint)
+}
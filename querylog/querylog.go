@@ -0,0 +1,116 @@
+// Package querylog characterizes the workload of a query log, e.g. of production, and
+// approximates it with a dbbench script.
+package querylog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Formats of the query logs.
+const (
+	FormatPlain    = "plain"    // one statement per line
+	FormatPostgres = "postgres" // server log with log_statement = 'all' or log_min_duration_statement = 0
+	FormatMySQL    = "mysql"    // general query log
+	FormatRecord   = "record"   // recorded by the proxy of 'dbbench record', see WriteRecord
+)
+
+var (
+	// e.g. "2024-01-01 10:00:00.000 UTC [123] LOG:  duration: 0.1 ms  statement: SELECT 1"
+	postgresEntry = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}[ T]\S+`)
+	postgresStmt  = regexp.MustCompile(`(?:statement|execute [^:]*): (.*)$`)
+	// e.g. "2024-01-01T10:00:00.000000Z	   12 Query	SELECT 1"
+	mysqlEntry = regexp.MustCompile(`^(?:\d{4}-\d{2}-\d{2}T\S+|\d{6}\s+\d+:\d+:\d+)?\s+\d+\s+(\w[\w ]*?)\t(.*)$`)
+
+	// statements controlling the session or transaction, which can't be replayed on a connection pool
+	control = regexp.MustCompile(`(?i)^(BEGIN|START\s+TRANSACTION|COMMIT|ROLLBACK|END|SAVEPOINT|RELEASE|SET|SHOW|USE|DEALLOCATE|DISCARD)\b`)
+)
+
+// Read returns the statements of the query log in the format. Statements controlling the
+// session or transaction are omitted.
+func Read(r io.Reader, format string) ([]string, error) {
+	var (
+		stmts   []string
+		current *strings.Builder // statement continued by the following lines
+		scanner = bufio.NewScanner(r)
+	)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		stmt := strings.TrimSuffix(strings.TrimSpace(current.String()), ";")
+		if stmt != "" && !control.MatchString(stmt) {
+			stmts = append(stmts, stmt)
+		}
+		current = nil
+	}
+	start := func(stmt string) {
+		flush()
+		current = &strings.Builder{}
+		current.WriteString(stmt)
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch format {
+		case FormatPlain:
+			start(line)
+			continue
+		case FormatPostgres:
+			if postgresEntry.MatchString(line) {
+				flush()
+				if m := postgresStmt.FindStringSubmatch(line); m != nil {
+					start(m[1])
+				}
+				continue
+			}
+		case FormatMySQL:
+			if m := mysqlEntry.FindStringSubmatch(line); m != nil {
+				flush()
+				if m[1] == "Query" || m[1] == "Execute" {
+					start(m[2])
+				}
+				continue
+			}
+		case FormatRecord:
+			if fields := strings.SplitN(line, "\t", 4); len(fields) == 4 {
+				start(fields[3])
+			}
+			continue
+		default:
+			return nil, fmt.Errorf("unknown query log format %q", format)
+		}
+
+		// continuation of a multi-line statement
+		if current != nil {
+			current.WriteString(" ")
+			current.WriteString(strings.TrimSpace(line))
+		}
+	}
+	flush()
+	return stmts, scanner.Err()
+}
+
+// Record is a statement recorded by the proxy of 'dbbench record'.
+type Record struct {
+	Time    time.Time     // when the statement was sent
+	Conn    int           // number of the client connection
+	Latency time.Duration // until the first byte of the response
+	Stmt    string
+}
+
+// WriteRecord writes the record as a line of the format FormatRecord: the time, the connection,
+// the latency and the statement, separated by tabs. Line breaks and tabs of the statement are
+// replaced by spaces.
+func WriteRecord(w io.Writer, r Record) error {
+	stmt := strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ").Replace(r.Stmt)
+	_, err := fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", r.Time.UTC().Format(time.RFC3339Nano), r.Conn, r.Latency, stmt)
+	return err
+}
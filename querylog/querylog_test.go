@@ -0,0 +1,82 @@
+package querylog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	testCases := []struct {
+		format string
+		log    string
+	}{
+		{
+			format: FormatPlain,
+			log:    "SELECT * FROM users WHERE id = 1;\nBEGIN;\nUPDATE users SET name = 'a' WHERE id = 2;\n",
+		},
+		{
+			format: FormatPostgres,
+			log: `2024-01-01 10:00:00.000 UTC [123] LOG:  connection authorized: user=app
+2024-01-01 10:00:00.001 UTC [123] LOG:  statement: SELECT * FROM users WHERE id = 1
+2024-01-01 10:00:00.002 UTC [123] LOG:  statement: BEGIN
+2024-01-01 10:00:00.003 UTC [123] LOG:  duration: 0.051 ms  statement: UPDATE users
+		SET name = 'a' WHERE id = 2
+`,
+		},
+		{
+			format: FormatMySQL,
+			log: "2024-01-01T10:00:00.000000Z\t   12 Connect\tapp@localhost on shop\n" +
+				"2024-01-01T10:00:00.000001Z\t   12 Query\tSELECT * FROM users WHERE id = 1\n" +
+				"2024-01-01T10:00:00.000002Z\t   12 Query\tSTART TRANSACTION\n" +
+				"2024-01-01T10:00:00.000003Z\t   12 Query\tUPDATE users\n" +
+				"SET name = 'a' WHERE id = 2\n" +
+				"2024-01-01T10:00:00.000004Z\t   12 Quit\t\n",
+		},
+		{
+			format: FormatRecord,
+			log: "2024-01-01T10:00:00.000001Z\t1\t120µs\tSELECT * FROM users WHERE id = 1\n" +
+				"2024-01-01T10:00:00.000002Z\t2\t80µs\tBEGIN\n" +
+				"2024-01-01T10:00:00.000003Z\t2\t1.5ms\tUPDATE users SET name = 'a' WHERE id = 2\n",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.format, func(t *testing.T) {
+			// act
+			stmts, err := Read(strings.NewReader(tt.log), tt.format)
+
+			// assert
+			require.NoError(t, err)
+			require.Equal(t, []string{"SELECT * FROM users WHERE id = 1", "UPDATE users SET name = 'a' WHERE id = 2"}, stmts)
+		})
+	}
+}
+
+func TestReadUnknownFormat(t *testing.T) {
+	_, err := Read(strings.NewReader("SELECT 1"), "oracle")
+	require.Error(t, err)
+}
+
+func TestWriteRecord(t *testing.T) {
+	// arrange
+	buf := &bytes.Buffer{}
+	r := Record{
+		Time:    time.Date(2024, 1, 1, 10, 0, 0, 1000, time.UTC),
+		Conn:    3,
+		Latency: 1500 * time.Microsecond,
+		Stmt:    "UPDATE users\n\tSET name = 'a' WHERE id = 2",
+	}
+
+	// act
+	err := WriteRecord(buf, r)
+	stmts, readErr := Read(buf, FormatRecord)
+
+	// assert
+	require.NoError(t, err)
+	require.NoError(t, readErr)
+	require.Equal(t, []string{"UPDATE users  SET name = 'a' WHERE id = 2"}, stmts)
+}
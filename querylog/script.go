@@ -0,0 +1,55 @@
+package querylog
+
+import (
+	"fmt"
+	"io"
+)
+
+// WriteScript writes a dbbench script with a loop benchmark, which executes a random statement
+// of the top classes per iteration, weighted by their frequency.
+func WriteScript(w io.Writer, classes []*Class, top int) error {
+	if len(classes) == 0 {
+		return fmt.Errorf("no statements found")
+	}
+
+	total, n := 0, len(classes)
+	for _, c := range classes {
+		total += c.Count
+	}
+	if top <= 0 || top > len(classes) {
+		top = len(classes)
+	}
+	classes = classes[:top]
+	covered := 0
+	for _, c := range classes {
+		covered += c.Count
+	}
+
+	fmt.Fprintf(w, "-- %v statements in %v classes, the top %v cover %.1f%%\n", total, n, top, float64(covered)/float64(total)*100)
+	for _, c := range classes {
+		fmt.Fprintf(w, "-- %5.1f%% (%vx) %v\n", float64(c.Count)/float64(total)*100, c.Count, c.Fingerprint)
+	}
+
+	fmt.Fprintln(w, "\\benchmark loop \\name mix")
+	if len(classes) == 1 {
+		_, err := fmt.Fprintln(w, classes[0].Template()+";")
+		return err
+	}
+
+	fmt.Fprintln(w, "{{$r := call .RandFloat64}}")
+	cumulative := 0
+	for i, c := range classes {
+		switch {
+		case i == 0:
+			cumulative += c.Count
+			fmt.Fprintf(w, "{{- if lt $r %.6f}}%v;\n", float64(cumulative)/float64(covered), c.Template())
+		case i < len(classes)-1:
+			cumulative += c.Count
+			fmt.Fprintf(w, "{{- else if lt $r %.6f}}%v;\n", float64(cumulative)/float64(covered), c.Template())
+		default:
+			fmt.Fprintf(w, "{{- else}}%v;\n", c.Template())
+		}
+	}
+	_, err := fmt.Fprintln(w, "{{- end}}")
+	return err
+}
@@ -0,0 +1,44 @@
+package querylog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sj14/dbbench/benchmark"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteScript(t *testing.T) {
+	// arrange
+	classes := Classify([]string{
+		"SELECT * FROM users WHERE id = 1",
+		"SELECT * FROM users WHERE id = 2",
+		"UPDATE users SET name = 'a' WHERE id = 3",
+		"DELETE FROM users WHERE id = 4",
+	})
+	buf := &bytes.Buffer{}
+
+	// act
+	err := WriteScript(buf, classes, 2)
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, `-- 4 statements in 3 classes, the top 2 cover 75.0%
+--  50.0% (2x) select * from users where id = ?
+--  25.0% (1x) update users set name = ? where id = ?
+\benchmark loop \name mix
+{{$r := call .RandFloat64}}
+{{- if lt $r 0.666667}}SELECT * FROM users WHERE id = {{call .RandInt63n 3}};
+{{- else}}UPDATE users SET name = 'a' WHERE id = {{call .RandInt63n 4}};
+{{- end}}
+`, buf.String())
+
+	benchmarks, err := benchmark.ParseScript(buf)
+	require.NoError(t, err)
+	require.Len(t, benchmarks, 1)
+	require.Equal(t, "(loop) mix", benchmarks[0].Name)
+}
+
+func TestWriteScriptEmpty(t *testing.T) {
+	require.Error(t, WriteScript(&bytes.Buffer{}, nil, 10))
+}
@@ -0,0 +1,56 @@
+package results
+
+import (
+	"fmt"
+	"time"
+)
+
+// CanaryThresholds contains the thresholds of a canary, zero values aren't checked.
+type CanaryThresholds struct {
+	NsPerOp time.Duration // max. duration per operation
+	P99     time.Duration // max. p99 latency of the statements
+	Drift   float64       // max. relative change of ns/op compared to the median of the previous runs, e.g. 0.2 for 20%
+}
+
+// Alert is a benchmark of a canary run, which exceeded a threshold.
+type Alert struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+// CanaryAlerts returns the alerts of the benchmarks of run, which exceeded the thresholds.
+// The drift is compared to the median ns/op of each benchmark in the previous runs.
+func CanaryAlerts(run *Run, previous []*Run, t CanaryThresholds) []Alert {
+	var alerts []Alert
+	for _, b := range run.Benchmarks {
+		if b.Skipped != "" {
+			continue
+		}
+		if t.NsPerOp > 0 && b.NsPerOp > t.NsPerOp.Nanoseconds() {
+			alerts = append(alerts, Alert{Name: b.Name, Message: fmt.Sprintf("%v per operation exceeds the threshold of %v", time.Duration(b.NsPerOp), t.NsPerOp)})
+		}
+		if t.P99 > 0 && b.Latency != nil && b.Latency.P99 > t.P99 {
+			alerts = append(alerts, Alert{Name: b.Name, Message: fmt.Sprintf("p99 latency of %v exceeds the threshold of %v", b.Latency.P99, t.P99)})
+		}
+	}
+
+	if t.Drift <= 0 || len(previous) == 0 {
+		return alerts
+	}
+	values := map[string][]float64{}
+	for _, p := range previous {
+		for _, b := range p.Benchmarks {
+			if b.Skipped == "" && b.NsPerOp > 0 {
+				values[b.Name] = append(values[b.Name], float64(b.NsPerOp))
+			}
+		}
+	}
+	base := &Run{}
+	for name, v := range values {
+		base.Benchmarks = append(base.Benchmarks, Benchmark{Name: name, NsPerOp: int64(median(v))})
+	}
+	for _, r := range Regressions(base, run, t.Drift) {
+		alerts = append(alerts, Alert{Name: r.Name, Message: fmt.Sprintf("%.1f%% slower than the median of the previous runs (%v -> %v per operation)", r.Change*100, time.Duration(r.Base), time.Duration(r.NsPerOp))})
+	}
+	return alerts
+}
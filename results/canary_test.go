@@ -0,0 +1,42 @@
+package results
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanaryAlerts(t *testing.T) {
+	// arrange
+	previous := []*Run{
+		{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 1000}, {Name: "selects", NsPerOp: 500}}},
+		{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 1100}, {Name: "selects", NsPerOp: 500}}},
+		{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 5000}, {Name: "selects", Skipped: "not supported"}}},
+	}
+	run := &Run{Benchmarks: []Benchmark{
+		{Name: "inserts", NsPerOp: 1400, Latency: &LatencyStats{P99: 3 * time.Millisecond}},
+		{Name: "selects", NsPerOp: 550, Latency: &LatencyStats{P99: time.Millisecond}},
+		{Name: "updates", Skipped: "not supported"},
+	}}
+
+	// act
+	got := CanaryAlerts(run, previous, CanaryThresholds{P99: 2 * time.Millisecond, Drift: 0.2})
+
+	// assert
+	require.Equal(t, []Alert{
+		{Name: "inserts", Message: "p99 latency of 3ms exceeds the threshold of 2ms"},
+		{Name: "inserts", Message: "27.3% slower than the median of the previous runs (1.1µs -> 1.4µs per operation)"},
+	}, got)
+}
+
+func TestCanaryAlertsWithoutPrevious(t *testing.T) {
+	// arrange
+	run := &Run{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 3000}}}
+
+	// act
+	got := CanaryAlerts(run, nil, CanaryThresholds{NsPerOp: 2 * time.Microsecond, Drift: 0.2})
+
+	// assert
+	require.Equal(t, []Alert{{Name: "inserts", Message: "3µs per operation exceeds the threshold of 2µs"}}, got)
+}
@@ -0,0 +1,206 @@
+package results
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Regression is a benchmark, which got slower compared to the baseline.
+type Regression struct {
+	Name       string
+	Percentile string  // latency percentile which regressed, e.g. "p99", empty for the ns/op
+	Base       int64   // ns/op or latency in nanoseconds of the baseline
+	NsPerOp    int64   // ns/op or latency in nanoseconds of the run
+	Change     float64 // relative change, e.g. 0.1 when 10% slower
+}
+
+func (r Regression) String() string {
+	if r.Percentile != "" {
+		return fmt.Sprintf("%v latency %.1f%% higher than the baseline (%v -> %v)", r.Percentile, r.Change*100, time.Duration(r.Base), time.Duration(r.NsPerOp))
+	}
+	return fmt.Sprintf("%.1f%% slower than the baseline (%v -> %v per operation)", r.Change*100, time.Duration(r.Base), time.Duration(r.NsPerOp))
+}
+
+// Regressions returns the benchmarks of run, which are more than threshold
+// (relative, e.g. 0.1 for 10%) slower than the benchmarks with the same name in base.
+// When both have latency percentiles, the p50, p95 and p99 latencies are compared as well.
+func Regressions(base, run *Run, threshold float64) []Regression {
+	baseBenchmarks := map[string]Benchmark{}
+	for _, b := range base.Benchmarks {
+		if b.Skipped == "" {
+			baseBenchmarks[b.Name] = b
+		}
+	}
+
+	var regressions []Regression
+	regressed := func(name, percentile string, base, value int64) {
+		if base <= 0 {
+			return
+		}
+		change := float64(value-base) / float64(base)
+		if change > threshold {
+			regressions = append(regressions, Regression{Name: name, Percentile: percentile, Base: base, NsPerOp: value, Change: change})
+		}
+	}
+	for _, b := range run.Benchmarks {
+		bb, ok := baseBenchmarks[b.Name]
+		if !ok || b.Skipped != "" {
+			continue
+		}
+		regressed(b.Name, "", bb.NsPerOp, b.NsPerOp)
+		if bb.Latency != nil && b.Latency != nil {
+			regressed(b.Name, "p50", int64(bb.Latency.P50), int64(b.Latency.P50))
+			regressed(b.Name, "p95", int64(bb.Latency.P95), int64(b.Latency.P95))
+			regressed(b.Name, "p99", int64(bb.Latency.P99), int64(b.Latency.P99))
+		}
+	}
+	return regressions
+}
+
+// Delta contains the relative changes of a benchmark compared to the baseline,
+// e.g. 0.1 when 10% higher. The latencies are only compared when both have percentiles.
+type Delta struct {
+	Name       string
+	NsPerOp    float64
+	Throughput float64 // operations per second
+	Latency    bool    // the percentiles are compared
+	P50        float64
+	P95        float64
+	P99        float64
+}
+
+// Deltas returns the changes of the benchmarks of run, which are in base as well.
+func Deltas(base, run *Run) []Delta {
+	baseBenchmarks := map[string]Benchmark{}
+	for _, b := range base.Benchmarks {
+		if b.Skipped == "" && b.NsPerOp > 0 {
+			baseBenchmarks[b.Name] = b
+		}
+	}
+
+	change := func(base, value float64) float64 {
+		if base == 0 {
+			return 0
+		}
+		return (value - base) / base
+	}
+	var deltas []Delta
+	for _, b := range run.Benchmarks {
+		bb, ok := baseBenchmarks[b.Name]
+		if !ok || b.Skipped != "" || b.NsPerOp <= 0 {
+			continue
+		}
+		d := Delta{
+			Name:       b.Name,
+			NsPerOp:    change(float64(bb.NsPerOp), float64(b.NsPerOp)),
+			Throughput: change(bb.OpsPerSec(), b.OpsPerSec()),
+		}
+		if bb.Latency != nil && b.Latency != nil {
+			d.Latency = true
+			d.P50 = change(float64(bb.Latency.P50), float64(b.Latency.P50))
+			d.P95 = change(float64(bb.Latency.P95), float64(b.Latency.P95))
+			d.P99 = change(float64(bb.Latency.P99), float64(b.Latency.P99))
+		}
+		deltas = append(deltas, d)
+	}
+	return deltas
+}
+
+// Mismatch is a difference of the setup of two runs, their results are not comparable.
+type Mismatch struct {
+	Field string // e.g. "threads" or "server version"
+	Base  string // value of the baseline
+	Run   string
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%v differs: %v (baseline) vs %v", m.Field, m.Base, m.Run)
+}
+
+// Mismatches returns the differences of the setup of run and base, e.g. the threads, the scale or
+// the server version. The server versions are expected to differ, when the runs are labeled
+// with different SUT versions. Unknown server versions, pools and TLS modes, e.g. of older results, are ignored.
+func Mismatches(base, run *Run) []Mismatch {
+	var mismatches []Mismatch
+	differs := func(field string, base, value interface{}) {
+		if base != value {
+			mismatches = append(mismatches, Mismatch{Field: field, Base: fmt.Sprint(base), Run: fmt.Sprint(value)})
+		}
+	}
+	differs("database", base.Database, run.Database)
+	if base.ServerVersion != "" && run.ServerVersion != "" && base.SUTVersion == run.SUTVersion {
+		differs("server version", base.ServerVersion, run.ServerVersion)
+	}
+	differs("settings", settings(base.Settings), settings(run.Settings))
+	if base.ConnPool != nil && run.ConnPool != nil {
+		differs("connection pool", base.ConnPool.String(), run.ConnPool.String())
+	}
+	if base.TLS != "" && run.TLS != "" {
+		differs("tls", base.TLS, run.TLS)
+	}
+	differs("workload", orNone(base.Workload), orNone(run.Workload))
+	differs("scale", base.Scale, run.Scale)
+	differs("threads", base.Threads, run.Threads)
+	if base.Duration == 0 && run.Duration == 0 {
+		differs("iterations", base.Iter, run.Iter)
+	} else {
+		differs("duration", base.Duration, run.Duration)
+	}
+	differs("rate", base.Rate, run.Rate)
+	differs("arrival", orNone(base.Arrival), orNone(run.Arrival))
+	differs("think time", orNone(base.ThinkTime), orNone(run.ThinkTime))
+	differs("query timeout", base.QueryTimeout, run.QueryTimeout)
+	differs("fresh connections", base.FreshConns, run.FreshConns)
+	differs("network", orNone(base.Network), orNone(run.Network))
+	if base.Floor > 0 && run.Floor > 0 {
+		if ratio := float64(run.Floor) / float64(base.Floor); ratio > floorTolerance || ratio < 1/floorTolerance {
+			differs("floor", base.Floor, run.Floor)
+		}
+	}
+	return mismatches
+}
+
+// floorTolerance is the factor, by which the floors of comparable runs may differ,
+// e.g. the round trips of another network path differ more.
+const floorTolerance = 2
+
+// String describes the pool, e.g. "max. open 10, max. idle 2, ...".
+func (p *ConnPool) String() string {
+	if p == nil {
+		return "unknown"
+	}
+	limit := func(d time.Duration) string {
+		if d == 0 {
+			return "none"
+		}
+		return d.String()
+	}
+	open := "unlimited"
+	if p.MaxOpen > 0 {
+		open = strconv.Itoa(p.MaxOpen)
+	}
+	return fmt.Sprintf("max. open %v, max. idle %v, max. lifetime %v, max. idle time %v", open, p.MaxIdle, limit(p.MaxLifetime), limit(p.MaxIdleTime))
+}
+
+// settings returns the settings sorted by their names, e.g. "a=1,b=2".
+func settings(m map[string]string) string {
+	if len(m) == 0 {
+		return "none"
+	}
+	var s []string
+	for k, v := range m {
+		s = append(s, k+"="+v)
+	}
+	sort.Strings(s)
+	return strings.Join(s, ",")
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
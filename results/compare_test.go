@@ -0,0 +1,191 @@
+package results
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegressions(t *testing.T) {
+	// arrange
+	base := &Run{Benchmarks: []Benchmark{
+		{Name: "inserts", NsPerOp: 1000},
+		{Name: "selects", NsPerOp: 1000},
+		{Name: "updates", NsPerOp: 1000},
+		{Name: "upserts", NsPerOp: 1000},
+	}}
+	run := &Run{Benchmarks: []Benchmark{
+		{Name: "inserts", NsPerOp: 1200},
+		{Name: "selects", NsPerOp: 1050},
+		{Name: "deletes", NsPerOp: 5000},
+		{Name: "upserts", Skipped: "not supported"},
+	}}
+
+	// act
+	got := Regressions(base, run, 0.1)
+
+	// assert
+	require.Equal(t, []Regression{{Name: "inserts", Base: 1000, NsPerOp: 1200, Change: 0.2}}, got)
+	require.Equal(t, "20.0% slower than the baseline (1µs -> 1.2µs per operation)", got[0].String())
+}
+
+func TestRegressionsPercentiles(t *testing.T) {
+	// arrange
+	base := &Run{Benchmarks: []Benchmark{
+		{Name: "inserts", NsPerOp: 1000, Latency: &LatencyStats{P50: 1000, P95: 2000, P99: 4000}},
+		{Name: "selects", NsPerOp: 1000},
+	}}
+	run := &Run{Benchmarks: []Benchmark{
+		{Name: "inserts", NsPerOp: 1050, Latency: &LatencyStats{P50: 1000, P95: 2100, P99: 6000}},
+		{Name: "selects", NsPerOp: 1000, Latency: &LatencyStats{P50: 5000, P95: 5000, P99: 5000}},
+	}}
+
+	// act
+	got := Regressions(base, run, 0.1)
+
+	// assert, only the p99 of inserts, selects has no percentiles in the baseline
+	require.Equal(t, []Regression{{Name: "inserts", Percentile: "p99", Base: 4000, NsPerOp: 6000, Change: 0.5}}, got)
+	require.Equal(t, "p99 latency 50.0% higher than the baseline (4µs -> 6µs)", got[0].String())
+}
+
+func TestDeltas(t *testing.T) {
+	// arrange
+	base := &Run{Benchmarks: []Benchmark{
+		{Name: "inserts", NsPerOp: 1000, Latency: &LatencyStats{P50: 1000, P95: 2000, P99: 4000}},
+		{Name: "selects", NsPerOp: 1000},
+		{Name: "deletes", Skipped: "not supported"},
+	}}
+	run := &Run{Benchmarks: []Benchmark{
+		{Name: "inserts", NsPerOp: 1250, Latency: &LatencyStats{P50: 900, P95: 2000, P99: 5000}},
+		{Name: "selects", NsPerOp: 800, Latency: &LatencyStats{P50: 800}},
+		{Name: "deletes", NsPerOp: 1000},
+		{Name: "updates", NsPerOp: 1000},
+	}}
+
+	// act
+	got := Deltas(base, run)
+
+	// assert
+	require.Len(t, got, 2)
+	require.Equal(t, "inserts", got[0].Name)
+	require.InDelta(t, 0.25, got[0].NsPerOp, 0.0001)
+	require.InDelta(t, -0.2, got[0].Throughput, 0.0001)
+	require.True(t, got[0].Latency)
+	require.InDelta(t, -0.1, got[0].P50, 0.0001)
+	require.InDelta(t, 0, got[0].P95, 0.0001)
+	require.InDelta(t, 0.25, got[0].P99, 0.0001)
+	require.Equal(t, "selects", got[1].Name)
+	require.InDelta(t, 0.25, got[1].Throughput, 0.0001)
+	require.False(t, got[1].Latency)
+}
+
+func TestMismatches(t *testing.T) {
+	base := Run{Database: "postgres", ServerVersion: "16.2", Iter: 1000, Threads: 10, Settings: map[string]string{"synchronous_commit": "off", "jit": "off"}, ConnPool: &ConnPool{MaxIdle: 2}, TLS: "disable", Floor: 100 * time.Microsecond}
+
+	testCases := []struct {
+		description string
+		run         func(r *Run)
+		want        []Mismatch
+	}{
+		{
+			description: "same setup",
+			run:         func(r *Run) { r.Settings = map[string]string{"jit": "off", "synchronous_commit": "off"} },
+		},
+		{
+			description: "threads and scale",
+			run:         func(r *Run) { r.Threads, r.Scale = 20, 5 },
+			want: []Mismatch{
+				{Field: "scale", Base: "0", Run: "5"},
+				{Field: "threads", Base: "10", Run: "20"},
+			},
+		},
+		{
+			description: "server version",
+			run:         func(r *Run) { r.ServerVersion = "15.6" },
+			want:        []Mismatch{{Field: "server version", Base: "16.2", Run: "15.6"}},
+		},
+		{
+			description: "server version of another SUT version",
+			run:         func(r *Run) { r.ServerVersion, r.SUTVersion = "15.6", "v15" },
+		},
+		{
+			description: "unknown server version",
+			run:         func(r *Run) { r.ServerVersion = "" },
+		},
+		{
+			description: "settings",
+			run:         func(r *Run) { r.Settings = nil },
+			want:        []Mismatch{{Field: "settings", Base: "jit=off,synchronous_commit=off", Run: "none"}},
+		},
+		{
+			description: "arrival and think time",
+			run:         func(r *Run) { r.Arrival, r.ThinkTime = "exp", "5ms-15ms" },
+			want: []Mismatch{
+				{Field: "arrival", Base: "none", Run: "exp"},
+				{Field: "think time", Base: "none", Run: "5ms-15ms"},
+			},
+		},
+		{
+			description: "query timeout and fresh connections",
+			run:         func(r *Run) { r.QueryTimeout, r.FreshConns = 5*time.Second, true },
+			want: []Mismatch{
+				{Field: "query timeout", Base: "0s", Run: "5s"},
+				{Field: "fresh connections", Base: "false", Run: "true"},
+			},
+		},
+		{
+			description: "duration instead of iterations",
+			run:         func(r *Run) { r.Duration = time.Minute },
+			want:        []Mismatch{{Field: "duration", Base: "0s", Run: "1m0s"}},
+		},
+		{
+			description: "connection pool",
+			run:         func(r *Run) { r.ConnPool = &ConnPool{MaxOpen: 10, MaxIdle: 10, MaxLifetime: time.Minute} },
+			want: []Mismatch{{Field: "connection pool",
+				Base: "max. open unlimited, max. idle 2, max. lifetime none, max. idle time none",
+				Run:  "max. open 10, max. idle 10, max. lifetime 1m0s, max. idle time none"}},
+		},
+		{
+			description: "unknown connection pool",
+			run:         func(r *Run) { r.ConnPool = nil },
+		},
+		{
+			description: "tls",
+			run:         func(r *Run) { r.TLS = "verify-full" },
+			want:        []Mismatch{{Field: "tls", Base: "disable", Run: "verify-full"}},
+		},
+		{
+			description: "network",
+			run:         func(r *Run) { r.Network = "cross-az" },
+			want:        []Mismatch{{Field: "network", Base: "none", Run: "cross-az"}},
+		},
+		{
+			description: "similar floor",
+			run:         func(r *Run) { r.Floor = 180 * time.Microsecond },
+		},
+		{
+			description: "floor of another network path",
+			run:         func(r *Run) { r.Floor = 2 * time.Millisecond },
+			want:        []Mismatch{{Field: "floor", Base: "100µs", Run: "2ms"}},
+		},
+		{
+			description: "unknown floor",
+			run:         func(r *Run) { r.Floor = 0 },
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			// arrange
+			run := base
+			tt.run(&run)
+
+			// act
+			got := Mismatches(&base, &run)
+
+			// assert
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
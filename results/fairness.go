@@ -0,0 +1,59 @@
+package results
+
+import "time"
+
+// FairnessStats contains how the concurrently running benchmarks shared the throughput, see --fairness.
+type FairnessStats struct {
+	Interval time.Duration    `json:"interval"`
+	Mean     float64          `json:"mean"` // mean Jain's fairness index of the samples, 1 when equally shared
+	Min      float64          `json:"min"`
+	Samples  []FairnessSample `json:"samples"`
+}
+
+// FairnessSample contains the throughput of the benchmarks, which ran concurrently during an interval.
+type FairnessSample struct {
+	Elapsed    time.Duration      `json:"elapsed"` // since the start of the run, at the end of the interval
+	Index      float64            `json:"index"`
+	Throughput map[string]float64 `json:"throughput"` // operations per second of each benchmark
+}
+
+// NewFairnessStats returns the stats of the samples, nil without samples.
+func NewFairnessStats(interval time.Duration, samples []FairnessSample) *FairnessStats {
+	if len(samples) == 0 {
+		return nil
+	}
+	f := &FairnessStats{Interval: interval, Min: 1, Samples: samples}
+	for _, s := range samples {
+		f.Mean += s.Index
+		if s.Index < f.Min {
+			f.Min = s.Index
+		}
+	}
+	f.Mean /= float64(len(samples))
+	return f
+}
+
+// Shares returns the mean share of the throughput of each benchmark, over the samples it ran in.
+func (f *FairnessStats) Shares() map[string]float64 {
+	var (
+		sums   = map[string]float64{}
+		counts = map[string]int{}
+	)
+	for _, s := range f.Samples {
+		var total float64
+		for _, t := range s.Throughput {
+			total += t
+		}
+		for name, t := range s.Throughput {
+			counts[name]++
+			if total > 0 {
+				sums[name] += t / total
+			}
+		}
+	}
+	shares := map[string]float64{}
+	for name, n := range counts {
+		shares[name] = sums[name] / float64(n)
+	}
+	return shares
+}
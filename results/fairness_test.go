@@ -0,0 +1,34 @@
+package results
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFairnessStats(t *testing.T) {
+	// arrange
+	samples := []FairnessSample{
+		{Elapsed: time.Second, Index: 0.8, Throughput: map[string]float64{"inserts": 300, "selects": 100}},
+		{Elapsed: 2 * time.Second, Index: 1, Throughput: map[string]float64{"inserts": 200, "selects": 200}},
+		{Elapsed: 3 * time.Second, Index: 0.9, Throughput: map[string]float64{"inserts": 100, "selects": 100, "updates": 400}},
+	}
+
+	// act
+	got := NewFairnessStats(time.Second, samples)
+
+	// assert
+	require.Equal(t, time.Second, got.Interval)
+	require.InDelta(t, 0.9, got.Mean, 0.0001)
+	require.Equal(t, 0.8, got.Min)
+	shares := got.Shares()
+	require.Len(t, shares, 3)
+	require.InDelta(t, (0.75+0.5+1.0/6)/3, shares["inserts"], 0.0001)
+	require.InDelta(t, (0.25+0.5+1.0/6)/3, shares["selects"], 0.0001)
+	require.InDelta(t, 4.0/6, shares["updates"], 0.0001)
+}
+
+func TestNewFairnessStatsWithoutSamples(t *testing.T) {
+	require.Nil(t, NewFairnessStats(time.Second, nil))
+}
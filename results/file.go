@@ -0,0 +1,51 @@
+package results
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// ReadFile reads a run, which was saved with WriteFile.
+func ReadFile(path string) (*Run, error) {
+	dat, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read results: %v", err)
+	}
+
+	if isEncrypted(dat) {
+		return nil, fmt.Errorf("%v is encrypted, decrypt it with 'dbbench decrypt'", path)
+	}
+
+	run := &Run{}
+	if err := json.Unmarshal(dat, run); err != nil {
+		return nil, fmt.Errorf("failed to parse results %v: %v", path, err)
+	}
+	return run, nil
+}
+
+// WriteFile saves the run as JSON to path.
+func (r *Run) WriteFile(path string) error {
+	dat, err := r.marshal()
+	if err != nil {
+		return err
+	}
+	return writeFile(path, dat)
+}
+
+// marshal returns the run as JSON, like it's saved by WriteFile.
+func (r *Run) marshal() ([]byte, error) {
+	dat, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal results: %v", err)
+	}
+	return dat, nil
+}
+
+// writeFile writes the saved results to path.
+func writeFile(path string, dat []byte) error {
+	if err := ioutil.WriteFile(path, dat, 0644); err != nil {
+		return fmt.Errorf("failed to write results: %v", err)
+	}
+	return nil
+}
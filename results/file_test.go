@@ -0,0 +1,35 @@
+package results
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteReadFile(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "run.json")
+	want := &Run{
+		Database:   "sqlite",
+		Start:      time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC),
+		Iter:       1000,
+		Scale:      2,
+		Benchmarks: []Benchmark{{Name: "inserts", Type: "loop", Duration: time.Second, NsPerOp: 1000000}},
+	}
+
+	// act
+	require.NoError(t, want.WriteFile(path))
+	got, err := ReadFile(path)
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
@@ -0,0 +1,69 @@
+package results
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Formats are the supported output formats of the results.
+var Formats = []string{"text", "json", "csv"}
+
+// OpsPerSec returns the throughput of the benchmark in operations per second.
+func (b Benchmark) OpsPerSec() float64 {
+	if b.NsPerOp == 0 {
+		return 0
+	}
+	return 1e9 / float64(b.NsPerOp)
+}
+
+// WriteJSON writes the run as indented JSON, the same as WriteFile.
+func WriteJSON(w io.Writer, run *Run) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(run); err != nil {
+		return fmt.Errorf("failed to marshal results: %v", err)
+	}
+	return nil
+}
+
+// csvHeader contains the columns written by WriteCSV, durations are in nanoseconds.
+var csvHeader = []string{
+	"name", "type", "iterations", "duration_ns", "ns_per_op", "ops_per_sec", "errors",
+	"min_ns", "mean_ns", "p50_ns", "p95_ns", "p99_ns", "max_ns", "skipped",
+}
+
+// WriteCSV writes a row per benchmark of the run. The latency columns are empty,
+// when no statement latencies were collected, e.g. for skipped benchmarks.
+func WriteCSV(w io.Writer, run *Run) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, b := range run.Benchmarks {
+		row := []string{
+			b.Name,
+			b.Type,
+			strconv.Itoa(b.Ops),
+			strconv.FormatInt(b.Duration.Nanoseconds(), 10),
+			strconv.FormatInt(b.NsPerOp, 10),
+			strconv.FormatFloat(b.OpsPerSec(), 'f', 1, 64),
+			strconv.Itoa(b.Errors),
+		}
+		if l := b.Latency; l != nil {
+			for _, d := range []int64{int64(l.Min), int64(l.Mean), int64(l.P50), int64(l.P95), int64(l.P99), int64(l.Max)} {
+				row = append(row, strconv.FormatInt(d, 10))
+			}
+		} else {
+			row = append(row, "", "", "", "", "", "")
+		}
+		row = append(row, b.Skipped)
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
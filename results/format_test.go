@@ -0,0 +1,51 @@
+package results
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJSON(t *testing.T) {
+	// arrange
+	run := &Run{Database: "sqlite", Iter: 1000, Benchmarks: []Benchmark{{Name: "inserts", Type: "loop", NsPerOp: 2000, Ops: 1000}}}
+	buf := &bytes.Buffer{}
+
+	// act
+	err := WriteJSON(buf, run)
+
+	// assert
+	require.NoError(t, err)
+	got := &Run{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), got))
+	require.Equal(t, run, got)
+}
+
+func TestWriteCSV(t *testing.T) {
+	// arrange
+	run := &Run{
+		Database: "sqlite",
+		Benchmarks: []Benchmark{
+			{
+				Name: "inserts", Type: "loop", Duration: 2 * time.Millisecond, NsPerOp: 2000, Ops: 1000, Errors: 2,
+				Latency: &LatencyStats{Min: 1000, Mean: 1900, P50: 1800, P95: 3000, P99: 4000, Max: 5000},
+			},
+			{Name: "upserts", Type: "loop", Skipped: "not supported"},
+		},
+	}
+	buf := &bytes.Buffer{}
+
+	// act
+	err := WriteCSV(buf, run)
+
+	// assert
+	require.NoError(t, err)
+	want := `name,type,iterations,duration_ns,ns_per_op,ops_per_sec,errors,min_ns,mean_ns,p50_ns,p95_ns,p99_ns,max_ns,skipped
+inserts,loop,1000,2000000,2000,500000.0,2,1000,1900,1800,3000,4000,5000,
+upserts,loop,0,0,0,0.0,0,,,,,,,not supported
+`
+	require.Equal(t, want, buf.String())
+}
@@ -0,0 +1,40 @@
+package results
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteGitHubAnnotations writes GitHub Actions workflow commands, which show
+// SLO violations as errors and regressions as warnings on pull requests.
+func WriteGitHubAnnotations(w io.Writer, run *Run, regressions []Regression) error {
+	for _, b := range run.Benchmarks {
+		if !b.SLOViolated() {
+			continue
+		}
+		if err := writeAnnotation(w, "error", "dbbench SLO violated", fmt.Sprintf("%v: %v", b.Name, b.SLOMessage())); err != nil {
+			return err
+		}
+	}
+	for _, r := range regressions {
+		if err := writeAnnotation(w, "warning", "dbbench regression", fmt.Sprintf("%v: %v", r.Name, r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	// escapeData escapes the message of a workflow command, which ends at a line break.
+	escapeData = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	// escapeProperty escapes the properties of a workflow command, which are separated by commas
+	// and end at the colons before the message.
+	escapeProperty = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+// writeAnnotation writes the workflow command of an annotation, e.g. error or warning.
+func writeAnnotation(w io.Writer, command, title, message string) error {
+	_, err := fmt.Fprintf(w, "::%v title=%v::%v\n", command, escapeProperty.Replace(title), escapeData.Replace(message))
+	return err
+}
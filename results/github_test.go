@@ -0,0 +1,40 @@
+package results
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteGitHubAnnotations(t *testing.T) {
+	// arrange
+	run := &Run{Benchmarks: []Benchmark{
+		{Name: "inserts", NsPerOp: 2000, SLO: time.Microsecond},
+		{Name: "selects", NsPerOp: 1200},
+	}}
+	regressions := []Regression{{Name: "selects", Base: 1000, NsPerOp: 1200, Change: 0.2}}
+	buf := &bytes.Buffer{}
+
+	// act
+	err := WriteGitHubAnnotations(buf, run, regressions)
+
+	// assert
+	require.NoError(t, err)
+	want := "::error title=dbbench SLO violated::inserts: 2µs per operation exceeds the SLO of 1µs\n" +
+		"::warning title=dbbench regression::selects: 20.0%25 slower than the baseline (1µs -> 1.2µs per operation)\n"
+	require.Equal(t, want, buf.String())
+}
+
+func TestWriteAnnotationEscaped(t *testing.T) {
+	// arrange
+	buf := &bytes.Buffer{}
+
+	// act
+	err := writeAnnotation(buf, "error", "slo: inserts, selects", "100% failed:\r\nconnection refused")
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, "::error title=slo%3A inserts%2C selects::100%25 failed:%0D%0Aconnection refused\n", buf.String())
+}
@@ -0,0 +1,139 @@
+package results
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Hint is a probable bottleneck of a benchmark with a suggestion what to adjust.
+type Hint struct {
+	Name       string `json:"name"`       // of the benchmark
+	Bottleneck string `json:"bottleneck"` // e.g. client pool or server disk
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion"`
+}
+
+// errorHints contains the message and the suggestion of each error kind, see benchmark.ErrorKind.
+var errorHints = []struct {
+	kind       string
+	message    string
+	suggestion string
+}{
+	{"connection", "%v statements failed to connect, the database or a proxy limits the connections or dropped them",
+		"lower --threads or --conns, or raise the connection limit of the database"},
+	{"timeout", "%v statements timed out, the database can't keep up with the load",
+		"lower --threads or --rate, or find the saturation point with --ramp"},
+	{"conflict", "%v statements failed by deadlocks, lock timeouts or serialization failures, the threads contend for the same rows",
+		"spread the keys, e.g. with a larger --scale or a lower --hit-ratio, or lower --threads"},
+	{"constraint", "%v statements violated a constraint, e.g. duplicate keys of the previous run",
+		"run without --noinit and --noclean, or generate unique keys with {{.Seq \"name\"}} and --seq-start"},
+	{"statement", "%v statements failed by syntax errors or missing tables, they fail regardless of the load",
+		"check the script and the schema, e.g. run without --noinit"},
+	{"other", "%v statements failed", "check the logged errors"},
+}
+
+// Hints returns the probable bottlenecks of the benchmarks of the run in plain language, derived
+// from the errors, the latency distribution and the stats collected by the client and the server.
+func Hints(run *Run) []Hint {
+	var hints []Hint
+	for _, b := range run.Benchmarks {
+		if b.Skipped != "" {
+			continue
+		}
+		add := func(bottleneck, suggestion, format string, args ...interface{}) {
+			hints = append(hints, Hint{Name: b.Name, Bottleneck: bottleneck, Message: fmt.Sprintf(format, args...), Suggestion: suggestion})
+		}
+
+		for _, e := range errorHints {
+			if n := b.ErrorKinds[e.kind]; n > 0 {
+				add("errors: "+e.kind, e.suggestion, e.message, n)
+			}
+		}
+
+		var (
+			waits      int64
+			waited     time.Duration
+			idleClosed int64
+			pooled     bool // the statements queue for the connections rather than on the database
+		)
+		for _, s := range b.Pool {
+			waits += s.WaitCount
+			waited += s.WaitDuration
+			idleClosed += s.MaxIdleClosed
+		}
+		if b.Latency != nil && b.Ops > 0 && waits > 0 {
+			// the pool is shared with the parallel benchmarks
+			busy := b.Latency.Mean * time.Duration(b.Ops)
+			if share := math.Min(float64(waited)/float64(busy), 1); share > 0.1 {
+				pooled = true
+				add("client pool", fmt.Sprintf("raise --conns to --threads (%v) or lower --threads", run.Threads),
+					"%.0f%% of the latency was spent waiting for a connection of the pool (%v waits, mean %v)",
+					share*100, waits, waited/time.Duration(waits))
+			}
+		}
+
+		if b.Ops > 0 && float64(idleClosed) > 0.01*float64(b.Ops) {
+			add("client pool", fmt.Sprintf("raise --max-idle-conns to --conns or --threads (%v)", run.Threads),
+				"%v connections were closed, because more connections than the max. idle ones were returned to the pool, the statements reconnect", idleClosed)
+		}
+
+		if c := b.Client; c != nil {
+			// too short to tell
+			if c.CPU >= 0.8 && b.Duration >= time.Second {
+				add("client CPU", "run dbbench on a separate host or on more cores, e.g. with --procs",
+					"dbbench used %.0f%% of the client cores, the client may limit the throughput", c.CPU*100)
+			}
+			if b.Latency != nil && b.Latency.P99 > 0 && c.GCPauseMax > b.Latency.P99 {
+				add("client GC", "reduce the generated data of the templates or raise GOGC",
+					"the longest GC pause of dbbench (%v) exceeds the p99 latency (%v), the client adds to the tail latency", c.GCPauseMax, b.Latency.P99)
+			}
+		}
+
+		if c := b.Cgroup; c != nil && c.Periods > 0 {
+			if share := float64(c.Throttled) / float64(c.Periods); share > 0.1 {
+				add("container CPU", "raise the CPU limit of the database container",
+					"the database container was throttled in %.0f%% of the periods of its CPU quota", share*100)
+			}
+		}
+
+		var maxCPU, maxIOWait int
+		for _, s := range b.Server {
+			if s.CPU > maxCPU {
+				maxCPU = s.CPU
+			}
+			if s.IOWait > maxIOWait {
+				maxIOWait = s.IOWait
+			}
+		}
+		if maxIOWait >= 20 {
+			add("server disk", "use faster disks, more memory for the caches or fewer writes per statement",
+				"the database server waited up to %v%% of its CPU time for the disks", maxIOWait)
+		}
+		if maxCPU >= 90 {
+			add("server CPU", "add cores to the database server or lower --threads",
+				"the CPU of the database server was used up to %v%%", maxCPU)
+		}
+
+		if !pooled && b.MinLatency > 0 && run.Threads > 1 && b.Concurrency >= 0.8*float64(run.Threads) {
+			if queueing := float64(b.MeanLatency) / float64(b.MinLatency); queueing > 2 {
+				add("server queueing", fmt.Sprintf("find the saturation point with --ramp 1-%v", run.Threads),
+					"the mean latency is %.1fx the min. latency with %.1f statements in flight, the statements queue on the database", queueing, b.Concurrency)
+			}
+		}
+
+		if run.Rate > 0 && b.Type == "loop" && b.Ops > 0 && b.Duration > 0 {
+			if throughput := float64(b.Ops) / b.Duration.Seconds(); throughput < 0.95*run.Rate {
+				add("rate", "raise --threads, the latency limits the rate of each thread",
+					"%.0f of %.0f ops/s requested were achieved", throughput, run.Rate)
+			}
+		}
+
+		if l := b.Latency; l != nil && l.P50 > 0 && l.P99 > 10*l.P50 {
+			add("tail latency", "look for slow intervals with --client-stats and for the disk usage with --server-stats",
+				"the p99 latency (%v) is %.0fx the p50 latency (%v), a few statements are much slower, e.g. by locks, checkpoints or cache misses",
+				l.P99, float64(l.P99)/float64(l.P50), l.P50)
+		}
+	}
+	return hints
+}
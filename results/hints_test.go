@@ -0,0 +1,150 @@
+package results
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHints(t *testing.T) {
+	testCases := []struct {
+		description string
+		run         *Run
+		want        []string // bottlenecks
+	}{
+		{
+			description: "no bottleneck",
+			run: &Run{Threads: 4, Benchmarks: []Benchmark{
+				{Name: "inserts", Type: "loop", Ops: 100, Duration: time.Second, Latency: &LatencyStats{P50: time.Millisecond, Mean: time.Millisecond, P99: 2 * time.Millisecond}},
+			}},
+		},
+		{
+			description: "skipped",
+			run: &Run{Threads: 4, Benchmarks: []Benchmark{
+				{Name: "inserts", Skipped: "unsupported", ErrorKinds: map[string]int{"other": 1}},
+			}},
+		},
+		{
+			description: "errors",
+			run: &Run{Threads: 4, Benchmarks: []Benchmark{
+				{Name: "inserts", Type: "loop", ErrorKinds: map[string]int{"constraint": 3, "conflict": 1}},
+			}},
+			want: []string{"errors: conflict", "errors: constraint"},
+		},
+		{
+			description: "pool waits",
+			run: &Run{Threads: 50, Benchmarks: []Benchmark{
+				{
+					Name: "selects", Type: "loop", Ops: 1000, Duration: time.Second,
+					Latency: &LatencyStats{P50: 4 * time.Millisecond, Mean: 5 * time.Millisecond, P99: 9 * time.Millisecond},
+					Pool:    []PoolSample{{WaitCount: 400, WaitDuration: time.Second}, {WaitCount: 400, WaitDuration: time.Second}},
+				},
+			}},
+			want: []string{"client pool"},
+		},
+		{
+			description: "idle connections closed",
+			run: &Run{Threads: 50, Benchmarks: []Benchmark{
+				{
+					Name: "selects", Type: "loop", Ops: 1000, Duration: time.Second,
+					Latency: &LatencyStats{P50: 4 * time.Millisecond, Mean: 4 * time.Millisecond, P99: 9 * time.Millisecond},
+					Pool:    []PoolSample{{MaxIdleClosed: 300}},
+				},
+			}},
+			want: []string{"client pool"},
+		},
+		{
+			description: "client",
+			run: &Run{Threads: 4, Benchmarks: []Benchmark{
+				{
+					Name: "selects", Type: "loop", Ops: 1000, Duration: time.Second,
+					Latency: &LatencyStats{P50: time.Millisecond, Mean: time.Millisecond, P99: 2 * time.Millisecond},
+					Client:  &ClientStats{CPU: 0.95, GCPauseMax: 3 * time.Millisecond},
+				},
+			}},
+			want: []string{"client CPU", "client GC"},
+		},
+		{
+			description: "server",
+			run: &Run{Threads: 4, Benchmarks: []Benchmark{
+				{
+					Name: "inserts", Type: "loop",
+					Cgroup: &CgroupStats{Periods: 100, Throttled: 30},
+					Server: []ServerSample{{CPU: 50, IOWait: 10}, {CPU: 95, IOWait: 40}},
+				},
+			}},
+			want: []string{"container CPU", "server disk", "server CPU"},
+		},
+		{
+			description: "queueing",
+			run: &Run{Threads: 10, Benchmarks: []Benchmark{
+				{Name: "selects", Type: "loop", Concurrency: 9.5, MeanLatency: 10 * time.Millisecond, MinLatency: time.Millisecond},
+			}},
+			want: []string{"server queueing"},
+		},
+		{
+			description: "queueing for the pool",
+			run: &Run{Threads: 10, Benchmarks: []Benchmark{
+				{
+					Name: "selects", Type: "loop", Ops: 1000, Concurrency: 9.5, MeanLatency: 10 * time.Millisecond, MinLatency: time.Millisecond,
+					Latency: &LatencyStats{P50: 9 * time.Millisecond, Mean: 10 * time.Millisecond, P99: 20 * time.Millisecond},
+					Pool:    []PoolSample{{WaitCount: 900, WaitDuration: 8 * time.Second}},
+				},
+			}},
+			want: []string{"client pool"},
+		},
+		{
+			description: "idle threads aren't queueing",
+			run: &Run{Threads: 10, Benchmarks: []Benchmark{
+				{Name: "selects", Type: "loop", Concurrency: 2, MeanLatency: 10 * time.Millisecond, MinLatency: time.Millisecond},
+			}},
+		},
+		{
+			description: "rate and tail",
+			run: &Run{Threads: 4, Rate: 1000, Benchmarks: []Benchmark{
+				{Name: "selects", Type: "loop", Ops: 800, Duration: time.Second, Latency: &LatencyStats{P50: time.Millisecond, Mean: time.Millisecond, P99: 20 * time.Millisecond}},
+			}},
+			want: []string{"rate", "tail latency"},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			// act
+			hints := Hints(tt.run)
+
+			// assert
+			var got []string
+			for _, h := range hints {
+				require.NotEmpty(t, h.Name)
+				require.NotEmpty(t, h.Message)
+				require.NotEmpty(t, h.Suggestion)
+				got = append(got, h.Bottleneck)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHintsMessage(t *testing.T) {
+	// arrange
+	run := &Run{Threads: 50, Benchmarks: []Benchmark{
+		{
+			Name: "selects", Type: "loop", Ops: 1000,
+			Latency: &LatencyStats{P50: 4 * time.Millisecond, Mean: 5 * time.Millisecond, P99: 9 * time.Millisecond},
+			Pool:    []PoolSample{{WaitCount: 800, WaitDuration: 2 * time.Second}},
+		},
+	}}
+
+	// act
+	hints := Hints(run)
+
+	// assert
+	require.Equal(t, []Hint{{
+		Name:       "selects",
+		Bottleneck: "client pool",
+		Message:    "40% of the latency was spent waiting for a connection of the pool (800 waits, mean 2.5ms)",
+		Suggestion: "raise --conns to --threads (50) or lower --threads",
+	}}, hints)
+}
@@ -0,0 +1,133 @@
+package results
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// HistoryFilter selects the benchmarks of the runs stored in the results warehouse, see History.
+// Empty fields don't filter.
+type HistoryFilter struct {
+	Database   string    // e.g. "postgres"
+	SUTVersion string    // label of the system under test, see --sut-version
+	Since      time.Time // start of the runs, inclusive
+	Until      time.Time // start of the runs, exclusive
+	Benchmarks []string  // names of the benchmarks
+	Limit      int       // max. number of rows, the latest ones
+}
+
+// Table contains the rows returned by a query, formatted as text.
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// History returns the benchmarks of the runs stored in the results warehouse, which match the filter,
+// the latest runs first. The driver is the name of the database/sql driver of db.
+func History(db *sql.DB, driver string, f HistoryFilter) (*Table, error) {
+	var (
+		where []string
+		args  []interface{}
+	)
+	if f.Database != "" {
+		where = append(where, "r.db = ?")
+		args = append(args, f.Database)
+	}
+	if f.SUTVersion != "" {
+		where = append(where, "r.sut_version = ?")
+		args = append(args, f.SUTVersion)
+	}
+	if !f.Since.IsZero() {
+		where = append(where, "r.started >= ?")
+		args = append(args, f.Since.UTC())
+	}
+	if !f.Until.IsZero() {
+		where = append(where, "r.started < ?")
+		args = append(args, f.Until.UTC())
+	}
+	if len(f.Benchmarks) > 0 {
+		where = append(where, "b.name IN (?"+strings.Repeat(", ?", len(f.Benchmarks)-1)+")")
+		for _, name := range f.Benchmarks {
+			args = append(args, name)
+		}
+	}
+
+	query := "SELECT r.started, r.db, r.sut_version, b.name, b.ops, b.ns_per_op, b.ops_per_sec, b.errors, b.p99_ns, r.id " +
+		"FROM dbbench_runs r JOIN dbbench_benchmarks b ON b.run_id = r.id"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY r.started DESC, b.name"
+	if f.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", f.Limit)
+	}
+	return QueryTable(db, sqlx.Rebind(sqlx.BindType(driver), query), args...)
+}
+
+// QueryTable executes the query and returns all returned rows, e.g. of a custom query of the
+// results warehouse. Times are formatted as RFC 3339, floats without exponent and NULL as an empty string.
+func QueryTable(db *sql.DB, query string, args ...interface{}) (*Table, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	t := &Table{Columns: cols}
+
+	values := make([]interface{}, len(cols))
+	for i := range values {
+		values[i] = new(interface{})
+	}
+	for rows.Next() {
+		if err := rows.Scan(values...); err != nil {
+			return nil, err
+		}
+		row := make([]string, len(cols))
+		for i, v := range values {
+			switch v := (*v.(*interface{})).(type) {
+			case nil:
+			case []byte:
+				row[i] = string(v)
+			case time.Time:
+				row[i] = v.Format(time.RFC3339)
+			case float64:
+				row[i] = strconv.FormatFloat(v, 'f', -1, 64)
+			default:
+				row[i] = fmt.Sprint(v)
+			}
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t, rows.Err()
+}
+
+// WriteText writes the table with aligned columns.
+func (t *Table) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.Columns, "\t"))
+	for _, row := range t.Rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// WriteCSV writes the table as CSV with a header of the column names.
+func (t *Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write(t.Columns)
+	cw.WriteAll(t.Rows)
+	return cw.Error()
+}
@@ -0,0 +1,83 @@
+package results
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	// arrange
+	db, cleanup := openWarehouse(t)
+	defer cleanup()
+
+	day := func(d int) time.Time { return time.Date(2020, 1, d, 12, 0, 0, 0, time.UTC) }
+	for _, run := range []*Run{
+		{Database: "postgres", Start: day(1), SUTVersion: "v1", Benchmarks: []Benchmark{{Name: "inserts", Type: "loop", NsPerOp: 1000, Ops: 10}, {Name: "selects", Type: "loop", NsPerOp: 500, Ops: 10}}},
+		{Database: "postgres", Start: day(2), SUTVersion: "v2", Benchmarks: []Benchmark{{Name: "inserts", Type: "loop", NsPerOp: 2000, Ops: 10}}},
+		{Database: "mysql", Start: day(3), SUTVersion: "v2", Benchmarks: []Benchmark{{Name: "inserts", Type: "loop", NsPerOp: 3000, Ops: 10}}},
+		{Database: "postgres", Start: day(4), SUTVersion: "v3", Benchmarks: []Benchmark{{Name: "inserts", Type: "loop", NsPerOp: 4000, Ops: 10}}},
+	} {
+		_, err := Store(db, "sqlite3", run)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name   string
+		filter HistoryFilter
+		want   []string // ns_per_op of the rows
+	}{
+		{name: "all", filter: HistoryFilter{}, want: []string{"4000", "3000", "2000", "1000", "500"}},
+		{name: "database", filter: HistoryFilter{Database: "postgres"}, want: []string{"4000", "2000", "1000", "500"}},
+		{name: "sut version", filter: HistoryFilter{SUTVersion: "v2"}, want: []string{"3000", "2000"}},
+		{name: "date range", filter: HistoryFilter{Since: day(2), Until: day(4)}, want: []string{"3000", "2000"}},
+		{name: "benchmarks", filter: HistoryFilter{Benchmarks: []string{"selects"}}, want: []string{"500"}},
+		{name: "limit", filter: HistoryFilter{Database: "postgres", Limit: 2}, want: []string{"4000", "2000"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			table, err := History(db, "sqlite3", tt.filter)
+
+			// assert
+			require.NoError(t, err)
+			require.Equal(t, "ns_per_op", table.Columns[5])
+			var got []string
+			for _, row := range table.Rows {
+				got = append(got, row[5])
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestQueryTable(t *testing.T) {
+	// arrange
+	db, cleanup := openWarehouse(t)
+	defer cleanup()
+	_, err := Store(db, "sqlite3", &Run{Database: "postgres", Start: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), Benchmarks: []Benchmark{{Name: "inserts", Type: "loop", NsPerOp: 1000}}})
+	require.NoError(t, err)
+
+	// act
+	table, err := QueryTable(db, "SELECT r.started, b.name, b.p99_ns, avg(b.ns_per_op) AS mean FROM dbbench_runs r JOIN dbbench_benchmarks b ON b.run_id = r.id GROUP BY r.started, b.name, b.p99_ns")
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, &Table{Columns: []string{"started", "name", "p99_ns", "mean"}, Rows: [][]string{{"2020-01-02T03:04:05Z", "inserts", "", "1000"}}}, table)
+}
+
+func TestTableWrite(t *testing.T) {
+	// arrange
+	table := &Table{Columns: []string{"name", "ns_per_op"}, Rows: [][]string{{"inserts", "1000"}, {"selects, all", "20"}}}
+	text, csv := &bytes.Buffer{}, &bytes.Buffer{}
+
+	// act
+	require.NoError(t, table.WriteText(text))
+	require.NoError(t, table.WriteCSV(csv))
+
+	// assert
+	require.Equal(t, "name          ns_per_op\ninserts       1000\nselects, all  20\n", text.String())
+	require.Equal(t, "name,ns_per_op\ninserts,1000\n\"selects, all\",20\n", csv.String())
+}
@@ -0,0 +1,251 @@
+package results
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// report layout
+const (
+	reportBarHeight  = 22
+	reportBarWidth   = 500
+	reportLabelWidth = 220
+	reportLineWidth  = 640
+	reportLineHeight = 200
+	reportMargin     = 40
+	colorP50         = "#4682b4"
+	colorP95         = "#ff8c00"
+	colorP99         = "#b22222"
+)
+
+// reportTemplate is the self-contained HTML report, without external scripts or styles.
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"latency": func(l *LatencyStats, percentile string) string {
+		if l == nil {
+			return "-"
+		}
+		return map[string]time.Duration{"min": l.Min, "mean": l.Mean, "p50": l.P50, "p95": l.P95, "p99": l.P99, "max": l.Max}[percentile].String()
+	},
+	"ops": func(b Benchmark) string { return fmt.Sprintf("%.0f", b.OpsPerSec()) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>dbbench {{.Run.Database}} {{.Run.Start.Format "2006-01-02 15:04:05"}}</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+h2 { margin-top: 2em; }
+</style>
+</head>
+<body>
+<h1>dbbench {{.Run.Database}}</h1>
+
+<h2>Configuration</h2>
+<table>
+{{range .Config}}<tr><td>{{index . 0}}</td><td>{{index . 1}}</td></tr>
+{{end}}</table>
+
+<h2>Throughput</h2>
+{{.Throughput}}
+
+<h2>Latencies</h2>
+<table>
+<tr><th>benchmark</th><th>type</th><th>ns/op</th><th>ops/s</th><th>ops</th><th>errors</th><th>min</th><th>mean</th><th>p50</th><th>p95</th><th>p99</th><th>max</th></tr>
+{{range .Run.Benchmarks}}<tr><td>{{.Name}}</td>{{if .Skipped}}<td colspan="11">skipped: {{.Skipped}}</td>{{else}}<td>{{.Type}}</td><td>{{.NsPerOp}}</td><td>{{ops .}}</td><td>{{.Ops}}</td><td>{{.Errors}}</td><td>{{latency .Latency "min"}}</td><td>{{latency .Latency "mean"}}</td><td>{{latency .Latency "p50"}}</td><td>{{latency .Latency "p95"}}</td><td>{{latency .Latency "p99"}}</td><td>{{latency .Latency "max"}}</td>{{end}}</tr>
+{{end}}</table>
+{{if .Timelines}}
+<h2>Latencies over Time</h2>
+{{range .Timelines}}<h3>{{.Name}}</h3>
+{{.Chart}}
+{{end}}{{end}}
+</body>
+</html>
+`))
+
+// reportTimeline is the chart of the latencies over time of a benchmark.
+type reportTimeline struct {
+	Name  string
+	Chart template.HTML
+}
+
+// WriteHTML writes a self-contained HTML report of the run: its configuration, a bar chart of the
+// throughput of the benchmarks, a table of their latency percentiles and a chart of the latencies
+// over time of each benchmark with a timeline.
+func WriteHTML(w io.Writer, run *Run) error {
+	data := struct {
+		Run        *Run
+		Config     [][2]string
+		Throughput template.HTML
+		Timelines  []reportTimeline
+	}{Run: run, Config: reportConfig(run), Throughput: throughputChart(run.Benchmarks)}
+
+	for _, b := range run.Benchmarks {
+		if len(b.Timeline) > 1 {
+			data.Timelines = append(data.Timelines, reportTimeline{Name: b.Name, Chart: timelineChart(b.Timeline)})
+		}
+	}
+	if err := reportTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render report: %v", err)
+	}
+	return nil
+}
+
+// reportConfig returns the configuration of the run as name and value, without the unset options.
+func reportConfig(run *Run) [][2]string {
+	config := [][2]string{
+		{"database", run.Database},
+		{"server version", run.ServerVersion},
+		{"dbbench version", run.Version},
+		{"SUT version", run.SUTVersion},
+		{"start", run.Start.Format(time.RFC3339)},
+		{"threads", fmt.Sprint(run.Threads)},
+	}
+	if run.Duration > 0 {
+		config = append(config, [2]string{"duration", run.Duration.String()})
+	} else {
+		config = append(config, [2]string{"iterations", fmt.Sprint(run.Iter)})
+	}
+	if run.Rate > 0 {
+		config = append(config, [2]string{"rate", fmt.Sprintf("%v ops/s", run.Rate)})
+	}
+	config = append(config, [2]string{"arrival", run.Arrival}, [2]string{"think time", run.ThinkTime})
+	if run.Seed != 0 {
+		config = append(config, [2]string{"seed", fmt.Sprint(run.Seed)})
+	}
+	if run.QueryTimeout > 0 {
+		config = append(config, [2]string{"query timeout", run.QueryTimeout.String()})
+	}
+	if run.FreshConns {
+		config = append(config, [2]string{"connections", "new per statement"})
+	}
+	if run.Agents > 0 {
+		config = append(config, [2]string{"agents", fmt.Sprint(run.Agents)})
+	}
+	if run.ClockSkew > 0 {
+		config = append(config, [2]string{"clock skew", run.ClockSkew.String()})
+	}
+	config = append(config,
+		[2]string{"workload", run.Workload},
+		[2]string{"network", run.Network},
+		[2]string{"TLS", run.TLS},
+	)
+	if len(run.Settings) > 0 {
+		config = append(config, [2]string{"settings", settings(run.Settings)})
+	}
+	if run.Scale > 0 {
+		config = append(config, [2]string{"scale", fmt.Sprint(run.Scale)})
+	}
+	if run.ConnPool != nil {
+		config = append(config, [2]string{"connection pool", run.ConnPool.String()})
+	}
+	if run.Floor > 0 {
+		config = append(config, [2]string{"floor", run.Floor.String()})
+	}
+
+	var set [][2]string
+	for _, c := range config {
+		if c[1] != "" {
+			set = append(set, c)
+		}
+	}
+	return set
+}
+
+// throughputChart renders a horizontal bar chart of the operations per second of the benchmarks,
+// the skipped ones are omitted.
+func throughputChart(benchmarks []Benchmark) template.HTML {
+	var (
+		shown []Benchmark
+		max   float64
+	)
+	for _, b := range benchmarks {
+		if b.Skipped != "" || b.NsPerOp <= 0 {
+			continue
+		}
+		shown = append(shown, b)
+		if b.OpsPerSec() > max {
+			max = b.OpsPerSec()
+		}
+	}
+	if len(shown) == 0 {
+		return "<p>no benchmarks</p>"
+	}
+
+	sb := &strings.Builder{}
+	width := reportLabelWidth + reportBarWidth + 100
+	fmt.Fprintf(sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="12">`+"\n", width, len(shown)*reportBarHeight+10)
+	for i, b := range shown {
+		y := i*reportBarHeight + 5
+		w := b.OpsPerSec() / max * reportBarWidth
+		fmt.Fprintf(sb, `<text x="%d" y="%d" text-anchor="end">%v</text>`+"\n", reportLabelWidth-8, y+14, html.EscapeString(b.Name))
+		fmt.Fprintf(sb, `<rect x="%d" y="%d" width="%.1f" height="%d" fill="%v"/>`+"\n", reportLabelWidth, y, w, reportBarHeight-4, colorBase)
+		fmt.Fprintf(sb, `<text x="%.1f" y="%d">%.0f ops/s</text>`+"\n", float64(reportLabelWidth)+w+6, y+14, b.OpsPerSec())
+	}
+	sb.WriteString("</svg>")
+	return template.HTML(sb.String())
+}
+
+// timelineChart renders a line chart of the p50, p95 and p99 latencies of the intervals.
+func timelineChart(timeline []TimelineSample) template.HTML {
+	samples := append([]TimelineSample{}, timeline...)
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Offset < samples[j].Offset })
+
+	var max time.Duration
+	for _, s := range samples {
+		if s.P99 > max {
+			max = s.P99
+		}
+	}
+	if max == 0 {
+		max = 1
+	}
+	first, last := samples[0].Offset, samples[len(samples)-1].Offset
+	span := last - first
+	if span == 0 {
+		span = 1
+	}
+	x := func(offset time.Duration) float64 {
+		return reportMargin + float64(offset-first)/float64(span)*reportLineWidth
+	}
+	y := func(d time.Duration) float64 {
+		return reportMargin + reportLineHeight - float64(d)/float64(max)*reportLineHeight
+	}
+	bottom := reportMargin + reportLineHeight
+
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="12">`+"\n",
+		2*reportMargin+reportLineWidth+40, 2*reportMargin+reportLineHeight)
+	fmt.Fprintf(sb, `<path d="M%d %dV%dH%d" fill="none" stroke="black"/>`+"\n", reportMargin, reportMargin, bottom, reportMargin+reportLineWidth)
+	fmt.Fprintf(sb, `<text x="%d" y="%d">%v</text>`+"\n", reportMargin, reportMargin-8, max)
+	fmt.Fprintf(sb, `<text x="%d" y="%d">%v</text>`+"\n", reportMargin, bottom+16, first.Round(time.Second))
+	fmt.Fprintf(sb, `<text x="%d" y="%d" text-anchor="end">%v</text>`+"\n", reportMargin+reportLineWidth, bottom+16, last.Round(time.Second))
+
+	for i, line := range []struct {
+		name  string
+		color string
+		value func(TimelineSample) time.Duration
+	}{
+		{"p50", colorP50, func(s TimelineSample) time.Duration { return s.P50 }},
+		{"p95", colorP95, func(s TimelineSample) time.Duration { return s.P95 }},
+		{"p99", colorP99, func(s TimelineSample) time.Duration { return s.P99 }},
+	} {
+		points := make([]string, len(samples))
+		for j, s := range samples {
+			points[j] = fmt.Sprintf("%.1f,%.1f", x(s.Offset), y(line.value(s)))
+		}
+		fmt.Fprintf(sb, `<polyline points="%v" fill="none" stroke="%v"/>`+"\n", strings.Join(points, " "), line.color)
+		legend := reportMargin + 120 + i*60
+		fmt.Fprintf(sb, `<rect x="%d" y="%d" width="10" height="10" fill="%v"/><text x="%d" y="%d">%v</text>`+"\n",
+			legend, reportMargin-18, line.color, legend+14, reportMargin-8, line.name)
+	}
+	sb.WriteString("</svg>")
+	return template.HTML(sb.String())
+}
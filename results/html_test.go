@@ -0,0 +1,45 @@
+package results
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteHTML(t *testing.T) {
+	// arrange
+	run := &Run{
+		Database: "postgres",
+		Threads:  4,
+		Iter:     1000,
+		Seed:     4242,
+		Settings: map[string]string{"work_mem": "64MB"},
+		Benchmarks: []Benchmark{
+			{Name: "<inserts>", Type: "loop", NsPerOp: 1000, Ops: 1000, Latency: &LatencyStats{P50: time.Millisecond, P99: 3 * time.Millisecond}, Timeline: []TimelineSample{
+				{Offset: time.Second, Throughput: 900, P50: time.Millisecond, P95: 2 * time.Millisecond, P99: 3 * time.Millisecond},
+				{Offset: 2 * time.Second, Throughput: 1100, P50: time.Millisecond, P95: 2 * time.Millisecond, P99: 4 * time.Millisecond},
+			}},
+			{Name: "selects", Type: "once", NsPerOp: 500},
+			{Name: "upserts", Skipped: "not supported"},
+		},
+	}
+	buf := &bytes.Buffer{}
+
+	// act
+	err := WriteHTML(buf, run)
+
+	// assert
+	require.NoError(t, err)
+	out := buf.String()
+	require.Contains(t, out, "<td>work_mem=64MB</td>")
+	require.Contains(t, out, "<td>seed</td><td>4242</td>")
+	require.Contains(t, out, "1000000 ops/s")
+	require.Contains(t, out, "&lt;inserts&gt;")
+	require.NotContains(t, out, "<inserts>")
+	require.Contains(t, out, "<td>3ms</td>")
+	require.Contains(t, out, "skipped: not supported")
+	require.Contains(t, out, "<polyline")
+	require.NotContains(t, out, "duration")
+}
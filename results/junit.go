@@ -0,0 +1,71 @@
+package results
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Time     float64     `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+// WriteJUnit writes the run as JUnit XML, each benchmark is a test case,
+// which fails when its service level objective was violated or statements failed
+// and is skipped when it can't run on the database.
+func WriteJUnit(w io.Writer, run *Run) error {
+	suite := junitSuite{Name: "dbbench " + run.Database, Tests: len(run.Benchmarks)}
+
+	for _, b := range run.Benchmarks {
+		c := junitCase{Name: b.Name, Classname: "dbbench." + run.Database, Time: b.Duration.Seconds()}
+		if b.Skipped != "" {
+			suite.Skipped++
+			c.Skipped = &junitSkipped{Message: b.Skipped}
+		}
+		switch {
+		case b.SLOViolated():
+			c.Failure = &junitFailure{Type: "SLO", Message: b.SLOMessage()}
+		case b.Errors > 0:
+			c.Failure = &junitFailure{Type: "errors", Message: fmt.Sprintf("%v statements failed (%.2f%%)", b.Errors, b.ErrorRate*100)}
+		}
+		if c.Failure != nil {
+			suite.Failures++
+		}
+		suite.Time += c.Time
+		suite.Cases = append(suite.Cases, c)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suite); err != nil {
+		return fmt.Errorf("failed to encode junit xml: %v", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,44 @@
+package results
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	// arrange
+	run := &Run{
+		Database: "postgres",
+		Benchmarks: []Benchmark{
+			{Name: "inserts", Duration: 2 * time.Second, NsPerOp: 2000, SLO: time.Millisecond},
+			{Name: "selects", Duration: time.Second, NsPerOp: 2000, SLO: time.Microsecond},
+			{Name: "upserts", Skipped: "not supported"},
+			{Name: "deletes", Duration: time.Second, NsPerOp: 1000, Errors: 5, ErrorRate: 0.005},
+		},
+	}
+	buf := &bytes.Buffer{}
+
+	// act
+	err := WriteJUnit(buf, run)
+
+	// assert
+	require.NoError(t, err)
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="dbbench postgres" tests="4" failures="2" skipped="1" time="4">
+  <testcase name="inserts" classname="dbbench.postgres" time="2"></testcase>
+  <testcase name="selects" classname="dbbench.postgres" time="1">
+    <failure message="2µs per operation exceeds the SLO of 1µs" type="SLO"></failure>
+  </testcase>
+  <testcase name="upserts" classname="dbbench.postgres" time="0">
+    <skipped message="not supported"></skipped>
+  </testcase>
+  <testcase name="deletes" classname="dbbench.postgres" time="1">
+    <failure message="5 statements failed (0.50%)" type="errors"></failure>
+  </testcase>
+</testsuite>
+`
+	require.Equal(t, want, buf.String())
+}
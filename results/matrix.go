@@ -0,0 +1,75 @@
+package results
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// WriteMatrix writes a table of the ns/op of each benchmark (rows) in each named run (columns),
+// e.g. of the variants of a scenario. The first run is the baseline, the others show the overhead
+// compared to it. Missing and skipped benchmarks are shown as "-".
+func WriteMatrix(w io.Writer, names []string, runs []*Run) error {
+	return writeMatrix(w, names, runs, func(ns, base int64) string {
+		if base <= 0 {
+			return fmt.Sprintf("%v ns/op", ns)
+		}
+		return fmt.Sprintf("%v ns/op (%+.1f%%)", ns, float64(ns-base)/float64(base)*100)
+	})
+}
+
+// WriteThroughputMatrix writes a table like WriteMatrix, but of the operations per second,
+// e.g. of the values of a settings sweep. The change is relative to the first run.
+func WriteThroughputMatrix(w io.Writer, names []string, runs []*Run) error {
+	opsPerSec := func(ns int64) float64 {
+		if ns <= 0 {
+			return 0
+		}
+		return float64(time.Second) / float64(ns)
+	}
+	return writeMatrix(w, names, runs, func(ns, base int64) string {
+		if base <= 0 || ns <= 0 {
+			return fmt.Sprintf("%.0f ops/s", opsPerSec(ns))
+		}
+		return fmt.Sprintf("%.0f ops/s (%+.1f%%)", opsPerSec(ns), (opsPerSec(ns)-opsPerSec(base))/opsPerSec(base)*100)
+	})
+}
+
+// writeMatrix writes the table with the cells formatted by cell, base is 0 for the first run
+// and when the benchmark is missing in it.
+func writeMatrix(w io.Writer, names []string, runs []*Run, cell func(ns, base int64) string) error {
+	benchNames, _ := nsPerOpSamples(runs)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "benchmark\t%v\n", strings.Join(names, "\t"))
+
+	for _, bench := range benchNames {
+		cells := make([]string, len(runs))
+		var base int64
+		for i, run := range runs {
+			ns, ok := nsPerOp(run, bench)
+			if ok {
+				cells[i] = cell(ns, base)
+			} else {
+				cells[i] = "-"
+			}
+			if i == 0 && ok {
+				base = ns
+			}
+		}
+		fmt.Fprintf(tw, "%v\t%v\n", bench, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// nsPerOp returns the ns/op of the benchmark in the run, unless it's missing or skipped.
+func nsPerOp(run *Run, name string) (int64, bool) {
+	for _, b := range run.Benchmarks {
+		if b.Name == name && b.Skipped == "" {
+			return b.NsPerOp, true
+		}
+	}
+	return 0, false
+}
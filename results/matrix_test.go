@@ -0,0 +1,47 @@
+package results
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteMatrix(t *testing.T) {
+	// arrange
+	runs := []*Run{
+		{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 1000}, {Name: "selects", NsPerOp: 100}, {Name: "upserts", Skipped: "not supported"}}},
+		{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 1200}, {Name: "selects", NsPerOp: 95}, {Name: "upserts", NsPerOp: 2000}}},
+	}
+	buf := &bytes.Buffer{}
+
+	// act
+	err := WriteMatrix(buf, []string{"plain", "encrypted"}, runs)
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, `benchmark  plain       encrypted
+inserts    1000 ns/op  1200 ns/op (+20.0%)
+selects    100 ns/op   95 ns/op (-5.0%)
+upserts    -           2000 ns/op
+`, buf.String())
+}
+
+func TestWriteThroughputMatrix(t *testing.T) {
+	// arrange
+	runs := []*Run{
+		{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 1000}, {Name: "updates", NsPerOp: 100}}},
+		{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 1250}, {Name: "updates", NsPerOp: 50}}},
+	}
+	buf := &bytes.Buffer{}
+
+	// act
+	err := WriteThroughputMatrix(buf, []string{"on", "off"}, runs)
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, `benchmark  on              off
+inserts    1000000 ops/s   800000 ops/s (-20.0%)
+updates    10000000 ops/s  20000000 ops/s (+100.0%)
+`, buf.String())
+}
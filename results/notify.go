@@ -0,0 +1,77 @@
+package results
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Notify posts a summary of the run, including SLO violations and regressions, to the webhook at url.
+// The payload is a JSON object with the "text" field, as expected by Slack, Teams and most chat webhooks.
+func Notify(url string, run *Run, regressions []Regression) error {
+	return post(url, summary(run, regressions))
+}
+
+// NotifyAlerts posts the alerts of a canary suite to the webhook at url, like Notify.
+// Without alerts, it posts that the previous alerts are resolved.
+func NotifyAlerts(url, suite string, alerts []Alert) error {
+	sb := &strings.Builder{}
+	if len(alerts) == 0 {
+		fmt.Fprintf(sb, "dbbench canary %v: resolved\n", suite)
+	} else {
+		fmt.Fprintf(sb, "dbbench canary %v: %v alerts\n", suite, len(alerts))
+	}
+	for _, a := range alerts {
+		fmt.Fprintf(sb, "%v: %v\n", a.Name, a.Message)
+	}
+	return post(url, sb.String())
+}
+
+// post posts the text to the webhook at url.
+func post(url, text string) error {
+	body, err := json.Marshal(struct {
+		Text string `json:"text"`
+	}{Text: text})
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %v", err)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to send notification: %v", resp.Status)
+	}
+	return nil
+}
+
+// summary returns a human readable summary of the run.
+func summary(run *Run, regressions []Regression) string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "dbbench %v run finished (%v iterations, %v threads)\n", run.Database, run.Iter, run.Threads)
+	for _, b := range run.Benchmarks {
+		if b.Skipped != "" {
+			fmt.Fprintf(sb, "%v: skipped, %v\n", b.Name, b.Skipped)
+			continue
+		}
+		fmt.Fprintf(sb, "%v: %v per operation", b.Name, time.Duration(b.NsPerOp))
+		if b.SLOViolated() {
+			fmt.Fprintf(sb, ", SLO violated")
+		}
+		fmt.Fprintln(sb)
+	}
+	if len(regressions) > 0 {
+		fmt.Fprintf(sb, "%v regressions:\n", len(regressions))
+		for _, r := range regressions {
+			fmt.Fprintf(sb, "%v: %v\n", r.Name, r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,79 @@
+package results
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotify(t *testing.T) {
+	// arrange
+	var received struct {
+		Text string `json:"text"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer srv.Close()
+
+	run := &Run{Database: "postgres", Iter: 1000, Threads: 25, Benchmarks: []Benchmark{
+		{Name: "inserts", NsPerOp: 2000, SLO: time.Microsecond},
+		{Name: "selects", NsPerOp: 1200},
+	}}
+	regressions := []Regression{{Name: "selects", Base: 1000, NsPerOp: 1200, Change: 0.2}}
+
+	// act
+	err := Notify(srv.URL, run, regressions)
+
+	// assert
+	require.NoError(t, err)
+	want := "dbbench postgres run finished (1000 iterations, 25 threads)\n" +
+		"inserts: 2µs per operation, SLO violated\n" +
+		"selects: 1.2µs per operation\n" +
+		"1 regressions:\n" +
+		"selects: 20.0% slower than the baseline (1µs -> 1.2µs per operation)\n"
+	require.Equal(t, want, received.Text)
+}
+
+func TestNotifyFailure(t *testing.T) {
+	// arrange
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	// act
+	err := Notify(srv.URL, &Run{}, nil)
+
+	// assert
+	require.Error(t, err)
+}
+
+func TestNotifyAlerts(t *testing.T) {
+	// arrange
+	var received []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Text string `json:"text"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		received = append(received, body.Text)
+	}))
+	defer srv.Close()
+
+	// act
+	errAlerts := NotifyAlerts(srv.URL, "staging", []Alert{{Name: "inserts", Message: "3µs per operation exceeds the threshold of 2µs"}})
+	errResolved := NotifyAlerts(srv.URL, "staging", nil)
+
+	// assert
+	require.NoError(t, errAlerts)
+	require.NoError(t, errResolved)
+	require.Equal(t, []string{
+		"dbbench canary staging: 1 alerts\ninserts: 3µs per operation exceeds the threshold of 2µs\n",
+		"dbbench canary staging: resolved\n",
+	}, received)
+}
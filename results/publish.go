@@ -0,0 +1,37 @@
+package results
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Publish uploads the run to the results registry at url and returns the shareable URL of the results.
+// The registry is expected to respond with a JSON object containing the "url" field.
+func Publish(url string, run *Run) (string, error) {
+	body, err := json.Marshal(run)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal results: %v", err)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to upload results: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("failed to upload results: %v", resp.Status)
+	}
+
+	var published struct {
+		URL string `json:"url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&published); err != nil {
+		return "", fmt.Errorf("failed to decode registry response: %v", err)
+	}
+	return published.URL, nil
+}
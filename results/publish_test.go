@@ -0,0 +1,44 @@
+package results
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublish(t *testing.T) {
+	// arrange
+	var received Run
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.Write([]byte(`{"url": "https://registry.example/r/1"}`))
+	}))
+	defer srv.Close()
+
+	run := &Run{Database: "postgres", Benchmarks: []Benchmark{{Name: "inserts", Type: "loop", NsPerOp: 42}}}
+
+	// act
+	url, err := Publish(srv.URL, run)
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, "https://registry.example/r/1", url)
+	require.Equal(t, run.Benchmarks, received.Benchmarks)
+}
+
+func TestPublishFailure(t *testing.T) {
+	// arrange
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	// act
+	_, err := Publish(srv.URL, &Run{})
+
+	// assert
+	require.Error(t, err)
+}
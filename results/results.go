@@ -0,0 +1,224 @@
+// Package results contains the results of a dbbench run.
+package results
+
+import "time"
+
+// Run contains the results of all benchmarks of a single dbbench run.
+// It doesn't contain any hostnames or credentials.
+type Run struct {
+	Database      string            `json:"database"`
+	Version       string            `json:"version"`                  // dbbench version
+	ServerVersion string            `json:"server_version,omitempty"` // version of the database server, when reported by the database
+	Settings      map[string]string `json:"settings,omitempty"`       // settings applied to each connection, see --set
+	ConnPool      *ConnPool         `json:"conn_pool,omitempty"`      // connection pool of the client, when configurable
+	TLS           string            `json:"tls,omitempty"`            // TLS mode of the connections, when configurable, see --tls
+	Start         time.Time         `json:"start"`
+	Iter          int               `json:"iter"`
+	Duration      time.Duration     `json:"duration,omitempty"`      // of each loop benchmark instead of Iter iterations
+	Rate          float64           `json:"rate,omitempty"`          // requested operations per second of the loop benchmarks
+	Arrival       string            `json:"arrival,omitempty"`       // distribution of the intervals between the requests at the rate, see --arrival
+	ThinkTime     string            `json:"think_time,omitempty"`    // pause of the threads after each statement, see --think-time
+	QueryTimeout  time.Duration     `json:"query_timeout,omitempty"` // of each statement, see --query-timeout
+	FreshConns    bool              `json:"fresh_conns,omitempty"`   // a new connection per statement, see --fresh-conns
+	Network       string            `json:"network,omitempty"`       // simulated network latency profile, see --network
+	Threads       int               `json:"threads"`
+	Seed          int64             `json:"seed,omitempty"`        // base seed of the random values of the run, see --seed
+	Agents        int               `json:"agents,omitempty"`      // number of machines generating the load, see --agents
+	ClockSkew     time.Duration     `json:"clock_skew,omitempty"`  // max. offset of the clocks of the agents
+	Scale         int               `json:"scale,omitempty"`       // scale factor of the seeded tables or warehouses of the workload
+	Workload      string            `json:"workload,omitempty"`    // built-in workload instead of the built-in benchmarks, see --workload
+	SUTVersion    string            `json:"sut_version,omitempty"` // version or git commit of the system under test
+	Floor         time.Duration     `json:"floor,omitempty"`       // mean latency of a trivial statement, e.g. SELECT 1
+	Benchmarks    []Benchmark       `json:"benchmarks"`
+	Fairness      *FairnessStats    `json:"fairness,omitempty"` // throughput shared by the concurrently running benchmarks
+}
+
+// ConnPool is the configuration of the connection pool of the client.
+type ConnPool struct {
+	MaxOpen     int           `json:"max_open"` // 0 -> unlimited
+	MaxIdle     int           `json:"max_idle"`
+	MaxLifetime time.Duration `json:"max_lifetime,omitempty"`
+	MaxIdleTime time.Duration `json:"max_idle_time,omitempty"`
+}
+
+// Benchmark contains the result of a single benchmark.
+type Benchmark struct {
+	Name        string         `json:"name"`
+	Type        string         `json:"type"`
+	Duration    time.Duration  `json:"duration"`
+	NsPerOp     int64          `json:"ns_per_op"`
+	Ops         int            `json:"ops,omitempty"`         // executed operations
+	SLO         time.Duration  `json:"slo,omitempty"`         // max. duration per operation
+	Skipped     string         `json:"skipped,omitempty"`     // reason why the benchmark was skipped
+	Interrupted bool           `json:"interrupted,omitempty"` // stopped by ctrl-c, contains the statements executed until then
+	Seed        int64          `json:"seed,omitempty"`        // base seed of the random values, see --seed
+	Baseline    string         `json:"baseline,omitempty"`    // paired benchmark, see Overhead
+	Overhead    float64        `json:"overhead,omitempty"`    // relative to the baseline, e.g. 0.1 when 10% slower
+	Floors      float64        `json:"floors,omitempty"`      // ns/op as multiple of the floor of the run
+	Errors      int            `json:"errors,omitempty"`      // failed statements
+	ErrorRate   float64        `json:"error_rate,omitempty"`  // fraction of the statements, which failed
+	Goodput     float64        `json:"goodput,omitempty"`     // successful statements per second, when some failed
+	Outages     []Outage       `json:"outages,omitempty"`     // periods with failed statements
+	ErrorKinds  map[string]int `json:"error_kinds,omitempty"` // failed statements by kind, e.g. conflict or timeout
+	Client      *ClientStats   `json:"client,omitempty"`      // GC pauses and resource usage of dbbench, see --client-stats
+	Server      []ServerSample `json:"server,omitempty"`      // disk and CPU usage of the database server
+	Pool        []PoolSample   `json:"pool,omitempty"`        // connection pool of the client, see --pool-stats
+	Cgroup      *CgroupStats   `json:"cgroup,omitempty"`      // CPU throttling and memory of the database container
+	Wire        *WireStats     `json:"wire,omitempty"`        // latency of the wire protocol, see --wire
+
+	// max. sustainable throughput in operations per second and its p99 latency, see --max-p99,
+	// or the throughput and the p99 latency at the saturation point of the ramp, see --ramp
+	Throughput float64       `json:"throughput,omitempty"`
+	P99        time.Duration `json:"p99,omitempty"`
+
+	// in-flight statements according to Little's law and the mean and min. latency, see --concurrency
+	Concurrency float64       `json:"concurrency,omitempty"`
+	MeanLatency time.Duration `json:"mean_latency,omitempty"`
+	MinLatency  time.Duration `json:"min_latency,omitempty"`
+
+	Latency  *LatencyStats  `json:"latency,omitempty"`  // distribution of the statement latencies
+	Neighbor *NeighborStats `json:"neighbor,omitempty"` // impact of a noisy neighbor, see --neighbor
+	LongTx   *LongTxStats   `json:"long_tx,omitempty"`  // impact of a long-running transaction, see --long-tx
+	Backup   *BackupStats   `json:"backup,omitempty"`   // impact of a backup, see --backup
+	Mix      []MixStats     `json:"mix,omitempty"`      // of each statement of a mixed workload
+	Ramp     []RampStep     `json:"ramp,omitempty"`     // steps of the increasing load, see --ramp
+
+	// throughput and latencies per second of the loop benchmark, see --report and --progress
+	Timeline []TimelineSample `json:"timeline,omitempty"`
+}
+
+// TimelineSample contains the throughput and the latencies of an interval of a benchmark.
+type TimelineSample struct {
+	Offset     time.Duration `json:"offset"`     // end of the interval since the start of the benchmark
+	Throughput float64       `json:"throughput"` // operations per second
+	P50        time.Duration `json:"p50"`
+	P95        time.Duration `json:"p95"`
+	P99        time.Duration `json:"p99"`
+}
+
+// RampStep contains the measurements of a step of the ramp.
+type RampStep struct {
+	Threads    int           `json:"threads"`
+	Rate       float64       `json:"rate,omitempty"` // requested operations per second
+	Ops        int           `json:"ops"`
+	Errors     int           `json:"errors,omitempty"`
+	Throughput float64       `json:"throughput"` // operations per second
+	P50        time.Duration `json:"p50"`
+	P99        time.Duration `json:"p99"`
+	Saturated  bool          `json:"saturated,omitempty"` // more load barely increased the throughput
+}
+
+// MixStats contains the result of a statement of a mixed workload.
+type MixStats struct {
+	Name       string        `json:"name"`
+	Share      float64       `json:"share"` // fraction of the total weight
+	Ops        int           `json:"ops"`
+	Errors     int           `json:"errors,omitempty"`
+	Throughput float64       `json:"throughput"` // operations per second
+	Latency    *LatencyStats `json:"latency,omitempty"`
+
+	ErrorKinds map[string]int    `json:"error_kinds,omitempty"` // failed statements by kind
+	Histogram  []HistogramBucket `json:"histogram,omitempty"`   // distribution of the latencies
+}
+
+// HistogramBucket contains the number of statements with a latency up to the bound, cumulative.
+type HistogramBucket struct {
+	Le    time.Duration `json:"le"`
+	Count int           `json:"count"`
+}
+
+// Outage is a period of a benchmark, in which statements failed.
+type Outage struct {
+	Start  time.Duration `json:"start"` // since the start of the benchmark
+	End    time.Duration `json:"end"`
+	Errors int           `json:"errors"`
+}
+
+// WireStats contains the mean latency of the round trips of the wire protocol, split into the time
+// until the first byte of the response was received and the time to receive the rest of it.
+type WireStats struct {
+	RoundTrips int64         `json:"round_trips"`
+	FirstByte  time.Duration `json:"first_byte"` // mostly the execution by the server
+	Transfer   time.Duration `json:"transfer"`   // mostly the transfer of the result
+}
+
+// NeighborStats contains the mutual impact of a benchmark and a neighbor workload running simultaneously.
+type NeighborStats struct {
+	NsPerOp int64   `json:"ns_per_op"` // of the benchmark while the neighbor runs
+	Alone   float64 `json:"alone"`     // operations per second of the neighbor alone
+	Shared  float64 `json:"shared"`    // operations per second of the neighbor while the benchmark runs
+}
+
+// LatencyStats contains the distribution of the statement latencies of a benchmark.
+type LatencyStats struct {
+	Min  time.Duration `json:"min"`
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P95  time.Duration `json:"p95"`
+	P99  time.Duration `json:"p99"`
+	Max  time.Duration `json:"max"`
+}
+
+// LongTxStats contains the impact of a transaction held open, while the benchmark was executed repeatedly.
+type LongTxStats struct {
+	Held       time.Duration `json:"held"`        // duration the transaction was open
+	Runs       int           `json:"runs"`        // executions of the benchmark while the transaction was open
+	NsPerOp    int64         `json:"ns_per_op"`   // of the benchmark while the transaction was open
+	Bloat      int64         `json:"bloat"`       // growth of dead row versions or undo logs while the transaction was open
+	BloatAlone int64         `json:"bloat_alone"` // growth during the benchmark without the transaction
+}
+
+// BackupStats contains the impact of a backup command, while the benchmark was executed repeatedly.
+type BackupStats struct {
+	Took    time.Duration `json:"took"`             // duration of the backup
+	Runs    int           `json:"runs"`             // executions of the benchmark while the backup ran
+	NsPerOp int64         `json:"ns_per_op"`        // of the benchmark while the backup ran
+	P99     time.Duration `json:"p99"`              // highest p99 latency of the runs
+	Failed  string        `json:"failed,omitempty"` // error of the backup command
+}
+
+// ServerSample is a sample of the disk and CPU usage of the database server during a benchmark.
+type ServerSample struct {
+	Offset    time.Duration `json:"offset"`     // since the start of the benchmark
+	BlocksIn  int64         `json:"blocks_in"`  // blocks per second read from the disks
+	BlocksOut int64         `json:"blocks_out"` // blocks per second written to the disks
+	CPU       int           `json:"cpu"`        // percentage of the CPU time in user and system mode
+	IOWait    int           `json:"iowait"`     // percentage of the CPU time waiting for I/O
+}
+
+// PoolSample is a sample of the connection pool of the client during a benchmark.
+// The counters are the ones of the interval since the previous sample.
+type PoolSample struct {
+	Offset            time.Duration `json:"offset"`               // since the start of the benchmark
+	Open              int           `json:"open"`                 // established connections, in use and idle
+	InUse             int           `json:"in_use"`               // connections in use
+	Idle              int           `json:"idle"`                 // idle connections
+	WaitCount         int64         `json:"wait_count"`           // statements, which waited for a connection
+	WaitDuration      time.Duration `json:"wait_duration"`        // total time waited for connections
+	MaxIdleClosed     int64         `json:"max_idle_closed"`      // closed, because there were too many idle connections
+	MaxIdleTimeClosed int64         `json:"max_idle_time_closed"` // closed, because they were idle for too long
+	MaxLifetimeClosed int64         `json:"max_lifetime_closed"`  // closed, because they reached their max. lifetime
+}
+
+// ClientStats contains the garbage collection pauses and the resource usage of dbbench during a benchmark.
+type ClientStats struct {
+	GCPauses     int           `json:"gc_pauses"`
+	GCPauseTotal time.Duration `json:"gc_pause_total"`
+	GCPauseMax   time.Duration `json:"gc_pause_max"`
+	CPU          float64       `json:"cpu"`                  // mean used fraction of the available cores
+	CPUTime      time.Duration `json:"cpu_time,omitempty"`   // user and system CPU time
+	HeapMax      uint64        `json:"heap_max,omitempty"`   // max. bytes of the allocated heap objects
+	Allocated    uint64        `json:"allocated,omitempty"`  // bytes allocated during the benchmark
+	Goroutines   int           `json:"goroutines,omitempty"` // max. number of goroutines
+	Bottleneck   string        `json:"bottleneck,omitempty"` // why the client probably limited the throughput
+}
+
+// CgroupStats contains the CPU throttling during a benchmark and the memory usage at its end
+// of the cgroup (container) running the database.
+type CgroupStats struct {
+	Periods       int64         `json:"periods"`        // enforcement periods of the CPU quota
+	Throttled     int64         `json:"throttled"`      // periods, in which the cgroup was throttled
+	ThrottledTime time.Duration `json:"throttled_time"` // total time the cgroup was throttled
+	Memory        int64         `json:"memory"`         // bytes of memory in use
+	MemoryLimit   int64         `json:"memory_limit"`   // bytes of the memory limit, 0 when unlimited
+}
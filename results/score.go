@@ -0,0 +1,114 @@
+package results
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"text/tabwriter"
+)
+
+// Score is the composite score of a target, e.g. a database or a configuration, like the SPEC ratios:
+// the weighted geometric mean of the ratios of the ns/op of the reference to the ns/op of the target.
+// The reference scores 1, a target twice as fast in all benchmarks scores 2.
+type Score struct {
+	Name   string
+	Score  float64
+	Ratios map[string]float64 // by benchmark, > 1 when faster than the reference
+}
+
+// ScoreWeight is the weight of a scored benchmark.
+type ScoreWeight struct {
+	Name   string
+	Weight float64
+}
+
+// Scores returns the scores of the targets (sets of runs, with the mean ns/op of each benchmark)
+// relative to the reference and the scored benchmarks. All benchmarks of the reference, which aren't
+// skipped, are scored with the weight 1, unless weights are given for them, a weight of 0 excludes the
+// benchmark. Each target must contain all scored benchmarks, otherwise it's not comparable.
+func Scores(reference []*Run, targets [][]*Run, names []string, weights map[string]float64) ([]Score, []ScoreWeight, error) {
+	refNames, refSamples := nsPerOpSamples(reference)
+	for name, w := range weights {
+		if w < 0 {
+			return nil, nil, fmt.Errorf("negative weight of %v: %v", name, w)
+		}
+		if _, ok := refSamples[name]; !ok {
+			return nil, nil, fmt.Errorf("no benchmark %v in the reference", name)
+		}
+	}
+
+	var (
+		scored []ScoreWeight
+		total  float64
+	)
+	for _, name := range refNames {
+		w, ok := weights[name]
+		if !ok {
+			w = 1
+		}
+		if w == 0 || mean(refSamples[name]) <= 0 {
+			continue
+		}
+		scored = append(scored, ScoreWeight{Name: name, Weight: w})
+		total += w
+	}
+	if len(scored) == 0 {
+		return nil, nil, fmt.Errorf("no benchmarks to score")
+	}
+
+	scores := make([]Score, 0, len(targets))
+	for i, runs := range targets {
+		_, samples := nsPerOpSamples(runs)
+		s := Score{Name: names[i], Ratios: map[string]float64{}}
+		var logSum float64
+		for _, b := range scored {
+			ns := mean(samples[b.Name])
+			if ns <= 0 {
+				return nil, nil, fmt.Errorf("%v is not comparable, missing benchmark %v", names[i], b.Name)
+			}
+			ratio := mean(refSamples[b.Name]) / ns
+			s.Ratios[b.Name] = ratio
+			logSum += b.Weight * math.Log(ratio)
+		}
+		s.Score = math.Exp(logSum / total)
+		scores = append(scores, s)
+	}
+	return scores, scored, nil
+}
+
+// WriteScores writes a table of the ratios of each scored benchmark (rows) of the targets (columns),
+// followed by their scores.
+func WriteScores(w io.Writer, scored []ScoreWeight, scores []Score) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	names := make([]string, len(scores))
+	for i, s := range scores {
+		names[i] = s.Name
+	}
+	fmt.Fprintf(tw, "benchmark\tweight\t%v\n", strings.Join(names, "\t"))
+
+	cells := make([]string, len(scores))
+	for _, b := range scored {
+		for i, s := range scores {
+			cells[i] = fmt.Sprintf("%.3f", s.Ratios[b.Name])
+		}
+		fmt.Fprintf(tw, "%v\t%v\t%v\n", b.Name, b.Weight, strings.Join(cells, "\t"))
+	}
+	for i, s := range scores {
+		cells[i] = fmt.Sprintf("%.3f", s.Score)
+	}
+	fmt.Fprintf(tw, "score\t\t%v\n", strings.Join(cells, "\t"))
+	return tw.Flush()
+}
+
+// mean returns the mean of the values, 0 when there are none.
+func mean(v []float64) float64 {
+	if len(v) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, x := range v {
+		sum += x
+	}
+	return sum / float64(len(v))
+}
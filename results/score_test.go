@@ -0,0 +1,78 @@
+package results
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScores(t *testing.T) {
+	// arrange
+	reference := []*Run{
+		{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 1000}, {Name: "selects", NsPerOp: 100}, {Name: "updates", Skipped: "not supported"}}},
+		{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 1000}, {Name: "selects", NsPerOp: 300}}},
+	}
+	targets := [][]*Run{
+		reference,
+		{{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 250}, {Name: "selects", NsPerOp: 200}}}},
+	}
+
+	// act
+	scores, scored, err := Scores(reference, targets, []string{"postgres", "mysql"}, map[string]float64{"inserts": 2})
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, []ScoreWeight{{Name: "inserts", Weight: 2}, {Name: "selects", Weight: 1}}, scored)
+	require.Len(t, scores, 2)
+	require.InDelta(t, 1, scores[0].Score, 1e-9)
+	require.Equal(t, "mysql", scores[1].Name)
+	require.InDelta(t, 4, scores[1].Ratios["inserts"], 1e-9)
+	require.InDelta(t, 1, scores[1].Ratios["selects"], 1e-9)
+	require.InDelta(t, 2.52, scores[1].Score, 0.01) // (4^2 * 1)^(1/3)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteScores(&buf, scored, scores))
+	require.Contains(t, buf.String(), "score")
+	require.Contains(t, buf.String(), "2.520")
+}
+
+func TestScoresInvalid(t *testing.T) {
+	reference := []*Run{{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 1000}, {Name: "selects", NsPerOp: 100}}}}
+
+	testCases := []struct {
+		description string
+		targets     [][]*Run
+		weights     map[string]float64
+	}{
+		{
+			description: "missing benchmark",
+			targets:     [][]*Run{{{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 1000}}}}},
+		},
+		{
+			description: "unknown weight",
+			targets:     [][]*Run{reference},
+			weights:     map[string]float64{"deletes": 1},
+		},
+		{
+			description: "negative weight",
+			targets:     [][]*Run{reference},
+			weights:     map[string]float64{"inserts": -1},
+		},
+		{
+			description: "nothing scored",
+			targets:     [][]*Run{reference},
+			weights:     map[string]float64{"inserts": 0, "selects": 0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			// act
+			_, _, err := Scores(reference, tc.targets, []string{"target"}, tc.weights)
+
+			// assert
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package results
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SignatureExt is appended to the path of a signed file for its signature.
+const SignatureExt = ".sig"
+
+// GenerateSigningKeys writes a new Ed25519 key pair as PEM to <prefix>.key (private, signs the results)
+// and <prefix>.pub (public, verifies them). The keys are compatible with openssl.
+func GenerateSigningKeys(prefix string) error {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return err
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(prefix+".key", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), 0600); err != nil {
+		return fmt.Errorf("failed to write private key: %v", err)
+	}
+	if err := ioutil.WriteFile(prefix+".pub", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0644); err != nil {
+		return fmt.Errorf("failed to write public key: %v", err)
+	}
+	return nil
+}
+
+// ReadSigningKey reads the private Ed25519 key of the PEM file.
+func ReadSigningKey(path string) (ed25519.PrivateKey, error) {
+	der, err := readPEM(path, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %v: %v", path, err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%v is not an Ed25519 key", path)
+	}
+	return priv, nil
+}
+
+// ReadVerifyingKey reads the public Ed25519 key of the PEM file.
+func ReadVerifyingKey(path string) (ed25519.PublicKey, error) {
+	der, err := readPEM(path, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %v: %v", path, err)
+	}
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%v is not an Ed25519 key", path)
+	}
+	return pub, nil
+}
+
+// readPEM returns the content of the PEM block of the type.
+func readPEM(path, typ string) ([]byte, error) {
+	dat, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %v", err)
+	}
+	block, _ := pem.Decode(dat)
+	if block == nil || block.Type != typ {
+		return nil, fmt.Errorf("%v doesn't contain a %v", path, strings.ToLower(typ))
+	}
+	return block.Bytes, nil
+}
+
+// WriteSealedFile saves the run as JSON to path like WriteFile. With the signing key, the signature
+// of the JSON is written to the path plus SignatureExt, see VerifyFile. With the encryption key, the
+// JSON is encrypted in memory and the file is written once, only readable by its owner, so the
+// results never hit the disk in plain text, see Decrypt.
+func (r *Run) WriteSealedFile(path string, signKey ed25519.PrivateKey, encryptKey []byte) error {
+	dat, err := r.marshal()
+	if err != nil {
+		return err
+	}
+	if signKey != nil {
+		if err := ioutil.WriteFile(path+SignatureExt, ed25519.Sign(signKey, dat), 0644); err != nil {
+			return fmt.Errorf("failed to write signature: %v", err)
+		}
+	}
+	if encryptKey == nil {
+		return writeFile(path, dat)
+	}
+
+	sealed, err := Encrypt(dat, encryptKey)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt results: %v", err)
+	}
+	// the temporary file is created with mode 0600 and replaces an existing file with other permissions
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write encrypted results: %v", err)
+	}
+	_, err = f.Write(sealed)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to write encrypted results: %v", err)
+	}
+	return nil
+}
+
+// ErrInvalidSignature is returned by VerifyFile, when the file was changed after it was signed
+// or it was signed with another key.
+var ErrInvalidSignature = errors.New("invalid signature, the file was changed or signed with another key")
+
+// VerifyFile verifies the file with its signature, written by SignFile.
+func VerifyFile(path string, key ed25519.PublicKey) error {
+	dat, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	sig, err := ioutil.ReadFile(path + SignatureExt)
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %v", err)
+	}
+	if !ed25519.Verify(key, dat, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// encryptedHeader starts the encrypted files, followed by the nonce and the sealed content.
+const encryptedHeader = "dbbench-encrypted-v1\n"
+
+// GenerateEncryptionKey writes a new random AES-256 key as hex to the file.
+func GenerateEncryptionKey(path string) error {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, []byte(hex.EncodeToString(key)+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write encryption key: %v", err)
+	}
+	return nil
+}
+
+// ReadEncryptionKey reads the AES-256 key of the file, written by GenerateEncryptionKey.
+func ReadEncryptionKey(path string) ([]byte, error) {
+	dat, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %v", err)
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(dat)))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("%v doesn't contain a hex encoded 256 bit key", path)
+	}
+	return key, nil
+}
+
+// Encrypt returns the encrypted content (AES-256-GCM), see Decrypt.
+func Encrypt(dat, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := append([]byte(encryptedHeader), nonce...)
+	return gcm.Seal(sealed, nonce, dat, nil), nil
+}
+
+// Decrypt returns the content encrypted by Encrypt, e.g. of a file written by WriteSealedFile.
+func Decrypt(dat, key []byte) ([]byte, error) {
+	if !isEncrypted(dat) {
+		return nil, errors.New("not encrypted by dbbench")
+	}
+	dat = dat[len(encryptedHeader):]
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(dat) < gcm.NonceSize() {
+		return nil, errors.New("truncated encrypted results")
+	}
+	plain, err := gcm.Open(nil, dat[:gcm.NonceSize()], dat[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt, the file was changed or encrypted with another key")
+	}
+	return plain, nil
+}
+
+// isEncrypted returns whether the content was encrypted by Encrypt.
+func isEncrypted(dat []byte) bool {
+	return bytes.HasPrefix(dat, []byte(encryptedHeader))
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,96 @@
+package results
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerifyFile(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	prefix := filepath.Join(dir, "lab")
+	require.NoError(t, GenerateSigningKeys(prefix))
+	priv, err := ReadSigningKey(prefix + ".key")
+	require.NoError(t, err)
+	pub, err := ReadVerifyingKey(prefix + ".pub")
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "run.json")
+
+	// act
+	require.NoError(t, (&Run{Database: "postgres", Iter: 1000}).WriteSealedFile(path, priv, nil))
+
+	// assert
+	require.NoError(t, VerifyFile(path, pub))
+
+	// act, tamper with the results
+	require.NoError(t, (&Run{Database: "postgres", Iter: 1001}).WriteFile(path))
+
+	// assert
+	require.Equal(t, ErrInvalidSignature, VerifyFile(path, pub))
+}
+
+func TestReadKeyOfWrongType(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	prefix := filepath.Join(dir, "lab")
+	require.NoError(t, GenerateSigningKeys(prefix))
+
+	// act
+	_, err = ReadSigningKey(prefix + ".pub")
+
+	// assert
+	require.Error(t, err)
+}
+
+func TestWriteSealedFileEncrypted(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	keyPath := filepath.Join(dir, "results.key")
+	require.NoError(t, GenerateEncryptionKey(keyPath))
+	key, err := ReadEncryptionKey(keyPath)
+	require.NoError(t, err)
+
+	run := &Run{Database: "postgres", Iter: 1000}
+	want, err := run.marshal()
+	require.NoError(t, err)
+	path := filepath.Join(dir, "run.json")
+	require.NoError(t, ioutil.WriteFile(path, nil, 0644))
+
+	// act, replacing an existing file
+	require.NoError(t, run.WriteSealedFile(path, nil, key))
+
+	// assert
+	_, err = ReadFile(path)
+	require.Error(t, err, "encrypted file is not readable as results")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2, "no temporary file is left")
+	dat, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	got, err := Decrypt(dat, key)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+
+	// act, with another key
+	other := make([]byte, len(key))
+	_, err = Decrypt(dat, other)
+
+	// assert
+	require.Error(t, err)
+}
@@ -0,0 +1,238 @@
+package results
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// SignificanceTest returns the two-sided p-value of the samples a and b having the same distribution.
+type SignificanceTest func(a, b []float64) float64
+
+// Comparison is the change of a benchmark between two sets of runs.
+type Comparison struct {
+	Name    string
+	Base    float64 // mean ns/op of the baseline runs
+	NsPerOp float64 // mean ns/op of the compared runs
+	Change  float64 // relative change, e.g. 0.1 when 10% slower
+	P       float64 // probability of a change at least as large being noise
+}
+
+// Significant returns whether the change is unlikely noise, at the significance level alpha.
+func (c Comparison) Significant(alpha float64) bool {
+	return c.P < alpha
+}
+
+func (c Comparison) String() string {
+	direction := "slower"
+	if c.Change < 0 {
+		direction = "faster"
+	}
+	return fmt.Sprintf("%.1f%% %v (%v -> %v per operation, p=%.3f)",
+		math.Abs(c.Change)*100, direction, time.Duration(c.Base), time.Duration(c.NsPerOp), c.P)
+}
+
+// Compare compares the ns/op of the benchmarks contained in both the base and the compared runs.
+func Compare(base, runs []*Run, test SignificanceTest) []Comparison {
+	_, baseSamples := nsPerOpSamples(base)
+	names, samples := nsPerOpSamples(runs)
+
+	var comparisons []Comparison
+	for _, name := range names {
+		a, ok := baseSamples[name]
+		if !ok {
+			continue
+		}
+		b := samples[name]
+		c := Comparison{Name: name, Base: sampleStats(a).mean, NsPerOp: sampleStats(b).mean, P: test(a, b)}
+		if c.Base > 0 {
+			c.Change = (c.NsPerOp - c.Base) / c.Base
+		}
+		comparisons = append(comparisons, c)
+	}
+	return comparisons
+}
+
+// MannWhitney is the Mann-Whitney U test, which makes no assumptions about the distribution of the
+// samples. The p-value is exact for small samples without ties and normal approximated otherwise.
+func MannWhitney(a, b []float64) float64 {
+	type value struct {
+		v     float64
+		fromA bool
+	}
+	var all []value
+	for _, v := range a {
+		all = append(all, value{v, true})
+	}
+	for _, v := range b {
+		all = append(all, value{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].v < all[j].v })
+
+	// rank sum of a, ties get the mean of their ranks
+	var (
+		rankSum float64
+		ties    float64 // sum of t^3-t over the groups of t tied values
+	)
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].fromA {
+				rankSum += rank
+			}
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+
+	n1, n2 := float64(len(a)), float64(len(b))
+	u := rankSum - n1*(n1+1)/2
+
+	if ties == 0 && len(all) <= 20 {
+		return exactU(len(a), len(b), int(u))
+	}
+
+	n := n1 + n2
+	sd := math.Sqrt(n1 * n2 / 12 * ((n + 1) - ties/(n*(n-1))))
+	if sd == 0 {
+		return 1
+	}
+	// continuity correction
+	d := math.Abs(u-n1*n2/2) - 0.5
+	if d < 0 {
+		d = 0
+	}
+	return math.Erfc(d / sd / math.Sqrt2)
+}
+
+// exactU returns the exact two-sided p-value of the U statistic of samples with the sizes m and n.
+func exactU(m, n, u int) float64 {
+	memo := map[[3]int]float64{}
+	// count returns the number of orderings of the samples with the statistic u.
+	var count func(m, n, u int) float64
+	count = func(m, n, u int) float64 {
+		if u < 0 {
+			return 0
+		}
+		if m == 0 || n == 0 {
+			if u == 0 {
+				return 1
+			}
+			return 0
+		}
+		key := [3]int{m, n, u}
+		if c, ok := memo[key]; ok {
+			return c
+		}
+		c := count(m-1, n, u-n) + count(m, n-1, u)
+		memo[key] = c
+		return c
+	}
+
+	// the distribution is symmetric, use the lower tail
+	if max := m * n; u > max-u {
+		u = max - u
+	}
+	var total, tail float64
+	for i := 0; i <= m*n; i++ {
+		c := count(m, n, i)
+		total += c
+		if i <= u {
+			tail += c
+		}
+	}
+	return math.Min(1, 2*tail/total)
+}
+
+// WelchTTest is Welch's t-test, which assumes normal distributed samples, but not equal variances.
+func WelchTTest(a, b []float64) float64 {
+	if len(a) < 2 || len(b) < 2 {
+		return 1
+	}
+	sa, sb := sampleStats(a), sampleStats(b)
+	va := sa.stddev * sa.stddev / float64(len(a))
+	vb := sb.stddev * sb.stddev / float64(len(b))
+	if va+vb == 0 {
+		if sa.mean == sb.mean {
+			return 1
+		}
+		return 0
+	}
+
+	t := (sa.mean - sb.mean) / math.Sqrt(va+vb)
+	df := (va + vb) * (va + vb) / (va*va/float64(len(a)-1) + vb*vb/float64(len(b)-1))
+	return incompleteBeta(df/(df+t*t), df/2, 0.5)
+}
+
+// incompleteBeta returns the regularized incomplete beta function I_x(a, b),
+// evaluated by its continued fraction (Numerical Recipes, 6.4).
+func incompleteBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+
+	// the continued fraction converges quickly for x < (a+1)/(a+b+2)
+	if x > (a+1)/(a+b+2) {
+		return 1 - front*betaFraction(1-x, b, a)/b
+	}
+	return front * betaFraction(x, a, b) / a
+}
+
+// betaFraction evaluates the continued fraction of the incomplete beta function with Lentz's method.
+func betaFraction(x, a, b float64) float64 {
+	const (
+		tiny = 1e-300
+		eps  = 1e-14
+	)
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1.0; m <= 300; m++ {
+		// even step
+		num := m * (b - m) * x / ((a + 2*m - 1) * (a + 2*m))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+
+		// odd step
+		num = -(a + m) * (a + b + m) * x / ((a + 2*m) * (a + 2*m + 1))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < eps {
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,52 @@
+package results
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMannWhitney(t *testing.T) {
+	// exact, all of a below b: 2 of the 20 orderings are as extreme
+	require.InDelta(t, 0.1, MannWhitney([]float64{1, 2, 3}, []float64{4, 5, 6}), 0.0001)
+	require.InDelta(t, 1, MannWhitney([]float64{1, 4}, []float64{2, 3}), 0.0001)
+	// normal approximation with ties: U = 2, sd = 4.729, z = (12.5 - 2 - 0.5) / sd
+	require.InDelta(t, 0.0345, MannWhitney([]float64{1, 2, 2, 3, 4}, []float64{3, 4, 5, 5, 6}), 0.001)
+	require.Equal(t, 1.0, MannWhitney([]float64{1, 1}, []float64{1, 1}))
+}
+
+func TestWelchTTest(t *testing.T) {
+	// t = sqrt(2), df = 2: p = 1 - t/sqrt(df+t^2)
+	require.InDelta(t, 1-math.Sqrt(2)/2, WelchTTest([]float64{1, 3}, []float64{3, 5}), 0.0001)
+	require.True(t, WelchTTest([]float64{10, 11, 12, 11, 10}, []float64{12, 13, 14, 12, 15}) < 0.05)
+	require.InDelta(t, 1, WelchTTest([]float64{1, 2, 3}, []float64{1, 2, 3}), 0.0001)
+	require.Equal(t, 1.0, WelchTTest([]float64{1}, []float64{2}))
+	require.Equal(t, 0.0, WelchTTest([]float64{1, 1}, []float64{2, 2}))
+}
+
+func TestCompare(t *testing.T) {
+	// arrange
+	base := []*Run{
+		{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 1000}, {Name: "selects", NsPerOp: 100}}},
+		{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 1010}, {Name: "selects", NsPerOp: 120}}},
+		{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 990}, {Name: "selects", NsPerOp: 80}}},
+	}
+	runs := []*Run{
+		{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 1200}, {Name: "selects", NsPerOp: 110}, {Name: "deletes", NsPerOp: 1}}},
+		{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 1210}, {Name: "selects", NsPerOp: 90}}},
+		{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 1190}, {Name: "selects", Skipped: "not supported"}}},
+	}
+
+	// act
+	got := Compare(base, runs, WelchTTest)
+
+	// assert
+	require.Len(t, got, 2)
+	require.Equal(t, "inserts", got[0].Name)
+	require.InDelta(t, 0.2, got[0].Change, 0.0001)
+	require.True(t, got[0].Significant(0.05))
+	require.Equal(t, "selects", got[1].Name)
+	require.False(t, got[1].Significant(0.05))
+	require.Equal(t, "20.0% slower (1µs -> 1.2µs per operation, p=0.000)", got[0].String())
+}
@@ -0,0 +1,42 @@
+package results
+
+import (
+	"fmt"
+	"time"
+)
+
+// SLOs contains the service level objectives of the benchmarks,
+// the maximum duration per operation (benchmark name -> duration).
+// The name "all" applies to all benchmarks without their own objective.
+type SLOs map[string]time.Duration
+
+// ParseSLOs parses the objectives from benchmark names and duration strings, e.g. "inserts" -> "2ms".
+func ParseSLOs(m map[string]string) (SLOs, error) {
+	slos := SLOs{}
+	for name, value := range m {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SLO for %v: %v", name, err)
+		}
+		slos[name] = d
+	}
+	return slos, nil
+}
+
+// For returns the objective of the benchmark, 0 when there is none.
+func (s SLOs) For(name string) time.Duration {
+	if d, ok := s[name]; ok {
+		return d
+	}
+	return s["all"]
+}
+
+// SLOViolated returns true when the benchmark has an objective, which was exceeded.
+func (b Benchmark) SLOViolated() bool {
+	return b.SLO > 0 && b.NsPerOp > b.SLO.Nanoseconds()
+}
+
+// SLOMessage describes the violation of the objective.
+func (b Benchmark) SLOMessage() string {
+	return fmt.Sprintf("%v per operation exceeds the SLO of %v", time.Duration(b.NsPerOp), b.SLO)
+}
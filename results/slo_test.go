@@ -0,0 +1,24 @@
+package results
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSLOs(t *testing.T) {
+	slos, err := ParseSLOs(map[string]string{"inserts": "2ms", "all": "1s"})
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Millisecond, slos.For("inserts"))
+	require.Equal(t, time.Second, slos.For("selects"))
+
+	_, err = ParseSLOs(map[string]string{"inserts": "fast"})
+	require.Error(t, err)
+}
+
+func TestSLOViolated(t *testing.T) {
+	require.False(t, Benchmark{NsPerOp: 100}.SLOViolated())
+	require.False(t, Benchmark{NsPerOp: 100, SLO: time.Microsecond}.SLOViolated())
+	require.True(t, Benchmark{NsPerOp: 1001, SLO: time.Microsecond}.SLOViolated())
+}
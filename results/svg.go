@@ -0,0 +1,139 @@
+package results
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+)
+
+// stats contains the mean and the standard deviation of a benchmark over several runs.
+type stats struct {
+	mean   float64
+	stddev float64
+}
+
+// nsPerOpSamples returns the ns/op of each benchmark in all runs.
+// The order of the benchmark names is kept.
+func nsPerOpSamples(runs []*Run) (names []string, samples map[string][]float64) {
+	samples = map[string][]float64{}
+	for _, run := range runs {
+		for _, b := range run.Benchmarks {
+			if b.Skipped != "" {
+				continue
+			}
+			if _, ok := samples[b.Name]; !ok {
+				names = append(names, b.Name)
+			}
+			samples[b.Name] = append(samples[b.Name], float64(b.NsPerOp))
+		}
+	}
+	return names, samples
+}
+
+// sampleStats returns the mean and the standard deviation of the sample.
+func sampleStats(v []float64) stats {
+	var sum float64
+	for _, x := range v {
+		sum += x
+	}
+	mean := sum / float64(len(v))
+
+	var sq float64
+	for _, x := range v {
+		sq += (x - mean) * (x - mean)
+	}
+
+	s := stats{mean: mean}
+	if len(v) > 1 {
+		s.stddev = math.Sqrt(sq / float64(len(v)-1))
+	}
+	return s
+}
+
+// nsPerOpStats returns the ns/op statistics of each benchmark over all runs.
+// The order of the benchmark names is kept.
+func nsPerOpStats(runs []*Run) (names []string, result map[string]stats) {
+	names, samples := nsPerOpSamples(runs)
+	result = map[string]stats{}
+	for name, v := range samples {
+		result[name] = sampleStats(v)
+	}
+	return names, result
+}
+
+// chart layout
+const (
+	chartHeight  = 300
+	chartMargin  = 50
+	groupWidth   = 120
+	barWidth     = 40
+	colorBase    = "#4682b4"
+	colorCompare = "#ff8c00"
+)
+
+// WriteSVG renders a bar chart comparing the ns/op of each benchmark of the base runs with the compared runs.
+// When several runs are given, the bars show the mean and the error bars the standard deviation.
+func WriteSVG(w io.Writer, base, compare []*Run) error {
+	baseNames, baseStats := nsPerOpStats(base)
+	compareNames, compareStats := nsPerOpStats(compare)
+
+	// benchmarks of both sides, in order of appearance
+	names := baseNames
+	for _, name := range compareNames {
+		if _, ok := baseStats[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	max := 0.0
+	for _, s := range []map[string]stats{baseStats, compareStats} {
+		for _, st := range s {
+			max = math.Max(max, st.mean+st.stddev)
+		}
+	}
+	if max == 0 {
+		max = 1
+	}
+
+	width := 2*chartMargin + groupWidth*len(names)
+	height := chartHeight + 2*chartMargin
+	scale := func(v float64) float64 { return v / max * chartHeight }
+	bottom := float64(chartMargin + chartHeight)
+
+	if _, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="12">`+"\n", width, height); err != nil {
+		return err
+	}
+
+	// legend and axis
+	fmt.Fprintf(w, `<rect x="%d" y="10" width="10" height="10" fill="%v"/><text x="%d" y="20">base (ns/op)</text>`+"\n", chartMargin, colorBase, chartMargin+15)
+	fmt.Fprintf(w, `<rect x="%d" y="10" width="10" height="10" fill="%v"/><text x="%d" y="20">compare (ns/op)</text>`+"\n", chartMargin+120, colorCompare, chartMargin+135)
+	fmt.Fprintf(w, `<line x1="%d" y1="%v" x2="%d" y2="%v" stroke="black"/>`+"\n", chartMargin, bottom, width-chartMargin, bottom)
+
+	bar := func(x float64, s stats, ok bool, color string) {
+		if !ok {
+			return
+		}
+		h := scale(s.mean)
+		fmt.Fprintf(w, `<rect x="%.1f" y="%.1f" width="%d" height="%.1f" fill="%v"/>`+"\n", x, bottom-h, barWidth, h, color)
+		fmt.Fprintf(w, `<text x="%.1f" y="%.1f" text-anchor="middle">%.0f</text>`+"\n", x+barWidth/2, bottom-scale(s.mean+s.stddev)-4, s.mean)
+		if s.stddev > 0 {
+			center := x + barWidth/2
+			top, low := bottom-scale(s.mean+s.stddev), bottom-scale(math.Max(s.mean-s.stddev, 0))
+			fmt.Fprintf(w, `<path d="M%.1f %.1fV%.1fM%.1f %.1fH%.1fM%.1f %.1fH%.1f" stroke="black"/>`+"\n",
+				center, top, low, center-5, top, center+5, center-5, low, center+5)
+		}
+	}
+
+	for i, name := range names {
+		x := float64(chartMargin + i*groupWidth + (groupWidth-2*barWidth)/2)
+		s, ok := baseStats[name]
+		bar(x, s, ok, colorBase)
+		s, ok = compareStats[name]
+		bar(x+barWidth, s, ok, colorCompare)
+		fmt.Fprintf(w, `<text x="%.1f" y="%.1f" text-anchor="middle">%v</text>`+"\n", x+barWidth, bottom+20, html.EscapeString(name))
+	}
+
+	_, err := fmt.Fprintln(w, "</svg>")
+	return err
+}
@@ -0,0 +1,53 @@
+package results
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNsPerOpStats(t *testing.T) {
+	runs := []*Run{
+		{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 10}, {Name: "selects", NsPerOp: 5}}},
+		{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 20}}},
+	}
+
+	names, stats := nsPerOpStats(runs)
+
+	require.Equal(t, []string{"inserts", "selects"}, names)
+	require.Equal(t, 15.0, stats["inserts"].mean)
+	require.InDelta(t, 7.07, stats["inserts"].stddev, 0.01)
+	require.Equal(t, 5.0, stats["selects"].mean)
+	require.Equal(t, 0.0, stats["selects"].stddev)
+}
+
+func TestWriteSVG(t *testing.T) {
+	// arrange
+	base := []*Run{{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 10}, {Name: "<selects>", NsPerOp: 5}}}}
+	compare := []*Run{
+		{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 12}}},
+		{Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: 14}}},
+	}
+	buf := &bytes.Buffer{}
+
+	// act
+	err := WriteSVG(buf, base, compare)
+
+	// assert
+	require.NoError(t, err)
+	require.True(t, strings.Contains(buf.String(), "&lt;selects&gt;"))
+
+	// well-formed XML
+	dec := xml.NewDecoder(buf)
+	for {
+		_, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+}
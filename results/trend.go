@@ -0,0 +1,168 @@
+package results
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Trend is the development of a metric of a benchmark over several runs.
+type Trend struct {
+	Name   string
+	Metric string  // "ns/op" or "p99"
+	Runs   int     // number of runs (or SUT versions) containing the benchmark
+	Slope  float64 // relative change per run, e.g. 0.02 when getting 2% slower with each run
+	P      float64 // probability of a trend at least as strong being noise (Mann-Kendall test)
+}
+
+// Significant returns whether the trend is unlikely noise, at the significance level alpha.
+func (t Trend) Significant(alpha float64) bool {
+	return t.P < alpha
+}
+
+func (t Trend) String() string {
+	direction := "slower"
+	if t.Slope < 0 {
+		direction = "faster"
+	}
+	return fmt.Sprintf("%v %.1f%% %v per run over %v runs (p=%.3f)", t.Metric, math.Abs(t.Slope)*100, direction, t.Runs, t.P)
+}
+
+// metrics are the metrics of a benchmark, whose trends are computed. Higher values are worse.
+var metrics = []struct {
+	name  string
+	value func(Benchmark) time.Duration
+}{
+	{"ns/op", func(b Benchmark) time.Duration { return time.Duration(b.NsPerOp) }},
+	{"p99", func(b Benchmark) time.Duration {
+		// of the max. sustainable throughput, otherwise of all statements
+		if b.P99 == 0 && b.Latency != nil {
+			return b.Latency.P99
+		}
+		return b.P99
+	}},
+}
+
+// GroupBySUT groups the runs by the version of the system under test, ordered by their start.
+// Runs without a SUT version form their own group.
+func GroupBySUT(runs []*Run) [][]*Run {
+	sorted := append([]*Run{}, runs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	var (
+		groups [][]*Run
+		index  = map[string]int{}
+	)
+	for _, run := range sorted {
+		if i, ok := index[run.SUTVersion]; ok && run.SUTVersion != "" {
+			groups[i] = append(groups[i], run)
+			continue
+		}
+		index[run.SUTVersion] = len(groups)
+		groups = append(groups, []*Run{run})
+	}
+	return groups
+}
+
+// Trends returns the trends of the benchmarks in the runs, ordered by their start. Runs of the
+// same SUT version are a single point of the trend, using the median of their results.
+// Metrics measured in less than 3 runs (or SUT versions) are omitted.
+func Trends(runs []*Run) []Trend {
+	var (
+		names  []string
+		values = map[string][][]float64{} // per benchmark and metric
+	)
+	for _, group := range GroupBySUT(runs) {
+		// the values of the group, before taking their median
+		groupValues := map[string][][]float64{}
+		for _, run := range group {
+			for _, b := range run.Benchmarks {
+				if b.Skipped != "" {
+					continue
+				}
+				if _, ok := values[b.Name]; !ok {
+					names = append(names, b.Name)
+					values[b.Name] = make([][]float64, len(metrics))
+				}
+				if _, ok := groupValues[b.Name]; !ok {
+					groupValues[b.Name] = make([][]float64, len(metrics))
+				}
+				for i, m := range metrics {
+					if v := m.value(b); v > 0 {
+						groupValues[b.Name][i] = append(groupValues[b.Name][i], float64(v))
+					}
+				}
+			}
+		}
+		for name, metricValues := range groupValues {
+			for i, v := range metricValues {
+				if len(v) > 0 {
+					values[name][i] = append(values[name][i], median(v))
+				}
+			}
+		}
+	}
+
+	var trends []Trend
+	for _, name := range names {
+		for i, m := range metrics {
+			series := values[name][i]
+			if len(series) < 3 {
+				continue
+			}
+			trends = append(trends, Trend{Name: name, Metric: m.name, Runs: len(series), Slope: theilSen(series) / median(series), P: mannKendall(series)})
+		}
+	}
+	return trends
+}
+
+// theilSen returns the median of the slopes between all pairs of values, which,
+// unlike a least squares fit, isn't distorted by single outliers.
+func theilSen(series []float64) float64 {
+	var slopes []float64
+	for i := range series {
+		for j := i + 1; j < len(series); j++ {
+			slopes = append(slopes, (series[j]-series[i])/float64(j-i))
+		}
+	}
+	return median(slopes)
+}
+
+// mannKendall returns the two-sided p-value of the Mann-Kendall test for a monotonic trend,
+// using the normal approximation of the test statistic.
+func mannKendall(series []float64) float64 {
+	var s float64
+	for i := range series {
+		for j := i + 1; j < len(series); j++ {
+			switch {
+			case series[j] > series[i]:
+				s++
+			case series[j] < series[i]:
+				s--
+			}
+		}
+	}
+
+	n := float64(len(series))
+	sd := math.Sqrt(n * (n - 1) * (2*n + 5) / 18)
+	// continuity correction
+	var z float64
+	switch {
+	case s > 0:
+		z = (s - 1) / sd
+	case s < 0:
+		z = (s + 1) / sd
+	}
+	return math.Erfc(math.Abs(z) / math.Sqrt2)
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
@@ -0,0 +1,105 @@
+package results
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrends(t *testing.T) {
+	// arrange
+	var runs []*Run
+	noise := []int64{0, 30, -20, 10, -30, 20, -10, 0, 30, -20}
+	for i := 9; i >= 0; i-- {
+		runs = append(runs, &Run{Start: time.Unix(int64(i), 0), Benchmarks: []Benchmark{
+			{Name: "inserts", NsPerOp: 1000 + int64(i)*20 + noise[i]},
+			{Name: "selects", NsPerOp: 1000 + noise[i]},
+			{Name: "updates", Skipped: "not supported"},
+		}})
+	}
+	runs[0].Benchmarks = append(runs[0].Benchmarks, Benchmark{Name: "deletes", NsPerOp: 1000})
+
+	// act
+	trends := Trends(runs)
+
+	// assert
+	require.Len(t, trends, 2)
+
+	require.Equal(t, "inserts", trends[0].Name)
+	require.Equal(t, "ns/op", trends[0].Metric)
+	require.Equal(t, 10, trends[0].Runs)
+	require.InDelta(t, 0.018, trends[0].Slope, 0.005)
+	require.True(t, trends[0].Significant(0.01))
+
+	require.Equal(t, "selects", trends[1].Name)
+	require.False(t, trends[1].Significant(0.05))
+}
+
+func TestTrendsBySUT(t *testing.T) {
+	// arrange
+	var runs []*Run
+	for i := 0; i < 8; i++ {
+		// an outlier of each version doesn't change the median
+		ns := []int64{1000, 1000 + int64(i)*100, 1000}
+		for j, n := range ns {
+			runs = append(runs, &Run{Start: time.Unix(int64(i*10+j), 0), SUTVersion: fmt.Sprintf("v%v", i), Benchmarks: []Benchmark{{Name: "inserts", NsPerOp: n}}})
+		}
+	}
+
+	// act
+	trends := Trends(runs)
+
+	// assert
+	require.Len(t, trends, 1)
+	require.Equal(t, 8, trends[0].Runs)
+	require.Equal(t, 0.0, trends[0].Slope)
+}
+
+func TestTrendsLatency(t *testing.T) {
+	// arrange
+	var runs []*Run
+	for i := 0; i < 10; i++ {
+		runs = append(runs, &Run{Start: time.Unix(int64(i), 0), Benchmarks: []Benchmark{
+			{Name: "selects", NsPerOp: 1000, Latency: &LatencyStats{P99: time.Duration(1000 + i*100)}},
+		}})
+	}
+
+	// act
+	trends := Trends(runs)
+
+	// assert
+	require.Len(t, trends, 2)
+	require.Equal(t, "p99", trends[1].Metric)
+	require.True(t, trends[1].Significant(0.01))
+}
+
+func TestGroupBySUT(t *testing.T) {
+	// arrange
+	runs := []*Run{
+		{Start: time.Unix(3, 0), SUTVersion: "b"},
+		{Start: time.Unix(1, 0), SUTVersion: "a"},
+		{Start: time.Unix(4, 0)},
+		{Start: time.Unix(2, 0), SUTVersion: "b"},
+		{Start: time.Unix(5, 0)},
+		{Start: time.Unix(6, 0), SUTVersion: "a"},
+	}
+
+	// act
+	groups := GroupBySUT(runs)
+
+	// assert
+	require.Equal(t, [][]*Run{{runs[1], runs[5]}, {runs[3], runs[0]}, {runs[2]}, {runs[4]}}, groups)
+}
+
+func TestMannKendall(t *testing.T) {
+	require.InDelta(t, 1, mannKendall([]float64{1, 1, 1, 1}), 0.0001)
+	require.True(t, mannKendall([]float64{1, 2, 3, 4, 5, 6, 7, 8}) < 0.001)
+	require.True(t, mannKendall([]float64{8, 7, 6, 5, 4, 3, 2, 1}) < 0.001)
+}
+
+func TestTrendString(t *testing.T) {
+	require.Equal(t, "ns/op 2.0% slower per run over 10 runs (p=0.001)", Trend{Metric: "ns/op", Runs: 10, Slope: 0.02, P: 0.001}.String())
+	require.Equal(t, "p99 5.0% faster per run over 4 runs (p=0.042)", Trend{Metric: "p99", Runs: 4, Slope: -0.05, P: 0.042}.String())
+}
@@ -0,0 +1,106 @@
+package results
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// warehouseTables returns the statements creating the tables of the results warehouse.
+// The whole run is also stored as JSON, e.g. to load it again with the other commands.
+func warehouseTables(driver string) []string {
+	text := "TEXT"
+	if driver == "mysql" {
+		// TEXT is limited to 64 KiB
+		text = "LONGTEXT"
+	}
+	return []string{
+		`CREATE TABLE IF NOT EXISTS dbbench_runs (
+			id VARCHAR(32) PRIMARY KEY,
+			db VARCHAR(64) NOT NULL,
+			version VARCHAR(64) NOT NULL,
+			started TIMESTAMP NOT NULL,
+			iter INT NOT NULL,
+			duration_ns BIGINT NOT NULL,
+			rate DOUBLE PRECISION NOT NULL,
+			threads INT NOT NULL,
+			scale INT NOT NULL,
+			network VARCHAR(64) NOT NULL,
+			sut_version VARCHAR(255) NOT NULL,
+			run ` + text + ` NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS dbbench_benchmarks (
+			run_id VARCHAR(32) NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			type VARCHAR(16) NOT NULL,
+			ops INT NOT NULL,
+			duration_ns BIGINT NOT NULL,
+			ns_per_op BIGINT NOT NULL,
+			ops_per_sec DOUBLE PRECISION NOT NULL,
+			errors INT NOT NULL,
+			p50_ns BIGINT,
+			p95_ns BIGINT,
+			p99_ns BIGINT,
+			max_ns BIGINT,
+			skipped VARCHAR(255) NOT NULL
+		)`,
+	}
+}
+
+// Store inserts the run into the results warehouse, a database shared by several users or machines,
+// and returns the id of the stored run. The tables are created when missing. The driver is the name
+// of the database/sql driver of db, e.g. "postgres", "mysql" or "sqlite3".
+func Store(db *sql.DB, driver string, run *Run) (string, error) {
+	for _, stmt := range warehouseTables(driver) {
+		if _, err := db.Exec(stmt); err != nil {
+			return "", fmt.Errorf("failed to create table: %v", err)
+		}
+	}
+
+	id, err := runID()
+	if err != nil {
+		return "", err
+	}
+	dat, err := json.Marshal(run)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal results: %v", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	bind := sqlx.BindType(driver)
+	if _, err := tx.Exec(sqlx.Rebind(bind, "INSERT INTO dbbench_runs (id, db, version, started, iter, duration_ns, rate, threads, scale, network, sut_version, run) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"),
+		id, run.Database, run.Version, run.Start.UTC(), run.Iter, int64(run.Duration), run.Rate, run.Threads, run.Scale, run.Network, run.SUTVersion, string(dat)); err != nil {
+		return "", fmt.Errorf("failed to insert run: %v", err)
+	}
+
+	insert := sqlx.Rebind(bind, "INSERT INTO dbbench_benchmarks (run_id, name, type, ops, duration_ns, ns_per_op, ops_per_sec, errors, p50_ns, p95_ns, p99_ns, max_ns, skipped) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	for _, b := range run.Benchmarks {
+		// the percentiles are NULL without latency stats
+		var p50, p95, p99, max interface{}
+		if l := b.Latency; l != nil {
+			p50, p95, p99, max = int64(l.P50), int64(l.P95), int64(l.P99), int64(l.Max)
+		}
+		if _, err := tx.Exec(insert, id, b.Name, b.Type, b.Ops, int64(b.Duration), b.NsPerOp, b.OpsPerSec(), b.Errors, p50, p95, p99, max, b.Skipped); err != nil {
+			return "", fmt.Errorf("failed to insert benchmark %v: %v", b.Name, err)
+		}
+	}
+	return id, tx.Commit()
+}
+
+// runID returns a random id of a run, unique across all users of the warehouse.
+func runID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate run id: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,67 @@
+package results
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+func openWarehouse(t *testing.T) (*sql.DB, func()) {
+	dir, err := ioutil.TempDir("", "dbbench")
+	require.NoError(t, err)
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "warehouse.sqlite"))
+	require.NoError(t, err)
+
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestStore(t *testing.T) {
+	// arrange
+	db, cleanup := openWarehouse(t)
+	defer cleanup()
+
+	run := &Run{
+		Database: "postgres",
+		Start:    time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Iter:     1000,
+		Threads:  25,
+		Benchmarks: []Benchmark{
+			{Name: "inserts", Type: "loop", NsPerOp: 2000, Ops: 1000, Latency: &LatencyStats{P50: 1800, P95: 3000, P99: 4000, Max: 5000}},
+			{Name: "upserts", Type: "loop", Skipped: "not supported"},
+		},
+	}
+
+	// act
+	first, err := Store(db, "sqlite3", run)
+	require.NoError(t, err)
+	second, err := Store(db, "sqlite3", run)
+	require.NoError(t, err)
+
+	// assert
+	require.NotEqual(t, first, second)
+
+	var runs int
+	require.NoError(t, db.QueryRow("SELECT count(*) FROM dbbench_runs").Scan(&runs))
+	require.Equal(t, 2, runs)
+
+	var (
+		opsPerSec float64
+		p99       sql.NullInt64
+	)
+	require.NoError(t, db.QueryRow("SELECT ops_per_sec, p99_ns FROM dbbench_benchmarks WHERE run_id = ? AND name = 'inserts'", first).Scan(&opsPerSec, &p99))
+	require.Equal(t, 500000.0, opsPerSec)
+	require.Equal(t, sql.NullInt64{Int64: 4000, Valid: true}, p99)
+
+	require.NoError(t, db.QueryRow("SELECT p99_ns FROM dbbench_benchmarks WHERE run_id = ? AND name = 'upserts'", first).Scan(&p99))
+	require.False(t, p99.Valid)
+}